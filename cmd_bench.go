@@ -0,0 +1,209 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"reflect"
+	"runtime"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+)
+
+const benchUsage = `Usage: avrocado bench --schema <file.avsc> [--n 100000] [--backend goavro|hamba] [flags]
+
+Measures encode and decode throughput and allocations of the Avro backend
+(the profile's avro_backend unless --backend is given) on random payloads
+for a schema, the same ones template --random generates. Their logical
+types are in readable form (ISO timestamps, decimal strings), so encode
+times include converting them.
+
+Before timing, each payload is round-tripped (encode, decode, encode,
+decode) as a self-test; the command fails if any round trip loses data.`
+
+// benchPoolSize is how many distinct payloads are generated and cycled through
+const benchPoolSize = 1000
+
+// benchResult is the timing of one operation
+type benchResult struct {
+	elapsed time.Duration
+	allocs  uint64
+	bytes   uint64
+}
+
+func runBenchCommand(args []string) error {
+	flags := pflag.NewFlagSet("bench", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, benchUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile whose Avro backend to use (default profile if empty)")
+	schemaFile := flags.StringP("schema", "s", "", "Schema file (.avsc)")
+	n := flags.IntP("n", "n", 100000, "Number of encodes and decodes to time")
+	backend := flags.String("backend", "", "Avro backend to measure, overriding the profile (goavro or hamba)")
+	seed := flags.Int64("seed", 0, "Seed for the random payloads (default: time-based)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *schemaFile == "" || *n < 1 || flags.NArg() != 0 {
+		return fmt.Errorf("%s", benchUsage)
+	}
+
+	// Only the backend is needed from the profile, so no registry or tunnel
+	if *backend == "" {
+		cfg, err := resolveCommandProfile(*profile)
+		if err != nil && *profile != "" {
+			return err
+		}
+		if cfg != nil {
+			*backend = cfg.AvroBackend
+		}
+	}
+	if err := avro.SetBackend(*backend); err != nil {
+		return err
+	}
+
+	schema, err := os.ReadFile(*schemaFile)
+	if err != nil {
+		return err
+	}
+	codec, err := avro.NewCodec(string(schema))
+	if err != nil {
+		return fmt.Errorf("%s: %w", *schemaFile, err)
+	}
+
+	if !flags.Changed("seed") {
+		*seed = time.Now().UnixNano()
+	}
+	payloads, err := benchPayloads(string(schema), min(*n, benchPoolSize), *seed)
+	if err != nil {
+		return err
+	}
+
+	// Self-test: every payload must survive a round trip unchanged
+	binaries := make([][]byte, len(payloads))
+	var size, failed int
+	var firstFailure error
+	for i, payload := range payloads {
+		if binaries[i], err = codec.EncodeStandard(payload); err != nil {
+			return fmt.Errorf("encoding generated payload %d: %w\n%s", i, err, payload)
+		}
+		size += len(binaries[i])
+		if err := roundTrip(codec, binaries[i]); err != nil {
+			failed++
+			if firstFailure == nil {
+				firstFailure = fmt.Errorf("payload %d: %w\n%s", i, err, payload)
+			}
+		}
+	}
+
+	encode, err := measure(*n, func(i int) error {
+		_, err := codec.EncodeStandard(payloads[i%len(payloads)])
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("encode: %w", err)
+	}
+	decode, err := measure(*n, func(i int) error {
+		_, err := codec.DecodeStandard(binaries[i%len(binaries)])
+		return err
+	})
+	if err != nil {
+		return fmt.Errorf("decode: %w", err)
+	}
+
+	fmt.Printf("backend    %s\n", avro.Backend())
+	fmt.Printf("schema     %s\n", *schemaFile)
+	fmt.Printf("payloads   %d distinct, %d bytes encoded on average (seed %d)\n", len(payloads), size/len(payloads), *seed)
+	fmt.Printf("self-test  %d/%d round trips OK\n\n", len(payloads)-failed, len(payloads))
+	fmt.Printf("%-8s %12s %10s %11s %10s\n", "", "ops/s", "µs/op", "allocs/op", "B/op")
+	encode.print("encode", *n)
+	decode.print("decode", *n)
+
+	if failed > 0 {
+		return fmt.Errorf("self-test: %d of %d round trips changed the payload; first: %w", failed, len(payloads), firstFailure)
+	}
+	return nil
+}
+
+// benchPayloads generates random compact JSON payloads for a schema
+func benchPayloads(schema string, count int, seed int64) ([]string, error) {
+	gen, err := avro.NewRandomGenerator(schema, nil, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return nil, err
+	}
+	payloads := make([]string, count)
+	for i := range payloads {
+		payload, err := gen.Generate()
+		if err != nil {
+			return nil, fmt.Errorf("generating payload: %w", err)
+		}
+		var compact bytes.Buffer
+		if err := json.Compact(&compact, []byte(payload)); err != nil {
+			return nil, err
+		}
+		payloads[i] = compact.String()
+	}
+	return payloads, nil
+}
+
+// roundTrip checks that decoding, re-encoding and decoding again gives the
+// same document
+func roundTrip(codec avro.Codec, binary []byte) error {
+	first, err := codec.DecodeStandard(binary)
+	if err != nil {
+		return fmt.Errorf("decoding: %w", err)
+	}
+	again, err := codec.EncodeStandard(first)
+	if err != nil {
+		return fmt.Errorf("re-encoding %s: %w", first, err)
+	}
+	second, err := codec.DecodeStandard(again)
+	if err != nil {
+		return fmt.Errorf("decoding again: %w", err)
+	}
+
+	var a, b interface{}
+	if err := json.Unmarshal([]byte(first), &a); err != nil {
+		return err
+	}
+	if err := json.Unmarshal([]byte(second), &b); err != nil {
+		return err
+	}
+	if !reflect.DeepEqual(a, b) {
+		return fmt.Errorf("decoded %s, then %s after a round trip", first, second)
+	}
+	return nil
+}
+
+// measure runs op n times, reporting the time taken and heap allocations
+func measure(n int, op func(i int) error) (benchResult, error) {
+	runtime.GC()
+	var before, after runtime.MemStats
+	runtime.ReadMemStats(&before)
+	start := time.Now()
+	for i := 0; i < n; i++ {
+		if err := op(i); err != nil {
+			return benchResult{}, err
+		}
+	}
+	elapsed := time.Since(start)
+	runtime.ReadMemStats(&after)
+	return benchResult{
+		elapsed: elapsed,
+		allocs:  after.Mallocs - before.Mallocs,
+		bytes:   after.TotalAlloc - before.TotalAlloc,
+	}, nil
+}
+
+// print writes one row of the results table for n operations
+func (r benchResult) print(name string, n int) {
+	perOp := r.elapsed / time.Duration(n)
+	fmt.Printf("%-8s %12.0f %10.2f %11d %10d\n", name,
+		float64(n)/r.elapsed.Seconds(),
+		float64(perOp.Nanoseconds())/1000,
+		r.allocs/uint64(n),
+		r.bytes/uint64(n))
+}
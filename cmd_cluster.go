@@ -0,0 +1,46 @@
+package main
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+const clusterUsage = `Usage: avrocado cluster [flags]
+
+Prints an overview of the profile's Kafka cluster: its ID, the brokers with
+their host and rack (the controller marked *), the Kafka version estimated
+from the APIs the brokers support, and topic and partition totals.`
+
+func runClusterCommand(args []string) error {
+	flags := pflag.NewFlagSet("cluster", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, clusterUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("%s", clusterUsage)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	info, err := kafka.DescribeCluster(ctx, cfg)
+	if err != nil {
+		return err
+	}
+	fmt.Print(report.FormatCluster(info))
+	return nil
+}
@@ -0,0 +1,144 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+const docsUsage = `Usage: avrocado docs [subject...] --out <dir> [flags]
+
+Writes a documentation page per subject into --out: the schema's description,
+a table of its fields with types, defaults and docs, the version history with
+each version's field changes, an example payload generated like
+"avrocado template" and the schema itself. An index page links them all.
+Without subjects, every subject in scope is documented.
+
+--format html (default) writes <subject>.html and index.html;
+--format markdown writes <subject>.md and index.md for wikis.`
+
+func runDocsCommand(args []string) error {
+	flags := pflag.NewFlagSet("docs", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, docsUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	out := flags.StringP("out", "o", "", "Directory to write the pages to")
+	format := flags.StringP("format", "f", report.DocsHTML, "Page format: html or markdown")
+	match := flags.StringP("match", "m", "", "Without subjects, only include subjects containing this text")
+	noExamples := flags.Bool("no-examples", false, "Leave out example payloads")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *out == "" {
+		return fmt.Errorf("%s", docsUsage)
+	}
+	ext := ".html"
+	switch *format {
+	case report.DocsHTML:
+	case report.DocsMarkdown, "md":
+		*format, ext = report.DocsMarkdown, ".md"
+	default:
+		return fmt.Errorf("unknown format %q (want html or markdown)", *format)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	client := newRegistryClient(cfg)
+
+	subjects := flags.Args()
+	if len(subjects) == 0 {
+		all, err := client.ListSubjects()
+		if err != nil {
+			return fmt.Errorf("listing subjects: %w", err)
+		}
+		for _, subject := range projectSubjects(cfg, all) {
+			if *match == "" || strings.Contains(strings.ToLower(subject), strings.ToLower(*match)) {
+				subjects = append(subjects, subject)
+			}
+		}
+	}
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	opts := avro.TemplateOptions{
+		PopulateCollections: cfg.Template.PopulateCollections,
+		MaxDepth:            cfg.Template.MaxDepth,
+		PreferNull:          cfg.Template.PreferNull,
+		MaxRecursion:        cfg.Template.MaxRecursion,
+		OmitDefaults:        cfg.Template.OmitDefaults,
+	}
+	fileName := func(subject string) string {
+		return subjectFileName(subject) + ext
+	}
+
+	var docs []report.SubjectDoc
+	failed := 0
+	for _, subject := range subjects {
+		versions, err := client.GetAllVersions(subject)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s: %v\n", subject, err)
+			failed++
+			continue
+		}
+		doc := report.SubjectDoc{Subject: subject, Versions: versions}
+		if !*noExamples && len(versions) > 0 {
+			doc.Example = docsExample(versions[len(versions)-1], opts)
+		}
+
+		page, err := report.SubjectDocs(doc, *format)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s: %v\n", subject, err)
+			failed++
+			continue
+		}
+		path := filepath.Join(*out, fileName(subject))
+		if err := os.WriteFile(path, []byte(page), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		docs = append(docs, doc)
+	}
+
+	index, err := report.DocsIndex(docs, fileName, *format)
+	if err != nil {
+		return err
+	}
+	path := filepath.Join(*out, "index"+ext)
+	if err := os.WriteFile(path, []byte(index), 0644); err != nil {
+		return fmt.Errorf("writing %s: %w", path, err)
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d page(s) and %s\n", len(docs), path)
+	if failed > 0 {
+		return fmt.Errorf("%d subject(s) could not be documented", failed)
+	}
+	return nil
+}
+
+// docsExample returns an example payload for a schema, empty if none can be
+// generated
+func docsExample(schema *registry.SchemaResponse, opts avro.TemplateOptions) string {
+	var example string
+	var err error
+	switch {
+	case schema.IsJSONSchema():
+		example, err = jsonschema.Template(schema.Schema)
+	case schema.SchemaType == "" || schema.SchemaType == registry.SchemaTypeAvro:
+		example, err = avro.GenerateTemplateWithOptions(schema.Schema, opts)
+	}
+	if err != nil {
+		return ""
+	}
+	return example
+}
@@ -0,0 +1,591 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+	"unicode/utf8"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/spf13/pflag"
+	"gopkg.in/yaml.v3"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/csfle"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/jsonpath"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/mask"
+)
+
+const dumpUsage = `Usage: avrocado dump --topic <topic> [--since <age|time>] [--out <file>] [flags]
+
+Consumes every partition of a topic up to its current end, decodes each
+message and writes it with its key, headers and metadata as JSON lines, or as
+an Avro object container file (--format ocf, or an .avro/.ocf --out).
+
+--since takes an age (24h, 90m) or a time (2024-05-01, 2024-05-01T09:00:00Z);
+without it the whole retained topic is dumped. --filter keeps only messages
+whose decoded value matches (same syntax as expect). Fields configured under
+mask are hidden unless --unmasked is given.
+
+Progress is checkpointed next to --out. If a dump is interrupted, run the same
+command again to resume where it stopped.`
+
+// dumpChunkSize is how many messages are fetched between checkpoints
+const dumpChunkSize = 500
+
+// dumpCheckpoint records how far a dump got, so an interrupted dump can
+// resume without duplicating or losing messages
+type dumpCheckpoint struct {
+	Topic    string        `yaml:"topic"`
+	Format   string        `yaml:"format"`
+	SchemaID int           `yaml:"schema_id,omitempty"` // Value schema of an OCF dump
+	Size     int64         `yaml:"size"`                // Output bytes covered by Offsets
+	Offsets  map[int]int64 `yaml:"offsets"`             // Next offset per partition
+}
+
+// dumpRange is the span of offsets to dump from one partition
+type dumpRange struct {
+	partition int
+	consumer  *kafka.Consumer
+	start     int64
+	end       int64
+}
+
+// dumpRecord is one consumed message ready to be written
+type dumpRecord struct {
+	topic     string
+	partition int
+	msg       kafka.Message
+	key       []byte
+	value     []byte
+	schemaID  int
+	text      string // Decoded value as JSON, empty if undecodable
+	err       error
+}
+
+// dumpWriter writes records in one output format
+type dumpWriter interface {
+	Write(rec dumpRecord) (bool, error) // False if the record was skipped
+	Flush() error
+}
+
+func runDumpCommand(args []string) error {
+	flags := pflag.NewFlagSet("dump", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, dumpUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	topic := flags.StringP("topic", "t", "", "Topic to dump")
+	since := flags.String("since", "", "Only messages newer than an age (24h) or time (RFC 3339 or 2006-01-02)")
+	out := flags.StringP("out", "o", "", "Output file (default stdout, JSON lines only)")
+	format := flags.String("format", "", "jsonl or ocf (default from the --out extension)")
+	filterExprs := flags.StringArrayP("filter", "f", nil, "Filter the decoded value must match (repeatable, all must match)")
+	unmasked := flags.Bool("unmasked", false, "Write masked fields' real values")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *topic == "" || flags.NArg() != 0 {
+		return fmt.Errorf("%s", dumpUsage)
+	}
+
+	if *format == "" {
+		*format = "jsonl"
+		if ext := strings.ToLower(filepath.Ext(*out)); ext == ".avro" || ext == ".ocf" {
+			*format = "ocf"
+		}
+	}
+	if *format != "jsonl" && *format != "ocf" {
+		return fmt.Errorf("unknown format %q (want jsonl or ocf)", *format)
+	}
+	if *format == "ocf" && (*out == "" || *out == "-") {
+		return fmt.Errorf("--format ocf needs --out")
+	}
+
+	var sinceTime time.Time
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		sinceTime = t
+	}
+
+	filters := make([]*jsonpath.Filter, 0, len(*filterExprs))
+	for _, expr := range *filterExprs {
+		f, err := jsonpath.ParseFilter(expr)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, f)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+
+	// Pick up an interrupted dump of the same topic
+	checkpointPath := *out + ".checkpoint"
+	checkpoint := &dumpCheckpoint{Topic: *topic, Format: *format, Offsets: make(map[int]int64)}
+	resuming := false
+	if *out != "" && *out != "-" {
+		if data, err := os.ReadFile(checkpointPath); err == nil {
+			if err := yaml.Unmarshal(data, checkpoint); err != nil {
+				return fmt.Errorf("reading checkpoint %s: %w", checkpointPath, err)
+			}
+			if checkpoint.Topic != *topic || checkpoint.Format != *format {
+				return fmt.Errorf("%s is a checkpoint for a %s dump of %s; delete it to start over", checkpointPath, checkpoint.Format, checkpoint.Topic)
+			}
+			if checkpoint.Offsets == nil {
+				checkpoint.Offsets = make(map[int]int64)
+			}
+			resuming = true
+		} else if !os.IsNotExist(err) {
+			return fmt.Errorf("reading checkpoint %s: %w", checkpointPath, err)
+		}
+	}
+
+	ranges, total, err := planDump(cfg, *topic, sinceTime, checkpoint, resuming)
+	for _, r := range ranges {
+		defer r.consumer.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	// Open the output, cutting off anything written after the last checkpoint
+	var file *os.File
+	output := io.Writer(os.Stdout)
+	if *out != "" && *out != "-" {
+		if resuming {
+			file, err = os.OpenFile(*out, os.O_RDWR, 0644)
+			if err == nil {
+				err = file.Truncate(checkpoint.Size)
+			}
+			if err == nil && *format == "jsonl" {
+				// OCF appends find the end themselves after reading the header
+				_, err = file.Seek(0, io.SeekEnd)
+			}
+		} else {
+			file, err = os.Create(*out)
+		}
+		if err != nil {
+			return fmt.Errorf("opening %s: %w", *out, err)
+		}
+		defer file.Close()
+		output = file
+	}
+
+	client := newRegistryClient(cfg)
+	decoder := avro.NewWireDecoder(client.GetSchemaByID)
+	decoder.Transform = decryptTransform(cfg, csfle.New(client, cfg.KMS), decoder, *topic)
+	var masker *dumpMasker
+	if !*unmasked && (len(cfg.Mask.Fields) > 0 || len(cfg.Mask.Tags) > 0) {
+		masker = &dumpMasker{cfg: cfg.Mask, decoder: decoder, maskers: make(map[int]*mask.Masker)}
+	}
+	var writer dumpWriter
+	if *format == "ocf" {
+		writer = &ocfDumpWriter{file: file, decoder: decoder, checkpoint: checkpoint, masker: masker}
+	} else {
+		writer = &jsonlDumpWriter{w: bufio.NewWriter(output)}
+	}
+
+	if resuming {
+		fmt.Fprintf(os.Stderr, "resuming dump of %s into %s\n", *topic, *out)
+	}
+
+	var done, written, skipped int64
+	progress := func() {
+		pct := 100.0
+		if total > 0 {
+			pct = 100 * float64(done) / float64(total)
+		}
+		fmt.Fprintf(os.Stderr, "\r%d/%d offsets (%.0f%%), %d messages written", done, total, pct, written)
+	}
+
+	for _, r := range ranges {
+		partition := r.partition
+		next := r.start
+		for next < r.end {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			messages, err := r.consumer.FetchMessages(ctx, int(min(r.end-next, dumpChunkSize)))
+			cancel()
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("consuming partition %d of %s: %w", partition, *topic, err)
+			}
+			if len(messages) == 0 {
+				// The rest of the range was compacted or deleted
+				break
+			}
+
+			for _, msg := range messages {
+				if msg.Offset >= r.end {
+					break
+				}
+				rec := decodeDumpRecord(decoder, *topic, partition, msg)
+				if len(filters) > 0 && !matchesDumpFilters(rec, filters) {
+					continue
+				}
+				if *format == "jsonl" {
+					rec.text = masker.maskText(rec.schemaID, rec.text)
+				}
+				ok, err := writer.Write(rec)
+				if err != nil {
+					return fmt.Errorf("writing offset %d of partition %d: %w", msg.Offset, partition, err)
+				}
+				if ok {
+					written++
+				} else {
+					skipped++
+				}
+			}
+
+			last := messages[len(messages)-1].Offset + 1
+			done += min(last, r.end) - next
+			next = last
+			if err := writer.Flush(); err != nil {
+				return fmt.Errorf("writing %s: %w", *out, err)
+			}
+			if file != nil {
+				if err := saveDumpCheckpoint(checkpointPath, checkpoint, file, partition, next); err != nil {
+					return err
+				}
+			}
+			progress()
+		}
+	}
+	progress()
+	fmt.Fprintln(os.Stderr)
+
+	if file != nil {
+		os.Remove(checkpointPath)
+		fmt.Fprintf(os.Stderr, "wrote %d messages to %s\n", written, *out)
+	}
+	if skipped > 0 {
+		fmt.Fprintf(os.Stderr, "skipped %d messages that weren't wire-format Avro with the same schema as the first\n", skipped)
+	}
+
+	if err := hooks.Fire(cfg, hooks.TopicDumped, map[string]interface{}{
+		"topic":    *topic,
+		"out":      *out,
+		"format":   *format,
+		"messages": written,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
+// planDump opens a consumer per partition positioned at the start of its
+// range: the checkpointed offset when resuming, else the first offset at or
+// after since. Ranges end at the partition's current high watermark.
+func planDump(cfg *config.Config, topic string, since time.Time, checkpoint *dumpCheckpoint, resuming bool) ([]dumpRange, int64, error) {
+	var ranges []dumpRange
+
+	probe, err := kafka.NewConsumer(cfg, topic)
+	if err != nil {
+		return ranges, 0, err
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+	defer cancel()
+	partitions, err := probe.Partitions(ctx)
+	probe.Close()
+	if err != nil {
+		return ranges, 0, err
+	}
+
+	var total int64
+	for _, partition := range partitions {
+		consumer, err := kafka.NewPartitionConsumer(cfg, topic, partition)
+		if err != nil {
+			return ranges, 0, err
+		}
+		ranges = append(ranges, dumpRange{partition: partition, consumer: consumer})
+		r := &ranges[len(ranges)-1]
+
+		first, last, err := consumer.Watermarks(ctx)
+		if err != nil {
+			return ranges, 0, fmt.Errorf("partition %d: %w", partition, err)
+		}
+		r.start, r.end = first, last
+
+		if offset, ok := checkpoint.Offsets[partition]; resuming && ok {
+			r.start = offset
+		} else if !since.IsZero() {
+			if r.start, err = consumer.OffsetAt(ctx, since); err != nil {
+				return ranges, 0, fmt.Errorf("partition %d: %w", partition, err)
+			}
+		}
+		r.start = min(max(r.start, first), r.end)
+		checkpoint.Offsets[partition] = r.start
+
+		if r.start < r.end {
+			if err := consumer.SeekTo(r.start); err != nil {
+				return ranges, 0, fmt.Errorf("partition %d: %w", partition, err)
+			}
+		}
+		total += r.end - r.start
+	}
+	return ranges, total, nil
+}
+
+// saveDumpCheckpoint records a partition's next offset along with the output
+// size that includes everything before it
+func saveDumpCheckpoint(path string, checkpoint *dumpCheckpoint, file *os.File, partition int, next int64) error {
+	info, err := file.Stat()
+	if err != nil {
+		return fmt.Errorf("checkpointing: %w", err)
+	}
+	checkpoint.Size = info.Size()
+	checkpoint.Offsets[partition] = next
+
+	data, err := yaml.Marshal(checkpoint)
+	if err != nil {
+		return fmt.Errorf("checkpointing: %w", err)
+	}
+	if err := os.WriteFile(path, data, 0644); err != nil {
+		return fmt.Errorf("checkpointing: %w", err)
+	}
+	return nil
+}
+
+// parseSince reads an age before now (24h) or an absolute time
+func parseSince(s string, now time.Time) (time.Time, error) {
+	if d, err := time.ParseDuration(s); err == nil {
+		return now.Add(-d), nil
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02"} {
+		if t, err := time.ParseInLocation(layout, s, time.Local); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("--since %q is not an age (24h) or a time (2024-05-01T09:00:00Z)", s)
+}
+
+// decryptTransform decrypts documents consumed from topic with the rules of
+// the schema they were written with, under the subject the naming strategy
+// gives that schema's record
+func decryptTransform(cfg *config.Config, encryptor *csfle.Encryptor, decoder *avro.WireDecoder, topic string) func(int, interface{}) (interface{}, error) {
+	return func(schemaID int, doc interface{}) (interface{}, error) {
+		schema, err := decoder.Schema(schemaID)
+		if err != nil {
+			return doc, err
+		}
+		subject := cfg.SubjectFor(topic, avro.RecordName(schema))
+		return encryptor.DecryptByID(subject)(schemaID, doc)
+	}
+}
+
+func decodeDumpRecord(decoder *avro.WireDecoder, topic string, partition int, msg kafka.Message) dumpRecord {
+	rec := dumpRecord{topic: topic, partition: partition, msg: msg}
+	rec.key, _ = base64.StdEncoding.DecodeString(msg.Key)
+	rec.value, rec.err = base64.StdEncoding.DecodeString(msg.Value)
+	if rec.err != nil {
+		return rec
+	}
+	rec.schemaID, _, _ = avro.SplitWireFormat(rec.value)
+	rec.text, _, rec.err = decoder.Decode(rec.value)
+	return rec
+}
+
+func matchesDumpFilters(rec dumpRecord, filters []*jsonpath.Filter) bool {
+	if rec.err != nil {
+		return false
+	}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(rec.text), &doc); err != nil {
+		return false
+	}
+	return jsonpath.MatchAll(filters, doc)
+}
+
+// jsonlDumpWriter writes one JSON object per message
+type jsonlDumpWriter struct {
+	w *bufio.Writer
+}
+
+func (j *jsonlDumpWriter) Write(rec dumpRecord) (bool, error) {
+	line := map[string]interface{}{
+		"topic":     rec.topic,
+		"partition": rec.partition,
+		"offset":    rec.msg.Offset,
+		"timestamp": rec.msg.Timestamp.Format(time.RFC3339Nano),
+	}
+	if len(rec.key) > 0 {
+		if utf8.Valid(rec.key) {
+			line["key"] = string(rec.key)
+		} else {
+			line["key_base64"] = rec.msg.Key
+		}
+	}
+	if len(rec.msg.Headers) > 0 {
+		line["headers"] = rec.msg.Headers
+	}
+	if rec.schemaID != 0 {
+		line["schema_id"] = rec.schemaID
+	}
+	if rec.err != nil {
+		line["value_base64"] = rec.msg.Value
+		line["error"] = rec.err.Error()
+	} else {
+		line["value"] = json.RawMessage(rec.text)
+	}
+
+	data, err := json.Marshal(line)
+	if err != nil {
+		return false, err
+	}
+	j.w.Write(data)
+	return true, j.w.WriteByte('\n')
+}
+
+func (j *jsonlDumpWriter) Flush() error {
+	return j.w.Flush()
+}
+
+// ocfDumpWriter writes messages into an Avro object container file. Each
+// record wraps the value, in its writer schema, with the message metadata.
+// The file holds a single schema, so it is fixed by the first message.
+type ocfDumpWriter struct {
+	file       *os.File
+	decoder    *avro.WireDecoder
+	checkpoint *dumpCheckpoint
+	ocf        *goavro.OCFWriter
+	value      *goavro.Codec
+	masker     *dumpMasker
+	pending    []interface{}
+}
+
+func (o *ocfDumpWriter) Write(rec dumpRecord) (bool, error) {
+	if rec.err != nil || rec.schemaID == 0 {
+		return false, nil
+	}
+	if o.checkpoint.SchemaID == 0 {
+		o.checkpoint.SchemaID = rec.schemaID
+	}
+	if rec.schemaID != o.checkpoint.SchemaID {
+		return false, nil
+	}
+	if err := o.open(); err != nil {
+		return false, err
+	}
+
+	_, payload, _ := avro.SplitWireFormat(rec.value)
+	value, _, err := o.value.NativeFromBinary(payload)
+	if err != nil {
+		return false, err
+	}
+	if o.masker != nil {
+		value = o.masker.forSchema(rec.schemaID).Apply(value)
+		if _, err := o.value.BinaryFromNative(nil, value); err != nil {
+			return false, fmt.Errorf("masked value no longer fits its schema (a masked enum or fixed field?), use --unmasked: %w", err)
+		}
+	}
+	var key interface{}
+	if len(rec.key) > 0 {
+		key = goavro.Union("bytes", rec.key)
+	}
+	headers := make(map[string]interface{}, len(rec.msg.Headers))
+	for k, v := range rec.msg.Headers {
+		headers[k] = v
+	}
+
+	o.pending = append(o.pending, map[string]interface{}{
+		"topic":     rec.topic,
+		"partition": int32(rec.partition),
+		"offset":    rec.msg.Offset,
+		"timestamp": rec.msg.Timestamp,
+		"key":       key,
+		"headers":   headers,
+		"value":     value,
+	})
+	return true, nil
+}
+
+// open creates the container writer once the value schema is known. When
+// resuming, goavro reads the existing header and appends after it.
+func (o *ocfDumpWriter) open() error {
+	if o.ocf != nil {
+		return nil
+	}
+	schema, err := o.decoder.Schema(o.checkpoint.SchemaID)
+	if err != nil {
+		return err
+	}
+	if o.value, err = goavro.NewCodec(schema); err != nil {
+		return fmt.Errorf("parsing schema %d: %w", o.checkpoint.SchemaID, err)
+	}
+
+	envelope := fmt.Sprintf(`{"type": "record", "name": "DumpRecord", "namespace": "avrocado.dump", "fields": [
+		{"name": "topic", "type": "string"},
+		{"name": "partition", "type": "int"},
+		{"name": "offset", "type": "long"},
+		{"name": "timestamp", "type": {"type": "long", "logicalType": "timestamp-millis"}},
+		{"name": "key", "type": ["null", "bytes"]},
+		{"name": "headers", "type": {"type": "map", "values": "string"}},
+		{"name": "value", "type": %s}
+	]}`, schema)
+	o.ocf, err = goavro.NewOCFWriter(goavro.OCFConfig{W: o.file, Schema: envelope, CompressionName: goavro.CompressionSnappyLabel})
+	if err != nil {
+		return err
+	}
+	return nil
+}
+
+// Flush writes the buffered records as one container block
+func (o *ocfDumpWriter) Flush() error {
+	if len(o.pending) == 0 {
+		return nil
+	}
+	err := o.ocf.Append(o.pending)
+	o.pending = o.pending[:0]
+	return err
+}
+
+// dumpMasker hides masked fields in dumped values, matching tags against
+// each message's writer schema. A nil dumpMasker masks nothing.
+type dumpMasker struct {
+	cfg     config.MaskConfig
+	decoder *avro.WireDecoder
+	maskers map[int]*mask.Masker
+}
+
+func (d *dumpMasker) forSchema(id int) *mask.Masker {
+	if m, ok := d.maskers[id]; ok {
+		return m
+	}
+	schema, _ := d.decoder.Schema(id)
+	m := mask.New(d.cfg, schema)
+	d.maskers[id] = m
+	return m
+}
+
+// maskText masks a decoded JSON value. Values that can't be parsed are
+// replaced entirely rather than risk leaking them.
+func (d *dumpMasker) maskText(schemaID int, text string) string {
+	if d == nil || text == "" {
+		return text
+	}
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return strconv.Quote(mask.Placeholder)
+	}
+	masked, err := json.Marshal(d.forSchema(schemaID).Apply(doc))
+	if err != nil {
+		return strconv.Quote(mask.Placeholder)
+	}
+	return string(masked)
+}
@@ -0,0 +1,113 @@
+package main
+
+import (
+	"context"
+	"encoding/base64"
+	"errors"
+	"fmt"
+	"os"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/csfle"
+	"github.com/JimmyyyW/avrocado/internal/jsonpath"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+const expectUsage = `Usage: avrocado expect --topic <topic> [--filter <expr>]... [flags]
+
+Consumes a topic until a decoded message matches every filter, prints it and
+exits 0. Exits 1 if no message matches before --timeout.
+
+Filters compare a JSONPath into the decoded value with a JSON literal:
+  --filter '$.orderId == "X"'   --filter '$.amount >= 100'   --filter '$.refund'
+A filter without an operator matches when the field is present and not null.
+
+Only messages produced after the command starts are considered, unless
+--from-beginning is given.`
+
+func runExpectCommand(args []string) error {
+	flags := pflag.NewFlagSet("expect", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, expectUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	topic := flags.StringP("topic", "t", "", "Topic to consume")
+	filterExprs := flags.StringArrayP("filter", "f", nil, "Filter the decoded value must match (repeatable, all must match)")
+	timeout := flags.Duration("timeout", 30*time.Second, "How long to wait for a matching message")
+	fromBeginning := flags.Bool("from-beginning", false, "Also match messages already on the topic")
+	quiet := flags.BoolP("quiet", "q", false, "Don't print the matching message")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *topic == "" || flags.NArg() != 0 {
+		return fmt.Errorf("%s", expectUsage)
+	}
+
+	filters := make([]*jsonpath.Filter, 0, len(*filterExprs))
+	for _, expr := range *filterExprs {
+		f, err := jsonpath.ParseFilter(expr)
+		if err != nil {
+			return err
+		}
+		filters = append(filters, f)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+
+	consumer, err := kafka.NewConsumer(cfg, *topic)
+	if err != nil {
+		return err
+	}
+	defer consumer.Close()
+	if !*fromBeginning {
+		if err := consumer.SeekToEnd(context.Background()); err != nil {
+			return fmt.Errorf("seeking to end of %s: %w", *topic, err)
+		}
+	}
+
+	ctx, cancel := context.WithTimeout(context.Background(), *timeout)
+	defer cancel()
+
+	client := newRegistryClient(cfg)
+	decoder := avro.NewWireDecoder(client.GetSchemaByID)
+	decoder.Transform = decryptTransform(cfg, csfle.New(client, cfg.KMS), decoder, *topic)
+	seen := 0
+	for ctx.Err() == nil {
+		messages, err := consumer.FetchMessages(ctx, 1)
+		if err != nil {
+			if errors.Is(err, context.DeadlineExceeded) {
+				break
+			}
+			return fmt.Errorf("consuming %s: %w", *topic, err)
+		}
+
+		for _, msg := range messages {
+			seen++
+			data, err := base64.StdEncoding.DecodeString(msg.Value)
+			if err != nil {
+				continue
+			}
+			value, doc, err := decoder.Decode(data)
+			if err != nil {
+				fmt.Fprintf(os.Stderr, "offset %d: %v\n", msg.Offset, err)
+				continue
+			}
+			if !jsonpath.MatchAll(filters, doc) {
+				continue
+			}
+
+			fmt.Fprintf(os.Stderr, "matched offset %d after %d messages\n", msg.Offset, seen)
+			if !*quiet {
+				fmt.Println(value)
+			}
+			return nil
+		}
+	}
+
+	return fmt.Errorf("no matching message on %s within %s (%d messages checked)", *topic, *timeout, seen)
+}
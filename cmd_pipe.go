@@ -0,0 +1,404 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/batch"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+const pipeUsage = `Usage: avrocado pipe --from <topic> --to <topic> (--jq <expr> | --jq-file <file> | --map) [flags]
+
+Consumes a topic up to its current end, transforms each decoded value and
+produces the result to another topic, re-encoded against the latest schema of
+its subject (<to>-value, or as the subject naming strategy says, unless
+--subject is given). Keys and headers are kept.
+
+Values are transformed as plain JSON, with union values unwrapped:
+  --jq       a jq expression (needs jq on the PATH); select() drops messages
+             and an expression with several results produces each of them
+  --map      the field mapping from each message's schema to the destination
+             schema, as shown by avrocado schema map
+
+--dry-run prints the transformed values as JSON lines and checks they encode,
+without producing anything. Use --limit to preview only the first few.
+
+--on-error decides what a message that fails to convert, or that the broker
+rejects, does to the pipe: stop (the default) ends it, skip leaves the message
+out, and retry=N produces rejected messages again up to N times before
+leaving them out. Failed messages are listed at the end.`
+
+// pipeBatchSize is how many messages are produced at once
+const pipeBatchSize = 100
+
+// pipeTransform turns one plain JSON value into zero or more values
+type pipeTransform func(schemaID int, value string) ([]string, error)
+
+func runPipeCommand(args []string) error {
+	flags := pflag.NewFlagSet("pipe", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, pipeUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	from := flags.String("from", "", "Topic to consume")
+	to := flags.String("to", "", "Topic to produce to")
+	subject := flags.String("subject", "", "Subject whose latest schema values are encoded with (default <to>-value)")
+	jqExpr := flags.String("jq", "", "jq expression transforming each value")
+	jqFile := flags.String("jq-file", "", "File holding the jq program")
+	useMap := flags.Bool("map", false, "Convert values with the field mapping between the schemas")
+	since := flags.String("since", "", "Only messages newer than an age (24h) or time (RFC 3339 or 2006-01-02)")
+	limit := flags.Int("limit", 0, "Stop after this many consumed messages (0 for all)")
+	dryRun := flags.BoolP("dry-run", "n", false, "Print the transformed values instead of producing them")
+	onError := flags.String("on-error", "stop", "Failure policy: stop, skip or retry=N")
+	yes := flags.BoolP("yes", "y", false, "Allow producing into a production profile")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *from == "" || *to == "" || flags.NArg() != 0 {
+		return fmt.Errorf("%s", pipeUsage)
+	}
+	transforms := 0
+	for _, set := range []bool{*jqExpr != "", *jqFile != "", *useMap} {
+		if set {
+			transforms++
+		}
+	}
+	if transforms != 1 {
+		return fmt.Errorf("give exactly one of --jq, --jq-file or --map")
+	}
+	policy, err := batch.ParsePolicy(*onError)
+	if err != nil {
+		return err
+	}
+
+	var sinceTime time.Time
+	if *since != "" {
+		t, err := parseSince(*since, time.Now())
+		if err != nil {
+			return err
+		}
+		sinceTime = t
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	if *subject == "" {
+		if *subject = cfg.SubjectFor(*to, ""); *subject == "" {
+			return fmt.Errorf("pass --subject: it can't be told from the topic under the %s naming strategy", cfg.NamingStrategy())
+		}
+	}
+	if cfg.Production && !*dryRun && !*yes {
+		return fmt.Errorf("profile %q is marked production; pass --yes to produce into it", cfg.Profile)
+	}
+
+	client := newRegistryClient(cfg)
+	if err := cfg.CheckSubject(*subject); err != nil {
+		return err
+	}
+	schema, err := client.GetLatestSchema(*subject)
+	if err != nil {
+		return fmt.Errorf("fetching schema for %s: %w", *subject, err)
+	}
+
+	var transform pipeTransform
+	switch {
+	case *useMap:
+		transform = mapTransform(client, schema.Schema)
+	default:
+		program := *jqExpr
+		if *jqFile != "" {
+			data, err := os.ReadFile(*jqFile)
+			if err != nil {
+				return fmt.Errorf("reading jq program: %w", err)
+			}
+			program = string(data)
+		}
+		jq, err := startJQ(program)
+		if err != nil {
+			return err
+		}
+		defer jq.Close()
+		transform = func(_ int, value string) ([]string, error) {
+			return jq.Run(value)
+		}
+	}
+
+	p := &piper{
+		topic:     *to,
+		schema:    schema,
+		transform: transform,
+		dryRun:    *dryRun,
+		report:    batch.NewReport(policy),
+		out:       bufio.NewWriter(os.Stdout),
+	}
+	if !*dryRun {
+		if p.producer, err = kafka.NewProducer(cfg); err != nil {
+			return err
+		}
+		defer p.producer.Close()
+	}
+
+	ranges, _, err := planDump(cfg, *from, sinceTime, &dumpCheckpoint{Offsets: make(map[int]int64)}, false)
+	for _, r := range ranges {
+		defer r.consumer.Close()
+	}
+	if err != nil {
+		return err
+	}
+
+	decoder := avro.NewWireDecoder(client.GetSchemaByID)
+	err = p.consume(ranges, decoder, *from, *limit)
+	if err == nil {
+		err = p.flush()
+	}
+	if !*dryRun {
+		fmt.Fprintln(os.Stderr)
+	}
+	if len(p.report.Failures) > 0 {
+		fmt.Fprint(os.Stderr, p.report.Summary())
+	}
+	if err != nil {
+		return err
+	}
+
+	verb := "produced"
+	if *dryRun {
+		verb = "would produce"
+	}
+	fmt.Fprintf(os.Stderr, "consumed %d messages from %s, %s %d to %s with schema %s v%d (ID %d)\n",
+		p.consumed, *from, verb, p.report.Sent, *to, *subject, schema.Version, schema.ID)
+
+	if !*dryRun {
+		if err := hooks.Fire(cfg, hooks.TopicPiped, map[string]interface{}{
+			"from":      *from,
+			"topic":     *to,
+			"subject":   *subject,
+			"schema_id": schema.ID,
+			"messages":  p.report.Sent,
+			"failed":    len(p.report.Failures),
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return nil
+}
+
+// piper transforms consumed messages and produces, or previews, the results
+type piper struct {
+	producer  *kafka.Producer
+	topic     string
+	schema    *registry.SchemaResponse
+	transform pipeTransform
+	dryRun    bool
+	report    *batch.Report
+	out       *bufio.Writer // Dry run output
+
+	batch    []kafka.Record
+	labels   []string // Where each batched record came from, for the report
+	consumed int
+}
+
+func (p *piper) consume(ranges []dumpRange, decoder *avro.WireDecoder, topic string, limit int) error {
+	for _, r := range ranges {
+		next := r.start
+		for next < r.end {
+			ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+			messages, err := r.consumer.FetchMessages(ctx, int(min(r.end-next, dumpChunkSize)))
+			cancel()
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				return fmt.Errorf("consuming partition %d of %s: %w", r.partition, topic, err)
+			}
+			if len(messages) == 0 {
+				break
+			}
+
+			for _, msg := range messages {
+				if msg.Offset >= r.end {
+					break
+				}
+				if limit > 0 && p.consumed >= limit {
+					return nil
+				}
+				p.consumed++
+				rec := decodeDumpRecord(decoder, topic, r.partition, msg)
+				if err := p.pipe(rec); err != nil {
+					return err
+				}
+				if len(p.batch) >= pipeBatchSize {
+					if err := p.flush(); err != nil {
+						return err
+					}
+				}
+			}
+			next = messages[len(messages)-1].Offset + 1
+		}
+	}
+	return nil
+}
+
+// pipe transforms one message and queues or prints the results. Messages
+// that fail to convert go to the report, which says whether to stop.
+func (p *piper) pipe(rec dumpRecord) error {
+	where := fmt.Sprintf("partition %d offset %d", rec.partition, rec.msg.Offset)
+	if rec.err != nil {
+		return p.report.Invalid(where, rec.err)
+	}
+	values, err := p.transform(rec.schemaID, rec.text)
+	if err != nil {
+		return p.report.Invalid(where, err)
+	}
+
+	for _, value := range values {
+		binary, err := avro.EncodeStandard(p.schema.Schema, value)
+		if err != nil {
+			return p.report.Invalid(where, fmt.Errorf("doesn't fit %s v%d: %w", p.schema.Subject, p.schema.Version, err))
+		}
+		if p.dryRun {
+			line, _ := json.Marshal(map[string]interface{}{
+				"partition": rec.partition,
+				"offset":    rec.msg.Offset,
+				"value":     json.RawMessage(value),
+			})
+			p.out.Write(append(line, '\n'))
+			p.report.Sent++
+			continue
+		}
+		p.batch = append(p.batch, kafka.Record{Key: rec.key, Value: binary, Headers: rec.msg.Headers})
+		p.labels = append(p.labels, where)
+	}
+	return nil
+}
+
+func (p *piper) flush() error {
+	if p.dryRun {
+		return p.out.Flush()
+	}
+	if len(p.batch) == 0 {
+		return nil
+	}
+	err := p.report.Produce(context.Background(), p.producer, p.topic, p.schema.ID, p.batch, p.labels)
+	p.batch = p.batch[:0]
+	p.labels = p.labels[:0]
+	fmt.Fprintf(os.Stderr, "\r%d messages produced", p.report.Sent)
+	return err
+}
+
+// mapTransform converts values with the field mapping from their writer
+// schema to the target schema, building each mapping once
+func mapTransform(client *registry.Client, target string) pipeTransform {
+	mappings := make(map[int]*avro.FieldMapping)
+	return func(schemaID int, value string) ([]string, error) {
+		mapping, ok := mappings[schemaID]
+		if !ok {
+			source, err := client.GetSchemaByID(schemaID)
+			if err != nil {
+				return nil, fmt.Errorf("fetching schema %d: %w", schemaID, err)
+			}
+			if mapping, err = avro.MapFields(source, target); err != nil {
+				return nil, err
+			}
+			mappings[schemaID] = mapping
+		}
+		out, err := mapping.Convert(value)
+		if err != nil {
+			return nil, err
+		}
+		return []string{out}, nil
+	}
+}
+
+// jqProcess runs one jq for the whole pipe. The program is wrapped so every
+// input yields exactly one line: all of its results, or the error it raised.
+type jqProcess struct {
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+	stderr *strings.Builder
+}
+
+func startJQ(program string) (*jqProcess, error) {
+	path, err := exec.LookPath("jq")
+	if err != nil {
+		return nil, fmt.Errorf("--jq needs jq installed: %w", err)
+	}
+	wrapped := "try {ok: [ " + program + "\n]} catch {error: .}"
+
+	// Check the program compiles first, since once running jq only reports
+	// that when the first value is written
+	if out, err := exec.Command(path, "-n", wrapped).CombinedOutput(); err != nil {
+		return nil, fmt.Errorf("invalid jq program: %s", strings.TrimSpace(string(out)))
+	}
+
+	cmd := exec.Command(path, "--unbuffered", "-c", wrapped)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return nil, err
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return nil, err
+	}
+	stderr := &strings.Builder{}
+	cmd.Stderr = stderr
+	if err := cmd.Start(); err != nil {
+		return nil, fmt.Errorf("starting jq: %w", err)
+	}
+	return &jqProcess{cmd: cmd, stdin: stdin, stdout: bufio.NewReaderSize(stdout, 1024*1024), stderr: stderr}, nil
+}
+
+// Run transforms one value
+func (j *jqProcess) Run(value string) ([]string, error) {
+	if _, err := io.WriteString(j.stdin, strings.ReplaceAll(value, "\n", " ")+"\n"); err != nil {
+		return nil, j.failed(err)
+	}
+	line, err := j.stdout.ReadBytes('\n')
+	if err != nil {
+		return nil, j.failed(err)
+	}
+	var result struct {
+		OK    []json.RawMessage `json:"ok"`
+		Error interface{}       `json:"error"`
+	}
+	if err := json.Unmarshal(line, &result); err != nil {
+		return nil, fmt.Errorf("reading jq output: %w", err)
+	}
+	if result.Error != nil {
+		return nil, fmt.Errorf("jq: %v", result.Error)
+	}
+	values := make([]string, len(result.OK))
+	for i, r := range result.OK {
+		values[i] = string(r)
+	}
+	return values, nil
+}
+
+// failed explains a broken pipe with what jq reported, such as a compile
+// error in the program or a runtime error on a value
+func (j *jqProcess) failed(err error) error {
+	j.stdin.Close()
+	j.cmd.Wait()
+	if msg := strings.TrimSpace(j.stderr.String()); msg != "" {
+		return fmt.Errorf("jq: %s", msg)
+	}
+	return fmt.Errorf("jq: %w", err)
+}
+
+func (j *jqProcess) Close() error {
+	j.stdin.Close()
+	return j.cmd.Wait()
+}
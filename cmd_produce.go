@@ -0,0 +1,244 @@
+package main
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/csfle"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/idempotency"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/keys"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+const produceUsage = `Usage: avrocado produce --subject <subject> (--file <payload.json> | --value <json>) [flags]
+
+Validates a JSON payload against the latest schema of a subject and produces
+it to the subject's topic (the profile's topic for the subject, else the
+one the subject naming strategy gives it, unless --topic is given), as send
+mode does. Under the record strategy the topic must be given. --file -
+reads the payload from stdin.
+
+Payloads are Avro JSON, so union values are wrapped as in the templates
+avrocado generates. Subjects with JSON Schemas take plain JSON, validated
+against the schema and sent as JSON behind the wire header. Fields the schema tags for encryption are encrypted, and
+--fresh-id writes a new idempotency key where the topic's idempotency config
+says.
+
+--key is encoded with the topic's key schema if the registry has a
+<topic>-key subject, and sent as a raw string otherwise.
+
+--dry-run only validates the payload, exiting 1 if it doesn't fit the
+schema, so a payload can be checked in CI without producing it.`
+
+// produceTimeout bounds the produce request
+const produceTimeout = 10 * time.Second
+
+func runProduceCommand(args []string) error {
+	flags := pflag.NewFlagSet("produce", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, produceUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	subject := flags.StringP("subject", "s", "", "Subject whose latest schema the payload is validated and encoded with")
+	topic := flags.StringP("topic", "t", "", "Topic to produce to (default from the subject)")
+	file := flags.StringP("file", "f", "", "Payload file, or - for stdin")
+	value := flags.String("value", "", "Payload JSON")
+	key := flags.StringP("key", "k", "", "Message key")
+	headerFlags := flags.StringArrayP("header", "H", nil, "Header as name=value (repeatable)")
+	freshID := flags.Bool("fresh-id", false, "Write a new idempotency key where the topic's idempotency config says")
+	dryRun := flags.Bool("dry-run", false, "Validate the payload without producing it")
+	yes := flags.BoolP("yes", "y", false, "Allow producing to a production profile")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *subject == "" || (*file == "") == (*value == "") || flags.NArg() != 0 {
+		return fmt.Errorf("%s", produceUsage)
+	}
+	headers, err := parseHeaders(*headerFlags)
+	if err != nil {
+		return err
+	}
+
+	payload := *value
+	if *file != "" {
+		if payload, err = readPayload(*file); err != nil {
+			return err
+		}
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	if cfg.Production && !*yes && !*dryRun {
+		return fmt.Errorf("profile %q is marked production; pass --yes to produce to it", cfg.Profile)
+	}
+	if *topic == "" {
+		*topic = cfg.TopicFor(*subject)
+	}
+	if *topic == "" {
+		return fmt.Errorf("no topic for %s under the %s naming strategy; pass --topic or set one under the profile's topics", *subject, cfg.NamingStrategy())
+	}
+
+	if err := cfg.CheckSubject(*subject); err != nil {
+		return err
+	}
+	client := newRegistryClient(cfg)
+	schema, err := client.GetLatestSchema(*subject)
+	if err != nil {
+		return fmt.Errorf("fetching schema for %s: %w", *subject, err)
+	}
+
+	var idKey string
+	if *freshID {
+		rule, ok := cfg.IdempotencyFor(*topic)
+		if !ok {
+			return fmt.Errorf("no idempotency config for %s", *topic)
+		}
+		idKey = idempotency.NewKey()
+		if payload, headers, err = idempotency.Inject(rule, payload, headers, idKey); err != nil {
+			return err
+		}
+	}
+
+	binary, err := encodePayload(csfle.New(client, cfg.KMS), schema, payload)
+	if err != nil {
+		return fmt.Errorf("payload doesn't fit %s v%d: %w", *subject, schema.Version, err)
+	}
+	var keySchema *registry.SchemaResponse
+	if *key != "" {
+		if keySchema, err = keys.Schema(cfg, client, *topic); err != nil {
+			return err
+		}
+	}
+	keyBytes, err := keys.Encode(keySchema, *key, cfg.Unframed())
+	if err != nil {
+		return err
+	}
+	if *dryRun {
+		fmt.Fprintf(os.Stderr, "payload is valid for %s v%d (ID %d)\n", *subject, schema.Version, schema.ID)
+		return nil
+	}
+
+	producer, err := kafka.NewProducer(cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	ctx, cancel := context.WithTimeout(context.Background(), produceTimeout)
+	defer cancel()
+	// A failed probe is left for the produce itself to report
+	if probe, _ := producer.CheckWrite(ctx, *topic); probe != nil {
+		if probe.Denied {
+			return probe
+		}
+		fmt.Fprintf(os.Stderr, "warning: %s\n", probe.Reason)
+	}
+	start := time.Now()
+	if err := producer.ProduceRecord(ctx, *topic, schema.ID, kafka.Record{Key: keyBytes, Value: binary, Headers: headers}); err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "produced to %s with schema %s v%d (ID %d), ack in %s\n", *topic, *subject, schema.Version, schema.ID, time.Since(start).Round(time.Millisecond))
+	if idKey != "" {
+		fmt.Fprintf(os.Stderr, "idempotency key %s\n", idKey)
+	}
+
+	if err := hooks.Fire(cfg, hooks.MessageProduced, map[string]interface{}{
+		"topic":          *topic,
+		"subject":        *subject,
+		"schema_id":      schema.ID,
+		"schema_version": schema.Version,
+		"key":            *key,
+		"payload":        payload,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
+// readPayload reads a payload file, or stdin for -
+func readPayload(file string) (string, error) {
+	var data []byte
+	var err error
+	if file == "-" {
+		data, err = io.ReadAll(os.Stdin)
+	} else {
+		data, err = os.ReadFile(file)
+	}
+	if err != nil {
+		return "", err
+	}
+	return string(data), nil
+}
+
+// parseHeaders reads name=value header flags
+func parseHeaders(flags []string) (map[string]string, error) {
+	if len(flags) == 0 {
+		return nil, nil
+	}
+	headers := make(map[string]string, len(flags))
+	for _, h := range flags {
+		name, value, ok := strings.Cut(h, "=")
+		if !ok || name == "" {
+			return nil, fmt.Errorf("invalid --header %q (want name=value)", h)
+		}
+		headers[name] = value
+	}
+	return headers, nil
+}
+
+// encodePayload validates a payload against schema and returns the value
+// bytes to produce: Avro binary with tagged fields encrypted, or compact JSON
+// for a JSON Schema
+func encodePayload(encryptor *csfle.Encryptor, schema *registry.SchemaResponse, payload string) ([]byte, error) {
+	if schema.IsJSONSchema() {
+		validator, err := jsonschema.Compile(schema.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+		}
+		return validator.Encode(payload)
+	}
+
+	encrypted, err := encryptPayload(encryptor, schema, payload)
+	if err != nil {
+		return nil, err
+	}
+	return avro.ValidateAndEncode(schema.Schema, encrypted)
+}
+
+// encryptPayload encrypts the fields the schema's rules tag for encryption
+func encryptPayload(encryptor *csfle.Encryptor, schema *registry.SchemaResponse, payload string) (string, error) {
+	rules := csfle.EncryptRules(schema.RuleSet)
+	if len(rules) == 0 {
+		return payload, nil
+	}
+
+	dec := json.NewDecoder(bytes.NewReader([]byte(payload)))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	doc, err := encryptor.Encrypt(schema.Subject, schema.Schema, rules, doc)
+	if err != nil {
+		return "", fmt.Errorf("encrypting fields: %w", err)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
@@ -0,0 +1,393 @@
+package main
+
+import (
+	"bufio"
+	"context"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/linkedin/goavro/v2"
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/batch"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/csfle"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/idempotency"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+const replayUsage = `Usage: avrocado replay --file <dump> --topic <topic> [--speed 2x | --as-fast-as-possible] [flags]
+
+Produces the messages of a dump file (JSON lines or OCF, as written by
+avrocado dump) to a topic, keeping their keys and headers. Values are
+re-encoded against the latest schema of the destination subject
+(<topic>-value, or as the subject naming strategy says, unless --subject is
+given), Avro or JSON Schema, encrypting the fields its rules tag for
+encryption as produce does.
+
+By default messages are spaced by their original timestamps; --speed scales
+that (2x replays twice as fast) and --as-fast-as-possible sends them in
+batches without waiting.
+
+--fresh-ids gives each message a new idempotency key (a random UUID) in the
+payload field and/or header configured for the topic under idempotency, or
+given with --id-field and --id-header, so consumers that deduplicate don't
+drop the replayed messages.
+
+--on-error decides what a message that doesn't fit the schema, or that the
+broker rejects, does to the replay: stop (the default) ends it, skip leaves
+the message out, and retry=N produces rejected messages again up to N times
+before leaving them out. Failed messages are listed at the end.`
+
+// replayBatchSize is how many messages are written at once without timing
+const replayBatchSize = 100
+
+// replayMessage is one dumped message to produce again
+type replayMessage struct {
+	offset    int64
+	timestamp time.Time
+	key       []byte
+	headers   map[string]string
+	value     string // Plain JSON
+}
+
+func runReplayCommand(args []string) error {
+	flags := pflag.NewFlagSet("replay", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, replayUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	file := flags.StringP("file", "f", "", "Dump file to replay (.jsonl, or .avro/.ocf)")
+	topic := flags.StringP("topic", "t", "", "Destination topic")
+	subject := flags.String("subject", "", "Subject whose latest schema values are encoded with (default <topic>-value)")
+	speedFlag := flags.String("speed", "1x", "Replay speed relative to the original timing (2x, 0.5x)")
+	fast := flags.Bool("as-fast-as-possible", false, "Ignore the original timing")
+	onError := flags.String("on-error", "stop", "Failure policy: stop, skip or retry=N")
+	freshIDs := flags.Bool("fresh-ids", false, "Give each message a new idempotency key where the topic's idempotency config says")
+	idField := flags.String("id-field", "", "Payload field (dotted path) to write a new idempotency key to")
+	idHeader := flags.String("id-header", "", "Header to write a new idempotency key to")
+	yes := flags.BoolP("yes", "y", false, "Allow replaying into a production profile")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *file == "" || *topic == "" || flags.NArg() != 0 {
+		return fmt.Errorf("%s", replayUsage)
+	}
+	speed, err := parseSpeed(*speedFlag)
+	if err != nil {
+		return err
+	}
+	policy, err := batch.ParsePolicy(*onError)
+	if err != nil {
+		return err
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	if *subject == "" {
+		if *subject = cfg.SubjectFor(*topic, ""); *subject == "" {
+			return fmt.Errorf("pass --subject: it can't be told from the topic under the %s naming strategy", cfg.NamingStrategy())
+		}
+	}
+	if cfg.Production && !*yes {
+		return fmt.Errorf("profile %q is marked production; pass --yes to replay into it", cfg.Profile)
+	}
+
+	var fresh *config.IdempotencyConfig
+	if *freshIDs || *idField != "" || *idHeader != "" {
+		rule, ok := cfg.IdempotencyFor(*topic)
+		if *idField != "" || *idHeader != "" {
+			rule, ok = config.IdempotencyConfig{Field: *idField, Header: *idHeader}, true
+		}
+		if !ok {
+			return fmt.Errorf("no idempotency config for %s; configure it or pass --id-field / --id-header", *topic)
+		}
+		fresh = &rule
+	}
+
+	if err := cfg.CheckSubject(*subject); err != nil {
+		return err
+	}
+	client := newRegistryClient(cfg)
+	schema, err := client.GetLatestSchema(*subject)
+	if err != nil {
+		return fmt.Errorf("fetching schema for %s: %w", *subject, err)
+	}
+	producer, err := kafka.NewProducer(cfg)
+	if err != nil {
+		return err
+	}
+	defer producer.Close()
+
+	r := &replayer{
+		producer:  producer,
+		topic:     *topic,
+		schema:    schema,
+		encryptor: csfle.New(client, cfg.KMS),
+		speed:     speed,
+		fast:      *fast,
+		fresh:     fresh,
+		report:    batch.NewReport(policy),
+	}
+
+	ext := strings.ToLower(filepath.Ext(*file))
+	if ext == ".avro" || ext == ".ocf" {
+		err = readOCFDump(*file, r.send)
+	} else {
+		err = readJSONLDump(*file, r.send)
+	}
+	if err == nil {
+		err = r.flush()
+	}
+	fmt.Fprintln(os.Stderr)
+	if len(r.report.Failures) > 0 {
+		fmt.Fprint(os.Stderr, r.report.Summary())
+	}
+	if err != nil {
+		return err
+	}
+
+	fmt.Fprintf(os.Stderr, "replayed %d messages to %s with schema %s v%d (ID %d)\n", r.report.Sent, *topic, *subject, schema.Version, schema.ID)
+	if r.skipped > 0 {
+		fmt.Fprintf(os.Stderr, "skipped %d messages dumped without a decoded value\n", r.skipped)
+	}
+
+	if err := hooks.Fire(cfg, hooks.TopicReplayed, map[string]interface{}{
+		"file":      *file,
+		"topic":     *topic,
+		"subject":   *subject,
+		"schema_id": schema.ID,
+		"messages":  r.report.Sent,
+		"failed":    len(r.report.Failures),
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return nil
+}
+
+// parseSpeed reads a replay speed such as "2x", "0.5x" or "3"
+func parseSpeed(s string) (float64, error) {
+	speed, err := strconv.ParseFloat(strings.TrimSuffix(strings.ToLower(s), "x"), 64)
+	if err != nil || speed <= 0 {
+		return 0, fmt.Errorf("invalid --speed %q (want e.g. 2x or 0.5x)", s)
+	}
+	return speed, nil
+}
+
+// replayer re-encodes dumped messages and produces them, either on the
+// original schedule scaled by speed or in batches as fast as possible
+type replayer struct {
+	producer  *kafka.Producer
+	topic     string
+	schema    *registry.SchemaResponse
+	encryptor *csfle.Encryptor
+	speed     float64
+	fast      bool
+	fresh     *config.IdempotencyConfig // Where to write new idempotency keys, if at all
+
+	// Timing: when the first message was sent and its original timestamp
+	started time.Time
+	first   time.Time
+
+	batch   []kafka.Record
+	labels  []string // Where each batched record came from, for the report
+	report  *batch.Report
+	skipped int
+}
+
+func (r *replayer) send(msg replayMessage) error {
+	if msg.value == "" {
+		r.skipped++
+		return nil
+	}
+	label := fmt.Sprintf("offset %d", msg.offset)
+	if r.fresh != nil {
+		var err error
+		if msg.value, msg.headers, err = idempotency.Inject(*r.fresh, msg.value, msg.headers, idempotency.NewKey()); err != nil {
+			return r.report.Invalid(label, err)
+		}
+	}
+	binary, err := r.encode(msg.value)
+	if err != nil {
+		return r.report.Invalid(label, fmt.Errorf("doesn't fit %s v%d: %w", r.schema.Subject, r.schema.Version, err))
+	}
+	r.batch = append(r.batch, kafka.Record{Key: msg.key, Value: binary, Headers: msg.headers})
+	r.labels = append(r.labels, label)
+
+	if r.fast {
+		if len(r.batch) >= replayBatchSize {
+			return r.flush()
+		}
+		return nil
+	}
+
+	// Wait until the message is due, measured from the start so produce
+	// latency doesn't accumulate as drift
+	if r.started.IsZero() {
+		r.started = time.Now()
+		r.first = msg.timestamp
+	} else if !msg.timestamp.IsZero() {
+		due := r.started.Add(time.Duration(float64(msg.timestamp.Sub(r.first)) / r.speed))
+		if wait := time.Until(due); wait > 0 {
+			time.Sleep(wait)
+		}
+	}
+	return r.flush()
+}
+
+// encode validates a dumped value against the destination schema and
+// returns its binary, encrypting the fields the schema's rules tag for
+// encryption as produce does
+func (r *replayer) encode(value string) ([]byte, error) {
+	if r.schema.IsJSONSchema() {
+		return encodePayload(r.encryptor, r.schema, value)
+	}
+	encrypted, err := encryptPayload(r.encryptor, r.schema, value)
+	if err != nil {
+		return nil, err
+	}
+	return avro.EncodeStandard(r.schema.Schema, encrypted)
+}
+
+func (r *replayer) flush() error {
+	if len(r.batch) == 0 {
+		return nil
+	}
+	err := r.report.Produce(context.Background(), r.producer, r.topic, r.schema.ID, r.batch, r.labels)
+	r.batch = r.batch[:0]
+	r.labels = r.labels[:0]
+	fmt.Fprintf(os.Stderr, "\r%d messages replayed", r.report.Sent)
+	return err
+}
+
+// dumpLine is one line of a JSON lines dump
+type dumpLine struct {
+	Offset    int64             `json:"offset"`
+	Timestamp time.Time         `json:"timestamp"`
+	Key       *string           `json:"key"`
+	KeyBase64 string            `json:"key_base64"`
+	Headers   map[string]string `json:"headers"`
+	Value     json.RawMessage   `json:"value"`
+}
+
+func readJSONLDump(path string, send func(replayMessage) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	scanner := bufio.NewScanner(f)
+	scanner.Buffer(make([]byte, 64*1024), 64*1024*1024)
+	for n := 1; scanner.Scan(); n++ {
+		if len(strings.TrimSpace(scanner.Text())) == 0 {
+			continue
+		}
+		var line dumpLine
+		if err := json.Unmarshal(scanner.Bytes(), &line); err != nil {
+			return fmt.Errorf("%s:%d: %w", path, n, err)
+		}
+
+		msg := replayMessage{offset: line.Offset, timestamp: line.Timestamp, headers: line.Headers, value: string(line.Value)}
+		if line.Key != nil {
+			msg.key = []byte(*line.Key)
+		} else if line.KeyBase64 != "" {
+			if msg.key, err = base64.StdEncoding.DecodeString(line.KeyBase64); err != nil {
+				return fmt.Errorf("%s:%d: key_base64: %w", path, n, err)
+			}
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+	return scanner.Err()
+}
+
+func readOCFDump(path string, send func(replayMessage) error) error {
+	f, err := os.Open(path)
+	if err != nil {
+		return err
+	}
+	defer f.Close()
+
+	reader, err := goavro.NewOCFReader(f)
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", path, err)
+	}
+
+	// Values are re-encoded from plain JSON, so convert them with a codec
+	// for the value field's own schema
+	valueSchema, err := envelopeValueSchema(reader.Codec().Schema())
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+	valueCodec, err := goavro.NewCodec(valueSchema)
+	if err != nil {
+		return fmt.Errorf("%s: %w", path, err)
+	}
+
+	for reader.Scan() {
+		datum, err := reader.Read()
+		if err != nil {
+			return fmt.Errorf("reading %s: %w", path, err)
+		}
+		record, ok := datum.(map[string]interface{})
+		if !ok {
+			return fmt.Errorf("%s is not an avrocado dump", path)
+		}
+
+		msg := replayMessage{headers: make(map[string]string)}
+		msg.offset, _ = record["offset"].(int64)
+		msg.timestamp, _ = record["timestamp"].(time.Time)
+		if key, ok := record["key"].(map[string]interface{}); ok {
+			msg.key, _ = key["bytes"].([]byte)
+		}
+		if headers, ok := record["headers"].(map[string]interface{}); ok {
+			for k, v := range headers {
+				msg.headers[k], _ = v.(string)
+			}
+		}
+
+		binary, err := valueCodec.BinaryFromNative(nil, record["value"])
+		if err != nil {
+			return fmt.Errorf("offset %d: %w", msg.offset, err)
+		}
+		if msg.value, err = avro.DecodeStandard(valueSchema, binary); err != nil {
+			return fmt.Errorf("offset %d: %w", msg.offset, err)
+		}
+		if err := send(msg); err != nil {
+			return err
+		}
+	}
+	return reader.Err()
+}
+
+// envelopeValueSchema extracts the schema of the value field from a dump's
+// envelope record schema
+func envelopeValueSchema(envelope string) (string, error) {
+	var record struct {
+		Fields []struct {
+			Name string          `json:"name"`
+			Type json.RawMessage `json:"type"`
+		} `json:"fields"`
+	}
+	if err := json.Unmarshal([]byte(envelope), &record); err != nil {
+		return "", fmt.Errorf("parsing container schema: %w", err)
+	}
+	for _, field := range record.Fields {
+		if field.Name == "value" {
+			return string(field.Type), nil
+		}
+	}
+	return "", fmt.Errorf("not an avrocado dump: no value field")
+}
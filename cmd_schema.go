@@ -0,0 +1,288 @@
+package main
+
+import (
+	"fmt"
+	"io"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+const schemaUsage = `Usage: avrocado schema <subcommand> [flags]
+
+Subcommands:
+  list                       List subjects
+  get <subject[@version]>    Print a schema (or --id <id>)
+  register <subject> <file>  Register a schema file (- for stdin) as a new version
+  import <asyncapi file>     Register the message schemas of an AsyncAPI document
+  diff <old> [new]           Unified diff of two schemas, or a subject's last two versions
+  changelog <subject>        Markdown changelog of field changes across all versions
+  coverage [subject...]      Doc string coverage per subject (all subjects if none given)
+  map <source> <target>      Pair fields across two schemas and print a jq transformation
+
+Schemas for diff and map are .avsc files or subject[@version]. list, get,
+register, import and diff take --format json for output to pipe into jq;
+register takes --type json for a JSON Schema. import names subjects by the
+naming strategy and prints its plan first; --dry-run stops there.`
+
+func runSchemaCommand(args []string) error {
+	if len(args) == 0 {
+		fmt.Println(schemaUsage)
+		return nil
+	}
+
+	switch args[0] {
+	case "list":
+		return runSchemaList(args[1:])
+	case "get":
+		return runSchemaGet(args[1:])
+	case "register":
+		return runSchemaRegister(args[1:])
+	case "import":
+		return runSchemaImport(args[1:])
+	case "diff":
+		return runSchemaDiff(args[1:])
+	case "changelog":
+		return runSchemaChangelog(args[1:])
+	case "coverage":
+		return runSchemaCoverage(args[1:])
+	case "map":
+		return runSchemaMap(args[1:])
+	case "-h", "--help", "help":
+		fmt.Println(schemaUsage)
+		return nil
+	default:
+		return fmt.Errorf("unknown schema subcommand %q\n\n%s", args[0], schemaUsage)
+	}
+}
+
+func runSchemaChangelog(args []string) error {
+	flags := pflag.NewFlagSet("schema changelog", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	out := flags.StringP("out", "o", "", "Write the changelog to a file instead of stdout")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: avrocado schema changelog <subject> [--out file]")
+	}
+	subject := flags.Arg(0)
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	client := newRegistryClient(cfg)
+
+	versions, err := client.GetAllVersions(subject)
+	if err != nil {
+		return fmt.Errorf("fetching versions of %s: %w", subject, err)
+	}
+
+	changelog, err := report.Changelog(subject, versions)
+	if err != nil {
+		return err
+	}
+
+	if *out == "" {
+		fmt.Print(changelog)
+		return nil
+	}
+	if err := os.WriteFile(*out, []byte(changelog), 0644); err != nil {
+		return fmt.Errorf("writing changelog: %w", err)
+	}
+	fmt.Fprintf(os.Stderr, "Wrote changelog for %s to %s\n", subject, *out)
+	return nil
+}
+
+func runSchemaCoverage(args []string) error {
+	flags := pflag.NewFlagSet("schema coverage", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	match := flags.StringP("match", "m", "", "Only include subjects containing this text")
+	min := flags.Float64("min", 0, "Fail if any subject's coverage is below this percentage")
+	verbose := flags.BoolP("verbose", "v", false, "List undocumented fields")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	client := newRegistryClient(cfg)
+
+	subjects := flags.Args()
+	if len(subjects) == 0 {
+		subjects, err = client.ListSubjects()
+		if err != nil {
+			return fmt.Errorf("listing subjects: %w", err)
+		}
+		subjects = projectSubjects(cfg, subjects)
+	}
+
+	var results []report.Coverage
+	for _, subject := range subjects {
+		if *match != "" && !strings.Contains(strings.ToLower(subject), strings.ToLower(*match)) {
+			continue
+		}
+		results = append(results, fetchCoverage(client, subject))
+	}
+
+	fmt.Print(report.FormatCoverage(results, *verbose))
+
+	if *min > 0 {
+		var below int
+		for _, r := range results {
+			if r.Err == nil && r.Percent() < *min {
+				below++
+			}
+		}
+		if below > 0 {
+			return fmt.Errorf("%d subject(s) below %.1f%% doc coverage", below, *min)
+		}
+	}
+	return nil
+}
+
+func fetchCoverage(client *registry.Client, subject string) report.Coverage {
+	schema, err := client.GetLatestSchema(subject)
+	if err != nil {
+		return report.Coverage{Subject: subject, Err: err}
+	}
+	return report.DocCoverage(subject, schema.Schema)
+}
+
+func runSchemaMap(args []string) error {
+	flags := pflag.NewFlagSet("schema map", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	jqOnly := flags.Bool("jq", false, "Print only the jq transformation")
+	convert := flags.String("convert", "", "Convert a plain JSON payload file (- for stdin) instead of printing the mapping")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: avrocado schema map <source> <target> [--jq | --convert file]")
+	}
+
+	loader := &schemaLoader{profile: *profile}
+	defer loader.close()
+
+	source, err := loader.load(flags.Arg(0))
+	if err != nil {
+		return err
+	}
+	target, err := loader.load(flags.Arg(1))
+	if err != nil {
+		return err
+	}
+	mapping, err := avro.MapFields(source, target)
+	if err != nil {
+		return err
+	}
+
+	switch {
+	case *convert != "":
+		var payload []byte
+		if *convert == "-" {
+			payload, err = io.ReadAll(os.Stdin)
+		} else {
+			payload, err = os.ReadFile(*convert)
+		}
+		if err != nil {
+			return fmt.Errorf("reading payload: %w", err)
+		}
+		out, err := mapping.Convert(string(payload))
+		if err != nil {
+			return err
+		}
+		fmt.Println(out)
+	case *jqOnly:
+		fmt.Print(mapping.JQ())
+	default:
+		fmt.Print(formatMapping(mapping))
+	}
+	return nil
+}
+
+// schemaLoader loads schemas from .avsc files or subject[@version] refs,
+// connecting to the registry only once a ref needs it
+type schemaLoader struct {
+	profile     string
+	client      *registry.Client
+	closeTunnel func()
+}
+
+func (l *schemaLoader) load(source string) (string, error) {
+	if _, err := os.Stat(source); err == nil {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return "", fmt.Errorf("reading schema: %w", err)
+		}
+		return string(data), nil
+	}
+	client, err := l.registry()
+	if err != nil {
+		return "", err
+	}
+	schema, err := client.GetSchemaRef(source)
+	if err != nil {
+		return "", fmt.Errorf("fetching schema %s: %w", source, err)
+	}
+	return schema.Schema, nil
+}
+
+// registry connects to the profile's registry on first use
+func (l *schemaLoader) registry() (*registry.Client, error) {
+	if l.client == nil {
+		cfg, closer, err := loadCommandConfig(l.profile)
+		if err != nil {
+			return nil, err
+		}
+		l.closeTunnel = closer
+		l.client = newRegistryClient(cfg)
+	}
+	return l.client, nil
+}
+
+func (l *schemaLoader) close() {
+	if l.closeTunnel != nil {
+		l.closeTunnel()
+	}
+}
+
+// formatMapping renders a field mapping as plain text
+func formatMapping(mapping *avro.FieldMapping) string {
+	var b strings.Builder
+	for _, p := range mapping.Pairs {
+		line := fmt.Sprintf("  %s <- %s", p.Target, p.Source)
+		if p.Match != avro.MatchName {
+			line += " (" + p.Match + ")"
+		}
+		if p.Note != "" {
+			line += ": " + p.Note
+		}
+		b.WriteString(line + "\n")
+	}
+	for _, p := range mapping.Conflicts {
+		fmt.Fprintf(&b, "! %s <- %s: %s\n", p.Target, p.Source, p.Note)
+	}
+	for _, path := range mapping.Missing {
+		fmt.Fprintf(&b, "! %s: required, no source field\n", path)
+	}
+	for _, path := range mapping.Defaulted {
+		fmt.Fprintf(&b, "  %s: default\n", path)
+	}
+	for _, path := range mapping.Dropped {
+		fmt.Fprintf(&b, "- %s: dropped\n", path)
+	}
+	b.WriteString("\n")
+	b.WriteString(mapping.JQ())
+	return b.String()
+}
@@ -0,0 +1,229 @@
+package main
+
+import (
+	"fmt"
+	"os"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/asyncapi"
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// Import plan actions
+const (
+	importCreate    = "create"
+	importVersion   = "new version"
+	importUnchanged = "unchanged"
+	importBlocked   = "blocked"
+)
+
+// importStep is one schema of an AsyncAPI document and what registering it
+// would do
+type importStep struct {
+	Subject    string `json:"subject"`
+	Action     string `json:"action"`
+	Reason     string `json:"reason,omitempty"` // Why a step is blocked
+	Warning    string `json:"warning,omitempty"`
+	Channel    string `json:"channel"`
+	Topic      string `json:"topic"`
+	Message    string `json:"message"`
+	SchemaType string `json:"schema_type"`
+	ID         int    `json:"id,omitempty"` // Once registered
+	schema     string
+}
+
+func runSchemaImport(args []string) error {
+	flags := pflag.NewFlagSet("schema import", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	dryRun := flags.BoolP("dry-run", "n", false, "Print the plan without registering anything")
+	yes := flags.BoolP("yes", "y", false, "Allow registering in a production profile")
+	format := formatFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 1 {
+		return fmt.Errorf("usage: avrocado schema import <asyncapi.yaml> [--dry-run] [--format json]")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	doc, err := asyncapi.Load(flags.Arg(0))
+	if err != nil {
+		return fmt.Errorf("reading %s: %w", flags.Arg(0), err)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	if cfg.Production && !*dryRun && !*yes {
+		return fmt.Errorf("profile %q is marked production; pass --yes to register in it", cfg.Profile)
+	}
+	client := newRegistryClient(cfg)
+
+	plan := importPlan(cfg, client, doc)
+	for _, skipped := range doc.Skipped {
+		fmt.Fprintf(os.Stderr, "skipped %s\n", skipped)
+	}
+
+	blocked := 0
+	for _, step := range plan {
+		if step.Action == importBlocked {
+			blocked++
+		}
+	}
+	if *dryRun || blocked > 0 {
+		if err := printImportPlan(plan, *format); err != nil {
+			return err
+		}
+		if blocked > 0 {
+			return fmt.Errorf("%d of %d schemas can't be registered; nothing was registered", blocked, len(plan))
+		}
+		return nil
+	}
+
+	for i, step := range plan {
+		if step.Action != importCreate && step.Action != importVersion {
+			continue
+		}
+		id, err := client.RegisterSchema(step.Subject, step.schema, step.SchemaType)
+		if err != nil {
+			return fmt.Errorf("registering %s: %w", step.Subject, err)
+		}
+		plan[i].ID = id
+		fmt.Fprintf(os.Stderr, "registered %s: schema ID %d\n", step.Subject, id)
+
+		if err := hooks.Fire(cfg, hooks.SchemaRegistered, map[string]interface{}{
+			"subject":   step.Subject,
+			"schema_id": id,
+			"schema":    step.schema,
+		}); err != nil {
+			fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+		}
+	}
+	return printImportPlan(plan, *format)
+}
+
+// importPlan names the subject of each schema in doc under the naming
+// strategy and works out what registering it would do
+func importPlan(cfg *config.Config, client *registry.Client, doc *asyncapi.Document) []importStep {
+	var plan []importStep
+	planned := make(map[string]int)
+	for _, m := range doc.Messages {
+		step := importStep{
+			Subject:    importSubject(cfg, m),
+			Channel:    m.Channel,
+			Topic:      m.Topic,
+			Message:    m.Name,
+			SchemaType: m.SchemaType,
+			schema:     m.Schema,
+		}
+		if m.Key {
+			step.Message += " (key)"
+		}
+		if i, ok := planned[step.Subject]; ok && step.Subject != "" {
+			if asyncapi.Equal(plan[i].schema, step.schema) {
+				continue // Several channels share a record-named subject
+			}
+			step.Action = importBlocked
+			step.Reason = fmt.Sprintf("%s on %s plans a different schema for this subject", plan[i].Message, plan[i].Topic)
+		} else {
+			step.Action, step.Reason = importAction(cfg, client, step)
+			step.Warning, _ = cfg.CheckSubjectName(step.Subject)
+		}
+		planned[step.Subject] = len(plan)
+		plan = append(plan, step)
+	}
+	return plan
+}
+
+// importSubject is the subject a schema is registered under: by topic,
+// record name or both, as the naming strategy has it
+func importSubject(cfg *config.Config, m asyncapi.Message) string {
+	switch cfg.NamingStrategy() {
+	case config.RecordNameStrategy:
+		return m.RecordName
+	case config.TopicRecordNameStrategy:
+		if m.RecordName == "" {
+			return ""
+		}
+		return m.Topic + "-" + m.RecordName
+	}
+	if m.Key {
+		return m.Topic + "-key"
+	}
+	return cfg.TopicSubject(m.Topic)
+}
+
+// importAction checks a step's schema and subject and compares the schema
+// with the subject's latest version
+func importAction(cfg *config.Config, client *registry.Client, step importStep) (string, string) {
+	if step.Subject == "" {
+		return importBlocked, "no record name to name the subject by (set the schema's name or title)"
+	}
+	var err error
+	if step.SchemaType == asyncapi.TypeAvro {
+		_, err = avro.NewCodec(step.schema)
+	} else {
+		_, err = jsonschema.Compile(step.schema)
+	}
+	if err != nil {
+		return importBlocked, fmt.Sprintf("invalid schema: %v", err)
+	}
+	if err := cfg.CheckSubject(step.Subject); err != nil {
+		return importBlocked, err.Error()
+	}
+	if _, err := cfg.CheckSubjectName(step.Subject); err != nil {
+		return importBlocked, err.Error()
+	}
+
+	latest, err := client.GetLatestSchema(step.Subject)
+	if registry.IsNotFound(err) {
+		return importCreate, ""
+	}
+	if err != nil {
+		return importBlocked, fmt.Sprintf("fetching %s: %v", step.Subject, err)
+	}
+	if asyncapi.Equal(latest.Schema, step.schema) {
+		return importUnchanged, ""
+	}
+	return importVersion, ""
+}
+
+func printImportPlan(plan []importStep, format string) error {
+	if format == "json" {
+		if plan == nil {
+			plan = []importStep{}
+		}
+		return printJSON(plan)
+	}
+
+	counts := make(map[string]int)
+	for _, step := range plan {
+		counts[step.Action]++
+		subject := step.Subject
+		if subject == "" {
+			subject = "?"
+		}
+		fmt.Printf("%-12s %-40s %-4s  %s on %s\n", step.Action, subject, step.SchemaType, step.Message, step.Topic)
+		if step.ID != 0 {
+			fmt.Printf("%12s schema ID %d\n", "", step.ID)
+		}
+		if step.Reason != "" {
+			fmt.Printf("%12s %s\n", "", step.Reason)
+		}
+		if step.Warning != "" {
+			fmt.Printf("%12s warning: %s\n", "", step.Warning)
+		}
+	}
+	fmt.Fprintf(os.Stderr, "%d to create, %d new versions, %d unchanged, %d blocked\n",
+		counts[importCreate], counts[importVersion], counts[importUnchanged], counts[importBlocked])
+	return nil
+}
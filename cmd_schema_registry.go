@@ -0,0 +1,293 @@
+package main
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/diff"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// schemaDiffContext is how many unchanged lines schema diff keeps around
+// each change
+const schemaDiffContext = 3
+
+// formatFlag adds the --format flag shared by the schema subcommands
+func formatFlag(flags *pflag.FlagSet) *string {
+	return flags.String("format", "text", "Output format: text or json")
+}
+
+// checkFormat rejects an unknown --format
+func checkFormat(format string) error {
+	if format != "text" && format != "json" {
+		return fmt.Errorf("invalid --format %q (want text or json)", format)
+	}
+	return nil
+}
+
+// printJSON writes v to stdout as indented JSON
+func printJSON(v interface{}) error {
+	enc := json.NewEncoder(os.Stdout)
+	enc.SetIndent("", "  ")
+	return enc.Encode(v)
+}
+
+func runSchemaList(args []string) error {
+	flags := pflag.NewFlagSet("schema list", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	match := flags.StringP("match", "m", "", "Only include subjects containing this text")
+	format := formatFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 0 {
+		return fmt.Errorf("usage: avrocado schema list [--match text] [--format json]")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+
+	subjects, err := newRegistryClient(cfg).ListSubjects()
+	if err != nil {
+		return fmt.Errorf("listing subjects: %w", err)
+	}
+	matched := []string{}
+	for _, subject := range projectSubjects(cfg, subjects) {
+		if *match == "" || strings.Contains(strings.ToLower(subject), strings.ToLower(*match)) {
+			matched = append(matched, subject)
+		}
+	}
+
+	if *format == "json" {
+		return printJSON(matched)
+	}
+	for _, subject := range matched {
+		fmt.Println(subject)
+	}
+	return nil
+}
+
+func runSchemaGet(args []string) error {
+	flags := pflag.NewFlagSet("schema get", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	id := flags.Int("id", 0, "Fetch the schema with this ID instead of a subject version")
+	format := formatFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if (*id == 0) == (flags.NArg() == 0) || flags.NArg() > 1 {
+		return fmt.Errorf("usage: avrocado schema get <subject[@version]> | --id <id> [--format json]")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	client := newRegistryClient(cfg)
+
+	var schema *registry.SchemaResponse
+	if *id != 0 {
+		schema, err = client.GetSchemaInfoByID(*id)
+		if err != nil {
+			return fmt.Errorf("fetching schema ID %d: %w", *id, err)
+		}
+	} else {
+		schema, err = client.GetSchemaRef(flags.Arg(0))
+		if err != nil {
+			return fmt.Errorf("fetching schema %s: %w", flags.Arg(0), err)
+		}
+	}
+
+	if *format == "json" && *id != 0 {
+		// An ID isn't tied to one subject version, so those are left out
+		return printJSON(struct {
+			ID         int                      `json:"id"`
+			SchemaType string                   `json:"schemaType"`
+			Schema     string                   `json:"schema"`
+			Metadata   *registry.SchemaMetadata `json:"metadata,omitempty"`
+			RuleSet    *registry.RuleSet        `json:"ruleSet,omitempty"`
+		}{schema.ID, schema.SchemaType, schema.Schema, schema.Metadata, schema.RuleSet})
+	}
+	if *format == "json" {
+		return printJSON(schema)
+	}
+	fmt.Println(registry.PrettyPrintSchema(schema.Schema))
+	return nil
+}
+
+func runSchemaRegister(args []string) error {
+	flags := pflag.NewFlagSet("schema register", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	yes := flags.BoolP("yes", "y", false, "Allow registering in a production profile")
+	schemaType := flags.String("type", "avro", "Schema type: avro or json")
+	format := formatFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() != 2 {
+		return fmt.Errorf("usage: avrocado schema register <subject> <file.avsc | -> [--type json] [--format json]")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+	subject := flags.Arg(0)
+
+	schema, err := readPayload(flags.Arg(1))
+	if err != nil {
+		return fmt.Errorf("reading schema: %w", err)
+	}
+	switch strings.ToUpper(*schemaType) {
+	case registry.SchemaTypeAvro:
+		*schemaType = registry.SchemaTypeAvro
+		_, err = avro.NewCodec(schema)
+	case registry.SchemaTypeJSON:
+		*schemaType = registry.SchemaTypeJSON
+		_, err = jsonschema.Compile(schema)
+	default:
+		return fmt.Errorf("invalid --type %q (want avro or json)", *schemaType)
+	}
+	if err != nil {
+		return fmt.Errorf("invalid schema: %w", err)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	if cfg.Production && !*yes {
+		return fmt.Errorf("profile %q is marked production; pass --yes to register in it", cfg.Profile)
+	}
+	if err := cfg.CheckSubject(subject); err != nil {
+		return err
+	}
+	warning, err := cfg.CheckSubjectName(subject)
+	if err != nil {
+		return err
+	}
+	if warning != "" {
+		fmt.Fprintf(os.Stderr, "warning: %s\n", warning)
+	}
+
+	id, err := newRegistryClient(cfg).RegisterSchema(subject, schema, *schemaType)
+	if err != nil {
+		return fmt.Errorf("registering %s: %w", subject, err)
+	}
+
+	if *format == "json" {
+		err = printJSON(map[string]interface{}{"subject": subject, "id": id})
+	} else {
+		fmt.Println(id)
+		fmt.Fprintf(os.Stderr, "registered %s: schema ID %d\n", subject, id)
+	}
+
+	if err := hooks.Fire(cfg, hooks.SchemaRegistered, map[string]interface{}{
+		"subject":   subject,
+		"schema_id": id,
+		"schema":    schema,
+	}); err != nil {
+		fmt.Fprintf(os.Stderr, "warning: %v\n", err)
+	}
+	return err
+}
+
+// schemaChange is a field change as schema diff writes it in JSON
+type schemaChange struct {
+	Path    string   `json:"path"`
+	Kind    string   `json:"kind"`
+	OldType string   `json:"old_type,omitempty"`
+	NewType string   `json:"new_type,omitempty"`
+	Details []string `json:"details,omitempty"`
+}
+
+func runSchemaDiff(args []string) error {
+	flags := pflag.NewFlagSet("schema diff", pflag.ContinueOnError)
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	format := formatFlag(flags)
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if flags.NArg() < 1 || flags.NArg() > 2 {
+		return fmt.Errorf("usage: avrocado schema diff <old> [new] [--format json]")
+	}
+	if err := checkFormat(*format); err != nil {
+		return err
+	}
+
+	loader := &schemaLoader{profile: *profile}
+	defer loader.close()
+
+	oldRef, newRef := flags.Arg(0), flags.Arg(1)
+	if newRef == "" {
+		// A subject on its own: its previous version against the latest
+		client, err := loader.registry()
+		if err != nil {
+			return err
+		}
+		versions, err := client.ListVersions(oldRef)
+		if err != nil {
+			return fmt.Errorf("listing versions of %s: %w", oldRef, err)
+		}
+		if len(versions) < 2 {
+			return fmt.Errorf("%s has only one version", oldRef)
+		}
+		subject := oldRef
+		oldRef = fmt.Sprintf("%s@%d", subject, versions[len(versions)-2])
+		newRef = fmt.Sprintf("%s@%d", subject, versions[len(versions)-1])
+	}
+
+	older, err := loader.load(oldRef)
+	if err != nil {
+		return err
+	}
+	newer, err := loader.load(newRef)
+	if err != nil {
+		return err
+	}
+
+	if *format == "json" {
+		fieldChanges, err := avro.CompareSchemas(older, newer)
+		if err != nil {
+			return err
+		}
+		changes := []schemaChange{}
+		for _, c := range fieldChanges {
+			change := schemaChange{Path: c.Path, Kind: c.Kind.String(), Details: c.Details}
+			if c.Old != nil {
+				change.OldType = c.Old.Type
+			}
+			if c.New != nil {
+				change.NewType = c.New.Type
+			}
+			changes = append(changes, change)
+		}
+		return printJSON(map[string]interface{}{"old": oldRef, "new": newRef, "changes": changes})
+	}
+
+	lines := diff.Lines(registry.PrettyPrintSchema(older), registry.PrettyPrintSchema(newer))
+	for _, l := range lines {
+		if l.Op != diff.Equal {
+			// Like diff(1), identical schemas print nothing
+			fmt.Print(diff.Unified(oldRef, newRef, lines, schemaDiffContext))
+			break
+		}
+	}
+	return nil
+}
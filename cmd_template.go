@@ -0,0 +1,233 @@
+package main
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"github.com/spf13/pflag"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+const templateUsage = `Usage: avrocado template <subject> [flags]
+       avrocado template --all --out <dir> [flags]
+
+Generates a JSON payload template from the latest version of a subject's schema.
+With --all, writes one <subject>.json per subject into --out.
+
+With --random, fills the payload with random data instead of placeholders,
+shaped by the subject's generation config (~/.config/avrocado/generators/<subject>.yaml
+or --constraints). --count N prints N payloads, one compact JSON per line, and
+--seed replays the same sequence.`
+
+func runTemplateCommand(args []string) error {
+	flags := pflag.NewFlagSet("template", pflag.ContinueOnError)
+	flags.Usage = func() { fmt.Fprintln(os.Stderr, templateUsage) }
+	profile := flags.StringP("profile", "p", "", "Configuration profile to use (default profile if empty)")
+	all := flags.Bool("all", false, "Generate templates for every subject")
+	out := flags.StringP("out", "o", "", "Output file, or directory with --all")
+	match := flags.StringP("match", "m", "", "With --all, only include subjects containing this text")
+	populate := flags.Bool("populate", false, "Put one example element in arrays and maps")
+	maxDepth := flags.Int("max-depth", 0, fmt.Sprintf("Nesting limit for records, arrays and maps (default %d)", avro.DefaultMaxDepth))
+	preferNull := flags.Bool("prefer-null", false, "Use null for optional unions")
+	minimal := flags.Bool("minimal", false, "Leave out fields that have defaults; they are filled in when encoding")
+	maxRecursion := flags.Int("max-recursion", 0, "How many times a recursive record may nest inside itself before \"__recursive\": null")
+	random := flags.Bool("random", false, "Fill payloads with random data")
+	count := flags.IntP("count", "n", 1, "With --random, number of payloads to generate")
+	constraints := flags.String("constraints", "", "With --random, generation config file (default per-subject file)")
+	seed := flags.Int64("seed", 0, "With --random, seed for a reproducible payload sequence (default: time-based, printed to stderr)")
+	if err := flags.Parse(args); err != nil {
+		return err
+	}
+	if *count < 1 || (*count > 1 && (!*random || *all)) {
+		return fmt.Errorf("--count needs --random and a single subject")
+	}
+	if *constraints != "" && *all {
+		return fmt.Errorf("--constraints applies to a single subject; --all uses each subject's own file")
+	}
+
+	if *all {
+		if flags.NArg() != 0 || *out == "" {
+			return fmt.Errorf("usage: avrocado template --all --out <dir>")
+		}
+	} else if flags.NArg() != 1 {
+		return fmt.Errorf("%s", templateUsage)
+	}
+
+	cfg, closeTunnel, err := loadCommandConfig(*profile)
+	if err != nil {
+		return err
+	}
+	defer closeTunnel()
+	client := newRegistryClient(cfg)
+
+	// Flags override the config file's template settings
+	opts := avro.TemplateOptions{
+		PopulateCollections: cfg.Template.PopulateCollections,
+		MaxDepth:            cfg.Template.MaxDepth,
+		PreferNull:          cfg.Template.PreferNull,
+		MaxRecursion:        cfg.Template.MaxRecursion,
+		OmitDefaults:        cfg.Template.OmitDefaults,
+	}
+	if flags.Changed("populate") {
+		opts.PopulateCollections = *populate
+	}
+	if flags.Changed("max-depth") {
+		opts.MaxDepth = *maxDepth
+	}
+	if flags.Changed("prefer-null") {
+		opts.PreferNull = *preferNull
+	}
+	if flags.Changed("max-recursion") {
+		opts.MaxRecursion = *maxRecursion
+	}
+	if flags.Changed("minimal") {
+		opts.OmitDefaults = *minimal
+	}
+
+	// Print generated seeds so a payload that triggers a bug can be reproduced
+	if *random && !flags.Changed("seed") {
+		*seed = time.Now().UnixNano()
+		fmt.Fprintf(os.Stderr, "seed: %d\n", *seed)
+	}
+
+	gen := payloadGenerator{
+		opts:        opts,
+		random:      *random,
+		constraints: *constraints,
+		rng:         rand.New(rand.NewSource(*seed)),
+	}
+
+	if !*all {
+		template, err := gen.generate(client, flags.Arg(0), *count)
+		if err != nil {
+			return err
+		}
+		if *out == "" {
+			fmt.Println(template)
+			return nil
+		}
+		return os.WriteFile(*out, []byte(template+"\n"), 0644)
+	}
+
+	subjects, err := client.ListSubjects()
+	if err != nil {
+		return fmt.Errorf("listing subjects: %w", err)
+	}
+	subjects = projectSubjects(cfg, subjects)
+
+	if err := os.MkdirAll(*out, 0755); err != nil {
+		return fmt.Errorf("creating output directory: %w", err)
+	}
+
+	var written, failed int
+	for _, subject := range subjects {
+		if *match != "" && !strings.Contains(strings.ToLower(subject), strings.ToLower(*match)) {
+			continue
+		}
+
+		template, err := gen.generate(client, subject, 1)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "skipping %s: %v\n", subject, err)
+			failed++
+			continue
+		}
+
+		path := filepath.Join(*out, templateFileName(subject))
+		if err := os.WriteFile(path, []byte(template+"\n"), 0644); err != nil {
+			return fmt.Errorf("writing %s: %w", path, err)
+		}
+		written++
+	}
+
+	fmt.Fprintf(os.Stderr, "Wrote %d template(s) to %s\n", written, *out)
+	if failed > 0 {
+		return fmt.Errorf("%d subject(s) could not be templated", failed)
+	}
+	return nil
+}
+
+// payloadGenerator produces template or random payloads for subjects
+type payloadGenerator struct {
+	opts        avro.TemplateOptions
+	random      bool
+	constraints string // Generation config file, instead of the subject's own
+	rng         *rand.Rand
+}
+
+// generate builds count payloads from the latest version of subject. A single
+// payload is indented; several are written one compact JSON per line.
+func (g payloadGenerator) generate(client *registry.Client, subject string, count int) (string, error) {
+	schema, err := client.GetLatestSchema(subject)
+	if err != nil {
+		return "", fmt.Errorf("fetching schema: %w", err)
+	}
+	if schema.IsJSONSchema() && !g.random {
+		return jsonschema.Template(schema.Schema)
+	}
+	if schema.SchemaType != "" && schema.SchemaType != registry.SchemaTypeAvro {
+		return "", fmt.Errorf("%s schemas are not supported", schema.SchemaType)
+	}
+
+	if !g.random {
+		return avro.GenerateTemplateWithOptions(schema.Schema, g.opts)
+	}
+
+	path := g.constraints
+	if path == "" {
+		path = avro.GetConstraintsPath(subject)
+	}
+	constraints, err := avro.LoadConstraints(path)
+	if err != nil {
+		return "", err
+	}
+
+	random, err := avro.NewRandomGenerator(schema.Schema, constraints, g.rng)
+	if err != nil {
+		return "", err
+	}
+
+	payloads := make([]string, 0, count)
+	for i := 0; i < count; i++ {
+		payload, err := random.Generate()
+		if err != nil {
+			return "", err
+		}
+		if count > 1 {
+			var compact bytes.Buffer
+			if err := json.Compact(&compact, []byte(payload)); err != nil {
+				return "", err
+			}
+			payload = compact.String()
+		}
+		payloads = append(payloads, payload)
+	}
+	return strings.Join(payloads, "\n"), nil
+}
+
+// templateFileName maps a subject to the file its template is written to
+func templateFileName(subject string) string {
+	return subjectFileName(subject) + ".json"
+}
+
+// subjectFileName maps a subject to a file name without an extension,
+// replacing characters that are awkward in paths (context prefixes like
+// ":.ctx:" and separators)
+func subjectFileName(subject string) string {
+	name := strings.Map(func(r rune) rune {
+		switch r {
+		case '/', '\\', ':', '*', '?', '"', '<', '>', '|':
+			return '_'
+		}
+		return r
+	}, subject)
+	return strings.TrimLeft(name, "_.")
+}
@@ -0,0 +1,162 @@
+package main
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/plugin"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// command is a headless subcommand that runs instead of the TUI
+type command struct {
+	summary string
+	run     func(args []string) error
+}
+
+var commands = map[string]command{
+	"bench":    {summary: "Measure Avro encode/decode throughput and self-test a backend", run: runBenchCommand},
+	"cluster":  {summary: "Show the Kafka cluster's brokers, version and topic totals", run: runClusterCommand},
+	"codegen":  {summary: "Generate Go, Java or Python types and a produce/consume example for a subject", run: runCodegenCommand},
+	"docs":     {summary: "Write HTML or Markdown documentation pages for subjects", run: runDocsCommand},
+	"dump":     {summary: "Export a topic's decoded messages to JSON lines or Avro OCF", run: runDumpCommand},
+	"expect":   {summary: "Wait for a message matching filters (exit 1 on timeout)", run: runExpectCommand},
+	"pipe":     {summary: "Transform a topic's messages (jq or field mapping) into another topic", run: runPipeCommand},
+	"produce":  {summary: "Validate a JSON payload against a subject and produce it", run: runProduceCommand},
+	"replay":   {summary: "Produce a dump file to a topic, re-encoded for its subject", run: runReplayCommand},
+	"schema":   {summary: "List, get, register, import, diff and map schemas; changelog and coverage reports", run: runSchemaCommand},
+	"template": {summary: "Generate payload templates for one or all subjects", run: runTemplateCommand},
+}
+
+// runCommand runs the subcommand named by args[0], if any.
+// Returns false when args don't name a subcommand and the TUI should start.
+func runCommand(args []string) bool {
+	if len(args) == 0 {
+		return false
+	}
+
+	if args[0] == "help" {
+		printCommands()
+		return true
+	}
+
+	cmd, ok := commands[args[0]]
+	if !ok {
+		return false
+	}
+
+	err := cmd.run(args[1:])
+	plugin.StopAll()
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+		os.Exit(1)
+	}
+	return true
+}
+
+func printCommands() {
+	names := make([]string, 0, len(commands))
+	for name := range commands {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	fmt.Println("Usage: avrocado [command] [flags]")
+	fmt.Println()
+	fmt.Println("Without a command, avrocado starts the interactive TUI.")
+	fmt.Println()
+	fmt.Println("Commands:")
+	for _, name := range names {
+		fmt.Printf("  %-10s %s\n", name, commands[name].summary)
+	}
+}
+
+// loadCommandConfig resolves the configuration for a headless command and
+// opens the profile's SSH tunnel, if any. An empty profile selects the
+// default profile, falling back to environment variables when no config file
+// or profile exists. The returned function releases the tunnel.
+func loadCommandConfig(profile string) (*config.Config, func(), error) {
+	cfg, err := resolveCommandProfile(profile)
+	if err != nil {
+		return nil, nil, err
+	}
+	if err := avro.SetBackend(cfg.AvroBackend); err != nil {
+		return nil, nil, err
+	}
+
+	closeTunnel, err := openTunnel(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("ssh tunnel: %w", err)
+	}
+
+	return cfg, closeTunnel, nil
+}
+
+func resolveCommandProfile(profile string) (*config.Config, error) {
+	project, err := config.FindProjectConfig(".")
+	if err != nil {
+		return nil, err
+	}
+	if profile == "" && project != nil {
+		profile = project.Profile
+	}
+
+	configFile, err := config.LoadConfigFile(config.GetConfigPath())
+	if err != nil {
+		if os.IsNotExist(err) && profile == "" {
+			return loadEnvConfig(project)
+		}
+		return nil, fmt.Errorf("loading config file: %w", err)
+	}
+
+	name := profile
+	if name == "" {
+		name = configFile.Default
+	}
+
+	cfg, err := configFile.Config(name)
+	if err != nil {
+		if profile == "" {
+			return loadEnvConfig(project)
+		}
+		return nil, err
+	}
+	cfg.Project = project
+	return cfg, nil
+}
+
+// newRegistryClient returns a registry client that reports throttling on
+// stderr, so a slow bulk command says why
+func newRegistryClient(cfg *config.Config) *registry.Client {
+	client := registry.NewClient(cfg)
+	client.OnThrottle(func(t registry.Throttle) {
+		fmt.Fprintf(os.Stderr, "registry throttled, retrying in %s (attempt %d)\n", t.Wait.Round(time.Second), t.Attempt)
+	})
+	return client
+}
+
+// projectSubjects narrows a registry's subjects to the project's scope and
+// the subjects the configuration allows
+func projectSubjects(cfg *config.Config, subjects []string) []string {
+	var scoped []string
+	for _, subject := range subjects {
+		if cfg.Project.InScope(subject) && cfg.SubjectAllowed(subject) {
+			scoped = append(scoped, subject)
+		}
+	}
+	return scoped
+}
+
+// loadEnvConfig loads the configuration from environment variables
+func loadEnvConfig(project *config.ProjectConfig) (*config.Config, error) {
+	cfg, err := config.Load()
+	if err != nil {
+		return nil, err
+	}
+	cfg.Project = project
+	return cfg, nil
+}
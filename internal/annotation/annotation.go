@@ -0,0 +1,105 @@
+// Package annotation stores notes attached to consumed messages.
+package annotation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"time"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Note is a note attached to a consumed message
+type Note struct {
+	Text    string    `yaml:"text"`
+	Updated time.Time `yaml:"updated"`
+}
+
+// Store holds notes on consumed messages, keyed by profile, topic,
+// partition and offset
+type Store struct {
+	path     string
+	Profiles map[string]map[string]map[int]map[int64]Note
+}
+
+// GetStorePath returns the path to the message notes file
+func GetStorePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "notes.yaml")
+	}
+	return filepath.Join(home, ".config", "avrocado", "notes.yaml")
+}
+
+// LoadStore reads the notes file. A missing file yields an empty store.
+func LoadStore(path string) (*Store, error) {
+	store := &Store{path: path, Profiles: make(map[string]map[string]map[int]map[int64]Note)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("reading notes file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &store.Profiles); err != nil {
+		return nil, fmt.Errorf("parsing notes file: %w", err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]map[string]map[int]map[int64]Note)
+	}
+
+	return store, nil
+}
+
+// Get returns the note on a message, if any
+func (s *Store) Get(profile, topic string, partition int, offset int64) (Note, bool) {
+	note, ok := s.Profiles[profile][topic][partition][offset]
+	return note, ok
+}
+
+// Set attaches a note to a message and writes the file. Empty text removes
+// the message's note.
+func (s *Store) Set(profile, topic string, partition int, offset int64, text string) error {
+	if text == "" {
+		if _, ok := s.Get(profile, topic, partition, offset); !ok {
+			return nil
+		}
+		delete(s.Profiles[profile][topic][partition], offset)
+		return s.save()
+	}
+
+	if s.Profiles[profile] == nil {
+		s.Profiles[profile] = make(map[string]map[int]map[int64]Note)
+	}
+	if s.Profiles[profile][topic] == nil {
+		s.Profiles[profile][topic] = make(map[int]map[int64]Note)
+	}
+	if s.Profiles[profile][topic][partition] == nil {
+		s.Profiles[profile][topic][partition] = make(map[int64]Note)
+	}
+	s.Profiles[profile][topic][partition][offset] = Note{Text: text, Updated: time.Now()}
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s.Profiles)
+	if err != nil {
+		return fmt.Errorf("marshaling notes: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("writing notes file: %w", err)
+	}
+
+	return nil
+}
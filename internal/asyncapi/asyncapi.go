@@ -0,0 +1,417 @@
+// Package asyncapi reads the message schemas of an AsyncAPI 2.x or 3.x
+// document, so a design-first spec can be pushed into the registry.
+package asyncapi
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"reflect"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Schema types, as the registry names them
+const (
+	TypeAvro = "AVRO"
+	TypeJSON = "JSON"
+)
+
+// maxRefDepth bounds $ref chains, which may be cyclic
+const maxRefDepth = 32
+
+// Message is a schema a channel carries: a message's payload, or its Kafka
+// key
+type Message struct {
+	Channel    string // The channel's key in the document
+	Topic      string // The channel's address, else its key
+	Name       string // The message's name, else its key or $ref
+	Key        bool   // The schema is of the Kafka message key
+	SchemaType string // TypeAvro or TypeJSON
+	Schema     string // The schema, with $refs resolved
+	RecordName string // Avro full name, or JSON Schema title
+}
+
+// Document is what an AsyncAPI document defines for the registry
+type Document struct {
+	Version  string // The asyncapi field
+	Messages []Message
+	Skipped  []string // Messages left out, with why
+}
+
+// Load reads an AsyncAPI document in YAML or JSON. External $refs are
+// resolved relative to its directory.
+func Load(path string) (*Document, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	return Parse(data, filepath.Dir(path))
+}
+
+// Parse reads an AsyncAPI document, resolving external $refs relative to
+// dir
+func Parse(data []byte, dir string) (*Document, error) {
+	var root interface{}
+	if err := yaml.Unmarshal(data, &root); err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+	doc, ok := root.(map[string]interface{})
+	if !ok {
+		return nil, fmt.Errorf("not an AsyncAPI document")
+	}
+	version, _ := doc["asyncapi"].(string)
+	if version == "" {
+		return nil, fmt.Errorf("not an AsyncAPI document: no asyncapi version")
+	}
+
+	r := &resolver{dir: dir, files: map[string]interface{}{"": root}}
+	d := &Document{Version: version}
+	channels, _ := doc["channels"].(map[string]interface{})
+	for _, key := range sortedKeys(channels) {
+		ch, _, err := r.resolve(channels[key], "", 0)
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", key, err)
+		}
+		channel, _ := ch.(map[string]interface{})
+		if channel == nil {
+			continue
+		}
+		if strings.HasPrefix(version, "2.") {
+			err = d.addChannelV2(r, key, channel)
+		} else {
+			err = d.addChannelV3(r, key, channel)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("channel %s: %w", key, err)
+		}
+	}
+	return d, nil
+}
+
+// addChannelV2 adds the messages of a 2.x channel's publish and subscribe
+// operations, each a message or a oneOf of them
+func (d *Document) addChannelV2(r *resolver, key string, channel map[string]interface{}) error {
+	for _, op := range []string{"publish", "subscribe"} {
+		operation, _ := channel[op].(map[string]interface{})
+		if operation == nil {
+			continue
+		}
+		msg, name, err := r.resolve(operation["message"], "", 0)
+		if err != nil {
+			return err
+		}
+		messages := []interface{}{msg}
+		names := []string{name}
+		if m, ok := msg.(map[string]interface{}); ok {
+			if oneOf, ok := m["oneOf"].([]interface{}); ok {
+				messages, names = nil, nil
+				for _, item := range oneOf {
+					resolved, name, err := r.resolve(item, "", 0)
+					if err != nil {
+						return err
+					}
+					messages = append(messages, resolved)
+					names = append(names, name)
+				}
+			}
+		}
+		for i, m := range messages {
+			if err := d.addMessage(r, key, key, names[i], m); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// addChannelV3 adds the messages of a 3.x channel, whose address is the
+// topic
+func (d *Document) addChannelV3(r *resolver, key string, channel map[string]interface{}) error {
+	topic, _ := channel["address"].(string)
+	if topic == "" {
+		topic = key
+	}
+	messages, _ := channel["messages"].(map[string]interface{})
+	for _, name := range sortedKeys(messages) {
+		msg, _, err := r.resolve(messages[name], "", 0)
+		if err != nil {
+			return fmt.Errorf("message %s: %w", name, err)
+		}
+		if err := d.addMessage(r, key, topic, name, msg); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+// addMessage adds a message's payload and Kafka key schemas, once per
+// channel
+func (d *Document) addMessage(r *resolver, channel, topic, fallback string, raw interface{}) error {
+	msg, _ := raw.(map[string]interface{})
+	if msg == nil {
+		return nil
+	}
+	name, _ := msg["name"].(string)
+	if name == "" {
+		name, _ = msg["messageId"].(string)
+	}
+	if name == "" {
+		name = fallback
+	}
+	format, _ := msg["schemaFormat"].(string)
+
+	if payload, ok := msg["payload"]; ok {
+		if err := d.addSchema(r, Message{Channel: channel, Topic: topic, Name: name}, format, payload); err != nil {
+			return err
+		}
+	}
+	if bindings, ok := msg["bindings"].(map[string]interface{}); ok {
+		if kafka, ok := bindings["kafka"].(map[string]interface{}); ok {
+			if key, ok := kafka["key"]; ok {
+				if err := d.addSchema(r, Message{Channel: channel, Topic: topic, Name: name, Key: true}, format, key); err != nil {
+					return err
+				}
+			}
+		}
+	}
+	return nil
+}
+
+func (d *Document) addSchema(r *resolver, m Message, format string, raw interface{}) error {
+	schema, err := r.resolveAll(raw, "", make(map[string]bool))
+	if err != nil {
+		return fmt.Errorf("message %s: %w", m.Name, err)
+	}
+	// A 3.x multi-format schema carries its own format
+	if multi, ok := schema.(map[string]interface{}); ok {
+		if f, ok := multi["schemaFormat"].(string); ok {
+			if inner, ok := multi["schema"]; ok {
+				format, schema = f, inner
+			}
+		}
+	}
+
+	what := m.Name
+	if m.Key {
+		what += " key"
+	}
+	m.SchemaType = schemaType(format)
+	if m.SchemaType == "" {
+		d.Skipped = append(d.Skipped, fmt.Sprintf("%s on %s: unsupported schema format %s", what, m.Topic, format))
+		return nil
+	}
+
+	data, err := json.Marshal(schema)
+	if err != nil {
+		return fmt.Errorf("message %s: encoding schema: %w", m.Name, err)
+	}
+	m.Schema = string(data)
+	m.RecordName = recordName(m.SchemaType, schema)
+
+	for _, other := range d.Messages {
+		if other.Topic == m.Topic && other.Key == m.Key && other.Schema == m.Schema {
+			return nil // Published and subscribed alike
+		}
+	}
+	d.Messages = append(d.Messages, m)
+	return nil
+}
+
+// schemaType maps a schemaFormat to a registry schema type, empty if the
+// registry can't take it. AsyncAPI's own schemas are JSON Schema.
+func schemaType(format string) string {
+	format = strings.ToLower(format)
+	switch {
+	case strings.Contains(format, "avro"):
+		return TypeAvro
+	case format == "", strings.HasPrefix(format, "application/vnd.aai.asyncapi"),
+		strings.HasPrefix(format, "application/schema+json"), strings.HasPrefix(format, "application/schema+yaml"):
+		return TypeJSON
+	}
+	return ""
+}
+
+// recordName is the name record-based subject naming uses
+func recordName(schemaType string, schema interface{}) string {
+	s, _ := schema.(map[string]interface{})
+	if schemaType == TypeJSON {
+		title, _ := s["title"].(string)
+		return title
+	}
+	name, _ := s["name"].(string)
+	namespace, _ := s["namespace"].(string)
+	if namespace != "" && !strings.Contains(name, ".") {
+		return namespace + "." + name
+	}
+	return name
+}
+
+// resolver follows $refs within the document and to files beside it
+type resolver struct {
+	dir   string
+	files map[string]interface{} // Parsed documents by path, "" for the root
+}
+
+// resolve follows a $ref, if raw is one, returning the value it points to
+// and the name its last segment gives it. file is the document raw is in.
+func (r *resolver) resolve(raw interface{}, file string, depth int) (interface{}, string, error) {
+	m, ok := raw.(map[string]interface{})
+	ref, isRef := m["$ref"].(string)
+	if !ok || !isRef {
+		return raw, "", nil
+	}
+	if depth > maxRefDepth {
+		return nil, "", fmt.Errorf("$ref %s: too deeply nested or recursive", ref)
+	}
+	value, target, key, err := r.deref(ref, file)
+	if err != nil {
+		return nil, "", err
+	}
+	value, name, err := r.resolve(value, target, depth+1)
+	if name == "" {
+		name = refName(key)
+	}
+	return value, name, err
+}
+
+// deref returns the value a $ref in file points to, the file it is in and
+// the reference's absolute form
+func (r *resolver) deref(ref, file string) (interface{}, string, string, error) {
+	target, pointer, _ := strings.Cut(ref, "#")
+	if target == "" {
+		target = file
+	} else if !filepath.IsAbs(target) {
+		base := r.dir
+		if file != "" {
+			base = filepath.Dir(file)
+		}
+		target = filepath.Join(base, target)
+	}
+	doc, err := r.load(target)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("$ref %s: %w", ref, err)
+	}
+	value, err := lookup(doc, pointer)
+	if err != nil {
+		return nil, "", "", fmt.Errorf("$ref %s: %w", ref, err)
+	}
+	return value, target, target + "#" + pointer, nil
+}
+
+// refName is the name a reference gives what it points to: the pointer's
+// last segment, else the file's base name
+func refName(key string) string {
+	target, pointer, _ := strings.Cut(key, "#")
+	if i := strings.LastIndex(pointer, "/"); i >= 0 && pointer[i+1:] != "" {
+		return pointer[i+1:]
+	}
+	return strings.TrimSuffix(filepath.Base(target), filepath.Ext(target))
+}
+
+// resolveAll replaces every $ref within raw, as schemas sent to the
+// registry must stand alone. expanding holds the references being
+// replaced, to catch recursive schemas.
+func (r *resolver) resolveAll(raw interface{}, file string, expanding map[string]bool) (interface{}, error) {
+	switch v := raw.(type) {
+	case map[string]interface{}:
+		if ref, ok := v["$ref"].(string); ok {
+			value, target, key, err := r.deref(ref, file)
+			if err != nil {
+				return nil, err
+			}
+			if expanding[key] {
+				return nil, fmt.Errorf("$ref %s is recursive, which a standalone schema can't express", ref)
+			}
+			expanding[key] = true
+			defer delete(expanding, key)
+			return r.resolveAll(value, target, expanding)
+		}
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			resolved, err := r.resolveAll(item, file, expanding)
+			if err != nil {
+				return nil, err
+			}
+			out[k] = resolved
+		}
+		return out, nil
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			resolved, err := r.resolveAll(item, file, expanding)
+			if err != nil {
+				return nil, err
+			}
+			out[i] = resolved
+		}
+		return out, nil
+	}
+	return raw, nil
+}
+
+// load parses a referenced file, YAML or JSON (.avsc included), once
+func (r *resolver) load(path string) (interface{}, error) {
+	if doc, ok := r.files[path]; ok {
+		return doc, nil
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+	var doc interface{}
+	if err := yaml.Unmarshal(data, &doc); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", path, err)
+	}
+	r.files[path] = doc
+	return doc, nil
+}
+
+// lookup follows a JSON pointer such as /components/schemas/Order
+func lookup(doc interface{}, pointer string) (interface{}, error) {
+	if pointer == "" || pointer == "/" {
+		return doc, nil
+	}
+	value := doc
+	for _, token := range strings.Split(strings.TrimPrefix(pointer, "/"), "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		switch v := value.(type) {
+		case map[string]interface{}:
+			next, ok := v[token]
+			if !ok {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			value = next
+		case []interface{}:
+			var i int
+			if _, err := fmt.Sscanf(token, "%d", &i); err != nil || i < 0 || i >= len(v) {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			value = v[i]
+		default:
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+	}
+	return value, nil
+}
+
+// Equal reports whether two schemas are the same JSON, ignoring layout
+func Equal(a, b string) bool {
+	var x, y interface{}
+	if json.Unmarshal([]byte(a), &x) != nil || json.Unmarshal([]byte(b), &y) != nil {
+		return a == b
+	}
+	return reflect.DeepEqual(x, y)
+}
+
+func sortedKeys(m map[string]interface{}) []string {
+	keys := make([]string, 0, len(m))
+	for k := range m {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
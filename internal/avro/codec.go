@@ -0,0 +1,236 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Avro backends, selectable per profile with avro_backend
+const (
+	BackendGoavro = "goavro"
+	BackendHamba  = "hamba"
+)
+
+// Codec encodes and decodes Avro binary for one schema. Encode and Decode
+// use JSON with unions wrapped by branch name ({"string": "x"}); the
+// Standard variants use plain JSON with unions unwrapped.
+type Codec interface {
+	Encode(jsonData string) ([]byte, error)
+	Decode(binary []byte) (string, error)
+	EncodeStandard(jsonData string) ([]byte, error)
+	DecodeStandard(binary []byte) (string, error)
+}
+
+var (
+	backendMu sync.RWMutex
+	backend   = BackendGoavro
+)
+
+// SetBackend selects the backend used for all codecs created afterwards.
+// An empty name selects the default, goavro.
+func SetBackend(name string) error {
+	if name == "" {
+		name = BackendGoavro
+	}
+	if name != BackendGoavro && name != BackendHamba {
+		return fmt.Errorf("unknown Avro backend %q (want %s or %s)", name, BackendGoavro, BackendHamba)
+	}
+	backendMu.Lock()
+	backend = name
+	backendMu.Unlock()
+	return nil
+}
+
+// Backend returns the selected backend
+func Backend() string {
+	backendMu.RLock()
+	defer backendMu.RUnlock()
+	return backend
+}
+
+// NewCodec parses a schema with the selected backend
+func NewCodec(schemaJSON string) (Codec, error) {
+	if Backend() == BackendHamba {
+		return newHambaCodec(schemaJSON)
+	}
+	return newGoavroCodec(schemaJSON)
+}
+
+// goavroCodec is the default backend, github.com/linkedin/goavro. The
+// standard JSON codecs are only built when they're first needed.
+type goavroCodec struct {
+	schema      string
+	fixedSchema string // schema with fixed decimals as plain fixed
+	codec       *goavro.Codec
+	encoder     *goavro.Codec // codec for fixedSchema
+	standard    *goavro.Codec
+	standardRaw *goavro.Codec // standard, without logical types
+	natives     *goavroNatives
+}
+
+func newGoavroCodec(schemaJSON string) (Codec, error) {
+	codec, err := goavro.NewCodec(schemaJSON)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	natives, err := newGoavroNatives(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	c := &goavroCodec{schema: schemaJSON, fixedSchema: schemaJSON, codec: codec, encoder: codec, natives: natives}
+	var doc interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &doc); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	if stripFixedDecimals(doc) {
+		stripped, err := json.Marshal(doc)
+		if err != nil {
+			return nil, fmt.Errorf("parsing schema: %w", err)
+		}
+		c.fixedSchema = string(stripped)
+		if c.encoder, err = goavro.NewCodec(c.fixedSchema); err != nil {
+			return nil, fmt.Errorf("parsing schema: %w", err)
+		}
+	}
+	return c, nil
+}
+
+// stripFixedDecimals removes the decimal logical type from fixed types,
+// reporting whether there were any. goavro sizes a fixed decimal of 0 as one
+// byte and fails to encode it, so their bytes are encoded as plain fixed.
+func stripFixedDecimals(schema interface{}) bool {
+	found := false
+	switch s := schema.(type) {
+	case map[string]interface{}:
+		if s["type"] == "fixed" && s["logicalType"] == "decimal" {
+			delete(s, "logicalType")
+			found = true
+		}
+		for k, v := range s {
+			if k != "default" && stripFixedDecimals(v) {
+				found = true
+			}
+		}
+	case []interface{}:
+		for _, v := range s {
+			if stripFixedDecimals(v) {
+				found = true
+			}
+		}
+	}
+	return found
+}
+
+func (c *goavroCodec) standardCodec() (*goavro.Codec, error) {
+	if c.standard == nil {
+		standard, err := goavro.NewCodecForStandardJSONFull(c.fixedSchema)
+		if err != nil {
+			return nil, fmt.Errorf("parsing schema: %w", err)
+		}
+		c.standard = standard
+	}
+	return c.standard, nil
+}
+
+// standardEncoder is the standard JSON codec without logical types, which
+// encodes payloads once their readable logical values are converted
+func (c *goavroCodec) standardEncoder() (*goavro.Codec, error) {
+	if c.standardRaw == nil {
+		var doc interface{}
+		if err := json.Unmarshal([]byte(c.schema), &doc); err != nil {
+			return nil, fmt.Errorf("parsing schema: %w", err)
+		}
+		stripped, err := json.Marshal(stripLogicalTypes(doc))
+		if err != nil {
+			return nil, fmt.Errorf("parsing schema: %w", err)
+		}
+		standard, err := goavro.NewCodecForStandardJSONFull(string(stripped))
+		if err != nil {
+			return nil, fmt.Errorf("parsing schema: %w", err)
+		}
+		c.standardRaw = standard
+	}
+	return c.standardRaw, nil
+}
+
+func (c *goavroCodec) Encode(jsonData string) ([]byte, error) {
+	var native interface{}
+	if err := json.Unmarshal([]byte(jsonData), &native); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	native, err := c.natives.convert(c.natives.schema, "", native)
+	if err != nil {
+		return nil, fmt.Errorf("encoding failed: %w", err)
+	}
+	binary, err := c.encoder.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("encoding failed: %w", err)
+	}
+	return binary, nil
+}
+
+func (c *goavroCodec) Decode(binary []byte) (string, error) {
+	native, _, err := c.codec.NativeFromBinary(binary)
+	if err != nil {
+		return "", fmt.Errorf("decoding failed: %w", err)
+	}
+	jsonBytes, err := json.Marshal(native)
+	if err != nil {
+		return "", fmt.Errorf("converting to JSON: %w", err)
+	}
+	return string(jsonBytes), nil
+}
+
+func (c *goavroCodec) EncodeStandard(jsonData string) ([]byte, error) {
+	standard, err := c.standardEncoder()
+	if err != nil {
+		return nil, err
+	}
+
+	dec := json.NewDecoder(strings.NewReader(jsonData))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	textual := *c.natives
+	textual.textual = true
+	if doc, err = textual.convert(textual.schema, "", doc); err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	converted, err := json.Marshal(doc)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	native, _, err := standard.NativeFromTextual(converted)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	binary, err := standard.BinaryFromNative(nil, native)
+	if err != nil {
+		return nil, fmt.Errorf("encoding failed: %w", err)
+	}
+	return binary, nil
+}
+
+func (c *goavroCodec) DecodeStandard(binary []byte) (string, error) {
+	standard, err := c.standardCodec()
+	if err != nil {
+		return "", err
+	}
+	native, _, err := standard.NativeFromBinary(binary)
+	if err != nil {
+		return "", fmt.Errorf("decoding failed: %w", err)
+	}
+	textual, err := standard.TextualFromNative(nil, native)
+	if err != nil {
+		return "", fmt.Errorf("converting to JSON: %w", err)
+	}
+	return string(textual), nil
+}
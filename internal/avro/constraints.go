@@ -0,0 +1,252 @@
+package avro
+
+import (
+	"fmt"
+	"math/rand"
+	"os"
+	"path/filepath"
+	"regexp/syntax"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Constraints shape the values produced by the random generator so that
+// generated data resembles production distributions. Fields are addressed by
+// the same paths as FlattenFields ("customer.country", "lines[].qty").
+type Constraints struct {
+	Lists  map[string][]interface{}   `yaml:"lists,omitempty"` // Named value lists, e.g. valid country codes
+	Fields map[string]FieldConstraint `yaml:"fields"`
+}
+
+// FieldConstraint restricts the random values of one field. The first rule
+// set wins, in the order list, one_of, weights, regex, min/max.
+type FieldConstraint struct {
+	List    string             `yaml:"list,omitempty"`    // Pick from a named list
+	OneOf   []interface{}      `yaml:"one_of,omitempty"`  // Pick uniformly from these values
+	Weights map[string]float64 `yaml:"weights,omitempty"` // Weighted choice, e.g. enum symbols
+	Regex   string             `yaml:"regex,omitempty"`   // Strings matching this pattern
+	Min     *float64           `yaml:"min,omitempty"`     // Numeric range, inclusive
+	Max     *float64           `yaml:"max,omitempty"`
+}
+
+// GetConstraintsPath returns the default generation config file for a subject
+func GetConstraintsPath(subject string) string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "generators", subject+".yaml")
+	}
+	return filepath.Join(home, ".config", "avrocado", "generators", subject+".yaml")
+}
+
+// LoadConstraints reads a generation config file. A missing file yields nil
+// constraints, which leave every field unconstrained.
+func LoadConstraints(path string) (*Constraints, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return nil, nil
+		}
+		return nil, fmt.Errorf("reading generation config: %w", err)
+	}
+
+	var c Constraints
+	if err := yaml.Unmarshal(data, &c); err != nil {
+		return nil, fmt.Errorf("parsing generation config: %w", err)
+	}
+	if err := c.validate(); err != nil {
+		return nil, fmt.Errorf("%s: %w", path, err)
+	}
+
+	return &c, nil
+}
+
+func (c *Constraints) validate() error {
+	for path, fc := range c.Fields {
+		if fc.List != "" {
+			if _, ok := c.Lists[fc.List]; !ok {
+				return fmt.Errorf("field %s: unknown list %q", path, fc.List)
+			}
+		}
+		if fc.Regex != "" {
+			if _, err := syntax.Parse(fc.Regex, syntax.Perl); err != nil {
+				return fmt.Errorf("field %s: invalid regex: %w", path, err)
+			}
+		}
+		if fc.Min != nil && fc.Max != nil && *fc.Min > *fc.Max {
+			return fmt.Errorf("field %s: min is greater than max", path)
+		}
+	}
+	return nil
+}
+
+// field returns the constraint for a path, if any
+func (c *Constraints) field(path string) (FieldConstraint, bool) {
+	if c == nil {
+		return FieldConstraint{}, false
+	}
+	fc, ok := c.Fields[path]
+	return fc, ok
+}
+
+// pick chooses a value for a constrained field. typeName is the Avro type the
+// value must fit, used to shape numeric ranges. Returns false when the
+// constraint has no rule applicable to the type.
+func (c *Constraints) pick(fc FieldConstraint, typeName string, rng *rand.Rand) (interface{}, bool) {
+	switch {
+	case fc.List != "" && len(c.Lists[fc.List]) > 0:
+		list := c.Lists[fc.List]
+		return list[rng.Intn(len(list))], true
+
+	case len(fc.OneOf) > 0:
+		return fc.OneOf[rng.Intn(len(fc.OneOf))], true
+
+	case len(fc.Weights) > 0:
+		return weightedChoice(fc.Weights, rng), true
+
+	case fc.Regex != "":
+		s, err := randomMatch(fc.Regex, rng)
+		if err != nil {
+			return nil, false
+		}
+		return s, true
+
+	case fc.Min != nil || fc.Max != nil:
+		from, to := 0.0, 1000.0
+		if fc.Min != nil {
+			from = *fc.Min
+		}
+		if fc.Max != nil {
+			to = *fc.Max
+		} else if to < from {
+			to = from + 1000
+		}
+		switch typeName {
+		case "int", "long":
+			lo, hi := int64(from), int64(to)
+			return lo + rng.Int63n(hi-lo+1), true
+		case "float", "double":
+			return roundTo(from+rng.Float64()*(to-from), 2), true
+		}
+	}
+	return nil, false
+}
+
+// weightedChoice picks a key with probability proportional to its weight.
+// Keys are visited in sorted order so seeded runs are reproducible.
+func weightedChoice(weights map[string]float64, rng *rand.Rand) string {
+	keys := make([]string, 0, len(weights))
+	var total float64
+	for k, w := range weights {
+		if w > 0 {
+			keys = append(keys, k)
+			total += w
+		}
+	}
+	if len(keys) == 0 {
+		return ""
+	}
+	sort.Strings(keys)
+
+	r := rng.Float64() * total
+	for _, k := range keys {
+		r -= weights[k]
+		if r < 0 {
+			return k
+		}
+	}
+	return keys[len(keys)-1]
+}
+
+// maxRepeat bounds open-ended regex repetition (*, +, {n,})
+const maxRepeat = 8
+
+// randomMatch generates a random string matching pattern
+func randomMatch(pattern string, rng *rand.Rand) (string, error) {
+	re, err := syntax.Parse(pattern, syntax.Perl)
+	if err != nil {
+		return "", err
+	}
+	var b strings.Builder
+	writeMatch(&b, re.Simplify(), rng)
+	return b.String(), nil
+}
+
+func writeMatch(b *strings.Builder, re *syntax.Regexp, rng *rand.Rand) {
+	switch re.Op {
+	case syntax.OpLiteral:
+		for _, r := range re.Rune {
+			b.WriteRune(r)
+		}
+	case syntax.OpCharClass:
+		b.WriteRune(randomClassRune(re.Rune, rng))
+	case syntax.OpAnyChar, syntax.OpAnyCharNotNL:
+		b.WriteRune(rune('a' + rng.Intn(26)))
+	case syntax.OpCapture:
+		writeMatch(b, re.Sub[0], rng)
+	case syntax.OpConcat:
+		for _, sub := range re.Sub {
+			writeMatch(b, sub, rng)
+		}
+	case syntax.OpAlternate:
+		writeMatch(b, re.Sub[rng.Intn(len(re.Sub))], rng)
+	case syntax.OpStar, syntax.OpPlus, syntax.OpQuest, syntax.OpRepeat:
+		lo, hi := repeatBounds(re)
+		n := lo
+		if hi > lo {
+			n += rng.Intn(hi - lo + 1)
+		}
+		for i := 0; i < n; i++ {
+			writeMatch(b, re.Sub[0], rng)
+		}
+	}
+	// Anchors and word boundaries produce no output
+}
+
+func repeatBounds(re *syntax.Regexp) (int, int) {
+	switch re.Op {
+	case syntax.OpStar:
+		return 0, maxRepeat
+	case syntax.OpPlus:
+		return 1, maxRepeat
+	case syntax.OpQuest:
+		return 0, 1
+	}
+	upper := re.Max
+	if upper < 0 {
+		upper = re.Min + maxRepeat
+	}
+	return re.Min, upper
+}
+
+// randomClassRune picks a rune from a character class given as sorted
+// [lo, hi] pairs. Printable ASCII is preferred, so negated classes like [^,]
+// don't produce control characters.
+func randomClassRune(ranges []rune, rng *rand.Rand) rune {
+	var printable [][2]rune
+	var total int
+	for i := 0; i+1 < len(ranges); i += 2 {
+		lo, hi := max(ranges[i], 0x20), min(ranges[i+1], 0x7e)
+		if lo <= hi {
+			printable = append(printable, [2]rune{lo, hi})
+			total += int(hi-lo) + 1
+		}
+	}
+	if total == 0 {
+		if len(ranges) == 0 {
+			return 'x'
+		}
+		return ranges[0]
+	}
+
+	n := rng.Intn(total)
+	for _, r := range printable {
+		size := int(r[1]-r[0]) + 1
+		if n < size {
+			return r[0] + rune(n)
+		}
+		n -= size
+	}
+	return printable[0][0]
+}
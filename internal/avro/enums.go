@@ -0,0 +1,192 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// EnumError is a payload value that isn't a symbol of its enum
+type EnumError struct {
+	Path       string // Field path, e.g. "order.items[2].status"
+	Enum       string
+	Value      string
+	Symbols    []string
+	Suggestion string // Closest symbol, if one is close enough to be a typo
+}
+
+func (e *EnumError) Error() string {
+	s := fmt.Sprintf("%s: %q is not a symbol of enum %s (allowed: %s)", describe(e.Path), e.Value, e.Enum, strings.Join(e.Symbols, ", "))
+	if e.Suggestion != "" {
+		s += fmt.Sprintf("; did you mean %q?", e.Suggestion)
+	}
+	return s
+}
+
+// CheckEnums finds the enum values in a payload that aren't symbols of
+// their enum. Union values may be wrapped ({"Status": "NEW"}) or plain.
+// Payloads that aren't JSON, or don't match the schema's shape elsewhere,
+// are left to the encoder to report.
+func CheckEnums(schemaJSON, jsonData string) []*EnumError {
+	var schema, doc interface{}
+	if json.Unmarshal([]byte(schemaJSON), &schema) != nil || json.Unmarshal([]byte(jsonData), &doc) != nil {
+		return nil
+	}
+	c := &enumChecker{named: make(map[string]map[string]interface{})}
+	collectNamedTypes(schema, c.named)
+	c.check("", schema, doc)
+	return c.errs
+}
+
+// explainEnums replaces an encoding error with a clearer one when the
+// payload has an invalid enum symbol
+func explainEnums(schemaJSON, jsonData string, err error) error {
+	if err == nil {
+		return nil
+	}
+	if errs := CheckEnums(schemaJSON, jsonData); len(errs) > 0 {
+		return errs[0]
+	}
+	return err
+}
+
+type enumChecker struct {
+	named map[string]map[string]interface{}
+	errs  []*EnumError
+}
+
+func (c *enumChecker) check(path string, schema, value interface{}) {
+	schema = deref(schema, c.named)
+
+	if branches, ok := schema.([]interface{}); ok {
+		c.checkUnion(path, branches, value)
+		return
+	}
+
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return
+	}
+	switch s["type"] {
+	case "enum":
+		if symbol, ok := value.(string); ok {
+			c.checkSymbol(path, s, symbol)
+		}
+	case "record":
+		obj, ok := value.(map[string]interface{})
+		if !ok {
+			return
+		}
+		for _, field := range fieldsOf(s) {
+			name, _ := field["name"].(string)
+			if v, ok := obj[name]; ok {
+				c.check(joinPath(path, name), field["type"], v)
+			}
+		}
+	case "array":
+		items, _ := value.([]interface{})
+		for i, item := range items {
+			c.check(fmt.Sprintf("%s[%d]", path, i), s["items"], item)
+		}
+	case "map":
+		entries, _ := value.(map[string]interface{})
+		for k, v := range entries {
+			c.check(joinPath(path, k), s["values"], v)
+		}
+	}
+}
+
+// checkUnion follows the branch a wrapped value names. A plain string is
+// only checked when no string branch could take it.
+func (c *enumChecker) checkUnion(path string, branches []interface{}, value interface{}) {
+	if name, inner := unionBranch(value); name != "" {
+		if branch := findBranch(branches, name, c.named); branch != nil {
+			c.check(path, branch, inner)
+			return
+		}
+	}
+
+	symbol, ok := value.(string)
+	if !ok {
+		return
+	}
+	var enum map[string]interface{}
+	for _, b := range branches {
+		switch t := typeOf(deref(b, c.named)); t {
+		case "string", "bytes", "fixed":
+			return
+		case "enum":
+			def := deref(b, c.named).(map[string]interface{})
+			if containsSymbol(def, symbol) {
+				return
+			}
+			if enum == nil {
+				enum = def
+			}
+		}
+	}
+	if enum != nil {
+		c.checkSymbol(path, enum, symbol)
+	}
+}
+
+func (c *enumChecker) checkSymbol(path string, enum map[string]interface{}, symbol string) {
+	if containsSymbol(enum, symbol) {
+		return
+	}
+	symbols := stringList(enum["symbols"])
+	c.errs = append(c.errs, &EnumError{
+		Path:       path,
+		Enum:       shortName(recordName(enum)),
+		Value:      symbol,
+		Symbols:    symbols,
+		Suggestion: closestSymbol(symbol, symbols),
+	})
+}
+
+func containsSymbol(enum map[string]interface{}, symbol string) bool {
+	for _, s := range stringList(enum["symbols"]) {
+		if s == symbol {
+			return true
+		}
+	}
+	return false
+}
+
+// closestSymbol returns the symbol nearest to value by edit distance,
+// ignoring case, or "" if none is within a third of the symbol's length
+func closestSymbol(value string, symbols []string) string {
+	best, bestDistance := "", -1
+	for _, s := range symbols {
+		d := levenshtein(strings.ToLower(value), strings.ToLower(s))
+		if d > max(len(s)/3, 1) {
+			continue
+		}
+		if bestDistance < 0 || d < bestDistance {
+			best, bestDistance = s, d
+		}
+	}
+	return best
+}
+
+// levenshtein returns the edit distance between two strings, by rune
+func levenshtein(a, b string) int {
+	ar, br := []rune(a), []rune(b)
+	prev := make([]int, len(br)+1)
+	curr := make([]int, len(br)+1)
+	for j := range prev {
+		prev[j] = j
+	}
+	for i := 1; i <= len(ar); i++ {
+		curr[0] = i
+		for j := 1; j <= len(br); j++ {
+			cost := 1
+			if ar[i-1] == br[j-1] {
+				cost = 0
+			}
+			curr[j] = min(prev[j]+1, curr[j-1]+1, prev[j-1]+cost)
+		}
+		prev, curr = curr, prev
+	}
+	return prev[len(br)]
+}
@@ -0,0 +1,205 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// ParseFields reads a block of field definitions: a JSON array of fields,
+// or a single field. Each needs a valid name and a type.
+func ParseFields(fieldsJSON string) ([]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(fieldsJSON))
+	dec.UseNumber()
+	var parsed interface{}
+	if err := dec.Decode(&parsed); err != nil {
+		return nil, fmt.Errorf("parsing fields: %w", err)
+	}
+	fields, ok := parsed.([]interface{})
+	if !ok {
+		fields = []interface{}{parsed}
+	}
+	if len(fields) == 0 {
+		return nil, fmt.Errorf("no fields")
+	}
+	for i, raw := range fields {
+		field, ok := raw.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("field %d is not an object", i+1)
+		}
+		name, _ := field["name"].(string)
+		if !avroName.MatchString(name) {
+			return nil, fmt.Errorf("field %d: %q is not a valid field name", i+1, name)
+		}
+		if _, ok := field["type"]; !ok {
+			return nil, fmt.Errorf("field %s has no type", name)
+		}
+	}
+	return fields, nil
+}
+
+// InsertFields appends a block of field definitions to a record schema's
+// top-level fields, returning the compacted schema. It fails if the record
+// already has a field of the same name, or if the result isn't valid Avro,
+// as when a named type in the block is already defined.
+func InsertFields(schemaJSON, fieldsJSON string) (string, error) {
+	fields, err := ParseFields(fieldsJSON)
+	if err != nil {
+		return "", err
+	}
+	dec := json.NewDecoder(strings.NewReader(schemaJSON))
+	dec.UseNumber()
+	var schema interface{}
+	if err := dec.Decode(&schema); err != nil {
+		return "", fmt.Errorf("parsing schema: %w", err)
+	}
+	record, ok := schema.(map[string]interface{})
+	if !ok || record["type"] != "record" {
+		return "", fmt.Errorf("schema is not a record")
+	}
+
+	existing, _ := record["fields"].([]interface{})
+	for _, raw := range fields {
+		name := raw.(map[string]interface{})["name"]
+		for _, other := range existing {
+			if f, ok := other.(map[string]interface{}); ok && f["name"] == name {
+				return "", fmt.Errorf("the record already has a field %s", name)
+			}
+		}
+		existing = append(existing, raw)
+	}
+	record["fields"] = existing
+
+	out, err := marshalSchema(record)
+	if err != nil {
+		return "", err
+	}
+	if _, err := NewCodec(out); err != nil {
+		return "", fmt.Errorf("schema with the fields is invalid: %w", err)
+	}
+	return out, nil
+}
+
+// ExtractFields copies top-level fields of a record schema out as a block,
+// indented, for saving as a snippet. Named types the fields use but that
+// are defined elsewhere in the schema are inlined, so the block stands on
+// its own.
+func ExtractFields(schemaJSON string, names []string) (string, error) {
+	dec := json.NewDecoder(strings.NewReader(schemaJSON))
+	dec.UseNumber()
+	var schema interface{}
+	if err := dec.Decode(&schema); err != nil {
+		return "", fmt.Errorf("parsing schema: %w", err)
+	}
+	record, ok := schema.(map[string]interface{})
+	if !ok || record["type"] != "record" {
+		return "", fmt.Errorf("schema is not a record")
+	}
+
+	namedTypes := make(map[string]map[string]interface{})
+	collectNamedTypes(schema, namedTypes)
+	fields, _ := record["fields"].([]interface{})
+
+	var block []interface{}
+	defined := make(map[string]bool)
+	for _, name := range names {
+		var found map[string]interface{}
+		for _, raw := range fields {
+			if f, ok := raw.(map[string]interface{}); ok && f["name"] == name {
+				found = f
+			}
+		}
+		if found == nil {
+			return "", fmt.Errorf("no field %s", name)
+		}
+		field := make(map[string]interface{}, len(found))
+		for k, v := range found {
+			field[k] = v
+		}
+		markDefined(field["type"], defined)
+		field["type"] = inlineNamedTypes(field["type"], namedTypes, defined)
+		block = append(block, field)
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(block); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
+
+// markDefined records the named types a schema defines inline
+func markDefined(schema interface{}, defined map[string]bool) {
+	named := make(map[string]map[string]interface{})
+	collectNamedTypes(schema, named)
+	for name := range named {
+		defined[name] = true
+	}
+}
+
+// inlineNamedTypes replaces the first reference to each named type that
+// isn't defined yet with its definition
+func inlineNamedTypes(schema interface{}, namedTypes map[string]map[string]interface{}, defined map[string]bool) interface{} {
+	switch s := schema.(type) {
+	case string:
+		definition, ok := namedTypes[s]
+		if !ok || defined[s] {
+			return s
+		}
+		markDefined(definition, defined)
+		return inlineNamedTypes(definition, namedTypes, defined)
+	case []interface{}:
+		out := make([]interface{}, len(s))
+		for i, branch := range s {
+			out[i] = inlineNamedTypes(branch, namedTypes, defined)
+		}
+		return out
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			out[k] = v
+		}
+		switch s["type"] {
+		case "record":
+			if fields, ok := s["fields"].([]interface{}); ok {
+				copied := make([]interface{}, len(fields))
+				for i, raw := range fields {
+					f, ok := raw.(map[string]interface{})
+					if !ok {
+						copied[i] = raw
+						continue
+					}
+					field := make(map[string]interface{}, len(f))
+					for k, v := range f {
+						field[k] = v
+					}
+					field["type"] = inlineNamedTypes(f["type"], namedTypes, defined)
+					copied[i] = field
+				}
+				out["fields"] = copied
+			}
+		case "array":
+			out["items"] = inlineNamedTypes(s["items"], namedTypes, defined)
+		case "map":
+			out["values"] = inlineNamedTypes(s["values"], namedTypes, defined)
+		}
+		return out
+	}
+	return schema
+}
+
+// marshalSchema encodes a parsed schema compactly, without escaping HTML in
+// docs
+func marshalSchema(schema interface{}) (string, error) {
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	if err := enc.Encode(schema); err != nil {
+		return "", err
+	}
+	return strings.TrimSpace(out.String()), nil
+}
@@ -0,0 +1,417 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/diff"
+)
+
+// Field describes one field of a record schema, flattened to a dotted path.
+// Fields of nested records appear after their parent, so "customer" is
+// followed by "customer.name". Array and map values are marked with [] and {}
+// ("lines[].sku", "attributes{}.value").
+type Field struct {
+	Path       string
+	Type       string // Human-readable type, e.g. "string", "null|long", "array<LineItem>"
+	Doc        string
+	Default    interface{}
+	HasDefault bool
+	Symbols    []string // Enum symbols, when the field is (or contains) an enum
+	Aliases    []string
+	// Former names of the named types in Type, from their aliases: the
+	// former name to the current one
+	TypeAliases map[string]string
+}
+
+// FieldChange describes how a field differs between two schema versions
+type FieldChange struct {
+	Path    string
+	Kind    diff.Kind
+	Old     *Field
+	New     *Field
+	Details []string // Human-readable list of what changed for diff.Changed
+}
+
+// fieldFlattener holds state while walking a schema
+type fieldFlattener struct {
+	namedTypes map[string]map[string]interface{}
+	visiting   map[string]bool // Records on the current path, to stop at recursive types
+	fields     []Field
+}
+
+// FlattenFields walks a record schema and returns every field depth-first
+func FlattenFields(schemaJSON string) ([]Field, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	f := &fieldFlattener{
+		namedTypes: make(map[string]map[string]interface{}),
+		visiting:   make(map[string]bool),
+	}
+	collectNamedTypes(schema, f.namedTypes)
+
+	record, ok := f.resolve(schema).(map[string]interface{})
+	if !ok || record["type"] != "record" {
+		return nil, fmt.Errorf("schema is not a record")
+	}
+	f.walkRecord("", record)
+
+	return f.fields, nil
+}
+
+// resolve replaces a named type reference with its definition
+func (f *fieldFlattener) resolve(schema interface{}) interface{} {
+	if name, ok := schema.(string); ok {
+		if named, ok := f.namedTypes[name]; ok {
+			return named
+		}
+	}
+	return schema
+}
+
+func (f *fieldFlattener) walkRecord(prefix string, record map[string]interface{}) {
+	name := recordName(record)
+	if f.visiting[name] {
+		return
+	}
+	f.visiting[name] = true
+	defer delete(f.visiting, name)
+
+	fields, _ := record["fields"].([]interface{})
+	for _, raw := range fields {
+		field, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fieldName, _ := field["name"].(string)
+		path := fieldName
+		if prefix != "" {
+			path = prefix + "." + fieldName
+		}
+
+		entry := Field{
+			Path: path,
+			Type: describeType(field["type"]),
+		}
+		entry.Doc, _ = field["doc"].(string)
+		entry.Default, entry.HasDefault = field["default"]
+		entry.Aliases = stringList(field["aliases"])
+		entry.Symbols = f.enumSymbols(field["type"])
+		entry.TypeAliases = f.typeAliases(field["type"], nil)
+		f.fields = append(f.fields, entry)
+
+		f.walkNested(path, field["type"])
+	}
+}
+
+// walkNested descends into records reachable from a field type
+func (f *fieldFlattener) walkNested(path string, schema interface{}) {
+	switch s := f.resolve(schema).(type) {
+	case []interface{}:
+		for _, branch := range s {
+			f.walkNested(path, branch)
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record":
+			f.walkRecord(path, s)
+		case "array":
+			f.walkNested(path+"[]", s["items"])
+		case "map":
+			f.walkNested(path+"{}", s["values"])
+		}
+	}
+}
+
+// enumSymbols returns the symbols of an enum field type, looking through unions
+func (f *fieldFlattener) enumSymbols(schema interface{}) []string {
+	switch s := f.resolve(schema).(type) {
+	case []interface{}:
+		for _, branch := range s {
+			if symbols := f.enumSymbols(branch); symbols != nil {
+				return symbols
+			}
+		}
+	case map[string]interface{}:
+		if s["type"] == "enum" {
+			return stringList(s["symbols"])
+		}
+	}
+	return nil
+}
+
+// typeAliases maps the aliases of the named types in a field type to their
+// names, looking through unions, arrays and maps
+func (f *fieldFlattener) typeAliases(schema interface{}, aliases map[string]string) map[string]string {
+	switch s := f.resolve(schema).(type) {
+	case []interface{}:
+		for _, branch := range s {
+			aliases = f.typeAliases(branch, aliases)
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "enum", "fixed":
+			name := shortName(recordName(s))
+			for _, alias := range stringList(s["aliases"]) {
+				if aliases == nil {
+					aliases = make(map[string]string)
+				}
+				aliases[shortName(alias)] = name
+			}
+		case "array":
+			aliases = f.typeAliases(s["items"], aliases)
+		case "map":
+			aliases = f.typeAliases(s["values"], aliases)
+		}
+	}
+	return aliases
+}
+
+// describeType renders a field type compactly without expanding named types
+func describeType(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		return s
+	case []interface{}:
+		parts := make([]string, len(s))
+		for i, branch := range s {
+			parts[i] = describeType(branch)
+		}
+		return strings.Join(parts, "|")
+	case map[string]interface{}:
+		typeName, _ := s["type"].(string)
+		switch typeName {
+		case "array":
+			return "array<" + describeType(s["items"]) + ">"
+		case "map":
+			return "map<" + describeType(s["values"]) + ">"
+		case "record", "enum", "fixed":
+			name, _ := s["name"].(string)
+			return typeName + " " + name
+		}
+		if logical, ok := s["logicalType"].(string); ok {
+			return typeName + "(" + logical + ")"
+		}
+		return typeName
+	}
+	return fmt.Sprintf("%v", schema)
+}
+
+// RecordName returns the full name of a record schema, or "" if schemaJSON
+// isn't a record
+func RecordName(schemaJSON string) string {
+	var record map[string]interface{}
+	if json.Unmarshal([]byte(schemaJSON), &record) != nil || record["type"] != "record" {
+		return ""
+	}
+	return recordName(record)
+}
+
+func recordName(record map[string]interface{}) string {
+	name, _ := record["name"].(string)
+	if ns, ok := record["namespace"].(string); ok && ns != "" && !strings.Contains(name, ".") {
+		return ns + "." + name
+	}
+	return name
+}
+
+func stringList(v interface{}) []string {
+	list, ok := v.([]interface{})
+	if !ok {
+		return nil
+	}
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+// CompareSchemas returns the field-level changes from oldJSON to newJSON.
+// Changed and added fields follow the new schema's order; removed fields
+// are listed last.
+func CompareSchemas(oldJSON, newJSON string) ([]FieldChange, error) {
+	oldFields, err := FlattenFields(oldJSON)
+	if err != nil {
+		return nil, fmt.Errorf("old schema: %w", err)
+	}
+	newFields, err := FlattenFields(newJSON)
+	if err != nil {
+		return nil, fmt.Errorf("new schema: %w", err)
+	}
+	return CompareFields(oldFields, newFields), nil
+}
+
+// CompareFields returns the changes between two flattened field lists. A
+// field renamed with its old name kept as an alias is a change, not a
+// removal and an addition, as readers still resolve it.
+func CompareFields(oldFields, newFields []Field) []FieldChange {
+	oldByPath := make(map[string]*Field, len(oldFields))
+	for i := range oldFields {
+		oldByPath[oldFields[i].Path] = &oldFields[i]
+	}
+	newPaths := make(map[string]bool, len(newFields))
+	for i := range newFields {
+		newPaths[newFields[i].Path] = true
+	}
+
+	var changes []FieldChange
+	seen := make(map[string]bool)
+	renamed := make(map[string]string) // New paths to old ones, for renamed fields and their children
+	for i := range newFields {
+		nf := &newFields[i]
+		oldPath := oldPathFor(nf, oldByPath, newPaths, renamed)
+		if oldPath != nf.Path {
+			renamed[nf.Path] = oldPath
+		}
+		seen[oldPath] = true
+		of, ok := oldByPath[oldPath]
+		if !ok {
+			changes = append(changes, FieldChange{Path: nf.Path, Kind: diff.Added, New: nf})
+			continue
+		}
+		details := fieldDetails(of, nf)
+		if oldName, newName := lastSegment(oldPath), lastSegment(nf.Path); oldName != newName {
+			details = append([]string{fmt.Sprintf("renamed from `%s` (kept as an alias)", oldName)}, details...)
+		}
+		if len(details) > 0 {
+			changes = append(changes, FieldChange{Path: nf.Path, Kind: diff.Changed, Old: of, New: nf, Details: details})
+		}
+	}
+
+	for i := range oldFields {
+		of := &oldFields[i]
+		if !seen[of.Path] {
+			changes = append(changes, FieldChange{Path: of.Path, Kind: diff.Removed, Old: of})
+		}
+	}
+
+	return changes
+}
+
+// oldPathFor returns the path a field of the new schema had in the old one:
+// the same path under its parent's old path, or the path of a former name
+// the field keeps as an alias, unless another new field took that name
+func oldPathFor(nf *Field, oldByPath map[string]*Field, newPaths map[string]bool, renamed map[string]string) string {
+	parent, name := "", nf.Path
+	if i := strings.LastIndex(nf.Path, "."); i >= 0 {
+		parent, name = nf.Path[:i+1], nf.Path[i+1:]
+	}
+	oldParent := parent
+	if parent != "" {
+		// The parent field's path, without the . and any [] or {} markers
+		field := strings.TrimRight(parent[:len(parent)-1], "[]{}")
+		if old, ok := renamed[field]; ok {
+			oldParent = old + parent[len(field):]
+		}
+	}
+
+	if _, ok := oldByPath[oldParent+name]; ok {
+		return oldParent + name
+	}
+	for _, alias := range nf.Aliases {
+		if _, ok := oldByPath[oldParent+alias]; ok && !newPaths[parent+alias] {
+			return oldParent + alias
+		}
+	}
+	return oldParent + name
+}
+
+// lastSegment returns a field path's own name
+func lastSegment(path string) string {
+	return path[strings.LastIndex(path, ".")+1:]
+}
+
+// renameTypes rewrites the named types of a described type that a later
+// version renamed, keeping the former names as aliases, to their new names
+func renameTypes(described string, aliases map[string]string) string {
+	if len(aliases) == 0 {
+		return described
+	}
+	var b strings.Builder
+	token := 0
+	flush := func(end int) {
+		name := described[token:end]
+		if renamed, ok := aliases[shortName(name)]; ok {
+			name = name[:len(name)-len(shortName(name))] + renamed
+		}
+		b.WriteString(name)
+	}
+	for i, r := range described {
+		if strings.ContainsRune(" |<>", r) {
+			flush(i)
+			b.WriteRune(r)
+			token = i + 1
+		}
+	}
+	flush(len(described))
+	return b.String()
+}
+
+// fieldDetails lists the differences between two versions of the same field
+func fieldDetails(old, new *Field) []string {
+	var details []string
+
+	if old.Type != new.Type {
+		if renamed := renameTypes(old.Type, new.TypeAliases); renamed == new.Type {
+			details = append(details, fmt.Sprintf("type renamed `%s` → `%s` (kept as an alias)", old.Type, new.Type))
+		} else {
+			details = append(details, fmt.Sprintf("type `%s` → `%s`", old.Type, new.Type))
+		}
+	}
+
+	switch {
+	case old.HasDefault && !new.HasDefault:
+		details = append(details, "default removed")
+	case !old.HasDefault && new.HasDefault:
+		details = append(details, fmt.Sprintf("default `%s` added", diff.FormatValue(new.Default, 40)))
+	case old.HasDefault && !reflect.DeepEqual(old.Default, new.Default):
+		details = append(details, fmt.Sprintf("default `%s` → `%s`",
+			diff.FormatValue(old.Default, 40), diff.FormatValue(new.Default, 40)))
+	}
+
+	if added, removed := symbolDelta(old.Symbols, new.Symbols); len(added) > 0 || len(removed) > 0 {
+		if len(added) > 0 {
+			details = append(details, "symbols added: "+strings.Join(added, ", "))
+		}
+		if len(removed) > 0 {
+			details = append(details, "symbols removed: "+strings.Join(removed, ", "))
+		}
+	}
+
+	if old.Doc != new.Doc {
+		details = append(details, "doc updated")
+	}
+
+	return details
+}
+
+func symbolDelta(old, new []string) (added, removed []string) {
+	oldSet := make(map[string]bool, len(old))
+	for _, s := range old {
+		oldSet[s] = true
+	}
+	newSet := make(map[string]bool, len(new))
+	for _, s := range new {
+		newSet[s] = true
+		if !oldSet[s] {
+			added = append(added, s)
+		}
+	}
+	for _, s := range old {
+		if !newSet[s] {
+			removed = append(removed, s)
+		}
+	}
+	sort.Strings(added)
+	sort.Strings(removed)
+	return added, removed
+}
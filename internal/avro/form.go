@@ -0,0 +1,174 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+)
+
+// Kinds of form fields
+const (
+	FormRecord = "record" // A nested record, whose fields follow it
+	FormValue  = "value"  // A primitive or enum, entered as text
+	FormJSON   = "json"   // An array, map or union of several types, entered as JSON
+)
+
+// FormField is one field of a record schema as a form edits it. Fields of
+// nested records follow their parent, one level deeper.
+type FormField struct {
+	Path     []string // Field names from the top-level record
+	Type     string   // Human-readable type, as in Field
+	Kind     string
+	Scalar   string   // The primitive a FormValue is encoded as, "enum", or a logical type in readable form
+	Symbols  []string // Enum symbols
+	Nullable bool     // A union of null and one other type, which can be toggled
+	Branch   string   // The other type's label, which wraps its values in Avro's JSON encoding
+}
+
+// Depth is how deeply a field is nested, 0 for top-level fields
+func (f FormField) Depth() int {
+	return len(f.Path) - 1
+}
+
+// formWalker holds state while listing form fields
+type formWalker struct {
+	namedTypes map[string]map[string]interface{}
+	visiting   map[string]bool // Records on the current path; recursive ones are entered as JSON
+	fields     []FormField
+}
+
+// FormFields lists the fields of a record schema for a form, depth-first
+func FormFields(schemaJSON string) ([]FormField, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	w := &formWalker{
+		namedTypes: make(map[string]map[string]interface{}),
+		visiting:   make(map[string]bool),
+	}
+	collectNamedTypes(schema, w.namedTypes)
+
+	record, namespace := w.resolve(schema, "")
+	r, ok := record.(map[string]interface{})
+	if !ok || (r["type"] != "record" && r["type"] != "error") {
+		return nil, fmt.Errorf("schema is not a record")
+	}
+	w.walkRecord(nil, r, namespace)
+	return w.fields, nil
+}
+
+// resolve replaces a named type reference with its definition and returns
+// the namespace names inside it resolve in
+func (w *formWalker) resolve(schema interface{}, namespace string) (interface{}, string) {
+	switch s := schema.(type) {
+	case string:
+		if named, full, ok := lookupNamed(w.namedTypes, s, namespace); ok {
+			return named, namespaceOf(full)
+		}
+	case map[string]interface{}:
+		if _, named := s["name"].(string); named {
+			return s, namespaceOf(fullName(s, namespace))
+		}
+	}
+	return schema, namespace
+}
+
+func (w *formWalker) walkRecord(path []string, record map[string]interface{}, namespace string) {
+	name := fullName(record, namespace)
+	w.visiting[name] = true
+	defer delete(w.visiting, name)
+
+	fields, _ := record["fields"].([]interface{})
+	for _, raw := range fields {
+		field, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fieldName, _ := field["name"].(string)
+		fieldPath := append(append([]string(nil), path...), fieldName)
+		w.walkField(fieldPath, field["type"], namespace)
+	}
+}
+
+func (w *formWalker) walkField(path []string, schema interface{}, namespace string) {
+	entry := FormField{Path: path, Type: describeType(schema), Kind: FormJSON}
+
+	if branches, ok := schema.([]interface{}); ok {
+		other, nullable := nullableBranch(branches)
+		if !nullable {
+			w.fields = append(w.fields, entry)
+			return
+		}
+		entry.Nullable = true
+		entry.Branch = w.branchName(other, namespace)
+		schema = other
+	}
+
+	resolved, ns := w.resolve(schema, namespace)
+	switch s := resolved.(type) {
+	case string:
+		if isPrimitive(s) && s != "null" {
+			entry.Kind, entry.Scalar = FormValue, s
+		}
+	case map[string]interface{}:
+		typeName, _ := s["type"].(string)
+		switch {
+		case typeName == "record" || typeName == "error":
+			if !w.visiting[fullName(s, ns)] {
+				entry.Kind = FormRecord
+				w.fields = append(w.fields, entry)
+				w.walkRecord(path, s, ns)
+				return
+			}
+		case typeName == "enum":
+			entry.Kind, entry.Scalar, entry.Symbols = FormValue, "enum", stringList(s["symbols"])
+		case typeName == "fixed":
+			entry.Kind, entry.Scalar = FormValue, "bytes"
+		case isPrimitive(typeName) && typeName != "null":
+			entry.Kind, entry.Scalar = FormValue, typeName
+			if _, readable := logicalPlaceholder(s); readable {
+				entry.Scalar, _ = s["logicalType"].(string) // Entered as text, e.g. an ISO timestamp
+			}
+		}
+	}
+	w.fields = append(w.fields, entry)
+}
+
+// nullableBranch returns the other branch of a union of null and one type
+func nullableBranch(branches []interface{}) (interface{}, bool) {
+	if len(branches) != 2 {
+		return nil, false
+	}
+	for i, branch := range branches {
+		if branch == "null" {
+			return branches[1-i], branches[1-i] != "null"
+		}
+	}
+	return nil, false
+}
+
+// branchName is the name a union branch goes by: a primitive's type or a
+// named type's full name
+func (w *formWalker) branchName(schema interface{}, namespace string) string {
+	switch s := schema.(type) {
+	case string:
+		if isPrimitive(s) {
+			return s
+		}
+		if _, full, ok := lookupNamed(w.namedTypes, s, namespace); ok {
+			return full
+		}
+		return s
+	case map[string]interface{}:
+		if _, ok := s["name"].(string); ok {
+			return fullName(s, namespace)
+		}
+		typeName, _ := s["type"].(string)
+		if logical, ok := s["logicalType"].(string); ok {
+			return typeName + "." + logical // As goavro labels it
+		}
+		return typeName
+	}
+	return ""
+}
@@ -0,0 +1,701 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"reflect"
+	"sort"
+	"strconv"
+	"strings"
+	"unicode/utf8"
+
+	hamba "github.com/hamba/avro/v2"
+)
+
+// hambaCodec is the github.com/hamba/avro backend. Values are converted
+// between JSON and hamba's generic types by walking the schema, so unions
+// read the same as with goavro and errors name the field they are about.
+// Binary is encoded with logical types stripped from the schema, which
+// keeps timestamps, dates and decimals as their underlying numbers and
+// bytes in JSON.
+type hambaCodec struct {
+	schema hamba.Schema // As written, for union branch labels
+	raw    hamba.Schema // Without logical types
+}
+
+func newHambaCodec(schemaJSON string) (Codec, error) {
+	schema, err := hamba.ParseWithCache(schemaJSON, "", &hamba.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &doc); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	stripped, err := json.Marshal(stripLogicalTypes(doc))
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	raw, err := hamba.ParseBytesWithCache(stripped, "", &hamba.SchemaCache{})
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	return &hambaCodec{schema: schema, raw: raw}, nil
+}
+
+// stripLogicalTypes removes logicalType annotations from a parsed schema,
+// leaving field defaults alone
+func stripLogicalTypes(schema interface{}) interface{} {
+	switch s := schema.(type) {
+	case map[string]interface{}:
+		delete(s, "logicalType")
+		for k, v := range s {
+			if k != "default" {
+				s[k] = stripLogicalTypes(v)
+			}
+		}
+	case []interface{}:
+		for i, v := range s {
+			s[i] = stripLogicalTypes(v)
+		}
+	}
+	return schema
+}
+
+func (c *hambaCodec) Encode(jsonData string) ([]byte, error) {
+	return c.encode(jsonData, true)
+}
+
+func (c *hambaCodec) EncodeStandard(jsonData string) ([]byte, error) {
+	return c.encode(jsonData, false)
+}
+
+func (c *hambaCodec) Decode(binary []byte) (string, error) {
+	return c.decode(binary, true)
+}
+
+func (c *hambaCodec) DecodeStandard(binary []byte) (string, error) {
+	return c.decode(binary, false)
+}
+
+func (c *hambaCodec) encode(jsonData string, wrapped bool) ([]byte, error) {
+	dec := json.NewDecoder(strings.NewReader(jsonData))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+
+	native, err := toNative(c.schema, doc, wrapped, "")
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	binary, err := hamba.Marshal(c.raw, native)
+	if err != nil {
+		return nil, fmt.Errorf("encoding failed: %w", err)
+	}
+	return binary, nil
+}
+
+func (c *hambaCodec) decode(binary []byte, wrapped bool) (string, error) {
+	var native interface{}
+	if err := hamba.Unmarshal(c.raw, binary, &native); err != nil {
+		return "", fmt.Errorf("decoding failed: %w", err)
+	}
+
+	var buf bytes.Buffer
+	if err := writeJSON(&buf, c.schema, native, wrapped); err != nil {
+		return "", fmt.Errorf("converting to JSON: %w", err)
+	}
+	return buf.String(), nil
+}
+
+// toNative converts a JSON value to the generic value hamba encodes for a
+// schema. Unions become single-entry maps keyed by branch name.
+func toNative(schema hamba.Schema, v interface{}, wrapped bool, path string) (interface{}, error) {
+	schema = hambaDeref(schema)
+
+	// Logical types may be written in readable form
+	if lts, ok := schema.(hamba.LogicalTypeSchema); ok && lts.Logical() != nil {
+		scale, size := 0, 0
+		if decimal, ok := lts.Logical().(*hamba.DecimalLogicalSchema); ok {
+			scale = decimal.Scale()
+		}
+		if fixed, ok := schema.(*hamba.FixedSchema); ok {
+			size = fixed.Size()
+		}
+		converted, ok, err := readableLogical(string(lts.Logical().Type()), scale, size, v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", describe(path), err)
+		}
+		if n, isInt := converted.(int64); isInt {
+			converted = json.Number(strconv.FormatInt(n, 10))
+		}
+		if ok {
+			v = converted
+		}
+	}
+
+	switch schema.Type() {
+	case hamba.Null:
+		if v != nil {
+			return nil, mismatch(path, "null", v)
+		}
+		return struct{}{}, nil
+
+	case hamba.Boolean:
+		b, ok := v.(bool)
+		if !ok {
+			return nil, mismatch(path, "boolean", v)
+		}
+		return b, nil
+
+	case hamba.Int:
+		n, ok := integer(v)
+		if !ok || n < math.MinInt32 || n > math.MaxInt32 {
+			return nil, mismatch(path, "int", v)
+		}
+		return int32(n), nil
+
+	case hamba.Long:
+		n, ok := integer(v)
+		if !ok {
+			return nil, mismatch(path, "long", v)
+		}
+		return n, nil
+
+	case hamba.Float, hamba.Double:
+		num, ok := v.(json.Number)
+		if !ok {
+			return nil, mismatch(path, string(schema.Type()), v)
+		}
+		f, err := num.Float64()
+		if err != nil {
+			return nil, mismatch(path, string(schema.Type()), v)
+		}
+		if schema.Type() == hamba.Float {
+			return float32(f), nil
+		}
+		return f, nil
+
+	case hamba.String:
+		s, ok := v.(string)
+		if !ok {
+			return nil, mismatch(path, "string", v)
+		}
+		return s, nil
+
+	case hamba.Bytes:
+		b, err := jsonBytes(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", describe(path), err)
+		}
+		return b, nil
+
+	case hamba.Fixed:
+		fixed := schema.(*hamba.FixedSchema)
+		b, err := jsonBytes(v)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", describe(path), err)
+		}
+		if len(b) != fixed.Size() {
+			return nil, fmt.Errorf("%s: %s needs %d bytes, got %d", describe(path), fixed.FullName(), fixed.Size(), len(b))
+		}
+		arr := reflect.New(reflect.ArrayOf(fixed.Size(), reflect.TypeOf(byte(0)))).Elem()
+		reflect.Copy(arr, reflect.ValueOf(b))
+		return arr.Interface(), nil
+
+	case hamba.Enum:
+		enum := schema.(*hamba.EnumSchema)
+		s, ok := v.(string)
+		if !ok {
+			return nil, mismatch(path, enum.FullName(), v)
+		}
+		for _, symbol := range enum.Symbols() {
+			if s == symbol {
+				return s, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: %q is not a symbol of %s (%s)", describe(path), s, enum.FullName(), strings.Join(enum.Symbols(), ", "))
+
+	case hamba.Array:
+		items, ok := v.([]interface{})
+		if !ok {
+			return nil, mismatch(path, "array", v)
+		}
+		out := make([]interface{}, len(items))
+		for i, item := range items {
+			var err error
+			if out[i], err = toNative(schema.(*hamba.ArraySchema).Items(), item, wrapped, fmt.Sprintf("%s[%d]", path, i)); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+
+	case hamba.Map:
+		entries, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, mismatch(path, "map", v)
+		}
+		out := make(map[string]interface{}, len(entries))
+		for k, item := range entries {
+			var err error
+			if out[k], err = toNative(schema.(*hamba.MapSchema).Values(), item, wrapped, joinPath(path, k)); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+
+	case hamba.Record:
+		record := schema.(*hamba.RecordSchema)
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return nil, mismatch(path, record.FullName(), v)
+		}
+		out := make(map[string]interface{}, len(record.Fields()))
+		for _, field := range record.Fields() {
+			fv, present := obj[field.Name()]
+			if !present {
+				if !field.HasDefault() {
+					return nil, fmt.Errorf("%s: missing required field", describe(joinPath(path, field.Name())))
+				}
+				// Fill the default in ourselves: hamba can't encode nulls
+				// nested inside record defaults
+				var err error
+				if out[field.Name()], err = defaultNative(field, joinPath(path, field.Name())); err != nil {
+					return nil, err
+				}
+				continue
+			}
+			var err error
+			if out[field.Name()], err = toNative(field.Type(), fv, wrapped, joinPath(path, field.Name())); err != nil {
+				return nil, err
+			}
+		}
+		return out, nil
+
+	case hamba.Union:
+		return unionToNative(schema.(*hamba.UnionSchema), v, wrapped, path)
+	}
+	return nil, fmt.Errorf("%s: unsupported type %s", describe(path), schema.Type())
+}
+
+// unionToNative picks the branch of a union a value belongs to: the one it
+// is wrapped in, if wrapped, otherwise the first it converts to
+func unionToNative(union *hamba.UnionSchema, v interface{}, wrapped bool, path string) (interface{}, error) {
+	if v == nil {
+		for _, branch := range union.Types() {
+			if branch.Type() == hamba.Null {
+				return map[string]interface{}{}, nil
+			}
+		}
+		return nil, fmt.Errorf("%s: null is not allowed", describe(path))
+	}
+
+	if obj, ok := v.(map[string]interface{}); ok && wrapped && len(obj) == 1 {
+		for label, inner := range obj {
+			for _, branch := range union.Types() {
+				if label == branchName(branch) || label == branchLabel(branch) {
+					native, err := toNative(branch, inner, wrapped, path)
+					if err != nil {
+						return nil, err
+					}
+					return map[string]interface{}{branchName(branch): native}, nil
+				}
+			}
+		}
+	}
+
+	var labels []string
+	for _, branch := range union.Types() {
+		labels = append(labels, branchLabel(branch))
+		if branch.Type() == hamba.Null {
+			continue
+		}
+		if native, err := toNative(branch, v, wrapped, path); err == nil {
+			return map[string]interface{}{branchName(branch): native}, nil
+		}
+	}
+	return nil, fmt.Errorf("%s: %s matches none of the union's types (%s)", describe(path), jsonKind(v), strings.Join(labels, ", "))
+}
+
+// writeJSON writes a decoded value as JSON in schema field order
+func writeJSON(buf *bytes.Buffer, schema hamba.Schema, v interface{}, wrapped bool) error {
+	schema = hambaDeref(schema)
+
+	switch schema.Type() {
+	case hamba.Null:
+		buf.WriteString("null")
+		return nil
+
+	case hamba.Bytes, hamba.Fixed:
+		rv := reflect.ValueOf(v)
+		if rv.Kind() != reflect.Slice && rv.Kind() != reflect.Array {
+			return fmt.Errorf("unexpected %T for %s", v, schema.Type())
+		}
+		runes := make([]rune, rv.Len())
+		for i := range runes {
+			runes[i] = rune(rv.Index(i).Uint())
+		}
+		return writeScalar(buf, string(runes))
+
+	case hamba.Array:
+		items, _ := v.([]interface{})
+		buf.WriteByte('[')
+		for i, item := range items {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			if err := writeJSON(buf, schema.(*hamba.ArraySchema).Items(), item, wrapped); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte(']')
+		return nil
+
+	case hamba.Map:
+		entries, _ := v.(map[string]interface{})
+		keys := make([]string, 0, len(entries))
+		for k := range entries {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		buf.WriteByte('{')
+		for i, k := range keys {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeScalar(buf, k)
+			buf.WriteByte(':')
+			if err := writeJSON(buf, schema.(*hamba.MapSchema).Values(), entries[k], wrapped); err != nil {
+				return err
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case hamba.Record:
+		obj, _ := v.(map[string]interface{})
+		buf.WriteByte('{')
+		for i, field := range schema.(*hamba.RecordSchema).Fields() {
+			if i > 0 {
+				buf.WriteByte(',')
+			}
+			writeScalar(buf, field.Name())
+			buf.WriteByte(':')
+			if err := writeJSON(buf, field.Type(), obj[field.Name()], wrapped); err != nil {
+				return fmt.Errorf("%s: %w", field.Name(), err)
+			}
+		}
+		buf.WriteByte('}')
+		return nil
+
+	case hamba.Union:
+		branch, inner := decodedBranch(schema.(*hamba.UnionSchema), v)
+		if branch == nil {
+			return fmt.Errorf("no union branch for %T", v)
+		}
+		if branch.Type() == hamba.Null || !wrapped {
+			return writeJSON(buf, branch, inner, wrapped)
+		}
+		buf.WriteByte('{')
+		writeScalar(buf, branchLabel(branch))
+		buf.WriteByte(':')
+		if err := writeJSON(buf, branch, inner, wrapped); err != nil {
+			return err
+		}
+		buf.WriteByte('}')
+		return nil
+	}
+	return writeScalar(buf, v)
+}
+
+// decodedBranch finds the union branch of a value hamba decoded: named and
+// complex types come back wrapped in a map keyed by branch name, primitives
+// as plain values
+func decodedBranch(union *hamba.UnionSchema, v interface{}) (hamba.Schema, interface{}) {
+	if v == nil {
+		for _, branch := range union.Types() {
+			if branch.Type() == hamba.Null {
+				return branch, nil
+			}
+		}
+		return nil, nil
+	}
+	if obj, ok := v.(map[string]interface{}); ok && len(obj) == 1 {
+		for name, inner := range obj {
+			for _, branch := range union.Types() {
+				if t := hambaDeref(branch).Type(); t != hamba.Map && name == branchName(branch) {
+					return branch, inner
+				}
+			}
+		}
+	}
+
+	var want hamba.Type
+	switch v.(type) {
+	case bool:
+		want = hamba.Boolean
+	case int, int32:
+		want = hamba.Int
+	case int64:
+		want = hamba.Long
+	case float32:
+		want = hamba.Float
+	case float64:
+		want = hamba.Double
+	case string:
+		want = hamba.String
+	case []byte:
+		want = hamba.Bytes
+	case []interface{}:
+		want = hamba.Array
+	case map[string]interface{}:
+		want = hamba.Map
+	}
+	for _, branch := range union.Types() {
+		if hambaDeref(branch).Type() == want {
+			return branch, v
+		}
+	}
+	return nil, nil
+}
+
+// writeScalar writes a JSON string, number or boolean. Strings aren't
+// HTML-escaped, matching goavro's output.
+func writeScalar(buf *bytes.Buffer, v interface{}) error {
+	var b []byte
+	switch v := v.(type) {
+	case string:
+		b = appendString(buf.AvailableBuffer(), v)
+	case bool:
+		b = strconv.AppendBool(buf.AvailableBuffer(), v)
+	case int:
+		b = strconv.AppendInt(buf.AvailableBuffer(), int64(v), 10)
+	case int32:
+		b = strconv.AppendInt(buf.AvailableBuffer(), int64(v), 10)
+	case int64:
+		b = strconv.AppendInt(buf.AvailableBuffer(), v, 10)
+	case float32:
+		return appendFloat(buf, float64(v), 32)
+	case float64:
+		return appendFloat(buf, v, 64)
+	default:
+		return fmt.Errorf("unexpected %T", v)
+	}
+	buf.Write(b)
+	return nil
+}
+
+// appendFloat writes a float the way encoding/json does
+func appendFloat(buf *bytes.Buffer, f float64, bits int) error {
+	if math.IsNaN(f) || math.IsInf(f, 0) {
+		return fmt.Errorf("%v can't be written as JSON", f)
+	}
+	format := byte('f')
+	if abs := math.Abs(f); abs != 0 && (abs < 1e-6 || abs >= 1e21) {
+		format = 'e'
+	}
+	b := strconv.AppendFloat(buf.AvailableBuffer(), f, format, -1, bits)
+	if format == 'e' {
+		// Clean up e-09 to e-9
+		if n := len(b); n >= 4 && b[n-4] == 'e' && b[n-3] == '-' && b[n-2] == '0' {
+			b[n-2] = b[n-1]
+			b = b[:n-1]
+		}
+	}
+	buf.Write(b)
+	return nil
+}
+
+// appendString appends s as a quoted JSON string
+func appendString(b []byte, s string) []byte {
+	const hex = "0123456789abcdef"
+	b = append(b, '"')
+	start := 0
+	for i := 0; i < len(s); {
+		c := s[i]
+		if c >= 0x20 && c != '"' && c != '\\' && c < utf8.RuneSelf {
+			i++
+			continue
+		}
+		if c < utf8.RuneSelf {
+			b = append(b, s[start:i]...)
+			switch c {
+			case '"', '\\':
+				b = append(b, '\\', c)
+			case '\n':
+				b = append(b, '\\', 'n')
+			case '\r':
+				b = append(b, '\\', 'r')
+			case '\t':
+				b = append(b, '\\', 't')
+			default:
+				b = append(b, '\\', 'u', '0', '0', hex[c>>4], hex[c&0xf])
+			}
+			i++
+			start = i
+			continue
+		}
+		r, size := utf8.DecodeRuneInString(s[i:])
+		if r == utf8.RuneError && size == 1 {
+			b = append(b, s[start:i]...)
+			b = append(b, `\ufffd`...)
+			i += size
+			start = i
+			continue
+		}
+		i += size
+	}
+	b = append(b, s[start:]...)
+	return append(b, '"')
+}
+
+func hambaDeref(schema hamba.Schema) hamba.Schema {
+	if ref, ok := schema.(*hamba.RefSchema); ok {
+		return ref.Schema()
+	}
+	return schema
+}
+
+// branchName is how hamba names a union branch in the raw schema: the full
+// name of named types, otherwise the type
+func branchName(schema hamba.Schema) string {
+	schema = hambaDeref(schema)
+	if named, ok := schema.(hamba.NamedSchema); ok {
+		return named.FullName()
+	}
+	return string(schema.Type())
+}
+
+// branchLabel is how goavro labels a union branch in wrapped JSON, which
+// includes the logical type ("long.timestamp-millis")
+func branchLabel(schema hamba.Schema) string {
+	name := branchName(schema)
+	if _, named := hambaDeref(schema).(hamba.NamedSchema); named {
+		return name
+	}
+	if lts, ok := hambaDeref(schema).(hamba.LogicalTypeSchema); ok && lts.Logical() != nil {
+		name += "." + string(lts.Logical().Type())
+	}
+	return name
+}
+
+// integer reads a JSON number with no fractional part
+func integer(v interface{}) (int64, bool) {
+	num, ok := v.(json.Number)
+	if !ok {
+		return 0, false
+	}
+	if n, err := num.Int64(); err == nil {
+		return n, true
+	}
+	f, err := num.Float64()
+	if err != nil || f != math.Trunc(f) || f < math.MinInt64 || f >= math.MaxInt64 {
+		return 0, false
+	}
+	return int64(f), true
+}
+
+// defaultNative converts a field's default, as hamba parsed it, to native
+// form. Defaults are plain JSON: a union default is for its first branch.
+func defaultNative(field *hamba.Field, path string) (interface{}, error) {
+	return toNative(field.Type(), plainJSON(field.Default()), false, path)
+}
+
+// plainJSON turns a parsed default back into what json.Decoder with
+// UseNumber would produce
+func plainJSON(v interface{}) interface{} {
+	switch v := v.(type) {
+	case []byte:
+		runes := make([]rune, len(v))
+		for i, b := range v {
+			runes[i] = rune(b)
+		}
+		return string(runes)
+	case int:
+		return json.Number(strconv.Itoa(v))
+	case int32:
+		return json.Number(strconv.FormatInt(int64(v), 10))
+	case int64:
+		return json.Number(strconv.FormatInt(v, 10))
+	case float32:
+		return json.Number(strconv.FormatFloat(float64(v), 'g', -1, 32))
+	case float64:
+		return json.Number(strconv.FormatFloat(v, 'g', -1, 64))
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = plainJSON(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = plainJSON(item)
+		}
+		return out
+	case json.Marshaler:
+		// hamba's marker for a null nested inside a default
+		if b, err := v.MarshalJSON(); err == nil && string(b) == "null" {
+			return nil
+		}
+	}
+	return v
+}
+
+// jsonBytes reads Avro JSON bytes: a string of code points 0-255
+func jsonBytes(v interface{}) ([]byte, error) {
+	s, ok := v.(string)
+	if !ok {
+		return nil, fmt.Errorf("expected bytes as a string, got %s", jsonKind(v))
+	}
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 255 {
+			return nil, fmt.Errorf("%q is not a byte (bytes are written as code points 0-255)", r)
+		}
+		b = append(b, byte(r))
+	}
+	return b, nil
+}
+
+func mismatch(path, want string, v interface{}) error {
+	return fmt.Errorf("%s: expected %s, got %s", describe(path), want, jsonKind(v))
+}
+
+// jsonKind describes a JSON value for error messages
+func jsonKind(v interface{}) string {
+	switch v := v.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number " + v.String()
+	case string:
+		return fmt.Sprintf("string %q", v)
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", v)
+}
+
+func joinPath(path, name string) string {
+	if path == "" {
+		return name
+	}
+	return path + "." + name
+}
+
+func describe(path string) string {
+	if path == "" {
+		return "value"
+	}
+	return path
+}
@@ -0,0 +1,552 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"math/big"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// Logical types can be written in payloads in a readable form instead of
+// their underlying values: timestamps as ISO 8601 ("2024-05-01T09:30:00Z",
+// without a zone for local timestamps), dates as "2024-05-01", times of day
+// as "09:30:00.250" and decimals as "12.34". Numbers and, for decimals,
+// Avro's JSON bytes still work.
+
+// Placeholders templates use for logical types
+const (
+	templateTimestamp      = "2024-01-01T00:00:00Z"
+	templateLocalTimestamp = "2024-01-01T00:00:00"
+	templateDate           = "2024-01-01"
+	templateUUID           = "00000000-0000-4000-8000-000000000000"
+)
+
+// logicalPlaceholder is the template value for a logical type, reporting
+// false for types without a readable form
+func logicalPlaceholder(schema map[string]interface{}) (interface{}, bool) {
+	logicalType, _ := schema["logicalType"].(string)
+	switch logicalType {
+	case "timestamp-millis", "timestamp-micros":
+		return templateTimestamp, true
+	case "local-timestamp-millis", "local-timestamp-micros":
+		return templateLocalTimestamp, true
+	case "date":
+		return templateDate, true
+	case "time-millis":
+		return "00:00:00.000", true
+	case "time-micros":
+		return "00:00:00.000000", true
+	case "uuid":
+		return templateUUID, true
+	case "decimal":
+		return formatDecimal(new(big.Int), decimalScale(schema)), true
+	}
+	return nil, false
+}
+
+func decimalScale(schema map[string]interface{}) int {
+	scale, _ := schema["scale"].(float64)
+	return int(scale)
+}
+
+// formatDecimal writes an unscaled decimal value with scale digits after
+// the point
+func formatDecimal(unscaled *big.Int, scale int) string {
+	if scale <= 0 {
+		return unscaled.String()
+	}
+	return new(big.Rat).SetFrac(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)).FloatString(scale)
+}
+
+// parseTimestamp reads an ISO 8601 timestamp; local timestamps have no zone
+func parseTimestamp(s string, local bool) (time.Time, error) {
+	layouts := []string{time.RFC3339Nano, "2006-01-02T15:04:05.999999999"}
+	if local {
+		layouts = []string{"2006-01-02T15:04:05.999999999", time.RFC3339Nano}
+	}
+	for _, layout := range layouts {
+		if t, err := time.Parse(layout, s); err == nil {
+			return t, nil
+		}
+	}
+	return time.Time{}, fmt.Errorf("%q is not an ISO 8601 timestamp (e.g. %s)", s, templateTimestamp)
+}
+
+// parseTimeOfDay reads a time of day as the duration since midnight
+func parseTimeOfDay(s string) (time.Duration, error) {
+	t, err := time.Parse("15:04:05.999999999", s)
+	if err != nil {
+		if t, err = time.Parse("15:04", s); err != nil {
+			return 0, fmt.Errorf("%q is not a time of day (e.g. 09:30:00.250)", s)
+		}
+	}
+	return time.Duration(t.Hour())*time.Hour + time.Duration(t.Minute())*time.Minute +
+		time.Duration(t.Second())*time.Second + time.Duration(t.Nanosecond()), nil
+}
+
+// parseDecimal reads a decimal as a rational, from a number, a decimal
+// string or, as Avro's JSON has it, the bytes of its unscaled value
+func parseDecimal(v interface{}, scale int) (*big.Rat, error) {
+	switch d := v.(type) {
+	case json.Number:
+		return parseDecimal(d.String(), scale)
+	case float64:
+		return parseDecimal(strconv.FormatFloat(d, 'f', -1, 64), scale)
+	case string:
+		if r, ok := new(big.Rat).SetString(d); ok {
+			return r, nil
+		}
+		unscaled, err := decimalBytes(d)
+		if err != nil {
+			return nil, err
+		}
+		return new(big.Rat).SetFrac(unscaled, new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)), nil
+	}
+	return nil, fmt.Errorf("expected a decimal such as \"12.34\", got %s", jsonKind(v))
+}
+
+// decimalBytes reads a decimal's unscaled value from its big-endian two's
+// complement bytes, written one character per byte
+func decimalBytes(s string) (*big.Int, error) {
+	b := make([]byte, 0, len(s))
+	for _, r := range s {
+		if r > 255 {
+			return nil, fmt.Errorf("%q is not a decimal such as \"12.34\"", s)
+		}
+		b = append(b, byte(r))
+	}
+	n := new(big.Int).SetBytes(b)
+	if len(b) > 0 && b[0]&0x80 != 0 {
+		n.Sub(n, new(big.Int).Lsh(big.NewInt(1), uint(len(b)*8)))
+	}
+	return n, nil
+}
+
+// unscaledBytes is a decimal's unscaled value as big-endian two's
+// complement bytes, sign-extended to size if it's above 0
+func unscaledBytes(r *big.Rat, scale, size int) ([]byte, error) {
+	scaled := new(big.Rat).Mul(r, new(big.Rat).SetInt(new(big.Int).Exp(big.NewInt(10), big.NewInt(int64(scale)), nil)))
+	if !scaled.IsInt() {
+		return nil, fmt.Errorf("has more than %d decimal places", scale)
+	}
+	n := scaled.Num()
+
+	var b []byte
+	if n.Sign() >= 0 {
+		b = n.Bytes()
+		if len(b) == 0 || b[0]&0x80 != 0 {
+			b = append([]byte{0}, b...)
+		}
+	} else {
+		// Two's complement in the fewest bytes that keep the sign
+		length := (n.BitLen() + 8) / 8
+		b = new(big.Int).Add(n, new(big.Int).Lsh(big.NewInt(1), uint(length*8))).Bytes()
+		for len(b) < length {
+			b = append([]byte{0}, b...)
+		}
+	}
+	if size > 0 {
+		if len(b) > size {
+			return nil, fmt.Errorf("doesn't fit in %d bytes", size)
+		}
+		pad := byte(0)
+		if n.Sign() < 0 {
+			pad = 0xff
+		}
+		for len(b) < size {
+			b = append([]byte{pad}, b...)
+		}
+	}
+	return b, nil
+}
+
+// byteString writes bytes one character per byte, as Avro's JSON does
+func byteString(b []byte) string {
+	runes := make([]rune, len(b))
+	for i, c := range b {
+		runes[i] = rune(c)
+	}
+	return string(runes)
+}
+
+// textualBytes is a byte string goavro's standard JSON codec reads: it
+// takes characters as their UTF-8 bytes, so the byte values 0x80 to 0xff
+// are escaped
+type textualBytes string
+
+func (b textualBytes) MarshalJSON() ([]byte, error) {
+	var buf strings.Builder
+	buf.WriteByte('"')
+	for _, r := range string(b) {
+		if r < 0x20 || (r > 0x7e && r <= 0xff) || r == '"' || r == '\\' {
+			fmt.Fprintf(&buf, `\u%04x`, r)
+		} else {
+			buf.WriteRune(r)
+		}
+	}
+	buf.WriteByte('"')
+	return []byte(buf.String()), nil
+}
+
+// readableLogical converts the readable form of a logical type value to
+// its underlying one: an int64 for timestamps, dates and times, or the
+// byte string of a decimal. It reports false for values not in a readable
+// form, which are left as they are.
+func readableLogical(logicalType string, scale, size int, v interface{}) (interface{}, bool, error) {
+	s, ok := v.(string)
+	if num, isNumber := v.(json.Number); isNumber && logicalType == "decimal" {
+		s, ok = num.String(), true
+	}
+	if !ok {
+		return nil, false, nil
+	}
+	switch logicalType {
+	case "timestamp-millis", "timestamp-micros", "local-timestamp-millis", "local-timestamp-micros":
+		t, err := parseTimestamp(s, strings.HasPrefix(logicalType, "local-"))
+		if err != nil {
+			return nil, false, err
+		}
+		if strings.HasSuffix(logicalType, "-micros") {
+			return t.UnixMicro(), true, nil
+		}
+		return t.UnixMilli(), true, nil
+	case "date":
+		t, err := time.Parse("2006-01-02", s)
+		if err != nil {
+			return nil, false, fmt.Errorf("%q is not a date (e.g. %s)", s, templateDate)
+		}
+		return t.Unix() / 86400, true, nil
+	case "time-millis", "time-micros":
+		d, err := parseTimeOfDay(s)
+		if err != nil {
+			return nil, false, err
+		}
+		if logicalType == "time-micros" {
+			return d.Microseconds(), true, nil
+		}
+		return d.Milliseconds(), true, nil
+	case "decimal":
+		r, ok := new(big.Rat).SetString(s)
+		if !ok {
+			return nil, false, nil // Avro's JSON bytes
+		}
+		b, err := unscaledBytes(r, scale, size)
+		if err != nil {
+			return nil, false, fmt.Errorf("%q %w", s, err)
+		}
+		return byteString(b), true, nil
+	}
+	return nil, false, nil
+}
+
+// goavroLogicalTypes are the logical types goavro encodes itself, and so
+// names union branches by ("long.timestamp-millis"); others are labelled by
+// their underlying type
+var goavroLogicalTypes = map[string]bool{
+	"long.timestamp-millis": true,
+	"long.timestamp-micros": true,
+	"int.time-millis":       true,
+	"long.time-micros":      true,
+	"int.date":              true,
+	"bytes.decimal":         true,
+}
+
+// goavroNatives converts a payload decoded from JSON to the values goavro
+// encodes: logical types in readable form become time.Time, time.Duration
+// and *big.Rat, and bare union values are wrapped in their branch, which
+// goavro requires. For the standard JSON codec (textual) they become their
+// underlying values instead, and unions stay unwrapped.
+type goavroNatives struct {
+	namedTypes map[string]map[string]interface{}
+	schema     interface{}
+	textual    bool
+}
+
+func newGoavroNatives(schemaJSON string) (*goavroNatives, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	n := &goavroNatives{namedTypes: make(map[string]map[string]interface{}), schema: schema}
+	collectNamedTypes(schema, n.namedTypes)
+	return n, nil
+}
+
+// convert converts a value for a schema. Values that don't fit are left for
+// goavro to report.
+func (n *goavroNatives) convert(schema interface{}, namespace string, v interface{}) (interface{}, error) {
+	switch s := schema.(type) {
+	case string:
+		if isPrimitive(s) {
+			return n.textualValue(s, v), nil
+		}
+		if named, full, ok := lookupNamed(n.namedTypes, s, namespace); ok {
+			return n.convert(named, namespaceOf(full), v)
+		}
+		return v, nil
+	case []interface{}:
+		return n.union(s, namespace, v)
+	case map[string]interface{}:
+		return n.complex(s, namespace, v)
+	}
+	return v, nil
+}
+
+func (n *goavroNatives) complex(s map[string]interface{}, namespace string, v interface{}) (interface{}, error) {
+	typeName, _ := s["type"].(string)
+	if _, named := s["name"].(string); named {
+		namespace = namespaceOf(fullName(s, namespace))
+	}
+	if logicalType, ok := s["logicalType"].(string); ok && v != nil && n.textual {
+		size, _ := s["size"].(float64)
+		converted, ok, err := readableLogical(logicalType, decimalScale(s), int(size), v)
+		if err != nil {
+			return nil, err
+		}
+		if str, isBytes := converted.(string); isBytes {
+			converted = textualBytes(str)
+		}
+		if ok {
+			return converted, nil
+		}
+	} else if ok && v != nil {
+		converted, err := goavroLogical(logicalType, s, v)
+		if err != nil {
+			return nil, err
+		}
+		if converted != nil {
+			return converted, nil
+		}
+	}
+
+	switch typeName {
+	case "record", "error":
+		obj, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		fields, _ := s["fields"].([]interface{})
+		for _, raw := range fields {
+			field, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			name, _ := field["name"].(string)
+			fv, present := obj[name]
+			if !present {
+				continue
+			}
+			converted, err := n.convert(field["type"], namespace, fv)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", name, err)
+			}
+			obj[name] = converted
+		}
+		return obj, nil
+	case "array":
+		items, ok := v.([]interface{})
+		if !ok {
+			return v, nil
+		}
+		for i, item := range items {
+			converted, err := n.convert(s["items"], namespace, item)
+			if err != nil {
+				return nil, fmt.Errorf("[%d]: %w", i, err)
+			}
+			items[i] = converted
+		}
+		return items, nil
+	case "map":
+		entries, ok := v.(map[string]interface{})
+		if !ok {
+			return v, nil
+		}
+		for k, item := range entries {
+			converted, err := n.convert(s["values"], namespace, item)
+			if err != nil {
+				return nil, fmt.Errorf("%s: %w", k, err)
+			}
+			entries[k] = converted
+		}
+		return entries, nil
+	}
+	if typeName == "" {
+		return n.convert(s["type"], namespace, v) // A type written as {"type": {...}}
+	}
+	return n.textualValue(typeName, v), nil
+}
+
+// textualValue marks the byte strings of bytes and fixed values for the
+// standard JSON codec
+func (n *goavroNatives) textualValue(typeName string, v interface{}) interface{} {
+	if str, ok := v.(string); ok && n.textual && (typeName == "bytes" || typeName == "fixed") {
+		return textualBytes(str)
+	}
+	return v
+}
+
+// goavroLogical converts a logical type value goavro takes natively, or
+// returns nil to leave it
+func goavroLogical(logicalType string, s map[string]interface{}, v interface{}) (interface{}, error) {
+	str, isString := v.(string)
+	switch logicalType {
+	case "timestamp-millis", "timestamp-micros":
+		if !isString {
+			return nil, nil
+		}
+		return parseTimestamp(str, false)
+	case "date":
+		if !isString {
+			return nil, nil
+		}
+		t, err := time.Parse("2006-01-02", str)
+		if err != nil {
+			return nil, fmt.Errorf("%q is not a date (e.g. %s)", str, templateDate)
+		}
+		return t, nil
+	case "time-millis", "time-micros":
+		if !isString {
+			return nil, nil
+		}
+		return parseTimeOfDay(str)
+	case "decimal":
+		r, err := parseDecimal(v, decimalScale(s))
+		if err != nil {
+			return nil, err
+		}
+		size, _ := s["size"].(float64)
+		b, err := unscaledBytes(r, decimalScale(s), int(size))
+		if err != nil {
+			return nil, fmt.Errorf("%v %w", v, err)
+		}
+		if s["type"] == "fixed" {
+			return b, nil // Encoded as a plain fixed, see stripFixedDecimals
+		}
+		return r, nil
+	}
+	// Logical types goavro doesn't know are encoded as their underlying type
+	size, _ := s["size"].(float64)
+	converted, ok, err := readableLogical(logicalType, decimalScale(s), int(size), v)
+	if err != nil || !ok {
+		return nil, err
+	}
+	return converted, nil
+}
+
+// union keeps a wrapped value in its branch, under goavro's label for it,
+// and wraps a bare one in the first branch it fits
+func (n *goavroNatives) union(branches []interface{}, namespace string, v interface{}) (interface{}, error) {
+	if v == nil {
+		return nil, nil
+	}
+	if obj, ok := v.(map[string]interface{}); ok && len(obj) == 1 && !n.textual {
+		for key, inner := range obj {
+			for _, branch := range branches {
+				label, alias := n.labels(branch, namespace)
+				if key == label || key == alias {
+					converted, err := n.convert(branch, namespace, inner)
+					if err != nil {
+						return nil, err
+					}
+					return map[string]interface{}{label: converted}, nil
+				}
+			}
+		}
+	}
+	for _, branch := range branches {
+		if branch == "null" || !n.fits(branch, namespace, v) {
+			continue
+		}
+		converted, err := n.convert(branch, namespace, v)
+		if err != nil {
+			continue
+		}
+		if n.textual {
+			return converted, nil
+		}
+		label, _ := n.labels(branch, namespace)
+		return map[string]interface{}{label: converted}, nil
+	}
+	return v, nil
+}
+
+// labels returns goavro's label for a union branch and another it may be
+// written with: the short name of a named type, or the logical type
+// appended to a type goavro labels by its underlying type ("string.uuid")
+func (n *goavroNatives) labels(branch interface{}, namespace string) (string, string) {
+	switch b := branch.(type) {
+	case string:
+		if isPrimitive(b) {
+			return b, b
+		}
+		if _, full, ok := lookupNamed(n.namedTypes, b, namespace); ok {
+			return full, shortName(full)
+		}
+		return b, b
+	case map[string]interface{}:
+		if _, named := b["name"].(string); named {
+			full := fullName(b, namespace)
+			return full, shortName(full)
+		}
+		typeName, _ := b["type"].(string)
+		if logicalType, ok := b["logicalType"].(string); ok {
+			label := typeName + "." + logicalType
+			if goavroLogicalTypes[label] {
+				return label, label
+			}
+			return typeName, label
+		}
+		return typeName, typeName
+	}
+	return "", ""
+}
+
+// fits reports whether a bare JSON value can belong to a union branch
+func (n *goavroNatives) fits(branch interface{}, namespace string, v interface{}) bool {
+	typeName := ""
+	var s map[string]interface{}
+	switch b := branch.(type) {
+	case string:
+		typeName = b
+		if named, _, ok := lookupNamed(n.namedTypes, b, namespace); ok {
+			s = named
+			typeName, _ = named["type"].(string)
+		}
+	case map[string]interface{}:
+		s = b
+		typeName, _ = b["type"].(string)
+	}
+
+	switch v.(type) {
+	case bool:
+		return typeName == "boolean"
+	case float64, json.Number:
+		switch typeName {
+		case "int", "long", "float", "double":
+			return true
+		}
+		return s != nil && s["logicalType"] == "decimal"
+	case string:
+		switch typeName {
+		case "string", "bytes", "fixed":
+			return true
+		case "enum":
+			for _, symbol := range stringList(s["symbols"]) {
+				if symbol == v {
+					return true
+				}
+			}
+			return false
+		}
+		// Readable logical values of numeric types
+		_, hasLogical := s["logicalType"]
+		return s != nil && hasLogical
+	case []interface{}:
+		return typeName == "array"
+	case map[string]interface{}:
+		return typeName == "record" || typeName == "error" || typeName == "map"
+	}
+	return false
+}
@@ -0,0 +1,492 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// How a target field was paired with a source field
+const (
+	MatchName    = "name"
+	MatchAlias   = "alias"
+	MatchSimilar = "similar name" // Differs only in case, "_" or "-"
+	MatchMoved   = "moved"        // Found elsewhere in the source
+)
+
+// FieldPair says where a target field's value comes from
+type FieldPair struct {
+	Target string // Field paths as FlattenFields writes them
+	Source string
+	Match  string
+	Note   string // Caveat for the conversion, e.g. "may be null"
+}
+
+// FieldMapping pairs the fields of a target schema with the fields of a
+// source schema, for migrating payloads from one to the other
+type FieldMapping struct {
+	Pairs     []FieldPair
+	Conflicts []FieldPair // Same name but types that can't convert, left unmapped
+	Missing   []string    // Required target fields nothing maps to
+	Defaulted []string    // Target fields nothing maps to, which get their default
+	Dropped   []string    // Source fields nothing maps from
+
+	fields []mapNode
+}
+
+// mapNode builds one field of the target record
+type mapNode struct {
+	name     string
+	source   []string  // Path in the source from the current context; nil if unmapped
+	fields   []mapNode // Set for records, built field by field
+	elements bool      // The source is an array and fields build each element
+	optional bool      // The value may be null, so a record is only built if present
+	missing  bool      // Required and unmapped
+}
+
+// MapFields pairs a target record's fields with a source record's: by name,
+// then by alias, then by a name differing only in case or separators, and
+// last (outside arrays) by a unique field of that name anywhere in the
+// source. Nested records and arrays of records are paired field by field.
+func MapFields(sourceSchema, targetSchema string) (*FieldMapping, error) {
+	var source, target interface{}
+	if err := json.Unmarshal([]byte(sourceSchema), &source); err != nil {
+		return nil, fmt.Errorf("parsing source schema: %w", err)
+	}
+	if err := json.Unmarshal([]byte(targetSchema), &target); err != nil {
+		return nil, fmt.Errorf("parsing target schema: %w", err)
+	}
+
+	mp := &mapper{
+		srcNamed: make(map[string]map[string]interface{}),
+		dstNamed: make(map[string]map[string]interface{}),
+		used:     make(map[string]bool),
+		mapping:  &FieldMapping{},
+	}
+	collectNamedTypes(source, mp.srcNamed)
+	collectNamedTypes(target, mp.dstNamed)
+
+	srcRecord, ok := deref(source, mp.srcNamed).(map[string]interface{})
+	if !ok || srcRecord["type"] != "record" {
+		return nil, fmt.Errorf("source schema is not a record")
+	}
+	dstRecord, ok := deref(target, mp.dstNamed).(map[string]interface{})
+	if !ok || dstRecord["type"] != "record" {
+		return nil, fmt.Errorf("target schema is not a record")
+	}
+	mp.srcRoot = srcRecord
+
+	mp.mapping.fields = mp.record(dstRecord, srcRecord, "", "", nil, false, map[string]bool{})
+
+	flat, err := FlattenFields(sourceSchema)
+	if err != nil {
+		return nil, err
+	}
+	for _, f := range flat {
+		if mp.used[f.Path] || mp.usedBelow(f.Path) {
+			continue
+		}
+		if n := len(mp.mapping.Dropped); n > 0 && isBelow(f.Path, mp.mapping.Dropped[n-1]) {
+			continue // Its parent is already listed
+		}
+		mp.mapping.Dropped = append(mp.mapping.Dropped, f.Path)
+	}
+	return mp.mapping, nil
+}
+
+type mapper struct {
+	srcNamed map[string]map[string]interface{}
+	dstNamed map[string]map[string]interface{}
+	srcRoot  map[string]interface{}
+	used     map[string]bool // Source field paths mapped from
+	mapping  *FieldMapping
+}
+
+// record pairs the fields of a target record with a source record.
+// srcPath is the display path of the source record, segments its path from
+// the current context. visiting stops at recursive target records.
+func (mp *mapper) record(dst, src map[string]interface{}, dstPath, srcPath string, segments []string, inArray bool, visiting map[string]bool) []mapNode {
+	name := recordName(dst)
+	if visiting[name] {
+		return nil
+	}
+	visiting[name] = true
+	defer delete(visiting, name)
+
+	var nodes []mapNode
+	for _, tf := range fieldsOf(dst) {
+		fieldName, _ := tf["name"].(string)
+		tPath := joinPath(dstPath, fieldName)
+		node := mapNode{name: fieldName}
+
+		sf, match := mp.candidate(tf, src)
+		var sPath string
+		var sSegments []string
+		var movedViaNull bool
+		if sf != nil {
+			sName, _ := sf["name"].(string)
+			sPath = joinPath(srcPath, sName)
+			sSegments = append(append([]string(nil), segments...), sName)
+		} else if !inArray {
+			if path, field, viaNull, ok := mp.moved(fieldName, tf["type"]); ok {
+				sf, match = field, MatchMoved
+				sPath, sSegments = path, strings.Split(path, ".")
+				movedViaNull = viaNull
+			}
+		}
+
+		if sf == nil {
+			mp.unmapped(&node, tf, tPath)
+			nodes = append(nodes, node)
+			continue
+		}
+
+		pair := FieldPair{Target: tPath, Source: sPath, Match: match}
+		sType, sNull := mp.nullable(sf["type"], mp.srcNamed)
+		dType, dNull := mp.nullable(tf["type"], mp.dstNamed)
+		if (sNull || movedViaNull) && !dNull {
+			pair.Note = "may be null"
+		}
+
+		sRec, sIsRec := sType.(map[string]interface{})
+		dRec, dIsRec := dType.(map[string]interface{})
+		switch {
+		case sIsRec && dIsRec && sRec["type"] == "record" && dRec["type"] == "record":
+			mp.use(sPath, pair)
+			node.source = sSegments
+			node.optional = sNull
+			node.fields = mp.record(dRec, sRec, tPath, sPath, sSegments, inArray, visiting)
+
+		case sIsRec && dIsRec && sRec["type"] == "array" && dRec["type"] == "array" && mp.isRecord(sRec["items"], mp.srcNamed) && mp.isRecord(dRec["items"], mp.dstNamed):
+			mp.use(sPath, pair)
+			node.source = sSegments
+			node.optional = sNull
+			node.elements = true
+			sItems := deref(sRec["items"], mp.srcNamed).(map[string]interface{})
+			dItems := deref(dRec["items"], mp.dstNamed).(map[string]interface{})
+			node.fields = mp.record(dItems, sItems, tPath+"[]", sPath+"[]", nil, true, visiting)
+
+		default:
+			note, ok := mp.compatible(sType, dType)
+			if !ok {
+				pair.Note = note
+				mp.mapping.Conflicts = append(mp.mapping.Conflicts, pair)
+				mp.unmapped(&node, tf, tPath)
+				break
+			}
+			if note != "" {
+				pair.Note = strings.TrimPrefix(pair.Note+"; "+note, "; ")
+			}
+			node.source = sSegments
+			mp.use(sPath, pair)
+		}
+		nodes = append(nodes, node)
+	}
+	return nodes
+}
+
+func (mp *mapper) use(path string, pair FieldPair) {
+	mp.used[path] = true
+	mp.mapping.Pairs = append(mp.mapping.Pairs, pair)
+}
+
+func (mp *mapper) unmapped(node *mapNode, field map[string]interface{}, path string) {
+	if _, ok := field["default"]; ok {
+		mp.mapping.Defaulted = append(mp.mapping.Defaulted, path)
+		return
+	}
+	node.missing = true
+	mp.mapping.Missing = append(mp.mapping.Missing, path)
+}
+
+// usedBelow reports whether a field nested under path is mapped from
+func (mp *mapper) usedBelow(path string) bool {
+	for used := range mp.used {
+		if isBelow(used, path) {
+			return true
+		}
+	}
+	return false
+}
+
+func isBelow(path, parent string) bool {
+	return strings.HasPrefix(path, parent+".") || strings.HasPrefix(path, parent+"[]") || strings.HasPrefix(path, parent+"{}")
+}
+
+// candidate finds the source field for a target field in the source record
+// at the same level
+func (mp *mapper) candidate(target, src map[string]interface{}) (map[string]interface{}, string) {
+	name, _ := target["name"].(string)
+	fields := fieldsOf(src)
+	for _, f := range fields {
+		if f["name"] == name {
+			return f, MatchName
+		}
+	}
+	targetAliases := stringList(target["aliases"])
+	for _, f := range fields {
+		sName, _ := f["name"].(string)
+		if containsString(targetAliases, sName) || containsString(stringList(f["aliases"]), name) {
+			return f, MatchAlias
+		}
+	}
+	for _, f := range fields {
+		sName, _ := f["name"].(string)
+		if normalizeName(sName) == normalizeName(name) {
+			return f, MatchSimilar
+		}
+	}
+	return nil, ""
+}
+
+// moved looks for a single unused, convertible field with a similar name
+// anywhere in the source outside arrays, for fields that were regrouped.
+// It also reports whether the field sits in a record that may be null.
+func (mp *mapper) moved(name string, targetType interface{}) (string, map[string]interface{}, bool, bool) {
+	var found []string
+	var field map[string]interface{}
+	var viaNull bool
+	var walk func(record map[string]interface{}, prefix string, nullable bool, visiting map[string]bool)
+	walk = func(record map[string]interface{}, prefix string, nullable bool, visiting map[string]bool) {
+		rn := recordName(record)
+		if visiting[rn] {
+			return
+		}
+		visiting[rn] = true
+		defer delete(visiting, rn)
+
+		for _, f := range fieldsOf(record) {
+			fName, _ := f["name"].(string)
+			path := joinPath(prefix, fName)
+			inner, isNull := mp.nullable(f["type"], mp.srcNamed)
+			if rec, ok := inner.(map[string]interface{}); ok && rec["type"] == "record" {
+				walk(rec, path, nullable || isNull, visiting)
+				continue
+			}
+			if normalizeName(fName) != normalizeName(name) || mp.used[path] {
+				continue
+			}
+			dType, _ := mp.nullable(targetType, mp.dstNamed)
+			if _, ok := mp.compatible(inner, dType); ok {
+				found = append(found, path)
+				field, viaNull = f, nullable
+			}
+		}
+	}
+	walk(mp.srcRoot, "", false, map[string]bool{})
+	if len(found) != 1 {
+		return "", nil, false, false
+	}
+	return found[0], field, viaNull, true
+}
+
+// nullable dereferences a type and unwraps a ["null", T] union
+func (mp *mapper) nullable(schema interface{}, named map[string]map[string]interface{}) (interface{}, bool) {
+	schema = deref(schema, named)
+	branches, ok := schema.([]interface{})
+	if !ok || len(branches) != 2 {
+		return schema, false
+	}
+	for i, b := range branches {
+		if typeOf(deref(b, named)) == "null" {
+			return deref(branches[1-i], named), true
+		}
+	}
+	return schema, false
+}
+
+func (mp *mapper) isRecord(schema interface{}, named map[string]map[string]interface{}) bool {
+	rec, ok := deref(schema, named).(map[string]interface{})
+	return ok && rec["type"] == "record"
+}
+
+// promotable lists the types a plain JSON value of each type can be
+// written as unchanged
+var promotable = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+	"enum":   {"string"},
+}
+
+// compatible reports whether a source value can be copied as is into a
+// target field, with a note on what to check, or why not
+func (mp *mapper) compatible(src, dst interface{}) (string, bool) {
+	sKind, dKind := typeOf(src), typeOf(dst)
+	if sKind != dKind {
+		if containsString(promotable[sKind], dKind) {
+			return "", true
+		}
+		if sKind == "string" && dKind == "enum" {
+			return "must be a symbol of " + shortName(recordName(dst.(map[string]interface{}))), true
+		}
+		return fmt.Sprintf("type %s can't become %s", describeType(src), describeType(dst)), false
+	}
+
+	switch sKind {
+	case "enum":
+		var lost []string
+		dstSymbols := stringList(dst.(map[string]interface{})["symbols"])
+		for _, s := range stringList(src.(map[string]interface{})["symbols"]) {
+			if !containsString(dstSymbols, s) {
+				lost = append(lost, s)
+			}
+		}
+		if len(lost) > 0 {
+			return "target enum lacks " + strings.Join(lost, ", "), true
+		}
+	case "fixed":
+		if fmt.Sprint(src.(map[string]interface{})["size"]) != fmt.Sprint(dst.(map[string]interface{})["size"]) {
+			return "fixed sizes differ", false
+		}
+	case "array":
+		return mp.compatible(deref(src.(map[string]interface{})["items"], mp.srcNamed), deref(dst.(map[string]interface{})["items"], mp.dstNamed))
+	case "map":
+		return mp.compatible(deref(src.(map[string]interface{})["values"], mp.srcNamed), deref(dst.(map[string]interface{})["values"], mp.dstNamed))
+	case "union":
+		if describeType(src) != describeType(dst) {
+			return fmt.Sprintf("unions %s and %s differ", describeType(src), describeType(dst)), false
+		}
+	}
+	return "", true
+}
+
+// normalizeName folds case and drops separators, so customer_id,
+// customerId and Customer-ID compare equal
+func normalizeName(name string) string {
+	return strings.ToLower(strings.NewReplacer("_", "", "-", "").Replace(name))
+}
+
+// JQ returns a jq program converting a source payload, as plain JSON with
+// unions unwrapped, into the target shape. Required target fields nothing
+// maps to are set to null with a TODO comment; fields with defaults that
+// nothing maps to are left out.
+func (m *FieldMapping) JQ() string {
+	var b strings.Builder
+	writeJQObject(&b, m.fields, "")
+	b.WriteString("\n")
+	return b.String()
+}
+
+func writeJQObject(b *strings.Builder, nodes []mapNode, indent string) {
+	var entries []mapNode
+	for _, n := range nodes {
+		if n.source != nil || n.missing {
+			entries = append(entries, n)
+		}
+	}
+	if len(entries) == 0 {
+		b.WriteString("{}")
+		return
+	}
+
+	b.WriteString("{\n")
+	inner := indent + "  "
+	for i, n := range entries {
+		b.WriteString(inner + n.name + ": ")
+		switch {
+		case n.missing:
+			b.WriteString("null")
+		case n.elements:
+			path := jqPath(n.source)
+			if n.optional {
+				b.WriteString("(if " + path + " == null then null else [" + path + "[] | ")
+				writeJQObject(b, n.fields, inner)
+				b.WriteString("] end)")
+			} else {
+				b.WriteString("[" + path + "[] | ")
+				writeJQObject(b, n.fields, inner)
+				b.WriteString("]")
+			}
+		case n.fields != nil:
+			if n.optional {
+				b.WriteString("(if " + jqPath(n.source) + " == null then null else ")
+				writeJQObject(b, n.fields, inner)
+				b.WriteString(" end)")
+			} else {
+				writeJQObject(b, n.fields, inner)
+			}
+		default:
+			b.WriteString(jqPath(n.source))
+		}
+		if i < len(entries)-1 {
+			b.WriteString(",")
+		}
+		if n.missing {
+			b.WriteString("  # TODO: required, no source field")
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString(indent + "}")
+}
+
+func jqPath(segments []string) string {
+	if len(segments) == 0 {
+		return "."
+	}
+	return "." + strings.Join(segments, ".")
+}
+
+// Convert applies the mapping to a source payload in plain JSON, returning
+// the target payload in plain JSON. It fails if a required target field
+// has no source.
+func (m *FieldMapping) Convert(payload string) (string, error) {
+	if len(m.Missing) > 0 {
+		return "", fmt.Errorf("no source for required target fields: %s", strings.Join(m.Missing, ", "))
+	}
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	out, err := json.Marshal(convertObject(m.fields, doc))
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+func convertObject(nodes []mapNode, ctx interface{}) map[string]interface{} {
+	out := make(map[string]interface{}, len(nodes))
+	for _, n := range nodes {
+		if n.source == nil {
+			continue
+		}
+		v, ok := lookup(ctx, n.source)
+		if !ok {
+			continue // Absent in this payload: let the target default apply
+		}
+		switch {
+		case v == nil:
+			out[n.name] = nil
+		case n.elements:
+			items, _ := v.([]interface{})
+			converted := make([]interface{}, len(items))
+			for i, item := range items {
+				converted[i] = convertObject(n.fields, item)
+			}
+			out[n.name] = converted
+		case n.fields != nil:
+			out[n.name] = convertObject(n.fields, ctx)
+		default:
+			out[n.name] = v
+		}
+	}
+	return out
+}
+
+func lookup(doc interface{}, path []string) (interface{}, bool) {
+	for _, segment := range path {
+		obj, ok := doc.(map[string]interface{})
+		if !ok {
+			return nil, false
+		}
+		if doc, ok = obj[segment]; !ok {
+			return nil, false
+		}
+	}
+	return doc, true
+}
@@ -0,0 +1,141 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// Project reads a payload written with writerSchema as a consumer using
+// readerSchema would see it, following the Avro schema resolution rules:
+// fields the reader lacks are dropped, fields the writer lacks take the
+// reader's default, numbers are promoted and enum symbols unknown to the
+// reader become its default. It returns the reader's view as plain JSON
+// with unions unwrapped, or, if the reader can't read the payload, the
+// problems CheckResolution would report.
+func Project(writerSchema, readerSchema string, payload []byte) (string, []string, error) {
+	codec, err := NewCodec(writerSchema)
+	if err != nil {
+		return "", nil, fmt.Errorf("parsing writer schema: %w", err)
+	}
+	text, err := codec.Decode(payload)
+	if err != nil {
+		return "", nil, fmt.Errorf("decoding payload: %w", err)
+	}
+	dec := json.NewDecoder(strings.NewReader(text))
+	dec.UseNumber() // Keep long values exact
+	var datum interface{}
+	if err := dec.Decode(&datum); err != nil {
+		return "", nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	var writer, reader interface{}
+	if err := json.Unmarshal([]byte(writerSchema), &writer); err != nil {
+		return "", nil, fmt.Errorf("parsing writer schema: %w", err)
+	}
+	if err := json.Unmarshal([]byte(readerSchema), &reader); err != nil {
+		return "", nil, fmt.Errorf("parsing reader schema: %w", err)
+	}
+
+	r := &resolver{
+		writerTypes: make(map[string]map[string]interface{}),
+		readerTypes: make(map[string]map[string]interface{}),
+	}
+	collectNamedTypes(writer, r.writerTypes)
+	collectNamedTypes(reader, r.readerTypes)
+
+	projected := r.project("$", writer, reader, datum)
+	if len(r.problems) > 0 {
+		return "", r.problems, nil
+	}
+	out, err := json.Marshal(projected)
+	if err != nil {
+		return "", nil, fmt.Errorf("encoding projection: %w", err)
+	}
+	return string(out), nil, nil
+}
+
+// project is resolve, returning the datum as the reader sees it
+func (r *resolver) project(path string, writer, reader, datum interface{}) interface{} {
+	writer = deref(writer, r.writerTypes)
+	reader = deref(reader, r.readerTypes)
+
+	if branches, ok := writer.([]interface{}); ok {
+		name, value := unionBranch(datum)
+		branch := findBranch(branches, name, r.writerTypes)
+		if branch == nil {
+			r.fail(path, "payload union branch %q not in writer schema", name)
+			return nil
+		}
+		return r.project(path, branch, reader, value)
+	}
+
+	if branches, ok := reader.([]interface{}); ok {
+		for _, branch := range branches {
+			if r.matches(writer, deref(branch, r.readerTypes)) {
+				return r.project(path, writer, branch, datum)
+			}
+		}
+		r.fail(path, "reader union %s has no branch for %s", describeType(reader), describeType(writer))
+		return nil
+	}
+
+	if !r.matches(writer, reader) {
+		r.fail(path, "reader expects %s but payload has %s", describeType(reader), describeType(writer))
+		return nil
+	}
+
+	switch typeOf(reader) {
+	case "record":
+		return r.projectRecord(path, writer.(map[string]interface{}), reader.(map[string]interface{}), datum)
+	case "enum":
+		symbol, _ := datum.(string)
+		readerEnum := reader.(map[string]interface{})
+		if !containsString(stringList(readerEnum["symbols"]), symbol) {
+			if def, hasDefault := readerEnum["default"]; hasDefault {
+				return def
+			}
+			r.fail(path, "enum symbol %q is unknown to the reader and its enum has no default", symbol)
+		}
+	case "array":
+		items, _ := datum.([]interface{})
+		projected := make([]interface{}, len(items))
+		for i, item := range items {
+			projected[i] = r.project(fmt.Sprintf("%s[%d]", path, i), writer.(map[string]interface{})["items"], reader.(map[string]interface{})["items"], item)
+		}
+		return projected
+	case "map":
+		values, _ := datum.(map[string]interface{})
+		projected := make(map[string]interface{}, len(values))
+		for k, v := range values {
+			projected[k] = r.project(fmt.Sprintf("%s[%q]", path, k), writer.(map[string]interface{})["values"], reader.(map[string]interface{})["values"], v)
+		}
+		return projected
+	}
+	return datum
+}
+
+func (r *resolver) projectRecord(path string, writer, reader map[string]interface{}, datum interface{}) interface{} {
+	values, _ := datum.(map[string]interface{})
+	writerFields := make(map[string]map[string]interface{})
+	for _, raw := range fieldsOf(writer) {
+		name, _ := raw["name"].(string)
+		writerFields[name] = raw
+	}
+
+	projected := make(map[string]interface{})
+	for _, field := range fieldsOf(reader) {
+		name, _ := field["name"].(string)
+		writerField, source, ok := writerFieldFor(field, writerFields)
+		if !ok {
+			def, hasDefault := field["default"]
+			if !hasDefault {
+				r.fail(path+"."+name, "required by the reader but missing from the payload schema (no default)")
+			}
+			projected[name] = def
+			continue
+		}
+		projected[name] = r.project(path+"."+name, writerField["type"], field["type"], values[source])
+	}
+	return projected
+}
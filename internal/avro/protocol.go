@@ -0,0 +1,364 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// Protocol is an Avro protocol (.avpr): the named types a service uses and
+// the messages it exchanges
+type Protocol struct {
+	Name      string
+	Namespace string
+	Doc       string
+	Messages  []ProtocolMessage // In the order the protocol declares them
+
+	named map[string]map[string]interface{} // Named types by full name
+}
+
+// ProtocolMessage is one message of a protocol: a request made of
+// parameters, and a response or errors
+type ProtocolMessage struct {
+	Name     string
+	Doc      string
+	Request  []interface{} // Parameters, written like record fields
+	Response interface{}
+	Errors   []interface{}
+	OneWay   bool
+}
+
+// IsProtocol reports whether a document is a protocol rather than a schema
+func IsProtocol(doc string) bool {
+	var probe struct {
+		Protocol *string `json:"protocol"`
+	}
+	return json.Unmarshal([]byte(doc), &probe) == nil && probe.Protocol != nil
+}
+
+// ParseProtocol parses a protocol document and checks that each message's
+// request is a valid record
+func ParseProtocol(doc string) (*Protocol, error) {
+	var raw struct {
+		Protocol  string                     `json:"protocol"`
+		Namespace string                     `json:"namespace"`
+		Doc       string                     `json:"doc"`
+		Types     []interface{}              `json:"types"`
+		Messages  map[string]json.RawMessage `json:"messages"`
+	}
+	if err := json.Unmarshal([]byte(doc), &raw); err != nil {
+		return nil, fmt.Errorf("parsing protocol: %w", err)
+	}
+	if raw.Protocol == "" {
+		return nil, fmt.Errorf("parsing protocol: no \"protocol\" name")
+	}
+
+	p := &Protocol{
+		Name:      raw.Protocol,
+		Namespace: raw.Namespace,
+		Doc:       raw.Doc,
+		named:     make(map[string]map[string]interface{}),
+	}
+	for _, t := range raw.Types {
+		p.register(t, p.Namespace)
+	}
+
+	names, err := messageOrder([]byte(doc))
+	if err != nil {
+		return nil, fmt.Errorf("parsing protocol: %w", err)
+	}
+	for _, name := range names {
+		var m struct {
+			Doc      string        `json:"doc"`
+			Request  []interface{} `json:"request"`
+			Response interface{}   `json:"response"`
+			Errors   []interface{} `json:"errors"`
+			OneWay   bool          `json:"one-way"`
+		}
+		if err := json.Unmarshal(raw.Messages[name], &m); err != nil {
+			return nil, fmt.Errorf("message %s: %w", name, err)
+		}
+		p.Messages = append(p.Messages, ProtocolMessage{
+			Name: name, Doc: m.Doc, Request: m.Request, Response: m.Response, Errors: m.Errors, OneWay: m.OneWay,
+		})
+	}
+
+	for _, m := range p.Messages {
+		schema, err := p.RequestSchema(m.Name)
+		if err != nil {
+			return nil, err
+		}
+		if _, err := goavro.NewCodec(schema); err != nil {
+			return nil, fmt.Errorf("message %s: %w", m.Name, err)
+		}
+	}
+	return p, nil
+}
+
+// Message returns the message with the given name
+func (p *Protocol) Message(name string) (ProtocolMessage, bool) {
+	for _, m := range p.Messages {
+		if m.Name == name {
+			return m, true
+		}
+	}
+	return ProtocolMessage{}, false
+}
+
+// RequestSchema returns a standalone record schema for a message's
+// request, named <message>Request, with the protocol types it uses inlined.
+// Templates and payloads for the request are built against it.
+func (p *Protocol) RequestSchema(message string) (string, error) {
+	m, ok := p.Message(message)
+	if !ok {
+		return "", fmt.Errorf("protocol %s has no message %s", p.Name, message)
+	}
+
+	defined := make(map[string]bool)
+	fields := make([]interface{}, 0, len(m.Request))
+	for _, f := range m.Request {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			return "", fmt.Errorf("message %s: request parameters must be objects", message)
+		}
+		inlined := make(map[string]interface{}, len(field))
+		for k, v := range field {
+			inlined[k] = v
+		}
+		inlined["type"] = p.inline(field["type"], p.Namespace, defined)
+		fields = append(fields, inlined)
+	}
+
+	record := map[string]interface{}{
+		"type":   "record",
+		"name":   qualify(message+"Request", p.Namespace),
+		"fields": fields,
+	}
+	if m.Doc != "" {
+		record["doc"] = m.Doc
+	}
+	b, err := json.Marshal(record)
+	if err != nil {
+		return "", err
+	}
+	return string(b), nil
+}
+
+// Signature renders a message like a method: "send(order: Order) -> Ack
+// throws Failure", with type names shortened
+func (p *Protocol) Signature(m ProtocolMessage) string {
+	params := make([]string, 0, len(m.Request))
+	for _, f := range m.Request {
+		field, _ := f.(map[string]interface{})
+		name, _ := field["name"].(string)
+		params = append(params, name+": "+p.typeLabel(field["type"]))
+	}
+	s := m.Name + "(" + strings.Join(params, ", ") + ")"
+	if m.OneWay {
+		return s + " (one-way)"
+	}
+	s += " -> " + p.typeLabel(m.Response)
+
+	var errs []string
+	for _, e := range m.Errors {
+		if label := p.typeLabel(e); label != "string" {
+			errs = append(errs, label)
+		}
+	}
+	if len(errs) > 0 {
+		s += " throws " + strings.Join(errs, ", ")
+	}
+	return s
+}
+
+// typeLabel names a type briefly: "Order", "array<string>", "null | Order"
+func (p *Protocol) typeLabel(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		return s[strings.LastIndex(s, ".")+1:]
+	case []interface{}:
+		labels := make([]string, len(s))
+		for i, b := range s {
+			labels[i] = p.typeLabel(b)
+		}
+		return strings.Join(labels, " | ")
+	case map[string]interface{}:
+		switch t, _ := s["type"].(string); t {
+		case "array":
+			return "array<" + p.typeLabel(s["items"]) + ">"
+		case "map":
+			return "map<" + p.typeLabel(s["values"]) + ">"
+		case "record", "error", "enum", "fixed":
+			name, _ := s["name"].(string)
+			return p.typeLabel(name)
+		default:
+			return p.typeLabel(s["type"])
+		}
+	}
+	return fmt.Sprint(schema)
+}
+
+// register records a protocol type, and the named types nested in it, by
+// full name
+func (p *Protocol) register(schema interface{}, namespace string) {
+	switch s := schema.(type) {
+	case []interface{}:
+		for _, b := range s {
+			p.register(b, namespace)
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error", "enum", "fixed":
+			full := fullName(s, namespace)
+			p.named[full] = s
+			namespace = namespaceOf(full)
+		}
+		if fields, ok := s["fields"].([]interface{}); ok {
+			for _, f := range fields {
+				if field, ok := f.(map[string]interface{}); ok {
+					p.register(field["type"], namespace)
+				}
+			}
+		}
+		p.register(s["items"], namespace)
+		p.register(s["values"], namespace)
+	}
+}
+
+// inline rewrites a type so it stands alone: each protocol type it refers
+// to is defined at its first use, and every name is written in full so it
+// means the same wherever it ends up
+func (p *Protocol) inline(schema interface{}, namespace string, defined map[string]bool) interface{} {
+	switch s := schema.(type) {
+	case string:
+		if isPrimitive(s) {
+			return s
+		}
+		full := qualify(s, namespace)
+		if _, ok := p.named[full]; !ok {
+			if _, ok := p.named[s]; ok {
+				full = s // A type in the null namespace
+			}
+		}
+		if def, ok := p.named[full]; ok && !defined[full] {
+			return p.inline(def, namespaceOf(full), defined)
+		}
+		return full
+
+	case []interface{}:
+		branches := make([]interface{}, len(s))
+		for i, b := range s {
+			branches[i] = p.inline(b, namespace, defined)
+		}
+		return branches
+
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(s))
+		for k, v := range s {
+			out[k] = v
+		}
+		switch t, _ := s["type"].(string); t {
+		case "record", "error", "enum", "fixed":
+			full := fullName(s, namespace)
+			defined[full] = true
+			out["name"] = full
+			delete(out, "namespace")
+			if t == "error" {
+				out["type"] = "record"
+			}
+			if fields, ok := s["fields"].([]interface{}); ok {
+				inlined := make([]interface{}, len(fields))
+				for i, f := range fields {
+					field, _ := f.(map[string]interface{})
+					copied := make(map[string]interface{}, len(field))
+					for k, v := range field {
+						copied[k] = v
+					}
+					copied["type"] = p.inline(field["type"], namespaceOf(full), defined)
+					inlined[i] = copied
+				}
+				out["fields"] = inlined
+			}
+		case "array":
+			out["items"] = p.inline(s["items"], namespace, defined)
+		case "map":
+			out["values"] = p.inline(s["values"], namespace, defined)
+		}
+		return out
+	}
+	return schema
+}
+
+// messageOrder returns the names of a protocol's messages in document order
+func messageOrder(doc []byte) ([]string, error) {
+	dec := json.NewDecoder(bytes.NewReader(doc))
+	if _, err := dec.Token(); err != nil { // {
+		return nil, err
+	}
+	for dec.More() {
+		key, err := dec.Token()
+		if err != nil {
+			return nil, err
+		}
+		if key != "messages" {
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+			continue
+		}
+
+		if t, err := dec.Token(); err != nil || t != json.Delim('{') {
+			return nil, fmt.Errorf("\"messages\" must be an object")
+		}
+		var names []string
+		for dec.More() {
+			name, err := dec.Token()
+			if err != nil {
+				return nil, err
+			}
+			names = append(names, name.(string))
+			var skip json.RawMessage
+			if err := dec.Decode(&skip); err != nil {
+				return nil, err
+			}
+		}
+		return names, nil
+	}
+	return nil, nil
+}
+
+// fullName returns a named type's full name given the enclosing namespace
+func fullName(s map[string]interface{}, namespace string) string {
+	name, _ := s["name"].(string)
+	if ns, ok := s["namespace"].(string); ok {
+		namespace = ns
+	}
+	return qualify(name, namespace)
+}
+
+// qualify returns the full name a reference resolves to in a namespace
+func qualify(name, namespace string) string {
+	if strings.Contains(name, ".") || namespace == "" {
+		return name
+	}
+	return namespace + "." + name
+}
+
+func namespaceOf(full string) string {
+	if i := strings.LastIndex(full, "."); i >= 0 {
+		return full[:i]
+	}
+	return ""
+}
+
+func isPrimitive(name string) bool {
+	switch name {
+	case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+		return true
+	}
+	return false
+}
@@ -0,0 +1,494 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"math"
+	"math/big"
+	"math/rand"
+	"strings"
+	"time"
+	"unicode"
+)
+
+// Random timestamps and dates fall within two years from randomEpoch, so a
+// seed always gives the same payloads
+var randomEpoch = time.Date(2024, 1, 1, 0, 0, 0, 0, time.UTC)
+
+const randomSpan = 2 * 365 * 24 * time.Hour
+
+// RandomGenerator produces random payloads for a schema, shaped by optional
+// per-field Constraints. The schema is parsed once, so generating many
+// payloads (for load tests) is cheap. Values use the same JSON shape as
+// GenerateTemplate.
+type RandomGenerator struct {
+	schema      interface{}
+	namedTypes  map[string]map[string]interface{}
+	constraints *Constraints
+	rng         *rand.Rand
+	depth       int
+	active      map[string]int // Records currently being generated, by full name
+	namespace   string         // Enclosing namespace, for resolving references
+}
+
+// NewRandomGenerator parses schemaJSON for random generation. constraints
+// may be nil.
+func NewRandomGenerator(schemaJSON string, constraints *Constraints, rng *rand.Rand) (*RandomGenerator, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	g := &RandomGenerator{
+		schema:      schema,
+		namedTypes:  make(map[string]map[string]interface{}),
+		constraints: constraints,
+		rng:         rng,
+		active:      make(map[string]int),
+	}
+	collectNamedTypes(schema, g.namedTypes)
+
+	return g, nil
+}
+
+// GenerateRandom returns a payload of plausible random data for a schema:
+// values fit their logical types and field names, enums pick a symbol and
+// numbers stay small. The same seed gives the same payload.
+func GenerateRandom(schemaJSON string, seed int64) (string, error) {
+	g, err := NewRandomGenerator(schemaJSON, nil, rand.New(rand.NewSource(seed)))
+	if err != nil {
+		return "", err
+	}
+	return g.Generate()
+}
+
+// Generate returns one random payload as indented JSON
+func (g *RandomGenerator) Generate() (string, error) {
+	value, err := g.value(g.schema, "")
+	if err != nil {
+		return "", err
+	}
+
+	pretty, err := json.MarshalIndent(value, "", "  ")
+	if err != nil {
+		return "", fmt.Errorf("formatting payload: %w", err)
+	}
+	return string(pretty), nil
+}
+
+// value generates a value for schema at a field path ("" for the root)
+func (g *RandomGenerator) value(schema interface{}, path string) (interface{}, error) {
+	if fc, ok := g.constraints.field(path); ok {
+		if v, ok := g.constraints.pick(fc, g.baseType(schema), g.rng); ok {
+			return v, nil
+		}
+	}
+
+	switch s := schema.(type) {
+	case string:
+		// Named type reference - resolve it from the enclosing namespace
+		if named, full, ok := lookupNamed(g.namedTypes, s, g.namespace); ok {
+			saved := g.namespace
+			g.namespace = namespaceOf(full)
+			defer func() { g.namespace = saved }()
+			return g.complex(named, path)
+		}
+		return g.primitive(s, path), nil
+	case []interface{}:
+		return g.union(s, path)
+	case map[string]interface{}:
+		if example, ok := exampleValue(s); ok {
+			return example, nil
+		}
+		return g.complex(s, path)
+	default:
+		return nil, fmt.Errorf("unexpected schema type: %T", schema)
+	}
+}
+
+// baseType names the type a constrained value must fit, looking through
+// named references and optional unions
+func (g *RandomGenerator) baseType(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		if named, _, ok := lookupNamed(g.namedTypes, s, g.namespace); ok {
+			return g.baseType(named)
+		}
+		return s
+	case []interface{}:
+		for _, t := range s {
+			if t != "null" {
+				return g.baseType(t)
+			}
+		}
+	case map[string]interface{}:
+		if t, ok := s["type"].(string); ok {
+			return t
+		}
+	}
+	return ""
+}
+
+func (g *RandomGenerator) primitive(typeName, path string) interface{} {
+	switch typeName {
+	case "null":
+		return nil
+	case "boolean":
+		return g.rng.Intn(2) == 1
+	case "int", "long":
+		if n, ok := g.namedNumber(path); ok {
+			return n
+		}
+		if typeName == "int" {
+			return g.rng.Intn(1000)
+		}
+		return g.rng.Int63n(1000000)
+	case "float", "double":
+		return roundTo(g.rng.Float64()*1000, 2)
+	case "string":
+		return g.namedString(path)
+	case "bytes":
+		return g.word(8)
+	default:
+		return ""
+	}
+}
+
+// nameWords splits the last field name in a path into lowercase words, at
+// underscores and camelCase humps ("customerEmail" is "customer", "email")
+func nameWords(path string) []string {
+	if i := strings.LastIndex(path, "."); i >= 0 {
+		path = path[i+1:]
+	}
+	path = strings.TrimRight(path, "[]{}")
+
+	var words []string
+	var word []rune
+	flush := func() {
+		if len(word) > 0 {
+			words = append(words, strings.ToLower(string(word)))
+			word = word[:0]
+		}
+	}
+	for _, r := range path {
+		switch {
+		case r == '_' || r == '-':
+			flush()
+		case unicode.IsUpper(r) && len(word) > 0 && !unicode.IsUpper(word[len(word)-1]):
+			flush()
+			word = append(word, r)
+		default:
+			word = append(word, r)
+		}
+	}
+	flush()
+	return words
+}
+
+func hasWord(words []string, want ...string) bool {
+	for _, w := range words {
+		for _, v := range want {
+			if w == v {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// Values for string fields, by what their names suggest
+var (
+	randomFirstNames = []string{"Alice", "Ben", "Chloe", "Dev", "Elena", "Femi", "Grace", "Hiro", "Isla", "Jonas"}
+	randomLastNames  = []string{"Adams", "Brown", "Chen", "Diaz", "Evans", "Fischer", "Garcia", "Hughes", "Ivanova", "Khan"}
+	randomCities     = []string{"London", "Manchester", "Berlin", "Paris", "Amsterdam", "Madrid", "New York", "Toronto"}
+	randomCountries  = []string{"GB", "DE", "FR", "NL", "ES", "US", "CA"}
+	randomCurrencies = []string{"GBP", "EUR", "USD"}
+)
+
+func (g *RandomGenerator) pick(values []string) string {
+	return values[g.rng.Intn(len(values))]
+}
+
+// namedString makes up a string that suits the field's name, such as an
+// email address for "email", or a random word
+func (g *RandomGenerator) namedString(path string) string {
+	words := nameWords(path)
+	last := ""
+	if len(words) > 0 {
+		last = words[len(words)-1]
+	}
+	switch {
+	case hasWord(words, "email"):
+		return strings.ToLower(g.pick(randomFirstNames)) + "." + strings.ToLower(g.pick(randomLastNames)) + "@example.com"
+	case hasWord(words, "uuid", "guid") || last == "id":
+		return g.uuid()
+	case hasWord(words, "first", "given", "firstname") && hasWord(words, "name", "firstname"):
+		return g.pick(randomFirstNames)
+	case hasWord(words, "last", "family", "surname", "lastname"):
+		return g.pick(randomLastNames)
+	case last == "name" || last == "fullname":
+		return g.pick(randomFirstNames) + " " + g.pick(randomLastNames)
+	case hasWord(words, "city", "town"):
+		return g.pick(randomCities)
+	case hasWord(words, "country"):
+		return g.pick(randomCountries)
+	case hasWord(words, "currency"):
+		return g.pick(randomCurrencies)
+	case hasWord(words, "url", "uri", "link", "website"):
+		return "https://example.com/" + g.word(6)
+	case hasWord(words, "phone", "mobile"):
+		return fmt.Sprintf("+44 7700 900%03d", g.rng.Intn(1000))
+	}
+	return g.word(8)
+}
+
+// namedNumber picks a number in a range that suits the field's name, such
+// as a small quantity or an adult's age
+func (g *RandomGenerator) namedNumber(path string) (int, bool) {
+	words := nameWords(path)
+	switch {
+	case hasWord(words, "age"):
+		return 18 + g.rng.Intn(63), true
+	case hasWord(words, "quantity", "qty", "count"):
+		return 1 + g.rng.Intn(20), true
+	case hasWord(words, "year"):
+		return randomEpoch.Year() + g.rng.Intn(2), true
+	}
+	return 0, false
+}
+
+// uuid returns a random version 4 UUID
+func (g *RandomGenerator) uuid() string {
+	var b [16]byte
+	g.rng.Read(b[:])
+	b[6] = b[6]&0x0f | 0x40
+	b[8] = b[8]&0x3f | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:])
+}
+
+// logical generates a value for a logical type in its readable form,
+// reporting false for types it doesn't know
+func (g *RandomGenerator) logical(schema map[string]interface{}) (interface{}, bool) {
+	logicalType, _ := schema["logicalType"].(string)
+	instant := randomEpoch.Add(time.Duration(g.rng.Int63n(int64(randomSpan))))
+	midnight := time.Time{}.Add(time.Duration(g.rng.Int63n(int64(24 * time.Hour))))
+	switch logicalType {
+	case "uuid":
+		return g.uuid(), true
+	case "timestamp-millis":
+		return instant.Format("2006-01-02T15:04:05.000Z07:00"), true
+	case "timestamp-micros":
+		return instant.Format("2006-01-02T15:04:05.000000Z07:00"), true
+	case "local-timestamp-millis":
+		return instant.Format("2006-01-02T15:04:05.000"), true
+	case "local-timestamp-micros":
+		return instant.Format("2006-01-02T15:04:05.000000"), true
+	case "date":
+		return instant.Format("2006-01-02"), true
+	case "time-millis":
+		return midnight.Format("15:04:05.000"), true
+	case "time-micros":
+		return midnight.Format("15:04:05.000000"), true
+	case "decimal":
+		return g.decimal(schema), true
+	}
+	return nil, false
+}
+
+// decimal returns a random decimal within the type's precision
+func (g *RandomGenerator) decimal(schema map[string]interface{}) string {
+	precision, _ := schema["precision"].(float64)
+	digits := int(precision)
+	if digits <= 0 || digits > 9 {
+		digits = 9
+	}
+	unscaled := big.NewInt(g.rng.Int63n(int64(math.Pow10(digits))))
+	return formatDecimal(unscaled, decimalScale(schema))
+}
+
+// union picks null now and then, otherwise a random non-null branch.
+// Branches that would stop (see stops) are only taken if there's nothing
+// else.
+func (g *RandomGenerator) union(types []interface{}, path string) (interface{}, error) {
+	var branches, stopped []interface{}
+	hasNull := false
+	for _, t := range types {
+		switch {
+		case t == "null":
+			hasNull = true
+		case g.stops(t):
+			stopped = append(stopped, t)
+		default:
+			branches = append(branches, t)
+		}
+	}
+
+	if hasNull && (len(branches) == 0 || g.rng.Intn(5) == 0) {
+		return nil, nil
+	}
+	if len(branches) == 0 {
+		branches = stopped
+	}
+	if len(branches) == 0 {
+		return nil, nil
+	}
+	return g.value(branches[g.rng.Intn(len(branches))], path)
+}
+
+// stops reports whether a record of schema would be cut short here: one
+// already being generated, or one past the depth limit. Arrays and maps of
+// them stay empty, and unions take another branch.
+func (g *RandomGenerator) stops(schema interface{}) bool {
+	namespace := g.namespace
+	s, ok := schema.(map[string]interface{})
+	if name, isName := schema.(string); isName {
+		named, full, found := lookupNamed(g.namedTypes, name, g.namespace)
+		if !found {
+			return false
+		}
+		s, namespace, ok = named, namespaceOf(full), true
+	}
+	if !ok || s["type"] != "record" {
+		return false
+	}
+	return g.depth >= DefaultMaxDepth || g.active[fullName(s, namespace)] > 0
+}
+
+func (g *RandomGenerator) complex(schema map[string]interface{}, path string) (interface{}, error) {
+	schemaType, ok := schema["type"].(string)
+	if !ok {
+		return nil, fmt.Errorf("missing or invalid 'type' field")
+	}
+	if value, ok := g.logical(schema); ok {
+		return value, nil
+	}
+
+	switch schemaType {
+	case "record", "array", "map":
+		// Collections past the depth limit stay empty so they remain
+		// valid. Records go on: a field that has to hold one can't be
+		// left out, and only a self-reference could nest them forever.
+		if g.depth >= DefaultMaxDepth {
+			switch schemaType {
+			case "array":
+				return []interface{}{}, nil
+			case "map":
+				return map[string]interface{}{}, nil
+			}
+		}
+		g.depth++
+		defer func() { g.depth-- }()
+	}
+
+	switch schemaType {
+	case "record", "enum", "fixed":
+		// Types defined inside a named type inherit its namespace
+		saved := g.namespace
+		g.namespace = namespaceOf(fullName(schema, g.namespace))
+		defer func() { g.namespace = saved }()
+	}
+
+	switch schemaType {
+	case "record":
+		return g.record(schema, path)
+	case "array":
+		items := []interface{}{}
+		if g.stops(schema["items"]) {
+			return items, nil
+		}
+		for i, n := 0, 1+g.rng.Intn(3); i < n; i++ {
+			item, err := g.value(schema["items"], path+"[]")
+			if err != nil {
+				return nil, fmt.Errorf("array items: %w", err)
+			}
+			items = append(items, item)
+		}
+		return items, nil
+	case "map":
+		values := map[string]interface{}{}
+		if g.stops(schema["values"]) {
+			return values, nil
+		}
+		for i, n := 0, 1+g.rng.Intn(3); i < n; i++ {
+			value, err := g.value(schema["values"], path+"{}")
+			if err != nil {
+				return nil, fmt.Errorf("map values: %w", err)
+			}
+			values[g.word(5)] = value
+		}
+		return values, nil
+	case "enum":
+		symbols := stringList(schema["symbols"])
+		if len(symbols) == 0 {
+			return "", nil
+		}
+		return symbols[g.rng.Intn(len(symbols))], nil
+	case "fixed":
+		size, _ := schema["size"].(float64)
+		return g.word(int(size)), nil
+	default:
+		return g.primitive(schemaType, path), nil
+	}
+}
+
+func (g *RandomGenerator) record(schema map[string]interface{}, path string) (interface{}, error) {
+	fields, ok := schema["fields"].([]interface{})
+	if !ok {
+		return nil, fmt.Errorf("record missing 'fields'")
+	}
+
+	// Recursive records stop at their first self-reference, which has to
+	// be reached through a union with null, an array or a map
+	name := fullName(schema, g.namespace)
+	if g.active[name] > 0 {
+		return nil, fmt.Errorf("record %s contains itself with no null, array or map to stop at", name)
+	}
+	g.active[name]++
+	defer func() { g.active[name]-- }()
+
+	result := make(map[string]interface{})
+	for _, f := range fields {
+		field, ok := f.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fieldName, ok := field["name"].(string)
+		if !ok {
+			continue
+		}
+
+		fieldPath := fieldName
+		if path != "" {
+			fieldPath = path + "." + fieldName
+		}
+
+		if _, constrained := g.constraints.field(fieldPath); !constrained {
+			if example, ok := exampleValue(field); ok {
+				result[fieldName] = example
+				continue
+			}
+		}
+
+		val, err := g.value(field["type"], fieldPath)
+		if err != nil {
+			return nil, fmt.Errorf("field %s: %w", fieldName, err)
+		}
+		result[fieldName] = val
+	}
+
+	return result, nil
+}
+
+// word returns n random lowercase letters
+func (g *RandomGenerator) word(n int) string {
+	var b strings.Builder
+	for i := 0; i < n; i++ {
+		b.WriteByte(byte('a' + g.rng.Intn(26)))
+	}
+	return b.String()
+}
+
+func roundTo(f float64, places int) float64 {
+	scale := math.Pow(10, float64(places))
+	return math.Round(f*scale) / scale
+}
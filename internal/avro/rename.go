@@ -0,0 +1,166 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// avroName is the form Avro requires of field names
+var avroName = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*$`)
+
+// RenameField renames fields of a record schema, adding each old name to the
+// field's aliases so data written with it still resolves. from is either a
+// field path as FlattenFields gives it (customer.address.street), renaming
+// that field, or a bare name, renaming the field in every record that has
+// one. Returns the compacted schema and the paths of the renamed fields.
+func RenameField(schemaJSON, from, to string) (string, []string, error) {
+	if !avroName.MatchString(to) {
+		return "", nil, fmt.Errorf("%q is not a valid field name", to)
+	}
+
+	// Numbers are kept as written, so long defaults don't lose precision
+	dec := json.NewDecoder(strings.NewReader(schemaJSON))
+	dec.UseNumber()
+	var schema interface{}
+	if err := dec.Decode(&schema); err != nil {
+		return "", nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	r := &fieldRenamer{
+		from:       from,
+		to:         to,
+		byPath:     strings.ContainsAny(from, ".[{"),
+		namedTypes: make(map[string]map[string]interface{}),
+		visiting:   make(map[string]bool),
+	}
+	collectNamedTypes(schema, r.namedTypes)
+
+	record, ok := r.resolve(schema).(map[string]interface{})
+	if !ok || record["type"] != "record" {
+		return "", nil, fmt.Errorf("schema is not a record")
+	}
+	if err := r.walkRecord("", record); err != nil {
+		return "", nil, err
+	}
+	if len(r.renamed) == 0 {
+		return "", nil, fmt.Errorf("no field %s", from)
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false) // Keep docs readable
+	if err := enc.Encode(schema); err != nil {
+		return "", nil, err
+	}
+	renamed := strings.TrimSpace(out.String())
+	if _, err := NewCodec(renamed); err != nil {
+		return "", nil, fmt.Errorf("renamed schema is invalid: %w", err)
+	}
+	return renamed, r.renamed, nil
+}
+
+// fieldRenamer holds state while renaming fields
+type fieldRenamer struct {
+	from, to   string
+	byPath     bool // from is a path rather than a bare name
+	namedTypes map[string]map[string]interface{}
+	visiting   map[string]bool
+	renamed    []string
+}
+
+func (r *fieldRenamer) resolve(schema interface{}) interface{} {
+	if name, ok := schema.(string); ok {
+		if named, ok := r.namedTypes[name]; ok {
+			return named
+		}
+	}
+	return schema
+}
+
+func (r *fieldRenamer) walkRecord(prefix string, record map[string]interface{}) error {
+	name := recordName(record)
+	if r.visiting[name] {
+		return nil
+	}
+	r.visiting[name] = true
+	defer delete(r.visiting, name)
+
+	fields, _ := record["fields"].([]interface{})
+	for _, raw := range fields {
+		field, ok := raw.(map[string]interface{})
+		if !ok {
+			continue
+		}
+		fieldName, _ := field["name"].(string)
+		path := fieldName
+		if prefix != "" {
+			path = prefix + "." + fieldName
+		}
+
+		if (r.byPath && path == r.from) || (!r.byPath && fieldName == r.from) {
+			if err := r.rename(fields, field); err != nil {
+				return fmt.Errorf("%s: %w", path, err)
+			}
+			path = strings.TrimSuffix(path, fieldName) + r.to
+			r.renamed = append(r.renamed, path)
+		}
+
+		if err := r.walkNested(path, field["type"]); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
+func (r *fieldRenamer) walkNested(path string, schema interface{}) error {
+	switch s := r.resolve(schema).(type) {
+	case []interface{}:
+		for _, branch := range s {
+			if err := r.walkNested(path, branch); err != nil {
+				return err
+			}
+		}
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record":
+			return r.walkRecord(path, s)
+		case "array":
+			return r.walkNested(path+"[]", s["items"])
+		case "map":
+			return r.walkNested(path+"{}", s["values"])
+		}
+	}
+	return nil
+}
+
+// rename renames one field, recording the old name as an alias. A name the
+// field had before is dropped from the aliases, as a field can't alias its
+// own name.
+func (r *fieldRenamer) rename(fields []interface{}, field map[string]interface{}) error {
+	for _, raw := range fields {
+		if other, ok := raw.(map[string]interface{}); ok && other["name"] == r.to {
+			return fmt.Errorf("the record already has a field %s", r.to)
+		}
+	}
+
+	old, _ := field["name"].(string)
+	aliases := []interface{}{}
+	hasOld := false
+	for _, alias := range stringList(field["aliases"]) {
+		if alias == r.to {
+			continue
+		}
+		hasOld = hasOld || alias == old
+		aliases = append(aliases, alias)
+	}
+	if !hasOld {
+		aliases = append(aliases, old)
+	}
+
+	field["name"] = r.to
+	field["aliases"] = aliases
+	return nil
+}
@@ -0,0 +1,334 @@
+package avro
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+
+	"github.com/linkedin/goavro/v2"
+)
+
+// CheckResolution verifies that a payload written with writerSchema can be
+// read by a consumer using readerSchema, following the Avro schema
+// resolution rules for the values actually present in the payload (so an
+// enum symbol the reader lacks only matters if the payload uses it).
+// Returns the problems found, each prefixed with the field path; an empty
+// list means the consumer can read the payload. An error means the payload or
+// one of the schemas is itself invalid.
+func CheckResolution(writerSchema, readerSchema, payloadJSON string) ([]string, error) {
+	writerCodec, err := goavro.NewCodec(writerSchema)
+	if err != nil {
+		return nil, fmt.Errorf("parsing writer schema: %w", err)
+	}
+	if _, err := goavro.NewCodec(readerSchema); err != nil {
+		return nil, fmt.Errorf("parsing reader schema: %w", err)
+	}
+
+	// Round-trip through binary to get the payload in goavro's canonical
+	// native form (typed numbers, unions as single-key maps)
+	binary, err := ValidateAndEncode(writerSchema, payloadJSON)
+	if err != nil {
+		return nil, err
+	}
+	datum, _, err := writerCodec.NativeFromBinary(binary)
+	if err != nil {
+		return nil, fmt.Errorf("decoding payload: %w", err)
+	}
+
+	var writer, reader interface{}
+	if err := json.Unmarshal([]byte(writerSchema), &writer); err != nil {
+		return nil, fmt.Errorf("parsing writer schema: %w", err)
+	}
+	if err := json.Unmarshal([]byte(readerSchema), &reader); err != nil {
+		return nil, fmt.Errorf("parsing reader schema: %w", err)
+	}
+
+	r := &resolver{
+		writerTypes: make(map[string]map[string]interface{}),
+		readerTypes: make(map[string]map[string]interface{}),
+	}
+	collectNamedTypes(writer, r.writerTypes)
+	collectNamedTypes(reader, r.readerTypes)
+
+	r.resolve("$", writer, reader, datum)
+	return r.problems, nil
+}
+
+// resolver walks a writer and reader schema side by side with a datum
+type resolver struct {
+	writerTypes map[string]map[string]interface{}
+	readerTypes map[string]map[string]interface{}
+	problems    []string
+}
+
+func (r *resolver) fail(path, format string, args ...interface{}) {
+	r.problems = append(r.problems, path+": "+fmt.Sprintf(format, args...))
+}
+
+func (r *resolver) resolve(path string, writer, reader, datum interface{}) {
+	writer = deref(writer, r.writerTypes)
+	reader = deref(reader, r.readerTypes)
+
+	// Writer unions carry the branch actually written
+	if branches, ok := writer.([]interface{}); ok {
+		name, value := unionBranch(datum)
+		branch := findBranch(branches, name, r.writerTypes)
+		if branch == nil {
+			r.fail(path, "payload union branch %q not in writer schema", name)
+			return
+		}
+		r.resolve(path, branch, reader, value)
+		return
+	}
+
+	// Reader unions accept the first branch matching the writer's type
+	if branches, ok := reader.([]interface{}); ok {
+		for _, branch := range branches {
+			if r.matches(writer, deref(branch, r.readerTypes)) {
+				r.resolve(path, writer, branch, datum)
+				return
+			}
+		}
+		r.fail(path, "reader union %s has no branch for %s", describeType(reader), describeType(writer))
+		return
+	}
+
+	if !r.matches(writer, reader) {
+		r.fail(path, "reader expects %s but payload has %s", describeType(reader), describeType(writer))
+		return
+	}
+
+	// Primitives and promotions are fully checked by matches
+	switch typeOf(reader) {
+	case "record":
+		r.resolveRecord(path, writer.(map[string]interface{}), reader.(map[string]interface{}), datum)
+	case "enum":
+		symbol, _ := datum.(string)
+		readerEnum := reader.(map[string]interface{})
+		if !containsString(stringList(readerEnum["symbols"]), symbol) {
+			if _, hasDefault := readerEnum["default"]; !hasDefault {
+				r.fail(path, "enum symbol %q is unknown to the reader and its enum has no default", symbol)
+			}
+		}
+	case "array":
+		items, _ := datum.([]interface{})
+		for i, item := range items {
+			r.resolve(fmt.Sprintf("%s[%d]", path, i), writer.(map[string]interface{})["items"], reader.(map[string]interface{})["items"], item)
+		}
+	case "map":
+		values, _ := datum.(map[string]interface{})
+		keys := make([]string, 0, len(values))
+		for k := range values {
+			keys = append(keys, k)
+		}
+		sort.Strings(keys)
+		for _, k := range keys {
+			r.resolve(fmt.Sprintf("%s[%q]", path, k), writer.(map[string]interface{})["values"], reader.(map[string]interface{})["values"], values[k])
+		}
+	case "fixed":
+		if size(writer) != size(reader) {
+			r.fail(path, "fixed size %v in reader, %v in payload", size(reader), size(writer))
+		}
+	}
+}
+
+func (r *resolver) resolveRecord(path string, writer, reader map[string]interface{}, datum interface{}) {
+	values, _ := datum.(map[string]interface{})
+	writerFields := make(map[string]map[string]interface{})
+	for _, raw := range fieldsOf(writer) {
+		name, _ := raw["name"].(string)
+		writerFields[name] = raw
+	}
+
+	for _, field := range fieldsOf(reader) {
+		name, _ := field["name"].(string)
+		fieldPath := path + "." + name
+
+		writerField, source, ok := writerFieldFor(field, writerFields)
+		if !ok {
+			if _, hasDefault := field["default"]; !hasDefault {
+				r.fail(fieldPath, "required by the reader but missing from the payload schema (no default)")
+			}
+			continue
+		}
+		r.resolve(fieldPath, writerField["type"], field["type"], values[source])
+	}
+}
+
+// writerFieldFor matches a reader field to the writer field it reads: by
+// name, then by the reader field's aliases, then by the writer fields'
+// aliases, so an older reader follows a field a newer writer renamed with
+// the old name kept as an alias. It also returns the name the writer field
+// has.
+func writerFieldFor(field map[string]interface{}, writerFields map[string]map[string]interface{}) (map[string]interface{}, string, bool) {
+	name, _ := field["name"].(string)
+	if writerField, ok := writerFields[name]; ok {
+		return writerField, name, true
+	}
+	for _, alias := range stringList(field["aliases"]) {
+		if writerField, ok := writerFields[alias]; ok {
+			return writerField, alias, true
+		}
+	}
+	for writerName, writerField := range writerFields {
+		if containsString(stringList(writerField["aliases"]), name) {
+			return writerField, writerName, true
+		}
+	}
+	return nil, "", false
+}
+
+// matches reports whether a reader type can read a writer type: the same
+// primitive, an allowed promotion, or named types with matching names
+func (r *resolver) matches(writer, reader interface{}) bool {
+	writerType, readerType := typeOf(writer), typeOf(reader)
+	switch readerType {
+	case "record", "enum", "fixed":
+		if writerType != readerType {
+			return false
+		}
+		return sameName(writer.(map[string]interface{}), reader.(map[string]interface{}))
+	case "array", "map":
+		return writerType == readerType
+	}
+
+	if writerType == readerType {
+		return true
+	}
+	for _, promoted := range promotions[writerType] {
+		if promoted == readerType {
+			return true
+		}
+	}
+	return false
+}
+
+// promotions lists the reader types each writer primitive may be read as
+var promotions = map[string][]string{
+	"int":    {"long", "float", "double"},
+	"long":   {"float", "double"},
+	"float":  {"double"},
+	"string": {"bytes"},
+	"bytes":  {"string"},
+}
+
+// sameName compares named types by unqualified name, also accepting the
+// reader's aliases, and the writer's for a type a newer writer renamed
+func sameName(writer, reader map[string]interface{}) bool {
+	writerName, readerName := shortName(recordName(writer)), shortName(recordName(reader))
+	if writerName == readerName {
+		return true
+	}
+	for _, alias := range stringList(reader["aliases"]) {
+		if shortName(alias) == writerName {
+			return true
+		}
+	}
+	for _, alias := range stringList(writer["aliases"]) {
+		if shortName(alias) == readerName {
+			return true
+		}
+	}
+	return false
+}
+
+func shortName(name string) string {
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		return name[i+1:]
+	}
+	return name
+}
+
+// deref replaces a named type reference with its definition and unwraps
+// primitives written in object form ({"type": "string"})
+func deref(schema interface{}, named map[string]map[string]interface{}) interface{} {
+	switch s := schema.(type) {
+	case string:
+		if def, ok := named[s]; ok {
+			return def
+		}
+	case map[string]interface{}:
+		if t, ok := s["type"].(string); ok {
+			switch t {
+			case "record", "enum", "fixed", "array", "map":
+			default:
+				if _, hasLogical := s["logicalType"]; !hasLogical {
+					return deref(t, named)
+				}
+			}
+		}
+	}
+	return schema
+}
+
+// typeOf returns the Avro type name of a dereferenced schema
+func typeOf(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		return s
+	case []interface{}:
+		return "union"
+	case map[string]interface{}:
+		t, _ := s["type"].(string)
+		return t
+	}
+	return ""
+}
+
+// unionBranch splits a goavro union datum into branch name and value
+func unionBranch(datum interface{}) (string, interface{}) {
+	if datum == nil {
+		return "null", nil
+	}
+	if m, ok := datum.(map[string]interface{}); ok && len(m) == 1 {
+		for name, value := range m {
+			return name, value
+		}
+	}
+	return "", datum
+}
+
+// findBranch returns the union branch a goavro branch name refers to
+func findBranch(branches []interface{}, name string, named map[string]map[string]interface{}) interface{} {
+	for _, branch := range branches {
+		resolved := deref(branch, named)
+		branchName := typeOf(resolved)
+		switch branchName {
+		case "record", "enum", "fixed":
+			branchName = recordName(resolved.(map[string]interface{}))
+		}
+		if branchName == name || shortName(branchName) == shortName(name) {
+			return branch
+		}
+	}
+	return nil
+}
+
+func fieldsOf(record map[string]interface{}) []map[string]interface{} {
+	raw, _ := record["fields"].([]interface{})
+	fields := make([]map[string]interface{}, 0, len(raw))
+	for _, f := range raw {
+		if field, ok := f.(map[string]interface{}); ok {
+			fields = append(fields, field)
+		}
+	}
+	return fields
+}
+
+func size(schema interface{}) float64 {
+	if m, ok := schema.(map[string]interface{}); ok {
+		n, _ := m["size"].(float64)
+		return n
+	}
+	return 0
+}
+
+func containsString(list []string, s string) bool {
+	for _, item := range list {
+		if item == s {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,235 @@
+package avro
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+)
+
+// avroNamespace is the form Avro requires of namespaces
+var avroNamespace = regexp.MustCompile(`^[A-Za-z_][A-Za-z0-9_]*(\.[A-Za-z_][A-Za-z0-9_]*)*$`)
+
+// ScaffoldTypes lists the field types Scaffold understands, as a hint
+const ScaffoldTypes = "string, int, long, float, double, boolean, bytes, date, time-millis, timestamp-millis, timestamp-micros, uuid, decimal(10,2), enum(A,B), array<T>, map<T>"
+
+// Scaffold describes a new record schema field by field
+type Scaffold struct {
+	Namespace string
+	Name      string
+	Doc       string
+	Fields    []ScaffoldField
+}
+
+// ScaffoldField is one field of a scaffolded record
+type ScaffoldField struct {
+	Name     string
+	Type     string // One of ScaffoldTypes
+	Nullable bool   // A union with null; defaults to null without a Default
+	Default  string // JSON, or plain text for string-like types
+	Doc      string
+
+	// Definition is a complete field definition in JSON, as a snippet gives
+	// it; Type, Nullable, Default and Doc are ignored when it is set
+	Definition string
+}
+
+// scaffoldRecord and scaffoldFieldJSON fix the key order of the schema
+// Scaffold writes, so it reads like a hand-written .avsc
+type scaffoldRecord struct {
+	Type      string        `json:"type"`
+	Name      string        `json:"name"`
+	Namespace string        `json:"namespace,omitempty"`
+	Doc       string        `json:"doc,omitempty"`
+	Fields    []interface{} `json:"fields"`
+}
+
+type scaffoldFieldJSON struct {
+	Name    string          `json:"name"`
+	Type    interface{}     `json:"type"`
+	Doc     string          `json:"doc,omitempty"`
+	Default json.RawMessage `json:"default,omitempty"`
+}
+
+type scaffoldType struct {
+	Type        string      `json:"type"`
+	Name        string      `json:"name,omitempty"`
+	LogicalType string      `json:"logicalType,omitempty"`
+	Precision   int         `json:"precision,omitempty"`
+	Scale       int         `json:"scale,omitempty"`
+	Symbols     []string    `json:"symbols,omitempty"`
+	Items       interface{} `json:"items,omitempty"`
+	Values      interface{} `json:"values,omitempty"`
+}
+
+// Schema builds the record schema, indented and checked to be valid Avro
+func (s Scaffold) Schema() (string, error) {
+	if !avroName.MatchString(s.Name) {
+		return "", fmt.Errorf("%q is not a valid record name", s.Name)
+	}
+	if s.Namespace != "" && !avroNamespace.MatchString(s.Namespace) {
+		return "", fmt.Errorf("%q is not a valid namespace", s.Namespace)
+	}
+	if len(s.Fields) == 0 {
+		return "", fmt.Errorf("the record needs at least one field")
+	}
+
+	record := scaffoldRecord{Type: "record", Name: s.Name, Namespace: s.Namespace, Doc: s.Doc}
+	seen := make(map[string]bool)
+	for _, f := range s.Fields {
+		if f.Definition != "" {
+			parsed, err := ParseFields(f.Definition)
+			if err != nil {
+				return "", err
+			}
+			f.Name, _ = parsed[0].(map[string]interface{})["name"].(string)
+			if seen[f.Name] {
+				return "", fmt.Errorf("field %s is listed twice", f.Name)
+			}
+			seen[f.Name] = true
+			record.Fields = append(record.Fields, parsed[0])
+			continue
+		}
+		if !avroName.MatchString(f.Name) {
+			return "", fmt.Errorf("%q is not a valid field name", f.Name)
+		}
+		if seen[f.Name] {
+			return "", fmt.Errorf("field %s is listed twice", f.Name)
+		}
+		seen[f.Name] = true
+
+		field, err := f.build()
+		if err != nil {
+			return "", fmt.Errorf("field %s: %w", f.Name, err)
+		}
+		record.Fields = append(record.Fields, field)
+	}
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(record); err != nil {
+		return "", err
+	}
+	schema := strings.TrimSpace(out.String())
+	if _, err := NewCodec(schema); err != nil {
+		return "", fmt.Errorf("invalid schema: %w", err)
+	}
+	return schema, nil
+}
+
+func (f ScaffoldField) build() (scaffoldFieldJSON, error) {
+	typ, err := parseScaffoldType(strings.TrimSpace(f.Type), f.Name)
+	if err != nil {
+		return scaffoldFieldJSON{}, err
+	}
+	field := scaffoldFieldJSON{Name: f.Name, Type: typ, Doc: f.Doc}
+
+	if f.Default != "" {
+		def, err := scaffoldDefault(typ, f.Default)
+		if err != nil {
+			return scaffoldFieldJSON{}, err
+		}
+		field.Default = def
+	}
+	if f.Nullable {
+		// A union's default must match its first branch
+		if f.Default == "" {
+			field.Type = []interface{}{"null", typ}
+			field.Default = json.RawMessage("null")
+		} else {
+			field.Type = []interface{}{typ, "null"}
+		}
+	}
+	return field, nil
+}
+
+// parseScaffoldType turns a type as typed in the wizard into schema JSON.
+// field names the enum types it declares.
+func parseScaffoldType(t, field string) (interface{}, error) {
+	switch t {
+	case "":
+		return nil, fmt.Errorf("no type given")
+	case "string", "int", "long", "float", "double", "boolean", "bytes":
+		return t, nil
+	case "date", "time-millis":
+		return scaffoldType{Type: "int", LogicalType: t}, nil
+	case "timestamp-millis", "timestamp-micros", "time-micros":
+		return scaffoldType{Type: "long", LogicalType: t}, nil
+	case "uuid":
+		return scaffoldType{Type: "string", LogicalType: t}, nil
+	}
+
+	inner, ok := typeArgument(t, "array<", ">")
+	if ok {
+		items, err := parseScaffoldType(inner, field+"Item")
+		if err != nil {
+			return nil, err
+		}
+		return scaffoldType{Type: "array", Items: items}, nil
+	}
+	if inner, ok = typeArgument(t, "map<", ">"); ok {
+		values, err := parseScaffoldType(inner, field+"Value")
+		if err != nil {
+			return nil, err
+		}
+		return scaffoldType{Type: "map", Values: values}, nil
+	}
+	if inner, ok = typeArgument(t, "enum(", ")"); ok {
+		var symbols []string
+		for _, symbol := range strings.Split(inner, ",") {
+			symbol = strings.TrimSpace(symbol)
+			if !avroName.MatchString(symbol) {
+				return nil, fmt.Errorf("%q is not a valid enum symbol", symbol)
+			}
+			symbols = append(symbols, symbol)
+		}
+		return scaffoldType{Type: "enum", Name: strings.ToUpper(field[:1]) + field[1:], Symbols: symbols}, nil
+	}
+	if inner, ok = typeArgument(t, "decimal(", ")"); ok {
+		precision, scale, _ := strings.Cut(inner, ",")
+		p, err := strconv.Atoi(strings.TrimSpace(precision))
+		if err != nil || p < 1 {
+			return nil, fmt.Errorf("invalid decimal precision %q", precision)
+		}
+		s := 0
+		if strings.TrimSpace(scale) != "" {
+			if s, err = strconv.Atoi(strings.TrimSpace(scale)); err != nil || s < 0 || s > p {
+				return nil, fmt.Errorf("invalid decimal scale %q", scale)
+			}
+		}
+		return scaffoldType{Type: "bytes", LogicalType: "decimal", Precision: p, Scale: s}, nil
+	}
+	return nil, fmt.Errorf("unknown type %q (want %s)", t, ScaffoldTypes)
+}
+
+// typeArgument returns what is between prefix and suffix
+func typeArgument(t, prefix, suffix string) (string, bool) {
+	if !strings.HasPrefix(t, prefix) || !strings.HasSuffix(t, suffix) {
+		return "", false
+	}
+	return strings.TrimSpace(t[len(prefix) : len(t)-len(suffix)]), true
+}
+
+// scaffoldDefault reads a default as JSON, or as plain text for the types
+// whose values are strings
+func scaffoldDefault(typ interface{}, value string) (json.RawMessage, error) {
+	stringLike := typ == "string" || typ == "bytes"
+	if t, ok := typ.(scaffoldType); ok {
+		stringLike = t.Type == "enum" || t.LogicalType == "uuid"
+	}
+	if stringLike {
+		var s string
+		if json.Unmarshal([]byte(value), &s) == nil {
+			return json.RawMessage(value), nil // Already quoted
+		}
+		return json.Marshal(value)
+	}
+	if !json.Valid([]byte(value)) {
+		return nil, fmt.Errorf("default %q is not valid JSON", value)
+	}
+	return json.RawMessage(value), nil
+}
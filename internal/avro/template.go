@@ -5,26 +5,59 @@ import (
 	"fmt"
 )
 
+// DefaultMaxDepth is the nesting limit used when TemplateOptions.MaxDepth is unset
+const DefaultMaxDepth = 10
+
+// RecursiveMarker is the key of the placeholder object emitted where a
+// self-referential record is cut off
+const RecursiveMarker = "__recursive"
+
+// TemplateOptions controls how templates are generated
+type TemplateOptions struct {
+	PopulateCollections bool // Put one example element in arrays and maps instead of leaving them empty
+	MaxDepth            int  // Records, arrays and maps nested deeper than this become null
+	PreferNull          bool // Use null for optional unions instead of the first non-null branch
+	MaxRecursion        int  // How many levels a record may nest inside itself, 0 cuts at the first self-reference
+	OmitDefaults        bool // Leave out fields that have a default, for the smallest valid payload
+}
+
 // templateGenerator holds state while generating a template,
 // including a registry of named types encountered during parsing.
 type templateGenerator struct {
 	namedTypes map[string]map[string]interface{}
+	opts       TemplateOptions
+	depth      int
+	active     map[string]int // Records currently being generated, by full name
+	namespace  string         // Enclosing namespace, for resolving references
 }
 
 // GenerateTemplate creates a JSON template from an Avro schema.
 // The template contains placeholder values for each field.
 func GenerateTemplate(schemaJSON string) (string, error) {
-	var schema map[string]interface{}
+	return GenerateTemplateWithOptions(schemaJSON, TemplateOptions{})
+}
+
+// GenerateTemplateWithOptions creates a JSON template from an Avro schema
+// using the given generation options
+func GenerateTemplateWithOptions(schemaJSON string, opts TemplateOptions) (string, error) {
+	// Key subjects often have a primitive schema, such as "string"
+	var schema interface{}
 	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
 		return "", fmt.Errorf("parsing schema: %w", err)
 	}
 
+	if opts.MaxDepth <= 0 {
+		opts.MaxDepth = DefaultMaxDepth
+	}
+
 	gen := &templateGenerator{
 		namedTypes: make(map[string]map[string]interface{}),
+		opts:       opts,
+		active:     make(map[string]int),
 	}
 
 	// First pass: collect all named types
-	gen.collectNamedTypes(schema)
+	collectNamedTypes(schema, gen.namedTypes)
 
 	// Second pass: generate the template
 	result, err := gen.generateValue(schema)
@@ -40,22 +73,33 @@ func GenerateTemplate(schemaJSON string) (string, error) {
 	return string(pretty), nil
 }
 
-// collectNamedTypes recursively finds and registers all named types in the schema
-func (g *templateGenerator) collectNamedTypes(schema interface{}) {
+// collectNamedTypes recursively finds and registers all named types in the
+// schema by full name, with namespaces inherited from enclosing types as the
+// Avro spec has it. Each short name is also registered for the first type
+// using it, so lookups by short name keep working; lookupNamed resolves
+// references properly.
+func collectNamedTypes(schema interface{}, namedTypes map[string]map[string]interface{}) {
+	collectNamed(schema, "", namedTypes)
+}
+
+func collectNamed(schema interface{}, namespace string, namedTypes map[string]map[string]interface{}) {
 	switch s := schema.(type) {
 	case map[string]interface{}:
 		// Check if this is a named type (record, enum, fixed)
 		if typeName, ok := s["type"].(string); ok {
 			switch typeName {
-			case "record", "enum", "fixed":
-				if name, ok := s["name"].(string); ok {
-					// Register with full name if namespace exists
-					fullName := name
-					if ns, ok := s["namespace"].(string); ok {
-						fullName = ns + "." + name
+			case "record", "error", "enum", "fixed":
+				if _, ok := s["name"].(string); ok {
+					full := fullName(s, namespace)
+					namespace = namespaceOf(full)
+					// A type in the null namespace takes its name from an
+					// alias registered for a type in another
+					namedTypes[full] = s
+					if short := shortName(full); short != full {
+						if _, ok := namedTypes[short]; !ok {
+							namedTypes[short] = s
+						}
 					}
-					g.namedTypes[name] = s
-					g.namedTypes[fullName] = s
 				}
 			}
 		}
@@ -65,7 +109,7 @@ func (g *templateGenerator) collectNamedTypes(schema interface{}) {
 			for _, f := range fields {
 				if field, ok := f.(map[string]interface{}); ok {
 					if fieldType, ok := field["type"]; ok {
-						g.collectNamedTypes(fieldType)
+						collectNamed(fieldType, namespace, namedTypes)
 					}
 				}
 			}
@@ -73,22 +117,38 @@ func (g *templateGenerator) collectNamedTypes(schema interface{}) {
 
 		// Recurse into array items
 		if items, ok := s["items"]; ok {
-			g.collectNamedTypes(items)
+			collectNamed(items, namespace, namedTypes)
 		}
 
 		// Recurse into map values
 		if values, ok := s["values"]; ok {
-			g.collectNamedTypes(values)
+			collectNamed(values, namespace, namedTypes)
 		}
 
 	case []interface{}:
 		// Union type - recurse into each option
 		for _, t := range s {
-			g.collectNamedTypes(t)
+			collectNamed(t, namespace, namedTypes)
 		}
 	}
 }
 
+// lookupNamed resolves a reference to a named type from within namespace:
+// a short name means the type in namespace, else the one in the null
+// namespace. It returns the type and its full name.
+func lookupNamed(namedTypes map[string]map[string]interface{}, name, namespace string) (map[string]interface{}, string, bool) {
+	full := qualify(name, namespace)
+	if t, ok := namedTypes[full]; ok {
+		return t, full, true
+	}
+	// A type in the null namespace, or one registered by its short name
+	t, ok := namedTypes[name]
+	if !ok {
+		return nil, "", false
+	}
+	return t, fullName(t, ""), true
+}
+
 func (g *templateGenerator) generateValue(schema interface{}) (interface{}, error) {
 	switch s := schema.(type) {
 	case string:
@@ -117,8 +177,11 @@ func (g *templateGenerator) generatePrimitive(typeName string) (interface{}, err
 	case "string":
 		return "", nil
 	default:
-		// Named type reference - look it up
-		if namedType, ok := g.namedTypes[typeName]; ok {
+		// Named type reference - resolve it from the enclosing namespace
+		if namedType, full, ok := lookupNamed(g.namedTypes, typeName, g.namespace); ok {
+			saved := g.namespace
+			g.namespace = namespaceOf(full)
+			defer func() { g.namespace = saved }()
 			return g.generateComplex(namedType)
 		}
 		// Unknown type, return empty string
@@ -127,6 +190,14 @@ func (g *templateGenerator) generatePrimitive(typeName string) (interface{}, err
 }
 
 func (g *templateGenerator) generateUnion(types []interface{}) (interface{}, error) {
+	if g.opts.PreferNull {
+		for _, t := range types {
+			if str, ok := t.(string); ok && str == "null" {
+				return nil, nil
+			}
+		}
+	}
+
 	// For unions, prefer the first non-null type
 	// If all are null, return null
 	for _, t := range types {
@@ -144,8 +215,41 @@ func (g *templateGenerator) generateComplex(schema map[string]interface{}) (inte
 		return nil, fmt.Errorf("missing or invalid 'type' field")
 	}
 
+	if example, ok := exampleValue(schema); ok {
+		return example, nil
+	}
+	if placeholder, ok := logicalPlaceholder(schema); ok {
+		return placeholder, nil
+	}
+
+	switch schemaType {
+	case "record", "error", "array", "map":
+		// Nested containers count towards the depth limit, which also stops
+		// self-referential records from recursing forever. Collections past
+		// the limit stay empty so they remain valid.
+		if g.depth >= g.opts.MaxDepth {
+			switch schemaType {
+			case "array":
+				return []interface{}{}, nil
+			case "map":
+				return map[string]interface{}{}, nil
+			}
+			return nil, nil
+		}
+		g.depth++
+		defer func() { g.depth-- }()
+	}
+
+	switch schemaType {
+	case "record", "error", "enum", "fixed":
+		// Types defined inside a named type inherit its namespace
+		saved := g.namespace
+		g.namespace = namespaceOf(fullName(schema, g.namespace))
+		defer func() { g.namespace = saved }()
+	}
+
 	switch schemaType {
-	case "record":
+	case "record", "error":
 		return g.generateRecord(schema)
 	case "array":
 		return g.generateArray(schema)
@@ -167,6 +271,15 @@ func (g *templateGenerator) generateRecord(schema map[string]interface{}) (inter
 		return nil, fmt.Errorf("record missing 'fields'")
 	}
 
+	// Cut cycles in self-referential records (trees, linked lists) with a
+	// visible marker rather than expanding them up to the depth limit
+	name := fullName(schema, g.namespace)
+	if g.active[name] > g.opts.MaxRecursion {
+		return map[string]interface{}{RecursiveMarker: nil}, nil
+	}
+	g.active[name]++
+	defer func() { g.active[name]-- }()
+
 	result := make(map[string]interface{})
 
 	for _, f := range fields {
@@ -185,6 +298,17 @@ func (g *templateGenerator) generateRecord(schema map[string]interface{}) (inter
 			continue
 		}
 
+		// The encoder fills in defaults, so minimal payloads leave them out
+		if _, hasDefault := field["default"]; hasDefault && g.opts.OmitDefaults {
+			continue
+		}
+
+		// Prefer an example supplied by the schema author
+		if example, ok := exampleValue(field); ok {
+			result[name] = example
+			continue
+		}
+
 		// Check for default value
 		if defaultVal, hasDefault := field["default"]; hasDefault {
 			result[name] = defaultVal
@@ -202,13 +326,31 @@ func (g *templateGenerator) generateRecord(schema map[string]interface{}) (inter
 }
 
 func (g *templateGenerator) generateArray(schema map[string]interface{}) (interface{}, error) {
-	// Return empty array
-	return []interface{}{}, nil
+	items, ok := schema["items"]
+	if !g.opts.PopulateCollections || !ok {
+		// Return empty array
+		return []interface{}{}, nil
+	}
+
+	item, err := g.generateValue(items)
+	if err != nil {
+		return nil, fmt.Errorf("array items: %w", err)
+	}
+	return []interface{}{item}, nil
 }
 
 func (g *templateGenerator) generateMap(schema map[string]interface{}) (interface{}, error) {
-	// Return empty map
-	return map[string]interface{}{}, nil
+	values, ok := schema["values"]
+	if !g.opts.PopulateCollections || !ok {
+		// Return empty map
+		return map[string]interface{}{}, nil
+	}
+
+	value, err := g.generateValue(values)
+	if err != nil {
+		return nil, fmt.Errorf("map values: %w", err)
+	}
+	return map[string]interface{}{"key": value}, nil
 }
 
 func (g *templateGenerator) generateEnum(schema map[string]interface{}) (interface{}, error) {
@@ -227,3 +369,21 @@ func (g *templateGenerator) generateFixed(schema map[string]interface{}) (interf
 	// Return empty string for fixed bytes
 	return "", nil
 }
+
+// exampleValue returns a sample value embedded in a field or type by the
+// schema author: an "example" attribute, the first of "examples", or the
+// first of "arg.properties" options (the avro-random-generator convention)
+func exampleValue(attrs map[string]interface{}) (interface{}, bool) {
+	if example, ok := attrs["example"]; ok {
+		return example, true
+	}
+	if examples, ok := attrs["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0], true
+	}
+	if props, ok := attrs["arg.properties"].(map[string]interface{}); ok {
+		if options, ok := props["options"].([]interface{}); ok && len(options) > 0 {
+			return options[0], true
+		}
+	}
+	return nil, false
+}
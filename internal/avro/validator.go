@@ -1,83 +1,108 @@
 package avro
 
-import (
-	"encoding/json"
-	"fmt"
-
-	"github.com/linkedin/goavro/v2"
-)
+import "encoding/json"
 
 // Validator validates JSON data against an Avro schema.
 type Validator struct {
-	codec *goavro.Codec
+	schema string
+	codec  Codec
 }
 
 // NewValidator creates a new Avro validator from a schema JSON string.
 func NewValidator(schemaJSON string) (*Validator, error) {
-	codec, err := goavro.NewCodec(schemaJSON)
+	codec, err := NewCodec(schemaJSON)
 	if err != nil {
-		return nil, fmt.Errorf("parsing schema: %w", err)
+		return nil, err
 	}
 
-	return &Validator{codec: codec}, nil
+	return &Validator{schema: schemaJSON, codec: codec}, nil
 }
 
 // Validate checks if the JSON data is valid according to the schema.
 // Returns nil if valid, or an error describing the validation failure.
 func (v *Validator) Validate(jsonData string) error {
-	// Parse JSON to native Go types
-	var native interface{}
-	if err := json.Unmarshal([]byte(jsonData), &native); err != nil {
-		return fmt.Errorf("invalid JSON: %w", err)
-	}
-
-	// Convert to Avro-compatible format and validate by encoding
-	_, err := v.codec.BinaryFromNative(nil, native)
-	if err != nil {
-		return fmt.Errorf("validation failed: %w", err)
-	}
-
-	return nil
+	// Validate by encoding
+	_, err := v.Encode(jsonData)
+	return err
 }
 
 // Encode converts JSON data to Avro binary format.
-// Returns the binary data or an error if validation fails.
+// Returns the binary data or an error if validation fails. An invalid enum
+// symbol is reported with the allowed symbols and the closest one.
 func (v *Validator) Encode(jsonData string) ([]byte, error) {
-	var native interface{}
-	if err := json.Unmarshal([]byte(jsonData), &native); err != nil {
-		return nil, fmt.Errorf("invalid JSON: %w", err)
-	}
-
-	binary, err := v.codec.BinaryFromNative(nil, native)
+	binary, err := v.codec.Encode(jsonData)
 	if err != nil {
-		return nil, fmt.Errorf("encoding failed: %w", err)
+		return nil, explainEnums(v.schema, jsonData, err)
 	}
-
 	return binary, nil
 }
 
 // Decode converts Avro binary data to JSON.
 // Returns the JSON string or an error if decoding fails.
 func (v *Validator) Decode(binary []byte) (string, error) {
-	native, _, err := v.codec.NativeFromBinary(binary)
+	return v.codec.Decode(binary)
+}
+
+// ValidateAndEncode validates JSON data and returns Avro binary if valid.
+func ValidateAndEncode(schemaJSON, jsonData string) ([]byte, error) {
+	v, err := NewValidator(schemaJSON)
 	if err != nil {
-		return "", fmt.Errorf("decoding failed: %w", err)
+		return nil, err
 	}
+	return v.Encode(jsonData)
+}
 
-	// Convert native Go types back to JSON
-	jsonBytes, err := json.Marshal(native)
+// EncodeKey validates a message key and returns its Avro binary. Keys are
+// entered on one line, so a key that doesn't fit the schema as JSON is tried
+// again as a string, letting string keys be typed without quotes.
+func EncodeKey(schemaJSON, key string) ([]byte, error) {
+	v, err := NewValidator(schemaJSON)
 	if err != nil {
-		return "", fmt.Errorf("converting to JSON: %w", err)
+		return nil, err
+	}
+	binary, err := v.Encode(key)
+	if err == nil {
+		return binary, nil
 	}
+	if quoted, ok := quoteKey(key); ok {
+		if binary, qerr := v.Encode(quoted); qerr == nil {
+			return binary, nil
+		}
+	}
+	return nil, err
+}
 
-	return string(jsonBytes), nil
+// quoteKey returns key as a JSON string, unless it already is one
+func quoteKey(key string) (string, bool) {
+	var s string
+	if json.Unmarshal([]byte(key), &s) == nil {
+		return "", false
+	}
+	quoted, err := json.Marshal(key)
+	return string(quoted), err == nil
 }
 
-// ValidateAndEncode validates JSON data and returns Avro binary if valid.
-func ValidateAndEncode(schemaJSON, jsonData string) ([]byte, error) {
-	v, err := NewValidator(schemaJSON)
+// DecodeStandard converts Avro binary data to plain JSON, with union values
+// unwrapped ("x" rather than {"string": "x"}) so they read like ordinary
+// documents for filtering and display.
+func DecodeStandard(schemaJSON string, binary []byte) (string, error) {
+	codec, err := NewCodec(schemaJSON)
+	if err != nil {
+		return "", err
+	}
+	return codec.DecodeStandard(binary)
+}
+
+// EncodeStandard converts plain JSON, with union values unwrapped as
+// DecodeStandard writes them, to Avro binary.
+func EncodeStandard(schemaJSON, jsonData string) ([]byte, error) {
+	codec, err := NewCodec(schemaJSON)
 	if err != nil {
 		return nil, err
 	}
-	return v.Encode(jsonData)
+	binary, err := codec.EncodeStandard(jsonData)
+	if err != nil {
+		return nil, explainEnums(schemaJSON, jsonData, err)
+	}
+	return binary, nil
 }
@@ -0,0 +1,169 @@
+package avro
+
+import (
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"sync"
+)
+
+// SplitWireFormat separates a Schema Registry wire-format message into its
+// schema ID and Avro payload. Returns false if data has no wire header.
+func SplitWireFormat(data []byte) (int, []byte, bool) {
+	if len(data) < 5 || data[0] != 0 {
+		return 0, nil, false
+	}
+	return int(binary.BigEndian.Uint32(data[1:5])), data[5:], true
+}
+
+// JoinWireFormat prepends the Schema Registry wire header (magic byte and
+// schema ID) to a payload
+func JoinWireFormat(schemaID int, payload []byte) []byte {
+	data := make([]byte, 5+len(payload))
+	binary.BigEndian.PutUint32(data[1:5], uint32(schemaID))
+	copy(data[5:], payload)
+	return data
+}
+
+// Decode converts a wire-format message back to Avro's textual JSON, with
+// union values wrapped as Encode expects them. lookup resolves the schema
+// ID in the header to the schema the payload was written with, typically
+// a registry client's GetSchemaByID or a WireDecoder's Schema, which
+// caches it. The payload is resolved to schemaJSON as the reader schema,
+// or read as written if schemaJSON is empty.
+func Decode(schemaJSON string, wireBytes []byte, lookup func(id int) (string, error)) (string, error) {
+	schemaID, payload, ok := SplitWireFormat(wireBytes)
+	if !ok {
+		return "", fmt.Errorf("message has no wire-format header")
+	}
+	writer, err := lookup(schemaID)
+	if err != nil {
+		return "", fmt.Errorf("looking up writer schema %d: %w", schemaID, err)
+	}
+	if schemaJSON == "" {
+		schemaJSON = writer
+	}
+	reader, err := NewCodec(schemaJSON)
+	if err != nil {
+		return "", err
+	}
+	if writer == schemaJSON {
+		return reader.Decode(payload)
+	}
+
+	// Resolve to the reader schema, then write it as the reader's JSON
+	projected, problems, err := Project(writer, schemaJSON, payload)
+	if err != nil {
+		return "", err
+	}
+	if len(problems) > 0 {
+		return "", fmt.Errorf("the reader schema can't read schema %d:\n  %s", schemaID, strings.Join(problems, "\n  "))
+	}
+	binary, err := reader.EncodeStandard(projected)
+	if err != nil {
+		return "", err
+	}
+	return reader.Decode(binary)
+}
+
+// WireDecoder decodes consumed message values to plain JSON, looking up the
+// schema for each wire-format schema ID once and caching it. It is safe for
+// concurrent use.
+type WireDecoder struct {
+	fetch   func(id int) (string, error)
+	mu      sync.Mutex
+	schemas map[int]string
+	codecs  map[int]Codec
+
+	// Transform, if set, post-processes each decoded wire-format document,
+	// for example to decrypt encrypted fields. Its errors are ignored and
+	// leave the document as it was decoded.
+	Transform func(schemaID int, doc interface{}) (interface{}, error)
+}
+
+// NewWireDecoder creates a decoder that resolves schema IDs with fetch,
+// typically a registry client's GetSchemaByID
+func NewWireDecoder(fetch func(id int) (string, error)) *WireDecoder {
+	return &WireDecoder{fetch: fetch, schemas: make(map[int]string), codecs: make(map[int]Codec)}
+}
+
+// Decode returns a message value as plain JSON text and as a parsed
+// document. Values without a wire header are accepted if they are already
+// JSON.
+func (d *WireDecoder) Decode(data []byte) (string, interface{}, error) {
+	text := string(data)
+	if schemaID, payload, ok := SplitWireFormat(data); ok {
+		codec, err := d.codec(schemaID)
+		switch {
+		case err != nil && json.Valid(payload):
+			// A JSON Schema subject: the payload is the document itself
+			text = string(payload)
+		case err != nil:
+			return "", nil, err
+		default:
+			if text, err = codec.DecodeStandard(payload); err != nil {
+				return "", nil, err
+			}
+		}
+	}
+
+	var doc interface{}
+	if err := json.Unmarshal([]byte(text), &doc); err != nil {
+		return "", nil, fmt.Errorf("message is neither wire-format Avro nor JSON")
+	}
+
+	if schemaID, _, ok := SplitWireFormat(data); ok && d.Transform != nil {
+		transformed, err := d.Transform(schemaID, doc)
+		out, merr := json.Marshal(transformed)
+		if err == nil && merr == nil {
+			text, doc = string(out), transformed
+		} else {
+			// Transform may have changed doc in place before failing
+			json.Unmarshal([]byte(text), &doc)
+		}
+	}
+	return text, doc, nil
+}
+
+// Schema returns the schema for a wire-format schema ID
+func (d *WireDecoder) Schema(id int) (string, error) {
+	d.mu.Lock()
+	schema, cached := d.schemas[id]
+	d.mu.Unlock()
+	if cached {
+		return schema, nil
+	}
+
+	schema, err := d.fetch(id)
+	if err != nil {
+		return "", fmt.Errorf("fetching schema %d: %w", id, err)
+	}
+	d.mu.Lock()
+	d.schemas[id] = schema
+	d.mu.Unlock()
+	return schema, nil
+}
+
+// codec returns the parsed schema for a wire-format schema ID
+func (d *WireDecoder) codec(id int) (Codec, error) {
+	d.mu.Lock()
+	codec, cached := d.codecs[id]
+	d.mu.Unlock()
+	if cached {
+		return codec, nil
+	}
+
+	schema, err := d.Schema(id)
+	if err != nil {
+		return nil, err
+	}
+	codec, err = NewCodec(schema)
+	if err != nil {
+		return nil, err
+	}
+	d.mu.Lock()
+	d.codecs[id] = codec
+	d.mu.Unlock()
+	return codec, nil
+}
@@ -0,0 +1,205 @@
+// Package batch applies a failure policy to sends of many records, and
+// keeps track of which records failed and why.
+package batch
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// Mode says what a send does when a record fails
+type Mode string
+
+const (
+	Stop  Mode = "stop"  // Stop at the first failed record
+	Skip  Mode = "skip"  // Leave failed records out and carry on
+	Retry Mode = "retry" // Retry broker errors, then leave the record out
+)
+
+// defaultRetries is how many times "retry" retries without a count
+const defaultRetries = 3
+
+// retryDelay is the wait before the first retry, doubled for each after
+const retryDelay = 500 * time.Millisecond
+
+// produceTimeout bounds each produce attempt
+const produceTimeout = 30 * time.Second
+
+// Policy is a failure mode, with the retry count for Retry
+type Policy struct {
+	Mode    Mode
+	Retries int
+}
+
+// ParsePolicy reads "stop", "skip", "retry" or "retry=N"
+func ParsePolicy(s string) (Policy, error) {
+	mode, count, hasCount := strings.Cut(strings.ToLower(strings.TrimSpace(s)), "=")
+	switch Mode(mode) {
+	case Stop, Skip:
+		if !hasCount {
+			return Policy{Mode: Mode(mode)}, nil
+		}
+	case Retry:
+		if !hasCount {
+			return Policy{Mode: Retry, Retries: defaultRetries}, nil
+		}
+		if n, err := strconv.Atoi(count); err == nil && n > 0 {
+			return Policy{Mode: Retry, Retries: n}, nil
+		}
+	}
+	return Policy{}, fmt.Errorf("invalid failure policy %q (want stop, skip or retry=N)", s)
+}
+
+func (p Policy) String() string {
+	if p.Mode == Retry {
+		return fmt.Sprintf("retry=%d", p.Retries)
+	}
+	return string(p.Mode)
+}
+
+// Kind is why a record failed
+type Kind string
+
+const (
+	Validation Kind = "validation" // The record couldn't be converted or doesn't fit the schema
+	Broker     Kind = "broker"     // Kafka didn't accept the record
+)
+
+// Failure is one record left out of a send
+type Failure struct {
+	Record   string // Where the record came from, e.g. "partition 0 offset 12"
+	Kind     Kind
+	Err      error
+	Attempts int // Produce attempts, for broker errors
+}
+
+// Report collects the outcome of a send under a policy
+type Report struct {
+	Policy   Policy
+	Sent     int
+	Retried  int // Records sent after at least one retry
+	Failures []Failure
+	Stopped  bool // The policy stopped the send at a failure
+}
+
+// NewReport starts a report for a send under policy
+func NewReport(policy Policy) *Report {
+	return &Report{Policy: policy}
+}
+
+// Invalid records a record that failed before it was produced. It returns
+// the error when the policy stops the send, and nil to carry on.
+func (r *Report) Invalid(record string, err error) error {
+	return r.fail(Failure{Record: record, Kind: Validation, Err: err})
+}
+
+func (r *Report) fail(f Failure) error {
+	r.Failures = append(r.Failures, f)
+	if r.Policy.Mode == Stop {
+		r.Stopped = true
+		return fmt.Errorf("%s: %w", f.Record, f.Err)
+	}
+	return nil
+}
+
+// Produce writes a batch of records, labelled for the report. Records the
+// broker rejects are produced again under the retry policy, with a growing
+// delay, then recorded as broker failures. It returns an error when the
+// policy stops the send.
+func (r *Report) Produce(ctx context.Context, producer *kafka.Producer, topic string, schemaID int, records []kafka.Record, labels []string) error {
+	pending := make([]int, len(records))
+	for i := range records {
+		pending[i] = i
+	}
+
+	for attempt := 1; len(pending) > 0; attempt++ {
+		batch := make([]kafka.Record, len(pending))
+		for i, idx := range pending {
+			batch[i] = records[idx]
+		}
+
+		attemptCtx, cancel := context.WithTimeout(ctx, produceTimeout)
+		err := producer.ProduceBatch(attemptCtx, topic, schemaID, batch)
+		cancel()
+
+		var failed []int
+		var errs []error
+		if err != nil {
+			perRecord := kafka.RecordErrors(err)
+			for i, idx := range pending {
+				recordErr := err
+				if perRecord != nil {
+					recordErr = perRecord[i]
+				}
+				if recordErr != nil {
+					failed = append(failed, idx)
+					errs = append(errs, recordErr)
+				}
+			}
+		}
+
+		r.Sent += len(pending) - len(failed)
+		if attempt > 1 {
+			r.Retried += len(pending) - len(failed)
+		}
+		if len(failed) == 0 {
+			return nil
+		}
+
+		if r.Policy.Mode != Retry || attempt > r.Policy.Retries {
+			for i, idx := range failed {
+				if stopErr := r.fail(Failure{Record: labels[idx], Kind: Broker, Err: errs[i], Attempts: attempt}); stopErr != nil {
+					return stopErr
+				}
+			}
+			return nil
+		}
+
+		select {
+		case <-ctx.Done():
+			return ctx.Err()
+		case <-time.After(retryDelay << (attempt - 1)):
+		}
+		pending = failed
+	}
+	return nil
+}
+
+// Count returns how many records failed for a reason
+func (r *Report) Count(kind Kind) int {
+	n := 0
+	for _, f := range r.Failures {
+		if f.Kind == kind {
+			n++
+		}
+	}
+	return n
+}
+
+// Summary lists the outcome and each failed record, one per line
+func (r *Report) Summary() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%d sent", r.Sent)
+	if r.Retried > 0 {
+		fmt.Fprintf(&b, " (%d after retrying)", r.Retried)
+	}
+	fmt.Fprintf(&b, ", %d failed validation, %d failed at the broker (policy %s)", r.Count(Validation), r.Count(Broker), r.Policy)
+	if r.Stopped {
+		b.WriteString(", stopped at the first failure")
+	}
+	b.WriteString("\n")
+
+	for _, f := range r.Failures {
+		line := fmt.Sprintf("  %-10s %s: %v", f.Kind, f.Record, f.Err)
+		if f.Attempts > 1 {
+			line += fmt.Sprintf(" (%d attempts)", f.Attempts)
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
@@ -0,0 +1,90 @@
+package bookmark
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+)
+
+// Store holds the last offset viewed in the consumer, keyed by profile,
+// topic and partition
+type Store struct {
+	path     string
+	Profiles map[string]map[string]map[int]int64
+}
+
+// GetStorePath returns the path to the offset bookmarks file
+func GetStorePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "bookmarks.yaml")
+	}
+	return filepath.Join(home, ".config", "avrocado", "bookmarks.yaml")
+}
+
+// LoadStore reads the bookmarks file. A missing file yields an empty store.
+func LoadStore(path string) (*Store, error) {
+	store := &Store{path: path, Profiles: make(map[string]map[string]map[int]int64)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("reading bookmarks file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &store.Profiles); err != nil {
+		return nil, fmt.Errorf("parsing bookmarks file: %w", err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]map[string]map[int]int64)
+	}
+
+	return store, nil
+}
+
+// Get returns the last offset viewed on a topic partition, if any
+func (s *Store) Get(profile, topic string, partition int) (int64, bool) {
+	offset, ok := s.Profiles[profile][topic][partition]
+	return offset, ok
+}
+
+// Set records the last offset viewed on a topic partition and writes the
+// file. Unchanged bookmarks are not rewritten.
+func (s *Store) Set(profile, topic string, partition int, offset int64) error {
+	if current, ok := s.Get(profile, topic, partition); ok && current == offset {
+		return nil
+	}
+
+	if s.Profiles[profile] == nil {
+		s.Profiles[profile] = make(map[string]map[int]int64)
+	}
+	if s.Profiles[profile][topic] == nil {
+		s.Profiles[profile][topic] = make(map[int]int64)
+	}
+	s.Profiles[profile][topic][partition] = offset
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s.Profiles)
+	if err != nil {
+		return fmt.Errorf("marshaling bookmarks: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("writing bookmarks file: %w", err)
+	}
+
+	return nil
+}
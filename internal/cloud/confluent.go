@@ -0,0 +1,234 @@
+package cloud
+
+import (
+	"encoding/json"
+	"fmt"
+	"io"
+	"net/http"
+	"net/url"
+	"sort"
+	"strings"
+	"time"
+)
+
+// DefaultBaseURL is the Confluent Cloud management API
+const DefaultBaseURL = "https://api.confluent.cloud"
+
+// Client queries the Confluent Cloud management API with a Cloud API key
+// (not a cluster-scoped key)
+type Client struct {
+	baseURL    string
+	httpClient *http.Client
+	apiKey     string
+	apiSecret  string
+}
+
+// Environment is a Confluent Cloud environment
+type Environment struct {
+	ID   string
+	Name string
+}
+
+// KafkaCluster is a Kafka cluster in an environment
+type KafkaCluster struct {
+	ID               string
+	Name             string
+	Provider         string
+	Region           string
+	BootstrapServers string
+}
+
+// Target is a Kafka cluster together with its environment's schema registry,
+// everything needed to prefill a profile
+type Target struct {
+	Environment       Environment
+	Cluster           KafkaCluster
+	SchemaRegistryURL string
+}
+
+// Label describes the target for pickers
+func (t Target) Label() string {
+	return fmt.Sprintf("%s / %s (%s %s)", t.Environment.Name, t.Cluster.Name, t.Cluster.Provider, t.Cluster.Region)
+}
+
+// NewClient creates a client for the Confluent Cloud API
+func NewClient(apiKey, apiSecret string) *Client {
+	return &Client{
+		baseURL:    DefaultBaseURL,
+		httpClient: &http.Client{Timeout: 30 * time.Second},
+		apiKey:     apiKey,
+		apiSecret:  apiSecret,
+	}
+}
+
+// listResponse is the paginated envelope used by Confluent Cloud list endpoints
+type listResponse struct {
+	Data     []json.RawMessage `json:"data"`
+	Metadata struct {
+		Next string `json:"next"`
+	} `json:"metadata"`
+}
+
+// list fetches every page of a list endpoint
+func (c *Client) list(path string, query url.Values) ([]json.RawMessage, error) {
+	next := c.baseURL + path
+	if len(query) > 0 {
+		next += "?" + query.Encode()
+	}
+
+	var items []json.RawMessage
+	for next != "" {
+		req, err := http.NewRequest(http.MethodGet, next, nil)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+		req.Header.Set("Accept", "application/json")
+		req.SetBasicAuth(c.apiKey, c.apiSecret)
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("executing request: %w", err)
+		}
+		body, err := io.ReadAll(resp.Body)
+		resp.Body.Close()
+		if err != nil {
+			return nil, fmt.Errorf("reading response: %w", err)
+		}
+
+		if resp.StatusCode != http.StatusOK {
+			return nil, fmt.Errorf("confluent cloud API error (status %d): %s", resp.StatusCode, string(body))
+		}
+
+		var page listResponse
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("parsing response: %w", err)
+		}
+		items = append(items, page.Data...)
+		next = page.Metadata.Next
+	}
+
+	return items, nil
+}
+
+// ListEnvironments returns the environments visible to the API key
+func (c *Client) ListEnvironments() ([]Environment, error) {
+	items, err := c.list("/org/v2/environments", nil)
+	if err != nil {
+		return nil, fmt.Errorf("listing environments: %w", err)
+	}
+
+	envs := make([]Environment, 0, len(items))
+	for _, item := range items {
+		var env struct {
+			ID          string `json:"id"`
+			DisplayName string `json:"display_name"`
+		}
+		if err := json.Unmarshal(item, &env); err != nil {
+			return nil, fmt.Errorf("parsing environment: %w", err)
+		}
+		envs = append(envs, Environment{ID: env.ID, Name: env.DisplayName})
+	}
+
+	return envs, nil
+}
+
+// ListKafkaClusters returns the Kafka clusters in an environment
+func (c *Client) ListKafkaClusters(envID string) ([]KafkaCluster, error) {
+	items, err := c.list("/cmk/v2/clusters", url.Values{"environment": {envID}})
+	if err != nil {
+		return nil, fmt.Errorf("listing kafka clusters: %w", err)
+	}
+
+	clusters := make([]KafkaCluster, 0, len(items))
+	for _, item := range items {
+		var cluster struct {
+			ID   string `json:"id"`
+			Spec struct {
+				DisplayName       string `json:"display_name"`
+				Cloud             string `json:"cloud"`
+				Region            string `json:"region"`
+				BootstrapEndpoint string `json:"kafka_bootstrap_endpoint"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(item, &cluster); err != nil {
+			return nil, fmt.Errorf("parsing kafka cluster: %w", err)
+		}
+		clusters = append(clusters, KafkaCluster{
+			ID:               cluster.ID,
+			Name:             cluster.Spec.DisplayName,
+			Provider:         cluster.Spec.Cloud,
+			Region:           cluster.Spec.Region,
+			BootstrapServers: stripScheme(cluster.Spec.BootstrapEndpoint),
+		})
+	}
+
+	return clusters, nil
+}
+
+// SchemaRegistryURL returns the schema registry endpoint of an environment,
+// or "" when schema registry is not enabled there
+func (c *Client) SchemaRegistryURL(envID string) (string, error) {
+	items, err := c.list("/srcm/v3/clusters", url.Values{"environment": {envID}})
+	if err != nil {
+		return "", fmt.Errorf("listing schema registries: %w", err)
+	}
+
+	for _, item := range items {
+		var sr struct {
+			Spec struct {
+				HTTPEndpoint string `json:"http_endpoint"`
+			} `json:"spec"`
+		}
+		if err := json.Unmarshal(item, &sr); err != nil {
+			return "", fmt.Errorf("parsing schema registry: %w", err)
+		}
+		if sr.Spec.HTTPEndpoint != "" {
+			return sr.Spec.HTTPEndpoint, nil
+		}
+	}
+
+	return "", nil
+}
+
+// Discover lists every Kafka cluster across all environments, paired with
+// the environment's schema registry, sorted by environment and cluster name
+func (c *Client) Discover() ([]Target, error) {
+	envs, err := c.ListEnvironments()
+	if err != nil {
+		return nil, err
+	}
+
+	var targets []Target
+	for _, env := range envs {
+		clusters, err := c.ListKafkaClusters(env.ID)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %w", env.Name, err)
+		}
+		if len(clusters) == 0 {
+			continue
+		}
+
+		srURL, err := c.SchemaRegistryURL(env.ID)
+		if err != nil {
+			return nil, fmt.Errorf("environment %s: %w", env.Name, err)
+		}
+
+		for _, cluster := range clusters {
+			targets = append(targets, Target{Environment: env, Cluster: cluster, SchemaRegistryURL: srURL})
+		}
+	}
+
+	sort.Slice(targets, func(i, j int) bool {
+		return targets[i].Label() < targets[j].Label()
+	})
+
+	return targets, nil
+}
+
+// stripScheme turns "SASL_SSL://host:9092" into "host:9092"
+func stripScheme(endpoint string) string {
+	if i := strings.Index(endpoint, "://"); i >= 0 {
+		return endpoint[i+3:]
+	}
+	return endpoint
+}
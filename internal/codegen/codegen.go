@@ -0,0 +1,301 @@
+// Package codegen generates types for an Avro subject in Go, Java and
+// Python, with a small example that produces and consumes them in the
+// registry's wire format, so a new consumer can start from working code.
+package codegen
+
+import (
+	"encoding/json"
+	"fmt"
+	"sort"
+	"strings"
+	"unicode"
+)
+
+// Languages lists the supported target languages
+var Languages = []string{"go", "java", "python"}
+
+// Target is what the generated example is wired to
+type Target struct {
+	Subject          string
+	Topic            string
+	SchemaID         int
+	BootstrapServers string
+	RegistryURL      string
+}
+
+// File is a generated source file, its path relative to the output
+// directory
+type File struct {
+	Path    string
+	Content string
+}
+
+// Generate returns the types for a record schema and an example that
+// produces and consumes them, in lang. pkg names the Go package, Java
+// package or Python module of the types; empty derives it from the schema.
+func Generate(lang, schemaJSON, pkg string, target Target) ([]File, error) {
+	s, err := parseSchema(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	switch lang {
+	case "go":
+		return generateGo(s, pkg, target)
+	case "java":
+		return generateJava(s, pkg, target)
+	case "python", "py":
+		return generatePython(s, pkg, target)
+	default:
+		return nil, fmt.Errorf("unsupported language %q (want %s)", lang, strings.Join(Languages, ", "))
+	}
+}
+
+// avroType is a field type: a primitive, a reference to a named type, or a
+// union, array or map of types
+type avroType struct {
+	Kind     string      // Primitive type name, "named", "union", "array" or "map"
+	Logical  string      // Logical type, e.g. timestamp-millis
+	Named    *namedType  // For "named"
+	Items    *avroType   // Array items or map values
+	Branches []*avroType // Union branches
+}
+
+// namedType is a record, enum or fixed definition
+type namedType struct {
+	Kind      string // record, enum or fixed
+	Name      string
+	Namespace string
+	Doc       string
+	Fields    []field  // Records
+	Symbols   []string // Enums
+	Size      int      // Fixed
+}
+
+type field struct {
+	Name string
+	Doc  string
+	Type *avroType
+}
+
+// FullName returns the type's namespace-qualified name
+func (n *namedType) FullName() string {
+	if n.Namespace == "" {
+		return n.Name
+	}
+	return n.Namespace + "." + n.Name
+}
+
+// schema is a parsed record schema and every named type it defines, in
+// definition order
+type schema struct {
+	Root  *namedType
+	Types []*namedType
+	JSON  string // The schema, compacted
+}
+
+// nullable returns the non-null branch of a ["null", T] union
+func (t *avroType) nullable() (*avroType, bool) {
+	if t.Kind != "union" || len(t.Branches) != 2 {
+		return nil, false
+	}
+	if t.Branches[0].Kind == "null" {
+		return t.Branches[1], true
+	}
+	if t.Branches[1].Kind == "null" {
+		return t.Branches[0], true
+	}
+	return nil, false
+}
+
+type parser struct {
+	named map[string]*namedType
+	order []*namedType
+}
+
+func parseSchema(schemaJSON string) (*schema, error) {
+	var raw interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &raw); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	p := &parser{named: make(map[string]*namedType)}
+	root, err := p.parse(raw, "")
+	if err != nil {
+		return nil, err
+	}
+	if root.Kind != "named" || root.Named.Kind != "record" {
+		return nil, fmt.Errorf("schema is not a record")
+	}
+
+	s := &schema{Root: root.Named, Types: p.order, JSON: schemaJSON}
+	if data, err := json.Marshal(raw); err == nil {
+		s.JSON = string(data)
+	}
+
+	// Types are generated by their short names
+	seen := make(map[string]string)
+	for _, t := range s.Types {
+		if other, ok := seen[t.Name]; ok {
+			return nil, fmt.Errorf("types %s and %s share a name", other, t.FullName())
+		}
+		seen[t.Name] = t.FullName()
+	}
+	return s, nil
+}
+
+func (p *parser) parse(raw interface{}, namespace string) (*avroType, error) {
+	switch v := raw.(type) {
+	case string:
+		switch v {
+		case "null", "boolean", "int", "long", "float", "double", "bytes", "string":
+			return &avroType{Kind: v}, nil
+		}
+		name := v
+		if !strings.Contains(name, ".") && namespace != "" {
+			name = namespace + "." + name
+		}
+		if named, ok := p.named[name]; ok {
+			return &avroType{Kind: "named", Named: named}, nil
+		}
+		if named, ok := p.named[v]; ok {
+			return &avroType{Kind: "named", Named: named}, nil
+		}
+		return nil, fmt.Errorf("unknown type %q", v)
+
+	case []interface{}:
+		union := &avroType{Kind: "union"}
+		for _, branch := range v {
+			t, err := p.parse(branch, namespace)
+			if err != nil {
+				return nil, err
+			}
+			union.Branches = append(union.Branches, t)
+		}
+		return union, nil
+
+	case map[string]interface{}:
+		typeName, _ := v["type"].(string)
+		logical, _ := v["logicalType"].(string)
+		switch typeName {
+		case "record", "error", "enum", "fixed":
+			return p.parseNamed(v, typeName, namespace)
+		case "array":
+			items, err := p.parse(v["items"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroType{Kind: "array", Items: items}, nil
+		case "map":
+			values, err := p.parse(v["values"], namespace)
+			if err != nil {
+				return nil, err
+			}
+			return &avroType{Kind: "map", Items: values}, nil
+		}
+		t, err := p.parse(v["type"], namespace)
+		if err != nil {
+			return nil, err
+		}
+		if t.Kind != "named" && t.Kind != "union" {
+			t.Logical = logical
+		}
+		return t, nil
+	}
+	return nil, fmt.Errorf("unexpected schema %v", raw)
+}
+
+func (p *parser) parseNamed(v map[string]interface{}, kind, namespace string) (*avroType, error) {
+	name, _ := v["name"].(string)
+	if name == "" {
+		return nil, fmt.Errorf("%s without a name", kind)
+	}
+	if ns, ok := v["namespace"].(string); ok {
+		namespace = ns
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		namespace, name = name[:i], name[i+1:]
+	}
+	if kind == "error" {
+		kind = "record"
+	}
+
+	named := &namedType{Kind: kind, Name: name, Namespace: namespace}
+	named.Doc, _ = v["doc"].(string)
+	p.named[named.FullName()] = named
+	p.order = append(p.order, named)
+
+	switch kind {
+	case "record":
+		fields, _ := v["fields"].([]interface{})
+		for _, raw := range fields {
+			f, ok := raw.(map[string]interface{})
+			if !ok {
+				continue
+			}
+			t, err := p.parse(f["type"], namespace)
+			if err != nil {
+				return nil, fmt.Errorf("%s.%v: %w", name, f["name"], err)
+			}
+			entry := field{Type: t}
+			entry.Name, _ = f["name"].(string)
+			entry.Doc, _ = f["doc"].(string)
+			named.Fields = append(named.Fields, entry)
+		}
+	case "enum":
+		for _, s := range v["symbols"].([]interface{}) {
+			if symbol, ok := s.(string); ok {
+				named.Symbols = append(named.Symbols, symbol)
+			}
+		}
+	case "fixed":
+		size, _ := v["size"].(float64)
+		named.Size = int(size)
+	}
+	return &avroType{Kind: "named", Named: named}, nil
+}
+
+// camelCase joins the words of a name with each capitalized:
+// order_id -> OrderId
+func camelCase(name string) string {
+	var b strings.Builder
+	upper := true
+	for _, r := range name {
+		if r == '_' || r == '-' || r == '.' {
+			upper = true
+			continue
+		}
+		if upper {
+			r = unicode.ToUpper(r)
+			upper = false
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
+
+// identifier reduces text to letters, digits and underscores, lowercased
+func identifier(text string) string {
+	var b strings.Builder
+	for _, r := range strings.ToLower(text) {
+		if unicode.IsLetter(r) || unicode.IsDigit(r) {
+			b.WriteRune(r)
+		} else if b.Len() > 0 && !strings.HasSuffix(b.String(), "_") {
+			b.WriteByte('_')
+		}
+	}
+	return strings.Trim(b.String(), "_")
+}
+
+// docLines splits a doc string into comment lines
+func docLines(doc string) []string {
+	doc = strings.TrimSpace(doc)
+	if doc == "" {
+		return nil
+	}
+	return strings.Split(doc, "\n")
+}
+
+// sortedFiles orders files by path
+func sortedFiles(files []File) []File {
+	sort.Slice(files, func(i, j int) bool { return files[i].Path < files[j].Path })
+	return files
+}
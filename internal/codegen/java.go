@@ -0,0 +1,218 @@
+package codegen
+
+import (
+	"fmt"
+	"path"
+	"strings"
+)
+
+// javaKeywords can't be field names; such fields get a trailing underscore
+// and an @AvroName
+var javaKeywords = map[string]bool{
+	"abstract": true, "assert": true, "boolean": true, "break": true, "byte": true, "case": true,
+	"catch": true, "char": true, "class": true, "const": true, "continue": true, "default": true,
+	"do": true, "double": true, "else": true, "enum": true, "extends": true, "final": true,
+	"finally": true, "float": true, "for": true, "goto": true, "if": true, "implements": true,
+	"import": true, "instanceof": true, "int": true, "interface": true, "long": true, "native": true,
+	"new": true, "package": true, "private": true, "protected": true, "public": true, "return": true,
+	"short": true, "static": true, "strictfp": true, "super": true, "switch": true, "synchronized": true,
+	"this": true, "throw": true, "throws": true, "transient": true, "try": true, "void": true,
+	"volatile": true, "while": true, "true": true, "false": true, "null": true,
+}
+
+// generateJava writes plain classes for Avro reflection and an example that
+// produces and consumes them with the registry's serializers. Reflection
+// finds classes by the schema's full names, so each class lives in its
+// type's namespace.
+func generateJava(s *schema, pkg string, target Target) ([]File, error) {
+	if pkg != "" && pkg != s.Root.Namespace {
+		return nil, fmt.Errorf("java classes must be in the schema's namespace %q for Avro reflection to find them", s.Root.Namespace)
+	}
+
+	var files []File
+	for _, t := range s.Types {
+		if t.Kind == "fixed" {
+			continue // Fields of fixed types are GenericData.Fixed
+		}
+		files = append(files, File{Path: javaPath(t.Namespace, t.Name), Content: javaType(t)})
+	}
+
+	example := s.Root.Name + "Example"
+	files = append(files, File{
+		Path: javaPath(s.Root.Namespace, example),
+		Content: javaPackage(s.Root.Namespace) + fmt.Sprintf(javaExample, target.Subject, s.Root.Name, example,
+			target.Topic, target.BootstrapServers, target.RegistryURL),
+	})
+	return sortedFiles(files), nil
+}
+
+func javaPath(namespace, name string) string {
+	return path.Join(strings.ReplaceAll(namespace, ".", "/"), name+".java")
+}
+
+func javaPackage(namespace string) string {
+	header := "// Generated by avrocado codegen.\n\n"
+	if namespace == "" {
+		return header
+	}
+	return header + "package " + namespace + ";\n\n"
+}
+
+// javaDoc renders a doc string as a Javadoc comment
+func javaDoc(b *strings.Builder, doc, indent string) {
+	lines := docLines(doc)
+	if len(lines) == 0 {
+		return
+	}
+	fmt.Fprintf(b, "%s/**\n", indent)
+	for _, line := range lines {
+		fmt.Fprintf(b, "%s * %s\n", indent, strings.ReplaceAll(line, "*/", "* /"))
+	}
+	fmt.Fprintf(b, "%s */\n", indent)
+}
+
+func javaType(t *namedType) string {
+	var b strings.Builder
+	b.WriteString(javaPackage(t.Namespace))
+	javaDoc(&b, t.Doc, "")
+
+	switch t.Kind {
+	case "record":
+		fmt.Fprintf(&b, "public class %s {\n", t.Name)
+		for i, f := range t.Fields {
+			if i > 0 {
+				b.WriteString("\n")
+			}
+			javaDoc(&b, f.Doc, "    ")
+			fieldType, nullable := javaFieldType(t.Namespace, f.Type)
+			if nullable {
+				b.WriteString("    @org.apache.avro.reflect.Nullable\n")
+			}
+			name := f.Name
+			if javaKeywords[name] {
+				fmt.Fprintf(&b, "    @org.apache.avro.reflect.AvroName(%q)\n", name)
+				name += "_"
+			}
+			fmt.Fprintf(&b, "    public %s %s;\n", fieldType, name)
+		}
+		b.WriteString("}\n")
+	case "enum":
+		fmt.Fprintf(&b, "public enum %s {\n    %s\n}\n", t.Name, strings.Join(t.Symbols, ",\n    "))
+	}
+	return b.String()
+}
+
+// javaFieldType returns the Java type of a field, and whether it is a
+// nullable union
+func javaFieldType(namespace string, t *avroType) (string, bool) {
+	if inner, ok := t.nullable(); ok {
+		return javaTypeName(namespace, inner, true), true
+	}
+	return javaTypeName(namespace, t, false), false
+}
+
+func javaTypeName(namespace string, t *avroType, boxed bool) string {
+	primitives := map[string][2]string{
+		"boolean": {"boolean", "Boolean"},
+		"int":     {"int", "Integer"},
+		"long":    {"long", "Long"},
+		"float":   {"float", "Float"},
+		"double":  {"double", "Double"},
+	}
+	if names, ok := primitives[t.Kind]; ok {
+		if boxed {
+			return names[1]
+		}
+		return names[0]
+	}
+
+	switch t.Kind {
+	case "bytes":
+		return "java.nio.ByteBuffer"
+	case "string":
+		return "String"
+	case "named":
+		if t.Named.Kind == "fixed" {
+			return "org.apache.avro.generic.GenericData.Fixed"
+		}
+		if t.Named.Namespace == namespace {
+			return t.Named.Name
+		}
+		return t.Named.FullName()
+	case "array":
+		return "java.util.List<" + javaTypeName(namespace, t.Items, true) + ">"
+	case "map":
+		return "java.util.Map<String, " + javaTypeName(namespace, t.Items, true) + ">"
+	}
+	return "Object"
+}
+
+// javaExample is the example class: subject, root type, class name, topic,
+// bootstrap servers and registry URL
+const javaExample = `import java.time.Duration;
+import java.util.List;
+import java.util.Properties;
+
+import io.confluent.kafka.serializers.KafkaAvroDeserializer;
+import io.confluent.kafka.serializers.KafkaAvroSerializer;
+import org.apache.kafka.clients.consumer.ConsumerRecord;
+import org.apache.kafka.clients.consumer.KafkaConsumer;
+import org.apache.kafka.clients.producer.KafkaProducer;
+import org.apache.kafka.clients.producer.ProducerRecord;
+import org.apache.kafka.common.serialization.StringDeserializer;
+import org.apache.kafka.common.serialization.StringSerializer;
+
+/**
+ * Produces and consumes %[2]s on the topic of %[1]s, encoded by the
+ * registry's Avro serializers through reflection.
+ */
+public class %[3]s {
+    static final String TOPIC = %[4]q;
+    static final String BOOTSTRAP_SERVERS = %[5]q;
+    static final String SCHEMA_REGISTRY_URL = %[6]q;
+
+    public static void produce(String key, %[2]s value) {
+        Properties props = new Properties();
+        props.put("bootstrap.servers", BOOTSTRAP_SERVERS);
+        props.put("key.serializer", StringSerializer.class.getName());
+        props.put("value.serializer", KafkaAvroSerializer.class.getName());
+        props.put("schema.registry.url", SCHEMA_REGISTRY_URL);
+        props.put("schema.reflection", "true");
+        // Encode with the registered schema instead of registering one
+        // derived from the class
+        props.put("auto.register.schemas", "false");
+        props.put("use.latest.version", "true");
+
+        try (KafkaProducer<String, %[2]s> producer = new KafkaProducer<>(props)) {
+            producer.send(new ProducerRecord<>(TOPIC, key, value));
+        }
+    }
+
+    public static void consume(String group) {
+        Properties props = new Properties();
+        props.put("bootstrap.servers", BOOTSTRAP_SERVERS);
+        props.put("group.id", group);
+        props.put("auto.offset.reset", "earliest");
+        props.put("key.deserializer", StringDeserializer.class.getName());
+        props.put("value.deserializer", KafkaAvroDeserializer.class.getName());
+        props.put("schema.registry.url", SCHEMA_REGISTRY_URL);
+        props.put("schema.reflection", "true");
+
+        try (KafkaConsumer<String, %[2]s> consumer = new KafkaConsumer<>(props)) {
+            consumer.subscribe(List.of(TOPIC));
+            while (true) {
+                for (ConsumerRecord<String, %[2]s> record : consumer.poll(Duration.ofSeconds(1))) {
+                    System.out.printf("%%s@%%d: %%s%%n", record.key(), record.offset(), record.value());
+                }
+            }
+        }
+    }
+
+    public static void main(String[] args) {
+        %[2]s value = new %[2]s();
+        // Set value's fields here: required fields can't be left null
+        produce("example", value);
+        consume("avrocado-example");
+    }
+}
+`
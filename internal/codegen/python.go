@@ -0,0 +1,264 @@
+package codegen
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+)
+
+// pythonKeywords can't be attribute names; such fields get a trailing
+// underscore
+var pythonKeywords = map[string]bool{
+	"False": true, "None": true, "True": true, "and": true, "as": true, "assert": true, "async": true,
+	"await": true, "break": true, "class": true, "continue": true, "def": true, "del": true, "elif": true,
+	"else": true, "except": true, "finally": true, "for": true, "from": true, "global": true, "if": true,
+	"import": true, "in": true, "is": true, "lambda": true, "nonlocal": true, "not": true, "or": true,
+	"pass": true, "raise": true, "return": true, "try": true, "while": true, "with": true, "yield": true,
+}
+
+// generatePython writes dataclasses that convert to and from the dicts
+// fastavro works with, and an example using confluent-kafka's serializers
+func generatePython(s *schema, module string, target Target) ([]File, error) {
+	if module == "" {
+		module = identifier(s.Root.Name)
+	}
+	p := &pythonWriter{imports: make(map[string]bool), typing: map[string]bool{"Any": true, "Dict": true}}
+	var body strings.Builder
+	for _, t := range s.Types {
+		p.writeType(&body, t)
+	}
+
+	var types strings.Builder
+	fmt.Fprintf(&types, "# Generated by avrocado codegen from %s. Do not edit.\n\n", target.Subject)
+	types.WriteString("from __future__ import annotations\n\n")
+	if p.imports["datetime"] {
+		types.WriteString("import datetime\n")
+	}
+	if p.imports["decimal"] {
+		types.WriteString("import decimal\n")
+	}
+	types.WriteString("from dataclasses import dataclass\n")
+	var typing []string
+	for name := range p.typing {
+		typing = append(typing, name)
+	}
+	sort.Strings(typing)
+	fmt.Fprintf(&types, "from typing import %s\n", strings.Join(typing, ", "))
+	types.WriteString(body.String())
+
+	example := fmt.Sprintf(pythonExample, target.Subject, s.Root.Name, module, target.Topic, target.Subject,
+		target.BootstrapServers, target.RegistryURL)
+	return []File{
+		{Path: module + ".py", Content: types.String()},
+		{Path: "example.py", Content: example},
+	}, nil
+}
+
+type pythonWriter struct {
+	imports map[string]bool
+	typing  map[string]bool
+}
+
+func (p *pythonWriter) writeType(b *strings.Builder, t *namedType) {
+	b.WriteString("\n\n")
+	switch t.Kind {
+	case "record":
+		b.WriteString("@dataclass\n")
+		fmt.Fprintf(b, "class %s:\n", t.Name)
+		if doc := strings.TrimSpace(t.Doc); doc != "" {
+			fmt.Fprintf(b, "    %q\n\n", doc)
+		}
+		for _, f := range t.Fields {
+			for _, line := range docLines(f.Doc) {
+				fmt.Fprintf(b, "    # %s\n", line)
+			}
+			fmt.Fprintf(b, "    %s: %s\n", pythonName(f.Name), p.pythonType(f.Type))
+		}
+		if len(t.Fields) == 0 {
+			b.WriteString("    pass\n")
+		}
+
+		b.WriteString("\n    @classmethod\n")
+		fmt.Fprintf(b, "    def from_dict(cls, d: Dict[str, Any]) -> %s:\n        return cls(\n", t.Name)
+		for _, f := range t.Fields {
+			fmt.Fprintf(b, "            %s=%s,\n", pythonName(f.Name), fromDict(fmt.Sprintf("d[%q]", f.Name), f.Type, 0))
+		}
+		b.WriteString("        )\n")
+
+		b.WriteString("\n    def to_dict(self) -> Dict[str, Any]:\n        return {\n")
+		for _, f := range t.Fields {
+			fmt.Fprintf(b, "            %q: %s,\n", f.Name, toDict("self."+pythonName(f.Name), f.Type, 0))
+		}
+		b.WriteString("        }\n")
+
+	case "enum":
+		p.typing["Literal"] = true
+		quoted := make([]string, len(t.Symbols))
+		for i, symbol := range t.Symbols {
+			quoted[i] = fmt.Sprintf("%q", symbol)
+		}
+		for _, line := range docLines(t.Doc) {
+			fmt.Fprintf(b, "# %s\n", line)
+		}
+		fmt.Fprintf(b, "%s = Literal[%s]\n", t.Name, strings.Join(quoted, ", "))
+
+	case "fixed":
+		fmt.Fprintf(b, "# %d bytes\n%s = bytes\n", t.Size, t.Name)
+	}
+}
+
+func pythonName(name string) string {
+	if pythonKeywords[name] {
+		return name + "_"
+	}
+	return name
+}
+
+func (p *pythonWriter) pythonType(t *avroType) string {
+	switch t.Kind {
+	case "boolean":
+		return "bool"
+	case "int", "long":
+		switch t.Logical {
+		case "date":
+			p.imports["datetime"] = true
+			return "datetime.date"
+		case "timestamp-millis", "timestamp-micros":
+			p.imports["datetime"] = true
+			return "datetime.datetime"
+		}
+		return "int"
+	case "float", "double":
+		return "float"
+	case "bytes":
+		if t.Logical == "decimal" {
+			p.imports["decimal"] = true
+			return "decimal.Decimal"
+		}
+		return "bytes"
+	case "string":
+		return "str"
+	case "named":
+		return t.Named.Name
+	case "array":
+		p.typing["List"] = true
+		return "List[" + p.pythonType(t.Items) + "]"
+	case "map":
+		return "Dict[str, " + p.pythonType(t.Items) + "]"
+	case "union":
+		if inner, ok := t.nullable(); ok {
+			p.typing["Optional"] = true
+			return "Optional[" + p.pythonType(inner) + "]"
+		}
+	}
+	return "Any"
+}
+
+// hasRecord reports whether values of t contain records, which convert to
+// and from dicts
+func hasRecord(t *avroType) bool {
+	switch t.Kind {
+	case "named":
+		return t.Named.Kind == "record"
+	case "array", "map":
+		return hasRecord(t.Items)
+	case "union":
+		if inner, ok := t.nullable(); ok {
+			return hasRecord(inner)
+		}
+	}
+	return false
+}
+
+// fromDict returns the expression converting a fastavro value to t
+func fromDict(expr string, t *avroType, depth int) string {
+	return convertPython(expr, t, depth, func(expr, name string) string {
+		return name + ".from_dict(" + expr + ")"
+	})
+}
+
+// toDict returns the expression converting a value of t for fastavro
+func toDict(expr string, t *avroType, depth int) string {
+	return convertPython(expr, t, depth, func(expr, _ string) string {
+		return expr + ".to_dict()"
+	})
+}
+
+func convertPython(expr string, t *avroType, depth int, record func(expr, name string) string) string {
+	if !hasRecord(t) {
+		return expr
+	}
+	x, k := fmt.Sprintf("x%d", depth), fmt.Sprintf("k%d", depth)
+	switch t.Kind {
+	case "named":
+		return record(expr, t.Named.Name)
+	case "array":
+		return fmt.Sprintf("[%s for %s in %s]", convertPython(x, t.Items, depth+1, record), x, expr)
+	case "map":
+		return fmt.Sprintf("{%s: %s for %s, %s in %s.items()}", k, convertPython(x, t.Items, depth+1, record), k, x, expr)
+	case "union":
+		inner, _ := t.nullable()
+		return fmt.Sprintf("(None if %s is None else %s)", expr, convertPython(expr, inner, depth, record))
+	}
+	return expr
+}
+
+// pythonExample is the example script: subject, root type, module, topic,
+// subject, bootstrap servers and registry URL
+const pythonExample = `# Generated by avrocado codegen from %[1]s. Edit freely.
+"""Produces and consumes %[2]s with confluent-kafka's Avro serializers."""
+
+from confluent_kafka import DeserializingConsumer, SerializingProducer
+from confluent_kafka.schema_registry import SchemaRegistryClient
+from confluent_kafka.schema_registry.avro import AvroDeserializer, AvroSerializer
+from confluent_kafka.serialization import StringDeserializer, StringSerializer
+
+from %[3]s import %[2]s
+
+TOPIC = %[4]q
+SUBJECT = %[5]q
+BOOTSTRAP_SERVERS = %[6]q
+SCHEMA_REGISTRY_URL = %[7]q
+
+registry = SchemaRegistryClient({"url": SCHEMA_REGISTRY_URL})
+schema_str = registry.get_latest_version(SUBJECT).schema.schema_str
+
+
+def produce(key: str, value: %[2]s) -> None:
+    producer = SerializingProducer({
+        "bootstrap.servers": BOOTSTRAP_SERVERS,
+        "key.serializer": StringSerializer(),
+        "value.serializer": AvroSerializer(
+            registry, schema_str, lambda obj, ctx: obj.to_dict(), {"auto.register.schemas": False}
+        ),
+    })
+    producer.produce(TOPIC, key=key, value=value)
+    producer.flush()
+
+
+def consume(group: str) -> None:
+    consumer = DeserializingConsumer({
+        "bootstrap.servers": BOOTSTRAP_SERVERS,
+        "group.id": group,
+        "auto.offset.reset": "earliest",
+        "key.deserializer": StringDeserializer(),
+        "value.deserializer": AvroDeserializer(registry, schema_str, lambda d, ctx: %[2]s.from_dict(d)),
+    })
+    consumer.subscribe([TOPIC])
+    try:
+        while True:
+            msg = consumer.poll(1.0)
+            if msg is None:
+                continue
+            if msg.error():
+                raise RuntimeError(msg.error())
+            print(f"{msg.key()}@{msg.offset()}: {msg.value()}")
+    finally:
+        consumer.close()
+
+
+if __name__ == "__main__":
+    # Build a %[2]s with every required field set, then:
+    # produce("example", value)
+    consume("avrocado-example")
+`
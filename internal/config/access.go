@@ -0,0 +1,67 @@
+package config
+
+import (
+	"fmt"
+	"path"
+)
+
+// SubjectAccess limits the subjects avrocado lists and produces to, so a
+// shared config can lock a team to its own subjects. Patterns match whole
+// subjects, with * for any run of characters.
+type SubjectAccess struct {
+	Allow []string `yaml:"allow,omitempty"` // Only these subjects, if any are listed
+	Deny  []string `yaml:"deny,omitempty"`  // Never these, even if allowed
+}
+
+// Allows reports whether a subject passes the lists
+func (a *SubjectAccess) Allows(subject string) bool {
+	if a == nil {
+		return true
+	}
+	if matchesAny(a.Deny, subject) {
+		return false
+	}
+	return len(a.Allow) == 0 || matchesAny(a.Allow, subject)
+}
+
+// validate checks the patterns are well formed
+func (a *SubjectAccess) validate() error {
+	if a == nil {
+		return nil
+	}
+	for _, pattern := range append(append([]string{}, a.Allow...), a.Deny...) {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return fmt.Errorf("invalid subject pattern %q", pattern)
+		}
+	}
+	return nil
+}
+
+func matchesAny(patterns []string, subject string) bool {
+	for _, pattern := range patterns {
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// SubjectAllowed reports whether the profile and the project both allow a
+// subject
+func (c *Config) SubjectAllowed(subject string) bool {
+	if !c.SubjectAccess.Allows(subject) {
+		return false
+	}
+	return c.Project == nil || c.Project.SubjectAccess.Allows(subject)
+}
+
+// CheckSubject returns an error naming what denies a subject, or nil
+func (c *Config) CheckSubject(subject string) error {
+	if !c.SubjectAccess.Allows(subject) {
+		return fmt.Errorf("subject %s is not allowed by profile %s", subject, c.Profile)
+	}
+	if c.Project != nil && !c.Project.SubjectAccess.Allows(subject) {
+		return fmt.Errorf("subject %s is not allowed by %s", subject, c.Project.Path)
+	}
+	return nil
+}
@@ -1,60 +1,353 @@
 package config
 
 import (
+	"context"
 	"errors"
 	"fmt"
+	"net"
 	"os"
 	"path/filepath"
+	"sort"
 	"strings"
+	"time"
 
 	"gopkg.in/yaml.v3"
 )
 
 // Legacy Config struct for backward compatibility and internal usage
 type Config struct {
+	// Profile is the name of the profile this config was built from (empty in env mode)
+	Profile string
+
 	// Schema Registry
 	RegistryURL string
 	APIKey      string
 	APISecret   string
+	RegistryTLS *TLSConfig // CA and client certificate for https registries, nil for the defaults
+
+	// Registry API: confluent (default), apicurio for Apicurio's
+	// Confluent-compatible API, apicurio-v2 for its native API, or local for
+	// .avsc files in LocalSchemaDir without a registry
+	RegistryFlavor string
+	RegistryGroup  string // Apicurio artifact group subjects live in, for apicurio-v2
+	RegistryIDType string // What wire-format IDs are in apicurio-v2: global (default) or content
+	LocalSchemaDir string // Directory searched for .avsc files, for local
+	LocalSchemaID  int    // ID local schemas are given, and framed into produced values
+	Framing        string // How produced values are framed: wire (default) or none for bare Avro
 
 	// Kafka
 	KafkaBootstrapServers string
 	KafkaSASLUsername     string
 	KafkaSASLPassword     string
 	KafkaSecurityProtocol string
+	KafkaSASLMechanism    string          // "PLAIN" (default), "SCRAM-SHA-256", "SCRAM-SHA-512" or "GSSAPI"
+	KafkaKerberos         *KerberosConfig // Required for GSSAPI
+	KafkaTLS              *TLSConfig      // CA and client certificate for SSL and SASL_SSL, nil for the defaults
+
+	// Production profiles get a persistent warning banner in the UI
+	Production bool
+	BannerText string
+
+	// SSH bastion to reach the registry and brokers through, if any
+	SSHTunnel *SSHTunnelConfig
+
+	// Avro library used to encode and decode: "goavro" (default) or "hamba"
+	AvroBackend string
+
+	// Payload template generation settings (from the config file)
+	Template TemplateConfig
+
+	// Consumer reader schemas for contract tests, by consumer name
+	Contracts map[string]string
+
+	// Reply topics for request/reply services, by request topic
+	RequestReply map[string]RequestReplyConfig
+
+	// Consumer table view columns, by topic
+	TableColumns map[string]string
+
+	// Commands and webhooks run after actions such as producing a message
+	Hooks []HookConfig
+
+	// External serializer plugins for proprietary envelopes, by name
+	Serializers map[string]SerializerConfig
+
+	// Key management services for field-level encryption, by KMS type
+	KMS map[string]KMSConfig
+
+	// Fields masked in the message viewer and exports
+	Mask MaskConfig
+
+	// Where replayed messages get a fresh idempotency key, by topic
+	Idempotency map[string]IdempotencyConfig
+
+	// Data quality assertions on sampled messages, by topic
+	Quality map[string][]QualityRule
+
+	// Subjects the profile may list and produce to, nil for all
+	SubjectAccess *SubjectAccess
+
+	// Convention subjects registered in the profile must follow, nil for none
+	NamingPolicy *NamingPolicy
+
+	// Topics of subjects not named after their topic, by subject
+	Topics map[string]string
+
+	// Full names of topics' key records, by topic, which name their key
+	// subjects under the record and topic_record strategies
+	KeyRecords map[string]string
+
+	// How subjects are named after topics: topic (default), record or
+	// topic_record. A project's strategy takes precedence.
+	SubjectNamingStrategy string
+
+	// Repo-local scope from .avrocado.yaml, nil outside a project
+	Project *ProjectConfig
+
+	// DialContext, when set, is used for all registry and broker connections
+	// (for example to route them through an SSH tunnel)
+	DialContext func(ctx context.Context, network, address string) (net.Conn, error)
 }
 
 // ConfigFile represents the YAML configuration file structure
 type ConfigFile struct {
-	Default        string                     `yaml:"default"`
-	Configurations map[string]*ProfileConfig `yaml:"configurations"`
+	Default        string                        `yaml:"default"`
+	RestoreSession bool                          `yaml:"restore_session,omitempty"` // Reopen the last profile, subject and layout on launch
+	Configurations map[string]*ProfileConfig     `yaml:"configurations"`
+	Template       TemplateConfig                `yaml:"template,omitempty"`
+	Contracts      map[string]string             `yaml:"contracts,omitempty"`     // Consumer name -> reader schema (.avsc path or subject[@version])
+	RequestReply   map[string]RequestReplyConfig `yaml:"request_reply,omitempty"` // Request topic -> where its replies arrive
+	TableColumns   map[string]string             `yaml:"table_columns,omitempty"` // Topic -> consumer table columns, e.g. "offset, key, $.status"
+	Hooks          []HookConfig                  `yaml:"hooks,omitempty"`
+	Serializers    map[string]SerializerConfig   `yaml:"serializers,omitempty"`
+	KMS            map[string]KMSConfig          `yaml:"kms,omitempty"` // KMS type (as in the KEK, e.g. aws-kms) -> how to reach it
+	Mask           MaskConfig                    `yaml:"mask,omitempty"`
+	Idempotency    map[string]IdempotencyConfig  `yaml:"idempotency,omitempty"` // Topic (or prefix ending in *) -> where its idempotency key lives
+	Quality        map[string][]QualityRule      `yaml:"quality,omitempty"`     // Topic (or prefix ending in *) -> data quality assertions
+}
+
+// IdempotencyConfig names the payload field and/or header holding a
+// message's idempotency key, which replays can refresh
+type IdempotencyConfig struct {
+	Field  string `yaml:"field,omitempty"`  // Dotted path in the payload, e.g. metadata.event_id
+	Header string `yaml:"header,omitempty"` // Header name, e.g. idempotency-key
+}
+
+// IdempotencyFor returns where a topic's idempotency key lives
+func (c *Config) IdempotencyFor(topic string) (IdempotencyConfig, bool) {
+	if rule, ok := c.Idempotency[topic]; ok {
+		return rule, true
+	}
+	patterns := make([]string, 0, len(c.Idempotency))
+	for pattern := range c.Idempotency {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(topic, strings.TrimSuffix(pattern, "*")) {
+			return c.Idempotency[pattern], true
+		}
+	}
+	return IdempotencyConfig{}, false
+}
+
+// MaskConfig selects fields whose values are hidden when messages are
+// displayed or exported, unless explicitly unmasked
+type MaskConfig struct {
+	Fields []string `yaml:"fields,omitempty"` // Field names, * wildcards allowed (email, *_ssn)
+	Tags   []string `yaml:"tags,omitempty"`   // Schema confluent:tags, e.g. PII
+}
+
+// KMSConfig says how to use a key management service to encrypt and
+// decrypt data encryption keys
+type KMSConfig struct {
+	Secret  string `yaml:"secret,omitempty"`  // For local-kms: secret the key encryption key is derived from
+	Command string `yaml:"command,omitempty"` // Helper program that encrypts or decrypts with the KMS
+}
+
+// SerializerConfig names an external program that unwraps consumed
+// messages and wraps produced ones, for envelopes such as encryption or
+// compression that sit around the Schema Registry wire format
+type SerializerConfig struct {
+	Command string   `yaml:"command"`
+	Topics  []string `yaml:"topics"`         // Topic names, or prefixes ending in *
+	Keys    bool     `yaml:"keys,omitempty"` // Also pass message keys through the plugin
+}
+
+// SerializerFor returns the name and settings of the serializer plugin
+// configured for a topic
+func (c *Config) SerializerFor(topic string) (string, SerializerConfig, bool) {
+	names := make([]string, 0, len(c.Serializers))
+	for name := range c.Serializers {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+
+	for _, name := range names {
+		s := c.Serializers[name]
+		for _, pattern := range s.Topics {
+			if pattern == topic || (strings.HasSuffix(pattern, "*") && strings.HasPrefix(topic, strings.TrimSuffix(pattern, "*"))) {
+				return name, s, true
+			}
+		}
+	}
+	return "", SerializerConfig{}, false
+}
+
+// HookConfig runs a shell command or POSTs to a URL when an event fires.
+// Either way the hook receives the event as JSON (on stdin for commands).
+type HookConfig struct {
+	Events  []string          `yaml:"events"`            // Event names, or "*" for all
+	Command string            `yaml:"command,omitempty"` // Run with sh -c
+	URL     string            `yaml:"url,omitempty"`
+	Headers map[string]string `yaml:"headers,omitempty"` // Extra HTTP headers, e.g. Authorization
+	Timeout time.Duration     `yaml:"timeout,omitempty"` // Defaults to DefaultHookTimeout
+}
+
+// DefaultHookTimeout bounds how long a hook may run
+const DefaultHookTimeout = 10 * time.Second
+
+// RequestReplyConfig describes how a command/response service replies to
+// requests produced to a topic
+type RequestReplyConfig struct {
+	ReplyTopic        string        `yaml:"reply_topic"`
+	CorrelationHeader string        `yaml:"correlation_header,omitempty"` // Defaults to DefaultCorrelationHeader
+	ReplyToHeader     string        `yaml:"reply_to_header,omitempty"`    // Header carrying the reply topic, for services that need it
+	Timeout           time.Duration `yaml:"timeout,omitempty"`            // Defaults to DefaultReplyTimeout
+}
+
+// Request/reply defaults
+const (
+	DefaultCorrelationHeader = "correlation-id"
+	DefaultReplyTimeout      = 30 * time.Second
+)
+
+// TemplateConfig controls how payload templates are generated
+type TemplateConfig struct {
+	PopulateCollections bool `yaml:"populate_collections,omitempty"` // One example element in arrays and maps
+	MaxDepth            int  `yaml:"max_depth,omitempty"`            // Nesting limit, 0 uses the default
+	PreferNull          bool `yaml:"prefer_null,omitempty"`          // Null for optional unions
+	MaxRecursion        int  `yaml:"max_recursion,omitempty"`        // Self-nesting allowed for recursive records
+	OmitDefaults        bool `yaml:"omit_defaults,omitempty"`        // Leave out fields with defaults
 }
 
 // ProfileConfig represents a named configuration profile
 type ProfileConfig struct {
-	Name           string                 `yaml:"name"`
-	SchemaRegistry SchemaRegistryConfig   `yaml:"schema_registry"`
-	Kafka          KafkaConfig            `yaml:"kafka"`
+	Name           string               `yaml:"name"`
+	Production     bool                 `yaml:"production,omitempty"` // Show a warning banner when this profile is active
+	Banner         string               `yaml:"banner,omitempty"`     // Banner text, defaults to DefaultBannerText
+	SchemaRegistry SchemaRegistryConfig `yaml:"schema_registry"`
+	Kafka          KafkaConfig          `yaml:"kafka"`
+	SSHTunnel      *SSHTunnelConfig     `yaml:"ssh_tunnel,omitempty"`
+	AvroBackend    string               `yaml:"avro_backend,omitempty"`   // "goavro" (default) or "hamba"
+	SubjectAccess  *SubjectAccess       `yaml:"subject_access,omitempty"` // Subjects this profile may list and produce to
+	NamingPolicy   *NamingPolicy        `yaml:"naming_policy,omitempty"`  // Convention for subjects registered in this profile
+	Topics         map[string]string    `yaml:"topics,omitempty"`         // Subject -> topic, for subjects not named after their topic
+	KeyRecords     map[string]string    `yaml:"key_records,omitempty"`    // Topic -> key record full name, for the record and topic_record strategies
+
+	SubjectNamingStrategy string `yaml:"subject_naming_strategy,omitempty"` // topic (default), record or topic_record
 }
 
+// DefaultBannerText is shown for production profiles without a custom banner
+const DefaultBannerText = "⚠ PROD"
+
 // SchemaRegistryConfig holds Schema Registry settings
 type SchemaRegistryConfig struct {
-	URL              string `yaml:"url"`
-	AuthMethod       string `yaml:"auth_method,omitempty"` // "none", "basic", "sasl"
-	APIKey           string `yaml:"api_key,omitempty"`     // For basic auth
-	APISecret        string `yaml:"api_secret,omitempty"`  // For basic auth
-	SASLUsername     string `yaml:"sasl_username,omitempty"`
-	SASLPassword     string `yaml:"sasl_password,omitempty"`
-	SecurityProtocol string `yaml:"security_protocol,omitempty"` // For SASL connections
+	URL              string     `yaml:"url"`
+	AuthMethod       string     `yaml:"auth_method,omitempty"` // "none", "basic", "sasl"
+	APIKey           string     `yaml:"api_key,omitempty"`     // For basic auth
+	APISecret        string     `yaml:"api_secret,omitempty"`  // For basic auth
+	SASLUsername     string     `yaml:"sasl_username,omitempty"`
+	SASLPassword     string     `yaml:"sasl_password,omitempty"`
+	SecurityProtocol string     `yaml:"security_protocol,omitempty"` // For SASL connections
+	TLS              *TLSConfig `yaml:"tls,omitempty"`               // For https URLs: CA, and client certificate for mutual TLS
+	Flavor           string     `yaml:"flavor,omitempty"`            // confluent (default), apicurio or apicurio-v2
+	Group            string     `yaml:"group,omitempty"`             // Apicurio artifact group, for apicurio-v2 (default "default")
+	IDType           string     `yaml:"id_type,omitempty"`           // Apicurio IDs in messages, for apicurio-v2: global (default) or content
+	Dir              string     `yaml:"dir,omitempty"`               // Directory of .avsc files, for local
+	SchemaID         int        `yaml:"schema_id,omitempty"`         // ID given to local schemas and framed into produced values
+	Framing          string     `yaml:"framing,omitempty"`           // wire (default) or none, to produce bare Avro
+}
+
+// Registry flavors
+const (
+	FlavorConfluent      = "confluent"
+	FlavorApicurio       = "apicurio"
+	FlavorApicurioV2     = "apicurio-v2"
+	FlavorLocal          = "local"
+	IDTypeGlobal         = "global"
+	IDTypeContent        = "content"
+	DefaultApicurioGroup = "default"
+)
+
+// Framings of produced values
+const (
+	FramingWire = "wire" // Magic byte and schema ID before the Avro binary
+	FramingNone = "none" // Bare Avro binary
+)
+
+func (r SchemaRegistryConfig) checkFlavor() error {
+	switch r.Flavor {
+	case "", FlavorConfluent, FlavorApicurio, FlavorApicurioV2:
+	case FlavorLocal:
+		if r.Dir == "" {
+			return fmt.Errorf("flavor local needs a dir of .avsc files")
+		}
+	default:
+		return fmt.Errorf("unknown flavor %q (want confluent, apicurio, apicurio-v2 or local)", r.Flavor)
+	}
+	switch r.Framing {
+	case "", FramingWire, FramingNone:
+	default:
+		return fmt.Errorf("unknown framing %q (want wire or none)", r.Framing)
+	}
+	switch r.IDType {
+	case "", IDTypeGlobal, IDTypeContent:
+	default:
+		return fmt.Errorf("unknown id_type %q (want global or content)", r.IDType)
+	}
+	return nil
 }
 
 // KafkaConfig holds Kafka settings
 type KafkaConfig struct {
-	BootstrapServers string `yaml:"bootstrap_servers"`
-	SecurityProtocol string `yaml:"security_protocol"`
-	SASLMechanism    string `yaml:"sasl_mechanism,omitempty"`
-	SASLUsername     string `yaml:"sasl_username,omitempty"`
-	SASLPassword     string `yaml:"sasl_password,omitempty"`
+	BootstrapServers string          `yaml:"bootstrap_servers"`
+	SecurityProtocol string          `yaml:"security_protocol"`
+	SASLMechanism    string          `yaml:"sasl_mechanism,omitempty"`
+	SASLUsername     string          `yaml:"sasl_username,omitempty"`
+	SASLPassword     string          `yaml:"sasl_password,omitempty"`
+	Kerberos         *KerberosConfig `yaml:"kerberos,omitempty"` // For sasl_mechanism GSSAPI
+	TLS              *TLSConfig      `yaml:"tls,omitempty"`      // For SSL and SASL_SSL: CA, and client certificate for mutual TLS
+}
+
+func checkSASLMechanism(mechanism string) error {
+	switch strings.ToUpper(mechanism) {
+	case "", "PLAIN", "SCRAM-SHA-256", "SCRAM-SHA-512", "GSSAPI":
+		return nil
+	}
+	return fmt.Errorf("unknown sasl_mechanism %q (want PLAIN, SCRAM-SHA-256, SCRAM-SHA-512 or GSSAPI)", mechanism)
+}
+
+// KerberosConfig holds SASL/GSSAPI settings. Credentials come from a keytab
+// when one is set, otherwise from the ticket cache (as populated by kinit).
+type KerberosConfig struct {
+	ServiceName string `yaml:"service_name,omitempty"` // broker principal primary, defaults to "kafka"
+	Realm       string `yaml:"realm,omitempty"`        // required with a keytab
+	Username    string `yaml:"username,omitempty"`     // client principal, required with a keytab
+	Keytab      string `yaml:"keytab,omitempty"`
+	CCache      string `yaml:"ccache,omitempty"`       // defaults to $KRB5CCNAME, then /tmp/krb5cc_<uid>
+	Krb5Conf    string `yaml:"krb5_conf,omitempty"`    // defaults to $KRB5_CONFIG, then /etc/krb5.conf
+	DisableFAST bool   `yaml:"disable_fast,omitempty"` // disable PA-FX-FAST, needed for Active Directory KDCs
+}
+
+// SSHTunnelConfig holds settings for reaching a cluster through an SSH bastion
+type SSHTunnelConfig struct {
+	Host                  string `yaml:"host"`               // host or host:port, port defaults to 22
+	User                  string `yaml:"user,omitempty"`     // defaults to $USER
+	KeyFile               string `yaml:"key_file,omitempty"` // private key, ssh-agent is also tried
+	KeyPassphrase         string `yaml:"key_passphrase,omitempty"`
+	KnownHostsFile        string `yaml:"known_hosts_file,omitempty"`         // defaults to ~/.ssh/known_hosts
+	InsecureIgnoreHostKey bool   `yaml:"insecure_ignore_host_key,omitempty"` // skip host key verification
 }
 
 // Load loads configuration from environment variables (legacy mode)
@@ -83,6 +376,8 @@ func Load() (*Config, error) {
 		KafkaSASLUsername:     kafkaUsername,
 		KafkaSASLPassword:     kafkaPassword,
 		KafkaSecurityProtocol: kafkaProtocol,
+		KafkaSASLMechanism:    os.Getenv("KAFKA_SASL_MECHANISM"),
+		AvroBackend:           os.Getenv("AVROCADO_AVRO_BACKEND"),
 	}, nil
 }
 
@@ -107,6 +402,36 @@ func LoadConfigFile(path string) (*ConfigFile, error) {
 	if err := yaml.Unmarshal(data, &cfg); err != nil {
 		return nil, fmt.Errorf("parsing config file: %w", err)
 	}
+	for name, profile := range cfg.Configurations {
+		if err := profile.SubjectAccess.validate(); err != nil {
+			return nil, fmt.Errorf("profile %s: subject_access: %w", name, err)
+		}
+		if err := profile.NamingPolicy.compile(); err != nil {
+			return nil, fmt.Errorf("profile %s: naming_policy: %w", name, err)
+		}
+		if err := checkNamingStrategy(profile.SubjectNamingStrategy); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		if _, err := profile.SchemaRegistry.TLS.Build(); err != nil {
+			return nil, fmt.Errorf("profile %s: schema_registry.tls: %w", name, err)
+		}
+		if err := profile.SchemaRegistry.checkFlavor(); err != nil {
+			return nil, fmt.Errorf("profile %s: schema_registry: %w", name, err)
+		}
+		if err := checkSASLMechanism(profile.Kafka.SASLMechanism); err != nil {
+			return nil, fmt.Errorf("profile %s: %w", name, err)
+		}
+		if _, err := profile.Kafka.TLS.Build(); err != nil {
+			return nil, fmt.Errorf("profile %s: kafka.tls: %w", name, err)
+		}
+	}
+	for topic, rules := range cfg.Quality {
+		for _, rule := range rules {
+			if err := rule.validate(); err != nil {
+				return nil, fmt.Errorf("quality %s: %w", topic, err)
+			}
+		}
+	}
 
 	return &cfg, nil
 }
@@ -159,16 +484,58 @@ func (cf *ConfigFile) GetProfile(name string) (*ProfileConfig, error) {
 	return nil, fmt.Errorf("profile %q not found", name)
 }
 
+// Config returns the named profile as a Config, with the file's settings
+// shared by every profile
+func (cf *ConfigFile) Config(name string) (*Config, error) {
+	profile, err := cf.GetProfile(name)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg := profile.ToConfig()
+	cfg.Profile = name
+	cfg.Template = cf.Template
+	cfg.Contracts = cf.Contracts
+	cfg.RequestReply = cf.RequestReply
+	cfg.TableColumns = cf.TableColumns
+	cfg.Hooks = cf.Hooks
+	cfg.Serializers = cf.Serializers
+	cfg.KMS = cf.KMS
+	cfg.Mask = cf.Mask
+	cfg.Idempotency = cf.Idempotency
+	cfg.Quality = cf.Quality
+	return cfg, nil
+}
+
 // ToConfig converts a ProfileConfig to a legacy Config struct
 func (pc *ProfileConfig) ToConfig() *Config {
 	return &Config{
 		RegistryURL:           pc.SchemaRegistry.URL,
 		APIKey:                pc.SchemaRegistry.APIKey,
 		APISecret:             pc.SchemaRegistry.APISecret,
+		RegistryTLS:           pc.SchemaRegistry.TLS,
+		RegistryFlavor:        pc.SchemaRegistry.Flavor,
+		RegistryGroup:         pc.SchemaRegistry.Group,
+		RegistryIDType:        pc.SchemaRegistry.IDType,
+		LocalSchemaDir:        pc.SchemaRegistry.Dir,
+		LocalSchemaID:         pc.SchemaRegistry.SchemaID,
+		Framing:               pc.SchemaRegistry.Framing,
 		KafkaBootstrapServers: pc.Kafka.BootstrapServers,
 		KafkaSASLUsername:     pc.Kafka.SASLUsername,
 		KafkaSASLPassword:     pc.Kafka.SASLPassword,
 		KafkaSecurityProtocol: pc.Kafka.SecurityProtocol,
+		KafkaSASLMechanism:    pc.Kafka.SASLMechanism,
+		KafkaKerberos:         pc.Kafka.Kerberos,
+		KafkaTLS:              pc.Kafka.TLS,
+		Production:            pc.Production,
+		BannerText:            pc.Banner,
+		SSHTunnel:             pc.SSHTunnel,
+		AvroBackend:           pc.AvroBackend,
+		SubjectAccess:         pc.SubjectAccess,
+		NamingPolicy:          pc.NamingPolicy,
+		Topics:                pc.Topics,
+		KeyRecords:            pc.KeyRecords,
+		SubjectNamingStrategy: pc.SubjectNamingStrategy,
 	}
 }
 
@@ -180,6 +547,12 @@ func (c *Config) HasKafka() bool {
 	return c.KafkaBootstrapServers != ""
 }
 
+// Unframed reports whether produced keys and values are bare Avro, without
+// the wire format's magic byte and schema ID
+func (c *Config) Unframed() bool {
+	return c.Framing == FramingNone
+}
+
 // SubjectToTopic converts a schema registry subject name to a Kafka topic.
 // It strips the -value or -key suffix if present.
 func SubjectToTopic(subject string) string {
@@ -0,0 +1,96 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// Naming policy modes
+const (
+	NamingBlock = "block" // Violations stop the registration
+	NamingWarn  = "warn"  // Violations are reported, the registration goes ahead
+)
+
+// NamingPolicy is the convention subjects registered in a profile must
+// follow, so governance teams catch violations before they land
+type NamingPolicy struct {
+	Pattern  string   `yaml:"pattern,omitempty"`  // Regular expression the whole subject must match
+	Prefixes []string `yaml:"prefixes,omitempty"` // The subject must start with one of these, if any are listed
+	Mode     string   `yaml:"mode,omitempty"`     // block (default) or warn
+
+	pattern *regexp.Regexp
+}
+
+// compile validates the policy and prepares its pattern
+func (p *NamingPolicy) compile() error {
+	if p == nil {
+		return nil
+	}
+	switch p.Mode {
+	case "", NamingBlock, NamingWarn:
+	default:
+		return fmt.Errorf("unknown mode %q (want block or warn)", p.Mode)
+	}
+	if p.Pattern != "" {
+		re, err := regexp.Compile("^(?:" + p.Pattern + ")$")
+		if err != nil {
+			return fmt.Errorf("invalid pattern: %w", err)
+		}
+		p.pattern = re
+	}
+	return nil
+}
+
+// Violations lists how a subject breaks the policy, none if it follows it
+func (p *NamingPolicy) Violations(subject string) []string {
+	if p == nil {
+		return nil
+	}
+	re := p.pattern
+	if re == nil && p.Pattern != "" {
+		// Not loaded from a config file, so not compiled yet
+		var err error
+		if re, err = regexp.Compile("^(?:" + p.Pattern + ")$"); err != nil {
+			return []string{fmt.Sprintf("invalid pattern: %v", err)}
+		}
+	}
+
+	var violations []string
+	if re != nil && !re.MatchString(subject) {
+		violations = append(violations, fmt.Sprintf("doesn't match %s", p.Pattern))
+	}
+	if len(p.Prefixes) > 0 {
+		prefixed := false
+		for _, prefix := range p.Prefixes {
+			if strings.HasPrefix(subject, prefix) {
+				prefixed = true
+				break
+			}
+		}
+		if !prefixed {
+			violations = append(violations, fmt.Sprintf("doesn't start with %s", strings.Join(p.Prefixes, ", ")))
+		}
+	}
+	return violations
+}
+
+// Warns reports whether violations are only warned about
+func (p *NamingPolicy) Warns() bool {
+	return p != nil && p.Mode == NamingWarn
+}
+
+// CheckSubjectName checks a subject about to be registered against the
+// profile's naming policy. A violation is returned as the error when the
+// policy blocks, and as the warning when it only warns.
+func (c *Config) CheckSubjectName(subject string) (warning string, err error) {
+	violations := c.NamingPolicy.Violations(subject)
+	if len(violations) == 0 {
+		return "", nil
+	}
+	message := fmt.Sprintf("subject %s breaks the naming policy of profile %s: %s", subject, c.Profile, strings.Join(violations, "; "))
+	if c.NamingPolicy.Warns() {
+		return message, nil
+	}
+	return "", fmt.Errorf("%s", message)
+}
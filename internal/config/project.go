@@ -0,0 +1,131 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path"
+	"path/filepath"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// ProjectFileName is the repo-local config file, looked for in the working
+// directory and its parents
+const ProjectFileName = ".avrocado.yaml"
+
+// Subject naming strategies, as in the serializer's subject.name.strategy
+const (
+	TopicNameStrategy       = "topic"        // <topic>-value and <topic>-key
+	RecordNameStrategy      = "record"       // <record full name>
+	TopicRecordNameStrategy = "topic_record" // <topic>-<record full name>
+)
+
+// ProjectConfig scopes avrocado to the subjects and topics a repository
+// works with
+type ProjectConfig struct {
+	Profile               string         `yaml:"profile,omitempty"`                 // Profile used when none is selected
+	Subjects              []string       `yaml:"subjects,omitempty"`                // Subjects of interest, * matches any run of characters
+	Topics                []string       `yaml:"topics,omitempty"`                  // Topics of interest, their subjects follow the naming strategy
+	Fixtures              []string       `yaml:"fixtures,omitempty"`                // Payload fixture directories, relative to the file
+	SubjectNamingStrategy string         `yaml:"subject_naming_strategy,omitempty"` // topic (default), record or topic_record
+	SubjectAccess         *SubjectAccess `yaml:"subject_access,omitempty"`          // Subjects that may be listed and produced to, on top of the profile's
+
+	Path string `yaml:"-"` // Where the file was found
+}
+
+// FindProjectConfig loads the project file in dir or the nearest parent
+// that has one. Returns nil if there is none.
+func FindProjectConfig(dir string) (*ProjectConfig, error) {
+	dir, err := filepath.Abs(dir)
+	if err != nil {
+		return nil, err
+	}
+	for {
+		candidate := filepath.Join(dir, ProjectFileName)
+		if _, err := os.Stat(candidate); err == nil {
+			return LoadProjectConfig(candidate)
+		}
+		parent := filepath.Dir(dir)
+		if parent == dir {
+			return nil, nil
+		}
+		dir = parent
+	}
+}
+
+// LoadProjectConfig loads a project file
+func LoadProjectConfig(file string) (*ProjectConfig, error) {
+	data, err := os.ReadFile(file)
+	if err != nil {
+		return nil, err
+	}
+
+	var project ProjectConfig
+	if err := yaml.Unmarshal(data, &project); err != nil {
+		return nil, fmt.Errorf("parsing %s: %w", file, err)
+	}
+	if err := checkNamingStrategy(project.SubjectNamingStrategy); err != nil {
+		return nil, fmt.Errorf("%s: %w", file, err)
+	}
+	for _, pattern := range project.Subjects {
+		if _, err := path.Match(pattern, ""); err != nil {
+			return nil, fmt.Errorf("%s: invalid subject pattern %q", file, pattern)
+		}
+	}
+	if err := project.SubjectAccess.validate(); err != nil {
+		return nil, fmt.Errorf("%s: subject_access: %w", file, err)
+	}
+	project.Path = file
+
+	return &project, nil
+}
+
+// Scoped reports whether the project narrows the subject list
+func (p *ProjectConfig) Scoped() bool {
+	return p != nil && (len(p.Subjects) > 0 || len(p.Topics) > 0)
+}
+
+// InScope reports whether a subject is one the project works with: it
+// matches a subject pattern, or belongs to a topic under the naming
+// strategy. Topics can't be matched under the record strategy, where
+// subjects don't carry the topic name.
+func (p *ProjectConfig) InScope(subject string) bool {
+	if !p.Scoped() {
+		return true
+	}
+	for _, pattern := range p.Subjects {
+		if ok, _ := path.Match(pattern, subject); ok {
+			return true
+		}
+	}
+	for _, topic := range p.Topics {
+		switch p.SubjectNamingStrategy {
+		case "", TopicNameStrategy:
+			if subject == topic+"-value" || subject == topic+"-key" {
+				return true
+			}
+		case TopicRecordNameStrategy:
+			if strings.HasPrefix(subject, topic+"-") {
+				return true
+			}
+		}
+	}
+	return false
+}
+
+// FixtureDirs returns the fixture directories, resolved against the
+// project file's directory
+func (p *ProjectConfig) FixtureDirs() []string {
+	if p == nil {
+		return nil
+	}
+	dirs := make([]string, len(p.Fixtures))
+	for i, dir := range p.Fixtures {
+		if !filepath.IsAbs(dir) {
+			dir = filepath.Join(filepath.Dir(p.Path), dir)
+		}
+		dirs[i] = dir
+	}
+	return dirs
+}
@@ -0,0 +1,56 @@
+package config
+
+import (
+	"fmt"
+	"regexp"
+	"sort"
+	"strings"
+)
+
+// QualityRule is a data quality assertion on one field of a topic's
+// messages. Every check set on the rule must hold.
+type QualityRule struct {
+	Name    string   `yaml:"name,omitempty"`     // Shown in the report, the field and checks by default
+	Field   string   `yaml:"field"`              // JSONPath into the decoded value, e.g. $.customer.email
+	NotNull bool     `yaml:"not_null,omitempty"` // The field must be present and not null
+	Regex   string   `yaml:"regex,omitempty"`    // String values must match
+	Min     *float64 `yaml:"min,omitempty"`      // Numeric values must be at least this
+	Max     *float64 `yaml:"max,omitempty"`      // Numeric values must be at most this
+}
+
+// validate checks the rule names a field, has a check and a valid regex
+func (r QualityRule) validate() error {
+	if r.Field == "" {
+		return fmt.Errorf("field is required")
+	}
+	if !r.NotNull && r.Regex == "" && r.Min == nil && r.Max == nil {
+		return fmt.Errorf("%s: no checks (not_null, regex, min or max)", r.Field)
+	}
+	if r.Regex != "" {
+		if _, err := regexp.Compile(r.Regex); err != nil {
+			return fmt.Errorf("%s: regex: %w", r.Field, err)
+		}
+	}
+	if r.Min != nil && r.Max != nil && *r.Min > *r.Max {
+		return fmt.Errorf("%s: min is above max", r.Field)
+	}
+	return nil
+}
+
+// QualityRulesFor returns the data quality rules for a topic
+func (c *Config) QualityRulesFor(topic string) []QualityRule {
+	if rules, ok := c.Quality[topic]; ok {
+		return rules
+	}
+	patterns := make([]string, 0, len(c.Quality))
+	for pattern := range c.Quality {
+		patterns = append(patterns, pattern)
+	}
+	sort.Strings(patterns)
+	for _, pattern := range patterns {
+		if strings.HasSuffix(pattern, "*") && strings.HasPrefix(topic, strings.TrimSuffix(pattern, "*")) {
+			return c.Quality[pattern]
+		}
+	}
+	return nil
+}
@@ -0,0 +1,79 @@
+package config
+
+import (
+	"crypto/tls"
+	"crypto/x509"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+)
+
+// TLSConfig holds TLS settings for the registry or the brokers. Each of the
+// CA, certificate and key is a file path or inline PEM.
+type TLSConfig struct {
+	CA   string `yaml:"ca,omitempty"`   // CA certificates to verify the server with, instead of the system roots
+	Cert string `yaml:"cert,omitempty"` // Client certificate, for mutual TLS
+	Key  string `yaml:"key,omitempty"`  // Client certificate's private key
+}
+
+// Build returns the tls.Config for these settings. A nil TLSConfig verifies
+// servers against the system roots without a client certificate.
+func (t *TLSConfig) Build() (*tls.Config, error) {
+	tlsCfg := &tls.Config{MinVersion: tls.VersionTLS12}
+	if t == nil {
+		return tlsCfg, nil
+	}
+
+	if t.CA != "" {
+		ca, err := readPEM(t.CA)
+		if err != nil {
+			return nil, fmt.Errorf("ca: %w", err)
+		}
+		pool := x509.NewCertPool()
+		if !pool.AppendCertsFromPEM(ca) {
+			return nil, fmt.Errorf("ca: no PEM certificates found")
+		}
+		tlsCfg.RootCAs = pool
+	}
+
+	if (t.Cert == "") != (t.Key == "") {
+		return nil, fmt.Errorf("cert and key must be set together")
+	}
+	if t.Cert != "" {
+		cert, err := readPEM(t.Cert)
+		if err != nil {
+			return nil, fmt.Errorf("cert: %w", err)
+		}
+		key, err := readPEM(t.Key)
+		if err != nil {
+			return nil, fmt.Errorf("key: %w", err)
+		}
+		pair, err := tls.X509KeyPair(cert, key)
+		if err != nil {
+			return nil, fmt.Errorf("client certificate: %w", err)
+		}
+		tlsCfg.Certificates = []tls.Certificate{pair}
+	}
+
+	return tlsCfg, nil
+}
+
+// readPEM returns inline PEM as is, or reads it from a file. A leading ~/
+// is the home directory.
+func readPEM(value string) ([]byte, error) {
+	if strings.Contains(value, "-----BEGIN") {
+		return []byte(value), nil
+	}
+	path := value
+	if rest, ok := strings.CutPrefix(path, "~/"); ok {
+		if home, err := os.UserHomeDir(); err == nil {
+			path = filepath.Join(home, rest)
+		}
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", value, err)
+	}
+	return data, nil
+}
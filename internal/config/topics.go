@@ -0,0 +1,166 @@
+package config
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+
+	"gopkg.in/yaml.v3"
+)
+
+// TopicFor returns the topic a subject's messages are produced to and
+// consumed from: the profile's override for the subject, if any, else the
+// topic the naming strategy gives it. It is empty for a subject under the
+// record strategy without an override, as record names don't carry the
+// topic.
+func (c *Config) TopicFor(subject string) string {
+	if topic, ok := c.Topics[subject]; ok {
+		return topic
+	}
+	return c.DefaultTopic(subject)
+}
+
+// DefaultTopic returns the topic the naming strategy gives a subject: the
+// subject without -value or -key under the topic strategy, the subject
+// before its record name under topic_record, and none under record
+func (c *Config) DefaultTopic(subject string) string {
+	switch c.NamingStrategy() {
+	case RecordNameStrategy:
+		return ""
+	case TopicRecordNameStrategy:
+		// Record names can't contain -, so the last one ends the topic
+		if i := strings.LastIndex(subject, "-"); i > 0 {
+			return subject[:i]
+		}
+		return ""
+	}
+	return SubjectToTopic(subject)
+}
+
+// SubjectFor returns the value subject of a record sent to a topic, where
+// subject is the subject whose schema the record was written with, or just
+// its record name: the topic's -value subject under the topic strategy, the
+// topic with the subject's record name under topic_record, and the subject
+// itself under record. It is empty if there is no subject to go on and the
+// strategy needs one.
+func (c *Config) SubjectFor(topic, subject string) string {
+	switch c.NamingStrategy() {
+	case RecordNameStrategy:
+		return subject
+	case TopicRecordNameStrategy:
+		if subject == "" {
+			return ""
+		}
+		// Record names can't contain -, so one ends the subject's topic
+		if i := strings.LastIndex(subject, "-"); i >= 0 {
+			subject = subject[i+1:]
+		}
+		return topic + "-" + subject
+	}
+	return topic + "-value"
+}
+
+// KeySubjectFor returns the key subject of a topic: the topic's -key
+// subject under the topic strategy, else the subject SubjectFor gives the
+// topic's key record (key_records). It is empty under the record strategies
+// for a topic without one.
+func (c *Config) KeySubjectFor(topic string) string {
+	if c.NamingStrategy() == TopicNameStrategy {
+		return topic + "-key"
+	}
+	return c.SubjectFor(topic, c.KeyRecords[topic])
+}
+
+// TopicSubject returns the value subject of a topic's messages: a subject
+// whose topic is overridden to it, else the one the naming strategy gives
+// it. It is empty under the record strategy without an override.
+func (c *Config) TopicSubject(topic string) string {
+	var subjects []string
+	for subject, t := range c.Topics {
+		if t == topic {
+			subjects = append(subjects, subject)
+		}
+	}
+	if len(subjects) > 0 {
+		sort.Strings(subjects)
+		return subjects[0]
+	}
+	return c.SubjectFor(topic, "")
+}
+
+// NamingStrategy returns the subject naming strategy in effect: the
+// project's, else the profile's, else the topic strategy
+func (c *Config) NamingStrategy() string {
+	if c.Project != nil && c.Project.SubjectNamingStrategy != "" {
+		return c.Project.SubjectNamingStrategy
+	}
+	if c.SubjectNamingStrategy != "" {
+		return c.SubjectNamingStrategy
+	}
+	return TopicNameStrategy
+}
+
+// checkNamingStrategy checks a subject_naming_strategy setting
+func checkNamingStrategy(strategy string) error {
+	switch strategy {
+	case "", TopicNameStrategy, RecordNameStrategy, TopicRecordNameStrategy:
+		return nil
+	}
+	return fmt.Errorf("unknown subject_naming_strategy %q (want topic, record or topic_record)", strategy)
+}
+
+// SetTopic overrides the topic of a subject for this session and saves the
+// override to the profile in the config file at path. An empty topic
+// removes the override. Without a profile, as when configured from
+// environment variables, the override lasts only for the session.
+func (c *Config) SetTopic(path, subject, topic string) error {
+	if topic == "" || topic == c.DefaultTopic(subject) {
+		delete(c.Topics, subject)
+		topic = ""
+	} else {
+		if c.Topics == nil {
+			c.Topics = make(map[string]string)
+		}
+		c.Topics[subject] = topic
+	}
+	if c.Profile == "" {
+		return nil
+	}
+
+	configFile, err := LoadConfigFile(path)
+	if err != nil {
+		return fmt.Errorf("loading config file: %w", err)
+	}
+	profile, err := configFile.GetProfile(c.Profile)
+	if err != nil {
+		return err
+	}
+	if topic == "" {
+		delete(profile.Topics, subject)
+	} else {
+		if profile.Topics == nil {
+			profile.Topics = make(map[string]string)
+		}
+		profile.Topics[subject] = topic
+	}
+	return configFile.Save(path)
+}
+
+// Save writes the config file to path
+func (cf *ConfigFile) Save(path string) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(cf)
+	if err != nil {
+		return fmt.Errorf("marshaling config: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing config file: %w", err)
+	}
+	return nil
+}
@@ -0,0 +1,383 @@
+// Package csfle implements Confluent-style client-side field level
+// encryption: schema ENCRYPT rules name tags, fields carrying those tags
+// ("confluent:tags" in the Avro schema) are encrypted with a data
+// encryption key (DEK) from the DEK Registry, and the DEK is itself
+// encrypted by a key encryption key (KEK) held in a KMS.
+package csfle
+
+import (
+	"crypto/cipher"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/binary"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"sync"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// Rule parameters
+const (
+	paramKekName    = "encrypt.kek.name"
+	paramKmsType    = "encrypt.kms.type"
+	paramKmsKeyID   = "encrypt.kms.key.id"
+	paramAlgorithm  = "encrypt.dek.algorithm"
+	paramExpiryDays = "encrypt.dek.expiry.days"
+)
+
+// defaultAlgorithm is the DEK algorithm when a rule doesn't set one
+const defaultAlgorithm = "AES256_GCM"
+
+// Encryptor encrypts and decrypts the tagged fields of decoded messages,
+// caching DEKs and schema rules
+type Encryptor struct {
+	client *registry.Client
+	kms    map[string]config.KMSConfig
+
+	mu      sync.Mutex
+	aeads   map[string]cipher.AEAD           // By KEK, subject, algorithm and DEK version
+	latest  map[string]int                   // Latest DEK version by KEK, subject and algorithm
+	schemas map[int]*registry.SchemaResponse // By schema ID
+}
+
+// New creates an Encryptor that fetches DEKs from the registry and
+// decrypts them with the configured KMSs
+func New(client *registry.Client, kms map[string]config.KMSConfig) *Encryptor {
+	return &Encryptor{
+		client:  client,
+		kms:     kms,
+		aeads:   make(map[string]cipher.AEAD),
+		latest:  make(map[string]int),
+		schemas: make(map[int]*registry.SchemaResponse),
+	}
+}
+
+// EncryptRules returns a rule set's enabled ENCRYPT rules
+func EncryptRules(rs *registry.RuleSet) []registry.Rule {
+	if rs == nil {
+		return nil
+	}
+	var rules []registry.Rule
+	for _, r := range rs.DomainRules {
+		if r.Type == "ENCRYPT" && !r.Disabled {
+			rules = append(rules, r)
+		}
+	}
+	return rules
+}
+
+// Encrypt encrypts the fields of doc tagged by the rules that apply on write.
+// doc is a parsed JSON payload; union values may be wrapped or plain.
+func (e *Encryptor) Encrypt(subject, schemaJSON string, rules []registry.Rule, doc interface{}) (interface{}, error) {
+	return e.apply(subject, schemaJSON, rules, doc, true)
+}
+
+// Decrypt decrypts the fields of doc tagged by the rules that apply on read
+func (e *Encryptor) Decrypt(subject, schemaJSON string, rules []registry.Rule, doc interface{}) (interface{}, error) {
+	return e.apply(subject, schemaJSON, rules, doc, false)
+}
+
+// DecryptByID returns a function that decrypts a document decoded with a
+// schema ID, using that schema's own rules. Suitable for
+// avro.WireDecoder.Transform.
+func (e *Encryptor) DecryptByID(subject string) func(schemaID int, doc interface{}) (interface{}, error) {
+	return func(schemaID int, doc interface{}) (interface{}, error) {
+		schema, err := e.schema(schemaID)
+		if err != nil {
+			return doc, err
+		}
+		rules := EncryptRules(schema.RuleSet)
+		if len(rules) == 0 {
+			return doc, nil
+		}
+		return e.Decrypt(subject, schema.Schema, rules, doc)
+	}
+}
+
+func (e *Encryptor) schema(id int) (*registry.SchemaResponse, error) {
+	e.mu.Lock()
+	schema, ok := e.schemas[id]
+	e.mu.Unlock()
+	if ok {
+		return schema, nil
+	}
+
+	schema, err := e.client.GetSchemaInfoByID(id)
+	if err != nil {
+		return nil, fmt.Errorf("fetching rules for schema %d: %w", id, err)
+	}
+	e.mu.Lock()
+	e.schemas[id] = schema
+	e.mu.Unlock()
+	return schema, nil
+}
+
+func (e *Encryptor) apply(subject, schemaJSON string, rules []registry.Rule, doc interface{}, encrypt bool) (interface{}, error) {
+	var schema interface{}
+	if err := json.Unmarshal([]byte(schemaJSON), &schema); err != nil {
+		return doc, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	for _, rule := range rules {
+		if !appliesOn(rule.Mode, encrypt) {
+			continue
+		}
+		if rule.Params[paramKekName] == "" {
+			return doc, fmt.Errorf("rule %s has no %s", rule.Name, paramKekName)
+		}
+
+		fn := func(s string) (string, error) { return e.encryptField(subject, rule, s) }
+		if !encrypt {
+			fn = func(s string) (string, error) { return e.decryptField(subject, rule, s) }
+		}
+		w := newWalker(rule.Tags, fn)
+		var err error
+		if doc, err = w.walk(schema, doc, ""); err != nil {
+			return doc, fmt.Errorf("rule %s: %w", rule.Name, err)
+		}
+	}
+	return doc, nil
+}
+
+// appliesOn reports whether a rule mode covers writing or reading
+func appliesOn(mode string, write bool) bool {
+	switch mode {
+	case "WRITEREAD", "":
+		return true
+	case "WRITE":
+		return write
+	case "READ":
+		return !write
+	}
+	return false
+}
+
+// encryptField encrypts a string field value and base64-encodes it
+func (e *Encryptor) encryptField(subject string, rule registry.Rule, plaintext string) (string, error) {
+	version, aead, err := e.dek(subject, rule, 0, true)
+	if err != nil {
+		return "", err
+	}
+	ciphertext, err := seal(aead, []byte(plaintext))
+	if err != nil {
+		return "", err
+	}
+	if rotated(rule) {
+		// Versioned ciphertext: magic byte and the DEK version
+		prefix := make([]byte, 5)
+		binary.BigEndian.PutUint32(prefix[1:], uint32(version))
+		ciphertext = append(prefix, ciphertext...)
+	}
+	return base64.StdEncoding.EncodeToString(ciphertext), nil
+}
+
+// decryptField decrypts a base64-encoded string field value
+func (e *Encryptor) decryptField(subject string, rule registry.Rule, value string) (string, error) {
+	ciphertext, err := base64.StdEncoding.DecodeString(value)
+	if err != nil {
+		return "", fmt.Errorf("field is not encrypted (not base64)")
+	}
+	version := 0
+	if rotated(rule) {
+		if len(ciphertext) < 5 || ciphertext[0] != 0 {
+			return "", fmt.Errorf("field has no DEK version prefix")
+		}
+		version = int(binary.BigEndian.Uint32(ciphertext[1:5]))
+		ciphertext = ciphertext[5:]
+	}
+
+	_, aead, err := e.dek(subject, rule, version, false)
+	if err != nil {
+		return "", err
+	}
+	plaintext, err := open(aead, ciphertext)
+	if err != nil {
+		return "", err
+	}
+	return string(plaintext), nil
+}
+
+// rotated reports whether DEKs expire, in which case ciphertexts carry the
+// version of the DEK that encrypted them
+func rotated(rule registry.Rule) bool {
+	days, err := strconv.Atoi(rule.Params[paramExpiryDays])
+	return err == nil && days > 0
+}
+
+// dek returns a version of the subject's DEK (the latest for 0) ready to
+// use. When create is set and the subject has no DEK yet, one is generated
+// and registered.
+func (e *Encryptor) dek(subject string, rule registry.Rule, version int, create bool) (int, cipher.AEAD, error) {
+	kekName := rule.Params[paramKekName]
+	algorithm := rule.Params[paramAlgorithm]
+	if algorithm == "" {
+		algorithm = defaultAlgorithm
+	}
+	keySize, ok := map[string]int{"AES128_GCM": 16, "AES256_GCM": 32}[algorithm]
+	if !ok {
+		return 0, nil, fmt.Errorf("DEK algorithm %s is not supported", algorithm)
+	}
+
+	e.mu.Lock()
+	defer e.mu.Unlock()
+
+	base := kekName + "/" + subject + "/" + algorithm
+	if version == 0 {
+		if v, ok := e.latest[base]; ok {
+			version = v
+		}
+	}
+	if aead, ok := e.aeads[fmt.Sprintf("%s/%d", base, version)]; ok && version > 0 {
+		return version, aead, nil
+	}
+
+	dek, err := e.client.GetDek(kekName, subject, version, algorithm)
+	if registry.IsNotFound(err) && create && version == 0 {
+		dek, err = e.createDek(kekName, subject, algorithm, keySize, rule)
+	}
+	if err != nil {
+		return 0, nil, fmt.Errorf("fetching DEK for %s under KEK %s: %w", subject, kekName, err)
+	}
+
+	key, err := e.keyMaterial(kekName, dek, rule)
+	if err != nil {
+		return 0, nil, err
+	}
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return 0, nil, err
+	}
+
+	e.aeads[fmt.Sprintf("%s/%d", base, dek.Version)] = aead
+	if version == 0 {
+		e.latest[base] = dek.Version
+	}
+	return dek.Version, aead, nil
+}
+
+// keyMaterial returns a DEK's raw AES key, decrypting it with the KEK's KMS
+// unless the registry returned it in the clear (shared KEKs)
+func (e *Encryptor) keyMaterial(kekName string, dek *registry.Dek, rule registry.Rule) ([]byte, error) {
+	var serialized []byte
+	if dek.KeyMaterial != "" {
+		var err error
+		if serialized, err = base64.StdEncoding.DecodeString(dek.KeyMaterial); err != nil {
+			return nil, fmt.Errorf("DEK key material is not base64: %w", err)
+		}
+	} else {
+		kms, keyID, err := e.kekKMS(kekName, rule)
+		if err != nil {
+			return nil, err
+		}
+		encrypted, err := base64.StdEncoding.DecodeString(dek.EncryptedKeyMaterial)
+		if err != nil {
+			return nil, fmt.Errorf("encrypted DEK is not base64: %w", err)
+		}
+		if serialized, err = kms.Decrypt(keyID, encrypted); err != nil {
+			return nil, fmt.Errorf("decrypting DEK with KEK %s: %w", kekName, err)
+		}
+	}
+	return parseAESGCMKey(serialized)
+}
+
+func (e *Encryptor) createDek(kekName, subject, algorithm string, keySize int, rule registry.Rule) (*registry.Dek, error) {
+	key := make([]byte, keySize)
+	if _, err := rand.Read(key); err != nil {
+		return nil, err
+	}
+	kms, keyID, err := e.kekKMS(kekName, rule)
+	if err != nil {
+		return nil, err
+	}
+	encrypted, err := kms.Encrypt(keyID, marshalAESGCMKey(key))
+	if err != nil {
+		return nil, fmt.Errorf("encrypting new DEK with KEK %s: %w", kekName, err)
+	}
+	return e.client.CreateDek(kekName, registry.Dek{
+		Subject:              subject,
+		Algorithm:            algorithm,
+		EncryptedKeyMaterial: base64.StdEncoding.EncodeToString(encrypted),
+	})
+}
+
+// kekKMS finds where a KEK lives: from the DEK Registry, or from the rule's
+// parameters for KEKs that aren't registered
+func (e *Encryptor) kekKMS(kekName string, rule registry.Rule) (KMS, string, error) {
+	kmsType, keyID := rule.Params[paramKmsType], rule.Params[paramKmsKeyID]
+	if kek, err := e.client.GetKek(kekName); err == nil {
+		kmsType, keyID = kek.KmsType, kek.KmsKeyID
+	} else if !registry.IsNotFound(err) || kmsType == "" {
+		return nil, "", fmt.Errorf("fetching KEK %s: %w", kekName, err)
+	}
+
+	kms, err := newKMS(kmsType, e.kms)
+	if err != nil {
+		return nil, "", err
+	}
+	return kms, keyID, nil
+}
+
+// seal encrypts with a random nonce, returning nonce || ciphertext || tag
+func seal(aead cipher.AEAD, plaintext []byte) ([]byte, error) {
+	nonce := make([]byte, aead.NonceSize())
+	if _, err := rand.Read(nonce); err != nil {
+		return nil, err
+	}
+	return aead.Seal(nonce, nonce, plaintext, nil), nil
+}
+
+func open(aead cipher.AEAD, ciphertext []byte) ([]byte, error) {
+	if len(ciphertext) < aead.NonceSize()+aead.Overhead() {
+		return nil, fmt.Errorf("ciphertext too short")
+	}
+	n := aead.NonceSize()
+	plaintext, err := aead.Open(nil, ciphertext[:n], ciphertext[n:], nil)
+	if err != nil {
+		return nil, fmt.Errorf("decrypting: wrong key or corrupted value")
+	}
+	return plaintext, nil
+}
+
+// DEK key material is a serialized Tink AesGcmKey protobuf: field 1 is the
+// key version (varint) and field 3 the key bytes.
+
+func parseAESGCMKey(b []byte) ([]byte, error) {
+	for len(b) > 0 {
+		tag, n := binary.Uvarint(b)
+		if n <= 0 {
+			return nil, fmt.Errorf("invalid DEK key material")
+		}
+		b = b[n:]
+		field, wireType := tag>>3, tag&7
+
+		switch wireType {
+		case 0:
+			if _, n = binary.Uvarint(b); n <= 0 {
+				return nil, fmt.Errorf("invalid DEK key material")
+			}
+			b = b[n:]
+		case 2:
+			length, n := binary.Uvarint(b)
+			if n <= 0 || uint64(len(b)-n) < length {
+				return nil, fmt.Errorf("invalid DEK key material")
+			}
+			value := b[n : n+int(length)]
+			if field == 3 {
+				return value, nil
+			}
+			b = b[n+int(length):]
+		default:
+			return nil, fmt.Errorf("invalid DEK key material")
+		}
+	}
+	return nil, fmt.Errorf("DEK key material has no key")
+}
+
+func marshalAESGCMKey(key []byte) []byte {
+	b := []byte{3<<3 | 2}
+	b = binary.AppendUvarint(b, uint64(len(key)))
+	return append(b, key...)
+}
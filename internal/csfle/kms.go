@@ -0,0 +1,118 @@
+package csfle
+
+import (
+	"bytes"
+	"crypto/aes"
+	"crypto/cipher"
+	"crypto/hkdf"
+	"crypto/sha256"
+	"encoding/base64"
+	"fmt"
+	"os"
+	"os/exec"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// KMS encrypts and decrypts data encryption keys with a key encryption key
+// that never leaves the key management service
+type KMS interface {
+	Encrypt(keyID string, plaintext []byte) ([]byte, error)
+	Decrypt(keyID string, ciphertext []byte) ([]byte, error)
+}
+
+// LocalKMSType is the KMS type of keys derived from a configured secret
+const LocalKMSType = "local-kms"
+
+// newKMS returns the KMS configured for a KMS type
+func newKMS(kmsType string, configs map[string]config.KMSConfig) (KMS, error) {
+	cfg, ok := configs[kmsType]
+	if !ok {
+		return nil, fmt.Errorf("no KMS configured for %s (add it under kms in the config file)", kmsType)
+	}
+	if cfg.Command != "" {
+		return commandKMS{command: cfg.Command, kmsType: kmsType}, nil
+	}
+	if kmsType == LocalKMSType && cfg.Secret != "" {
+		return newLocalKMS(os.ExpandEnv(cfg.Secret))
+	}
+	return nil, fmt.Errorf("KMS %s needs a command (or a secret for %s)", kmsType, LocalKMSType)
+}
+
+// localKMS encrypts with an AES-256-GCM key derived from a secret, for
+// development clusters without a real KMS
+type localKMS struct {
+	aead cipher.AEAD
+}
+
+func newLocalKMS(secret string) (KMS, error) {
+	key, err := hkdf.Key(sha256.New, []byte(secret), nil, "", 32)
+	if err != nil {
+		return nil, fmt.Errorf("deriving local KMS key: %w", err)
+	}
+	aead, err := newAESGCM(key)
+	if err != nil {
+		return nil, err
+	}
+	return localKMS{aead: aead}, nil
+}
+
+func (k localKMS) Encrypt(_ string, plaintext []byte) ([]byte, error) {
+	return seal(k.aead, plaintext)
+}
+
+func (k localKMS) Decrypt(_ string, ciphertext []byte) ([]byte, error) {
+	return open(k.aead, ciphertext)
+}
+
+// commandKMS delegates to a helper program, so any KMS with a CLI can be
+// used. The program gets AVROCADO_KMS_OP (encrypt or decrypt),
+// AVROCADO_KMS_TYPE and AVROCADO_KMS_KEY_ID in its environment and the
+// data base64-encoded on stdin, and prints the result base64-encoded.
+type commandKMS struct {
+	command string
+	kmsType string
+}
+
+func (k commandKMS) Encrypt(keyID string, plaintext []byte) ([]byte, error) {
+	return k.run("encrypt", keyID, plaintext)
+}
+
+func (k commandKMS) Decrypt(keyID string, ciphertext []byte) ([]byte, error) {
+	return k.run("decrypt", keyID, ciphertext)
+}
+
+func (k commandKMS) run(op, keyID string, data []byte) ([]byte, error) {
+	cmd := exec.Command("sh", "-c", k.command)
+	cmd.Env = append(os.Environ(),
+		"AVROCADO_KMS_OP="+op,
+		"AVROCADO_KMS_TYPE="+k.kmsType,
+		"AVROCADO_KMS_KEY_ID="+keyID,
+	)
+	cmd.Stdin = strings.NewReader(base64.StdEncoding.EncodeToString(data))
+	var stderr bytes.Buffer
+	cmd.Stderr = &stderr
+
+	out, err := cmd.Output()
+	if err != nil {
+		return nil, fmt.Errorf("%s KMS helper (%s): %w: %s", k.kmsType, op, err, strings.TrimSpace(stderr.String()))
+	}
+	result, err := base64.StdEncoding.DecodeString(strings.TrimSpace(string(out)))
+	if err != nil {
+		return nil, fmt.Errorf("%s KMS helper (%s): output is not base64: %w", k.kmsType, op, err)
+	}
+	return result, nil
+}
+
+func newAESGCM(key []byte) (cipher.AEAD, error) {
+	block, err := aes.NewCipher(key)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	aead, err := cipher.NewGCM(block)
+	if err != nil {
+		return nil, fmt.Errorf("creating cipher: %w", err)
+	}
+	return aead, nil
+}
@@ -0,0 +1,244 @@
+package csfle
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+)
+
+// walker finds the fields of a decoded document whose schema carries one of
+// a rule's tags and transforms their string values
+type walker struct {
+	tags  map[string]bool
+	fn    func(string) (string, error)
+	named map[string]interface{} // Named types seen so far, by short and full name
+}
+
+func newWalker(tags []string, fn func(string) (string, error)) *walker {
+	w := &walker{tags: make(map[string]bool), fn: fn, named: make(map[string]interface{})}
+	for _, t := range tags {
+		w.tags[t] = true
+	}
+	return w
+}
+
+func (w *walker) walk(schema, value interface{}, namespace string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+
+	switch s := schema.(type) {
+	case string:
+		if named, ok := w.named[s]; ok {
+			return w.walk(named, value, namespace)
+		}
+		if named, ok := w.named[namespace+"."+s]; ok {
+			return w.walk(named, value, namespace)
+		}
+		return value, nil
+
+	case []interface{}:
+		return w.walkUnion(s, value, namespace, w.walk)
+
+	case map[string]interface{}:
+		switch s["type"] {
+		case "record", "error":
+			namespace = w.register(s, namespace)
+			obj, ok := value.(map[string]interface{})
+			if !ok {
+				return value, nil
+			}
+			fields, _ := s["fields"].([]interface{})
+			for _, f := range fields {
+				field, _ := f.(map[string]interface{})
+				name, _ := field["name"].(string)
+				fv, present := obj[name]
+				if !present {
+					continue
+				}
+				var err error
+				if w.tagged(field) {
+					fv, err = w.transform(field["type"], fv, namespace)
+				} else {
+					fv, err = w.walk(field["type"], fv, namespace)
+				}
+				if err != nil {
+					return value, fmt.Errorf("%s: %w", name, err)
+				}
+				obj[name] = fv
+			}
+			return obj, nil
+
+		case "enum", "fixed":
+			w.register(s, namespace)
+			return value, nil
+
+		case "array":
+			items, ok := value.([]interface{})
+			if !ok {
+				return value, nil
+			}
+			for i, item := range items {
+				v, err := w.walk(s["items"], item, namespace)
+				if err != nil {
+					return value, fmt.Errorf("[%d]: %w", i, err)
+				}
+				items[i] = v
+			}
+			return items, nil
+
+		case "map":
+			entries, ok := value.(map[string]interface{})
+			if !ok {
+				return value, nil
+			}
+			for k, item := range entries {
+				v, err := w.walk(s["values"], item, namespace)
+				if err != nil {
+					return value, fmt.Errorf("%s: %w", k, err)
+				}
+				entries[k] = v
+			}
+			return entries, nil
+
+		default:
+			// A primitive written as {"type": "string", ...}
+			return w.walk(s["type"], value, namespace)
+		}
+	}
+	return value, nil
+}
+
+// transform applies fn to a tagged field's string value. Only string
+// fields are encrypted; other types are left as they are.
+func (w *walker) transform(schema, value interface{}, namespace string) (interface{}, error) {
+	if value == nil {
+		return nil, nil
+	}
+	if union, ok := schema.([]interface{}); ok {
+		return w.walkUnion(union, value, namespace, w.transform)
+	}
+	if s, ok := value.(string); ok && typeName(schema) == "string" {
+		return w.fn(s)
+	}
+	return value, nil
+}
+
+// walkUnion handles a union value written either wrapped ({"string": "x"})
+// or plain ("x"), choosing the branch for a plain value by its JSON kind
+func (w *walker) walkUnion(branches []interface{}, value interface{}, namespace string, next func(schema, value interface{}, namespace string) (interface{}, error)) (interface{}, error) {
+	if obj, ok := value.(map[string]interface{}); ok && len(obj) == 1 {
+		for label, inner := range obj {
+			for _, b := range branches {
+				if branchLabel(b, namespace) == label || typeName(b) == label {
+					v, err := next(b, inner, namespace)
+					if err != nil {
+						return value, err
+					}
+					obj[label] = v
+					return obj, nil
+				}
+			}
+		}
+	}
+
+	for _, b := range branches {
+		if kindMatches(w.resolve(b, namespace), value) {
+			return next(b, value, namespace)
+		}
+	}
+	return value, nil
+}
+
+// tagged reports whether a field carries one of the walker's tags
+func (w *walker) tagged(field map[string]interface{}) bool {
+	tags, _ := field["confluent:tags"].([]interface{})
+	for _, t := range tags {
+		if s, ok := t.(string); ok && w.tags[s] {
+			return true
+		}
+	}
+	return false
+}
+
+// register records a named type and returns its namespace
+func (w *walker) register(s map[string]interface{}, namespace string) string {
+	name, _ := s["name"].(string)
+	if ns, ok := s["namespace"].(string); ok {
+		namespace = ns
+	}
+	if i := strings.LastIndex(name, "."); i >= 0 {
+		namespace = name[:i]
+		name = name[i+1:]
+	}
+	w.named[name] = s
+	if namespace != "" {
+		w.named[namespace+"."+name] = s
+	}
+	return namespace
+}
+
+func (w *walker) resolve(schema interface{}, namespace string) interface{} {
+	if s, ok := schema.(string); ok {
+		if named, ok := w.named[s]; ok {
+			return named
+		}
+		if named, ok := w.named[namespace+"."+s]; ok {
+			return named
+		}
+	}
+	return schema
+}
+
+// typeName returns the type of a schema: the primitive name, or "record",
+// "array" etc. for complex types
+func typeName(schema interface{}) string {
+	switch s := schema.(type) {
+	case string:
+		return s
+	case map[string]interface{}:
+		t, _ := s["type"].(string)
+		return t
+	}
+	return ""
+}
+
+// branchLabel returns how a union branch is labelled in wrapped JSON: the
+// type name for primitives and unnamed types, the full name for named ones
+func branchLabel(schema interface{}, namespace string) string {
+	s, ok := schema.(map[string]interface{})
+	if !ok {
+		return typeName(schema)
+	}
+	name, ok := s["name"].(string)
+	if !ok {
+		return typeName(schema)
+	}
+	if ns, ok := s["namespace"].(string); ok {
+		namespace = ns
+	}
+	if namespace != "" && !strings.Contains(name, ".") {
+		return namespace + "." + name
+	}
+	return name
+}
+
+// kindMatches reports whether a plain JSON value could belong to a branch
+func kindMatches(schema, value interface{}) bool {
+	switch value.(type) {
+	case string:
+		t := typeName(schema)
+		return t == "string" || t == "bytes" || t == "enum" || t == "fixed"
+	case float64, json.Number:
+		t := typeName(schema)
+		return t == "int" || t == "long" || t == "float" || t == "double"
+	case bool:
+		return typeName(schema) == "boolean"
+	case []interface{}:
+		return typeName(schema) == "array"
+	case map[string]interface{}:
+		t := typeName(schema)
+		return t == "record" || t == "error" || t == "map"
+	}
+	return false
+}
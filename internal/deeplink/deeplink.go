@@ -0,0 +1,83 @@
+// Package deeplink reads and writes avrocado:// URIs, which open the TUI on
+// a subject or a message, so links in runbooks and incident docs land in the
+// right view.
+package deeplink
+
+import (
+	"fmt"
+	"net/url"
+	"strconv"
+	"strings"
+)
+
+// Scheme is the URI scheme of avrocado links
+const Scheme = "avrocado"
+
+// anyProfile stands for whichever profile avrocado would pick, for links
+// made from the environment configuration
+const anyProfile = "-"
+
+// Link is the context an avrocado:// URI opens: a subject, or a message on
+// a topic
+type Link struct {
+	Profile   string // Empty to use the profile avrocado would pick anyway
+	Subject   string
+	Topic     string
+	Partition int
+	Offset    int64
+}
+
+// Parse reads avrocado://<profile>/<subject> or
+// avrocado://<profile>/<topic>?offset=<n>[&partition=<n>]
+func Parse(uri string) (*Link, error) {
+	u, err := url.Parse(uri)
+	if err != nil {
+		return nil, fmt.Errorf("parsing link: %w", err)
+	}
+	if u.Scheme != Scheme {
+		return nil, fmt.Errorf("link %s: want an %s:// URI", uri, Scheme)
+	}
+	name := strings.Trim(u.Path, "/")
+	if u.Host == "" || name == "" {
+		return nil, fmt.Errorf("link %s: want %s://<profile>/<subject> or %s://<profile>/<topic>?offset=<n>", uri, Scheme, Scheme)
+	}
+
+	link := &Link{Profile: u.Host}
+	if link.Profile == anyProfile {
+		link.Profile = ""
+	}
+	query := u.Query()
+	if !query.Has("offset") {
+		link.Subject = name
+		return link, nil
+	}
+
+	link.Topic = name
+	if link.Offset, err = strconv.ParseInt(query.Get("offset"), 10, 64); err != nil || link.Offset < 0 {
+		return nil, fmt.Errorf("link %s: offset must be a whole number", uri)
+	}
+	if query.Has("partition") {
+		if link.Partition, err = strconv.Atoi(query.Get("partition")); err != nil || link.Partition < 0 {
+			return nil, fmt.Errorf("link %s: partition must be a whole number", uri)
+		}
+	}
+	return link, nil
+}
+
+// SubjectLink returns the link to a subject. An empty profile links to
+// whichever profile is picked on opening.
+func SubjectLink(profile, subject string) string {
+	return fmt.Sprintf("%s://%s/%s", Scheme, linkProfile(profile), url.PathEscape(subject))
+}
+
+// MessageLink returns the link to a message
+func MessageLink(profile, topic string, partition int, offset int64) string {
+	return fmt.Sprintf("%s://%s/%s?partition=%d&offset=%d", Scheme, linkProfile(profile), url.PathEscape(topic), partition, offset)
+}
+
+func linkProfile(profile string) string {
+	if profile == "" {
+		return anyProfile
+	}
+	return profile
+}
@@ -0,0 +1,121 @@
+package deprecation
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// Registry metadata properties that mark a schema as deprecated
+const (
+	PropertyDeprecated  = "deprecated"
+	PropertyReason      = "deprecated.reason"
+	PropertyReplacement = "deprecated.replacement"
+)
+
+// Deprecation describes why a subject is deprecated and what to use instead
+type Deprecation struct {
+	Reason      string `yaml:"reason,omitempty"`
+	Replacement string `yaml:"replacement,omitempty"`
+	Source      string `yaml:"-"` // "local" or "registry"
+}
+
+// Store holds local deprecations keyed by profile, then subject
+type Store struct {
+	path     string
+	Profiles map[string]map[string]Deprecation
+}
+
+// GetStorePath returns the path to the local deprecations file
+func GetStorePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "deprecations.yaml")
+	}
+	return filepath.Join(home, ".config", "avrocado", "deprecations.yaml")
+}
+
+// LoadStore reads the deprecations file. A missing file yields an empty store.
+func LoadStore(path string) (*Store, error) {
+	store := &Store{path: path, Profiles: make(map[string]map[string]Deprecation)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("reading deprecations file: %w", err)
+	}
+
+	if err := yaml.Unmarshal(data, &store.Profiles); err != nil {
+		return nil, fmt.Errorf("parsing deprecations file: %w", err)
+	}
+	if store.Profiles == nil {
+		store.Profiles = make(map[string]map[string]Deprecation)
+	}
+
+	return store, nil
+}
+
+// Get returns the local deprecation for a subject, if any
+func (s *Store) Get(profile, subject string) (Deprecation, bool) {
+	d, ok := s.Profiles[profile][subject]
+	if ok {
+		d.Source = "local"
+	}
+	return d, ok
+}
+
+// Set records or, when d has neither reason nor replacement, clears a
+// subject's deprecation and writes the file
+func (s *Store) Set(profile, subject string, d Deprecation) error {
+	if d.Reason == "" && d.Replacement == "" {
+		delete(s.Profiles[profile], subject)
+		if len(s.Profiles[profile]) == 0 {
+			delete(s.Profiles, profile)
+		}
+	} else {
+		if s.Profiles[profile] == nil {
+			s.Profiles[profile] = make(map[string]Deprecation)
+		}
+		s.Profiles[profile][subject] = d
+	}
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s.Profiles)
+	if err != nil {
+		return fmt.Errorf("marshaling deprecations: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("writing deprecations file: %w", err)
+	}
+
+	return nil
+}
+
+// FromMetadata reads a deprecation from registry schema metadata properties
+func FromMetadata(schema *registry.SchemaResponse) (Deprecation, bool) {
+	if schema == nil || schema.Metadata == nil {
+		return Deprecation{}, false
+	}
+	props := schema.Metadata.Properties
+	if props[PropertyDeprecated] != "true" {
+		return Deprecation{}, false
+	}
+	return Deprecation{
+		Reason:      props[PropertyReason],
+		Replacement: props[PropertyReplacement],
+		Source:      "registry",
+	}, true
+}
@@ -0,0 +1,147 @@
+package diff
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"sort"
+	"strings"
+)
+
+// Kind describes how a value differs between two documents
+type Kind int
+
+const (
+	Added Kind = iota
+	Removed
+	Changed
+)
+
+func (k Kind) String() string {
+	switch k {
+	case Added:
+		return "added"
+	case Removed:
+		return "removed"
+	default:
+		return "changed"
+	}
+}
+
+// Change is a single difference at a JSONPath-style location
+type Change struct {
+	Path string
+	Kind Kind
+	Old  interface{}
+	New  interface{}
+}
+
+// JSON compares two decoded JSON documents and returns the changes needed to
+// turn a into b. Objects are compared key by key and arrays index by index;
+// anything else is compared by value.
+func JSON(a, b interface{}) []Change {
+	var changes []Change
+	compare("$", a, b, &changes)
+	return changes
+}
+
+// JSONStrings decodes two JSON texts and compares them
+func JSONStrings(a, b string) ([]Change, error) {
+	var left, right interface{}
+	if err := json.Unmarshal([]byte(a), &left); err != nil {
+		return nil, fmt.Errorf("parsing left document: %w", err)
+	}
+	if err := json.Unmarshal([]byte(b), &right); err != nil {
+		return nil, fmt.Errorf("parsing right document: %w", err)
+	}
+	return JSON(left, right), nil
+}
+
+func compare(path string, a, b interface{}, changes *[]Change) {
+	switch av := a.(type) {
+	case map[string]interface{}:
+		bv, ok := b.(map[string]interface{})
+		if !ok {
+			break
+		}
+		for _, key := range unionKeys(av, bv) {
+			childPath := childKey(path, key)
+			aChild, inA := av[key]
+			bChild, inB := bv[key]
+			switch {
+			case !inB:
+				*changes = append(*changes, Change{Path: childPath, Kind: Removed, Old: aChild})
+			case !inA:
+				*changes = append(*changes, Change{Path: childPath, Kind: Added, New: bChild})
+			default:
+				compare(childPath, aChild, bChild, changes)
+			}
+		}
+		return
+
+	case []interface{}:
+		bv, ok := b.([]interface{})
+		if !ok {
+			break
+		}
+		for i := 0; i < len(av) || i < len(bv); i++ {
+			childPath := fmt.Sprintf("%s[%d]", path, i)
+			switch {
+			case i >= len(bv):
+				*changes = append(*changes, Change{Path: childPath, Kind: Removed, Old: av[i]})
+			case i >= len(av):
+				*changes = append(*changes, Change{Path: childPath, Kind: Added, New: bv[i]})
+			default:
+				compare(childPath, av[i], bv[i], changes)
+			}
+		}
+		return
+	}
+
+	if !reflect.DeepEqual(a, b) {
+		*changes = append(*changes, Change{Path: path, Kind: Changed, Old: a, New: b})
+	}
+}
+
+func unionKeys(a, b map[string]interface{}) []string {
+	seen := make(map[string]bool, len(a)+len(b))
+	var keys []string
+	for k := range a {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	for k := range b {
+		if !seen[k] {
+			seen[k] = true
+			keys = append(keys, k)
+		}
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+// childKey appends an object key to a path, quoting keys that are not plain identifiers
+func childKey(path, key string) string {
+	if key != "" && strings.IndexFunc(key, func(r rune) bool {
+		return !(r == '_' || r >= 'a' && r <= 'z' || r >= 'A' && r <= 'Z' || r >= '0' && r <= '9')
+	}) < 0 {
+		return path + "." + key
+	}
+	quoted, _ := json.Marshal(key)
+	return path + "[" + string(quoted) + "]"
+}
+
+// FormatValue renders a value as compact JSON, truncated to max characters
+func FormatValue(v interface{}, max int) string {
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprintf("%v", v)
+	}
+	s := string(data)
+	if max > 3 && len(s) > max {
+		s = s[:max-3] + "..."
+	}
+	return s
+}
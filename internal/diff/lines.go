@@ -0,0 +1,222 @@
+package diff
+
+import (
+	"fmt"
+	"strings"
+)
+
+// Op is what happened to a line in a line diff
+type Op int
+
+const (
+	Equal Op = iota
+	Insert
+	Delete
+)
+
+// Line is one line of a line diff, with its line numbers in the old and new
+// text (0 where it isn't in that text)
+type Line struct {
+	Op   Op
+	Text string
+	Old  int
+	New  int
+}
+
+// Lines returns the shortest line diff that turns a into b (Myers'
+// algorithm)
+func Lines(a, b string) []Line {
+	x, y := splitLines(a), splitLines(b)
+
+	// The common prefix and suffix don't need searching
+	prefix := 0
+	for prefix < len(x) && prefix < len(y) && x[prefix] == y[prefix] {
+		prefix++
+	}
+	suffix := 0
+	for suffix < len(x)-prefix && suffix < len(y)-prefix && x[len(x)-1-suffix] == y[len(y)-1-suffix] {
+		suffix++
+	}
+
+	ops := make([]Op, 0, len(x)+len(y))
+	for range prefix {
+		ops = append(ops, Equal)
+	}
+	ops = append(ops, shortestEdit(x[prefix:len(x)-suffix], y[prefix:len(y)-suffix])...)
+	for range suffix {
+		ops = append(ops, Equal)
+	}
+
+	lines := make([]Line, 0, len(ops))
+	i, j := 0, 0
+	for _, op := range ops {
+		switch op {
+		case Equal:
+			lines = append(lines, Line{Op: Equal, Text: x[i], Old: i + 1, New: j + 1})
+			i++
+			j++
+		case Delete:
+			lines = append(lines, Line{Op: Delete, Text: x[i], Old: i + 1})
+			i++
+		case Insert:
+			lines = append(lines, Line{Op: Insert, Text: y[j], New: j + 1})
+			j++
+		}
+	}
+	return lines
+}
+
+// shortestEdit finds the edit script with Myers' greedy algorithm, keeping
+// each round's furthest reaching paths to trace the script back
+func shortestEdit(a, b []string) []Op {
+	n, m := len(a), len(b)
+	limit := n + m
+	if limit == 0 {
+		return nil
+	}
+	offset := limit
+	v := make([]int, 2*limit+2)
+	var trace [][]int
+
+search:
+	for d := 0; d <= limit; d++ {
+		trace = append(trace, append([]int(nil), v...))
+		for k := -d; k <= d; k += 2 {
+			var x int
+			if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+				x = v[offset+k+1] // Down: insert from b
+			} else {
+				x = v[offset+k-1] + 1 // Right: delete from a
+			}
+			y := x - k
+			for x < n && y < m && a[x] == b[y] {
+				x++
+				y++
+			}
+			v[offset+k] = x
+			if x >= n && y >= m {
+				break search
+			}
+		}
+	}
+
+	// Walk back from the end, one edit per round
+	var ops []Op
+	x, y := n, m
+	for d := len(trace) - 1; d >= 0; d-- {
+		v := trace[d]
+		k := x - y
+		prevK := k - 1
+		if k == -d || (k != d && v[offset+k-1] < v[offset+k+1]) {
+			prevK = k + 1
+		}
+		prevX := v[offset+prevK]
+		prevY := prevX - prevK
+		for x > prevX && y > prevY {
+			ops = append(ops, Equal)
+			x--
+			y--
+		}
+		if d > 0 {
+			if x == prevX {
+				ops = append(ops, Insert)
+			} else {
+				ops = append(ops, Delete)
+			}
+		}
+		x, y = prevX, prevY
+	}
+
+	for i, j := 0, len(ops)-1; i < j; i, j = i+1, j-1 {
+		ops[i], ops[j] = ops[j], ops[i]
+	}
+	return ops
+}
+
+func splitLines(s string) []string {
+	if s == "" {
+		return nil
+	}
+	return strings.Split(strings.TrimSuffix(s, "\n"), "\n")
+}
+
+// Hunk is a diff line to show, or a run of unchanged lines left out
+type Hunk struct {
+	Line    Line
+	Skipped int // Unchanged lines left out here, 0 for a shown line
+}
+
+// Hunks keeps the changed lines and context unchanged lines around each,
+// folding longer unchanged runs
+func Hunks(lines []Line, context int) []Hunk {
+	keep := make([]bool, len(lines))
+	for i, l := range lines {
+		if l.Op == Equal {
+			continue
+		}
+		for j := max(i-context, 0); j <= min(i+context, len(lines)-1); j++ {
+			keep[j] = true
+		}
+	}
+
+	var hunks []Hunk
+	for i := 0; i < len(lines); i++ {
+		if keep[i] {
+			hunks = append(hunks, Hunk{Line: lines[i]})
+			continue
+		}
+		start := i
+		for i < len(lines) && !keep[i] {
+			i++
+		}
+		hunks = append(hunks, Hunk{Skipped: i - start})
+		i--
+	}
+	return hunks
+}
+
+// Unified writes a line diff in unified format, for patch tools and reviews
+func Unified(oldName, newName string, lines []Line, context int) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "--- %s\n+++ %s\n", oldName, newName)
+
+	hunks := Hunks(lines, context)
+	for start := 0; start < len(hunks); {
+		if hunks[start].Skipped > 0 {
+			start++
+			continue
+		}
+		end := start
+		for end < len(hunks) && hunks[end].Skipped == 0 {
+			end++
+		}
+
+		oldStart, newStart, oldCount, newCount := 0, 0, 0, 0
+		var body strings.Builder
+		for _, h := range hunks[start:end] {
+			l := h.Line
+			switch l.Op {
+			case Insert:
+				body.WriteString("+" + l.Text + "\n")
+				newCount++
+			case Delete:
+				body.WriteString("-" + l.Text + "\n")
+				oldCount++
+			default:
+				body.WriteString(" " + l.Text + "\n")
+				oldCount++
+				newCount++
+			}
+			if oldStart == 0 && l.Old > 0 {
+				oldStart = l.Old
+			}
+			if newStart == 0 && l.New > 0 {
+				newStart = l.New
+			}
+		}
+		fmt.Fprintf(&b, "@@ -%d,%d +%d,%d @@\n", oldStart, oldCount, newStart, newCount)
+		b.WriteString(body.String())
+		start = end
+	}
+	return b.String()
+}
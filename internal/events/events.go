@@ -113,7 +113,7 @@ func ListEvents(baseDir, topic string) ([]string, error) {
 
 	var files []string
 	for _, entry := range entries {
-		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" {
+		if !entry.IsDir() && filepath.Ext(entry.Name()) == ".json" && !IsOverlay(entry.Name()) {
 			files = append(files, entry.Name())
 		}
 	}
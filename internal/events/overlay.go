@@ -0,0 +1,57 @@
+package events
+
+import (
+	"fmt"
+	"os"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/jsonpatch"
+)
+
+// overlaySuffix marks files that patch an event for one environment. The
+// overlay of order-created.json for the staging profile is
+// order-created.staging.overlay.json, next to it.
+const overlaySuffix = ".overlay.json"
+
+// OverlayPath returns where an event file's overlay for an environment lives
+func OverlayPath(eventPath, env string) string {
+	return strings.TrimSuffix(eventPath, ".json") + "." + env + overlaySuffix
+}
+
+// IsOverlay reports whether a file name is an overlay rather than an event
+func IsOverlay(name string) bool {
+	return strings.HasSuffix(name, overlaySuffix)
+}
+
+// HasOverlay reports whether an event file has an overlay for an environment
+func HasOverlay(eventPath, env string) bool {
+	if env == "" {
+		return false
+	}
+	_, err := os.Stat(OverlayPath(eventPath, env))
+	return err == nil
+}
+
+// ApplyOverlay patches an event's payload with its overlay for an
+// environment, a JSON merge patch (RFC 7386) of the payload. It returns the
+// overlay's path, or "" if the event has no overlay for env.
+func ApplyOverlay(event *Event, eventPath, env string) (string, error) {
+	if env == "" {
+		return "", nil
+	}
+	path := OverlayPath(eventPath, env)
+	patch, err := os.ReadFile(path)
+	if os.IsNotExist(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", fmt.Errorf("reading overlay: %w", err)
+	}
+
+	payload, err := jsonpatch.MergePatch([]byte(event.Payload), patch)
+	if err != nil {
+		return "", fmt.Errorf("applying overlay %s: %w", path, err)
+	}
+	event.Payload = string(payload)
+	return path, nil
+}
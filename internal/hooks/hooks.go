@@ -0,0 +1,119 @@
+// Package hooks runs the commands and webhooks configured to fire after
+// actions, so avrocado can notify a team channel or trigger automation.
+package hooks
+
+import (
+	"bytes"
+	"context"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"io"
+	"net/http"
+	"os"
+	"os/exec"
+	"strings"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// Event names
+const (
+	MessageProduced  = "message.produced"  // A message was sent from send mode or avrocado produce
+	TopicDumped      = "topic.dumped"      // avrocado dump finished
+	TopicReplayed    = "topic.replayed"    // avrocado replay finished
+	TopicPiped       = "topic.piped"       // avrocado pipe finished producing
+	SchemaRegistered = "schema.registered" // A new schema version was registered
+)
+
+// Event is the JSON a hook receives
+type Event struct {
+	Event   string                 `json:"event"`
+	Time    time.Time              `json:"time"`
+	Profile string                 `json:"profile,omitempty"`
+	Data    map[string]interface{} `json:"data"`
+}
+
+// Fire runs every hook configured for the event and waits for them to
+// finish. Returns the failures joined, or nil.
+func Fire(cfg *config.Config, event string, data map[string]interface{}) error {
+	var hooks []config.HookConfig
+	for _, h := range cfg.Hooks {
+		if matches(h, event) {
+			hooks = append(hooks, h)
+		}
+	}
+	if len(hooks) == 0 {
+		return nil
+	}
+
+	body, err := json.Marshal(Event{Event: event, Time: time.Now(), Profile: cfg.Profile, Data: data})
+	if err != nil {
+		return fmt.Errorf("encoding %s event: %w", event, err)
+	}
+
+	errs := make(chan error, len(hooks))
+	for _, h := range hooks {
+		go func(h config.HookConfig) {
+			errs <- run(h, event, body)
+		}(h)
+	}
+
+	var failures []error
+	for range hooks {
+		if err := <-errs; err != nil {
+			failures = append(failures, err)
+		}
+	}
+	return errors.Join(failures...)
+}
+
+func matches(h config.HookConfig, event string) bool {
+	for _, e := range h.Events {
+		if e == "*" || e == event {
+			return true
+		}
+	}
+	return false
+}
+
+func run(h config.HookConfig, event string, body []byte) error {
+	timeout := h.Timeout
+	if timeout <= 0 {
+		timeout = config.DefaultHookTimeout
+	}
+	ctx, cancel := context.WithTimeout(context.Background(), timeout)
+	defer cancel()
+
+	if h.Command != "" {
+		cmd := exec.CommandContext(ctx, "sh", "-c", h.Command)
+		cmd.Stdin = bytes.NewReader(body)
+		cmd.Env = append(os.Environ(), "AVROCADO_EVENT="+event)
+		if out, err := cmd.CombinedOutput(); err != nil {
+			return fmt.Errorf("hook %q: %w: %s", h.Command, err, strings.TrimSpace(string(out)))
+		}
+	}
+
+	if h.URL != "" {
+		req, err := http.NewRequestWithContext(ctx, http.MethodPost, h.URL, bytes.NewReader(body))
+		if err != nil {
+			return fmt.Errorf("hook %s: %w", h.URL, err)
+		}
+		req.Header.Set("Content-Type", "application/json")
+		for k, v := range h.Headers {
+			req.Header.Set(k, os.ExpandEnv(v))
+		}
+		resp, err := http.DefaultClient.Do(req)
+		if err != nil {
+			return fmt.Errorf("hook %s: %w", h.URL, err)
+		}
+		defer resp.Body.Close()
+		if resp.StatusCode >= 300 {
+			msg, _ := io.ReadAll(io.LimitReader(resp.Body, 512))
+			return fmt.Errorf("hook %s: %s: %s", h.URL, resp.Status, strings.TrimSpace(string(msg)))
+		}
+	}
+
+	return nil
+}
@@ -0,0 +1,95 @@
+// Package idempotency gives replayed messages fresh idempotency keys, so
+// consumers that deduplicate on them don't silently drop the replays.
+package idempotency
+
+import (
+	"crypto/rand"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// NewKey returns a random UUID (version 4)
+func NewKey() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
+
+// Inject writes key into the rule's payload field and header. The payload
+// may be plain JSON or have wrapped unions ({"string": "..."}), which keep
+// their wrapping. Headers are copied rather than changed.
+func Inject(rule config.IdempotencyConfig, payload string, headers map[string]string, key string) (string, map[string]string, error) {
+	if rule.Header != "" {
+		copied := make(map[string]string, len(headers)+1)
+		for k, v := range headers {
+			copied[k] = v
+		}
+		copied[rule.Header] = key
+		headers = copied
+	}
+	if rule.Field == "" {
+		return payload, headers, nil
+	}
+
+	var doc interface{}
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	if err := dec.Decode(&doc); err != nil {
+		return "", nil, fmt.Errorf("invalid JSON: %w", err)
+	}
+	if err := setField(doc, strings.Split(rule.Field, "."), key); err != nil {
+		return "", nil, fmt.Errorf("idempotency field %s: %w", rule.Field, err)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", nil, err
+	}
+	return string(out), headers, nil
+}
+
+func setField(doc interface{}, path []string, key string) error {
+	obj, ok := unwrap(doc, path[0]).(map[string]interface{})
+	if !ok {
+		return fmt.Errorf("%s is not inside an object", path[0])
+	}
+	if len(path) > 1 {
+		next, ok := obj[path[0]]
+		if !ok || next == nil {
+			return fmt.Errorf("payload has no %s", path[0])
+		}
+		return setField(next, path[1:], key)
+	}
+
+	// Keep a wrapped union wrapped
+	if wrapped, ok := obj[path[0]].(map[string]interface{}); ok && len(wrapped) == 1 {
+		if _, ok := wrapped["string"]; ok {
+			wrapped["string"] = key
+			return nil
+		}
+	}
+	obj[path[0]] = key
+	return nil
+}
+
+// unwrap looks through a wrapped union record ({"com.acme.Meta": {...}})
+// when it doesn't itself hold the field
+func unwrap(doc interface{}, field string) interface{} {
+	obj, ok := doc.(map[string]interface{})
+	if !ok || len(obj) != 1 {
+		return doc
+	}
+	if _, ok := obj[field]; ok {
+		return doc
+	}
+	for _, inner := range obj {
+		if innerObj, ok := inner.(map[string]interface{}); ok {
+			return innerObj
+		}
+	}
+	return doc
+}
@@ -0,0 +1,40 @@
+package jsonpatch
+
+import "fmt"
+
+// MergePatch applies a JSON merge patch (RFC 7386) to a document: objects
+// in the patch are merged key by key, null removes a key and anything else
+// replaces the value. The result is indented, with the document's keys in
+// their original order and new keys last.
+func MergePatch(doc, patch []byte) ([]byte, error) {
+	target, err := parse(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+	p, err := parse(patch)
+	if err != nil {
+		return nil, fmt.Errorf("parsing merge patch: %w", err)
+	}
+	return format(mergePatch(target, p))
+}
+
+func mergePatch(target, patch interface{}) interface{} {
+	p, ok := patch.(*object)
+	if !ok {
+		return patch
+	}
+	t, ok := target.(*object)
+	if !ok {
+		t = newObject()
+	}
+	for _, key := range p.keys {
+		value := p.values[key]
+		if value == nil {
+			t.remove(key)
+			continue
+		}
+		current, _ := t.get(key)
+		t.set(key, mergePatch(current, value))
+	}
+	return t
+}
@@ -0,0 +1,287 @@
+package jsonpatch
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strconv"
+	"strings"
+)
+
+// operation is one step of a JSON Patch
+type operation struct {
+	Op    string          `json:"op"`
+	Path  string          `json:"path"`
+	From  string          `json:"from"`
+	Value json.RawMessage `json:"value"`
+}
+
+// Apply applies a JSON Patch (RFC 6902): a list of add, remove, replace,
+// move, copy and test operations addressed by JSON Pointers. A failing
+// operation fails the whole patch. The result is indented like MergePatch.
+func Apply(doc, patch []byte) ([]byte, error) {
+	target, err := parse(doc)
+	if err != nil {
+		return nil, fmt.Errorf("parsing document: %w", err)
+	}
+	var ops []operation
+	if err := json.Unmarshal(patch, &ops); err != nil {
+		return nil, fmt.Errorf("parsing JSON Patch: %w", err)
+	}
+
+	for i, op := range ops {
+		if target, err = applyOp(target, op); err != nil {
+			return nil, fmt.Errorf("operation %d (%s %s): %w", i+1, op.Op, op.Path, err)
+		}
+	}
+	return format(target)
+}
+
+// IsJSONPatch reports whether a patch is a JSON Patch (an array of
+// operations) rather than a merge patch
+func IsJSONPatch(patch []byte) bool {
+	return bytes.HasPrefix(bytes.TrimSpace(patch), []byte("["))
+}
+
+func applyOp(doc interface{}, op operation) (interface{}, error) {
+	var value interface{}
+	if op.Op == "add" || op.Op == "replace" || op.Op == "test" {
+		if op.Value == nil {
+			return nil, fmt.Errorf("missing value")
+		}
+		v, err := parse(op.Value)
+		if err != nil {
+			return nil, err
+		}
+		value = v
+	}
+
+	switch op.Op {
+	case "add":
+		return add(doc, op.Path, value)
+	case "remove":
+		doc, _, err := remove(doc, op.Path)
+		return doc, err
+	case "replace":
+		return replace(doc, op.Path, value)
+	case "move":
+		if strings.HasPrefix(op.Path, op.From+"/") {
+			return nil, fmt.Errorf("can't move %s into itself", op.From)
+		}
+		doc, moved, err := remove(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, moved)
+	case "copy":
+		v, err := get(doc, op.From)
+		if err != nil {
+			return nil, err
+		}
+		return add(doc, op.Path, deepCopy(v))
+	case "test":
+		v, err := get(doc, op.Path)
+		if err != nil {
+			return nil, err
+		}
+		if !equal(v, value) {
+			return nil, fmt.Errorf("test failed")
+		}
+		return doc, nil
+	}
+	return nil, fmt.Errorf("unknown op %q", op.Op)
+}
+
+// splitPointer parses a JSON Pointer into unescaped reference tokens
+func splitPointer(pointer string) ([]string, error) {
+	if pointer == "" {
+		return nil, nil
+	}
+	if !strings.HasPrefix(pointer, "/") {
+		return nil, fmt.Errorf("invalid JSON Pointer %q", pointer)
+	}
+	tokens := strings.Split(pointer[1:], "/")
+	for i, t := range tokens {
+		tokens[i] = strings.ReplaceAll(strings.ReplaceAll(t, "~1", "/"), "~0", "~")
+	}
+	return tokens, nil
+}
+
+// arrayIndex parses an array index token; "-" means past the end
+func arrayIndex(token string, length int, allowEnd bool) (int, error) {
+	if token == "-" && allowEnd {
+		return length, nil
+	}
+	i, err := strconv.Atoi(token)
+	if err != nil || i < 0 || (token != "0" && strings.HasPrefix(token, "0")) {
+		return 0, fmt.Errorf("invalid array index %q", token)
+	}
+	if i > length || (i == length && !allowEnd) {
+		return 0, fmt.Errorf("index %d out of range", i)
+	}
+	return i, nil
+}
+
+func get(doc interface{}, pointer string) (interface{}, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, err
+	}
+	for _, t := range tokens {
+		switch v := doc.(type) {
+		case *object:
+			next, ok := v.get(t)
+			if !ok {
+				return nil, fmt.Errorf("%s not found", pointer)
+			}
+			doc = next
+		case []interface{}:
+			i, err := arrayIndex(t, len(v), false)
+			if err != nil {
+				return nil, err
+			}
+			doc = v[i]
+		default:
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+	}
+	return doc, nil
+}
+
+// parentOf returns the container a pointer's last token refers into
+func parentOf(doc interface{}, pointer string) (interface{}, string, error) {
+	tokens, err := splitPointer(pointer)
+	if err != nil {
+		return nil, "", err
+	}
+	last := tokens[len(tokens)-1]
+	parentPointer := pointer[:strings.LastIndex(pointer, "/")]
+	parent, err := get(doc, parentPointer)
+	return parent, last, err
+}
+
+func add(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	if pointer == "" {
+		return value, nil
+	}
+	parent, last, err := parentOf(doc, pointer)
+	if err != nil {
+		return nil, err
+	}
+	switch p := parent.(type) {
+	case *object:
+		p.set(last, value)
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(last, len(p), true)
+		if err != nil {
+			return nil, err
+		}
+		// Slices can't grow in place inside their parent, so swap in a copy
+		grown := append(p[:i:i], append([]interface{}{value}, p[i:]...)...)
+		return replace(doc, pointer[:strings.LastIndex(pointer, "/")], grown)
+	}
+	return nil, fmt.Errorf("parent of %s is not an object or array", pointer)
+}
+
+func remove(doc interface{}, pointer string) (interface{}, interface{}, error) {
+	if pointer == "" {
+		return nil, doc, nil
+	}
+	parent, last, err := parentOf(doc, pointer)
+	if err != nil {
+		return nil, nil, err
+	}
+	switch p := parent.(type) {
+	case *object:
+		v, ok := p.get(last)
+		if !ok {
+			return nil, nil, fmt.Errorf("%s not found", pointer)
+		}
+		p.remove(last)
+		return doc, v, nil
+	case []interface{}:
+		i, err := arrayIndex(last, len(p), false)
+		if err != nil {
+			return nil, nil, err
+		}
+		v := p[i]
+		shrunk := append(p[:i:i], p[i+1:]...)
+		doc, err := replace(doc, pointer[:strings.LastIndex(pointer, "/")], shrunk)
+		return doc, v, err
+	}
+	return nil, nil, fmt.Errorf("%s not found", pointer)
+}
+
+// replace sets an existing value in place, keeping its key's position
+func replace(doc interface{}, pointer string, value interface{}) (interface{}, error) {
+	if pointer == "" {
+		return value, nil
+	}
+	parent, last, err := parentOf(doc, pointer)
+	if err != nil {
+		return nil, err
+	}
+	switch p := parent.(type) {
+	case *object:
+		if _, ok := p.get(last); !ok {
+			return nil, fmt.Errorf("%s not found", pointer)
+		}
+		p.set(last, value)
+		return doc, nil
+	case []interface{}:
+		i, err := arrayIndex(last, len(p), false)
+		if err != nil {
+			return nil, err
+		}
+		p[i] = value
+		return doc, nil
+	}
+	return nil, fmt.Errorf("%s not found", pointer)
+}
+
+func deepCopy(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *object:
+		c := newObject()
+		for _, k := range v.keys {
+			c.set(k, deepCopy(v.values[k]))
+		}
+		return c
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = deepCopy(item)
+		}
+		return c
+	}
+	return v
+}
+
+// equal compares documents, ignoring object key order and number formatting
+func equal(a, b interface{}) bool {
+	return reflect.DeepEqual(plain(a), plain(b))
+}
+
+// plain converts a document to maps and float64s for comparison
+func plain(v interface{}) interface{} {
+	switch v := v.(type) {
+	case *object:
+		m := make(map[string]interface{}, len(v.keys))
+		for _, k := range v.keys {
+			m[k] = plain(v.values[k])
+		}
+		return m
+	case []interface{}:
+		c := make([]interface{}, len(v))
+		for i, item := range v {
+			c[i] = plain(item)
+		}
+		return c
+	case json.Number:
+		f, _ := v.Float64()
+		return f
+	}
+	return v
+}
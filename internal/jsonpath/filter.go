@@ -0,0 +1,109 @@
+package jsonpath
+
+import (
+	"encoding/json"
+	"fmt"
+	"reflect"
+	"strings"
+)
+
+// Filter is a comparison between the value at a path and a JSON literal,
+// such as $.orderId == "X" or $.amount >= 100. A filter with no operator
+// matches when the path exists and is not null.
+type Filter struct {
+	raw   string
+	path  Path
+	op    string
+	value interface{}
+}
+
+// operators in match order, longest first so ">=" isn't read as ">"
+var operators = []string{"==", "!=", ">=", "<=", ">", "<"}
+
+// ParseFilter parses a filter expression. The right-hand side is a JSON
+// literal: strings must be double-quoted.
+func ParseFilter(expr string) (*Filter, error) {
+	f := &Filter{raw: expr}
+
+	pathPart, literal := expr, ""
+	for _, op := range operators {
+		if i := strings.Index(expr, op); i >= 0 {
+			f.op = op
+			pathPart, literal = expr[:i], strings.TrimSpace(expr[i+len(op):])
+			break
+		}
+	}
+
+	path, err := Parse(pathPart)
+	if err != nil {
+		return nil, err
+	}
+	f.path = path
+
+	if f.op == "" {
+		return f, nil
+	}
+	if err := json.Unmarshal([]byte(literal), &f.value); err != nil {
+		return nil, fmt.Errorf("invalid filter %q: %s is not a JSON value (quote strings with \")", expr, literal)
+	}
+	return f, nil
+}
+
+// String returns the filter as written
+func (f *Filter) String() string {
+	return f.raw
+}
+
+// Match reports whether a decoded document satisfies the filter
+func (f *Filter) Match(doc interface{}) bool {
+	actual, ok := f.path.Get(doc)
+	if !ok {
+		return false
+	}
+
+	switch f.op {
+	case "":
+		return actual != nil
+	case "==":
+		return reflect.DeepEqual(actual, f.value)
+	case "!=":
+		return !reflect.DeepEqual(actual, f.value)
+	}
+
+	// Ordering comparisons work on numbers and strings
+	if a, ok := actual.(float64); ok {
+		if b, ok := f.value.(float64); ok {
+			return compare(f.op, a < b, a > b)
+		}
+	}
+	if a, ok := actual.(string); ok {
+		if b, ok := f.value.(string); ok {
+			return compare(f.op, a < b, a > b)
+		}
+	}
+	return false
+}
+
+func compare(op string, less, greater bool) bool {
+	switch op {
+	case ">":
+		return greater
+	case ">=":
+		return !less
+	case "<":
+		return less
+	case "<=":
+		return !greater
+	}
+	return false
+}
+
+// MatchAll reports whether doc satisfies every filter
+func MatchAll(filters []*Filter, doc interface{}) bool {
+	for _, f := range filters {
+		if !f.Match(doc) {
+			return false
+		}
+	}
+	return true
+}
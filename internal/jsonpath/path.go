@@ -0,0 +1,104 @@
+// Package jsonpath evaluates a small JSONPath subset against decoded JSON
+// documents: root-relative paths like $.order.lines[0].sku or
+// $['odd key'], and comparison filters like $.status == "PAID".
+package jsonpath
+
+import (
+	"fmt"
+	"strconv"
+	"strings"
+)
+
+// Path is a parsed path: a sequence of object keys (string) and array
+// indexes (int)
+type Path struct {
+	raw   string
+	steps []interface{}
+}
+
+// Parse parses a path. The leading "$" is optional, so "status" and
+// "$.status" are the same path.
+func Parse(path string) (Path, error) {
+	p := Path{raw: path}
+	s := strings.TrimSpace(path)
+	s = strings.TrimPrefix(s, "$")
+
+	for s != "" {
+		switch {
+		case s[0] == '.':
+			s = s[1:]
+			end := strings.IndexAny(s, ".[")
+			if end < 0 {
+				end = len(s)
+			}
+			if end == 0 {
+				return Path{}, fmt.Errorf("invalid path %q: empty key", path)
+			}
+			p.steps = append(p.steps, s[:end])
+			s = s[end:]
+
+		case s[0] == '[':
+			end := strings.Index(s, "]")
+			if end < 0 {
+				return Path{}, fmt.Errorf("invalid path %q: unclosed [", path)
+			}
+			inner := strings.TrimSpace(s[1:end])
+			s = s[end+1:]
+
+			if len(inner) >= 2 && (inner[0] == '\'' || inner[0] == '"') && inner[len(inner)-1] == inner[0] {
+				p.steps = append(p.steps, inner[1:len(inner)-1])
+				continue
+			}
+			idx, err := strconv.Atoi(inner)
+			if err != nil {
+				return Path{}, fmt.Errorf("invalid path %q: bad index %q", path, inner)
+			}
+			p.steps = append(p.steps, idx)
+
+		default:
+			// A bare leading key, as in "status.code"
+			if len(p.steps) > 0 {
+				return Path{}, fmt.Errorf("invalid path %q: unexpected %q", path, s)
+			}
+			s = "." + s
+		}
+	}
+
+	return p, nil
+}
+
+// String returns the path as written
+func (p Path) String() string {
+	return p.raw
+}
+
+// Get returns the value at the path, and false if any step is missing.
+// Negative indexes count from the end of an array.
+func (p Path) Get(doc interface{}) (interface{}, bool) {
+	current := doc
+	for _, step := range p.steps {
+		switch s := step.(type) {
+		case string:
+			obj, ok := current.(map[string]interface{})
+			if !ok {
+				return nil, false
+			}
+			if current, ok = obj[s]; !ok {
+				return nil, false
+			}
+		case int:
+			arr, ok := current.([]interface{})
+			if !ok {
+				return nil, false
+			}
+			if s < 0 {
+				s += len(arr)
+			}
+			if s < 0 || s >= len(arr) {
+				return nil, false
+			}
+			current = arr[s]
+		}
+	}
+	return current, true
+}
@@ -0,0 +1,561 @@
+// Package jsonschema validates payloads against JSON Schema (draft 7 and
+// later keywords that Schema Registry users commonly rely on) and generates
+// payload templates from a schema's properties.
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"math"
+	"regexp"
+	"sort"
+	"strings"
+	"unicode/utf8"
+)
+
+// Schema is a compiled JSON Schema
+type Schema struct {
+	root     interface{}
+	patterns map[string]*regexp.Regexp
+}
+
+// Compile parses a JSON Schema and checks its patterns and local references
+func Compile(schemaJSON string) (*Schema, error) {
+	root, err := decode(schemaJSON)
+	if err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	if _, ok := root.(map[string]interface{}); !ok {
+		if _, ok := root.(bool); !ok {
+			return nil, fmt.Errorf("schema must be an object or a boolean")
+		}
+	}
+
+	s := &Schema{root: root, patterns: make(map[string]*regexp.Regexp)}
+	if err := s.prepare(root); err != nil {
+		return nil, err
+	}
+	return s, nil
+}
+
+// prepare compiles every pattern and checks every $ref resolves
+func (s *Schema) prepare(node interface{}) error {
+	switch n := node.(type) {
+	case map[string]interface{}:
+		if pattern, ok := n["pattern"].(string); ok {
+			re, err := regexp.Compile(pattern)
+			if err != nil {
+				return fmt.Errorf("invalid pattern %q: %w", pattern, err)
+			}
+			s.patterns[pattern] = re
+		}
+		if ref, ok := n["$ref"].(string); ok {
+			if _, err := s.resolve(ref); err != nil {
+				return err
+			}
+		}
+		for key, child := range n {
+			// Values of these keywords are data, not schemas
+			if key == "enum" || key == "const" || key == "default" || key == "examples" {
+				continue
+			}
+			if err := s.prepare(child); err != nil {
+				return err
+			}
+		}
+	case []interface{}:
+		for _, child := range n {
+			if err := s.prepare(child); err != nil {
+				return err
+			}
+		}
+	}
+	return nil
+}
+
+// resolve follows a local reference such as #/definitions/Address
+func (s *Schema) resolve(ref string) (interface{}, error) {
+	if ref == "#" {
+		return s.root, nil
+	}
+	if !strings.HasPrefix(ref, "#/") {
+		return nil, fmt.Errorf("unsupported $ref %q: only references within the schema are supported", ref)
+	}
+	node := s.root
+	for _, token := range strings.Split(ref[2:], "/") {
+		token = strings.ReplaceAll(strings.ReplaceAll(token, "~1", "/"), "~0", "~")
+		obj, ok := node.(map[string]interface{})
+		if !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+		if node, ok = obj[token]; !ok {
+			return nil, fmt.Errorf("unresolvable $ref %q", ref)
+		}
+	}
+	return node, nil
+}
+
+// ValidationError lists everything a payload gets wrong
+type ValidationError struct {
+	Problems []string // Each as "<JSON pointer>: <problem>"
+}
+
+func (e *ValidationError) Error() string {
+	return strings.Join(e.Problems, "; ")
+}
+
+// Validate checks a JSON payload against the schema
+func (s *Schema) Validate(payload string) error {
+	doc, err := decode(payload)
+	if err != nil {
+		return fmt.Errorf("invalid JSON: %w", err)
+	}
+	var problems []string
+	s.validate(s.root, doc, "", &problems, 0)
+	if len(problems) > 0 {
+		return &ValidationError{Problems: problems}
+	}
+	return nil
+}
+
+// Encode validates a payload and returns it as compact JSON, the form it is
+// produced in
+func (s *Schema) Encode(payload string) ([]byte, error) {
+	if err := s.Validate(payload); err != nil {
+		return nil, err
+	}
+	var compact bytes.Buffer
+	if err := json.Compact(&compact, []byte(payload)); err != nil {
+		return nil, err
+	}
+	return compact.Bytes(), nil
+}
+
+// EncodeKey validates a message key against a JSON Schema and returns it as
+// compact JSON. A key that isn't valid as JSON is tried again as a string,
+// so string keys can be typed without quotes.
+func EncodeKey(schemaJSON, key string) ([]byte, error) {
+	s, err := Compile(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+	encoded, err := s.Encode(key)
+	if err == nil {
+		return encoded, nil
+	}
+	var str string
+	if json.Unmarshal([]byte(key), &str) != nil {
+		quoted, _ := json.Marshal(key)
+		if encoded, qerr := s.Encode(string(quoted)); qerr == nil {
+			return encoded, nil
+		}
+	}
+	return nil, err
+}
+
+// maxRefDepth stops a recursive $ref that never reaches data
+const maxRefDepth = 64
+
+func (s *Schema) validate(node, value interface{}, path string, problems *[]string, refs int) {
+	at := path
+	if at == "" {
+		at = "/"
+	}
+	fail := func(format string, args ...interface{}) {
+		*problems = append(*problems, at+": "+fmt.Sprintf(format, args...))
+	}
+
+	switch n := node.(type) {
+	case bool:
+		if !n {
+			fail("not allowed")
+		}
+		return
+	case map[string]interface{}:
+		if ref, ok := n["$ref"].(string); ok {
+			if refs >= maxRefDepth {
+				fail("$ref %s nests too deeply", ref)
+				return
+			}
+			target, err := s.resolve(ref)
+			if err != nil {
+				fail("%v", err)
+				return
+			}
+			s.validate(target, value, path, problems, refs+1)
+		}
+
+		if types, ok := n["type"]; ok && !matchesType(types, value) {
+			fail("expected %s, got %s", describeTypes(types), typeOf(value))
+			return
+		}
+		if enum, ok := n["enum"].([]interface{}); ok && !containsValue(enum, value) {
+			fail("must be one of %s", compact(enum))
+		}
+		if c, ok := n["const"]; ok && !equal(c, value) {
+			fail("must be %s", compact(c))
+		}
+
+		s.validateCombinators(n, value, path, problems, refs, fail)
+
+		switch v := value.(type) {
+		case map[string]interface{}:
+			s.validateObject(n, v, path, problems, refs, fail)
+		case []interface{}:
+			s.validateArray(n, v, path, problems, refs, fail)
+		case string:
+			s.validateString(n, v, fail)
+		case json.Number:
+			validateNumber(n, v, fail)
+		}
+	}
+}
+
+func (s *Schema) validateCombinators(n map[string]interface{}, value interface{}, path string, problems *[]string, refs int, fail func(string, ...interface{})) {
+	if all, ok := n["allOf"].([]interface{}); ok {
+		for _, sub := range all {
+			s.validate(sub, value, path, problems, refs)
+		}
+	}
+	if anyOf, ok := n["anyOf"].([]interface{}); ok {
+		if s.countValid(anyOf, value, path, refs) == 0 {
+			fail("matches none of anyOf")
+		}
+	}
+	if oneOf, ok := n["oneOf"].([]interface{}); ok {
+		if count := s.countValid(oneOf, value, path, refs); count != 1 {
+			fail("matches %d of oneOf, want exactly 1", count)
+		}
+	}
+	if not, ok := n["not"]; ok && s.countValid([]interface{}{not}, value, path, refs) == 1 {
+		fail("must not match the not schema")
+	}
+	if cond, ok := n["if"]; ok {
+		if s.countValid([]interface{}{cond}, value, path, refs) == 1 {
+			if then, ok := n["then"]; ok {
+				s.validate(then, value, path, problems, refs)
+			}
+		} else if els, ok := n["else"]; ok {
+			s.validate(els, value, path, problems, refs)
+		}
+	}
+}
+
+// countValid counts the schemas a value is valid against
+func (s *Schema) countValid(schemas []interface{}, value interface{}, path string, refs int) int {
+	count := 0
+	for _, sub := range schemas {
+		var problems []string
+		s.validate(sub, value, path, &problems, refs)
+		if len(problems) == 0 {
+			count++
+		}
+	}
+	return count
+}
+
+func (s *Schema) validateObject(n map[string]interface{}, obj map[string]interface{}, path string, problems *[]string, refs int, fail func(string, ...interface{})) {
+	for _, name := range stringList(n["required"]) {
+		if _, ok := obj[name]; !ok {
+			fail("missing required property %q", name)
+		}
+	}
+	if min, ok := intKeyword(n, "minProperties"); ok && len(obj) < min {
+		fail("must have at least %d properties", min)
+	}
+	if max, ok := intKeyword(n, "maxProperties"); ok && len(obj) > max {
+		fail("must have at most %d properties", max)
+	}
+
+	properties, _ := n["properties"].(map[string]interface{})
+	patternProperties, _ := n["patternProperties"].(map[string]interface{})
+	for _, key := range sortedKeys(obj) {
+		child := path + "/" + escapePointer(key)
+		matched := false
+		if sub, ok := properties[key]; ok {
+			matched = true
+			s.validate(sub, obj[key], child, problems, refs)
+		}
+		for pattern, sub := range patternProperties {
+			re := s.patterns[pattern]
+			if re == nil {
+				re = regexp.MustCompile(regexp.QuoteMeta(pattern))
+			}
+			if re.MatchString(key) {
+				matched = true
+				s.validate(sub, obj[key], child, problems, refs)
+			}
+		}
+		if matched {
+			continue
+		}
+		if additional, ok := n["additionalProperties"]; ok {
+			if allowed, isBool := additional.(bool); isBool && !allowed {
+				*problems = append(*problems, child+": property not allowed")
+			} else if !isBool {
+				s.validate(additional, obj[key], child, problems, refs)
+			}
+		}
+	}
+}
+
+func (s *Schema) validateArray(n map[string]interface{}, arr []interface{}, path string, problems *[]string, refs int, fail func(string, ...interface{})) {
+	if min, ok := intKeyword(n, "minItems"); ok && len(arr) < min {
+		fail("must have at least %d items", min)
+	}
+	if max, ok := intKeyword(n, "maxItems"); ok && len(arr) > max {
+		fail("must have at most %d items", max)
+	}
+	if unique, _ := n["uniqueItems"].(bool); unique {
+		for i := range arr {
+			for j := i + 1; j < len(arr); j++ {
+				if equal(arr[i], arr[j]) {
+					fail("items %d and %d are equal, items must be unique", i, j)
+				}
+			}
+		}
+	}
+
+	switch items := n["items"].(type) {
+	case []interface{}:
+		// Tuple form: one schema per position
+		for i, item := range arr {
+			child := fmt.Sprintf("%s/%d", path, i)
+			if i < len(items) {
+				s.validate(items[i], item, child, problems, refs)
+			} else if additional, ok := n["additionalItems"]; ok {
+				s.validate(additional, item, child, problems, refs)
+			}
+		}
+	case nil:
+	default:
+		for i, item := range arr {
+			s.validate(items, item, fmt.Sprintf("%s/%d", path, i), problems, refs)
+		}
+	}
+	if contains, ok := n["contains"]; ok {
+		found := false
+		for _, item := range arr {
+			if s.countValid([]interface{}{contains}, item, path, refs) == 1 {
+				found = true
+				break
+			}
+		}
+		if !found {
+			fail("no item matches contains")
+		}
+	}
+}
+
+func (s *Schema) validateString(n map[string]interface{}, str string, fail func(string, ...interface{})) {
+	length := utf8.RuneCountInString(str)
+	if min, ok := intKeyword(n, "minLength"); ok && length < min {
+		fail("must be at least %d characters", min)
+	}
+	if max, ok := intKeyword(n, "maxLength"); ok && length > max {
+		fail("must be at most %d characters", max)
+	}
+	if pattern, ok := n["pattern"].(string); ok {
+		if re := s.patterns[pattern]; re != nil && !re.MatchString(str) {
+			fail("must match %s", pattern)
+		}
+	}
+}
+
+func validateNumber(n map[string]interface{}, num json.Number, fail func(string, ...interface{})) {
+	v, err := num.Float64()
+	if err != nil {
+		return
+	}
+	if min, ok := numberKeyword(n, "minimum"); ok && v < min {
+		fail("must be >= %v", min)
+	}
+	if max, ok := numberKeyword(n, "maximum"); ok && v > max {
+		fail("must be <= %v", max)
+	}
+	if min, ok := numberKeyword(n, "exclusiveMinimum"); ok && v <= min {
+		fail("must be > %v", min)
+	}
+	if max, ok := numberKeyword(n, "exclusiveMaximum"); ok && v >= max {
+		fail("must be < %v", max)
+	}
+	if multiple, ok := numberKeyword(n, "multipleOf"); ok && multiple > 0 {
+		if q := v / multiple; math.Abs(q-math.Round(q)) > 1e-9 {
+			fail("must be a multiple of %v", multiple)
+		}
+	}
+}
+
+// matchesType checks a value against a type keyword, a name or a list of names
+func matchesType(types, value interface{}) bool {
+	switch t := types.(type) {
+	case string:
+		return isType(t, value)
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && isType(s, value) {
+				return true
+			}
+		}
+		return false
+	}
+	return true
+}
+
+func isType(name string, value interface{}) bool {
+	switch name {
+	case "integer":
+		n, ok := value.(json.Number)
+		if !ok {
+			return false
+		}
+		if _, err := n.Int64(); err == nil {
+			return true
+		}
+		f, err := n.Float64()
+		return err == nil && f == math.Trunc(f)
+	case "number":
+		_, ok := value.(json.Number)
+		return ok
+	default:
+		return typeOf(value) == name
+	}
+}
+
+// typeOf names a decoded JSON value's type
+func typeOf(value interface{}) string {
+	switch value.(type) {
+	case nil:
+		return "null"
+	case bool:
+		return "boolean"
+	case json.Number:
+		return "number"
+	case string:
+		return "string"
+	case []interface{}:
+		return "array"
+	case map[string]interface{}:
+		return "object"
+	}
+	return fmt.Sprintf("%T", value)
+}
+
+func describeTypes(types interface{}) string {
+	if list, ok := types.([]interface{}); ok {
+		names := make([]string, len(list))
+		for i, t := range list {
+			names[i] = fmt.Sprint(t)
+		}
+		return strings.Join(names, " or ")
+	}
+	return fmt.Sprint(types)
+}
+
+func containsValue(list []interface{}, value interface{}) bool {
+	for _, item := range list {
+		if equal(item, value) {
+			return true
+		}
+	}
+	return false
+}
+
+// equal compares decoded JSON values, numbers by value
+func equal(a, b interface{}) bool {
+	if x, ok := a.(json.Number); ok {
+		y, ok := b.(json.Number)
+		if !ok {
+			return false
+		}
+		fx, errx := x.Float64()
+		fy, erry := y.Float64()
+		return errx == nil && erry == nil && fx == fy
+	}
+	switch x := a.(type) {
+	case []interface{}:
+		y, ok := b.([]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for i := range x {
+			if !equal(x[i], y[i]) {
+				return false
+			}
+		}
+		return true
+	case map[string]interface{}:
+		y, ok := b.(map[string]interface{})
+		if !ok || len(x) != len(y) {
+			return false
+		}
+		for k, v := range x {
+			if w, ok := y[k]; !ok || !equal(v, w) {
+				return false
+			}
+		}
+		return true
+	}
+	return a == b
+}
+
+func intKeyword(n map[string]interface{}, key string) (int, bool) {
+	v, ok := numberKeyword(n, key)
+	return int(v), ok
+}
+
+func numberKeyword(n map[string]interface{}, key string) (float64, bool) {
+	num, ok := n[key].(json.Number)
+	if !ok {
+		return 0, false
+	}
+	v, err := num.Float64()
+	return v, err == nil
+}
+
+func stringList(v interface{}) []string {
+	list, _ := v.([]interface{})
+	out := make([]string, 0, len(list))
+	for _, item := range list {
+		if s, ok := item.(string); ok {
+			out = append(out, s)
+		}
+	}
+	return out
+}
+
+func sortedKeys(obj map[string]interface{}) []string {
+	keys := make([]string, 0, len(obj))
+	for k := range obj {
+		keys = append(keys, k)
+	}
+	sort.Strings(keys)
+	return keys
+}
+
+func escapePointer(token string) string {
+	return strings.ReplaceAll(strings.ReplaceAll(token, "~", "~0"), "/", "~1")
+}
+
+func compact(v interface{}) string {
+	out, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(out)
+}
+
+// decode parses JSON keeping numbers as written
+func decode(text string) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(text)))
+	dec.UseNumber()
+	var v interface{}
+	if err := dec.Decode(&v); err != nil {
+		return nil, err
+	}
+	if dec.More() {
+		return nil, fmt.Errorf("unexpected data after the top-level value")
+	}
+	return v, nil
+}
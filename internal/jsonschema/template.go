@@ -0,0 +1,128 @@
+package jsonschema
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+)
+
+// Template builds a sample payload from a schema's properties. Defaults,
+// examples, consts and the first enum value are used where the schema gives
+// them, otherwise a zero value of the property's type.
+func Template(schemaJSON string) (string, error) {
+	s, err := Compile(schemaJSON)
+	if err != nil {
+		return "", err
+	}
+	value := s.sample(s.root, make(map[string]bool))
+
+	var out bytes.Buffer
+	enc := json.NewEncoder(&out)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(value); err != nil {
+		return "", fmt.Errorf("encoding template: %w", err)
+	}
+	return string(bytes.TrimSpace(out.Bytes())), nil
+}
+
+// sample makes a value for one schema node. refs holds the references being
+// expanded, so a recursive definition stops at its first repetition.
+func (s *Schema) sample(node interface{}, refs map[string]bool) interface{} {
+	n, ok := node.(map[string]interface{})
+	if !ok {
+		return nil
+	}
+
+	if ref, ok := n["$ref"].(string); ok {
+		if refs[ref] {
+			return nil
+		}
+		target, err := s.resolve(ref)
+		if err != nil {
+			return nil
+		}
+		refs[ref] = true
+		defer delete(refs, ref)
+		return s.sample(target, refs)
+	}
+
+	if v, ok := n["const"]; ok {
+		return v
+	}
+	if v, ok := n["default"]; ok {
+		return v
+	}
+	if examples, ok := n["examples"].([]interface{}); ok && len(examples) > 0 {
+		return examples[0]
+	}
+	if enum, ok := n["enum"].([]interface{}); ok && len(enum) > 0 {
+		return enum[0]
+	}
+	for _, key := range []string{"oneOf", "anyOf"} {
+		if branches, ok := n[key].([]interface{}); ok && len(branches) > 0 {
+			return s.sample(branches[0], refs)
+		}
+	}
+	if all, ok := n["allOf"].([]interface{}); ok && len(all) > 0 {
+		merged := map[string]interface{}{}
+		for _, sub := range all {
+			if obj, ok := s.sample(sub, refs).(map[string]interface{}); ok {
+				for k, v := range obj {
+					merged[k] = v
+				}
+			}
+		}
+		return merged
+	}
+
+	switch sampleType(n) {
+	case "object":
+		obj := map[string]interface{}{}
+		properties, _ := n["properties"].(map[string]interface{})
+		for name, prop := range properties {
+			obj[name] = s.sample(prop, refs)
+		}
+		return obj
+	case "array":
+		if items, ok := n["items"]; ok {
+			if _, tuple := items.([]interface{}); !tuple {
+				return []interface{}{s.sample(items, refs)}
+			}
+		}
+		return []interface{}{}
+	case "string":
+		return ""
+	case "integer", "number":
+		if min, ok := n["minimum"].(json.Number); ok {
+			return min
+		}
+		return json.Number("0")
+	case "boolean":
+		return false
+	}
+	return nil
+}
+
+// sampleType picks the type a template uses for a node, preferring a
+// non-null type when several are allowed
+func sampleType(n map[string]interface{}) string {
+	switch t := n["type"].(type) {
+	case string:
+		return t
+	case []interface{}:
+		for _, name := range t {
+			if s, ok := name.(string); ok && s != "null" {
+				return s
+			}
+		}
+		return "null"
+	}
+	if _, ok := n["properties"]; ok {
+		return "object"
+	}
+	if _, ok := n["items"]; ok {
+		return "array"
+	}
+	return ""
+}
@@ -0,0 +1,88 @@
+package kafka
+
+import (
+	"errors"
+	"fmt"
+
+	"github.com/segmentio/kafka-go"
+)
+
+// Topic operations, named as in Kafka ACLs
+const (
+	opRead     = "Read"
+	opWrite    = "Write"
+	opDescribe = "Describe"
+
+	opDescribeConfigs = "DescribeConfigs"
+	opAlterConfigs    = "AlterConfigs"
+)
+
+// aclRoles are the Confluent RBAC roles that grant each topic operation
+var aclRoles = map[string]string{
+	opRead:     "DeveloperRead",
+	opWrite:    "DeveloperWrite",
+	opDescribe: "DeveloperRead or DeveloperWrite",
+
+	opDescribeConfigs: "DeveloperManage",
+	opAlterConfigs:    "DeveloperManage",
+}
+
+// explainAuth wraps a broker authorization or authentication error with the
+// ACL the request needed, or the credentials to check. Other errors are
+// returned unchanged.
+func explainAuth(err error, operation, topic string) error {
+	code, ok := authCode(err)
+	if !ok {
+		return err
+	}
+
+	var explanation string
+	switch code {
+	case kafka.TopicAuthorizationFailed:
+		explanation = fmt.Sprintf("your credentials lack %s on topic %s (ACL: ALLOW %s on Topic:%s, or the %s role)",
+			operation, topic, operation, topic, aclRoles[operation])
+	case kafka.GroupAuthorizationFailed:
+		explanation = "your credentials lack Read on the consumer group (ACL: ALLOW Read on Group, or the DeveloperRead role)"
+	case kafka.ClusterAuthorizationFailed:
+		if operation == opWrite {
+			explanation = "your credentials lack IdempotentWrite on the cluster (ACL: ALLOW IdempotentWrite on Cluster:kafka-cluster)"
+		} else {
+			explanation = "your credentials lack a cluster permission the request needs (ACL: ALLOW Describe on Cluster:kafka-cluster)"
+		}
+	case kafka.TransactionalIDAuthorizationFailed:
+		explanation = "your credentials lack Write on the transactional ID (ACL: ALLOW Write on TransactionalId)"
+	case kafka.SASLAuthenticationFailed:
+		explanation = "the brokers rejected the credentials; check the profile's sasl_username and sasl_password, and that the API key belongs to this Kafka cluster rather than the Schema Registry"
+	case kafka.UnsupportedSASLMechanism:
+		explanation = "the brokers don't accept the SASL mechanism; check the profile's sasl_mechanism"
+	}
+	return fmt.Errorf("%s: %w", explanation, err)
+}
+
+// authCode finds a broker auth error in err, including one that failed
+// every message of a batch write
+func authCode(err error) (kafka.Error, bool) {
+	var code kafka.Error
+	if errors.As(err, &code) {
+		return code, isAuthCode(code)
+	}
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		for _, e := range writeErrs {
+			if e != nil && errors.As(e, &code) && isAuthCode(code) {
+				return code, true
+			}
+		}
+	}
+	return 0, false
+}
+
+func isAuthCode(code kafka.Error) bool {
+	switch code {
+	case kafka.TopicAuthorizationFailed, kafka.GroupAuthorizationFailed,
+		kafka.ClusterAuthorizationFailed, kafka.TransactionalIDAuthorizationFailed,
+		kafka.SASLAuthenticationFailed, kafka.UnsupportedSASLMechanism:
+		return true
+	}
+	return false
+}
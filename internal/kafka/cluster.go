@@ -0,0 +1,150 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"sort"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+)
+
+// ClusterInfo is an overview of a Kafka cluster
+type ClusterInfo struct {
+	ClusterID    string
+	Controller   int // Broker ID of the controller, -1 if none was reported
+	Brokers      []kafka.Broker
+	KafkaVersion string // Estimated from the APIs the bootstrap broker supports, empty if unknown
+	APIs         int    // Number of APIs the bootstrap broker supports
+
+	Topics          int
+	InternalTopics  int
+	Partitions      int // Across all topics, internal ones included
+	UnderReplicated int // Partitions with fewer in-sync replicas than replicas
+	TopicErrors     int // Topics whose metadata came back with an error, such as no leader
+}
+
+// versionMarkers are APIs added in each Kafka release, newest first. The
+// newest API a broker supports dates it.
+var versionMarkers = []struct {
+	apiKey  int
+	version string
+}{
+	{75, "3.8"},    // DescribeTopicPartitions
+	{74, "3.7"},    // ListClientMetricsResources
+	{66, "3.0"},    // ListTransactions
+	{60, "2.8"},    // DescribeCluster
+	{50, "2.7"},    // DescribeUserScramCredentials
+	{48, "2.6"},    // DescribeClientQuotas
+	{45, "2.4"},    // AlterPartitionReassignments
+	{44, "2.3"},    // IncrementalAlterConfigs
+	{43, "2.2"},    // ElectLeaders
+	{42, "2.0"},    // DeleteGroups
+	{37, "1.0"},    // CreatePartitions
+	{30, "0.11"},   // CreateAcls
+	{19, "0.10.1"}, // CreateTopics
+	{18, "0.10"},   // ApiVersions
+}
+
+// DescribeCluster fetches the cluster's brokers and topics from the
+// bootstrap broker, and the APIs it supports
+func DescribeCluster(ctx context.Context, cfg *config.Config) (_ *ClusterInfo, err error) {
+	defer metrics.Observe(metrics.Kafka+" describe cluster", time.Now(), &err)
+	client, addr, err := adminClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Addr: addr})
+	if err != nil {
+		return nil, fmt.Errorf("reading cluster metadata: %w", explainAuth(err, opDescribe, ""))
+	}
+
+	info := &ClusterInfo{
+		ClusterID:  meta.ClusterID,
+		Controller: meta.Controller.ID,
+		Brokers:    meta.Brokers,
+	}
+	if meta.Controller.Host == "" {
+		info.Controller = -1
+	}
+	sort.Slice(info.Brokers, func(i, j int) bool { return info.Brokers[i].ID < info.Brokers[j].ID })
+
+	for _, t := range meta.Topics {
+		if t.Error != nil {
+			info.TopicErrors++
+			continue
+		}
+		info.Topics++
+		if t.Internal {
+			info.InternalTopics++
+		}
+		for _, p := range t.Partitions {
+			info.Partitions++
+			if len(p.Isr) < len(p.Replicas) {
+				info.UnderReplicated++
+			}
+		}
+	}
+
+	// An old broker without ApiVersions just leaves the version unknown
+	if versions, err := client.ApiVersions(ctx, &kafka.ApiVersionsRequest{Addr: addr}); err == nil && versions.Error == nil {
+		info.APIs = len(versions.ApiKeys)
+		info.KafkaVersion = estimateVersion(versions.ApiKeys)
+	}
+	return info, nil
+}
+
+// adminClient returns a client for admin requests to the profile's
+// bootstrap broker, and its address
+func adminClient(cfg *config.Config) (*kafka.Client, net.Addr, error) {
+	if cfg.KafkaBootstrapServers == "" {
+		return nil, nil, fmt.Errorf("KAFKA_BOOTSTRAP_SERVERS not configured")
+	}
+	transport, err := NewTransport(cfg)
+	if err != nil {
+		return nil, nil, fmt.Errorf("dialer error: %w", err)
+	}
+	return &kafka.Client{Transport: transport}, kafka.TCP(cfg.KafkaBootstrapServers), nil
+}
+
+// estimateVersion dates a broker by the newest API it supports
+func estimateVersion(apis []kafka.ApiVersionsResponseApiKey) string {
+	supported := make(map[int]bool, len(apis))
+	for _, api := range apis {
+		supported[api.ApiKey] = true
+	}
+	for _, marker := range versionMarkers {
+		if supported[marker.apiKey] {
+			return marker.version
+		}
+	}
+	return ""
+}
+
+// ListTopics returns the names of the cluster's topics, internal ones left
+// out, sorted
+func ListTopics(ctx context.Context, cfg *config.Config) (_ []string, err error) {
+	defer metrics.Observe(metrics.Kafka+" list topics", time.Now(), &err)
+	client, addr, err := adminClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	meta, err := client.Metadata(ctx, &kafka.MetadataRequest{Addr: addr})
+	if err != nil {
+		return nil, fmt.Errorf("listing topics: %w", explainAuth(err, opDescribe, ""))
+	}
+	var topics []string
+	for _, t := range meta.Topics {
+		if !t.Internal {
+			topics = append(topics, t.Name)
+		}
+	}
+	sort.Strings(topics)
+	return topics, nil
+}
@@ -2,15 +2,16 @@ package kafka
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/base64"
 	"fmt"
+	"sort"
 	"time"
 
 	"github.com/segmentio/kafka-go"
-	"github.com/segmentio/kafka-go/sasl/plain"
 
 	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+	"github.com/JimmyyyW/avrocado/internal/plugin"
 )
 
 // Message represents a Kafka message
@@ -19,39 +20,37 @@ type Message struct {
 	Value     string
 	Offset    int64
 	Timestamp time.Time
+	Headers   map[string]string
+
+	// Set when the topic's serializer plugin couldn't unwrap the message,
+	// which is then left as consumed
+	SerializerError string
 }
 
 // Consumer wraps a Kafka consumer for reading messages
 type Consumer struct {
-	reader *kafka.Reader
+	reader     *kafka.Reader
+	dialer     *kafka.Dialer
+	broker     string
+	topic      string
+	serializer *plugin.Serializer // Unwraps messages, nil without a plugin
 }
 
 // NewConsumer creates a new Kafka consumer for the given topic
 func NewConsumer(cfg *config.Config, topic string) (*Consumer, error) {
+	return NewPartitionConsumer(cfg, topic, 0)
+}
+
+// NewPartitionConsumer creates a Kafka consumer for one partition of a topic
+func NewPartitionConsumer(cfg *config.Config, topic string, partition int) (*Consumer, error) {
 	if cfg.KafkaBootstrapServers == "" {
 		return nil, fmt.Errorf("KAFKA_BOOTSTRAP_SERVERS not configured")
 	}
 
 	// Create dialer with optional SASL/TLS support
-	dialer := &kafka.Dialer{
-		Timeout:   10 * time.Second,
-		DualStack: true,
-	}
-
-	// Configure SASL_SSL if needed
-	if cfg.KafkaSecurityProtocol == "SASL_SSL" {
-		// Configure TLS with system CA certificates
-		dialer.TLS = &tls.Config{
-			MinVersion: tls.VersionTLS12,
-		}
-
-		// Configure SASL PLAIN mechanism (for Confluent Cloud)
-		if cfg.KafkaSASLUsername != "" && cfg.KafkaSASLPassword != "" {
-			dialer.SASLMechanism = plain.Mechanism{
-				Username: cfg.KafkaSASLUsername,
-				Password: cfg.KafkaSASLPassword,
-			}
-		}
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, fmt.Errorf("dialer error: %w", err)
 	}
 
 	// Create reader with configured dialer
@@ -61,15 +60,23 @@ func NewConsumer(cfg *config.Config, topic string) (*Consumer, error) {
 	reader := kafka.NewReader(kafka.ReaderConfig{
 		Brokers:     []string{cfg.KafkaBootstrapServers},
 		Topic:       topic,
+		Partition:   partition,
 		Dialer:      dialer,
 		StartOffset: 0, // Read from the beginning
 	})
 
-	return &Consumer{reader: reader}, nil
+	return &Consumer{
+		reader:     reader,
+		dialer:     dialer,
+		broker:     cfg.KafkaBootstrapServers,
+		topic:      topic,
+		serializer: plugin.ForTopic(cfg, topic),
+	}, nil
 }
 
 // FetchMessages fetches up to maxMessages from the topic
-func (c *Consumer) FetchMessages(ctx context.Context, maxMessages int) ([]Message, error) {
+func (c *Consumer) FetchMessages(ctx context.Context, maxMessages int) (_ []Message, err error) {
+	defer metrics.Observe(metrics.Kafka+" fetch", time.Now(), &err)
 	messages := []Message{}
 
 	for i := 0; i < maxMessages; i++ {
@@ -81,22 +88,126 @@ func (c *Consumer) FetchMessages(ctx context.Context, maxMessages int) ([]Messag
 			}
 			// If it's the first message and we get an error, return it
 			if len(messages) == 0 {
-				return nil, err
+				return nil, explainAuth(err, opRead, c.topic)
 			}
 			break
 		}
 
+		var headers map[string]string
+		if len(msg.Headers) > 0 {
+			headers = make(map[string]string, len(msg.Headers))
+			for _, h := range msg.Headers {
+				headers[h.Key] = string(h.Value)
+			}
+		}
+
+		key, value := msg.Key, msg.Value
+		var serializerErr string
+		if c.serializer != nil {
+			if k, v, err := c.serializer.Decode(c.topic, key, value, headers); err != nil {
+				serializerErr = err.Error()
+			} else {
+				key, value = k, v
+			}
+		}
+
 		messages = append(messages, Message{
-			Key:       base64.StdEncoding.EncodeToString(msg.Key),
-			Value:     base64.StdEncoding.EncodeToString(msg.Value),
-			Offset:    msg.Offset,
-			Timestamp: msg.Time,
+			Key:             base64.StdEncoding.EncodeToString(key),
+			Value:           base64.StdEncoding.EncodeToString(value),
+			Offset:          msg.Offset,
+			Timestamp:       msg.Time,
+			Headers:         headers,
+			SerializerError: serializerErr,
 		})
 	}
 
 	return messages, nil
 }
 
+// SeekToEnd skips the messages already on the topic, so only messages
+// produced from now on are fetched. The end offset is resolved immediately,
+// so a message produced right after SeekToEnd returns is not missed.
+func (c *Consumer) SeekToEnd(ctx context.Context) error {
+	_, last, err := c.Watermarks(ctx)
+	if err != nil {
+		return err
+	}
+	return c.reader.SetOffset(last)
+}
+
+// Watermarks returns the partition's first retained offset and the offset
+// the next message will be written at
+func (c *Consumer) Watermarks(ctx context.Context) (_, _ int64, err error) {
+	defer metrics.Observe(metrics.Kafka+" watermarks", time.Now(), &err)
+	conn, err := c.dialer.DialLeader(ctx, "tcp", c.broker, c.topic, c.Partition())
+	if err != nil {
+		return 0, 0, fmt.Errorf("connecting to partition leader: %w", explainAuth(err, opDescribe, c.topic))
+	}
+	defer conn.Close()
+
+	first, last, err := conn.ReadOffsets()
+	if err != nil {
+		return 0, 0, fmt.Errorf("reading watermarks: %w", explainAuth(err, opDescribe, c.topic))
+	}
+	return first, last, nil
+}
+
+// Partitions returns the IDs of the topic's partitions
+func (c *Consumer) Partitions(ctx context.Context) (_ []int, err error) {
+	defer metrics.Observe(metrics.Kafka+" partitions", time.Now(), &err)
+	conn, err := c.dialer.DialContext(ctx, "tcp", c.broker)
+	if err != nil {
+		return nil, fmt.Errorf("connecting to %s: %w", c.broker, explainAuth(err, opDescribe, c.topic))
+	}
+	defer conn.Close()
+
+	partitions, err := conn.ReadPartitions(c.topic)
+	if err != nil {
+		return nil, fmt.Errorf("reading partitions of %s: %w", c.topic, explainAuth(err, opDescribe, c.topic))
+	}
+	ids := make([]int, len(partitions))
+	for i, p := range partitions {
+		ids[i] = p.ID
+	}
+	sort.Ints(ids)
+	return ids, nil
+}
+
+// OffsetAt returns the offset of the first message with a timestamp at or
+// after t, or the high watermark if there is none
+func (c *Consumer) OffsetAt(ctx context.Context, t time.Time) (_ int64, err error) {
+	defer metrics.Observe(metrics.Kafka+" offset lookup", time.Now(), &err)
+	conn, err := c.dialer.DialLeader(ctx, "tcp", c.broker, c.topic, c.Partition())
+	if err != nil {
+		return 0, fmt.Errorf("connecting to partition leader: %w", explainAuth(err, opDescribe, c.topic))
+	}
+	defer conn.Close()
+
+	offset, err := conn.ReadOffset(t)
+	if err != nil {
+		return 0, fmt.Errorf("reading offset at %s: %w", t.Format(time.RFC3339), explainAuth(err, opDescribe, c.topic))
+	}
+	if offset < 0 {
+		return conn.ReadLastOffset()
+	}
+	return offset, nil
+}
+
+// SeekTo positions the consumer so the next fetch starts at offset
+func (c *Consumer) SeekTo(offset int64) error {
+	return c.reader.SetOffset(offset)
+}
+
+// Partition returns the partition the consumer reads
+func (c *Consumer) Partition() int {
+	return c.reader.Config().Partition
+}
+
+// Lag returns how many messages remain after the last fetched message
+func (c *Consumer) Lag() int64 {
+	return c.reader.Lag()
+}
+
 // Close closes the consumer
 func (c *Consumer) Close() error {
 	if c.reader != nil {
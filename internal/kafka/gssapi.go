@@ -0,0 +1,191 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"os"
+	"sync"
+
+	krbclient "github.com/jcmturner/gokrb5/v8/client"
+	krbconfig "github.com/jcmturner/gokrb5/v8/config"
+	"github.com/jcmturner/gokrb5/v8/credentials"
+	"github.com/jcmturner/gokrb5/v8/gssapi"
+	"github.com/jcmturner/gokrb5/v8/iana/keyusage"
+	"github.com/jcmturner/gokrb5/v8/keytab"
+	"github.com/jcmturner/gokrb5/v8/spnego"
+	"github.com/jcmturner/gokrb5/v8/types"
+	"github.com/segmentio/kafka-go/sasl"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// gssapiMechanism implements SASL/GSSAPI (Kerberos) authentication.
+// The Kerberos client is created lazily on the first handshake and shared by
+// all connections; it renews its TGT itself when logged in from a keytab.
+type gssapiMechanism struct {
+	cfg *config.KerberosConfig
+
+	mu     sync.Mutex
+	client *krbclient.Client
+}
+
+func newGSSAPIMechanism(cfg *config.KerberosConfig) (*gssapiMechanism, error) {
+	if cfg == nil {
+		return nil, fmt.Errorf("GSSAPI requires a kerberos section in the kafka config")
+	}
+	if cfg.Keytab != "" && (cfg.Username == "" || cfg.Realm == "") {
+		return nil, fmt.Errorf("kerberos keytab requires username and realm")
+	}
+	return &gssapiMechanism{cfg: cfg}, nil
+}
+
+func (m *gssapiMechanism) Name() string {
+	return "GSSAPI"
+}
+
+func (m *gssapiMechanism) Start(ctx context.Context) (sasl.StateMachine, []byte, error) {
+	meta := sasl.MetadataFromContext(ctx)
+	if meta == nil {
+		return nil, nil, fmt.Errorf("GSSAPI: broker host unknown")
+	}
+
+	cl, err := m.login()
+	if err != nil {
+		return nil, nil, err
+	}
+
+	service := m.cfg.ServiceName
+	if service == "" {
+		service = "kafka"
+	}
+
+	ticket, key, err := cl.GetServiceTicket(service + "/" + meta.Host)
+	if err != nil {
+		return nil, nil, fmt.Errorf("GSSAPI: getting service ticket for %s/%s: %w", service, meta.Host, err)
+	}
+
+	token, err := spnego.NewKRB5TokenAPREQ(cl, ticket, key,
+		[]int{gssapi.ContextFlagInteg, gssapi.ContextFlagConf}, []int{})
+	if err != nil {
+		return nil, nil, fmt.Errorf("GSSAPI: building AP-REQ: %w", err)
+	}
+
+	ir, err := token.Marshal()
+	if err != nil {
+		return nil, nil, fmt.Errorf("GSSAPI: encoding AP-REQ: %w", err)
+	}
+
+	return &gssapiSession{key: key}, ir, nil
+}
+
+// login returns the shared Kerberos client, obtaining a TGT if needed
+func (m *gssapiMechanism) login() (*krbclient.Client, error) {
+	m.mu.Lock()
+	defer m.mu.Unlock()
+
+	if m.client == nil {
+		cl, err := m.newClient()
+		if err != nil {
+			return nil, err
+		}
+		m.client = cl
+	}
+
+	if err := m.client.AffirmLogin(); err != nil {
+		return nil, fmt.Errorf("kerberos login: %w", err)
+	}
+	return m.client, nil
+}
+
+func (m *gssapiMechanism) newClient() (*krbclient.Client, error) {
+	krb5Path := m.cfg.Krb5Conf
+	if krb5Path == "" {
+		krb5Path = os.Getenv("KRB5_CONFIG")
+	}
+	if krb5Path == "" {
+		krb5Path = "/etc/krb5.conf"
+	}
+
+	krb5conf, err := krbconfig.Load(krb5Path)
+	if err != nil {
+		return nil, fmt.Errorf("loading krb5 config %s: %w", krb5Path, err)
+	}
+
+	settings := []func(*krbclient.Settings){krbclient.DisablePAFXFAST(m.cfg.DisableFAST)}
+
+	if m.cfg.Keytab != "" {
+		kt, err := keytab.Load(m.cfg.Keytab)
+		if err != nil {
+			return nil, fmt.Errorf("loading keytab: %w", err)
+		}
+		return krbclient.NewWithKeytab(m.cfg.Username, m.cfg.Realm, kt, krb5conf, settings...), nil
+	}
+
+	ccache, err := credentials.LoadCCache(ccachePath(m.cfg.CCache))
+	if err != nil {
+		return nil, fmt.Errorf("loading kerberos ticket cache (run kinit?): %w", err)
+	}
+
+	cl, err := krbclient.NewFromCCache(ccache, krb5conf, settings...)
+	if err != nil {
+		return nil, fmt.Errorf("reading kerberos ticket cache: %w", err)
+	}
+	return cl, nil
+}
+
+// ccachePath resolves the ticket cache location the same way kinit does for
+// FILE caches
+func ccachePath(configured string) string {
+	path := configured
+	if path == "" {
+		path = os.Getenv("KRB5CCNAME")
+	}
+	if path == "" {
+		return fmt.Sprintf("/tmp/krb5cc_%d", os.Getuid())
+	}
+	if len(path) > 5 && path[:5] == "FILE:" {
+		return path[5:]
+	}
+	return path
+}
+
+// gssapiSession runs the GSSAPI exchange for one connection. After the
+// AP-REQ, the broker sends a wrap token offering security layers; we verify
+// it and reply that no security layer is wanted (RFC 4752 section 3.1).
+type gssapiSession struct {
+	key  types.EncryptionKey
+	done bool
+}
+
+func (s *gssapiSession) Next(ctx context.Context, challenge []byte) (bool, []byte, error) {
+	if s.done {
+		return true, nil, nil
+	}
+
+	// Brokers that send an AP-REP first expect an empty reply
+	if len(challenge) == 0 || challenge[0] == 0x60 {
+		return false, []byte{}, nil
+	}
+
+	var offer gssapi.WrapToken
+	if err := offer.Unmarshal(challenge, true); err != nil {
+		return false, nil, fmt.Errorf("GSSAPI: decoding broker token: %w", err)
+	}
+	if ok, err := offer.Verify(s.key, keyusage.GSSAPI_ACCEPTOR_SEAL); !ok {
+		return false, nil, fmt.Errorf("GSSAPI: verifying broker token: %w", err)
+	}
+
+	reply, err := gssapi.NewInitiatorWrapToken([]byte{0x01, 0x00, 0x00, 0x00}, s.key)
+	if err != nil {
+		return false, nil, fmt.Errorf("GSSAPI: building reply: %w", err)
+	}
+	response, err := reply.Marshal()
+	if err != nil {
+		return false, nil, fmt.Errorf("GSSAPI: encoding reply: %w", err)
+	}
+
+	// The reply still has to be sent, so the exchange completes on the
+	// broker's (empty) answer to it
+	s.done = true
+	return false, response, nil
+}
@@ -0,0 +1,135 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+)
+
+// describeACLsKey is the DescribeAcls API key, looked for in the broker's
+// ApiVersions before asking for ACLs
+const describeACLsKey = 29
+
+// WriteProbe is what CheckWrite found wrong with producing to a topic
+type WriteProbe struct {
+	Topic  string
+	Denied bool // The brokers will refuse the write, rather than likely refuse it
+	Reason string
+}
+
+func (p *WriteProbe) Error() string {
+	return p.Reason
+}
+
+// CheckWrite probes whether the credentials can write to a topic, so a
+// missing ACL is reported up front rather than as a timeout inside
+// WriteMessages. It fetches the topic's metadata, then, if the brokers
+// support it and the credentials may, describes the ACLs on the topic for
+// the SASL user. It returns nil if nothing was found wrong, including when
+// the ACLs can't be read.
+func (p *Producer) CheckWrite(ctx context.Context, topic string) (_ *WriteProbe, err error) {
+	defer metrics.Observe(metrics.Kafka+" write probe", time.Now(), &err)
+	broker := p.cfg.KafkaBootstrapServers
+
+	conn, err := p.dialer.DialContext(ctx, "tcp", broker)
+	if err != nil {
+		if _, ok := authCode(err); ok {
+			return &WriteProbe{Topic: topic, Denied: true, Reason: explainAuth(err, opWrite, topic).Error()}, nil
+		}
+		return nil, fmt.Errorf("connecting to %s: %w", broker, err)
+	}
+	defer conn.Close()
+
+	if _, err := conn.ReadPartitions(topic); err != nil {
+		// Write implies Describe, so a topic the credentials can't describe
+		// can't be written either
+		if code, ok := authCode(err); ok && code == kafka.TopicAuthorizationFailed {
+			return &WriteProbe{Topic: topic, Denied: true, Reason: explainAuth(err, opWrite, topic).Error()}, nil
+		}
+		return nil, fmt.Errorf("reading metadata for %s: %w", topic, err)
+	}
+
+	principal := p.principal()
+	if principal == "" || !supportsDescribeACLs(conn) {
+		return nil, nil
+	}
+
+	res, err := newClient(p.dialer).DescribeACLs(ctx, &kafka.DescribeACLsRequest{
+		Addr: kafka.TCP(broker),
+		Filter: kafka.ACLFilter{
+			ResourceTypeFilter:        kafka.ResourceTypeTopic,
+			ResourceNameFilter:        topic,
+			ResourcePatternTypeFilter: kafka.PatternTypeMatch,
+			Operation:                 kafka.ACLOperationTypeAny,
+			PermissionType:            kafka.ACLPermissionTypeAny,
+		},
+	})
+	if err != nil || res.Error != nil {
+		// No authorizer, or the credentials may not describe ACLs: nothing
+		// to go on
+		return nil, nil
+	}
+	return writeVerdict(topic, principal, res.Resources), nil
+}
+
+// principal returns the ACL principal of the profile's SASL user, empty if
+// it can't be told from the profile
+func (p *Producer) principal() string {
+	mechanism := strings.ToUpper(p.cfg.KafkaSASLMechanism)
+	if p.cfg.KafkaSASLUsername == "" || mechanism == "GSSAPI" {
+		return ""
+	}
+	return "User:" + p.cfg.KafkaSASLUsername
+}
+
+func supportsDescribeACLs(conn *kafka.Conn) bool {
+	versions, err := conn.ApiVersions()
+	if err != nil {
+		return false
+	}
+	for _, v := range versions {
+		if v.ApiKey == describeACLsKey {
+			return true
+		}
+	}
+	return false
+}
+
+// writeVerdict reads the ACLs matching a topic: a DENY of Write for the
+// principal refuses the write, and no ALLOW for it likely does. ACLs on
+// the topic for other principals only are taken to mean the ACLs are
+// managed and the principal was left out; no ACLs at all may mean access
+// is granted another way, such as by a role, so nothing is reported.
+func writeVerdict(topic, principal string, resources []kafka.ACLResource) *WriteProbe {
+	var seen, allowed bool
+	for _, r := range resources {
+		for _, acl := range r.ACLs {
+			seen = true
+			if acl.Principal != principal && acl.Principal != "User:*" {
+				continue
+			}
+			if acl.Operation != kafka.ACLOperationTypeWrite && acl.Operation != kafka.ACLOperationTypeAll {
+				continue
+			}
+			switch acl.PermissionType {
+			case kafka.ACLPermissionTypeDeny:
+				return &WriteProbe{Topic: topic, Denied: true, Reason: fmt.Sprintf(
+					"an ACL denies %s %s on Topic:%s (%s pattern %s)",
+					acl.Principal, acl.Operation, topic, r.PatternType, r.ResourceName)}
+			case kafka.ACLPermissionTypeAllow:
+				allowed = true
+			}
+		}
+	}
+	if allowed || !seen {
+		return nil
+	}
+	return &WriteProbe{Topic: topic, Reason: fmt.Sprintf(
+		"you likely lack WRITE on topic %s: no ACL allows %s to write it (ACL: ALLOW Write on Topic:%s, or the %s role)",
+		topic, principal, topic, aclRoles[opWrite])}
+}
@@ -2,21 +2,24 @@ package kafka
 
 import (
 	"context"
-	"crypto/tls"
 	"encoding/binary"
+	"errors"
 	"fmt"
-	"strings"
 	"time"
 
 	"github.com/segmentio/kafka-go"
-	"github.com/segmentio/kafka-go/sasl/plain"
 
 	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+	"github.com/JimmyyyW/avrocado/internal/plugin"
 )
 
 // Producer wraps a Kafka producer with Avro serialization support.
 type Producer struct {
-	writer *kafka.Writer
+	writer    *kafka.Writer
+	transport *kafka.Transport
+	dialer    *kafka.Dialer
+	cfg       *config.Config
 }
 
 // NewProducer creates a new Kafka producer from config.
@@ -31,70 +34,130 @@ func NewProducer(cfg *config.Config) (*Producer, error) {
 		return nil, fmt.Errorf("dialer error: %w", err)
 	}
 
-	// Create writer with configured dialer
-	writer := kafka.NewWriter(kafka.WriterConfig{
-		Brokers: []string{cfg.KafkaBootstrapServers},
-		Dialer: dialer,
-		Balancer: &kafka.LeastBytes{},
-		RequiredAcks: int(kafka.RequireAll),
-	})
+	// Write through the shared transport rather than WriterConfig.Dialer,
+	// which drops the dialer's DialFunc and so bypasses SSH tunnels
+	transport := transportFor(dialer)
+	writer := &kafka.Writer{
+		Addr:         kafka.TCP(cfg.KafkaBootstrapServers),
+		Transport:    transport,
+		Balancer:     &kafka.LeastBytes{},
+		RequiredAcks: kafka.RequireAll,
+		// Messages are mostly written one at a time, so don't wait for a
+		// batch to fill
+		BatchTimeout: 10 * time.Millisecond,
+	}
 
-	return &Producer{writer: writer}, nil
+	return &Producer{writer: writer, transport: transport, dialer: dialer, cfg: cfg}, nil
 }
 
-func newDialer(cfg *config.Config) (*kafka.Dialer, error) {
-	dialer := &kafka.Dialer{
-		Timeout: 10 * time.Second,
-		DualStack: true,
+// Produce sends a message to the specified topic.
+// The value should be Avro binary data (without wire format header).
+// schemaID is used to prepend the Schema Registry wire format header.
+func (p *Producer) Produce(ctx context.Context, topic string, schemaID int, key, value []byte) error {
+	return p.produce(ctx, topic, schemaID, key, value, nil)
+}
+
+func (p *Producer) produce(ctx context.Context, topic string, schemaID int, key, value []byte, headers map[string]string) (err error) {
+	defer metrics.Observe(metrics.Kafka+" produce", time.Now(), &err)
+
+	msg, err := p.message(topic, schemaID, Record{Key: key, Value: value, Headers: headers})
+	if err != nil {
+		return err
+	}
+	if err := p.writer.WriteMessages(ctx, msg); err != nil {
+		return fmt.Errorf("producing message: %w", explainAuth(err, opWrite, topic))
 	}
 
-	switch strings.ToUpper(cfg.KafkaSecurityProtocol) {
-	case "PLAINTEXT":
-		return dialer, nil
-	case "SASL_SSL":
-		if cfg.KafkaSASLUsername == "" || cfg.KafkaSASLPassword == "" {
-			return nil, fmt.Errorf("SASL creds missing")
-		}
+	return nil
+}
+
+// Record is one message for ProduceBatch
+type Record struct {
+	Key     []byte
+	Value   []byte // Avro binary, without the wire-format header
+	Headers map[string]string
+}
+
+// ProduceBatch sends several messages with the same schema in one write.
+func (p *Producer) ProduceBatch(ctx context.Context, topic string, schemaID int, records []Record) (err error) {
+	defer metrics.Observe(metrics.Kafka+" produce batch", time.Now(), &err)
 
-		dialer.SASLMechanism = plain.Mechanism{
-			Username: cfg.KafkaSASLUsername,
-			Password: cfg.KafkaSASLPassword,
+	msgs := make([]kafka.Message, len(records))
+	for i, r := range records {
+		msg, err := p.message(topic, schemaID, r)
+		if err != nil {
+			return err
 		}
+		msgs[i] = msg
+	}
+	if err := p.writer.WriteMessages(ctx, msgs...); err != nil {
+		return fmt.Errorf("producing %d messages: %w", len(msgs), explainAuth(err, opWrite, topic))
+	}
 
-		dialer.TLS = &tls.Config{}
-		return dialer, nil
+	return nil
+}
 
-	default:
-		return nil, fmt.Errorf("unsupported kafka security protocol")
+// RecordErrors returns the error for each record of a ProduceBatch that
+// failed for some records only, nil for the records that were written. It
+// returns nil if the batch failed as a whole, as it does when the
+// credentials aren't authorized to write.
+func RecordErrors(err error) []error {
+	if _, ok := authCode(err); ok {
+		return nil
+	}
+	var writeErrs kafka.WriteErrors
+	if errors.As(err, &writeErrs) {
+		return writeErrs
 	}
+	return nil
 }
 
-// Produce sends a message to the specified topic.
-// The value should be Avro binary data (without wire format header).
-// schemaID is used to prepend the Schema Registry wire format header.
-func (p *Producer) Produce(ctx context.Context, topic string, schemaID int, key, value []byte) error {
+// message builds the Kafka message for a record, wrapped by the topic's
+// serializer plugin if one is configured
+func (p *Producer) message(topic string, schemaID int, r Record) (kafka.Message, error) {
+	msg := wireMessage(topic, schemaID, r)
+	if p.cfg.Unframed() {
+		msg.Value = r.Value
+	}
+	if s := plugin.ForTopic(p.cfg, topic); s != nil {
+		key, value, err := s.Encode(topic, msg.Key, msg.Value, r.Headers)
+		if err != nil {
+			return msg, err
+		}
+		msg.Key, msg.Value = key, value
+	}
+	return msg, nil
+}
+
+func wireMessage(topic string, schemaID int, r Record) kafka.Message {
 	// Prepend Schema Registry wire format:
 	// - Magic byte (0x00)
 	// - Schema ID (4 bytes, big-endian)
-	wireValue := make([]byte, 5+len(value))
+	wireValue := make([]byte, 5+len(r.Value))
 	wireValue[0] = 0x00 // Magic byte
 	binary.BigEndian.PutUint32(wireValue[1:5], uint32(schemaID))
-	copy(wireValue[5:], value)
+	copy(wireValue[5:], r.Value)
 
 	msg := kafka.Message{
 		Topic: topic,
 		Value: wireValue,
 	}
 
-	if key != nil {
-		msg.Key = key
+	if r.Key != nil {
+		msg.Key = r.Key
 	}
 
-	if err := p.writer.WriteMessages(ctx, msg); err != nil {
-		return fmt.Errorf("producing message: %w", err)
+	for k, v := range r.Headers {
+		msg.Headers = append(msg.Headers, kafka.Header{Key: k, Value: []byte(v)})
 	}
 
-	return nil
+	return msg
+}
+
+// ProduceRecord sends one message whose key is already encoded, such as a
+// key in wire format for a topic with a key schema
+func (p *Producer) ProduceRecord(ctx context.Context, topic string, schemaID int, r Record) error {
+	return p.produce(ctx, topic, schemaID, r.Key, r.Value, r.Headers)
 }
 
 // ProduceWithStringKey sends a message with a string key.
@@ -106,10 +169,21 @@ func (p *Producer) ProduceWithStringKey(ctx context.Context, topic string, schem
 	return p.Produce(ctx, topic, schemaID, keyBytes, value)
 }
 
+// ProduceWithHeaders sends a message with a string key and headers.
+func (p *Producer) ProduceWithHeaders(ctx context.Context, topic string, schemaID int, key string, value []byte, headers map[string]string) error {
+	var keyBytes []byte
+	if key != "" {
+		keyBytes = []byte(key)
+	}
+	return p.produce(ctx, topic, schemaID, keyBytes, value, headers)
+}
+
 // Close closes the producer.
 func (p *Producer) Close() error {
-	if p.writer != nil {
-		return p.writer.Close()
+	if p.writer == nil {
+		return nil
 	}
-	return nil
+	// The writer leaves a transport it didn't create open
+	defer p.transport.CloseIdleConnections()
+	return p.writer.Close()
 }
@@ -0,0 +1,199 @@
+package kafka
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"strconv"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+)
+
+// TopicConfigNames are the topic configs shown, in order: the ones that
+// come up when debugging retention and message size
+var TopicConfigNames = []string{
+	"retention.ms",
+	"retention.bytes",
+	"cleanup.policy",
+	"max.message.bytes",
+	"compression.type",
+	"min.insync.replicas",
+}
+
+// editableConfigs are the topic configs that may be changed
+var editableConfigs = map[string]bool{
+	"retention.ms":      true,
+	"retention.bytes":   true,
+	"cleanup.policy":    true,
+	"max.message.bytes": true,
+	"compression.type":  true,
+}
+
+// TopicConfig is one config of a topic
+type TopicConfig struct {
+	Name     string
+	Value    string
+	Default  bool // Not set on the topic, so the broker or cluster default applies
+	Editable bool
+}
+
+// DescribeTopicConfigs returns the topic's TopicConfigNames configs
+func DescribeTopicConfigs(ctx context.Context, cfg *config.Config, topic string) (_ []TopicConfig, err error) {
+	defer metrics.Observe(metrics.Kafka+" describe configs", time.Now(), &err)
+	client, addr, err := adminClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.DescribeConfigs(ctx, &kafka.DescribeConfigsRequest{
+		Addr: addr,
+		Resources: []kafka.DescribeConfigRequestResource{{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: topic,
+			ConfigNames:  TopicConfigNames,
+		}},
+	})
+	if err != nil {
+		return nil, fmt.Errorf("describing configs of %s: %w", topic, explainAuth(err, opDescribeConfigs, topic))
+	}
+	if len(res.Resources) == 0 {
+		return nil, fmt.Errorf("describing configs of %s: no configs returned", topic)
+	}
+	if err := res.Resources[0].Error; err != nil {
+		return nil, fmt.Errorf("describing configs of %s: %w", topic, explainAuth(err, opDescribeConfigs, topic))
+	}
+
+	entries := make(map[string]kafka.DescribeConfigResponseConfigEntry)
+	for _, e := range res.Resources[0].ConfigEntries {
+		entries[e.ConfigName] = e
+	}
+	var configs []TopicConfig
+	for _, name := range TopicConfigNames {
+		e, ok := entries[name]
+		if !ok {
+			continue
+		}
+		configs = append(configs, TopicConfig{
+			Name:     name,
+			Value:    e.ConfigValue,
+			Default:  e.IsDefault || (e.ConfigSource > 0 && e.ConfigSource != configSourceTopic),
+			Editable: editableConfigs[name] && !e.ReadOnly,
+		})
+	}
+	return configs, nil
+}
+
+// configSourceTopic is the DescribeConfigs source of a config set on the
+// topic itself. Older brokers report no source, only whether it's a default.
+const configSourceTopic = 1
+
+// CheckTopicConfig checks a value for one of the editable topic configs
+func CheckTopicConfig(name, value string) error {
+	if !editableConfigs[name] {
+		return fmt.Errorf("%s can't be changed here", name)
+	}
+	switch name {
+	case "retention.ms", "retention.bytes":
+		n, err := strconv.ParseInt(value, 10, 64)
+		if err != nil || n < -1 {
+			return fmt.Errorf("%s must be a whole number, or -1 for no limit", name)
+		}
+	case "max.message.bytes":
+		n, err := strconv.ParseInt(value, 10, 32)
+		if err != nil || n < 0 {
+			return fmt.Errorf("%s must be a whole number of bytes", name)
+		}
+	case "cleanup.policy":
+		for _, policy := range strings.Split(value, ",") {
+			if policy != "delete" && policy != "compact" {
+				return fmt.Errorf("cleanup.policy must be delete, compact or compact,delete")
+			}
+		}
+	case "compression.type":
+		types := []string{"producer", "uncompressed", "gzip", "snappy", "lz4", "zstd"}
+		if !slices.Contains(types, value) {
+			return fmt.Errorf("compression.type must be one of %s", strings.Join(types, ", "))
+		}
+	}
+	return nil
+}
+
+// SetTopicConfig sets one of the editable configs on a topic
+func SetTopicConfig(ctx context.Context, cfg *config.Config, topic, name, value string) (err error) {
+	defer metrics.Observe(metrics.Kafka+" alter configs", time.Now(), &err)
+	if err := CheckTopicConfig(name, value); err != nil {
+		return err
+	}
+	client, addr, err := adminClient(cfg)
+	if err != nil {
+		return err
+	}
+
+	res, err := client.IncrementalAlterConfigs(ctx, &kafka.IncrementalAlterConfigsRequest{
+		Addr: addr,
+		Resources: []kafka.IncrementalAlterConfigsRequestResource{{
+			ResourceType: kafka.ResourceTypeTopic,
+			ResourceName: topic,
+			Configs: []kafka.IncrementalAlterConfigsRequestConfig{{
+				Name:            name,
+				Value:           value,
+				ConfigOperation: kafka.ConfigOperationSet,
+			}},
+		}},
+	})
+	if err == nil && len(res.Resources) > 0 {
+		err = res.Resources[0].Error
+	}
+	if err != nil {
+		return fmt.Errorf("setting %s on %s: %w", name, topic, explainAuth(err, opAlterConfigs, topic))
+	}
+	return nil
+}
+
+// Quota is a client quota that applies to the profile's user
+type Quota struct {
+	Name  string // e.g. producer_byte_rate
+	Value float64
+}
+
+// DescribeUserQuotas returns the client quotas set for the profile's SASL
+// user. It returns nil without a SASL user.
+func DescribeUserQuotas(ctx context.Context, cfg *config.Config) (_ []Quota, err error) {
+	defer metrics.Observe(metrics.Kafka+" describe quotas", time.Now(), &err)
+	if cfg.KafkaSASLUsername == "" {
+		return nil, nil
+	}
+	client, addr, err := adminClient(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	res, err := client.DescribeClientQuotas(ctx, &kafka.DescribeClientQuotasRequest{
+		Addr: addr,
+		Components: []kafka.DescribeClientQuotasRequestComponent{{
+			EntityType: "user",
+			MatchType:  0, // Exact
+			Match:      cfg.KafkaSASLUsername,
+		}},
+	})
+	if err == nil {
+		err = res.Error
+	}
+	if err != nil {
+		return nil, fmt.Errorf("describing quotas: %w", explainAuth(err, opDescribe, ""))
+	}
+
+	var quotas []Quota
+	for _, entry := range res.Entries {
+		for _, v := range entry.Values {
+			quotas = append(quotas, Quota{Name: v.Key, Value: v.Value})
+		}
+	}
+	slices.SortFunc(quotas, func(a, b Quota) int { return strings.Compare(a.Name, b.Name) })
+	return quotas, nil
+}
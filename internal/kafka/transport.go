@@ -0,0 +1,125 @@
+package kafka
+
+import (
+	"crypto/tls"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/segmentio/kafka-go"
+	"github.com/segmentio/kafka-go/sasl"
+	"github.com/segmentio/kafka-go/sasl/plain"
+	"github.com/segmentio/kafka-go/sasl/scram"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// NewTransport returns the transport producers and admin clients reach the
+// brokers with: the profile's SASL mechanism and TLS settings, dialing
+// through its SSH tunnel if it has one. Consumers read with the dialer it is
+// built from, so both sides connect the same way.
+func NewTransport(cfg *config.Config) (*kafka.Transport, error) {
+	dialer, err := newDialer(cfg)
+	if err != nil {
+		return nil, err
+	}
+	return transportFor(dialer), nil
+}
+
+// newDialer returns the dialer the transport and consumers are built on,
+// set up for the profile's security protocol
+func newDialer(cfg *config.Config) (*kafka.Dialer, error) {
+	dialer := &kafka.Dialer{
+		Timeout:   10 * time.Second,
+		DualStack: true,
+		DialFunc:  cfg.DialContext,
+	}
+
+	switch strings.ToUpper(cfg.KafkaSecurityProtocol) {
+	case "", "PLAINTEXT":
+		return dialer, nil
+	case "SSL":
+		tlsCfg, err := newTLSConfig(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialer.TLS = tlsCfg
+		return dialer, nil
+	case "SASL_PLAINTEXT", "SASL_SSL":
+		mechanism, err := saslMechanism(cfg)
+		if err != nil {
+			return nil, err
+		}
+		dialer.SASLMechanism = mechanism
+
+		if strings.EqualFold(cfg.KafkaSecurityProtocol, "SASL_SSL") {
+			if dialer.TLS, err = newTLSConfig(cfg); err != nil {
+				return nil, err
+			}
+		}
+		return dialer, nil
+
+	default:
+		return nil, fmt.Errorf("unsupported kafka security protocol %q", cfg.KafkaSecurityProtocol)
+	}
+}
+
+// newTLSConfig returns the TLS settings for SSL and SASL_SSL: the profile's
+// CA and client certificate, if set, else the system roots
+func newTLSConfig(cfg *config.Config) (*tls.Config, error) {
+	tlsCfg, err := cfg.KafkaTLS.Build()
+	if err != nil {
+		return nil, fmt.Errorf("kafka tls: %w", err)
+	}
+	return tlsCfg, nil
+}
+
+// transportFor returns a transport that dials the brokers the way the
+// dialer does
+func transportFor(dialer *kafka.Dialer) *kafka.Transport {
+	return &kafka.Transport{
+		Dial:        dialer.DialFunc,
+		DialTimeout: dialer.Timeout,
+		SASL:        dialer.SASLMechanism,
+		TLS:         dialer.TLS,
+	}
+}
+
+// newClient returns a client for admin requests that dials the brokers the
+// way the dialer does
+func newClient(dialer *kafka.Dialer) *kafka.Client {
+	return &kafka.Client{Transport: transportFor(dialer)}
+}
+
+// saslMechanism builds the SASL mechanism selected by the profile: PLAIN
+// by default, SCRAM-SHA-256, SCRAM-SHA-512 or GSSAPI
+func saslMechanism(cfg *config.Config) (sasl.Mechanism, error) {
+	mechanism := strings.ToUpper(cfg.KafkaSASLMechanism)
+	switch mechanism {
+	case "", "PLAIN":
+		if cfg.KafkaSASLUsername == "" || cfg.KafkaSASLPassword == "" {
+			return nil, fmt.Errorf("SASL creds missing")
+		}
+		return plain.Mechanism{
+			Username: cfg.KafkaSASLUsername,
+			Password: cfg.KafkaSASLPassword,
+		}, nil
+	case "SCRAM-SHA-256", "SCRAM-SHA-512":
+		if cfg.KafkaSASLUsername == "" || cfg.KafkaSASLPassword == "" {
+			return nil, fmt.Errorf("SASL creds missing")
+		}
+		algorithm := scram.SHA256
+		if mechanism == "SCRAM-SHA-512" {
+			algorithm = scram.SHA512
+		}
+		m, err := scram.Mechanism(algorithm, cfg.KafkaSASLUsername, cfg.KafkaSASLPassword)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", mechanism, err)
+		}
+		return m, nil
+	case "GSSAPI":
+		return newGSSAPIMechanism(cfg.KafkaKerberos)
+	default:
+		return nil, fmt.Errorf("unsupported SASL mechanism %q", cfg.KafkaSASLMechanism)
+	}
+}
@@ -0,0 +1,57 @@
+// Package keys encodes message keys with their topic's key schema, the
+// same way for send mode and avrocado produce.
+package keys
+
+import (
+	"fmt"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// Schema returns the latest schema of a topic's key subject, as the naming
+// strategy names it, or nil if the topic has no key schema
+func Schema(cfg *config.Config, client *registry.Client, topic string) (*registry.SchemaResponse, error) {
+	subject := cfg.KeySubjectFor(topic)
+	if subject == "" {
+		return nil, nil
+	}
+	schema, err := client.GetLatestSchema(subject)
+	if registry.IsNotFound(err) {
+		return nil, nil
+	}
+	if err != nil {
+		return nil, fmt.Errorf("looking up key schema for %s: %w", topic, err)
+	}
+	return schema, nil
+}
+
+// Encode returns the bytes of a message key: encoded with the topic's key
+// schema behind its wire header if the topic has one, otherwise the raw
+// string. A key that isn't JSON is taken as a string. An empty key is sent
+// as no key, and an unframed one without the header.
+func Encode(schema *registry.SchemaResponse, key string, unframed bool) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+	if schema == nil {
+		return []byte(key), nil
+	}
+
+	var payload []byte
+	var err error
+	if schema.IsJSONSchema() {
+		payload, err = jsonschema.EncodeKey(schema.Schema, key)
+	} else {
+		payload, err = avro.EncodeKey(schema.Schema, key)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("key doesn't fit %s v%d: %w", schema.Subject, schema.Version, err)
+	}
+	if unframed {
+		return payload, nil
+	}
+	return avro.JoinWireFormat(schema.ID, payload), nil
+}
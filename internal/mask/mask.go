@@ -0,0 +1,164 @@
+// Package mask hides the values of sensitive fields in decoded messages, so
+// they can be shown or exported without leaking customer data.
+package mask
+
+import (
+	"encoding/json"
+	"path"
+	"strings"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// Placeholder replaces masked string values
+const Placeholder = "****"
+
+// Masker masks fields by name, or by the confluent:tags they carry in a
+// schema. A nil Masker masks nothing.
+type Masker struct {
+	patterns []string        // Lowercase field name patterns
+	tagged   map[string]bool // Names of fields carrying a masked tag
+}
+
+// New returns a masker for documents of a schema, or nil if nothing in the
+// configuration applies
+func New(cfg config.MaskConfig, schema string) *Masker {
+	m := &Masker{tagged: make(map[string]bool)}
+	for _, p := range cfg.Fields {
+		m.patterns = append(m.patterns, strings.ToLower(p))
+	}
+
+	if len(cfg.Tags) > 0 {
+		tags := make(map[string]bool, len(cfg.Tags))
+		for _, t := range cfg.Tags {
+			tags[t] = true
+		}
+		var parsed interface{}
+		if json.Unmarshal([]byte(schema), &parsed) == nil {
+			collectTagged(parsed, tags, m.tagged)
+		}
+	}
+
+	if len(m.patterns) == 0 && len(m.tagged) == 0 {
+		return nil
+	}
+	return m
+}
+
+// collectTagged records the names of record fields carrying one of tags,
+// anywhere in a parsed schema
+func collectTagged(schema interface{}, tags, tagged map[string]bool) {
+	switch s := schema.(type) {
+	case []interface{}:
+		for _, item := range s {
+			collectTagged(item, tags, tagged)
+		}
+	case map[string]interface{}:
+		if fieldTags, ok := s["confluent:tags"].([]interface{}); ok {
+			if name, ok := s["name"].(string); ok {
+				for _, t := range fieldTags {
+					if t, ok := t.(string); ok && tags[t] {
+						tagged[name] = true
+					}
+				}
+			}
+		}
+		for _, v := range s {
+			collectTagged(v, tags, tagged)
+		}
+	}
+}
+
+// Masks reports whether a field is masked
+func (m *Masker) Masks(field string) bool {
+	if m == nil {
+		return false
+	}
+	if m.tagged[field] {
+		return true
+	}
+	field = strings.ToLower(field)
+	for _, p := range m.patterns {
+		if ok, _ := path.Match(p, field); ok {
+			return true
+		}
+	}
+	return false
+}
+
+// Apply returns a copy of a decoded document with masked fields' values
+// hidden. Fields are matched by name at any depth. Values keep their type
+// where they can, so masked documents still fit their schema: strings
+// become the placeholder, numbers zero and containers have their contents
+// masked.
+func (m *Masker) Apply(doc interface{}) interface{} {
+	if m == nil {
+		return doc
+	}
+	return m.replace(doc, hide)
+}
+
+// replace copies a document, passing the values of masked fields through fn
+func (m *Masker) replace(doc interface{}, fn func(interface{}) interface{}) interface{} {
+	switch v := doc.(type) {
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			if m.Masks(k) {
+				out[k] = fn(item)
+			} else {
+				out[k] = m.replace(item, fn)
+			}
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = m.replace(item, fn)
+		}
+		return out
+	}
+	return doc
+}
+
+// hide masks a value and everything inside it
+func hide(v interface{}) interface{} {
+	switch v := v.(type) {
+	case nil:
+		return nil
+	case string:
+		return Placeholder
+	case []byte:
+		return []byte(Placeholder)
+	case bool:
+		return false
+	case float64:
+		return float64(0)
+	case float32:
+		return float32(0)
+	case int:
+		return 0
+	case int32:
+		return int32(0)
+	case int64:
+		return int64(0)
+	case json.Number:
+		return json.Number("0")
+	case time.Time:
+		return time.Unix(0, 0).UTC()
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = hide(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = hide(item)
+		}
+		return out
+	}
+	return Placeholder
+}
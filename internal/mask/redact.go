@@ -0,0 +1,93 @@
+package mask
+
+import (
+	"crypto/hmac"
+	crand "crypto/rand"
+	"crypto/sha256"
+	"encoding/json"
+	"math/rand/v2"
+)
+
+// Redactor replaces masked fields with fakes that keep the format of the
+// real values: every letter becomes a random letter of the same case and
+// every digit a random digit, so emails, phone numbers and IDs still look
+// like emails, phone numbers and IDs. Equal values get equal fakes, so
+// references between fields survive, but fakes can't be traced back to the
+// real values across redactors.
+type Redactor struct {
+	masker *Masker
+	salt   []byte
+}
+
+// NewRedactor returns a redactor for a masker's fields, or nil if the
+// masker is nil
+func NewRedactor(m *Masker) *Redactor {
+	if m == nil {
+		return nil
+	}
+	salt := make([]byte, 32)
+	crand.Read(salt)
+	return &Redactor{masker: m, salt: salt}
+}
+
+// Redact returns a copy of a document with masked fields faked. Parse
+// documents with json.Decoder.UseNumber so numbers keep their digits.
+func (r *Redactor) Redact(doc interface{}) interface{} {
+	if r == nil {
+		return doc
+	}
+	return r.masker.replace(doc, r.fake)
+}
+
+func (r *Redactor) fake(v interface{}) interface{} {
+	switch v := v.(type) {
+	case string:
+		return r.fakeText(v, false)
+	case json.Number:
+		return json.Number(r.fakeText(string(v), true))
+	case map[string]interface{}:
+		out := make(map[string]interface{}, len(v))
+		for k, item := range v {
+			out[k] = r.fake(item)
+		}
+		return out
+	case []interface{}:
+		out := make([]interface{}, len(v))
+		for i, item := range v {
+			out[i] = r.fake(item)
+		}
+		return out
+	}
+	return hide(v)
+}
+
+// fakeText swaps letters and digits for random ones seeded by the value.
+// Numbers keep a non-zero leading digit and their exponent.
+func (r *Redactor) fakeText(s string, number bool) string {
+	mac := hmac.New(sha256.New, r.salt)
+	mac.Write([]byte(s))
+	var seed [32]byte
+	copy(seed[:], mac.Sum(nil))
+	rng := rand.New(rand.NewChaCha8(seed))
+
+	out := []rune(s)
+	leading := true
+	for i, c := range out {
+		switch {
+		case number && (c == 'e' || c == 'E'):
+			return string(out)
+		case c >= '0' && c <= '9':
+			d := rng.IntN(10)
+			if number && leading && c != '0' {
+				d = 1 + rng.IntN(9)
+			}
+			out[i] = rune('0' + d)
+			leading = false
+		case c >= 'a' && c <= 'z':
+			out[i] = rune('a' + rng.IntN(26))
+		case c >= 'A' && c <= 'Z':
+			out[i] = rune('A' + rng.IntN(26))
+		}
+	}
+	return string(out)
+}
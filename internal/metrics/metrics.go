@@ -0,0 +1,153 @@
+// Package metrics counts and times registry calls, Kafka operations and UI
+// work in-process, so a slow session can be attributed to one of them.
+package metrics
+
+import (
+	"fmt"
+	"io"
+	"net"
+	"net/http"
+	"sort"
+	"strings"
+	"sync"
+	"time"
+)
+
+// Operation groups, the first word of every operation name
+const (
+	Registry = "registry"
+	Kafka    = "kafka"
+	UI       = "ui"
+)
+
+// bounds are the upper bounds, in seconds, of the latency histogram buckets
+var bounds = []float64{0.001, 0.005, 0.01, 0.025, 0.05, 0.1, 0.25, 0.5, 1, 2.5, 5, 10}
+
+// Stat is what has been recorded for one operation
+type Stat struct {
+	Name    string // "<group> <operation>", e.g. "kafka fetch"
+	Count   int64
+	Errors  int64
+	Total   time.Duration
+	Max     time.Duration
+	Buckets []int64 // Observations per bound, cumulative; the last is +Inf
+}
+
+// Group returns the part of the system the operation belongs to
+func (s Stat) Group() string {
+	group, _, _ := strings.Cut(s.Name, " ")
+	return group
+}
+
+// Mean returns the average latency
+func (s Stat) Mean() time.Duration {
+	if s.Count == 0 {
+		return 0
+	}
+	return s.Total / time.Duration(s.Count)
+}
+
+// Quantile estimates a latency quantile (0.95 for p95) from the histogram:
+// the bound of the bucket it falls in, or Max beyond the last bound
+func (s Stat) Quantile(q float64) time.Duration {
+	rank := int64(q * float64(s.Count))
+	for i, n := range s.Buckets[:len(bounds)] {
+		if n > rank {
+			return min(time.Duration(bounds[i]*float64(time.Second)), s.Max)
+		}
+	}
+	return s.Max
+}
+
+var (
+	mu    sync.Mutex
+	stats = make(map[string]*Stat)
+)
+
+// Observe records one operation that began at start. Use it as
+// defer metrics.Observe(name, time.Now(), &err) to capture the result.
+func Observe(name string, start time.Time, err *error) {
+	elapsed := time.Since(start)
+	failed := err != nil && *err != nil
+
+	mu.Lock()
+	defer mu.Unlock()
+	s, ok := stats[name]
+	if !ok {
+		s = &Stat{Name: name, Buckets: make([]int64, len(bounds)+1)}
+		stats[name] = s
+	}
+	s.Count++
+	if failed {
+		s.Errors++
+	}
+	s.Total += elapsed
+	s.Max = max(s.Max, elapsed)
+	for i, b := range bounds {
+		if elapsed.Seconds() <= b {
+			s.Buckets[i]++
+		}
+	}
+	s.Buckets[len(bounds)]++
+}
+
+// Snapshot returns a copy of every operation's stats, sorted by name
+func Snapshot() []Stat {
+	mu.Lock()
+	defer mu.Unlock()
+	snapshot := make([]Stat, 0, len(stats))
+	for _, s := range stats {
+		c := *s
+		c.Buckets = append([]int64(nil), s.Buckets...)
+		snapshot = append(snapshot, c)
+	}
+	sort.Slice(snapshot, func(i, j int) bool { return snapshot[i].Name < snapshot[j].Name })
+	return snapshot
+}
+
+// WritePrometheus writes the stats in the Prometheus text exposition format
+func WritePrometheus(w io.Writer) error {
+	snapshot := Snapshot()
+	var b strings.Builder
+
+	b.WriteString("# HELP avrocado_operation_duration_seconds Latency of registry calls, Kafka operations and UI updates.\n")
+	b.WriteString("# TYPE avrocado_operation_duration_seconds histogram\n")
+	for _, s := range snapshot {
+		group, op, _ := strings.Cut(s.Name, " ")
+		labels := fmt.Sprintf("group=%q,operation=%q", group, op)
+		for i, bound := range bounds {
+			fmt.Fprintf(&b, "avrocado_operation_duration_seconds_bucket{%s,le=\"%g\"} %d\n", labels, bound, s.Buckets[i])
+		}
+		fmt.Fprintf(&b, "avrocado_operation_duration_seconds_bucket{%s,le=\"+Inf\"} %d\n", labels, s.Count)
+		fmt.Fprintf(&b, "avrocado_operation_duration_seconds_sum{%s} %g\n", labels, s.Total.Seconds())
+		fmt.Fprintf(&b, "avrocado_operation_duration_seconds_count{%s} %d\n", labels, s.Count)
+	}
+
+	b.WriteString("# HELP avrocado_operation_errors_total Operations that returned an error.\n")
+	b.WriteString("# TYPE avrocado_operation_errors_total counter\n")
+	for _, s := range snapshot {
+		group, op, _ := strings.Cut(s.Name, " ")
+		fmt.Fprintf(&b, "avrocado_operation_errors_total{group=%q,operation=%q} %d\n", group, op, s.Errors)
+	}
+
+	_, err := io.WriteString(w, b.String())
+	return err
+}
+
+// Serve exposes the stats for Prometheus at http://addr/metrics until the
+// returned server is closed
+func Serve(addr string) (*http.Server, error) {
+	listener, err := net.Listen("tcp", addr)
+	if err != nil {
+		return nil, fmt.Errorf("metrics endpoint: %w", err)
+	}
+
+	mux := http.NewServeMux()
+	mux.HandleFunc("/metrics", func(w http.ResponseWriter, _ *http.Request) {
+		w.Header().Set("Content-Type", "text/plain; version=0.0.4")
+		WritePrometheus(w)
+	})
+	server := &http.Server{Handler: mux, ReadHeaderTimeout: 5 * time.Second}
+	go server.Serve(listener)
+	return server, nil
+}
@@ -0,0 +1,212 @@
+// Package plugin runs external serializer programs that wrap and unwrap
+// message keys and values, for envelopes avrocado doesn't understand
+// natively (encryption, compression, proprietary headers).
+//
+// A plugin is a long-running process speaking line-delimited JSON: each
+// request is one line on its stdin and each response one line on its
+// stdout. Requests look like
+//
+//	{"op": "decode", "topic": "orders", "key": "<base64>", "value": "<base64>", "headers": {"h": "v"}}
+//
+// with "op" either "decode" (consumed message to Schema Registry wire
+// format) or "encode" (wire format to what goes on the topic). "key" is only
+// sent when the plugin is configured with keys: true. The response carries
+// the transformed bytes, or an error for that message:
+//
+//	{"key": "<base64>", "value": "<base64>"}
+//	{"error": "bad envelope"}
+//
+// The plugin should exit when its stdin is closed.
+package plugin
+
+import (
+	"bufio"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"io"
+	"os/exec"
+	"sync"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// callTimeout bounds how long a plugin may take to answer one request
+const callTimeout = 10 * time.Second
+
+// Serializer is a running (or not yet started) plugin process
+type Serializer struct {
+	name string
+	cfg  config.SerializerConfig
+
+	mu     sync.Mutex
+	cmd    *exec.Cmd
+	stdin  io.WriteCloser
+	stdout *bufio.Reader
+}
+
+var (
+	runningMu sync.Mutex
+	running   = make(map[string]*Serializer)
+)
+
+// ForTopic returns the serializer configured for a topic, or nil. Callers
+// share one process per plugin.
+func ForTopic(cfg *config.Config, topic string) *Serializer {
+	name, sc, ok := cfg.SerializerFor(topic)
+	if !ok {
+		return nil
+	}
+
+	runningMu.Lock()
+	defer runningMu.Unlock()
+	key := name + "\x00" + sc.Command
+	if s, ok := running[key]; ok {
+		return s
+	}
+	s := &Serializer{name: name, cfg: sc}
+	running[key] = s
+	return s
+}
+
+// Decode unwraps a consumed key and value
+func (s *Serializer) Decode(topic string, key, value []byte, headers map[string]string) ([]byte, []byte, error) {
+	return s.call("decode", topic, key, value, headers)
+}
+
+// Encode wraps a key and wire-format value for producing
+func (s *Serializer) Encode(topic string, key, value []byte, headers map[string]string) ([]byte, []byte, error) {
+	return s.call("encode", topic, key, value, headers)
+}
+
+type request struct {
+	Op      string            `json:"op"`
+	Topic   string            `json:"topic"`
+	Key     []byte            `json:"key,omitempty"`
+	Value   []byte            `json:"value"`
+	Headers map[string]string `json:"headers,omitempty"`
+}
+
+type response struct {
+	Key   *string `json:"key"`
+	Value string  `json:"value"`
+	Error string  `json:"error"`
+}
+
+func (s *Serializer) call(op, topic string, key, value []byte, headers map[string]string) ([]byte, []byte, error) {
+	req := request{Op: op, Topic: topic, Value: value, Headers: headers}
+	if s.cfg.Keys {
+		req.Key = key
+	}
+	line, err := json.Marshal(req)
+	if err != nil {
+		return nil, nil, err
+	}
+
+	s.mu.Lock()
+	defer s.mu.Unlock()
+
+	if err := s.start(); err != nil {
+		return nil, nil, err
+	}
+
+	type result struct {
+		line []byte
+		err  error
+	}
+	done := make(chan result, 1)
+	go func() {
+		if _, err := s.stdin.Write(append(line, '\n')); err != nil {
+			done <- result{err: err}
+			return
+		}
+		out, err := s.stdout.ReadBytes('\n')
+		done <- result{line: out, err: err}
+	}()
+
+	var r result
+	select {
+	case r = <-done:
+	case <-time.After(callTimeout):
+		r.err = fmt.Errorf("no response within %s", callTimeout)
+	}
+	if r.err != nil {
+		// Restart the process on the next call rather than reading a
+		// response meant for this one
+		s.stop()
+		return nil, nil, fmt.Errorf("serializer %s: %w", s.name, r.err)
+	}
+
+	var resp response
+	if err := json.Unmarshal(r.line, &resp); err != nil {
+		s.stop()
+		return nil, nil, fmt.Errorf("serializer %s: invalid response: %w", s.name, err)
+	}
+	if resp.Error != "" {
+		return nil, nil, fmt.Errorf("serializer %s: %s", s.name, resp.Error)
+	}
+
+	newValue, err := base64.StdEncoding.DecodeString(resp.Value)
+	if err != nil {
+		return nil, nil, fmt.Errorf("serializer %s: value is not base64: %w", s.name, err)
+	}
+	newKey := key
+	if s.cfg.Keys && resp.Key != nil {
+		if newKey, err = base64.StdEncoding.DecodeString(*resp.Key); err != nil {
+			return nil, nil, fmt.Errorf("serializer %s: key is not base64: %w", s.name, err)
+		}
+	}
+	return newKey, newValue, nil
+}
+
+// start launches the process if it isn't running. Called with mu held.
+func (s *Serializer) start() error {
+	if s.cmd != nil {
+		return nil
+	}
+
+	cmd := exec.Command("sh", "-c", s.cfg.Command)
+	stdin, err := cmd.StdinPipe()
+	if err != nil {
+		return fmt.Errorf("serializer %s: %w", s.name, err)
+	}
+	stdout, err := cmd.StdoutPipe()
+	if err != nil {
+		return fmt.Errorf("serializer %s: %w", s.name, err)
+	}
+	if err := cmd.Start(); err != nil {
+		return fmt.Errorf("starting serializer %s: %w", s.name, err)
+	}
+
+	s.cmd = cmd
+	s.stdin = stdin
+	s.stdout = bufio.NewReader(stdout)
+	return nil
+}
+
+// stop kills the process. Called with mu held.
+func (s *Serializer) stop() {
+	if s.cmd == nil {
+		return
+	}
+	s.stdin.Close()
+	s.cmd.Process.Kill()
+	go s.cmd.Wait()
+	s.cmd = nil
+}
+
+// StopAll closes every running plugin process
+func StopAll() {
+	runningMu.Lock()
+	defer runningMu.Unlock()
+	for _, s := range running {
+		s.mu.Lock()
+		if s.cmd != nil {
+			s.stdin.Close()
+			go s.cmd.Wait()
+			s.cmd = nil
+		}
+		s.mu.Unlock()
+	}
+}
@@ -0,0 +1,203 @@
+// Package quality runs a topic's data quality rules over decoded messages
+// and reports which held: a spot check, not a monitor.
+package quality
+
+import (
+	"encoding/json"
+	"fmt"
+	"regexp"
+	"strconv"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/jsonpath"
+)
+
+// maxExamples caps the failing messages listed per rule
+const maxExamples = 5
+
+// Rule is a compiled data quality rule
+type Rule struct {
+	config.QualityRule
+	path  jsonpath.Path
+	regex *regexp.Regexp
+}
+
+// Compile parses the rules' paths and patterns
+func Compile(rules []config.QualityRule) ([]Rule, error) {
+	compiled := make([]Rule, 0, len(rules))
+	for _, r := range rules {
+		path, err := jsonpath.Parse(r.Field)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", r.Field, err)
+		}
+		rule := Rule{QualityRule: r, path: path}
+		if r.Regex != "" {
+			if rule.regex, err = regexp.Compile(r.Regex); err != nil {
+				return nil, fmt.Errorf("%s: regex: %w", r.Field, err)
+			}
+		}
+		compiled = append(compiled, rule)
+	}
+	return compiled, nil
+}
+
+// String describes the rule: its name, or its field and checks
+func (r Rule) String() string {
+	if r.Name != "" {
+		return r.Name
+	}
+	var checks []string
+	if r.NotNull {
+		checks = append(checks, "not null")
+	}
+	if r.Regex != "" {
+		checks = append(checks, "matches "+r.Regex)
+	}
+	switch {
+	case r.Min != nil && r.Max != nil:
+		checks = append(checks, "between "+formatNumber(*r.Min)+" and "+formatNumber(*r.Max))
+	case r.Min != nil:
+		checks = append(checks, ">= "+formatNumber(*r.Min))
+	case r.Max != nil:
+		checks = append(checks, "<= "+formatNumber(*r.Max))
+	}
+	return r.Field + " " + strings.Join(checks, ", ")
+}
+
+// Check returns why a decoded document breaks the rule, or "" if it holds.
+// A missing or null field only breaks not_null; the other checks skip it.
+// With redact set the reason leaves out the offending value.
+func (r Rule) Check(doc interface{}, redact bool) string {
+	value, ok := r.path.Get(doc)
+	if !ok || value == nil {
+		if r.NotNull {
+			if !ok {
+				return "missing"
+			}
+			return "null"
+		}
+		return ""
+	}
+
+	shown := "value"
+	if !redact {
+		shown = describe(value)
+	}
+	if r.regex != nil {
+		s, isString := value.(string)
+		if !isString {
+			return shown + " is not a string"
+		}
+		if !r.regex.MatchString(s) {
+			return shown + " does not match"
+		}
+	}
+	if r.Min != nil || r.Max != nil {
+		n, isNumber := value.(float64)
+		if !isNumber {
+			return shown + " is not a number"
+		}
+		if r.Min != nil && n < *r.Min {
+			return shown + " is below " + formatNumber(*r.Min)
+		}
+		if r.Max != nil && n > *r.Max {
+			return shown + " is above " + formatNumber(*r.Max)
+		}
+	}
+	return ""
+}
+
+// Message is a sampled message's decoded value and where it was read from
+type Message struct {
+	Partition int
+	Offset    int64
+	Doc       interface{}
+	Redact    bool // Leave the value out of failure reasons, for masked messages
+}
+
+// Failure is a message that broke a rule
+type Failure struct {
+	Partition int
+	Offset    int64
+	Reason    string
+}
+
+// Result is how a rule fared over the sampled messages
+type Result struct {
+	Rule     Rule
+	Passed   int
+	Failed   int
+	Examples []Failure // The first few failures
+}
+
+// Run checks every message against every rule
+func Run(rules []Rule, messages []Message) []Result {
+	results := make([]Result, len(rules))
+	for i, rule := range rules {
+		results[i].Rule = rule
+		for _, msg := range messages {
+			reason := rule.Check(msg.Doc, msg.Redact)
+			if reason == "" {
+				results[i].Passed++
+				continue
+			}
+			results[i].Failed++
+			if len(results[i].Examples) < maxExamples {
+				results[i].Examples = append(results[i].Examples, Failure{Partition: msg.Partition, Offset: msg.Offset, Reason: reason})
+			}
+		}
+	}
+	return results
+}
+
+// Format renders the results as a plain-text pass/fail report
+func Format(topic string, sampled int, results []Result) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d messages sampled from the end of the topic\n\n", topic, sampled)
+	if sampled == 0 {
+		b.WriteString("No messages to check.\n")
+		return b.String()
+	}
+
+	width := 0
+	for _, r := range results {
+		width = max(width, len(r.Rule.String()))
+	}
+
+	failed := 0
+	for _, r := range results {
+		status := "PASS"
+		if r.Failed > 0 {
+			status = "FAIL"
+			failed++
+		}
+		fmt.Fprintf(&b, "%s  %-*s  %d/%d\n", status, width, r.Rule, r.Passed, sampled)
+		for _, f := range r.Examples {
+			fmt.Fprintf(&b, "        partition %d offset %d: %s\n", f.Partition, f.Offset, f.Reason)
+		}
+		if more := r.Failed - len(r.Examples); more > 0 {
+			fmt.Fprintf(&b, "        ... and %d more\n", more)
+		}
+	}
+
+	fmt.Fprintf(&b, "\n%d rules, %d passed, %d failed\n", len(results), len(results)-failed, failed)
+	return b.String()
+}
+
+// describe renders a value for a failure reason, shortened if long
+func describe(value interface{}) string {
+	data, err := json.Marshal(value)
+	if err != nil {
+		return fmt.Sprint(value)
+	}
+	s := string(data)
+	if len(s) > 40 {
+		s = s[:39] + "…"
+	}
+	return s
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', 10, 64)
+}
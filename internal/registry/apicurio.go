@@ -0,0 +1,279 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"sort"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// apicurioPage is how many artifacts or versions are listed per request
+const apicurioPage = 500
+
+// apicurio holds the settings for Apicurio Registry's native v2 API, where
+// subjects are artifacts in a group and schema IDs are global or content IDs
+type apicurio struct {
+	group      string
+	contentIDs bool // IDs in the wire format are content IDs
+}
+
+// apiBase appends an API's path to a registry URL unless it names one
+func apiBase(baseURL, api string) string {
+	if strings.Contains(baseURL, "/apis/") {
+		return baseURL
+	}
+	return baseURL + api
+}
+
+func (c *Client) groupPath() string {
+	return "/groups/" + url.PathEscape(c.apicurio.group)
+}
+
+func (c *Client) artifactPath(subject string) string {
+	return c.groupPath() + "/artifacts/" + url.PathEscape(subject)
+}
+
+// rulesPath is the rules resource of an artifact, or the global one
+func (c *Client) rulesPath(subject string) string {
+	if subject == "" {
+		return "/admin/rules"
+	}
+	return c.artifactPath(subject) + "/rules"
+}
+
+// artifactMeta is the metadata Apicurio returns for an artifact version
+type artifactMeta struct {
+	ID        string `json:"id"`
+	Version   string `json:"version"`
+	Type      string `json:"type"`
+	GlobalID  int    `json:"globalId"`
+	ContentID int    `json:"contentId"`
+	CreatedOn string `json:"createdOn"`
+}
+
+// schemaID is the ID a version goes by in the wire format
+func (a *apicurio) schemaID(meta artifactMeta) int {
+	if a.contentIDs {
+		return meta.ContentID
+	}
+	return meta.GlobalID
+}
+
+func (c *Client) apicurioSubjects() ([]string, error) {
+	var subjects []string
+	for offset := 0; ; {
+		body, err := c.doRequest(http.MethodGet, fmt.Sprintf("%s/artifacts?limit=%d&offset=%d", c.groupPath(), apicurioPage, offset))
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Artifacts []artifactMeta `json:"artifacts"`
+			Count     int            `json:"count"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("parsing artifacts: %w", err)
+		}
+		for _, a := range page.Artifacts {
+			subjects = append(subjects, a.ID)
+		}
+		offset += len(page.Artifacts)
+		if len(page.Artifacts) == 0 || offset >= page.Count {
+			break
+		}
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+// apicurioVersions lists an artifact's numbered versions, oldest first.
+// Versions given custom names can't be addressed by number and are skipped.
+func (c *Client) apicurioVersions(subject string) ([]int, error) {
+	var versions []int
+	for offset := 0; ; {
+		body, err := c.doRequest(http.MethodGet, fmt.Sprintf("%s/versions?limit=%d&offset=%d", c.artifactPath(subject), apicurioPage, offset))
+		if err != nil {
+			return nil, err
+		}
+		var page struct {
+			Versions []artifactMeta `json:"versions"`
+			Count    int            `json:"count"`
+		}
+		if err := json.Unmarshal(body, &page); err != nil {
+			return nil, fmt.Errorf("parsing versions: %w", err)
+		}
+		for _, v := range page.Versions {
+			if n, err := strconv.Atoi(v.Version); err == nil {
+				versions = append(versions, n)
+			}
+		}
+		offset += len(page.Versions)
+		if len(page.Versions) == 0 || offset >= page.Count {
+			break
+		}
+	}
+	sort.Ints(versions)
+	return versions, nil
+}
+
+// apicurioVersion fetches a version of an artifact, the latest for an empty
+// version. The metadata is read first so the content matches it.
+func (c *Client) apicurioVersion(subject, version string) (*SchemaResponse, error) {
+	metaPath := c.artifactPath(subject) + "/meta"
+	if version != "" {
+		metaPath = c.artifactPath(subject) + "/versions/" + url.PathEscape(version) + "/meta"
+	}
+	body, err := c.doRequest(http.MethodGet, metaPath)
+	if err != nil {
+		return nil, err
+	}
+	var meta artifactMeta
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return nil, fmt.Errorf("parsing metadata: %w", err)
+	}
+
+	content, err := c.doRequest(http.MethodGet, c.artifactPath(subject)+"/versions/"+url.PathEscape(meta.Version))
+	if err != nil {
+		return nil, err
+	}
+
+	resp := &SchemaResponse{
+		Subject:    subject,
+		ID:         c.apicurio.schemaID(meta),
+		SchemaType: apicurioSchemaType(meta.Type, content),
+		Schema:     string(content),
+	}
+	resp.Version, _ = strconv.Atoi(meta.Version)
+	if created, ok := parseApicurioTime(meta.CreatedOn); ok {
+		resp.Metadata = &SchemaMetadata{Properties: map[string]string{"createdAt": created.Format(time.RFC3339)}}
+	}
+	return resp, nil
+}
+
+// apicurioByID fetches a schema by the ID in the wire format. Apicurio
+// returns only the content, so the type is inferred from it.
+func (c *Client) apicurioByID(id int) (*SchemaResponse, error) {
+	kind := "globalIds"
+	if c.apicurio.contentIDs {
+		kind = "contentIds"
+	}
+	content, err := c.doRequest(http.MethodGet, fmt.Sprintf("/ids/%s/%d", kind, id))
+	if err != nil {
+		return nil, err
+	}
+	return &SchemaResponse{ID: id, SchemaType: apicurioSchemaType("", content), Schema: string(content)}, nil
+}
+
+// apicurioRegister adds schema as the next version of an artifact, creating
+// it if needed. Apicurio returns the existing version for identical content.
+func (c *Client) apicurioRegister(subject, schema, schemaType string) (int, error) {
+	if schemaType == "" {
+		schemaType = SchemaTypeAvro
+	}
+	header := http.Header{}
+	header.Set("X-Registry-ArtifactId", subject)
+	header.Set("X-Registry-ArtifactType", schemaType)
+	if schemaType == "PROTOBUF" {
+		header.Set("Content-Type", "application/x-protobuf")
+	}
+	body, err := c.doRequestHeader(http.MethodPost, c.groupPath()+"/artifacts?ifExists=RETURN_OR_UPDATE", []byte(schema), header)
+	if err != nil {
+		return 0, err
+	}
+	var meta artifactMeta
+	if err := json.Unmarshal(body, &meta); err != nil {
+		return 0, fmt.Errorf("parsing response: %w", err)
+	}
+	return c.apicurio.schemaID(meta), nil
+}
+
+// apicurioDelete deletes an artifact with all its versions, which Apicurio
+// does not soft-delete
+func (c *Client) apicurioDelete(subject string) ([]int, error) {
+	versions, err := c.apicurioVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+	if _, err := c.doRequest(http.MethodDelete, c.artifactPath(subject)); err != nil {
+		return nil, err
+	}
+	return versions, nil
+}
+
+// compatibilityRule is an Apicurio COMPATIBILITY rule; its config holds the
+// level
+type compatibilityRule struct {
+	Type   string `json:"type"`
+	Config string `json:"config"`
+}
+
+// apicurioCompatibility reads a COMPATIBILITY rule. Without a global rule
+// Apicurio checks nothing, which is NONE.
+func (c *Client) apicurioCompatibility(subject string) (string, error) {
+	body, err := c.doRequest(http.MethodGet, c.rulesPath(subject)+"/COMPATIBILITY")
+	if IsNotFound(err) {
+		if subject == "" {
+			return "NONE", nil
+		}
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+	var rule compatibilityRule
+	if err := json.Unmarshal(body, &rule); err != nil {
+		return "", fmt.Errorf("parsing compatibility: %w", err)
+	}
+	return rule.Config, nil
+}
+
+// apicurioSetCompatibility updates a COMPATIBILITY rule, creating it if it
+// doesn't exist yet
+func (c *Client) apicurioSetCompatibility(subject, level string) error {
+	rule := compatibilityRule{Type: "COMPATIBILITY", Config: level}
+	_, err := c.doRequestBody(http.MethodPut, c.rulesPath(subject)+"/COMPATIBILITY", rule)
+	if IsNotFound(err) {
+		_, err = c.doRequestBody(http.MethodPost, c.rulesPath(subject), rule)
+	}
+	return err
+}
+
+// apicurioSchemaType maps an artifact type to the registry's schema types,
+// inferring it from the content when Apicurio doesn't say
+func apicurioSchemaType(artifactType string, content []byte) string {
+	switch artifactType {
+	case "":
+	case "JSON":
+		return SchemaTypeJSON
+	default:
+		return artifactType
+	}
+	var doc map[string]interface{}
+	if err := json.Unmarshal(content, &doc); err != nil {
+		if _, isArray := err.(*json.UnmarshalTypeError); !isArray {
+			return "PROTOBUF"
+		}
+		return SchemaTypeAvro
+	}
+	if _, ok := doc["$schema"]; ok {
+		return SchemaTypeJSON
+	}
+	if _, ok := doc["properties"]; ok {
+		return SchemaTypeJSON
+	}
+	return SchemaTypeAvro
+}
+
+// parseApicurioTime parses Apicurio's timestamps, which omit the colon in
+// the zone offset
+func parseApicurioTime(value string) (time.Time, bool) {
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04:05-0700", "2006-01-02T15:04:05.000-0700"} {
+		if t, err := time.Parse(layout, value); err == nil {
+			return t, true
+		}
+	}
+	return time.Time{}, false
+}
@@ -1,13 +1,19 @@
 package registry
 
 import (
+	"bytes"
 	"encoding/json"
+	"errors"
 	"fmt"
 	"io"
 	"net/http"
+	"net/url"
+	"strconv"
 	"strings"
+	"time"
 
 	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
 )
 
 type Client struct {
@@ -15,41 +21,161 @@ type Client struct {
 	httpClient *http.Client
 	apiKey     string
 	apiSecret  string
+	onThrottle func(Throttle)
+	tlsErr     error         // Why the profile's TLS settings couldn't be loaded
+	apicurio   *apicurio     // Set for Apicurio's native API
+	local      *localSchemas // Set for local schema files
 }
 
+// Schema types as the registry names them. An empty type means Avro.
+const (
+	SchemaTypeAvro = "AVRO"
+	SchemaTypeJSON = "JSON"
+)
+
 type SchemaResponse struct {
-	Subject    string `json:"subject"`
-	Version    int    `json:"version"`
-	ID         int    `json:"id"`
-	SchemaType string `json:"schemaType"`
-	Schema     string `json:"schema"`
+	Subject    string          `json:"subject"`
+	Version    int             `json:"version"`
+	ID         int             `json:"id"`
+	SchemaType string          `json:"schemaType"`
+	Schema     string          `json:"schema"`
+	Metadata   *SchemaMetadata `json:"metadata,omitempty"`
+	RuleSet    *RuleSet        `json:"ruleSet,omitempty"`
+}
+
+// RuleSet holds a schema's data contract rules (Confluent Schema Registry
+// 7.4+)
+type RuleSet struct {
+	DomainRules    []Rule `json:"domainRules,omitempty"`
+	MigrationRules []Rule `json:"migrationRules,omitempty"`
+}
+
+// Rule is one data contract rule, such as an ENCRYPT transform for fields
+// carrying a tag
+type Rule struct {
+	Name     string            `json:"name"`
+	Kind     string            `json:"kind"` // TRANSFORM or CONDITION
+	Mode     string            `json:"mode"` // WRITE, READ, WRITEREAD, ...
+	Type     string            `json:"type"` // e.g. ENCRYPT
+	Tags     []string          `json:"tags,omitempty"`
+	Params   map[string]string `json:"params,omitempty"`
+	Disabled bool              `json:"disabled,omitempty"`
+}
+
+// SchemaMetadata holds the optional metadata attached to a schema version
+// (Confluent Schema Registry 7.4+ data contracts)
+type SchemaMetadata struct {
+	Tags       map[string][]string `json:"tags,omitempty"`
+	Properties map[string]string   `json:"properties,omitempty"`
+}
+
+// createdAtProperties are metadata property names commonly used to record
+// when a schema version was registered
+var createdAtProperties = []string{"createdAt", "created_at", "created", "timestamp"}
+
+// CreatedAt returns the registration date recorded in the schema metadata, if any
+func (s *SchemaResponse) CreatedAt() (time.Time, bool) {
+	if s.Metadata == nil {
+		return time.Time{}, false
+	}
+	for _, name := range createdAtProperties {
+		value, ok := s.Metadata.Properties[name]
+		if !ok {
+			continue
+		}
+		if t, err := time.Parse(time.RFC3339, value); err == nil {
+			return t, true
+		}
+		if ms, err := strconv.ParseInt(value, 10, 64); err == nil {
+			return time.UnixMilli(ms), true
+		}
+	}
+	return time.Time{}, false
+}
+
+// APIError is returned for non-200 registry responses
+type APIError struct {
+	StatusCode int
+	Body       string
+	Method     string // The request, to name the permission a 403 needed
+	Path       string
+}
+
+func (e *APIError) Error() string {
+	if explanation := e.explain(); explanation != "" {
+		return explanation
+	}
+	return fmt.Sprintf("API error (status %d): %s", e.StatusCode, e.Body)
+}
+
+// IsNotFound reports whether err is a registry 404
+func IsNotFound(err error) bool {
+	var apiErr *APIError
+	return errors.As(err, &apiErr) && apiErr.StatusCode == http.StatusNotFound
 }
 
 func NewClient(cfg *config.Config) *Client {
-	return &Client{
+	httpClient := &http.Client{}
+	var tlsErr error
+	if cfg.DialContext != nil || cfg.RegistryTLS != nil {
+		transport := http.DefaultTransport.(*http.Transport).Clone()
+		if cfg.DialContext != nil {
+			transport.DialContext = cfg.DialContext
+		}
+		if cfg.RegistryTLS != nil {
+			transport.TLSClientConfig, tlsErr = cfg.RegistryTLS.Build()
+		}
+		httpClient.Transport = transport
+	}
+
+	c := &Client{
 		baseURL:    strings.TrimSuffix(cfg.RegistryURL, "/"),
-		httpClient: &http.Client{},
+		httpClient: httpClient,
 		apiKey:     cfg.APIKey,
 		apiSecret:  cfg.APISecret,
+		tlsErr:     tlsErr,
 	}
+	switch cfg.RegistryFlavor {
+	case config.FlavorApicurio:
+		c.baseURL = apiBase(c.baseURL, "/apis/ccompat/v7")
+	case config.FlavorApicurioV2:
+		c.baseURL = apiBase(c.baseURL, "/apis/registry/v2")
+		c.apicurio = &apicurio{group: cfg.RegistryGroup, contentIDs: cfg.RegistryIDType == config.IDTypeContent}
+		if c.apicurio.group == "" {
+			c.apicurio.group = config.DefaultApicurioGroup
+		}
+	case config.FlavorLocal:
+		c.local = &localSchemas{dir: cfg.LocalSchemaDir, schemaID: cfg.LocalSchemaID}
+	}
+	return c
 }
 
 func (c *Client) doRequest(method, path string) ([]byte, error) {
-	url := c.baseURL + path
-	req, err := http.NewRequest(method, url, nil)
-	if err != nil {
-		return nil, fmt.Errorf("creating request: %w", err)
-	}
+	return c.doRequestBody(method, path, nil)
+}
+
+// doRequestBody sends payload, if not nil, as the JSON request body
+func (c *Client) doRequestBody(method, path string, payload interface{}) ([]byte, error) {
+	return c.doRequestHeader(method, path, payload, nil)
+}
 
-	req.Header.Set("Accept", "application/vnd.schemaregistry.v1+json")
+// doRequestHeader is doRequestBody with extra request headers. A []byte
+// payload is sent as is.
+func (c *Client) doRequestHeader(method, path string, payload interface{}, header http.Header) (_ []byte, err error) {
+	defer metrics.Observe(operation(method, path), time.Now(), &err)
 
-	if c.apiKey != "" && c.apiSecret != "" {
-		req.SetBasicAuth(c.apiKey, c.apiSecret)
+	var data []byte
+	if raw, ok := payload.([]byte); ok {
+		data = raw
+	} else if payload != nil {
+		if data, err = json.Marshal(payload); err != nil {
+			return nil, fmt.Errorf("encoding request: %w", err)
+		}
 	}
 
-	resp, err := c.httpClient.Do(req)
+	resp, err := c.send(method, path, data, header)
 	if err != nil {
-		return nil, fmt.Errorf("executing request: %w", err)
+		return nil, err
 	}
 	defer resp.Body.Close()
 
@@ -58,14 +184,116 @@ func (c *Client) doRequest(method, path string) ([]byte, error) {
 		return nil, fmt.Errorf("reading response: %w", err)
 	}
 
-	if resp.StatusCode != http.StatusOK {
-		return nil, fmt.Errorf("API error (status %d): %s", resp.StatusCode, string(body))
+	// Apicurio answers deletes with 204
+	if resp.StatusCode != http.StatusOK && resp.StatusCode != http.StatusNoContent {
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body), Method: method, Path: path}
 	}
 
 	return body, nil
 }
 
+// doRequestStream is doRequest for large responses: the caller reads and
+// closes the returned body
+func (c *Client) doRequestStream(method, path string) (_ io.ReadCloser, err error) {
+	defer metrics.Observe(operation(method, path), time.Now(), &err)
+
+	resp, err := c.send(method, path, nil, nil)
+	if err != nil {
+		return nil, err
+	}
+	if resp.StatusCode != http.StatusOK {
+		defer resp.Body.Close()
+		body, _ := io.ReadAll(resp.Body)
+		return nil, &APIError{StatusCode: resp.StatusCode, Body: string(body), Method: method, Path: path}
+	}
+	return resp.Body, nil
+}
+
+// send performs a request with data, if not nil, as the JSON body, and any
+// extra headers. Throttled responses are waited out and retried; the caller
+// closes the body of the response returned.
+func (c *Client) send(method, path string, data []byte, header http.Header) (*http.Response, error) {
+	if c.local != nil {
+		return nil, c.local.check(method, path)
+	}
+	if c.tlsErr != nil {
+		return nil, fmt.Errorf("registry tls: %w", c.tlsErr)
+	}
+	for attempt := 1; ; attempt++ {
+		var reqBody io.Reader
+		if data != nil {
+			reqBody = bytes.NewReader(data)
+		}
+		req, err := http.NewRequest(method, c.baseURL+path, reqBody)
+		if err != nil {
+			return nil, fmt.Errorf("creating request: %w", err)
+		}
+
+		mediaType := "application/vnd.schemaregistry.v1+json"
+		if c.apicurio != nil {
+			mediaType = "application/json"
+		}
+		req.Header.Set("Accept", mediaType)
+		if data != nil {
+			req.Header.Set("Content-Type", mediaType)
+		}
+		for name, values := range header {
+			req.Header[name] = values
+		}
+
+		if c.apiKey != "" && c.apiSecret != "" {
+			req.SetBasicAuth(c.apiKey, c.apiSecret)
+		}
+
+		resp, err := c.httpClient.Do(req)
+		if err != nil {
+			return nil, fmt.Errorf("executing request: %w", err)
+		}
+
+		wait, throttled := throttleWait(resp, attempt)
+		if !throttled || attempt > maxThrottleRetries {
+			return resp, nil
+		}
+		io.Copy(io.Discard, resp.Body)
+		resp.Body.Close()
+
+		if c.onThrottle != nil {
+			c.onThrottle(Throttle{Operation: operation(method, path), Attempt: attempt, Wait: wait})
+		}
+		time.Sleep(wait)
+	}
+}
+
+// pathWords are the fixed parts of registry API paths. Other segments
+// (subjects, IDs, versions) are replaced in metrics names, so each subject
+// doesn't get its own entry.
+var pathWords = map[string]bool{
+	"subjects": true, "versions": true, "latest": true, "schemas": true, "ids": true,
+	"config": true, "mode": true, "compatibility": true, "exporters": true,
+	"dek-registry": true, "v1": true, "keks": true, "deks": true,
+	"groups": true, "artifacts": true, "meta": true, "globalIds": true,
+	"contentIds": true, "rules": true, "admin": true, "COMPATIBILITY": true,
+}
+
+// operation names a request for metrics, e.g. "registry GET /subjects/*/versions"
+func operation(method, path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, s := range segments {
+		if !pathWords[s] {
+			segments[i] = "*"
+		}
+	}
+	return metrics.Registry + " " + method + " /" + strings.Join(segments, "/")
+}
+
 func (c *Client) ListSubjects() ([]string, error) {
+	if c.local != nil {
+		return c.local.subjects()
+	}
+	if c.apicurio != nil {
+		return c.apicurioSubjects()
+	}
 	body, err := c.doRequest(http.MethodGet, "/subjects")
 	if err != nil {
 		return nil, err
@@ -79,7 +307,62 @@ func (c *Client) ListSubjects() ([]string, error) {
 	return subjects, nil
 }
 
+// StreamSubjects calls fn for each subject as the response is read, so
+// registries with huge subject lists needn't be held in memory at once
+func (c *Client) StreamSubjects(fn func(subject string) error) error {
+	if c.local != nil {
+		subjects, err := c.local.subjects()
+		if err != nil {
+			return err
+		}
+		for _, subject := range subjects {
+			if err := fn(subject); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	if c.apicurio != nil {
+		subjects, err := c.apicurioSubjects()
+		if err != nil {
+			return err
+		}
+		for _, subject := range subjects {
+			if err := fn(subject); err != nil {
+				return err
+			}
+		}
+		return nil
+	}
+	body, err := c.doRequestStream(http.MethodGet, "/subjects")
+	if err != nil {
+		return err
+	}
+	defer body.Close()
+
+	dec := json.NewDecoder(body)
+	if tok, err := dec.Token(); err != nil || tok != json.Delim('[') {
+		return fmt.Errorf("parsing subjects: expected a JSON array")
+	}
+	for dec.More() {
+		var subject string
+		if err := dec.Decode(&subject); err != nil {
+			return fmt.Errorf("parsing subjects: %w", err)
+		}
+		if err := fn(subject); err != nil {
+			return err
+		}
+	}
+	return nil
+}
+
 func (c *Client) GetLatestSchema(subject string) (*SchemaResponse, error) {
+	if c.local != nil {
+		return c.local.schema(subject, 1)
+	}
+	if c.apicurio != nil {
+		return c.apicurioVersion(subject, "")
+	}
 	path := fmt.Sprintf("/subjects/%s/versions/latest", subject)
 	body, err := c.doRequest(http.MethodGet, path)
 	if err != nil {
@@ -94,6 +377,195 @@ func (c *Client) GetLatestSchema(subject string) (*SchemaResponse, error) {
 	return &schema, nil
 }
 
+// ListVersions returns the registered version numbers for a subject
+func (c *Client) ListVersions(subject string) ([]int, error) {
+	if c.local != nil {
+		if _, err := c.local.schema(subject, 1); err != nil {
+			return nil, err
+		}
+		return []int{1}, nil
+	}
+	if c.apicurio != nil {
+		return c.apicurioVersions(subject)
+	}
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	body, err := c.doRequest(http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var versions []int
+	if err := json.Unmarshal(body, &versions); err != nil {
+		return nil, fmt.Errorf("parsing versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// GetSchemaRef fetches a schema written as subject, subject@latest or
+// subject@<version>
+func (c *Client) GetSchemaRef(ref string) (*SchemaResponse, error) {
+	subject, version, hasVersion := strings.Cut(ref, "@")
+	if !hasVersion || version == "latest" {
+		return c.GetLatestSchema(subject)
+	}
+	n, err := strconv.Atoi(version)
+	if err != nil {
+		return nil, fmt.Errorf("invalid version %q in %s", version, ref)
+	}
+	return c.GetSchemaVersion(subject, n)
+}
+
+// GetSchemaVersion fetches a specific version of a subject's schema
+func (c *Client) GetSchemaVersion(subject string, version int) (*SchemaResponse, error) {
+	if c.local != nil {
+		return c.local.schema(subject, version)
+	}
+	if c.apicurio != nil {
+		return c.apicurioVersion(subject, strconv.Itoa(version))
+	}
+	path := fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version)
+	body, err := c.doRequest(http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var schema SchemaResponse
+	if err := json.Unmarshal(body, &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+
+	return &schema, nil
+}
+
+// GetSchemaByID fetches the schema with a global ID, as embedded in
+// wire-format messages
+func (c *Client) GetSchemaByID(id int) (string, error) {
+	schema, err := c.GetSchemaInfoByID(id)
+	if err != nil {
+		return "", err
+	}
+	return schema.Schema, nil
+}
+
+// GetSchemaInfoByID fetches the schema with a global ID along with its
+// metadata and rules. Subject and version are not set.
+func (c *Client) GetSchemaInfoByID(id int) (*SchemaResponse, error) {
+	if c.apicurio != nil {
+		return c.apicurioByID(id)
+	}
+	body, err := c.doRequest(http.MethodGet, fmt.Sprintf("/schemas/ids/%d", id))
+	if err != nil {
+		return nil, err
+	}
+
+	var schema SchemaResponse
+	if err := json.Unmarshal(body, &schema); err != nil {
+		return nil, fmt.Errorf("parsing schema: %w", err)
+	}
+	schema.ID = id
+
+	return &schema, nil
+}
+
+// GetAllVersions fetches every version of a subject's schema, oldest first
+func (c *Client) GetAllVersions(subject string) ([]*SchemaResponse, error) {
+	versions, err := c.ListVersions(subject)
+	if err != nil {
+		return nil, err
+	}
+
+	schemas := make([]*SchemaResponse, 0, len(versions))
+	for _, v := range versions {
+		schema, err := c.GetSchemaVersion(subject, v)
+		if err != nil {
+			return nil, fmt.Errorf("fetching version %d: %w", v, err)
+		}
+		schemas = append(schemas, schema)
+	}
+
+	return schemas, nil
+}
+
+// RegisterSchema registers schema as a new version of subject and returns
+// its global ID. Registering a schema the subject already has returns the
+// existing ID without adding a version. schemaType is empty or AVRO for Avro
+// schemas.
+func (c *Client) RegisterSchema(subject, schema, schemaType string) (int, error) {
+	if c.apicurio != nil {
+		return c.apicurioRegister(subject, schema, schemaType)
+	}
+	path := fmt.Sprintf("/subjects/%s/versions", url.PathEscape(subject))
+	request := map[string]string{"schema": schema}
+	if schemaType != "" && schemaType != SchemaTypeAvro {
+		request["schemaType"] = schemaType
+	}
+	body, err := c.doRequestBody(http.MethodPost, path, request)
+	if err != nil {
+		return 0, err
+	}
+
+	var registered struct {
+		ID int `json:"id"`
+	}
+	if err := json.Unmarshal(body, &registered); err != nil {
+		return 0, fmt.Errorf("parsing registration: %w", err)
+	}
+
+	return registered.ID, nil
+}
+
+// DeleteSubject deletes every version of a subject and returns the deleted
+// version numbers. A soft delete keeps the schemas readable by ID, and the
+// subject can be registered again; permanent also removes them, soft
+// deleting first as the registry requires.
+func (c *Client) DeleteSubject(subject string, permanent bool) ([]int, error) {
+	if c.apicurio != nil {
+		return c.apicurioDelete(subject)
+	}
+	path := fmt.Sprintf("/subjects/%s", url.PathEscape(subject))
+	body, err := c.doRequest(http.MethodDelete, path)
+	// A subject already soft deleted is 404 until deleted permanently
+	if err != nil && !(permanent && IsNotFound(err)) {
+		return nil, err
+	}
+	if permanent {
+		if body, err = c.doRequest(http.MethodDelete, path+"?permanent=true"); err != nil {
+			return nil, err
+		}
+	}
+
+	var versions []int
+	if err := json.Unmarshal(body, &versions); err != nil {
+		return nil, fmt.Errorf("parsing deleted versions: %w", err)
+	}
+
+	return versions, nil
+}
+
+// DeleteSchemaVersion deletes one version of a subject, soft or permanently
+// as DeleteSubject does
+func (c *Client) DeleteSchemaVersion(subject string, version int, permanent bool) error {
+	if c.apicurio != nil {
+		_, err := c.doRequest(http.MethodDelete, c.artifactPath(subject)+"/versions/"+strconv.Itoa(version))
+		return err
+	}
+	path := fmt.Sprintf("/subjects/%s/versions/%d", url.PathEscape(subject), version)
+	_, err := c.doRequest(http.MethodDelete, path)
+	if err != nil && !(permanent && IsNotFound(err)) {
+		return err
+	}
+	if permanent {
+		_, err = c.doRequest(http.MethodDelete, path+"?permanent=true")
+	}
+	return err
+}
+
+// IsJSONSchema reports whether the schema is a JSON Schema rather than Avro
+func (s *SchemaResponse) IsJSONSchema() bool {
+	return s != nil && s.SchemaType == SchemaTypeJSON
+}
+
 func PrettyPrintSchema(schema string) string {
 	var parsed interface{}
 	if err := json.Unmarshal([]byte(schema), &parsed); err != nil {
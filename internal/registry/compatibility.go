@@ -0,0 +1,74 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// CompatibilityLevels are the compatibility levels a registry accepts, from
+// the most to the least strict
+var CompatibilityLevels = []string{
+	"FULL_TRANSITIVE",
+	"FULL",
+	"BACKWARD_TRANSITIVE",
+	"BACKWARD",
+	"FORWARD_TRANSITIVE",
+	"FORWARD",
+	"NONE",
+}
+
+// configPath is the config resource of a subject, or the global one
+func configPath(subject string) string {
+	if subject == "" {
+		return "/config"
+	}
+	return "/config/" + url.PathEscape(subject)
+}
+
+// GetCompatibility returns the compatibility level set on a subject, empty
+// if it has none and the global level applies. An empty subject returns the
+// global level.
+func (c *Client) GetCompatibility(subject string) (string, error) {
+	if c.apicurio != nil {
+		return c.apicurioCompatibility(subject)
+	}
+	body, err := c.doRequest(http.MethodGet, configPath(subject))
+	if subject != "" && IsNotFound(err) {
+		return "", nil
+	}
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		CompatibilityLevel string `json:"compatibilityLevel"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parsing compatibility: %w", err)
+	}
+
+	return resp.CompatibilityLevel, nil
+}
+
+// SetCompatibility sets the compatibility level of a subject, or the global
+// level for an empty subject
+func (c *Client) SetCompatibility(subject, level string) error {
+	if c.apicurio != nil {
+		return c.apicurioSetCompatibility(subject, level)
+	}
+	_, err := c.doRequestBody(http.MethodPut, configPath(subject), map[string]string{"compatibility": level})
+	return err
+}
+
+// DeleteCompatibility removes a subject's compatibility level, so the
+// global level applies to it again
+func (c *Client) DeleteCompatibility(subject string) error {
+	if c.apicurio != nil {
+		_, err := c.doRequest(http.MethodDelete, c.rulesPath(subject)+"/COMPATIBILITY")
+		return err
+	}
+	_, err := c.doRequest(http.MethodDelete, configPath(subject))
+	return err
+}
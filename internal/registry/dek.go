@@ -0,0 +1,77 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+)
+
+// Kek is a key encryption key registered with the DEK Registry. The key
+// itself stays in the KMS; the registry records where it lives.
+type Kek struct {
+	Name     string            `json:"name"`
+	KmsType  string            `json:"kmsType"`
+	KmsKeyID string            `json:"kmsKeyId"`
+	KmsProps map[string]string `json:"kmsProps,omitempty"`
+	Shared   bool              `json:"shared"`
+}
+
+// Dek is a data encryption key for a subject, stored encrypted by its KEK
+type Dek struct {
+	KekName              string `json:"kekName"`
+	Subject              string `json:"subject"`
+	Version              int    `json:"version"`
+	Algorithm            string `json:"algorithm"`
+	EncryptedKeyMaterial string `json:"encryptedKeyMaterial,omitempty"` // Base64
+	KeyMaterial          string `json:"keyMaterial,omitempty"`          // Base64, only for shared KEKs
+}
+
+// GetKek fetches a key encryption key by name
+func (c *Client) GetKek(name string) (*Kek, error) {
+	body, err := c.doRequest(http.MethodGet, "/dek-registry/v1/keks/"+url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var kek Kek
+	if err := json.Unmarshal(body, &kek); err != nil {
+		return nil, fmt.Errorf("parsing KEK %s: %w", name, err)
+	}
+	return &kek, nil
+}
+
+// GetDek fetches a version of a subject's data encryption key, or the
+// latest when version is 0
+func (c *Client) GetDek(kekName, subject string, version int, algorithm string) (*Dek, error) {
+	v := "latest"
+	if version > 0 {
+		v = fmt.Sprint(version)
+	}
+	path := fmt.Sprintf("/dek-registry/v1/keks/%s/deks/%s/versions/%s?algorithm=%s",
+		url.PathEscape(kekName), url.PathEscape(subject), v, url.QueryEscape(algorithm))
+	body, err := c.doRequest(http.MethodGet, path)
+	if err != nil {
+		return nil, err
+	}
+
+	var dek Dek
+	if err := json.Unmarshal(body, &dek); err != nil {
+		return nil, fmt.Errorf("parsing DEK for %s: %w", subject, err)
+	}
+	return &dek, nil
+}
+
+// CreateDek registers a new data encryption key for a subject
+func (c *Client) CreateDek(kekName string, dek Dek) (*Dek, error) {
+	body, err := c.doRequestBody(http.MethodPost, "/dek-registry/v1/keks/"+url.PathEscape(kekName)+"/deks", dek)
+	if err != nil {
+		return nil, err
+	}
+
+	var created Dek
+	if err := json.Unmarshal(body, &created); err != nil {
+		return nil, fmt.Errorf("parsing DEK for %s: %w", dek.Subject, err)
+	}
+	return &created, nil
+}
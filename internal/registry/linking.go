@@ -0,0 +1,164 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// Subject modes that reject new registrations
+const (
+	ModeImport   = "IMPORT"
+	ModeReadOnly = "READONLY"
+)
+
+// Exporter is a schema exporter (schema linking) configured on the registry
+type Exporter struct {
+	Name                string            `json:"name"`
+	ContextType         string            `json:"contextType"`
+	Context             string            `json:"context"`
+	Subjects            []string          `json:"subjects"`
+	SubjectRenameFormat string            `json:"subjectRenameFormat"`
+	Config              map[string]string `json:"config"`
+}
+
+// Destination returns the URL of the registry the exporter copies schemas to
+func (e *Exporter) Destination() string {
+	return e.Config["schema.registry.url"]
+}
+
+// Exports reports whether the exporter copies subject. Exporter subject
+// lists may use a trailing * as a prefix wildcard.
+func (e *Exporter) Exports(subject string) bool {
+	for _, pattern := range e.Subjects {
+		if prefix, ok := strings.CutSuffix(pattern, "*"); ok {
+			if strings.HasPrefix(subject, prefix) {
+				return true
+			}
+		} else if pattern == subject {
+			return true
+		}
+	}
+	return false
+}
+
+// Link describes how a subject takes part in schema linking
+type Link struct {
+	Mode      string      // Subject mode, IMPORT or READONLY when managed elsewhere
+	Source    string      // Where imported schemas come from, when known
+	Exporters []*Exporter // Exporters on this registry that copy the subject out
+}
+
+// ReadOnly reports whether registrations against the subject will be rejected
+func (l Link) ReadOnly() bool {
+	return l.Mode == ModeImport || l.Mode == ModeReadOnly
+}
+
+// ListExporters returns the names of the exporters configured on the registry
+func (c *Client) ListExporters() ([]string, error) {
+	body, err := c.doRequest(http.MethodGet, "/exporters")
+	if err != nil {
+		return nil, err
+	}
+
+	var names []string
+	if err := json.Unmarshal(body, &names); err != nil {
+		return nil, fmt.Errorf("parsing exporters: %w", err)
+	}
+
+	return names, nil
+}
+
+// GetExporter fetches an exporter's configuration
+func (c *Client) GetExporter(name string) (*Exporter, error) {
+	body, err := c.doRequest(http.MethodGet, "/exporters/"+url.PathEscape(name))
+	if err != nil {
+		return nil, err
+	}
+
+	var exporter Exporter
+	if err := json.Unmarshal(body, &exporter); err != nil {
+		return nil, fmt.Errorf("parsing exporter: %w", err)
+	}
+
+	return &exporter, nil
+}
+
+// GetMode returns a subject's mode, falling back to the global mode.
+// An empty subject returns the global mode.
+func (c *Client) GetMode(subject string) (string, error) {
+	path := "/mode"
+	if subject != "" {
+		path += "/" + url.PathEscape(subject) + "?defaultToGlobal=true"
+	}
+
+	body, err := c.doRequest(http.MethodGet, path)
+	if err != nil {
+		return "", err
+	}
+
+	var resp struct {
+		Mode string `json:"mode"`
+	}
+	if err := json.Unmarshal(body, &resp); err != nil {
+		return "", fmt.Errorf("parsing mode: %w", err)
+	}
+
+	return resp.Mode, nil
+}
+
+// Links works out which subjects are managed by schema linking, from the
+// global mode and the registry's exporters. Registries without the exporter
+// API (open-source Schema Registry) are treated as having no exporters.
+// Per-subject modes are not fetched here; see GetMode.
+func (c *Client) Links(subjects []string) (map[string]Link, error) {
+	globalMode, err := c.GetMode("")
+	if err != nil && !IsNotFound(err) {
+		return nil, fmt.Errorf("fetching mode: %w", err)
+	}
+
+	var exporters []*Exporter
+	names, err := c.ListExporters()
+	if err != nil && !IsNotFound(err) {
+		return nil, fmt.Errorf("listing exporters: %w", err)
+	}
+	for _, name := range names {
+		exporter, err := c.GetExporter(name)
+		if err != nil {
+			return nil, fmt.Errorf("fetching exporter %s: %w", name, err)
+		}
+		exporters = append(exporters, exporter)
+	}
+
+	links := make(map[string]Link)
+	for _, subject := range subjects {
+		link := Link{Mode: globalMode}
+		for _, exporter := range exporters {
+			if exporter.Exports(subject) {
+				link.Exporters = append(link.Exporters, exporter)
+			}
+		}
+		if link.ReadOnly() {
+			link.Source = LinkSource(subject)
+		}
+		if link.ReadOnly() || len(link.Exporters) > 0 {
+			links[subject] = link
+		}
+	}
+
+	return links, nil
+}
+
+// LinkSource describes where an imported subject comes from. Schema linking
+// places imported subjects in a context (":.context:subject") named after
+// the source cluster by default.
+func LinkSource(subject string) string {
+	if strings.HasPrefix(subject, ":.") {
+		if end := strings.Index(subject[2:], ":"); end > 0 {
+			return "context " + subject[1:end+2]
+		}
+	}
+	return "a linked registry"
+}
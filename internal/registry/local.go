@@ -0,0 +1,92 @@
+package registry
+
+import (
+	"fmt"
+	"io/fs"
+	"net/http"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+)
+
+// localSchemas serves the .avsc files under a directory in place of a
+// registry. Each file is a subject named after it, with a single version.
+type localSchemas struct {
+	dir      string
+	schemaID int // The ID every schema is given
+}
+
+// files maps each subject to its file, searching the directory recursively
+func (l *localSchemas) files() (map[string]string, error) {
+	files := make(map[string]string)
+	err := filepath.WalkDir(l.dir, func(path string, d fs.DirEntry, err error) error {
+		if err != nil {
+			return err
+		}
+		if d.IsDir() {
+			if path != l.dir && strings.HasPrefix(d.Name(), ".") {
+				return filepath.SkipDir
+			}
+			return nil
+		}
+		if filepath.Ext(path) != ".avsc" {
+			return nil
+		}
+		subject := strings.TrimSuffix(d.Name(), ".avsc")
+		if other, ok := files[subject]; ok {
+			return fmt.Errorf("%s and %s are both subject %s", other, path, subject)
+		}
+		files[subject] = path
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading local schemas: %w", err)
+	}
+	return files, nil
+}
+
+func (l *localSchemas) subjects() ([]string, error) {
+	files, err := l.files()
+	if err != nil {
+		return nil, err
+	}
+	subjects := make([]string, 0, len(files))
+	for subject := range files {
+		subjects = append(subjects, subject)
+	}
+	sort.Strings(subjects)
+	return subjects, nil
+}
+
+// schema reads a subject's file, as version 1
+func (l *localSchemas) schema(subject string, version int) (*SchemaResponse, error) {
+	files, err := l.files()
+	if err != nil {
+		return nil, err
+	}
+	path, ok := files[subject]
+	if !ok || version > 1 {
+		return nil, l.notFound(http.MethodGet, subject)
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, fmt.Errorf("reading %s: %w", path, err)
+	}
+	return &SchemaResponse{Subject: subject, Version: 1, ID: l.schemaID, SchemaType: SchemaTypeAvro, Schema: string(data)}, nil
+}
+
+// notFound is the error for what local schemas don't have, so callers
+// treat it as a registry without it
+func (l *localSchemas) notFound(method, path string) error {
+	return &APIError{StatusCode: http.StatusNotFound, Body: fmt.Sprintf("not in local schemas (%s)", l.dir), Method: method, Path: path}
+}
+
+// check refuses requests that would change the registry, as local schemas
+// are changed by editing their files
+func (l *localSchemas) check(method, path string) error {
+	if method == http.MethodGet {
+		return l.notFound(method, path)
+	}
+	return fmt.Errorf("local schemas in %s are read-only here; edit the .avsc files instead", l.dir)
+}
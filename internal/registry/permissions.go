@@ -0,0 +1,101 @@
+package registry
+
+import (
+	"encoding/json"
+	"fmt"
+	"net/http"
+	"net/url"
+	"strings"
+)
+
+// registryError is the body the registry sends with an error status
+type registryError struct {
+	ErrorCode int    `json:"error_code"`
+	Message   string `json:"message"`
+}
+
+// explain describes a 401 or 403 response in terms of the credentials or
+// the permission the request needed, and a rate or schema limit in terms of
+// what to do about it. Returns "" for other errors.
+func (e *APIError) explain() string {
+	detail := e.Body
+	var body registryError
+	if json.Unmarshal([]byte(e.Body), &body) == nil && body.Message != "" {
+		detail = body.Message
+	}
+
+	switch e.StatusCode {
+	case http.StatusUnauthorized:
+		return fmt.Sprintf("the registry rejected the credentials (401: %s); check the profile's api_key and api_secret, and that the key belongs to this Schema Registry rather than the Kafka cluster", detail)
+	case http.StatusForbidden:
+		permission := requiredPermission(e.Method, e.Path)
+		if permission == "" {
+			return fmt.Sprintf("your API key lacks the permission for %s %s (403: %s)", e.Method, e.Path, detail)
+		}
+		return fmt.Sprintf("your API key lacks %s (403: %s)", permission, detail)
+	case http.StatusTooManyRequests:
+		return fmt.Sprintf("the registry is still throttling requests after %d retries (429: %s); try again later or spread bulk operations out", maxThrottleRetries, detail)
+	}
+	if isSchemaLimit(e) {
+		return fmt.Sprintf("the registry's schema limit is reached (%d: %s); delete unused subjects or versions, or raise the limit (on Confluent Cloud, the Stream Governance package)", e.StatusCode, detail)
+	}
+	return ""
+}
+
+// requiredPermission names the Confluent RBAC permission a registry request
+// needs, with the ACL operation for registries secured by ACLs
+func requiredPermission(method, path string) string {
+	path, _, _ = strings.Cut(path, "?")
+	segments := strings.Split(strings.TrimPrefix(path, "/"), "/")
+	for i, s := range segments {
+		if unescaped, err := url.PathUnescape(s); err == nil {
+			segments[i] = unescaped
+		}
+	}
+
+	switch {
+	case len(segments) == 1 && segments[0] == "subjects":
+		return "Subject:Read on the subjects to list (ACL: GLOBAL_SUBJECTS_READ)"
+
+	case segments[0] == "subjects" && len(segments) >= 2:
+		subject := segments[1]
+		switch method {
+		case http.MethodPost:
+			return fmt.Sprintf("Subject:Write on %s (ACL: SUBJECT_WRITE)", subject)
+		case http.MethodDelete:
+			return fmt.Sprintf("Subject:Delete on %s (ACL: SUBJECT_DELETE)", subject)
+		default:
+			return fmt.Sprintf("Subject:Read on %s (ACL: SUBJECT_READ)", subject)
+		}
+
+	case segments[0] == "compatibility" && len(segments) >= 3:
+		return fmt.Sprintf("Subject:Read on %s (ACL: SUBJECT_READ)", segments[2])
+
+	case segments[0] == "schemas":
+		return "Subject:Read on a subject that uses the schema (ACL: SUBJECT_READ)"
+
+	case segments[0] == "config" || segments[0] == "mode":
+		if len(segments) == 1 {
+			if method == http.MethodGet {
+				return "read access to the global " + segments[0] + " (ACL: GLOBAL_READ)"
+			}
+			return "write access to the global " + segments[0] + " (ACL: GLOBAL_COMPATIBILITY_WRITE)"
+		}
+		subject := segments[1]
+		if method == http.MethodGet {
+			return fmt.Sprintf("Subject:ReadCompatibility on %s (ACL: SUBJECT_COMPATIBILITY_READ)", subject)
+		}
+		return fmt.Sprintf("Subject:AlterCompatibility on %s (ACL: SUBJECT_COMPATIBILITY_WRITE)", subject)
+
+	case segments[0] == "exporters":
+		return "the ResourceOwner role on the Schema Registry cluster, which schema exporters require"
+
+	case segments[0] == "dek-registry" && len(segments) >= 4:
+		kek := segments[3]
+		if method == http.MethodGet {
+			return fmt.Sprintf("Kek:Read on %s", kek)
+		}
+		return fmt.Sprintf("Kek:Write on %s", kek)
+	}
+	return ""
+}
@@ -0,0 +1,59 @@
+package registry
+
+import (
+	"net/http"
+	"strconv"
+	"strings"
+	"time"
+)
+
+// maxThrottleRetries is how many times a throttled request is retried
+// before its 429 is returned
+const maxThrottleRetries = 5
+
+// maxThrottleWait caps the wait before a retry, whatever Retry-After says
+const maxThrottleWait = 30 * time.Second
+
+// Throttle describes a throttled request that is about to be retried
+type Throttle struct {
+	Operation string        // The request, as named in metrics
+	Attempt   int           // 1 for the first retry
+	Wait      time.Duration // How long until the retry
+}
+
+// OnThrottle sets a function called before each retry of a throttled
+// request, to tell the user why it is taking a while. It is called from the
+// goroutine making the request.
+func (c *Client) OnThrottle(fn func(Throttle)) {
+	c.onThrottle = fn
+}
+
+// throttleWait reports whether a response is a rate limit, and how long to
+// wait before retrying: Retry-After if the registry sent it, otherwise a
+// backoff doubling from one second
+func throttleWait(resp *http.Response, attempt int) (time.Duration, bool) {
+	if resp.StatusCode != http.StatusTooManyRequests {
+		return 0, false
+	}
+
+	wait := time.Second << (attempt - 1)
+	if after := resp.Header.Get("Retry-After"); after != "" {
+		if seconds, err := strconv.Atoi(after); err == nil {
+			wait = time.Duration(seconds) * time.Second
+		} else if t, err := http.ParseTime(after); err == nil {
+			wait = time.Until(t)
+		}
+	}
+	return min(max(wait, 0), maxThrottleWait), true
+}
+
+// isSchemaLimit reports whether a rejected registration hit the registry's
+// cap on schemas, as Confluent Cloud enforces per environment
+func isSchemaLimit(e *APIError) bool {
+	if e.Method != http.MethodPost || e.StatusCode < 400 || e.StatusCode >= 500 {
+		return false
+	}
+	body := strings.ToLower(e.Body)
+	return strings.Contains(body, "schema limit") || strings.Contains(body, "limit exceeded") ||
+		(strings.Contains(body, "schemas") && strings.Contains(body, "limit"))
+}
@@ -0,0 +1,97 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/diff"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// Changelog renders a Markdown changelog for a subject from its versions,
+// which must be ordered oldest first. The newest version is listed first.
+func Changelog(subject string, versions []*registry.SchemaResponse) (string, error) {
+	var b strings.Builder
+
+	fmt.Fprintf(&b, "# Changelog: %s\n", subject)
+
+	if len(versions) == 0 {
+		b.WriteString("\nNo versions registered.\n")
+		return b.String(), nil
+	}
+
+	for i := len(versions) - 1; i >= 0; i-- {
+		v := versions[i]
+		b.WriteString("\n")
+		b.WriteString(versionHeading(v))
+
+		if v.SchemaType != "" && v.SchemaType != "AVRO" {
+			fmt.Fprintf(&b, "\n%s schema, field changes not available.\n", v.SchemaType)
+			continue
+		}
+
+		if i == 0 {
+			fields, err := avro.FlattenFields(v.Schema)
+			if err != nil {
+				return "", fmt.Errorf("version %d: %w", v.Version, err)
+			}
+			fmt.Fprintf(&b, "\nInitial version with %d fields.\n", len(fields))
+			continue
+		}
+
+		changes, err := avro.CompareSchemas(versions[i-1].Schema, v.Schema)
+		if err != nil {
+			return "", fmt.Errorf("version %d: %w", v.Version, err)
+		}
+		writeFieldChanges(&b, changes)
+	}
+
+	return b.String(), nil
+}
+
+func versionHeading(v *registry.SchemaResponse) string {
+	heading := fmt.Sprintf("## Version %d (schema ID %d", v.Version, v.ID)
+	if created, ok := v.CreatedAt(); ok {
+		heading += ", registered " + created.Format("2006-01-02")
+	}
+	return heading + ")\n"
+}
+
+// writeFieldChanges writes Added/Removed/Changed sections for a version
+func writeFieldChanges(b *strings.Builder, changes []avro.FieldChange) {
+	if len(changes) == 0 {
+		b.WriteString("\nNo field changes.\n")
+		return
+	}
+
+	sections := []struct {
+		title string
+		kind  diff.Kind
+	}{
+		{"Added", diff.Added},
+		{"Removed", diff.Removed},
+		{"Changed", diff.Changed},
+	}
+
+	for _, section := range sections {
+		var lines []string
+		for _, c := range changes {
+			if c.Kind != section.kind {
+				continue
+			}
+			switch c.Kind {
+			case diff.Added:
+				lines = append(lines, fmt.Sprintf("- `%s` (`%s`)", c.Path, c.New.Type))
+			case diff.Removed:
+				lines = append(lines, fmt.Sprintf("- `%s` (`%s`)", c.Path, c.Old.Type))
+			case diff.Changed:
+				lines = append(lines, fmt.Sprintf("- `%s`: %s", c.Path, strings.Join(c.Details, "; ")))
+			}
+		}
+		if len(lines) == 0 {
+			continue
+		}
+		fmt.Fprintf(b, "\n### %s\n\n%s\n", section.title, strings.Join(lines, "\n"))
+	}
+}
@@ -0,0 +1,53 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// FormatCluster renders a plain-text overview of a Kafka cluster: its
+// brokers, version and topic totals
+func FormatCluster(info *kafka.ClusterInfo) string {
+	var b strings.Builder
+
+	clusterID := info.ClusterID
+	if clusterID == "" {
+		clusterID = "unknown"
+	}
+	version := "unknown"
+	if info.KafkaVersion != "" {
+		version = info.KafkaVersion + " or later"
+	}
+	fmt.Fprintf(&b, "Cluster ID:  %s\n", clusterID)
+	fmt.Fprintf(&b, "Kafka:       %s (estimated from %d supported APIs)\n", version, info.APIs)
+	if info.Controller >= 0 {
+		fmt.Fprintf(&b, "Controller:  broker %d\n", info.Controller)
+	} else {
+		fmt.Fprintf(&b, "Controller:  unknown\n")
+	}
+
+	fmt.Fprintf(&b, "\n%-6s  %-40s  %s\n", "BROKER", "HOST", "RACK")
+	for _, broker := range info.Brokers {
+		rack := broker.Rack
+		if rack == "" {
+			rack = "-"
+		}
+		id := fmt.Sprint(broker.ID)
+		if broker.ID == info.Controller {
+			id += "*"
+		}
+		fmt.Fprintf(&b, "%-6s  %-40s  %s\n", id, fmt.Sprintf("%s:%d", broker.Host, broker.Port), rack)
+	}
+
+	fmt.Fprintf(&b, "\nTopics:      %d (%d internal)\n", info.Topics, info.InternalTopics)
+	fmt.Fprintf(&b, "Partitions:  %d\n", info.Partitions)
+	if info.UnderReplicated > 0 {
+		fmt.Fprintf(&b, "Under-replicated partitions: %d\n", info.UnderReplicated)
+	}
+	if info.TopicErrors > 0 {
+		fmt.Fprintf(&b, "Topics with metadata errors: %d\n", info.TopicErrors)
+	}
+	return b.String()
+}
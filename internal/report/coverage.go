@@ -0,0 +1,83 @@
+package report
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+)
+
+// Coverage summarises how many fields of a subject's schema carry a doc string
+type Coverage struct {
+	Subject    string
+	Total      int
+	Documented int
+	Missing    []string // Paths of fields without a doc string
+	Err        error    // Set when the schema could not be fetched or parsed
+}
+
+// Percent returns the documented share of fields; schemas without fields count as fully covered
+func (c Coverage) Percent() float64 {
+	if c.Total == 0 {
+		return 100
+	}
+	return float64(c.Documented) * 100 / float64(c.Total)
+}
+
+// DocCoverage computes doc string coverage for a subject's schema
+func DocCoverage(subject, schemaJSON string) Coverage {
+	coverage := Coverage{Subject: subject}
+
+	fields, err := avro.FlattenFields(schemaJSON)
+	if err != nil {
+		coverage.Err = err
+		return coverage
+	}
+
+	for _, f := range fields {
+		coverage.Total++
+		if strings.TrimSpace(f.Doc) != "" {
+			coverage.Documented++
+		} else {
+			coverage.Missing = append(coverage.Missing, f.Path)
+		}
+	}
+
+	return coverage
+}
+
+// FormatCoverage renders a plain-text coverage report. When verbose is set,
+// the undocumented fields of each subject are listed under it.
+func FormatCoverage(results []Coverage, verbose bool) string {
+	var b strings.Builder
+
+	width := len("SUBJECT")
+	for _, r := range results {
+		if len(r.Subject) > width {
+			width = len(r.Subject)
+		}
+	}
+
+	var total, documented int
+	fmt.Fprintf(&b, "%-*s  %8s  %s\n", width, "SUBJECT", "COVERAGE", "DOCUMENTED")
+	for _, r := range results {
+		if r.Err != nil {
+			fmt.Fprintf(&b, "%-*s  %8s  error: %v\n", width, r.Subject, "-", r.Err)
+			continue
+		}
+		total += r.Total
+		documented += r.Documented
+		fmt.Fprintf(&b, "%-*s  %7.1f%%  %d/%d\n", width, r.Subject, r.Percent(), r.Documented, r.Total)
+		if verbose {
+			for _, path := range r.Missing {
+				fmt.Fprintf(&b, "    missing doc: %s\n", path)
+			}
+		}
+	}
+
+	overall := Coverage{Total: total, Documented: documented}
+	fmt.Fprintf(&b, "\nOverall: %.1f%% (%d/%d fields documented across %d subjects)\n",
+		overall.Percent(), documented, total, len(results))
+
+	return b.String()
+}
@@ -0,0 +1,331 @@
+package report
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"html/template"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/diff"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// Documentation formats
+const (
+	DocsMarkdown = "markdown"
+	DocsHTML     = "html"
+)
+
+// SubjectDoc is what a subject's documentation page is built from
+type SubjectDoc struct {
+	Subject  string
+	Versions []*registry.SchemaResponse // Oldest first
+	Example  string                     // Example payload, empty for none
+}
+
+// docsPage is a subject's documentation, ready to render in either format
+type docsPage struct {
+	Subject    string
+	Name       string // Record name, for Avro schemas
+	Doc        string // The schema's own doc string or description
+	SchemaType string
+	Version    int
+	ID         int
+	Fields     []docsField
+	History    []docsVersion // Newest first
+	Example    string
+	Schema     string // The latest schema, indented
+}
+
+type docsField struct {
+	Path, Type, Default, Doc string
+}
+
+type docsVersion struct {
+	Version int
+	ID      int
+	Date    string
+	Changes string
+}
+
+// SubjectDocs renders a subject's documentation page: its description, a
+// table of the latest version's fields, the version history, an example
+// payload and the schema itself
+func SubjectDocs(d SubjectDoc, format string) (string, error) {
+	page, err := newDocsPage(d)
+	if err != nil {
+		return "", err
+	}
+	if format == DocsHTML {
+		var b bytes.Buffer
+		if err := docsTemplate.ExecuteTemplate(&b, "subject", page); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+	return page.markdown(), nil
+}
+
+// DocsIndex renders a page linking to each subject's page, where
+// fileName(subject) is the file the subject's page was written to
+func DocsIndex(docs []SubjectDoc, fileName func(subject string) string, format string) (string, error) {
+	type entry struct {
+		Subject, File, Doc string
+		Version            int
+	}
+	entries := make([]entry, 0, len(docs))
+	for _, d := range docs {
+		if len(d.Versions) == 0 {
+			continue
+		}
+		latest := d.Versions[len(d.Versions)-1]
+		entries = append(entries, entry{Subject: d.Subject, File: fileName(d.Subject), Doc: schemaDoc(latest.Schema), Version: latest.Version})
+	}
+
+	if format == DocsHTML {
+		var b bytes.Buffer
+		if err := docsTemplate.ExecuteTemplate(&b, "index", entries); err != nil {
+			return "", err
+		}
+		return b.String(), nil
+	}
+
+	var b strings.Builder
+	b.WriteString("# Schemas\n\n| Subject | Version | Description |\n|---|---|---|\n")
+	for _, e := range entries {
+		fmt.Fprintf(&b, "| [%s](%s) | %d | %s |\n", markdownCell(e.Subject), e.File, e.Version, markdownCell(e.Doc))
+	}
+	return b.String(), nil
+}
+
+func newDocsPage(d SubjectDoc) (*docsPage, error) {
+	if len(d.Versions) == 0 {
+		return nil, fmt.Errorf("%s has no versions", d.Subject)
+	}
+	latest := d.Versions[len(d.Versions)-1]
+	page := &docsPage{
+		Subject:    d.Subject,
+		Doc:        schemaDoc(latest.Schema),
+		SchemaType: latest.SchemaType,
+		Version:    latest.Version,
+		ID:         latest.ID,
+		Example:    d.Example,
+		Schema:     latest.Schema,
+	}
+	if page.SchemaType == "" {
+		page.SchemaType = registry.SchemaTypeAvro
+	}
+	var indented bytes.Buffer
+	if json.Indent(&indented, []byte(latest.Schema), "", "  ") == nil {
+		page.Schema = indented.String()
+	}
+
+	avroSchema := page.SchemaType == registry.SchemaTypeAvro
+	if avroSchema {
+		var record struct {
+			Name      string `json:"name"`
+			Namespace string `json:"namespace"`
+		}
+		if json.Unmarshal([]byte(latest.Schema), &record) == nil && record.Name != "" {
+			page.Name = record.Name
+			if record.Namespace != "" {
+				page.Name = record.Namespace + "." + record.Name
+			}
+		}
+		fields, err := avro.FlattenFields(latest.Schema)
+		if err != nil {
+			return nil, fmt.Errorf("%s: %w", d.Subject, err)
+		}
+		for _, f := range fields {
+			field := docsField{Path: f.Path, Type: f.Type, Doc: f.Doc}
+			if f.HasDefault {
+				if data, err := json.Marshal(f.Default); err == nil {
+					field.Default = string(data)
+				}
+			}
+			if len(f.Symbols) > 0 {
+				field.Type += " (" + strings.Join(f.Symbols, ", ") + ")"
+			}
+			page.Fields = append(page.Fields, field)
+		}
+	}
+
+	for i := len(d.Versions) - 1; i >= 0; i-- {
+		v := d.Versions[i]
+		entry := docsVersion{Version: v.Version, ID: v.ID}
+		if created, ok := v.CreatedAt(); ok {
+			entry.Date = created.Format("2006-01-02")
+		}
+		switch {
+		case i == 0:
+			entry.Changes = "Initial version"
+		case avroSchema && v.SchemaType == latest.SchemaType:
+			changes, err := avro.CompareSchemas(d.Versions[i-1].Schema, v.Schema)
+			if err == nil {
+				entry.Changes = summarizeChanges(changes)
+			}
+		}
+		page.History = append(page.History, entry)
+	}
+	return page, nil
+}
+
+// schemaDoc returns a schema's top-level doc (Avro) or description (JSON
+// Schema)
+func schemaDoc(schemaJSON string) string {
+	var top struct {
+		Doc         string `json:"doc"`
+		Description string `json:"description"`
+	}
+	if json.Unmarshal([]byte(schemaJSON), &top) != nil {
+		return ""
+	}
+	if top.Doc != "" {
+		return top.Doc
+	}
+	return top.Description
+}
+
+// summarizeChanges describes a version's field changes in one line
+func summarizeChanges(changes []avro.FieldChange) string {
+	if len(changes) == 0 {
+		return "No field changes"
+	}
+	var added, removed, changed []string
+	for _, c := range changes {
+		switch c.Kind {
+		case diff.Added:
+			added = append(added, c.Path)
+		case diff.Removed:
+			removed = append(removed, c.Path)
+		case diff.Changed:
+			changed = append(changed, c.Path)
+		}
+	}
+	var parts []string
+	for _, group := range []struct {
+		verb  string
+		paths []string
+	}{{"Added", added}, {"Removed", removed}, {"Changed", changed}} {
+		if len(group.paths) > 0 {
+			parts = append(parts, group.verb+" "+strings.Join(group.paths, ", "))
+		}
+	}
+	return strings.Join(parts, "; ")
+}
+
+func (p *docsPage) markdown() string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "# %s\n\n", p.Subject)
+	if p.Doc != "" {
+		b.WriteString(p.Doc + "\n\n")
+	}
+	meta := fmt.Sprintf("%s schema, version %d (schema ID %d)", p.SchemaType, p.Version, p.ID)
+	if p.Name != "" {
+		meta = fmt.Sprintf("Record `%s`, %s", p.Name, meta)
+	}
+	b.WriteString(meta + "\n")
+
+	if len(p.Fields) > 0 {
+		b.WriteString("\n## Fields\n\n| Field | Type | Default | Description |\n|---|---|---|---|\n")
+		for _, f := range p.Fields {
+			def := ""
+			if f.Default != "" {
+				def = "`" + markdownCell(f.Default) + "`"
+			}
+			fmt.Fprintf(&b, "| `%s` | `%s` | %s | %s |\n", f.Path, markdownCell(f.Type), def, markdownCell(f.Doc))
+		}
+	}
+
+	b.WriteString("\n## Version History\n\n| Version | Schema ID | Registered | Changes |\n|---|---|---|---|\n")
+	for _, v := range p.History {
+		fmt.Fprintf(&b, "| %d | %d | %s | %s |\n", v.Version, v.ID, v.Date, markdownCell(v.Changes))
+	}
+
+	if p.Example != "" {
+		fmt.Fprintf(&b, "\n## Example\n\n```json\n%s\n```\n", p.Example)
+	}
+	fmt.Fprintf(&b, "\n## Schema\n\n```json\n%s\n```\n", p.Schema)
+	return b.String()
+}
+
+// markdownCell keeps text on one line of a Markdown table
+func markdownCell(s string) string {
+	s = strings.ReplaceAll(s, "|", `\|`)
+	return strings.Join(strings.Fields(s), " ")
+}
+
+var docsTemplate = template.Must(template.New("docs").Parse(`
+{{- define "style" -}}
+<style>
+body { font-family: system-ui, sans-serif; max-width: 960px; margin: 2em auto; padding: 0 1em; color: #222; }
+table { border-collapse: collapse; width: 100%; margin: 1em 0; }
+th, td { border: 1px solid #ddd; padding: 0.4em 0.6em; text-align: left; vertical-align: top; }
+th { background: #f4f4f4; }
+code, pre { font-family: ui-monospace, monospace; font-size: 0.9em; }
+pre { background: #f6f8fa; padding: 1em; overflow-x: auto; }
+.meta { color: #666; }
+</style>
+{{- end -}}
+
+{{- define "subject" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>{{.Subject}}</title>
+{{template "style"}}
+</head>
+<body>
+<p><a href="index.html">All schemas</a></p>
+<h1>{{.Subject}}</h1>
+{{if .Doc}}<p>{{.Doc}}</p>{{end}}
+<p class="meta">{{if .Name}}Record <code>{{.Name}}</code>, {{end}}{{.SchemaType}} schema, version {{.Version}} (schema ID {{.ID}})</p>
+{{- if .Fields}}
+<h2>Fields</h2>
+<table>
+<tr><th>Field</th><th>Type</th><th>Default</th><th>Description</th></tr>
+{{- range .Fields}}
+<tr><td><code>{{.Path}}</code></td><td><code>{{.Type}}</code></td><td>{{if .Default}}<code>{{.Default}}</code>{{end}}</td><td>{{.Doc}}</td></tr>
+{{- end}}
+</table>
+{{- end}}
+<h2>Version History</h2>
+<table>
+<tr><th>Version</th><th>Schema ID</th><th>Registered</th><th>Changes</th></tr>
+{{- range .History}}
+<tr><td>{{.Version}}</td><td>{{.ID}}</td><td>{{.Date}}</td><td>{{.Changes}}</td></tr>
+{{- end}}
+</table>
+{{- if .Example}}
+<h2>Example</h2>
+<pre><code>{{.Example}}</code></pre>
+{{- end}}
+<h2>Schema</h2>
+<pre><code>{{.Schema}}</code></pre>
+</body>
+</html>
+{{end -}}
+
+{{- define "index" -}}
+<!DOCTYPE html>
+<html lang="en">
+<head>
+<meta charset="utf-8">
+<title>Schemas</title>
+{{template "style"}}
+</head>
+<body>
+<h1>Schemas</h1>
+<table>
+<tr><th>Subject</th><th>Version</th><th>Description</th></tr>
+{{- range .}}
+<tr><td><a href="{{.File}}">{{.Subject}}</a></td><td>{{.Version}}</td><td>{{.Doc}}</td></tr>
+{{- end}}
+</table>
+</body>
+</html>
+{{end -}}
+`))
@@ -0,0 +1,175 @@
+package report
+
+import (
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+)
+
+// maxDistinct caps the distinct values counted per field
+const maxDistinct = 1000
+
+// FieldUsage summarises how a schema field is populated across sampled
+// messages
+type FieldUsage struct {
+	Path      string
+	Type      string
+	Populated int // Messages with a non-null value for the field
+	Distinct  int // Distinct values seen, at most maxDistinct
+	Numeric   bool
+	Min, Max  float64 // Over numeric values, when Numeric is set
+
+	distinct map[string]bool
+}
+
+// NullRate returns the share of messages without a value for the field,
+// null or missing
+func (u FieldUsage) NullRate(sampled int) float64 {
+	if sampled == 0 {
+		return 0
+	}
+	return float64(sampled-u.Populated) * 100 / float64(sampled)
+}
+
+// Usage computes per-field statistics over decoded message values. Fields
+// inside arrays and maps count as populated when any element has a value.
+func Usage(schemaJSON string, docs []interface{}) ([]FieldUsage, error) {
+	fields, err := avro.FlattenFields(schemaJSON)
+	if err != nil {
+		return nil, err
+	}
+
+	usage := make([]FieldUsage, len(fields))
+	for i, f := range fields {
+		usage[i] = FieldUsage{Path: f.Path, Type: f.Type, distinct: make(map[string]bool)}
+	}
+	for _, doc := range docs {
+		for i := range usage {
+			usage[i].add(fieldValues(doc, usage[i].Path))
+		}
+	}
+	for i := range usage {
+		usage[i].distinct = nil
+	}
+	return usage, nil
+}
+
+// add records one message's values for the field
+func (u *FieldUsage) add(values []interface{}) {
+	populated := false
+	for _, v := range values {
+		if v == nil {
+			continue
+		}
+		populated = true
+
+		if n, ok := v.(float64); ok {
+			if !u.Numeric || n < u.Min {
+				u.Min = n
+			}
+			if !u.Numeric || n > u.Max {
+				u.Max = n
+			}
+			u.Numeric = true
+		}
+		if len(u.distinct) < maxDistinct {
+			key, err := json.Marshal(v)
+			if err == nil && !u.distinct[string(key)] {
+				u.distinct[string(key)] = true
+				u.Distinct++
+			}
+		}
+	}
+	if populated {
+		u.Populated++
+	}
+}
+
+// fieldValues returns the values at a flattened field path ("customer.name",
+// "lines[].sku", "attributes{}.value") in a decoded document
+func fieldValues(doc interface{}, path string) []interface{} {
+	values := []interface{}{doc}
+	for _, segment := range strings.Split(path, ".") {
+		name := strings.TrimRight(segment, "[]{}")
+		var next []interface{}
+		for _, v := range values {
+			if record, ok := v.(map[string]interface{}); ok {
+				if value, ok := record[name]; ok {
+					next = append(next, value)
+				}
+			}
+		}
+		for _, container := range segment[len(name):] {
+			var elements []interface{}
+			for _, v := range next {
+				switch c := v.(type) {
+				case []interface{}:
+					if container == '[' {
+						elements = append(elements, c...)
+					}
+				case map[string]interface{}:
+					if container == '{' {
+						for _, e := range c {
+							elements = append(elements, e)
+						}
+					}
+				}
+			}
+			if container == '[' || container == '{' {
+				next = elements
+			}
+		}
+		values = next
+	}
+	return values
+}
+
+// FormatUsage renders field statistics as a plain-text table
+func FormatUsage(subject, topic string, sampled int, usage []FieldUsage) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "%s: %d messages sampled from the end of %s\n\n", subject, sampled, topic)
+	if sampled == 0 {
+		b.WriteString("No messages to sample.\n")
+		return b.String()
+	}
+
+	pathWidth, typeWidth := len("FIELD"), len("TYPE")
+	for _, u := range usage {
+		pathWidth = max(pathWidth, len(u.Path))
+		typeWidth = max(typeWidth, min(len(u.Type), 30))
+	}
+
+	var unused []string
+	fmt.Fprintf(&b, "%-*s  %-*s  %6s  %9s  %s\n", pathWidth, "FIELD", typeWidth, "TYPE", "NULL", "DISTINCT", "RANGE")
+	for _, u := range usage {
+		typ := u.Type
+		if len(typ) > typeWidth {
+			typ = typ[:typeWidth-1] + "…"
+		}
+		distinct := strconv.Itoa(u.Distinct)
+		if u.Distinct >= maxDistinct {
+			distinct += "+"
+		}
+		rng := ""
+		if u.Numeric {
+			rng = formatNumber(u.Min) + " .. " + formatNumber(u.Max)
+		}
+		row := fmt.Sprintf("%-*s  %-*s  %5.1f%%  %9s  %s", pathWidth, u.Path, typeWidth, typ, u.NullRate(sampled), distinct, rng)
+		b.WriteString(strings.TrimRight(row, " ") + "\n")
+		if u.Populated == 0 {
+			unused = append(unused, u.Path)
+		}
+	}
+
+	if len(unused) > 0 {
+		fmt.Fprintf(&b, "\nNever populated in the sample: %s\n", strings.Join(unused, ", "))
+	}
+	return b.String()
+}
+
+func formatNumber(n float64) string {
+	return strconv.FormatFloat(n, 'g', 10, 64)
+}
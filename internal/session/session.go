@@ -0,0 +1,60 @@
+package session
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+)
+
+// State captures the UI context that is restored on the next launch
+type State struct {
+	Profile     string `json:"profile,omitempty"`
+	Subject     string `json:"subject,omitempty"`
+	Filter      string `json:"filter,omitempty"`
+	ListPercent int    `json:"list_percent,omitempty"` // Width of the subjects pane as a percentage
+	FocusedPane string `json:"focused_pane,omitempty"` // "list" or "viewer"
+	View        string `json:"view,omitempty"`         // "schema" or "consumer"
+}
+
+// GetSessionPath returns the path to the session file
+func GetSessionPath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "session.json")
+	}
+	return filepath.Join(home, ".config", "avrocado", "session.json")
+}
+
+// Load reads a previously saved session from disk
+func Load(path string) (*State, error) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		return nil, err
+	}
+
+	var state State
+	if err := json.Unmarshal(data, &state); err != nil {
+		return nil, fmt.Errorf("parsing session file: %w", err)
+	}
+
+	return &state, nil
+}
+
+// Save writes the session to disk, creating the directory if needed
+func Save(path string, state *State) error {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return fmt.Errorf("creating session directory: %w", err)
+	}
+
+	data, err := json.MarshalIndent(state, "", "  ")
+	if err != nil {
+		return fmt.Errorf("marshaling session: %w", err)
+	}
+
+	if err := os.WriteFile(path, data, 0600); err != nil {
+		return fmt.Errorf("writing session file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,173 @@
+// Package snippet stores reusable blocks of Avro fields, such as an audit
+// block or a money type, to insert into new and edited schemas.
+package snippet
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+
+	"gopkg.in/yaml.v3"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+)
+
+// Snippet is a named block of field definitions
+type Snippet struct {
+	Name        string `yaml:"-"`
+	Description string `yaml:"description,omitempty"`
+	Fields      string `yaml:"fields"` // JSON array of Avro field definitions
+	Builtin     bool   `yaml:"-"`
+}
+
+// builtins ship with avrocado; a saved snippet with the same name replaces
+// one
+var builtins = map[string]Snippet{
+	"audit": {
+		Description: "Who created and last changed the record, and when",
+		Fields: `[
+  {"name": "created_at", "type": {"type": "long", "logicalType": "timestamp-millis"}, "doc": "When the record was created"},
+  {"name": "created_by", "type": "string", "doc": "Who created the record"},
+  {"name": "updated_at", "type": ["null", {"type": "long", "logicalType": "timestamp-millis"}], "default": null, "doc": "When the record was last changed"},
+  {"name": "updated_by", "type": ["null", "string"], "default": null, "doc": "Who last changed the record"}
+]`,
+	},
+	"money": {
+		Description: "An amount with its ISO 4217 currency",
+		Fields: `[
+  {"name": "amount", "type": {"type": "bytes", "logicalType": "decimal", "precision": 18, "scale": 4}, "doc": "The amount, in units of the currency"},
+  {"name": "currency", "type": "string", "doc": "ISO 4217 currency code, e.g. EUR"}
+]`,
+	},
+	"address": {
+		Description: "A postal address record",
+		Fields: `[
+  {"name": "address", "type": {"type": "record", "name": "Address", "fields": [
+    {"name": "line1", "type": "string"},
+    {"name": "line2", "type": ["null", "string"], "default": null},
+    {"name": "city", "type": "string"},
+    {"name": "region", "type": ["null", "string"], "default": null},
+    {"name": "postal_code", "type": "string"},
+    {"name": "country", "type": "string", "doc": "ISO 3166-1 alpha-2 country code"}
+  ]}}
+]`,
+	},
+}
+
+// Store holds the saved snippets
+type Store struct {
+	path     string
+	snippets map[string]Snippet
+}
+
+// GetStorePath returns the path to the snippets file
+func GetStorePath() string {
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "snippets.yaml")
+	}
+	return filepath.Join(home, ".config", "avrocado", "snippets.yaml")
+}
+
+// LoadStore reads the snippets file. A missing file yields a store with only
+// the built-in snippets.
+func LoadStore(path string) (*Store, error) {
+	store := &Store{path: path, snippets: make(map[string]Snippet)}
+
+	data, err := os.ReadFile(path)
+	if err != nil {
+		if os.IsNotExist(err) {
+			return store, nil
+		}
+		return nil, fmt.Errorf("reading snippets file: %w", err)
+	}
+	if err := yaml.Unmarshal(data, &store.snippets); err != nil {
+		return nil, fmt.Errorf("parsing snippets file: %w", err)
+	}
+	if store.snippets == nil {
+		store.snippets = make(map[string]Snippet)
+	}
+	return store, nil
+}
+
+// List returns the saved and built-in snippets, sorted by name
+func (s *Store) List() []Snippet {
+	var list []Snippet
+	for name := range builtins {
+		if _, saved := s.snippets[name]; !saved {
+			list = append(list, s.builtin(name))
+		}
+	}
+	for name, snippet := range s.snippets {
+		snippet.Name = name
+		list = append(list, snippet)
+	}
+	sort.Slice(list, func(i, j int) bool { return list[i].Name < list[j].Name })
+	return list
+}
+
+// Get returns a snippet by name
+func (s *Store) Get(name string) (Snippet, bool) {
+	if snippet, ok := s.snippets[name]; ok {
+		snippet.Name = name
+		return snippet, true
+	}
+	if _, ok := builtins[name]; ok {
+		return s.builtin(name), true
+	}
+	return Snippet{}, false
+}
+
+func (s *Store) builtin(name string) Snippet {
+	snippet := builtins[name]
+	snippet.Name = name
+	snippet.Builtin = true
+	return snippet
+}
+
+// Save checks a snippet's fields and writes it to the file, replacing any
+// snippet of the same name
+func (s *Store) Save(snippet Snippet) error {
+	if snippet.Name == "" {
+		return fmt.Errorf("the snippet needs a name")
+	}
+	if _, err := avro.ParseFields(snippet.Fields); err != nil {
+		return err
+	}
+	s.snippets[snippet.Name] = snippet
+	return s.save()
+}
+
+// Delete removes a saved snippet. Built-in snippets can't be deleted, but
+// deleting a saved snippet that replaced one brings it back.
+func (s *Store) Delete(name string) error {
+	if _, ok := s.snippets[name]; !ok {
+		if _, builtin := builtins[name]; builtin {
+			return fmt.Errorf("%s is built in and can't be deleted", name)
+		}
+		return fmt.Errorf("no snippet %s", name)
+	}
+	delete(s.snippets, name)
+	return s.save()
+}
+
+func (s *Store) save() error {
+	if s.path == "" {
+		return nil
+	}
+	if err := os.MkdirAll(filepath.Dir(s.path), 0700); err != nil {
+		return fmt.Errorf("creating config directory: %w", err)
+	}
+
+	data, err := yaml.Marshal(s.snippets)
+	if err != nil {
+		return fmt.Errorf("marshaling snippets: %w", err)
+	}
+
+	if err := os.WriteFile(s.path, data, 0600); err != nil {
+		return fmt.Errorf("writing snippets file: %w", err)
+	}
+
+	return nil
+}
@@ -0,0 +1,293 @@
+// Package subjectstore keeps very large subject lists on disk, indexed by
+// trigram so substring filters don't scan every subject on each keystroke.
+package subjectstore
+
+import (
+	"encoding/binary"
+	"errors"
+	"fmt"
+	"os"
+	"path/filepath"
+	"sort"
+	"strings"
+	"time"
+
+	bolt "go.etcd.io/bbolt"
+)
+
+var (
+	subjectsBucket = []byte("subjects") // ID -> subject, in registry order
+	trigramsBucket = []byte("trigrams") // Trigram -> sorted IDs of subjects containing it
+)
+
+// batchSize is how many subjects are written per transaction while loading
+const batchSize = 5000
+
+// Store is an on-disk, indexed list of a registry's subjects
+type Store struct {
+	db    *bolt.DB
+	count int
+}
+
+// GetStorePath returns the store file for a profile's subjects
+func GetStorePath(profile string) string {
+	if profile == "" {
+		profile = "default"
+	}
+	name := "subjects-" + strings.ReplaceAll(profile, string(filepath.Separator), "_") + ".db"
+	home, err := os.UserHomeDir()
+	if err != nil {
+		return filepath.Join(".", ".config", "avrocado", "cache", name)
+	}
+	return filepath.Join(home, ".config", "avrocado", "cache", name)
+}
+
+// Open opens (creating if needed) a store. It fails rather than waiting if
+// another avrocado has the same store open.
+func Open(path string) (*Store, error) {
+	if err := os.MkdirAll(filepath.Dir(path), 0700); err != nil {
+		return nil, fmt.Errorf("creating cache directory: %w", err)
+	}
+	db, err := bolt.Open(path, 0600, &bolt.Options{Timeout: time.Second, NoFreelistSync: true})
+	if errors.Is(err, bolt.ErrTimeout) {
+		return nil, fmt.Errorf("subject store %s is in use by another avrocado", path)
+	}
+	if err != nil {
+		return nil, fmt.Errorf("opening subject store: %w", err)
+	}
+	return &Store{db: db}, nil
+}
+
+// Loader replaces a store's subjects, taking them one at a time as they are
+// streamed from the registry
+type Loader struct {
+	store    *Store
+	batch    []string
+	postings map[string][]uint32
+	next     uint32
+}
+
+// Load empties the store and returns a loader for the new subject list.
+// Call Add for each subject, then Finish.
+func (s *Store) Load() (*Loader, error) {
+	err := s.db.Update(func(tx *bolt.Tx) error {
+		for _, name := range [][]byte{subjectsBucket, trigramsBucket} {
+			if tx.Bucket(name) != nil {
+				if err := tx.DeleteBucket(name); err != nil {
+					return err
+				}
+			}
+			if _, err := tx.CreateBucket(name); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, fmt.Errorf("clearing subject store: %w", err)
+	}
+	s.count = 0
+	return &Loader{store: s, postings: make(map[string][]uint32)}, nil
+}
+
+// Add appends a subject
+func (l *Loader) Add(subject string) error {
+	l.batch = append(l.batch, subject)
+	for _, t := range trigrams(subject) {
+		l.postings[t] = append(l.postings[t], l.next+uint32(len(l.batch)-1))
+	}
+	if len(l.batch) >= batchSize {
+		return l.flush()
+	}
+	return nil
+}
+
+// flush writes the pending subjects. Posting lists are written once, by
+// Finish, since most trigrams appear in many batches.
+func (l *Loader) flush() error {
+	err := l.store.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(subjectsBucket)
+		b.FillPercent = 1 // IDs are appended in order
+		for i, subject := range l.batch {
+			if err := b.Put(idKey(l.next+uint32(i)), []byte(subject)); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return fmt.Errorf("writing subjects: %w", err)
+	}
+	l.next += uint32(len(l.batch))
+	l.store.count = int(l.next)
+	l.batch = l.batch[:0]
+	return nil
+}
+
+// Finish writes the remaining subjects and the trigram index
+func (l *Loader) Finish() error {
+	if err := l.flush(); err != nil {
+		return err
+	}
+
+	keys := make([]string, 0, len(l.postings))
+	for t := range l.postings {
+		keys = append(keys, t)
+	}
+	sort.Strings(keys)
+
+	err := l.store.db.Update(func(tx *bolt.Tx) error {
+		b := tx.Bucket(trigramsBucket)
+		b.FillPercent = 1
+		for _, t := range keys {
+			ids := l.postings[t]
+			value := make([]byte, 4*len(ids))
+			for i, id := range ids {
+				binary.BigEndian.PutUint32(value[4*i:], id)
+			}
+			if err := b.Put([]byte(t), value); err != nil {
+				return err
+			}
+		}
+		return nil
+	})
+	l.postings = nil
+	if err != nil {
+		return fmt.Errorf("writing subject index: %w", err)
+	}
+	return nil
+}
+
+// Count returns how many subjects the store holds
+func (s *Store) Count() int {
+	return s.count
+}
+
+// Query returns up to limit subjects, in registry order, containing query
+// (case-insensitively), and whether there are more
+func (s *Store) Query(query string, limit int) ([]string, bool, error) {
+	query = strings.ToLower(query)
+	var results []string
+	more := false
+
+	match := func(subject []byte) bool {
+		if !strings.Contains(strings.ToLower(string(subject)), query) {
+			return true
+		}
+		if len(results) == limit {
+			more = true
+			return false
+		}
+		results = append(results, string(subject))
+		return true
+	}
+
+	err := s.db.View(func(tx *bolt.Tx) error {
+		subjects := tx.Bucket(subjectsBucket)
+		if subjects == nil {
+			return nil
+		}
+
+		grams := trigrams(query)
+		if len(grams) == 0 {
+			// Too short to use the index: scan everything
+			c := subjects.Cursor()
+			for k, v := c.First(); k != nil && match(v); k, v = c.Next() {
+			}
+			return nil
+		}
+
+		for _, id := range candidates(tx.Bucket(trigramsBucket), grams) {
+			if !match(subjects.Get(idKey(id))) {
+				break
+			}
+		}
+		return nil
+	})
+	if err != nil {
+		return nil, false, fmt.Errorf("querying subjects: %w", err)
+	}
+	return results, more, nil
+}
+
+// All returns every subject, for the rare operations that need the full list
+func (s *Store) All() ([]string, error) {
+	subjects := make([]string, 0, s.count)
+	err := s.db.View(func(tx *bolt.Tx) error {
+		b := tx.Bucket(subjectsBucket)
+		if b == nil {
+			return nil
+		}
+		return b.ForEach(func(_, v []byte) error {
+			subjects = append(subjects, string(v))
+			return nil
+		})
+	})
+	if err != nil {
+		return nil, fmt.Errorf("reading subjects: %w", err)
+	}
+	return subjects, nil
+}
+
+// Close releases the store file
+func (s *Store) Close() error {
+	return s.db.Close()
+}
+
+// candidates intersects the posting lists of a query's trigrams, smallest
+// first. Matches still need checking, as a subject can contain every
+// trigram without containing the query.
+func candidates(index *bolt.Bucket, grams []string) []uint32 {
+	lists := make([][]byte, 0, len(grams))
+	for _, t := range grams {
+		list := index.Get([]byte(t))
+		if list == nil {
+			return nil
+		}
+		lists = append(lists, list)
+	}
+	sort.Slice(lists, func(i, j int) bool { return len(lists[i]) < len(lists[j]) })
+
+	ids := make([]uint32, len(lists[0])/4)
+	for i := range ids {
+		ids[i] = binary.BigEndian.Uint32(lists[0][4*i:])
+	}
+	for _, list := range lists[1:] {
+		kept := ids[:0]
+		j, n := 0, len(list)/4
+		for _, id := range ids {
+			for j < n && binary.BigEndian.Uint32(list[4*j:]) < id {
+				j++
+			}
+			if j < n && binary.BigEndian.Uint32(list[4*j:]) == id {
+				kept = append(kept, id)
+			}
+		}
+		ids = kept
+	}
+	return ids
+}
+
+// trigrams returns the distinct lower-case three-byte substrings of s
+func trigrams(s string) []string {
+	s = strings.ToLower(s)
+	if len(s) < 3 {
+		return nil
+	}
+	seen := make(map[string]bool, len(s)-2)
+	grams := make([]string, 0, len(s)-2)
+	for i := 0; i+3 <= len(s); i++ {
+		t := s[i : i+3]
+		if !seen[t] {
+			seen[t] = true
+			grams = append(grams, t)
+		}
+	}
+	return grams
+}
+
+func idKey(id uint32) []byte {
+	key := make([]byte, 4)
+	binary.BigEndian.PutUint32(key, id)
+	return key
+}
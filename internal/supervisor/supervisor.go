@@ -0,0 +1,134 @@
+// Package supervisor owns the UI's background work, so quitting (or
+// switching profile) stops every task and closes every connection it
+// opened instead of leaking them.
+package supervisor
+
+import (
+	"context"
+	"errors"
+	"fmt"
+	"io"
+	"sync"
+	"sync/atomic"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// Supervisor runs tasks with a context that is cancelled on Shutdown and
+// closes the resources it owns then
+type Supervisor struct {
+	ctx    context.Context
+	cancel context.CancelFunc
+
+	mu      sync.Mutex
+	stopped bool
+	owned   map[io.Closer]bool
+	tasks   sync.WaitGroup
+	running atomic.Int64
+}
+
+// New creates a supervisor
+func New() *Supervisor {
+	ctx, cancel := context.WithCancel(context.Background())
+	return &Supervisor{ctx: ctx, cancel: cancel, owned: make(map[io.Closer]bool)}
+}
+
+// Context returns the context background work should derive from
+func (s *Supervisor) Context() context.Context {
+	return s.ctx
+}
+
+// Go runs fn in a goroutine. Shutdown cancels its context and waits for it.
+func (s *Supervisor) Go(fn func(ctx context.Context)) {
+	if !s.start() {
+		return
+	}
+	go func() {
+		defer s.done()
+		fn(s.ctx)
+	}()
+}
+
+// Cmd wraps a Bubble Tea command so it runs as a supervised task. After
+// Shutdown it does nothing.
+func (s *Supervisor) Cmd(fn func(ctx context.Context) tea.Msg) tea.Cmd {
+	return func() tea.Msg {
+		if !s.start() {
+			return nil
+		}
+		defer s.done()
+		return fn(s.ctx)
+	}
+}
+
+func (s *Supervisor) start() bool {
+	s.mu.Lock()
+	defer s.mu.Unlock()
+	if s.stopped {
+		return false
+	}
+	s.tasks.Add(1)
+	s.running.Add(1)
+	return true
+}
+
+func (s *Supervisor) done() {
+	s.running.Add(-1)
+	s.tasks.Done()
+}
+
+// Own makes the supervisor responsible for closing c on Shutdown. After
+// Shutdown, c is closed straight away.
+func (s *Supervisor) Own(c io.Closer) {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		c.Close()
+		return
+	}
+	s.owned[c] = true
+	s.mu.Unlock()
+}
+
+// Release closes c and forgets it
+func (s *Supervisor) Release(c io.Closer) error {
+	s.mu.Lock()
+	delete(s.owned, c)
+	s.mu.Unlock()
+	return c.Close()
+}
+
+// Shutdown cancels all tasks, closes everything owned and waits up to
+// timeout for the tasks to return
+func (s *Supervisor) Shutdown(timeout time.Duration) error {
+	s.mu.Lock()
+	if s.stopped {
+		s.mu.Unlock()
+		return nil
+	}
+	s.stopped = true
+	owned := s.owned
+	s.owned = nil
+	s.mu.Unlock()
+
+	s.cancel()
+	var errs []error
+	for c := range owned {
+		if err := c.Close(); err != nil {
+			errs = append(errs, err)
+		}
+	}
+
+	finished := make(chan struct{})
+	go func() {
+		s.tasks.Wait()
+		close(finished)
+	}()
+	select {
+	case <-finished:
+	case <-time.After(timeout):
+		errs = append(errs, fmt.Errorf("%d background tasks still running after %s", s.running.Load(), timeout))
+	}
+	return errors.Join(errs...)
+}
@@ -0,0 +1,175 @@
+package tunnel
+
+import (
+	"context"
+	"fmt"
+	"net"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	"golang.org/x/crypto/ssh"
+	"golang.org/x/crypto/ssh/agent"
+	"golang.org/x/crypto/ssh/knownhosts"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// Tunnel is an SSH connection to a bastion host. Registry and broker
+// connections are dialed through it, so addresses only reachable from the
+// bastion (including brokers' advertised listeners) work transparently.
+type Tunnel struct {
+	client *ssh.Client
+	agent  net.Conn
+}
+
+// Connect opens the profile's SSH tunnel, if one is configured, and routes
+// cfg's registry and broker connections through it. It returns nil without
+// a tunnel.
+func Connect(cfg *config.Config) (*Tunnel, error) {
+	if cfg.SSHTunnel == nil || cfg.SSHTunnel.Host == "" {
+		return nil, nil
+	}
+
+	t, err := Open(cfg.SSHTunnel)
+	if err != nil {
+		return nil, err
+	}
+
+	cfg.DialContext = t.DialContext
+	return t, nil
+}
+
+// Open connects to the bastion described by cfg
+func Open(cfg *config.SSHTunnelConfig) (*Tunnel, error) {
+	if cfg.Host == "" {
+		return nil, fmt.Errorf("ssh tunnel host not configured")
+	}
+
+	user := cfg.User
+	if user == "" {
+		user = os.Getenv("USER")
+	}
+
+	t := &Tunnel{}
+
+	auth, err := t.authMethods(cfg)
+	if err != nil {
+		return nil, err
+	}
+
+	hostKeyCallback, err := hostKeyCallback(cfg)
+	if err != nil {
+		t.closeAgent()
+		return nil, err
+	}
+
+	addr := cfg.Host
+	if _, _, err := net.SplitHostPort(addr); err != nil {
+		addr = net.JoinHostPort(addr, "22")
+	}
+
+	client, err := ssh.Dial("tcp", addr, &ssh.ClientConfig{
+		User:            user,
+		Auth:            auth,
+		HostKeyCallback: hostKeyCallback,
+		Timeout:         10 * time.Second,
+	})
+	if err != nil {
+		t.closeAgent()
+		return nil, fmt.Errorf("connecting to ssh bastion %s: %w", addr, err)
+	}
+
+	t.client = client
+	return t, nil
+}
+
+// authMethods uses the configured private key, falling back to ssh-agent
+func (t *Tunnel) authMethods(cfg *config.SSHTunnelConfig) ([]ssh.AuthMethod, error) {
+	var methods []ssh.AuthMethod
+
+	if cfg.KeyFile != "" {
+		key, err := os.ReadFile(expandHome(cfg.KeyFile))
+		if err != nil {
+			return nil, fmt.Errorf("reading ssh key: %w", err)
+		}
+
+		var signer ssh.Signer
+		if cfg.KeyPassphrase != "" {
+			signer, err = ssh.ParsePrivateKeyWithPassphrase(key, []byte(cfg.KeyPassphrase))
+		} else {
+			signer, err = ssh.ParsePrivateKey(key)
+		}
+		if err != nil {
+			return nil, fmt.Errorf("parsing ssh key: %w", err)
+		}
+		methods = append(methods, ssh.PublicKeys(signer))
+	}
+
+	if sock := os.Getenv("SSH_AUTH_SOCK"); sock != "" {
+		conn, err := net.Dial("unix", sock)
+		if err == nil {
+			t.agent = conn
+			methods = append(methods, ssh.PublicKeysCallback(agent.NewClient(conn).Signers))
+		}
+	}
+
+	if len(methods) == 0 {
+		return nil, fmt.Errorf("no ssh credentials: set key_file or run ssh-agent")
+	}
+
+	return methods, nil
+}
+
+// hostKeyCallback verifies the bastion against known_hosts unless disabled
+func hostKeyCallback(cfg *config.SSHTunnelConfig) (ssh.HostKeyCallback, error) {
+	if cfg.InsecureIgnoreHostKey {
+		return ssh.InsecureIgnoreHostKey(), nil
+	}
+
+	path := cfg.KnownHostsFile
+	if path == "" {
+		path = "~/.ssh/known_hosts"
+	}
+
+	callback, err := knownhosts.New(expandHome(path))
+	if err != nil {
+		return nil, fmt.Errorf("loading known hosts: %w", err)
+	}
+	return callback, nil
+}
+
+// DialContext opens a connection to address from the bastion
+func (t *Tunnel) DialContext(ctx context.Context, network, address string) (net.Conn, error) {
+	conn, err := t.client.DialContext(ctx, network, address)
+	if err != nil {
+		return nil, fmt.Errorf("dialing %s through ssh tunnel: %w", address, err)
+	}
+	return conn, nil
+}
+
+// Close shuts down the SSH connection and any agent connection
+func (t *Tunnel) Close() error {
+	t.closeAgent()
+	if t.client != nil {
+		return t.client.Close()
+	}
+	return nil
+}
+
+func (t *Tunnel) closeAgent() {
+	if t.agent != nil {
+		t.agent.Close()
+		t.agent = nil
+	}
+}
+
+func expandHome(path string) string {
+	if strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[2:])
+		}
+	}
+	return path
+}
@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+	"time"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/annotation"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// noteFor returns the note on a consumed message, if any
+func (m Model) noteFor(msg kafka.Message) (annotation.Note, bool) {
+	if m.consumer == nil {
+		return annotation.Note{}, false
+	}
+	return m.notes.Get(m.cfg.Profile, m.topic(), m.consumer.Partition(), msg.Offset)
+}
+
+// enterAnnotating asks for the note on the current message
+func (m *Model) enterAnnotating() {
+	if m.consumer == nil || m.currentMsgIdx >= len(m.consumedMessages) {
+		return
+	}
+	msg := m.consumedMessages[m.currentMsgIdx]
+	note, _ := m.noteFor(msg)
+	m.notePrompt = NewTextPrompt(fmt.Sprintf("Note on offset %d", msg.Offset), "Saved locally for this topic partition and offset; empty removes the note", note.Text)
+	m.state = stateAnnotating
+}
+
+func (m *Model) handleAnnotating(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.notePrompt.Update(msg)
+	m.notePrompt = newModel.(TextPromptModel)
+	if !m.notePrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateConsumerMode
+	if !m.notePrompt.Saved() || m.consumer == nil || m.currentMsgIdx >= len(m.consumedMessages) {
+		return m, nil
+	}
+	offset := m.consumedMessages[m.currentMsgIdx].Offset
+	text := strings.TrimSpace(m.notePrompt.Value())
+	if err := m.notes.Set(m.cfg.Profile, m.topic(), m.consumer.Partition(), offset, text); err != nil {
+		m.err = err
+		return m, nil
+	}
+	if text == "" {
+		m.copyNotify = fmt.Sprintf("Removed the note on offset %d", offset)
+	} else {
+		m.copyNotify = fmt.Sprintf("Saved the note on offset %d", offset)
+	}
+	return m, nil
+}
+
+// shareMessage copies the current message as a Markdown bundle: its
+// metadata, note and decoded value, as shown (masked fields stay masked).
+// Without a clipboard the bundle is written to a file instead.
+func (m *Model) shareMessage() {
+	if m.consumer == nil || m.currentMsgIdx >= len(m.consumedMessages) {
+		return
+	}
+	msg := m.consumedMessages[m.currentMsgIdx]
+	bundle := m.messageBundle(msg, m.messageValue(m.currentMsgIdx))
+	if err := clipboard.WriteAll(bundle); err == nil {
+		m.copyNotify = fmt.Sprintf("Copied offset %d with its note and metadata", msg.Offset)
+		return
+	}
+
+	path := fmt.Sprintf("%s-%d-%d.md", m.topic(), m.consumer.Partition(), msg.Offset)
+	if err := os.WriteFile(path, []byte(bundle), 0600); err != nil {
+		m.err = fmt.Errorf("saving message bundle: %w", err)
+		return
+	}
+	m.copyNotify = "No clipboard; saved the message bundle to " + path
+}
+
+// messageBundle renders a consumed message for pasting into a ticket
+func (m Model) messageBundle(msg kafka.Message, value string) string {
+	var b strings.Builder
+	fmt.Fprintf(&b, "### %s / partition %d / offset %d\n\n", m.topic(), m.consumer.Partition(), msg.Offset)
+
+	profile := m.cfg.Profile
+	if profile == "" {
+		profile = "env"
+	}
+	fmt.Fprintf(&b, "- Profile: %s\n", profile)
+	fmt.Fprintf(&b, "- Subject: %s\n", m.selectedSubject)
+	fmt.Fprintf(&b, "- Timestamp: %s\n", msg.Timestamp.UTC().Format(time.RFC3339Nano))
+	fmt.Fprintf(&b, "- Link: %s\n", m.messageLink(msg))
+	if label := m.readerLabel(msg.Value); label != "" {
+		fmt.Fprintf(&b, "- Schema: %s\n", label)
+	}
+	if msg.Key != "" {
+		fmt.Fprintf(&b, "- Key: `%s`\n", m.decodeKey(msg.Key))
+	}
+	if len(msg.Headers) > 0 {
+		names := make([]string, 0, len(msg.Headers))
+		for name := range msg.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString("- Headers:\n")
+		for _, name := range names {
+			fmt.Fprintf(&b, "  - %s: `%s`\n", name, msg.Headers[name])
+		}
+	}
+
+	if note, ok := m.noteFor(msg); ok {
+		fmt.Fprintf(&b, "\n**Note** (%s): %s\n", note.Updated.Format("2006-01-02 15:04"), note.Text)
+	}
+
+	fmt.Fprintf(&b, "\n```json\n%s\n```\n", value)
+	return b.String()
+}
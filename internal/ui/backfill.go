@@ -0,0 +1,423 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/supervisor"
+)
+
+// Backfill limits: messages fetched per step, and the most kept in memory
+const (
+	backfillChunkSize   = 500
+	maxBackfillMessages = 10000
+)
+
+// backfillState tracks a historical range being consumed
+type backfillState struct {
+	id        int // Distinguishes chunks of a cancelled backfill from the current one
+	partition int
+	start     int64 // First offset of the range
+	end       int64 // Offset after the last one in the range
+	next      int64 // Next offset to fetch
+	done      bool
+	note      string // Why the range was narrowed or stopped early
+}
+
+// backfillStartedMsg carries the consumer positioned at a resolved range
+type backfillStartedMsg struct {
+	id       int
+	consumer *kafka.Consumer
+	start    int64
+	end      int64
+	note     string
+	err      error
+}
+
+// backfillChunkMsg carries one step of backfilled messages
+type backfillChunkMsg struct {
+	id       int
+	messages []kafka.Message
+	decoded  []decodedMessage
+	err      error
+}
+
+// backfillBound is one end of a range: an offset or a time
+type backfillBound struct {
+	offset int64
+	time   time.Time
+	isTime bool
+	set    bool
+}
+
+// parseBackfillBound reads an offset ("100000"), a time today ("09:15",
+// "09:15:30") or a date and time ("2024-05-01 09:15", RFC 3339). An empty
+// string leaves the bound open.
+func parseBackfillBound(s string, now time.Time) (backfillBound, error) {
+	s = strings.TrimSpace(s)
+	if s == "" {
+		return backfillBound{}, nil
+	}
+	if offset, err := strconv.ParseInt(s, 10, 64); err == nil {
+		if offset < 0 {
+			return backfillBound{}, fmt.Errorf("offset %d is negative", offset)
+		}
+		return backfillBound{offset: offset, set: true}, nil
+	}
+
+	for _, layout := range []string{"15:04", "15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			today := time.Date(now.Year(), now.Month(), now.Day(), t.Hour(), t.Minute(), t.Second(), 0, now.Location())
+			return backfillBound{time: today, isTime: true, set: true}, nil
+		}
+	}
+	for _, layout := range []string{time.RFC3339, "2006-01-02T15:04", "2006-01-02 15:04", "2006-01-02 15:04:05"} {
+		if t, err := time.ParseInLocation(layout, s, now.Location()); err == nil {
+			return backfillBound{time: t, isTime: true, set: true}, nil
+		}
+	}
+	return backfillBound{}, fmt.Errorf("%q is not an offset or a time (09:15, 2024-05-01 09:15)", s)
+}
+
+// BackfillFormModel asks for the partition and range to backfill
+type BackfillFormModel struct {
+	topic      string
+	fields     []formField
+	focusedIdx int
+	saved      bool
+	quit       bool
+}
+
+// NewBackfillForm creates the range form for a topic
+func NewBackfillForm(topic string, partition int) BackfillFormModel {
+	return BackfillFormModel{
+		topic: topic,
+		fields: []formField{
+			{label: "Partition", value: strconv.Itoa(partition)},
+			{label: "From", placeholder: "offset, 09:00 or 2024-05-01 09:00 (empty: earliest)"},
+			{label: "To", placeholder: "offset, 09:15 or 2024-05-01 09:15 (empty: latest)"},
+		},
+	}
+}
+
+func (m BackfillFormModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m BackfillFormModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			m.quit = true
+			return m, nil
+		case "tab", "shift+tab":
+			m.focusedIdx = (m.focusedIdx + 1) % len(m.fields)
+		case "enter":
+			if m.focusedIdx == len(m.fields)-1 {
+				m.saved = true
+				m.quit = true
+				return m, nil
+			}
+			m.focusedIdx++
+		default:
+			field := &m.fields[m.focusedIdx]
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				field.value += string(msg.Runes)
+			} else if msg.String() == "backspace" {
+				if len(field.value) > 0 {
+					runes := []rune(field.value)
+					field.value = string(runes[:len(runes)-1])
+				}
+			} else if msg.String() == "ctrl+u" {
+				field.value = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m BackfillFormModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Backfill Range: %s", m.topic)) + "\n\n"
+
+	for i, field := range m.fields {
+		prefix := "  "
+		if i == m.focusedIdx {
+			prefix = "> "
+		}
+
+		label := lipgloss.NewStyle().Width(12).Render(field.label + ":")
+		value := field.value
+		if value == "" {
+			value = lipgloss.NewStyle().Faint(true).Render(field.placeholder)
+		}
+
+		if i == m.focusedIdx {
+			s += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("11")).
+				Bold(true).
+				Render(prefix+label+" "+value) + "\n"
+		} else {
+			s += prefix + label + " " + value + "\n"
+		}
+	}
+
+	s += "\n"
+	s += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("Offset ranges include both ends; up to %d messages are kept", maxBackfillMessages)) + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[tab] Next  [enter] Next / Start  [esc] Cancel") + "\n"
+
+	return s
+}
+
+// Range returns the entered partition and bounds
+func (m BackfillFormModel) Range(now time.Time) (int, backfillBound, backfillBound, error) {
+	partition, err := strconv.Atoi(strings.TrimSpace(m.fields[0].value))
+	if err != nil || partition < 0 {
+		return 0, backfillBound{}, backfillBound{}, fmt.Errorf("invalid partition %q", m.fields[0].value)
+	}
+	from, err := parseBackfillBound(m.fields[1].value, now)
+	if err != nil {
+		return 0, backfillBound{}, backfillBound{}, fmt.Errorf("from: %w", err)
+	}
+	to, err := parseBackfillBound(m.fields[2].value, now)
+	if err != nil {
+		return 0, backfillBound{}, backfillBound{}, fmt.Errorf("to: %w", err)
+	}
+	return partition, from, to, nil
+}
+
+// Saved returns whether the user started the backfill
+func (m BackfillFormModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the form is closed
+func (m BackfillFormModel) Quit() bool {
+	return m.quit
+}
+
+func (m *Model) handleBackfillForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.backfillForm.Update(msg)
+	m.backfillForm = newModel.(BackfillFormModel)
+
+	if !m.backfillForm.Quit() {
+		return m, cmd
+	}
+	m.state = stateConsumerMode
+	if !m.backfillForm.Saved() {
+		return m, nil
+	}
+
+	partition, from, to, err := m.backfillForm.Range(time.Now())
+	if err != nil {
+		m.err = err
+		return m, nil
+	}
+
+	m.backfillID++
+	m.backfill = &backfillState{id: m.backfillID, partition: partition}
+	m.debugMsg = fmt.Sprintf("Resolving range on partition %d...", partition)
+	return m, startBackfill(m.supervisor, m.cfg, m.topic(), m.backfillID, partition, from, to)
+}
+
+// startBackfill opens a consumer on the partition, resolves time bounds to
+// offsets, clamps the range to the partition's watermarks and seeks to it
+func startBackfill(sup *supervisor.Supervisor, cfg *config.Config, topic string, id, partition int, from, to backfillBound) tea.Cmd {
+	return sup.Cmd(func(ctx context.Context) tea.Msg {
+		result := backfillStartedMsg{id: id}
+
+		consumer, err := kafka.NewPartitionConsumer(cfg, topic, partition)
+		if err != nil {
+			result.err = fmt.Errorf("failed to create consumer: %w", err)
+			return result
+		}
+		sup.Own(consumer)
+
+		ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+		defer cancel()
+
+		first, last, err := consumer.Watermarks(ctx)
+		if err != nil {
+			sup.Release(consumer)
+			result.err = err
+			return result
+		}
+
+		start, end := first, last
+		if from.set {
+			if start, err = resolveBound(ctx, consumer, from); err != nil {
+				sup.Release(consumer)
+				result.err = err
+				return result
+			}
+		}
+		if to.set {
+			if end, err = resolveBound(ctx, consumer, to); err != nil {
+				sup.Release(consumer)
+				result.err = err
+				return result
+			}
+			if !to.isTime {
+				end++ // Offset ranges include their last offset
+			}
+		}
+
+		if start < first {
+			result.note = fmt.Sprintf("offsets before %d are no longer retained", first)
+			start = first
+		}
+		if end > last {
+			end = last
+		}
+		if start >= end {
+			sup.Release(consumer)
+			result.err = fmt.Errorf("no messages in range on partition %d (retained offsets %d-%d)", partition, first, last-1)
+			return result
+		}
+
+		if err := consumer.SeekTo(start); err != nil {
+			sup.Release(consumer)
+			result.err = err
+			return result
+		}
+
+		result.consumer = consumer
+		result.start = start
+		result.end = end
+		return result
+	})
+}
+
+func resolveBound(ctx context.Context, consumer *kafka.Consumer, b backfillBound) (int64, error) {
+	if b.isTime {
+		return consumer.OffsetAt(ctx, b.time)
+	}
+	return b.offset, nil
+}
+
+// backfillChunkCmd fetches and decodes the next step of the range
+func (m *Model) backfillChunkCmd() tea.Cmd {
+	consumer := m.consumer
+	decoder := m.wireDecoder
+	decode := m.decodeValue
+	state := *m.backfill
+
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
+		defer cancel()
+
+		n := int(min(state.end-state.next, backfillChunkSize))
+		messages, err := consumer.FetchMessages(ctx, n)
+		if err != nil {
+			return backfillChunkMsg{id: state.id, err: err}
+		}
+
+		inRange := messages[:0]
+		for _, msg := range messages {
+			if msg.Offset < state.end {
+				inRange = append(inRange, msg)
+			}
+		}
+		return backfillChunkMsg{id: state.id, messages: inRange, decoded: decodeMessages(decoder, decode, inRange)}
+	})
+}
+
+func (m *Model) handleBackfillStarted(msg backfillStartedMsg) tea.Cmd {
+	if m.backfill == nil || msg.id != m.backfill.id {
+		if msg.consumer != nil {
+			go m.supervisor.Release(msg.consumer)
+		}
+		return nil
+	}
+	if msg.err != nil {
+		m.backfill = nil
+		m.debugMsg = fmt.Sprintf("ERROR: Backfill failed: %v", msg.err)
+		return nil
+	}
+
+	m.setConsumer(msg.consumer)
+	m.backfill.start = msg.start
+	m.backfill.end = msg.end
+	m.backfill.next = msg.start
+	m.backfill.note = msg.note
+
+	m.consumedMessages = []kafka.Message{}
+	m.decodedMessages = nil
+	m.currentMsgIdx = 0
+	m.throughput = throughputStats{}
+	m.debugMsg = ""
+	m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Backfilling partition %d, offsets %d-%d", m.backfill.partition, msg.start, msg.end-1)
+	return m.backfillChunkCmd()
+}
+
+func (m *Model) handleBackfillChunk(msg backfillChunkMsg) tea.Cmd {
+	if m.backfill == nil || msg.id != m.backfill.id || m.backfill.done {
+		return nil
+	}
+	b := m.backfill
+
+	if msg.err != nil {
+		b.done = true
+		b.note = fmt.Sprintf("stopped at offset %d: %v", b.next, msg.err)
+	} else if len(msg.messages) == 0 {
+		// Compacted or deleted tail of the range
+		b.done = true
+		if b.next < b.end {
+			b.note = fmt.Sprintf("no messages after offset %d", b.next-1)
+		}
+	} else {
+		m.consumedMessages = append(m.consumedMessages, msg.messages...)
+		m.decodedMessages = append(m.decodedMessages, msg.decoded...)
+		m.throughput.add(msg.decoded)
+		b.next = msg.messages[len(msg.messages)-1].Offset + 1
+
+		if b.next >= b.end {
+			b.done = true
+		} else if len(m.consumedMessages) >= maxBackfillMessages {
+			b.done = true
+			b.note = fmt.Sprintf("stopped at the %d message limit (offset %d)", maxBackfillMessages, b.next-1)
+		}
+	}
+
+	redecode := m.redecodeIfStale(msg.decoded)
+	if !b.done {
+		return tea.Batch(m.backfillChunkCmd(), redecode)
+	}
+
+	m.debugMsg = fmt.Sprintf("Backfilled %d messages from partition %d, offsets %d-%d", len(m.consumedMessages), b.partition, b.start, b.end-1)
+	if b.note != "" {
+		m.debugMsg += " (" + b.note + ")"
+	}
+	m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Showing 1/%d", len(m.consumedMessages))
+	return redecode
+}
+
+// backfilling reports whether a backfill is still fetching
+func (m Model) backfilling() bool {
+	return m.backfill != nil && !m.backfill.done
+}
+
+// renderBackfillProgress draws a progress bar for a running backfill
+func (m Model) renderBackfillProgress(width int) string {
+	b := m.backfill
+	total := b.end - b.start
+	if total <= 0 {
+		return HelpStyle.Render("Resolving range...")
+	}
+
+	fetched := b.next - b.start
+	barWidth := max(width-24, 10)
+	filled := int(float64(barWidth) * float64(fetched) / float64(total))
+	bar := strings.Repeat("█", filled) + strings.Repeat("░", barWidth-filled)
+	return lipgloss.NewStyle().Foreground(lipgloss.Color("3")).Render(
+		fmt.Sprintf("%s %3.0f%% %d/%d", bar, 100*float64(fetched)/float64(total), fetched, total))
+}
@@ -0,0 +1,20 @@
+package ui
+
+import (
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// renderBanner returns the production warning banner followed by a newline,
+// or an empty string when the active profile is not marked as production
+func (m Model) renderBanner() string {
+	if !m.cfg.Production {
+		return ""
+	}
+
+	text := m.cfg.BannerText
+	if text == "" {
+		text = config.DefaultBannerText
+	}
+
+	return BannerStyle.Width(m.width).Render(text+"  "+m.cfg.Profile) + "\n"
+}
@@ -0,0 +1,43 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+)
+
+// saveBookmark remembers the offset of the message being viewed
+func (m *Model) saveBookmark() {
+	if m.consumer == nil || m.currentMsgIdx >= len(m.consumedMessages) {
+		return
+	}
+	topic := m.topic()
+	offset := m.consumedMessages[m.currentMsgIdx].Offset
+	if err := m.bookmarks.Set(m.cfg.Profile, topic, m.consumer.Partition(), offset); err != nil {
+		m.err = err
+	}
+}
+
+// resumeFromBookmark seeks to the last offset viewed on the topic and fetches
+// from there
+func (m *Model) resumeFromBookmark() tea.Cmd {
+	if m.consumer == nil || m.isLoadingMessages || m.backfilling() || m.tailFetching {
+		return nil
+	}
+
+	topic := m.topic()
+	offset, ok := m.bookmarks.Get(m.cfg.Profile, topic, m.consumer.Partition())
+	if !ok {
+		m.debugMsg = fmt.Sprintf("No bookmark for %s yet. Press 'f' to fetch messages.", topic)
+		return nil
+	}
+	if err := m.consumer.SeekTo(offset); err != nil {
+		m.debugMsg = fmt.Sprintf("ERROR: Failed to seek to offset %d: %v", offset, err)
+		return nil
+	}
+
+	m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Resuming %s at offset %d...", topic, offset)
+	m.isLoadingMessages = true
+	m.debugMsg = "Fetching messages..."
+	return tea.Batch(m.fetchMessagesCmd(), m.tickCmd())
+}
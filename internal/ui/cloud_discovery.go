@@ -0,0 +1,226 @@
+package ui
+
+import (
+	"os"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/cloud"
+)
+
+// cloudTargetsMsg carries the result of a Confluent Cloud discovery
+type cloudTargetsMsg struct {
+	targets []cloud.Target
+	err     error
+}
+
+// CloudDiscoveryModel asks for a Confluent Cloud API key, lists the clusters
+// it can see and lets the user pick one to prefill a new profile
+type CloudDiscoveryModel struct {
+	fields      []formField
+	focusedIdx  int
+	loading     bool
+	targets     []cloud.Target
+	selectedIdx int
+	chosen      *cloud.Target
+	err         string
+	quit        bool
+}
+
+// NewCloudDiscovery creates the discovery form, prefilled from the
+// CONFLUENT_CLOUD_API_KEY/SECRET environment variables used by the confluent CLI
+func NewCloudDiscovery() CloudDiscoveryModel {
+	return CloudDiscoveryModel{
+		fields: []formField{
+			{label: "Cloud API Key", value: os.Getenv("CONFLUENT_CLOUD_API_KEY"), placeholder: "Cloud resource management key"},
+			{label: "Cloud API Secret", value: os.Getenv("CONFLUENT_CLOUD_API_SECRET"), placeholder: "", masked: true},
+		},
+	}
+}
+
+func (m CloudDiscoveryModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m CloudDiscoveryModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case cloudTargetsMsg:
+		m.loading = false
+		if msg.err != nil {
+			m.err = msg.err.Error()
+			return m, nil
+		}
+		if len(msg.targets) == 0 {
+			m.err = "no Kafka clusters found for this API key"
+			return m, nil
+		}
+		m.targets = msg.targets
+		m.selectedIdx = 0
+		m.err = ""
+	case tea.KeyMsg:
+		if m.loading {
+			if msg.String() == "esc" {
+				m.quit = true
+			}
+			return m, nil
+		}
+		if m.targets != nil {
+			return m.updatePicker(msg)
+		}
+		return m.updateForm(msg)
+	}
+	return m, nil
+}
+
+func (m CloudDiscoveryModel) updateForm(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.quit = true
+	case "tab", "shift+tab":
+		m.focusedIdx = (m.focusedIdx + 1) % len(m.fields)
+	case "enter":
+		if m.focusedIdx < len(m.fields)-1 {
+			m.focusedIdx++
+			return m, nil
+		}
+		key := strings.TrimSpace(m.fields[0].value)
+		secret := strings.TrimSpace(m.fields[1].value)
+		if key == "" || secret == "" {
+			m.err = "API key and secret are required"
+			return m, nil
+		}
+		m.loading = true
+		m.err = ""
+		return m, discoverClusters(key, secret)
+	default:
+		field := &m.fields[m.focusedIdx]
+		if msg.Type == tea.KeyRunes {
+			field.value += string(msg.Runes)
+		} else if msg.String() == "backspace" {
+			if len(field.value) > 0 {
+				runes := []rune(field.value)
+				field.value = string(runes[:len(runes)-1])
+			}
+		} else if msg.String() == "ctrl+u" {
+			field.value = ""
+		}
+	}
+	return m, nil
+}
+
+func (m CloudDiscoveryModel) updatePicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc":
+		m.quit = true
+	case "j", "down":
+		if m.selectedIdx < len(m.targets)-1 {
+			m.selectedIdx++
+		}
+	case "k", "up":
+		if m.selectedIdx > 0 {
+			m.selectedIdx--
+		}
+	case "enter":
+		target := m.targets[m.selectedIdx]
+		m.chosen = &target
+		m.quit = true
+	}
+	return m, nil
+}
+
+func discoverClusters(key, secret string) tea.Cmd {
+	return func() tea.Msg {
+		targets, err := cloud.NewClient(key, secret).Discover()
+		return cloudTargetsMsg{targets: targets, err: err}
+	}
+}
+
+func (m CloudDiscoveryModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render("Discover Confluent Cloud Clusters") + "\n\n"
+
+	switch {
+	case m.loading:
+		s += "Querying Confluent Cloud...\n\n"
+		s += lipgloss.NewStyle().Faint(true).Render("[esc] Cancel") + "\n"
+		return s
+
+	case m.targets != nil:
+		for i, t := range m.targets {
+			line := t.Label()
+			if t.SchemaRegistryURL == "" {
+				line += lipgloss.NewStyle().Faint(true).Render("  (no schema registry)")
+			}
+			if i == m.selectedIdx {
+				s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true).Render("> "+line) + "\n"
+			} else {
+				s += "  " + line + "\n"
+			}
+		}
+		if m.selectedIdx < len(m.targets) {
+			t := m.targets[m.selectedIdx]
+			s += "\n  Kafka Bootstrap: " + t.Cluster.BootstrapServers + "\n"
+			s += "  Schema Registry: " + t.SchemaRegistryURL + "\n"
+		}
+		s += "\n" + lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [enter] Create profile  [esc] Cancel") + "\n"
+		return s
+	}
+
+	for i, field := range m.fields {
+		prefix := "  "
+		if i == m.focusedIdx {
+			prefix = "> "
+		}
+
+		label := lipgloss.NewStyle().Width(20).Render(field.label + ":")
+		value := field.value
+		if field.masked && len(value) > 0 {
+			value = strings.Repeat("*", len(value))
+		}
+		if value == "" {
+			value = lipgloss.NewStyle().Faint(true).Render(field.placeholder)
+		}
+
+		if i == m.focusedIdx {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true).Render(prefix+label+" "+value) + "\n"
+		} else {
+			s += prefix + label + " " + value + "\n"
+		}
+	}
+
+	s += "\n"
+	if m.err != "" {
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ Error: "+m.err) + "\n\n"
+	}
+	s += lipgloss.NewStyle().Faint(true).Render("Cluster and schema registry API keys are entered on the next screen") + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[tab] Next  [enter] Next / Discover  [esc] Cancel") + "\n"
+
+	return s
+}
+
+// Chosen returns the picked cluster, or nil if discovery was cancelled
+func (m CloudDiscoveryModel) Chosen() *cloud.Target {
+	return m.chosen
+}
+
+// Quit returns whether discovery is finished
+func (m CloudDiscoveryModel) Quit() bool {
+	return m.quit
+}
+
+// cloudProfileName derives a profile key like "prod-orders" from a target
+func cloudProfileName(t cloud.Target) string {
+	name := strings.ToLower(t.Environment.Name + "-" + t.Cluster.Name)
+	name = strings.Map(func(r rune) rune {
+		if (r >= 'a' && r <= 'z') || (r >= '0' && r <= '9') || r == '-' || r == '_' {
+			return r
+		}
+		return '-'
+	}, name)
+	for strings.Contains(name, "--") {
+		name = strings.ReplaceAll(name, "--", "-")
+	}
+	return strings.Trim(name, "-")
+}
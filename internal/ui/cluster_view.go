@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+type clusterLoadedMsg struct {
+	info *kafka.ClusterInfo
+	err  error
+}
+
+// startClusterInfo fetches the cluster overview for the report pane
+func (m *Model) startClusterInfo() tea.Cmd {
+	m.statusMsg = fmt.Sprintf("Describing cluster at %s...", m.cfg.KafkaBootstrapServers)
+	cfg := m.cfg
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		info, err := kafka.DescribeCluster(ctx, cfg)
+		return clusterLoadedMsg{info: info, err: err}
+	}
+}
+
+func (m *Model) handleClusterLoaded(msg clusterLoadedMsg) {
+	if msg.err != nil {
+		m.statusMsg = ""
+		m.err = fmt.Errorf("describing cluster: %w", msg.err)
+		return
+	}
+	m.statusMsg = fmt.Sprintf("Cluster with %d brokers", len(msg.info.Brokers))
+	m.openReport("Kafka Cluster", report.FormatCluster(msg.info))
+}
@@ -0,0 +1,139 @@
+package ui
+
+import (
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/jsonpath"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// messageColumn is one column of a tabular message view: a message
+// attribute (offset, key, timestamp) or a JSONPath into the decoded value
+type messageColumn struct {
+	header string
+	path   *jsonpath.Path // nil for message attributes
+}
+
+// messageAttributes are the column names that refer to the message itself
+var messageAttributes = []string{"offset", "key", "timestamp"}
+
+// parseColumns parses a comma-separated column list such as
+// "offset, key, $.status, $.amount"
+func parseColumns(spec string) ([]messageColumn, error) {
+	var columns []messageColumn
+	for _, part := range strings.Split(spec, ",") {
+		part = strings.TrimSpace(part)
+		if part == "" {
+			continue
+		}
+		if containsFold(messageAttributes, part) {
+			columns = append(columns, messageColumn{header: strings.ToLower(part)})
+			continue
+		}
+		path, err := jsonpath.Parse(part)
+		if err != nil {
+			return nil, err
+		}
+		columns = append(columns, messageColumn{header: part, path: &path})
+	}
+	return columns, nil
+}
+
+// defaultColumns returns the message attributes followed by the schema's
+// top-level fields
+func defaultColumns(schemaJSON string) []messageColumn {
+	columns := []messageColumn{{header: "offset"}, {header: "key"}, {header: "timestamp"}}
+
+	fields, err := avro.FlattenFields(schemaJSON)
+	if err != nil {
+		return columns
+	}
+	for _, f := range fields {
+		if strings.ContainsAny(f.Path, ".[{") {
+			continue
+		}
+		path, err := jsonpath.Parse(f.Path)
+		if err != nil {
+			continue
+		}
+		columns = append(columns, messageColumn{header: f.Path, path: &path})
+	}
+	return columns
+}
+
+// decodedMessage is a consumed message with its value decoded for columns
+// and for the message view
+type decodedMessage struct {
+	msg   kafka.Message
+	key   string
+	doc   interface{} // nil if the value couldn't be decoded
+	value decodedValue
+}
+
+// decodeMessages decodes messages to plain JSON documents for columns, and
+// with decode for the message view. Called from fetch commands, so that
+// schema lookups don't happen while rendering.
+func decodeMessages(decoder *avro.WireDecoder, decode func(payload string) decodedValue, messages []kafka.Message) []decodedMessage {
+	decoded := make([]decodedMessage, 0, len(messages))
+	for _, msg := range messages {
+		d := decodedMessage{msg: msg, key: Model{}.decodeKey(msg.Key), value: decode(msg.Value)}
+		if data, err := base64.StdEncoding.DecodeString(msg.Value); err == nil {
+			_, d.doc, _ = decoder.Decode(data)
+		}
+		decoded = append(decoded, d)
+	}
+	return decoded
+}
+
+// value returns the column's raw value for a message, and false if the
+// message has no value there
+func (c messageColumn) value(d decodedMessage) (interface{}, bool) {
+	switch {
+	case c.path != nil:
+		return c.path.Get(d.doc)
+	case c.header == "offset":
+		return d.msg.Offset, true
+	case c.header == "key":
+		return d.key, true
+	case c.header == "timestamp":
+		return d.msg.Timestamp, true
+	}
+	return nil, false
+}
+
+// cell formats the column's value for a message as text: strings as-is,
+// nested values as compact JSON, missing values and nulls empty
+func (c messageColumn) cell(d decodedMessage) string {
+	v, ok := c.value(d)
+	if !ok || v == nil {
+		return ""
+	}
+
+	switch v := v.(type) {
+	case string:
+		return v
+	case time.Time:
+		return v.Format(time.RFC3339Nano)
+	case int64:
+		return fmt.Sprintf("%d", v)
+	}
+	data, err := json.Marshal(v)
+	if err != nil {
+		return fmt.Sprint(v)
+	}
+	return string(data)
+}
+
+func containsFold(list []string, s string) bool {
+	for _, item := range list {
+		if strings.EqualFold(item, s) {
+			return true
+		}
+	}
+	return false
+}
@@ -0,0 +1,315 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// compatibilityLoadedMsg carries the global compatibility level, and the
+// subject's own level if it has one
+type compatibilityLoadedMsg struct {
+	subject string
+	global  string
+	level   string // Empty when the subject inherits the global level
+	err     error
+}
+
+// compatibilitySetMsg reports changing a compatibility level
+type compatibilitySetMsg struct {
+	subject string // Empty for the global level
+	level   string // Empty when the subject's level was removed
+	err     error
+}
+
+// inheritGlobal is the choice that removes a subject's own level
+const inheritGlobal = "Inherit global"
+
+// CompatibilityModel shows the global and a subject's compatibility levels
+// and changes them, each change confirmed first
+type CompatibilityModel struct {
+	subject    string // Empty to show the global level only
+	global     string
+	level      string
+	production bool
+	cursor     int // 0 is the global level, 1 the subject's
+
+	choosing   bool
+	choice     int
+	confirming bool
+	confirmed  bool
+	applying   bool
+	quit       bool
+}
+
+// NewCompatibility shows the loaded compatibility levels
+func NewCompatibility(production bool, msg compatibilityLoadedMsg) CompatibilityModel {
+	m := CompatibilityModel{subject: msg.subject, global: msg.global, level: msg.level, production: production}
+	if m.subject != "" {
+		m.cursor = 1
+	}
+	return m
+}
+
+func (m CompatibilityModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m CompatibilityModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.applying {
+		return m, nil
+	}
+
+	switch {
+	case m.confirming:
+		switch keyMsg.String() {
+		case "y", "Y":
+			m.confirming = false
+			m.confirmed = true
+			m.applying = true
+		default:
+			m.confirming = false
+		}
+
+	case m.choosing:
+		switch keyMsg.String() {
+		case "esc":
+			m.choosing = false
+		case "j", "down":
+			if m.choice < len(m.choices())-1 {
+				m.choice++
+			}
+		case "k", "up":
+			if m.choice > 0 {
+				m.choice--
+			}
+		case "enter":
+			m.choosing = false
+			if m.chosen() != m.current() {
+				m.confirming = true
+			}
+		}
+
+	default:
+		switch keyMsg.String() {
+		case "esc", "q":
+			m.quit = true
+		case "j", "down":
+			if m.subject != "" && m.cursor == 0 {
+				m.cursor = 1
+			}
+		case "k", "up":
+			m.cursor = 0
+		case "enter", "e":
+			m.choosing = true
+			m.choice = 0
+			for i, level := range m.choices() {
+				if level == m.current() {
+					m.choice = i
+				}
+			}
+		}
+	}
+	return m, nil
+}
+
+// choices lists the levels the selected row can be set to
+func (m CompatibilityModel) choices() []string {
+	if m.cursor == 0 {
+		return registry.CompatibilityLevels
+	}
+	return append([]string{inheritGlobal}, registry.CompatibilityLevels...)
+}
+
+// current returns the selected row's level, as a choice
+func (m CompatibilityModel) current() string {
+	if m.cursor == 0 {
+		return m.global
+	}
+	if m.level == "" {
+		return inheritGlobal
+	}
+	return m.level
+}
+
+func (m CompatibilityModel) chosen() string {
+	return m.choices()[m.choice]
+}
+
+// target names the selected row
+func (m CompatibilityModel) target() string {
+	if m.cursor == 0 {
+		return "the global level"
+	}
+	return m.subject
+}
+
+func (m CompatibilityModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Compatibility") + "\n\n")
+
+	global := m.global
+	if global == "" {
+		global = "unknown"
+	}
+	rows := []string{fmt.Sprintf("%-30s %s", "Global", global)}
+	if m.subject != "" {
+		level := m.level
+		if level == "" {
+			level = fmt.Sprintf("%s (inherited)", global)
+		}
+		rows = append(rows, fmt.Sprintf("%-30s %s", m.subject, level))
+	}
+	for i, row := range rows {
+		if i == m.cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+row) + "\n")
+		} else {
+			b.WriteString(NormalItemStyle.Render("  "+row) + "\n")
+		}
+	}
+
+	if m.choosing {
+		b.WriteString("\n" + ListTitleStyle.Render("Set "+m.target()+" to") + "\n")
+		for i, level := range m.choices() {
+			line := level
+			if level == m.current() {
+				line += " ✓"
+			}
+			if i == m.choice {
+				b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+			} else {
+				b.WriteString(NormalItemStyle.Render("  "+line) + "\n")
+			}
+		}
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.applying:
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Setting %s...", m.target())) + "\n")
+	case m.confirming:
+		prompt := fmt.Sprintf("Set %s from %s to %s? [y/N]", m.target(), m.current(), m.chosen())
+		if m.cursor == 0 {
+			prompt += " Every subject without its own level is checked against it"
+		}
+		if m.production {
+			prompt = "PRODUCTION: " + prompt
+		}
+		b.WriteString(DiffChangedStyle.Render(prompt) + "\n")
+	case m.choosing:
+		b.WriteString(lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [enter] Review change  [esc] Cancel") + "\n")
+	default:
+		b.WriteString(HelpStyle.Render("New versions must be compatible with earlier ones at this level; TRANSITIVE checks every earlier version, not just the latest") + "\n")
+		b.WriteString(lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [enter] Change level  [esc] Close") + "\n")
+	}
+	return b.String()
+}
+
+// Confirmed returns the change confirmed since it was last applied. An
+// empty level removes the subject's own level.
+func (m CompatibilityModel) Confirmed() (subject, level string, ok bool) {
+	if !m.confirmed {
+		return "", "", false
+	}
+	if m.cursor == 1 {
+		subject = m.subject
+	}
+	level = m.chosen()
+	if level == inheritGlobal {
+		level = ""
+	}
+	return subject, level, true
+}
+
+// Quit returns whether the view is closed
+func (m CompatibilityModel) Quit() bool {
+	return m.quit
+}
+
+// startCompatibility loads the global compatibility level, and the selected
+// subject's if there is one
+func (m *Model) startCompatibility() tea.Cmd {
+	m.statusMsg = "Loading compatibility levels..."
+	return loadCompatibility(m.client, m.selectedSubject)
+}
+
+func loadCompatibility(client *registry.Client, subject string) tea.Cmd {
+	return func() tea.Msg {
+		msg := compatibilityLoadedMsg{subject: subject}
+		if msg.global, msg.err = client.GetCompatibility(""); msg.err != nil {
+			return msg
+		}
+		if subject != "" {
+			msg.level, msg.err = client.GetCompatibility(subject)
+		}
+		return msg
+	}
+}
+
+func (m *Model) handleCompatibilityLoaded(msg compatibilityLoadedMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = fmt.Errorf("loading compatibility: %w", msg.err)
+		return
+	}
+	if m.state == stateCompatibility && m.compatibility.subject == msg.subject {
+		// Reloaded after a change; keep the cursor
+		cursor := m.compatibility.cursor
+		m.compatibility = NewCompatibility(m.cfg.Production, msg)
+		m.compatibility.cursor = cursor
+		return
+	}
+	if m.state != stateBrowsing && m.state != stateViewing {
+		return
+	}
+	m.compatibility = NewCompatibility(m.cfg.Production, msg)
+	m.compatibilityReturn = m.state
+	m.state = stateCompatibility
+}
+
+func (m *Model) handleCompatibility(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.compatibility.Update(msg)
+	m.compatibility = newModel.(CompatibilityModel)
+	if m.compatibility.Quit() {
+		m.state = m.compatibilityReturn
+		return m, nil
+	}
+
+	subject, level, ok := m.compatibility.Confirmed()
+	if !ok {
+		return m, cmd
+	}
+	m.compatibility.confirmed = false
+	client := m.client
+	return m, func() tea.Msg {
+		var err error
+		if level == "" {
+			err = client.DeleteCompatibility(subject)
+		} else {
+			err = client.SetCompatibility(subject, level)
+		}
+		return compatibilitySetMsg{subject: subject, level: level, err: err}
+	}
+}
+
+func (m *Model) handleCompatibilitySet(msg compatibilitySetMsg) tea.Cmd {
+	m.compatibility.applying = false
+	if msg.err != nil {
+		m.err = fmt.Errorf("setting compatibility: %w", msg.err)
+		return nil
+	}
+	switch {
+	case msg.subject == "":
+		m.copyNotify = "Set the global compatibility level to " + msg.level
+	case msg.level == "":
+		m.copyNotify = msg.subject + " now inherits the global compatibility level"
+	default:
+		m.copyNotify = fmt.Sprintf("Set the compatibility level of %s to %s", msg.subject, msg.level)
+	}
+	return loadCompatibility(m.client, m.compatibility.subject)
+}
@@ -6,6 +6,7 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/JimmyyyW/avrocado/internal/cloud"
 	"github.com/JimmyyyW/avrocado/internal/config"
 )
 
@@ -15,6 +16,7 @@ type formField struct {
 	placeholder string
 	masked      bool
 	hidden      bool
+	path        pathKind // Path fields can be browsed for with ctrl+f
 }
 
 type ConfigEditorModel struct {
@@ -22,6 +24,7 @@ type ConfigEditorModel struct {
 	profileName string
 	fields      []formField
 	focusedIdx  int
+	picker      pathPicker
 	width       int
 	height      int
 	err         string
@@ -44,13 +47,33 @@ func NewConfigEditor(configFile *config.ConfigFile) ConfigEditorModel {
 			{label: "Schema Registry SASL Username", value: "", placeholder: "(for sasl auth)", hidden: true},
 			{label: "Schema Registry SASL Password", value: "", placeholder: "(for sasl auth)", masked: true, hidden: true},
 			{label: "Kafka Bootstrap Servers", value: "", placeholder: "localhost:9092"},
-			{label: "Kafka Security Protocol", value: "PLAINTEXT", placeholder: "PLAINTEXT|SASL_SSL"},
+			{label: "Kafka Security Protocol", value: "PLAINTEXT", placeholder: "PLAINTEXT|SSL|SASL_PLAINTEXT|SASL_SSL"},
 			{label: "Kafka SASL Username", value: "", placeholder: "(for SASL_SSL)", hidden: true},
 			{label: "Kafka SASL Password", value: "", placeholder: "(for SASL_SSL)", masked: true, hidden: true},
+			{label: "Production", value: "no", placeholder: "yes|no"},
+			{label: "Banner Text", value: "", placeholder: config.DefaultBannerText, hidden: true},
 		},
 	}
 }
 
+// NewConfigEditorForCloudTarget creates a new profile prefilled from a
+// discovered Confluent Cloud cluster. Focus starts on the schema registry
+// API key, since cluster and registry keys are separate from the Cloud key.
+func NewConfigEditorForCloudTarget(configFile *config.ConfigFile, target cloud.Target) ConfigEditorModel {
+	m := NewConfigEditor(configFile)
+	m.fields[0].value = cloudProfileName(target)
+	m.fields[1].value = target.SchemaRegistryURL
+	m.fields[2].value = "basic"
+	m.fields[3].hidden = false
+	m.fields[4].hidden = false
+	m.fields[7].value = target.Cluster.BootstrapServers
+	m.fields[8].value = "SASL_SSL"
+	m.fields[9].hidden = false
+	m.fields[10].hidden = false
+	m.focusedIdx = 3
+	return m
+}
+
 // NewConfigEditorForProfile creates a new config editor for editing an existing profile
 func NewConfigEditorForProfile(configFile *config.ConfigFile, profileName string) ConfigEditorModel {
 	m := NewConfigEditor(configFile)
@@ -94,11 +117,18 @@ func NewConfigEditorForProfile(configFile *config.ConfigFile, profileName string
 			m.fields[6].hidden = false
 		}
 
-		// Show Kafka SASL fields if SASL_SSL is selected
-		if profile.Kafka.SecurityProtocol == "SASL_SSL" {
+		// Show Kafka SASL fields if SASL_SSL or SASL_PLAINTEXT is selected
+		if profile.Kafka.SecurityProtocol == "SASL_SSL" || profile.Kafka.SecurityProtocol == "SASL_PLAINTEXT" {
 			m.fields[9].hidden = false
 			m.fields[10].hidden = false
 		}
+
+		// Load production banner settings
+		if profile.Production {
+			m.fields[11].value = "yes"
+			m.fields[12].hidden = false
+		}
+		m.fields[12].value = profile.Banner
 	}
 
 	return m
@@ -111,11 +141,22 @@ func (m ConfigEditorModel) Init() tea.Cmd {
 func (m ConfigEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
+		if m.picker.active() {
+			if path, ok := m.picker.update(msg); ok {
+				m.fields[m.focusedIdx].value = path
+			}
+			return m, nil
+		}
 		switch msg.String() {
 		case "esc":
 			// Cancel editing
 			m.quit = true
 			return m, nil
+		case "ctrl+f":
+			// Browse for a file path
+			if field := m.fields[m.focusedIdx]; field.path != noPath {
+				m.picker.browse(field.label, field.path, field.value)
+			}
 		case "tab":
 			// Move to next visible field
 			for i := 0; i < len(m.fields); i++ {
@@ -185,14 +226,19 @@ func (m ConfigEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 			// Update hidden fields based on kafka security protocol
 			if m.focusedIdx == 8 { // Kafka Security Protocol field
-				if m.fields[8].value == "SASL_SSL" {
+				if m.fields[8].value == "SASL_SSL" || m.fields[8].value == "SASL_PLAINTEXT" {
 					m.fields[9].hidden = false
 					m.fields[10].hidden = false
-				} else if m.fields[8].value == "PLAINTEXT" {
+				} else if m.fields[8].value == "PLAINTEXT" || m.fields[8].value == "SSL" {
 					m.fields[9].hidden = true
 					m.fields[10].hidden = true
 				}
 			}
+
+			// Show banner text only for production profiles
+			if m.focusedIdx == 11 { // Production field
+				m.fields[12].hidden = m.fields[11].value != "yes"
+			}
 		}
 	case tea.WindowSizeMsg:
 		m.width = msg.Width
@@ -202,6 +248,10 @@ func (m ConfigEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 }
 
 func (m ConfigEditorModel) View() string {
+	if m.picker.active() {
+		return m.picker.view()
+	}
+
 	var s string
 	title := "New Configuration"
 	if !m.isNewConfig {
@@ -253,6 +303,9 @@ func (m ConfigEditorModel) View() string {
 
 	// Determine what button text to show
 	buttonText := "[tab] Next  [shift+tab] Prev  [enter] Save  [esc] Cancel"
+	if m.fields[m.focusedIdx].path != noPath {
+		buttonText = "[tab] Next  [shift+tab] Prev  [ctrl+f] Browse  [enter] Save  [esc] Cancel"
+	}
 	if m.err != "" {
 		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ Error: "+m.err) + "\n\n"
 	}
@@ -297,8 +350,16 @@ func (m *ConfigEditorModel) saveProfile() error {
 	}
 
 	// Create profile config
+	production := m.fields[11].value == "yes"
+	banner := ""
+	if production {
+		banner = m.fields[12].value
+	}
+
 	profile := &config.ProfileConfig{
 		Name:           profileName,
+		Production:     production,
+		Banner:         banner,
 		SchemaRegistry: srConfig,
 		Kafka: config.KafkaConfig{
 			BootstrapServers: kafkaServers,
@@ -319,6 +380,21 @@ func (m *ConfigEditorModel) saveProfile() error {
 		keyName = m.profileName
 	}
 
+	// Keep settings the editor does not expose (SASL mechanism, Kerberos, TLS, SSH tunnel, Avro backend, subject access, naming policy, topics, key records)
+	if existing, ok := m.configFile.Configurations[keyName]; ok && !m.isNewConfig {
+		profile.Kafka.SASLMechanism = existing.Kafka.SASLMechanism
+		profile.Kafka.Kerberos = existing.Kafka.Kerberos
+		profile.Kafka.TLS = existing.Kafka.TLS
+		profile.SchemaRegistry.TLS = existing.SchemaRegistry.TLS
+		profile.SSHTunnel = existing.SSHTunnel
+		profile.AvroBackend = existing.AvroBackend
+		profile.SubjectAccess = existing.SubjectAccess
+		profile.NamingPolicy = existing.NamingPolicy
+		profile.Topics = existing.Topics
+		profile.KeyRecords = existing.KeyRecords
+		profile.SubjectNamingStrategy = existing.SubjectNamingStrategy
+	}
+
 	m.configFile.Configurations[keyName] = profile
 
 	return nil
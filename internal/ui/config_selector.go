@@ -19,6 +19,7 @@ const (
 	stateSelecting selectorState = iota
 	stateEditing
 	stateConfirmDelete
+	stateDiscovering
 )
 
 type ConfigSelectorModel struct {
@@ -31,6 +32,7 @@ type ConfigSelectorModel struct {
 	selectedName  string
 	state         selectorState
 	editor        ConfigEditorModel
+	discovery     CloudDiscoveryModel
 	err           string
 	message       string
 	messageTimer  int
@@ -72,6 +74,9 @@ func (m ConfigSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	if m.state == stateEditing {
 		return m.handleEditorState(msg)
 	}
+	if m.state == stateDiscovering {
+		return m.handleDiscoveryState(msg)
+	}
 
 	switch msg := msg.(type) {
 	case tea.KeyMsg:
@@ -101,6 +106,10 @@ func (m ConfigSelectorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			// Create new configuration
 			m.state = stateEditing
 			m.editor = NewConfigEditor(m.configFile)
+		case "c":
+			// Create a configuration from a Confluent Cloud cluster
+			m.state = stateDiscovering
+			m.discovery = NewCloudDiscovery()
 		case "e":
 			// Edit selected configuration
 			if m.selectedIdx >= 0 && m.selectedIdx < len(m.profiles) {
@@ -166,13 +175,33 @@ func (m *ConfigSelectorModel) handleEditorState(msg tea.Msg) (tea.Model, tea.Cmd
 	return m, cmd
 }
 
+func (m *ConfigSelectorModel) handleDiscoveryState(msg tea.Msg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.discovery.Update(msg)
+	m.discovery = newModel.(CloudDiscoveryModel)
+
+	if m.discovery.Quit() {
+		if target := m.discovery.Chosen(); target != nil {
+			m.state = stateEditing
+			m.editor = NewConfigEditorForCloudTarget(m.configFile, *target)
+		} else {
+			m.state = stateSelecting
+		}
+		m.discovery = CloudDiscoveryModel{}
+	}
+
+	return m, cmd
+}
+
 func (m ConfigSelectorModel) View() string {
 	if m.state == stateEditing {
 		return m.editor.View()
 	}
+	if m.state == stateDiscovering {
+		return m.discovery.View()
+	}
 
 	if len(m.profiles) == 0 {
-		return "No configurations found. Create one with 'n'.\n"
+		return "No configurations found. Create one with 'n', or from Confluent Cloud with 'c'.\n"
 	}
 
 	var s string
@@ -212,6 +241,14 @@ func (m ConfigSelectorModel) View() string {
 			}
 			if profile.Kafka.SASLUsername != "" {
 				s += "  Kafka Auth: Yes (" + profile.Kafka.SASLMechanism + ")\n"
+			} else if profile.Kafka.Kerberos != nil {
+				s += "  Kafka Auth: Kerberos (GSSAPI)\n"
+			}
+			if profile.SSHTunnel != nil && profile.SSHTunnel.Host != "" {
+				s += "  SSH Tunnel: " + sshTarget(profile.SSHTunnel) + "\n"
+			}
+			if profile.Production {
+				s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true).Render("  Production: Yes") + "\n"
 			}
 		}
 	}
@@ -224,7 +261,7 @@ func (m ConfigSelectorModel) View() string {
 		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ "+m.err) + "\n\n"
 	}
 
-	s += lipgloss.NewStyle().Faint(true).Render("[enter] Select  [n] New  [e] Edit  [d] Default  [q] Quit") + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[enter] Select  [n] New  [c] From Confluent Cloud  [e] Edit  [d] Default  [q] Quit") + "\n"
 
 	return s
 }
@@ -238,6 +275,11 @@ func (m ConfigSelectorModel) SelectedProfile() *config.ProfileConfig {
 	return nil
 }
 
+// SelectedName returns the key of the selected profile
+func (m ConfigSelectorModel) SelectedName() string {
+	return m.selectedName
+}
+
 func (m *ConfigSelectorModel) saveConfigFile() error {
 	// Create directory if it doesn't exist
 	dir := filepath.Dir(m.configPath)
@@ -258,3 +300,10 @@ func (m *ConfigSelectorModel) saveConfigFile() error {
 
 	return nil
 }
+
+func sshTarget(t *config.SSHTunnelConfig) string {
+	if t.User == "" {
+		return t.Host
+	}
+	return t.User + "@" + t.Host
+}
@@ -0,0 +1,236 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// contractCheckedMsg carries the result of checking a payload against a
+// consumer's reader schema
+type contractCheckedMsg struct {
+	consumer string
+	source   string
+	problems []string
+	err      error
+}
+
+// ContractPromptModel asks which consumer to test the payload against: a
+// contract name from the config file, an .avsc file or a subject[@version]
+type ContractPromptModel struct {
+	subject   string
+	contracts map[string]string
+	input     string
+	picker    pathPicker
+	chosen    string
+	quit      bool
+}
+
+// NewContractPrompt creates the prompt, prefilled with the consumer last
+// tested for this subject
+func NewContractPrompt(subject string, contracts map[string]string, last string) ContractPromptModel {
+	return ContractPromptModel{
+		subject:   subject,
+		contracts: contracts,
+		input:     last,
+	}
+}
+
+func (m ContractPromptModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ContractPromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.picker.active() {
+			if path, ok := m.picker.update(msg); ok {
+				m.input = path
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			m.quit = true
+		case "ctrl+f":
+			// Browse for a reader schema file
+			start := m.input
+			if !strings.HasSuffix(start, ".avsc") {
+				start = ""
+			}
+			m.picker.browse("Reader Schema", openPath, start, ".avsc")
+		case "enter":
+			if strings.TrimSpace(m.input) != "" {
+				m.chosen = strings.TrimSpace(m.input)
+				m.quit = true
+			}
+		case "tab":
+			m.input = m.nextContract()
+		case "backspace":
+			if len(m.input) > 0 {
+				runes := []rune(m.input)
+				m.input = string(runes[:len(runes)-1])
+			}
+		case "ctrl+u":
+			m.input = ""
+		default:
+			if msg.Type == tea.KeyRunes {
+				m.input += string(msg.Runes)
+			}
+		}
+	}
+	return m, nil
+}
+
+// nextContract cycles through the configured contract names
+func (m ContractPromptModel) nextContract() string {
+	names := m.contractNames()
+	if len(names) == 0 {
+		return m.input
+	}
+	for i, name := range names {
+		if name == m.input {
+			return names[(i+1)%len(names)]
+		}
+	}
+	return names[0]
+}
+
+func (m ContractPromptModel) contractNames() []string {
+	names := make([]string, 0, len(m.contracts))
+	for name := range m.contracts {
+		names = append(names, name)
+	}
+	sort.Strings(names)
+	return names
+}
+
+func (m ContractPromptModel) View() string {
+	if m.picker.active() {
+		return m.picker.view()
+	}
+
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render("Contract Test") + "\n\n"
+	s += fmt.Sprintf("Check that a consumer can read this %s payload with its reader schema.\n\n", m.subject)
+
+	s += "Consumer (contract name, .avsc file or subject[@version]):\n"
+	s += "> " + m.input + "\n\n"
+
+	if names := m.contractNames(); len(names) > 0 {
+		s += "Configured contracts:\n"
+		for _, name := range names {
+			s += lipgloss.NewStyle().Faint(true).Render(fmt.Sprintf("  %s → %s", name, m.contracts[name])) + "\n"
+		}
+		s += "\n"
+	}
+
+	s += lipgloss.NewStyle().Faint(true).Render("[enter] Check  [tab] Next contract  [ctrl+f] Browse for .avsc  [esc] Cancel") + "\n"
+
+	return s
+}
+
+// Chosen returns the entered consumer, or "" if the prompt was cancelled
+func (m ContractPromptModel) Chosen() string {
+	return m.chosen
+}
+
+// Quit returns whether the prompt is finished
+func (m ContractPromptModel) Quit() bool {
+	return m.quit
+}
+
+func (m *Model) handleContractPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.contractPrompt.Update(msg)
+	m.contractPrompt = newModel.(ContractPromptModel)
+
+	if !m.contractPrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateSendMode
+	consumer := m.contractPrompt.Chosen()
+	if consumer == "" {
+		return m, nil
+	}
+
+	if m.lastContract == nil {
+		m.lastContract = make(map[string]string)
+	}
+	m.lastContract[m.selectedSubject] = consumer
+	m.statusMsg = fmt.Sprintf("[SEND MODE] Checking contract: %s", consumer)
+	return m, checkContract(m.client, m.cfg.Contracts, consumer, m.rawSchema, m.editor.Value())
+}
+
+func (m *Model) handleContractChecked(msg contractCheckedMsg) {
+	m.statusMsg = fmt.Sprintf("[SEND MODE] Target: %s", m.topic())
+	if msg.err != nil {
+		m.err = fmt.Errorf("contract test %s: %w", msg.consumer, msg.err)
+		return
+	}
+	m.openReport("Contract Test: "+msg.consumer, renderContractResult(msg))
+}
+
+// checkContract loads the consumer's reader schema and checks the payload
+// resolves under it
+func checkContract(client *registry.Client, contracts map[string]string, consumer, writerSchema, payload string) tea.Cmd {
+	return func() tea.Msg {
+		source := consumer
+		if configured, ok := contracts[consumer]; ok {
+			source = configured
+		}
+
+		readerSchema, err := loadSchema(client, source)
+		if err != nil {
+			return contractCheckedMsg{consumer: consumer, source: source, err: err}
+		}
+
+		problems, err := avro.CheckResolution(writerSchema, readerSchema, payload)
+		return contractCheckedMsg{consumer: consumer, source: source, problems: problems, err: err}
+	}
+}
+
+// loadSchema reads a schema from an existing file, or otherwise from the
+// registry as subject or subject@version
+func loadSchema(client *registry.Client, source string) (string, error) {
+	if _, err := os.Stat(source); err == nil {
+		data, err := os.ReadFile(source)
+		if err != nil {
+			return "", fmt.Errorf("reading schema: %w", err)
+		}
+		return string(data), nil
+	}
+
+	schema, err := client.GetSchemaRef(source)
+	if err != nil {
+		return "", fmt.Errorf("fetching schema %s: %w", source, err)
+	}
+	return schema.Schema, nil
+}
+
+func renderContractResult(msg contractCheckedMsg) string {
+	var b strings.Builder
+
+	b.WriteString(HelpStyle.Render("Reader schema: " + msg.source))
+	b.WriteString("\n\n")
+
+	if len(msg.problems) == 0 {
+		b.WriteString(SuccessStyle.Render(fmt.Sprintf("✓ %s can read this payload", msg.consumer)))
+		return b.String()
+	}
+
+	b.WriteString(ErrorStyle.Render(fmt.Sprintf("✗ %s would fail to read this payload (%d problems)", msg.consumer, len(msg.problems))))
+	b.WriteString("\n\n")
+	for _, p := range msg.problems {
+		b.WriteString("  " + p + "\n")
+	}
+
+	return b.String()
+}
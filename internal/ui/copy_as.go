@@ -0,0 +1,120 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/deeplink"
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+// copyAsOption is an entry in the "copy as" menu. Its produce function
+// builds the text to copy and may be slow, so it runs as a command.
+type copyAsOption struct {
+	label   string
+	produce func(m Model) (string, error)
+}
+
+// copyAsMsg carries the produced text back to the model for copying
+type copyAsMsg struct {
+	label   string
+	content string
+	err     error
+}
+
+func copyAsOptions() []copyAsOption {
+	return []copyAsOption{
+		{label: "Schema (pretty JSON)", produce: func(m Model) (string, error) {
+			return m.currentSchema, nil
+		}},
+		{label: "Schema (compact JSON)", produce: func(m Model) (string, error) {
+			var parsed interface{}
+			if err := json.Unmarshal([]byte(m.rawSchema), &parsed); err != nil {
+				return "", fmt.Errorf("parsing schema: %w", err)
+			}
+			compact, err := json.Marshal(parsed)
+			return string(compact), err
+		}},
+		{label: "Deep link (avrocado://)", produce: func(m Model) (string, error) {
+			return deeplink.SubjectLink(m.cfg.Profile, m.selectedSubject), nil
+		}},
+		{label: "Changelog (Markdown)", produce: func(m Model) (string, error) {
+			versions, err := m.client.GetAllVersions(m.selectedSubject)
+			if err != nil {
+				return "", fmt.Errorf("fetching versions: %w", err)
+			}
+			return report.Changelog(m.selectedSubject, versions)
+		}},
+	}
+}
+
+func (m *Model) enterCopyAs() {
+	m.copyAsIdx = 0
+	m.copyAsReturn = m.state
+	m.state = stateCopyAs
+}
+
+func (m *Model) handleCopyAs(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	options := copyAsOptions()
+
+	switch msg.String() {
+	case "esc", "q":
+		m.state = m.copyAsReturn
+	case "j", "down":
+		if m.copyAsIdx < len(options)-1 {
+			m.copyAsIdx++
+		}
+	case "k", "up":
+		if m.copyAsIdx > 0 {
+			m.copyAsIdx--
+		}
+	case "enter":
+		option := options[m.copyAsIdx]
+		m.state = m.copyAsReturn
+		m.statusMsg = fmt.Sprintf("Preparing %s...", option.label)
+		model := *m
+		return m, func() tea.Msg {
+			content, err := option.produce(model)
+			return copyAsMsg{label: option.label, content: content, err: err}
+		}
+	}
+	return m, nil
+}
+
+// handleCopyAsResult copies the produced text to the clipboard
+func (m *Model) handleCopyAsResult(msg copyAsMsg) {
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	if err := clipboard.WriteAll(msg.content); err != nil {
+		m.err = fmt.Errorf("failed to copy: %w", err)
+		return
+	}
+	m.copyNotify = fmt.Sprintf("Copied %s to clipboard!", msg.label)
+}
+
+func (m Model) renderCopyAs(width, height int) string {
+	var b strings.Builder
+
+	b.WriteString(ListTitleStyle.Render("Copy As"))
+	b.WriteString("\n\n")
+
+	for i, option := range copyAsOptions() {
+		if i == m.copyAsIdx {
+			b.WriteString(SelectedItemStyle.Render("> " + option.label))
+		} else {
+			b.WriteString(NormalItemStyle.Render("  " + option.label))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("[enter] Copy  [esc] Cancel"))
+
+	return b.String()
+}
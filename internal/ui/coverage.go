@@ -0,0 +1,40 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+type coverageLoadedMsg struct {
+	results []report.Coverage
+}
+
+// loadCoverage computes doc coverage for the currently filtered subjects
+func (m Model) loadCoverage() tea.Cmd {
+	subjects := append([]string(nil), m.filteredSubjects...)
+	client := m.client
+
+	return func() tea.Msg {
+		results := make([]report.Coverage, 0, len(subjects))
+		for _, subject := range subjects {
+			schema, err := client.GetLatestSchema(subject)
+			if err != nil {
+				results = append(results, report.Coverage{Subject: subject, Err: err})
+				continue
+			}
+			results = append(results, report.DocCoverage(subject, schema.Schema))
+		}
+		return coverageLoadedMsg{results: results}
+	}
+}
+
+func (m *Model) startCoverage() tea.Cmd {
+	if len(m.filteredSubjects) == 0 {
+		return nil
+	}
+	m.statusMsg = fmt.Sprintf("Computing doc coverage for %d subjects...", len(m.filteredSubjects))
+	return m.loadCoverage()
+}
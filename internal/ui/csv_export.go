@@ -0,0 +1,197 @@
+package ui
+
+import (
+	"encoding/csv"
+	"fmt"
+	"os"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// CSVExportModel asks which columns to export and where to write the file
+type CSVExportModel struct {
+	count      int
+	fields     []formField
+	focusedIdx int
+	picker     pathPicker
+	saved      bool
+	quit       bool
+}
+
+// NewCSVExport creates the export form for count messages from topic
+func NewCSVExport(topic string, count int) CSVExportModel {
+	return CSVExportModel{
+		count: count,
+		fields: []formField{
+			{label: "Columns", placeholder: "offset, key, $.status, $.amount (empty: top-level fields)"},
+			{label: "File", value: fmt.Sprintf("%s-%s.csv", topic, time.Now().Format("20060102-150405")), path: savePath},
+		},
+	}
+}
+
+func (m CSVExportModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m CSVExportModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.picker.active() {
+			if path, ok := m.picker.update(msg); ok {
+				m.fields[m.focusedIdx].value = path
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			m.quit = true
+			return m, nil
+		case "ctrl+f":
+			if field := m.fields[m.focusedIdx]; field.path != noPath {
+				m.picker.browse("Export To", field.path, field.value)
+			}
+		case "tab", "shift+tab":
+			m.focusedIdx = (m.focusedIdx + 1) % len(m.fields)
+		case "enter":
+			if m.focusedIdx == len(m.fields)-1 {
+				m.saved = true
+				m.quit = true
+				return m, nil
+			}
+			m.focusedIdx++
+		default:
+			field := &m.fields[m.focusedIdx]
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				field.value += string(msg.Runes)
+			} else if msg.String() == "backspace" {
+				if len(field.value) > 0 {
+					runes := []rune(field.value)
+					field.value = string(runes[:len(runes)-1])
+				}
+			} else if msg.String() == "ctrl+u" {
+				field.value = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m CSVExportModel) View() string {
+	if m.picker.active() {
+		return m.picker.view()
+	}
+
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Export %d Messages to CSV", m.count)) + "\n\n"
+
+	for i, field := range m.fields {
+		prefix := "  "
+		if i == m.focusedIdx {
+			prefix = "> "
+		}
+
+		label := lipgloss.NewStyle().Width(12).Render(field.label + ":")
+		value := field.value
+		if value == "" {
+			value = lipgloss.NewStyle().Faint(true).Render(field.placeholder)
+		}
+
+		if i == m.focusedIdx {
+			s += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("11")).
+				Bold(true).
+				Render(prefix+label+" "+value) + "\n"
+		} else {
+			s += prefix + label + " " + value + "\n"
+		}
+	}
+
+	s += "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("Columns are offset, key, timestamp or JSONPaths into the decoded value") + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[tab] Next  [enter] Next / Export  [ctrl+f] Browse for file  [esc] Cancel") + "\n"
+
+	return s
+}
+
+// Columns returns the entered column list
+func (m CSVExportModel) Columns() string {
+	return strings.TrimSpace(m.fields[0].value)
+}
+
+// FilePath returns the entered output file
+func (m CSVExportModel) FilePath() string {
+	return strings.TrimSpace(m.fields[1].value)
+}
+
+// Saved returns whether the user confirmed the export
+func (m CSVExportModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the form is closed
+func (m CSVExportModel) Quit() bool {
+	return m.quit
+}
+
+func (m *Model) handleExportingCSV(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.csvExport.Update(msg)
+	m.csvExport = newModel.(CSVExportModel)
+
+	if !m.csvExport.Quit() {
+		return m, cmd
+	}
+	m.state = stateConsumerMode
+	if !m.csvExport.Saved() {
+		return m, nil
+	}
+
+	columns := defaultColumns(m.rawSchema)
+	if spec := m.csvExport.Columns(); spec != "" {
+		var err error
+		if columns, err = parseColumns(spec); err != nil {
+			m.err = err
+			return m, nil
+		}
+	}
+
+	path := m.csvExport.FilePath()
+	if err := writeMessagesCSV(path, columns, m.visibleMessages()); err != nil {
+		m.err = err
+		return m, nil
+	}
+	m.copyNotify = fmt.Sprintf("Exported %d messages to %s", len(m.consumedMessages), path)
+	return m, nil
+}
+
+// writeMessagesCSV writes one row per message with a header row of column names
+func writeMessagesCSV(path string, columns []messageColumn, messages []decodedMessage) error {
+	f, err := os.Create(path)
+	if err != nil {
+		return fmt.Errorf("creating CSV file: %w", err)
+	}
+	defer f.Close()
+
+	w := csv.NewWriter(f)
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		header[i] = c.header
+	}
+	w.Write(header)
+
+	for _, d := range messages {
+		row := make([]string, len(columns))
+		for i, c := range columns {
+			row[i] = c.cell(d)
+		}
+		w.Write(row)
+	}
+
+	w.Flush()
+	if err := w.Error(); err != nil {
+		return fmt.Errorf("writing CSV file: %w", err)
+	}
+	return nil
+}
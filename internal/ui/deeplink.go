@@ -0,0 +1,65 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/deeplink"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/session"
+)
+
+// OpenLink queues a deep link to be opened once subjects are loaded, as a
+// restored session is: its subject's schema, or the consumer on its topic
+// at the linked message
+func (m *Model) OpenLink(link *deeplink.Link) {
+	state := &session.State{Profile: m.cfg.Profile, Subject: link.Subject, FocusedPane: "viewer", View: "schema"}
+	if link.Topic != "" {
+		state.Subject = m.cfg.TopicSubject(link.Topic)
+		state.View = "consumer"
+		if state.Subject == "" {
+			m.err = fmt.Errorf("no subject for topic %s under the %s naming strategy; set one under the profile's topics", link.Topic, m.cfg.NamingStrategy())
+			return
+		}
+	}
+	m.link = link
+	m.restore = state
+}
+
+// openLinkedMessage fetches from the linked offset once the consumer is
+// open on the linked topic
+func (m *Model) openLinkedMessage() tea.Cmd {
+	link := m.link
+	m.link = nil
+	if link == nil || link.Topic == "" || m.consumer == nil {
+		return nil
+	}
+
+	topic := m.topic()
+	if link.Partition != m.consumer.Partition() {
+		consumer, err := kafka.NewPartitionConsumer(m.cfg, topic, link.Partition)
+		if err != nil {
+			m.debugMsg = fmt.Sprintf("ERROR: Failed to open partition %d: %v", link.Partition, err)
+			return nil
+		}
+		m.setConsumer(consumer)
+	}
+	if err := m.consumer.SeekTo(link.Offset); err != nil {
+		m.debugMsg = fmt.Sprintf("ERROR: Failed to seek to offset %d: %v", link.Offset, err)
+		return nil
+	}
+
+	m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Opening %s partition %d at offset %d...", topic, link.Partition, link.Offset)
+	m.isLoadingMessages = true
+	m.debugMsg = "Fetching messages..."
+	return tea.Batch(m.fetchMessagesCmd(), m.tickCmd())
+}
+
+// messageLink returns the deep link to a consumed message
+func (m Model) messageLink(msg kafka.Message) string {
+	if m.consumer == nil {
+		return ""
+	}
+	return deeplink.MessageLink(m.cfg.Profile, m.topic(), m.consumer.Partition(), msg.Offset)
+}
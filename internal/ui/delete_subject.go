@@ -0,0 +1,181 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// subjectDeletedMsg reports deleting a subject, or one of its versions
+type subjectDeletedMsg struct {
+	subject   string
+	version   int // 0 when the whole subject was deleted
+	permanent bool
+	deleted   []int
+	gone      bool // No versions are left
+	err       error
+}
+
+// DeleteSubjectModel confirms deleting the viewed version of a subject, or
+// the whole subject, optionally permanently
+type DeleteSubjectModel struct {
+	subject    string
+	version    int
+	production bool
+	whole      bool // Every version rather than the viewed one
+	permanent  bool
+	confirmed  bool
+	quit       bool
+}
+
+// NewDeleteSubject asks to delete a subject's version
+func NewDeleteSubject(subject string, version int, production bool) DeleteSubjectModel {
+	return DeleteSubjectModel{subject: subject, version: version, production: production}
+}
+
+func (m DeleteSubjectModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m DeleteSubjectModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "a", "tab":
+		m.whole = !m.whole
+	case "p":
+		m.permanent = !m.permanent
+	case "y", "Y":
+		m.confirmed = true
+		m.quit = true
+	default:
+		m.quit = true
+	}
+	return m, nil
+}
+
+func (m DeleteSubjectModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Delete "+m.subject) + "\n\n")
+
+	check := func(on bool) string {
+		if on {
+			return "[x]"
+		}
+		return "[ ]"
+	}
+	b.WriteString(fmt.Sprintf("  %s All versions (a)\n", check(m.whole)))
+	b.WriteString(fmt.Sprintf("  %s Permanently (p)\n\n", check(m.permanent)))
+
+	if m.permanent {
+		b.WriteString(ErrorStyle.Render("The schemas are removed for good, and messages written with them can no longer be decoded") + "\n")
+	} else {
+		b.WriteString(HelpStyle.Render("A soft delete keeps the schemas readable by ID; delete again with p to remove them") + "\n")
+	}
+
+	b.WriteString("\n")
+	prompt := fmt.Sprintf("Delete %s? [y/N]", m.target())
+	if m.production {
+		prompt = "PRODUCTION: " + prompt
+	}
+	b.WriteString(DiffChangedStyle.Render(prompt) + "\n")
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render("[a] All versions  [p] Permanently  [y] Delete  [any other key] Cancel") + "\n")
+	return b.String()
+}
+
+// target describes what will be deleted
+func (m DeleteSubjectModel) target() string {
+	what := fmt.Sprintf("version %d of %s", m.version, m.subject)
+	if m.whole {
+		what = "every version of " + m.subject
+	}
+	if m.permanent {
+		what += " permanently"
+	}
+	return what
+}
+
+// Confirmed returns whether the deletion was confirmed
+func (m DeleteSubjectModel) Confirmed() bool {
+	return m.confirmed
+}
+
+// Quit returns whether the dialog is closed
+func (m DeleteSubjectModel) Quit() bool {
+	return m.quit
+}
+
+// enterDeleteSubject asks to delete the viewed subject or version
+func (m *Model) enterDeleteSubject() {
+	m.deleteSubject = NewDeleteSubject(m.selectedSubject, m.schemaVersion, m.cfg.Production)
+	m.state = stateDeletingSubject
+}
+
+func (m *Model) handleDeleteSubject(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.deleteSubject.Update(msg)
+	m.deleteSubject = newModel.(DeleteSubjectModel)
+	if !m.deleteSubject.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateViewing
+	if !m.deleteSubject.Confirmed() {
+		return m, nil
+	}
+	d := m.deleteSubject
+	client := m.client
+	m.statusMsg = fmt.Sprintf("Deleting %s...", d.target())
+	return m, func() tea.Msg {
+		msg := subjectDeletedMsg{subject: d.subject, permanent: d.permanent}
+		if d.whole {
+			msg.deleted, msg.err = client.DeleteSubject(d.subject, d.permanent)
+			msg.gone = true
+			return msg
+		}
+		msg.version = d.version
+		if msg.err = client.DeleteSchemaVersion(d.subject, d.version, d.permanent); msg.err == nil {
+			// Deleting the last version deletes the subject
+			_, err := client.ListVersions(d.subject)
+			msg.gone = registry.IsNotFound(err)
+		}
+		return msg
+	}
+}
+
+// handleSubjectDeleted shows the subject's latest version after one was
+// deleted, or reloads the subject list once the subject is gone
+func (m *Model) handleSubjectDeleted(msg subjectDeletedMsg) tea.Cmd {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = fmt.Errorf("deleting %s: %w", msg.subject, msg.err)
+		return nil
+	}
+
+	how := ""
+	if msg.permanent {
+		how = " permanently"
+	}
+	if msg.version != 0 {
+		m.copyNotify = fmt.Sprintf("Deleted %s v%d%s", msg.subject, msg.version, how)
+	} else {
+		m.copyNotify = fmt.Sprintf("Deleted %s (%d versions)%s", msg.subject, len(msg.deleted), how)
+	}
+	if !msg.gone {
+		return m.loadSchema(msg.subject)
+	}
+
+	if m.selectedSubject == msg.subject {
+		m.selectedSubject = ""
+		m.currentSchema = ""
+		m.rawSchema = ""
+		m.viewer.SetContent("")
+		m.state = stateBrowsing
+	}
+	return m.reloadSubjects()
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/deprecation"
+)
+
+type DeprecationEditorModel struct {
+	subject    string
+	fields     []formField
+	focusedIdx int
+	saved      bool
+	quit       bool
+}
+
+// NewDeprecationEditor creates an editor for a subject's local deprecation
+func NewDeprecationEditor(subject string, current deprecation.Deprecation) DeprecationEditorModel {
+	return DeprecationEditorModel{
+		subject: subject,
+		fields: []formField{
+			{label: "Reason", value: current.Reason, placeholder: "e.g., replaced by v2 contract"},
+			{label: "Replacement Subject", value: current.Replacement, placeholder: "e.g., orders-v2-value"},
+		},
+	}
+}
+
+func (m DeprecationEditorModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m DeprecationEditorModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		switch msg.String() {
+		case "esc":
+			m.quit = true
+			return m, nil
+		case "tab", "shift+tab":
+			m.focusedIdx = (m.focusedIdx + 1) % len(m.fields)
+		case "enter":
+			if m.focusedIdx == len(m.fields)-1 {
+				m.saved = true
+				m.quit = true
+				return m, nil
+			}
+			m.focusedIdx++
+		default:
+			// Handle text input
+			field := &m.fields[m.focusedIdx]
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				field.value += string(msg.Runes)
+			} else if msg.String() == "backspace" {
+				if len(field.value) > 0 {
+					runes := []rune(field.value)
+					field.value = string(runes[:len(runes)-1])
+				}
+			} else if msg.String() == "ctrl+u" {
+				field.value = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m DeprecationEditorModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Deprecate Subject: %s", m.subject)) + "\n\n"
+
+	for i, field := range m.fields {
+		prefix := "  "
+		if i == m.focusedIdx {
+			prefix = "> "
+		}
+
+		label := lipgloss.NewStyle().Width(22).Render(field.label + ":")
+		value := field.value
+		if value == "" {
+			value = lipgloss.NewStyle().Faint(true).Render(field.placeholder)
+		}
+
+		if i == m.focusedIdx {
+			s += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("11")).
+				Bold(true).
+				Render(prefix+label+" "+value) + "\n"
+		} else {
+			s += prefix + label + " " + value + "\n"
+		}
+	}
+
+	s += "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("Leave both fields empty to remove the deprecation") + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[tab] Next  [enter] Next / Save  [esc] Cancel") + "\n"
+
+	return s
+}
+
+// Deprecation returns the entered deprecation
+func (m DeprecationEditorModel) Deprecation() deprecation.Deprecation {
+	return deprecation.Deprecation{
+		Reason:      m.fields[0].value,
+		Replacement: m.fields[1].value,
+	}
+}
+
+// Saved returns whether the user confirmed the edit
+func (m DeprecationEditorModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the editor is closed
+func (m DeprecationEditorModel) Quit() bool {
+	return m.quit
+}
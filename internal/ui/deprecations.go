@@ -0,0 +1,66 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/deprecation"
+)
+
+// deprecationFor returns a subject's deprecation, preferring local
+// annotations over registry metadata
+func (m Model) deprecationFor(subject string) (deprecation.Deprecation, bool) {
+	if m.deprecations != nil {
+		if d, ok := m.deprecations.Get(m.cfg.Profile, subject); ok {
+			return d, true
+		}
+	}
+	d, ok := m.registryDeprecations[subject]
+	return d, ok
+}
+
+// deprecationBanner renders the viewer warning for a deprecated subject
+func (m Model) deprecationBanner(subject string) string {
+	d, ok := m.deprecationFor(subject)
+	if !ok {
+		return ""
+	}
+
+	text := "⚠ DEPRECATED"
+	if d.Reason != "" {
+		text += ": " + d.Reason
+	}
+	if d.Replacement != "" {
+		text += fmt.Sprintf(" → use %s", d.Replacement)
+	}
+	text += fmt.Sprintf(" (%s)", d.Source)
+	return DeprecatedBannerStyle.Render(text)
+}
+
+func (m *Model) enterDeprecationEditor() {
+	current, _ := m.deprecations.Get(m.cfg.Profile, m.selectedSubject)
+	m.deprecationEditor = NewDeprecationEditor(m.selectedSubject, current)
+	m.state = stateEditingDeprecation
+}
+
+func (m *Model) handleEditingDeprecation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.deprecationEditor.Update(msg)
+	m.deprecationEditor = newModel.(DeprecationEditorModel)
+
+	if m.deprecationEditor.Quit() {
+		if m.deprecationEditor.Saved() {
+			d := m.deprecationEditor.Deprecation()
+			if err := m.deprecations.Set(m.cfg.Profile, m.selectedSubject, d); err != nil {
+				m.err = err
+			} else if d.Reason == "" && d.Replacement == "" {
+				m.copyNotify = fmt.Sprintf("Removed deprecation for %s", m.selectedSubject)
+			} else {
+				m.copyNotify = fmt.Sprintf("Marked %s as deprecated", m.selectedSubject)
+			}
+		}
+		m.state = stateViewing
+	}
+
+	return m, cmd
+}
@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+
+	"github.com/JimmyyyW/avrocado/internal/csfle"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// encryptFields encrypts the fields of a send mode payload tagged by the
+// schema's ENCRYPT rules. Payloads are returned unchanged without rules.
+func (m Model) encryptFields(payload string) (string, error) {
+	return m.encryptFieldsFor(m.selectedSubject, m.rawSchema, m.ruleSet, payload)
+}
+
+// encryptFieldsFor encrypts a payload for another subject's schema
+func (m Model) encryptFieldsFor(subject, schema string, ruleSet *registry.RuleSet, payload string) (string, error) {
+	rules := csfle.EncryptRules(ruleSet)
+	if len(rules) == 0 {
+		return payload, nil
+	}
+
+	doc, err := parseJSONNumbers(payload)
+	if err != nil {
+		return "", fmt.Errorf("invalid JSON: %w", err)
+	}
+	doc, err = m.encryptor.Encrypt(subject, schema, rules, doc)
+	if err != nil {
+		return "", fmt.Errorf("encrypting fields: %w", err)
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return "", err
+	}
+	return string(out), nil
+}
+
+// decryptFields decrypts a decoded message's encrypted fields. On error the
+// message is returned as decoded.
+func (m Model) decryptFields(jsonData string) (string, error) {
+	rules := csfle.EncryptRules(m.ruleSet)
+	if len(rules) == 0 {
+		return jsonData, nil
+	}
+
+	doc, err := parseJSONNumbers(jsonData)
+	if err != nil {
+		return jsonData, err
+	}
+	if doc, err = m.encryptor.Decrypt(m.selectedSubject, m.rawSchema, rules, doc); err != nil {
+		return jsonData, err
+	}
+	out, err := json.Marshal(doc)
+	if err != nil {
+		return jsonData, err
+	}
+	return string(out), nil
+}
+
+// parseJSONNumbers parses JSON keeping numbers exact, so long values survive
+// being re-encoded
+func parseJSONNumbers(s string) (interface{}, error) {
+	dec := json.NewDecoder(bytes.NewReader([]byte(s)))
+	dec.UseNumber()
+	var doc interface{}
+	if err := dec.Decode(&doc); err != nil {
+		return nil, err
+	}
+	return doc, nil
+}
@@ -11,17 +11,21 @@ import (
 
 type EventLoaderModel struct {
 	topic       string
+	env         string // Profile whose overlays are applied
 	files       []string
 	selectedIdx int
 	selectedEvent *events.Event
+	overlay     string // Overlay applied to the loaded event, if any
 	quit        bool
 	err         string
 }
 
-// NewEventLoader creates a new event loader model
-func NewEventLoader(topic string) EventLoaderModel {
+// NewEventLoader creates a new event loader model. Events are patched with
+// their overlay for env, if they have one.
+func NewEventLoader(topic, env string) EventLoaderModel {
 	m := EventLoaderModel{
 		topic: topic,
+		env:   env,
 	}
 
 	// Load files for this topic
@@ -53,6 +57,9 @@ func (m EventLoaderModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				basePath := events.GetEventsDir()
 				filePath := events.GetEventPath(basePath, m.topic, m.files[m.selectedIdx])
 				event, err := events.LoadEvent(filePath)
+				if err == nil {
+					m.overlay, err = events.ApplyOverlay(event, filePath, m.env)
+				}
 				if err != nil {
 					m.err = err.Error()
 				} else {
@@ -86,11 +93,15 @@ func (m EventLoaderModel) View() string {
 	var s string
 	s += lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Load Event - %s", m.topic)) + "\n\n"
 
+	basePath := events.GetEventsDir()
 	for i, file := range m.files {
 		prefix := "  "
 		if i == m.selectedIdx {
 			prefix = "> "
 		}
+		if events.HasOverlay(events.GetEventPath(basePath, m.topic, file), m.env) {
+			file += " (+" + m.env + " overlay)"
+		}
 
 		if i == m.selectedIdx {
 			s += lipgloss.NewStyle().
@@ -113,6 +124,11 @@ func (m EventLoaderModel) LoadedEvent() *events.Event {
 	return m.selectedEvent
 }
 
+// Overlay returns the path of the overlay applied to the loaded event, or ""
+func (m EventLoaderModel) Overlay() string {
+	return m.overlay
+}
+
 // Quit returns whether the user quit
 func (m EventLoaderModel) Quit() bool {
 	return m.quit
@@ -20,10 +20,15 @@ type EventSaverModel struct {
 	quit        bool
 	err         string
 	filePath    string
+
+	// Replaces sensitive fields with fakes; nil when none are configured
+	redact    func(payload string) (string, error)
+	redacting bool
 }
 
-// NewEventSaver creates a new event saver model
-func NewEventSaver(topic, key string, schemaID int, payload string) EventSaverModel {
+// NewEventSaver creates a new event saver model. When redact is given,
+// sensitive fields are redacted on save unless the user turns it off.
+func NewEventSaver(topic, key string, schemaID int, payload string, redact func(string) (string, error)) EventSaverModel {
 	return EventSaverModel{
 		topic:       topic,
 		key:         key,
@@ -31,6 +36,8 @@ func NewEventSaver(topic, key string, schemaID int, payload string) EventSaverMo
 		schemaID:    schemaID,
 		eventName:   "",
 		focusedIdx:  0,
+		redact:      redact,
+		redacting:   redact != nil,
 	}
 }
 
@@ -45,10 +52,21 @@ func (m EventSaverModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		case "esc":
 			m.quit = true
 			return m, nil
+		case "ctrl+r":
+			m.redacting = m.redact != nil && !m.redacting
 		case "enter":
 			// Save event
+			payload := m.payload
+			if m.redacting {
+				redacted, err := m.redact(payload)
+				if err != nil {
+					m.err = err.Error()
+					return m, nil
+				}
+				payload = redacted
+			}
 			basePath := events.GetEventsDir()
-			path, err := events.SaveEvent(basePath, m.topic, m.key, m.payload, m.schemaID, m.eventName)
+			path, err := events.SaveEvent(basePath, m.topic, m.key, payload, m.schemaID, m.eventName)
 			if err != nil {
 				m.err = err.Error()
 			} else {
@@ -83,12 +101,25 @@ func (m EventSaverModel) View() string {
 	s += "Event Name (optional, defaults to timestamp):\n"
 	s += "> " + m.eventName + "\n"
 
+	if m.redact != nil {
+		s += "\n"
+		if m.redacting {
+			s += "Sensitive fields: " + lipgloss.NewStyle().Foreground(lipgloss.Color("10")).Render("replaced with fakes") + "\n"
+		} else {
+			s += "Sensitive fields: " + lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("saved as they are") + "\n"
+		}
+	}
+
 	s += "\n"
 	if m.err != "" {
 		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ Error: "+m.err) + "\n\n"
 	}
 
-	s += lipgloss.NewStyle().Faint(true).Render("[enter] Save  [esc] Cancel") + "\n"
+	if m.redact != nil {
+		s += lipgloss.NewStyle().Faint(true).Render("[enter] Save  [ctrl+r] Toggle redaction  [esc] Cancel") + "\n"
+	} else {
+		s += lipgloss.NewStyle().Faint(true).Render("[enter] Save  [esc] Cancel") + "\n"
+	}
 
 	return s
 }
@@ -98,6 +129,11 @@ func (m EventSaverModel) Saved() bool {
 	return m.saved
 }
 
+// Redacted returns whether sensitive fields were redacted
+func (m EventSaverModel) Redacted() bool {
+	return m.redacting
+}
+
 // FilePath returns the path to the saved file
 func (m EventSaverModel) FilePath() string {
 	return m.filePath
@@ -0,0 +1,331 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"slices"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// fanoutResult is the outcome of sending the payload to one topic
+type fanoutResult struct {
+	topic   string
+	subject string
+	version int
+	payload string // As sent, with any fresh idempotency key
+	ack     time.Duration
+	err     error
+}
+
+// fanoutSentMsg carries the per-topic results of a fan-out send
+type fanoutSentMsg struct {
+	key     string
+	results []fanoutResult
+}
+
+// FanoutPickerModel picks the topics a payload is sent to. Typing filters
+// the list; space toggles a topic.
+type FanoutPickerModel struct {
+	topics []string
+	chosen map[string]bool
+	filter string
+	cursor int
+	saved  bool
+	quit   bool
+}
+
+// NewFanoutPicker lists topics, with the current selection (or else the
+// current topic) checked
+func NewFanoutPicker(topics []string, current string, selected []string) FanoutPickerModel {
+	m := FanoutPickerModel{topics: topics, chosen: make(map[string]bool)}
+	if !slices.Contains(m.topics, current) {
+		m.topics = append(m.topics, current) // A topic overridden for the subject
+	}
+	sort.Strings(m.topics)
+
+	if len(selected) == 0 {
+		selected = []string{current}
+	}
+	for _, topic := range selected {
+		m.chosen[topic] = true
+	}
+	return m
+}
+
+func (m FanoutPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m FanoutPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	visible := m.visible()
+	switch keyMsg.String() {
+	case "esc":
+		m.quit = true
+	case "enter":
+		m.saved = true
+		m.quit = true
+	case "down", "ctrl+n":
+		if m.cursor < len(visible)-1 {
+			m.cursor++
+		}
+	case "up", "ctrl+p":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case " ":
+		if m.cursor < len(visible) {
+			topic := visible[m.cursor]
+			m.chosen[topic] = !m.chosen[topic]
+		}
+	case "ctrl+a":
+		// Check all visible topics, or uncheck them if all are checked
+		all := true
+		for _, topic := range visible {
+			all = all && m.chosen[topic]
+		}
+		for _, topic := range visible {
+			m.chosen[topic] = !all
+		}
+	case "backspace":
+		if len(m.filter) > 0 {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+			m.cursor = 0
+		}
+	case "ctrl+u":
+		m.filter = ""
+		m.cursor = 0
+	default:
+		if keyMsg.Type == tea.KeyRunes {
+			m.filter += string(keyMsg.Runes)
+			m.cursor = 0
+		}
+	}
+	return m, nil
+}
+
+// visible returns the topics matching the filter
+func (m FanoutPickerModel) visible() []string {
+	if m.filter == "" {
+		return m.topics
+	}
+	var topics []string
+	for _, topic := range m.topics {
+		if strings.Contains(strings.ToLower(topic), strings.ToLower(m.filter)) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func (m FanoutPickerModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Send To Topics (%d selected)", len(m.Selected()))) + "\n\n"
+	s += "Filter: " + m.filter + "\n\n"
+
+	visible := m.visible()
+	start := max(0, m.cursor-15)
+	for i := start; i < len(visible) && i < start+20; i++ {
+		topic := visible[i]
+		check := "[ ]"
+		if m.chosen[topic] {
+			check = "[x]"
+		}
+		line := check + " " + topic
+		if i == m.cursor {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true).Render("> "+line) + "\n"
+		} else {
+			s += "  " + line + "\n"
+		}
+	}
+	if len(visible) == 0 {
+		s += lipgloss.NewStyle().Faint(true).Render("  No topics match") + "\n"
+	}
+
+	s += "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("Each topic's payload is validated against its own <topic>-value subject") + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[↑/↓] Move  [space] Toggle  [ctrl+a] Toggle all  [enter] Done  [esc] Cancel") + "\n"
+	return s
+}
+
+// Selected returns the checked topics in order
+func (m FanoutPickerModel) Selected() []string {
+	var topics []string
+	for _, topic := range m.topics {
+		if m.chosen[topic] {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+// Saved returns whether the user confirmed the selection
+func (m FanoutPickerModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the picker is closed
+func (m FanoutPickerModel) Quit() bool {
+	return m.quit
+}
+
+func (m *Model) handleFanoutPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.fanoutPicker.Update(msg)
+	m.fanoutPicker = newModel.(FanoutPickerModel)
+	if !m.fanoutPicker.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateSendMode
+	if !m.fanoutPicker.Saved() {
+		return m, nil
+	}
+	m.fanoutTopics = m.fanoutPicker.Selected()
+	current := m.topic()
+	if len(m.fanoutTopics) == 1 && m.fanoutTopics[0] == current {
+		m.fanoutTopics = nil
+	}
+	if len(m.fanoutTopics) == 0 {
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Target: %s", current)
+	} else {
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Targets: %s  |  Ctrl+S sends to all", strings.Join(m.fanoutTopics, ", "))
+	}
+	return m, nil
+}
+
+// sendFanout validates the payload against each topic's value subject and
+// produces it to every topic that accepts it
+func (m Model) sendFanout() tea.Cmd {
+	topics := m.fanoutTopics
+	key := m.keyInput.Value()
+	payload := m.editor.Value()
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		results := make([]fanoutResult, 0, len(topics))
+		for _, topic := range topics {
+			results = append(results, m.sendToTopic(ctx, topic, key, payload))
+		}
+		return fanoutSentMsg{key: key, results: results}
+	})
+}
+
+func (m Model) sendToTopic(ctx context.Context, topic, key, payload string) fanoutResult {
+	result := fanoutResult{topic: topic, subject: m.cfg.SubjectFor(topic, m.selectedSubject)}
+	if m.producer == nil {
+		result.err = fmt.Errorf("Kafka not configured")
+		return result
+	}
+	if err := m.cfg.CheckSubject(result.subject); err != nil {
+		result.err = err
+		return result
+	}
+
+	schema := &registry.SchemaResponse{Subject: m.selectedSubject, Version: m.schemaVersion, ID: m.schemaID, Schema: m.rawSchema, RuleSet: m.ruleSet}
+	if result.subject != m.selectedSubject {
+		var err error
+		if schema, err = m.client.GetLatestSchema(result.subject); err != nil {
+			result.err = fmt.Errorf("fetching schema: %w", err)
+			return result
+		}
+	}
+	result.version = schema.Version
+
+	sent, headers, _, err := m.withFreshID(topic, payload)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	result.payload = sent
+
+	encrypted, err := m.encryptFieldsFor(result.subject, schema.Schema, schema.RuleSet, sent)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	binary, err := avro.ValidateAndEncode(schema.Schema, encrypted)
+	if err != nil {
+		result.err = err
+		return result
+	}
+	keyBytes, err := m.encodeKeyFor(topic, key)
+	if err != nil {
+		result.err = err
+		return result
+	}
+
+	ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
+	defer cancel()
+	start := time.Now()
+	result.err = m.producer.ProduceRecord(ctx, topic, schema.ID, kafka.Record{Key: keyBytes, Value: binary, Headers: headers})
+	result.ack = time.Since(start)
+	return result
+}
+
+// handleFanoutSent reports the per-topic results and fires a produced hook
+// for every topic that got the message
+func (m *Model) handleFanoutSent(msg fanoutSentMsg) tea.Cmd {
+	var b strings.Builder
+	var cmds []tea.Cmd
+	failed := 0
+	for _, r := range msg.results {
+		if r.err != nil {
+			failed++
+			b.WriteString(ErrorStyle.Render("✗ "+r.topic) + "\n")
+			b.WriteString(fmt.Sprintf("    %v\n", r.err))
+			continue
+		}
+		b.WriteString(SuccessStyle.Render("✓ "+r.topic) + "\n")
+		b.WriteString(fmt.Sprintf("    %s v%d, ack in %s\n", r.subject, r.version, formatLatency(r.ack)))
+		cmds = append(cmds, m.fireHook(hooks.MessageProduced, m.producedHookData(r.topic, msg.key, r.payload)))
+	}
+
+	sent := len(msg.results) - failed
+	if failed > 0 {
+		// Retry only where it failed, so no topic gets the message twice
+		m.fanoutTopics = m.fanoutTopics[:0:0]
+		for _, r := range msg.results {
+			if r.err != nil {
+				m.fanoutTopics = append(m.fanoutTopics, r.topic)
+			}
+		}
+		m.state = stateSendMode
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Sent to %d of %d topics - press Ctrl+S to retry the failed ones", sent, len(msg.results))
+	} else {
+		m.state = stateViewing
+		m.editor.Blur()
+		m.statusMsg = fmt.Sprintf("SUCCESS: Message produced to %d topics", sent)
+	}
+	m.openReport(fmt.Sprintf("Sent to %d/%d topics", sent, len(msg.results)), b.String())
+	return tea.Batch(cmds...)
+}
+
+// subjectTopics returns the topics the naming strategy gives the registry's
+// subjects: under the topic strategy, those with a value subject
+func (m Model) subjectTopics() []string {
+	topicStrategy := m.cfg.NamingStrategy() == config.TopicNameStrategy
+	var topics []string
+	for _, subject := range m.allSubjects() {
+		if topicStrategy && !strings.HasSuffix(subject, "-value") {
+			continue
+		}
+		if topic := m.cfg.DefaultTopic(subject); topic != "" && !slices.Contains(topics, topic) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
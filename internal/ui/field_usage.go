@@ -0,0 +1,162 @@
+package ui
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/report"
+)
+
+// defaultUsageSample is the number of recent messages sampled by default
+const defaultUsageSample = 500
+
+// fieldUsageMsg carries field statistics over a topic's recent messages
+type fieldUsageMsg struct {
+	subject string
+	topic   string
+	sampled int
+	usage   []report.FieldUsage
+	err     error
+}
+
+// enterUsagePrompt asks how many recent messages to sample
+func (m *Model) enterUsagePrompt() {
+	if m.isJSONSchema() {
+		m.err = fmt.Errorf("field usage is only available for Avro schemas")
+		return
+	}
+	if !m.cfg.HasKafka() {
+		m.err = fmt.Errorf("Kafka not configured")
+		return
+	}
+	if !m.needTopic() {
+		return
+	}
+	m.usagePrompt = NewTextPrompt("Field Usage: "+m.topic(), fmt.Sprintf("Recent messages to sample, split across partitions (at most %d)", maxBackfillMessages), strconv.Itoa(defaultUsageSample))
+	m.state = stateUsagePrompt
+}
+
+func (m *Model) handleUsagePrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.usagePrompt.Update(msg)
+	m.usagePrompt = newModel.(TextPromptModel)
+	if !m.usagePrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateViewing
+	if !m.usagePrompt.Saved() {
+		return m, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(m.usagePrompt.Value()))
+	if err != nil || n <= 0 {
+		m.err = fmt.Errorf("sample size must be a positive whole number")
+		return m, nil
+	}
+	n = min(n, maxBackfillMessages)
+
+	subject, topic, schema := m.selectedSubject, m.topic(), m.rawSchema
+	cfg, masker := m.cfg, m.masker
+	decoder := avro.NewWireDecoder(m.client.GetSchemaByID)
+	decoder.Transform = m.encryptor.DecryptByID(subject)
+	m.statusMsg = fmt.Sprintf("Sampling %d messages from %s...", n, topic)
+	return m, m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		messages, err := sampleRecent(ctx, cfg, topic, n)
+		if err != nil {
+			return fieldUsageMsg{err: err}
+		}
+		docs := make([]interface{}, 0, len(messages))
+		for _, msg := range messages {
+			data, err := base64.StdEncoding.DecodeString(msg.Value)
+			if err != nil {
+				continue
+			}
+			if _, doc, err := decoder.Decode(data); err == nil {
+				docs = append(docs, masker.Apply(doc))
+			}
+		}
+		usage, err := report.Usage(schema, docs)
+		return fieldUsageMsg{subject: subject, topic: topic, sampled: len(docs), usage: usage, err: err}
+	})
+}
+
+// sampledMessage is a recent message and the partition it was read from
+type sampledMessage struct {
+	kafka.Message
+	Partition int
+}
+
+// sampleRecent fetches up to n of a topic's most recent messages, split
+// evenly across its partitions
+func sampleRecent(ctx context.Context, cfg *config.Config, topic string, n int) ([]sampledMessage, error) {
+	probe, err := kafka.NewConsumer(cfg, topic)
+	if err != nil {
+		return nil, fmt.Errorf("failed to create consumer: %w", err)
+	}
+	partitionCtx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	partitions, err := probe.Partitions(partitionCtx)
+	cancel()
+	probe.Close()
+	if err != nil {
+		return nil, err
+	}
+	if len(partitions) == 0 {
+		return nil, nil
+	}
+
+	var messages []sampledMessage
+	perPartition := max(1, n/len(partitions))
+	for _, partition := range partitions {
+		fetched, err := sampleRecentPartition(ctx, cfg, topic, partition, perPartition)
+		if err != nil {
+			return nil, fmt.Errorf("sampling partition %d: %w", partition, err)
+		}
+		for _, msg := range fetched {
+			messages = append(messages, sampledMessage{Message: msg, Partition: partition})
+		}
+	}
+	return messages, nil
+}
+
+func sampleRecentPartition(ctx context.Context, cfg *config.Config, topic string, partition, n int) ([]kafka.Message, error) {
+	consumer, err := kafka.NewPartitionConsumer(cfg, topic, partition)
+	if err != nil {
+		return nil, err
+	}
+	defer consumer.Close()
+
+	ctx, cancel := context.WithTimeout(ctx, 15*time.Second)
+	defer cancel()
+	first, last, err := consumer.Watermarks(ctx)
+	if err != nil {
+		return nil, err
+	}
+	start := max(first, last-int64(n))
+	if start >= last {
+		return nil, nil
+	}
+	if err := consumer.SeekTo(start); err != nil {
+		return nil, err
+	}
+	return consumer.FetchMessages(ctx, int(last-start))
+}
+
+func (m *Model) handleFieldUsage(msg fieldUsageMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = fmt.Errorf("field usage: %w", msg.err)
+		return
+	}
+	if m.state != stateViewing && m.state != stateBrowsing {
+		return
+	}
+	m.openReport("Field Usage", report.FormatUsage(msg.subject, msg.topic, msg.sampled, msg.usage))
+}
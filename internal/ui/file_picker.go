@@ -0,0 +1,217 @@
+package ui
+
+import (
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/filepicker"
+	"github.com/charmbracelet/bubbles/key"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// pickerHeight is how many entries a file picker shows inside a form
+const pickerHeight = 15
+
+// pathKind says whether a form field holds a path and how it is browsed
+type pathKind int
+
+const (
+	noPath   pathKind = iota
+	openPath          // An existing file to read
+	savePath          // A file to write: an existing file, or a directory to put it in
+)
+
+// FilePickerModel browses the filesystem for a file. Directories are read
+// synchronously, so the picker works inside any form without routing
+// messages to it.
+type FilePickerModel struct {
+	title    string
+	kind     pathKind
+	picker   filepicker.Model
+	selected string
+	err      string
+	quit     bool
+}
+
+// NewFilePicker creates a picker starting at path (a directory, or a file
+// whose directory is used), showing height entries. Open pickers only
+// allow files with one of the given extensions, if any.
+func NewFilePicker(title, path string, kind pathKind, height int, extensions ...string) FilePickerModel {
+	picker := filepicker.New()
+	picker.CurrentDirectory = startDirectory(path)
+	picker.AutoHeight = false
+	picker.SetHeight(max(height, 5))
+	picker.ShowPermissions = false
+	if kind == openPath {
+		picker.AllowedTypes = extensions
+	}
+	// Esc cancels the picker instead of going up a directory
+	picker.KeyMap.Back = key.NewBinding(key.WithKeys("h", "backspace", "left"))
+
+	m := FilePickerModel{title: title, kind: kind, picker: picker}
+	m.run(m.picker.Init())
+	return m
+}
+
+// startDirectory returns the absolute directory to start browsing in,
+// falling back to the working directory
+func startDirectory(path string) string {
+	path = expandHome(path)
+	for path != "" && path != "." {
+		if info, err := os.Stat(path); err == nil && info.IsDir() {
+			break
+		}
+		parent := filepath.Dir(path)
+		if parent == path {
+			break
+		}
+		path = parent
+	}
+	if path == "" {
+		path = "."
+	}
+	if abs, err := filepath.Abs(path); err == nil {
+		return abs
+	}
+	return path
+}
+
+// expandHome expands a leading ~ to the home directory
+func expandHome(path string) string {
+	if path == "~" || strings.HasPrefix(path, "~/") {
+		if home, err := os.UserHomeDir(); err == nil {
+			return filepath.Join(home, path[1:])
+		}
+	}
+	return path
+}
+
+// run applies the picker's directory reads immediately
+func (m *FilePickerModel) run(cmd tea.Cmd) {
+	for cmd != nil {
+		msg := cmd()
+		if msg == nil {
+			return
+		}
+		m.picker, cmd = m.picker.Update(msg)
+	}
+}
+
+func (m FilePickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m FilePickerModel) Update(msg tea.Msg) (FilePickerModel, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	m.err = ""
+	switch keyMsg.String() {
+	case "esc", "q":
+		m.quit = true
+		return m, nil
+	case ".":
+		m.picker.ShowHidden = !m.picker.ShowHidden
+		m.run(m.picker.Init())
+		return m, nil
+	case "s":
+		if m.kind == savePath {
+			m.selected = m.picker.CurrentDirectory
+			m.quit = true
+			return m, nil
+		}
+	}
+
+	var cmd tea.Cmd
+	m.picker, cmd = m.picker.Update(msg)
+	if ok, path := m.picker.DidSelectFile(msg); ok {
+		m.selected = path
+		m.quit = true
+		return m, nil
+	}
+	if ok, path := m.picker.DidSelectDisabledFile(msg); ok {
+		m.err = filepath.Base(path) + " isn't a " + strings.Join(m.picker.AllowedTypes, " or ") + " file"
+	}
+	m.run(cmd)
+	return m, nil
+}
+
+func (m FilePickerModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render(m.title) + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render(m.picker.CurrentDirectory) + "\n\n"
+	s += m.picker.View() + "\n"
+	if m.err != "" {
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ "+m.err) + "\n"
+	}
+	help := "[j/k] Move  [l/enter] Open  [h] Up  [.] Hidden files  [esc] Cancel"
+	if m.kind == savePath {
+		help = "[j/k] Move  [l] Open  [enter] Choose file  [s] Save in this directory  [h] Up  [esc] Cancel"
+	}
+	s += lipgloss.NewStyle().Faint(true).Render(help) + "\n"
+	return s
+}
+
+// Selected returns the chosen file (or, when saving, possibly a
+// directory), or "" if the picker was cancelled
+func (m FilePickerModel) Selected() string {
+	return m.selected
+}
+
+// Quit returns whether the picker is closed
+func (m FilePickerModel) Quit() bool {
+	return m.quit
+}
+
+// pathPicker lets a form fill a path field from a file picker. Forms embed
+// it, open it on ctrl+f and hand it keys while it is open.
+type pathPicker struct {
+	picker  *FilePickerModel
+	kind    pathKind
+	current string // Value being browsed for
+}
+
+// browse opens the picker, starting from where the current value points
+func (p *pathPicker) browse(title string, kind pathKind, current string, extensions ...string) {
+	picker := NewFilePicker(title, current, kind, pickerHeight, extensions...)
+	p.picker = &picker
+	p.kind = kind
+	p.current = current
+}
+
+// active reports whether the picker is open
+func (p pathPicker) active() bool {
+	return p.picker != nil
+}
+
+// update hands a key to the open picker. Once a file is chosen it returns
+// the new value; saving into a directory keeps the current file name.
+func (p *pathPicker) update(msg tea.KeyMsg) (string, bool) {
+	picker, _ := p.picker.Update(msg)
+	p.picker = &picker
+	if !picker.Quit() {
+		return "", false
+	}
+	p.picker = nil
+
+	chosen := picker.Selected()
+	if chosen == "" {
+		return "", false
+	}
+	if info, err := os.Stat(chosen); err == nil && info.IsDir() {
+		name := filepath.Base(p.current)
+		if p.current == "" || name == "." || name == string(filepath.Separator) {
+			name = "untitled"
+		}
+		chosen = filepath.Join(chosen, name)
+	}
+	return chosen, true
+}
+
+// view renders the open picker
+func (p pathPicker) view() string {
+	return p.picker.View()
+}
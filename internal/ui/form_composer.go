@@ -0,0 +1,493 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+)
+
+// composerRow is one field of the form composer
+type composerRow struct {
+	avro.FormField
+	value   string // Text entered for a value or JSON field
+	isNull  bool
+	wrapped string // Union branch label the payload wrapped the value in, kept when writing it back
+	parent  int    // Row of the enclosing record, -1 at the top level
+}
+
+func (r composerRow) name() string {
+	return r.Path[len(r.Path)-1]
+}
+
+func (r composerRow) key() string {
+	return strings.Join(r.Path, ".")
+}
+
+// formComposer edits a payload field by field, with a null/value toggle for
+// optional fields. The JSON in the editor stays the payload: the form is
+// built from it and written back to it after every change.
+type formComposer struct {
+	rows   []composerRow
+	cursor int
+	synced string // The payload the form was last built from or written to
+}
+
+// newFormComposer builds a form for a payload. Fields the payload doesn't
+// have, or has under a null, are filled from the previous form, so a value
+// toggled to null comes back, else from the template.
+func newFormComposer(schema, payload, template string, previous *formComposer) (*formComposer, error) {
+	fields, err := avro.FormFields(schema)
+	if err != nil {
+		return nil, err
+	}
+	root, err := decodeObject(payload)
+	if err != nil {
+		return nil, fmt.Errorf("payload is not a JSON object: %w", err)
+	}
+	defaults, _ := decodeObject(template)
+
+	remembered := make(map[string]composerRow)
+	if previous != nil {
+		for _, row := range previous.rows {
+			remembered[row.key()] = row
+		}
+	}
+
+	c := &formComposer{synced: payload}
+	objects := make([]map[string]interface{}, len(fields)) // Each record row's object in the payload
+	rowOf := make(map[string]int)
+	for i, field := range fields {
+		row := composerRow{FormField: field, parent: -1}
+		parentObj := root
+		if field.Depth() > 0 {
+			row.parent = rowOf[strings.Join(field.Path[:field.Depth()], ".")]
+			parentObj = objects[row.parent]
+		}
+		rowOf[row.key()] = i
+
+		value, present := parentObj[row.name()]
+		if present && value == nil && row.Nullable {
+			row.isNull = true
+			present = false
+		}
+		if present && row.Nullable {
+			row.wrapped, value = unwrapBranch(value, row.Branch)
+		}
+		if !present {
+			if prev, ok := remembered[row.key()]; ok {
+				row.value, row.wrapped = prev.value, prev.wrapped
+				row.isNull = row.isNull || prev.isNull
+			} else {
+				row.value = formatFormValue(lookupPath(defaults, row.Path))
+			}
+		} else {
+			row.value = formatFormValue(value)
+		}
+		if row.Kind == avro.FormRecord {
+			objects[i], _ = value.(map[string]interface{})
+			if !present {
+				objects[i] = nil
+			}
+		}
+		c.rows = append(c.rows, row)
+	}
+	return c, nil
+}
+
+func decodeObject(payload string) (map[string]interface{}, error) {
+	dec := json.NewDecoder(strings.NewReader(payload))
+	dec.UseNumber()
+	var obj map[string]interface{}
+	if err := dec.Decode(&obj); err != nil {
+		return nil, err
+	}
+	return obj, nil
+}
+
+// unwrapBranch takes a value out of the {"branch": value} form of Avro's
+// JSON encoding, returning the branch it was wrapped in
+func unwrapBranch(value interface{}, branch string) (string, interface{}) {
+	obj, ok := value.(map[string]interface{})
+	if !ok || len(obj) != 1 {
+		return "", value
+	}
+	short := branch
+	if i := strings.LastIndex(branch, "."); i >= 0 {
+		short = branch[i+1:]
+	}
+	for key, inner := range obj {
+		if key == branch || key == short || strings.HasPrefix(key, branch+".") {
+			return key, inner
+		}
+	}
+	return "", value
+}
+
+func lookupPath(obj map[string]interface{}, path []string) interface{} {
+	var value interface{} = obj
+	for _, name := range path {
+		m, ok := value.(map[string]interface{})
+		if !ok {
+			return nil
+		}
+		value = m[name]
+	}
+	return value
+}
+
+// formatFormValue is the text a value is edited as: strings as they are,
+// anything else as JSON
+func formatFormValue(value interface{}) string {
+	switch v := value.(type) {
+	case nil:
+		return ""
+	case string:
+		return v
+	case json.Number:
+		return v.String()
+	}
+	data, err := json.Marshal(value)
+	if err != nil {
+		return ""
+	}
+	return string(data)
+}
+
+// parse converts a row's text to its JSON value
+func (r composerRow) parse() (interface{}, error) {
+	text := r.value
+	if r.Kind == avro.FormJSON {
+		dec := json.NewDecoder(strings.NewReader(text))
+		dec.UseNumber()
+		var value interface{}
+		if err := dec.Decode(&value); err != nil {
+			return nil, fmt.Errorf("not valid JSON: %w", err)
+		}
+		return value, nil
+	}
+	switch r.Scalar {
+	case "int", "long":
+		if _, err := strconv.ParseInt(strings.TrimSpace(text), 10, 64); err != nil {
+			return nil, fmt.Errorf("%q is not a whole number", text)
+		}
+		return json.Number(strings.TrimSpace(text)), nil
+	case "float", "double":
+		if _, err := strconv.ParseFloat(strings.TrimSpace(text), 64); err != nil {
+			return nil, fmt.Errorf("%q is not a number", text)
+		}
+		return json.Number(strings.TrimSpace(text)), nil
+	case "boolean":
+		b, err := strconv.ParseBool(strings.TrimSpace(text))
+		if err != nil {
+			return nil, fmt.Errorf("%q is not true or false", text)
+		}
+		return b, nil
+	}
+	return text, nil
+}
+
+// payload writes the form back to JSON, with null for optional fields
+// toggled to null and the others wrapped in their union branch
+func (c *formComposer) payload() (string, error) {
+	root := make(map[string]interface{})
+	objects := make([]map[string]interface{}, len(c.rows))
+	for i, row := range c.rows {
+		parent := root
+		if row.parent >= 0 {
+			if parent = objects[row.parent]; parent == nil {
+				continue // Inside a record that is null
+			}
+		}
+		if row.isNull {
+			parent[row.name()] = nil
+			continue
+		}
+
+		var value interface{}
+		if row.Kind == avro.FormRecord {
+			objects[i] = make(map[string]interface{})
+			value = objects[i]
+		} else {
+			v, err := row.parse()
+			if err != nil {
+				return "", fmt.Errorf("%s: %w", row.key(), err)
+			}
+			value = v
+		}
+		if row.Nullable {
+			// Avro's JSON encoding names the branch of a union value
+			label := row.wrapped
+			if label == "" {
+				label = row.Branch
+			}
+			value = map[string]interface{}{label: value}
+		}
+		parent[row.name()] = value
+	}
+
+	var buf bytes.Buffer
+	enc := json.NewEncoder(&buf)
+	enc.SetEscapeHTML(false)
+	enc.SetIndent("", "  ")
+	if err := enc.Encode(root); err != nil {
+		return "", fmt.Errorf("formatting payload: %w", err)
+	}
+	return strings.TrimSuffix(buf.String(), "\n"), nil
+}
+
+// visible reports whether a row is shown: rows inside a null record aren't
+func (c *formComposer) visible(i int) bool {
+	for p := c.rows[i].parent; p >= 0; p = c.rows[p].parent {
+		if c.rows[p].isNull {
+			return false
+		}
+	}
+	return true
+}
+
+// move steps the cursor to the next visible row in a direction
+func (c *formComposer) move(step int) {
+	for i := c.cursor + step; i >= 0 && i < len(c.rows); i += step {
+		if c.visible(i) {
+			c.cursor = i
+			return
+		}
+	}
+}
+
+// update applies a key to the form, reporting whether it was one the form
+// handles
+func (c *formComposer) update(msg tea.KeyMsg) bool {
+	if len(c.rows) == 0 {
+		return false
+	}
+	row := &c.rows[c.cursor]
+	switch msg.String() {
+	case "up":
+		c.move(-1)
+	case "down", "enter":
+		c.move(1)
+	case "alt+n":
+		if row.Nullable {
+			row.isNull = !row.isNull
+		}
+	case "backspace":
+		if row.Kind != avro.FormRecord && len(row.value) > 0 {
+			runes := []rune(row.value)
+			row.value = string(runes[:len(runes)-1])
+			row.isNull = false
+		}
+	case "ctrl+u":
+		if row.Kind != avro.FormRecord {
+			row.value = ""
+			row.isNull = false
+		}
+	default:
+		if msg.Type != tea.KeyRunes && msg.Type != tea.KeySpace {
+			return false
+		}
+		if row.Kind != avro.FormRecord {
+			// Typing into a null field sets it
+			row.value += string(msg.Runes)
+			row.isNull = false
+		}
+	}
+	return true
+}
+
+// view renders the visible rows, scrolled to keep the cursor in sight
+func (c *formComposer) view(width, height int) string {
+	faint := lipgloss.NewStyle().Faint(true)
+	focused := lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true)
+
+	var lines []string
+	cursorLine := 0
+	for i, row := range c.rows {
+		if !c.visible(i) {
+			continue
+		}
+		prefix := "  "
+		if i == c.cursor {
+			prefix = "> "
+			cursorLine = len(lines)
+		}
+		line := prefix + strings.Repeat("  ", row.Depth()) + row.name()
+		if row.Nullable {
+			if row.isNull {
+				line += "  [● null ○ value]"
+			} else {
+				line += "  [○ null ● value]"
+			}
+		}
+		switch {
+		case row.isNull:
+		case row.Kind == avro.FormRecord:
+			line += ":"
+		default:
+			line += ": " + row.value
+		}
+		if i == c.cursor {
+			line = focused.Render(line)
+		}
+		lines = append(lines, line+"  "+faint.Render(row.Type))
+	}
+	if len(lines) == 0 {
+		lines = append(lines, faint.Render("  (no fields)"))
+	}
+
+	height-- // Hint line
+	if height < 1 {
+		height = 1
+	}
+	start := 0
+	if cursorLine >= height {
+		start = cursorLine - height + 1
+	}
+	end := start + height
+	if end > len(lines) {
+		end = len(lines)
+	}
+	for i := start; i < end; i++ {
+		lines[i] = lipgloss.NewStyle().MaxWidth(width).Render(lines[i])
+	}
+
+	hint := "[↑/↓] Field  [alt+n] Null/value  [ctrl+u] Clear  [alt+f] Raw JSON"
+	return strings.Join(lines[start:end], "\n") + "\n" + faint.Render(hint)
+}
+
+// composerTemplate is the payload fields missing from the one being edited
+// are filled from: the schema's template with optional fields set
+func (m Model) composerTemplate() string {
+	opts := m.templateOptions()
+	opts.PreferNull = false
+	opts.OmitDefaults = false
+	template, err := avro.GenerateTemplateWithOptions(m.rawSchema, opts)
+	if err != nil {
+		return "{}"
+	}
+	return template
+}
+
+// toggleComposer switches send mode between the raw JSON editor and the form
+func (m *Model) toggleComposer() {
+	if m.formMode {
+		if err := m.syncComposer(); err != nil {
+			m.err = err
+			return
+		}
+		m.formMode = false
+		m.editor.Focus()
+		m.statusMsg = "[SEND MODE] Raw JSON  |  alt+f form"
+		return
+	}
+	if m.isJSONSchema() {
+		m.err = fmt.Errorf("the form composer is only available for Avro schemas")
+		return
+	}
+	composer, err := newFormComposer(m.rawSchema, m.editor.Value(), m.composerTemplate(), m.composer)
+	if err != nil {
+		m.err = fmt.Errorf("opening form: %w", err)
+		return
+	}
+	if m.composer != nil && m.composer.cursor < len(composer.rows) {
+		composer.cursor = m.composer.cursor
+	}
+	m.composer = composer
+	m.formMode = true
+	m.editor.Blur()
+	m.statusMsg = "[SEND MODE] Form  |  ↑/↓ field, alt+n null/value, alt+f raw JSON"
+}
+
+// refreshComposer rebuilds the form if the payload changed underneath it,
+// as loading a saved message or applying a patch does
+func (m *Model) refreshComposer() {
+	if m.editor.Value() == m.composer.synced {
+		return
+	}
+	composer, err := newFormComposer(m.rawSchema, m.editor.Value(), m.composerTemplate(), m.composer)
+	if err != nil {
+		m.formMode = false
+		m.editor.Focus()
+		m.err = fmt.Errorf("back to raw JSON: %w", err)
+		return
+	}
+	composer.cursor = m.composer.cursor
+	if composer.cursor >= len(composer.rows) || !composer.visible(composer.cursor) {
+		composer.cursor = 0
+	}
+	m.composer = composer
+}
+
+// syncComposer writes the form to the editor, which the send mode commands
+// read the payload from
+func (m *Model) syncComposer() error {
+	payload, err := m.composer.payload()
+	if err != nil {
+		return err
+	}
+	m.editor.SetValue(payload)
+	m.composer.synced = payload
+	return nil
+}
+
+// composerCommands are the send mode keys that act on the payload rather
+// than edit the form
+var composerCommands = map[string]bool{
+	"esc": true, "ctrl+s": true, "alt+t": true, "alt+s": true, "ctrl+n": true,
+	"ctrl+o": true, "alt+o": true, "alt+w": true, "alt+p": true, "alt+|": true,
+	"alt+i": true, "alt+m": true, "alt+r": true, "ctrl+g": true, "ctrl+r": true, "tab": true,
+	"shift+tab": true,
+}
+
+// handleComposer handles a send mode key while the form is shown, reporting
+// whether it was used. Commands are left to send mode once the form is
+// written to the editor.
+func (m *Model) handleComposer(msg tea.KeyMsg) bool {
+	m.refreshComposer()
+	if !m.formMode {
+		return false
+	}
+	key := msg.String()
+	if key == "alt+f" {
+		m.toggleComposer()
+		return true
+	}
+	if composerCommands[key] {
+		if err := m.syncComposer(); err != nil {
+			m.err = err
+			return true
+		}
+		return false
+	}
+	if m.composer.update(msg) {
+		// Keep the editor current where the form is valid; invalid text is
+		// reported when the payload is used
+		if payload, err := m.composer.payload(); err == nil {
+			m.editor.SetValue(payload)
+			m.composer.synced = payload
+		}
+	}
+	return true
+}
+
+// composerView renders the form, rebuilt first if the payload changed
+// underneath it
+func (m Model) composerView(width, height int) string {
+	composer := m.composer
+	if m.editor.Value() != composer.synced {
+		rebuilt, err := newFormComposer(m.rawSchema, m.editor.Value(), m.composerTemplate(), composer)
+		if err != nil {
+			return m.editor.View()
+		}
+		rebuilt.cursor = composer.cursor
+		composer = rebuilt
+	}
+	return composer.view(width, height)
+}
@@ -0,0 +1,45 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+)
+
+// hookFailedMsg reports hooks that failed after an action succeeded
+type hookFailedMsg struct {
+	event string
+	err   error
+}
+
+// fireHook runs the hooks configured for an event in the background
+func (m Model) fireHook(event string, data map[string]interface{}) tea.Cmd {
+	if len(m.cfg.Hooks) == 0 {
+		return nil
+	}
+	cfg := m.cfg
+	return func() tea.Msg {
+		if err := hooks.Fire(cfg, event, data); err != nil {
+			return hookFailedMsg{event: event, err: err}
+		}
+		return nil
+	}
+}
+
+// producedHookData describes a message produced from send mode
+func (m Model) producedHookData(topic, key, payload string) map[string]interface{} {
+	return map[string]interface{}{
+		"topic":          topic,
+		"subject":        m.selectedSubject,
+		"schema_id":      m.schemaID,
+		"schema_version": m.schemaVersion,
+		"key":            key,
+		"payload":        payload,
+	}
+}
+
+func (m *Model) handleHookFailed(msg hookFailedMsg) {
+	m.err = fmt.Errorf("%s hook failed: %w", msg.event, msg.err)
+}
@@ -0,0 +1,39 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/JimmyyyW/avrocado/internal/idempotency"
+)
+
+// toggleFreshIDs turns fresh idempotency keys on or off. While on, every
+// send to a topic with idempotency config gets a new key.
+func (m *Model) toggleFreshIDs() {
+	topic := m.topic()
+	if _, ok := m.cfg.IdempotencyFor(topic); !ok && !m.freshIDs {
+		m.err = fmt.Errorf("no idempotency config for %s (see idempotency in the config file)", topic)
+		return
+	}
+	m.freshIDs = !m.freshIDs
+	if m.freshIDs {
+		m.statusMsg = "[SEND MODE] Fresh idempotency key on every send"
+	} else {
+		m.statusMsg = "[SEND MODE] Sending idempotency keys as they are"
+	}
+}
+
+// withFreshID returns the payload and headers to send to a topic, with a new
+// idempotency key when fresh keys are on and the topic has idempotency
+// config, along with the key ("" if none was written)
+func (m Model) withFreshID(topic, payload string) (string, map[string]string, string, error) {
+	rule, ok := m.cfg.IdempotencyFor(topic)
+	if !m.freshIDs || !ok {
+		return payload, nil, "", nil
+	}
+	key := idempotency.NewKey()
+	payload, headers, err := idempotency.Inject(rule, payload, nil, key)
+	if err != nil {
+		return "", nil, "", err
+	}
+	return payload, headers, key, nil
+}
@@ -0,0 +1,47 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// isJSONSchema reports whether the loaded subject uses JSON Schema
+func (m Model) isJSONSchema() bool {
+	return m.schemaType == registry.SchemaTypeJSON
+}
+
+// encodeValue validates a payload against the loaded schema and returns the
+// value bytes to produce. Avro payloads have their tagged fields encrypted
+// and are binary encoded; JSON Schema payloads are sent as compact JSON.
+func (m Model) encodeValue(payload string) ([]byte, error) {
+	if !m.isJSONSchema() {
+		encrypted, err := m.encryptFields(payload)
+		if err != nil {
+			return nil, err
+		}
+		return avro.ValidateAndEncode(m.rawSchema, encrypted)
+	}
+
+	schema, err := jsonschema.Compile(m.rawSchema)
+	if err != nil {
+		return nil, fmt.Errorf("invalid JSON Schema: %w", err)
+	}
+	value, err := schema.Encode(payload)
+	if err != nil {
+		return nil, fmt.Errorf("validation failed: %w", err)
+	}
+	return value, nil
+}
+
+// checkSchema validates an edited schema as the subject's schema type
+func (m Model) checkSchema(schema string) error {
+	if m.isJSONSchema() {
+		_, err := jsonschema.Compile(schema)
+		return err
+	}
+	_, err := avro.NewCodec(schema)
+	return err
+}
@@ -0,0 +1,78 @@
+package ui
+
+import (
+	"fmt"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/keys"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// keySchemaLoadedMsg carries the key schema of the topic send mode targets,
+// nil if the topic has none
+type keySchemaLoadedMsg struct {
+	topic  string
+	schema *registry.SchemaResponse
+	err    error
+}
+
+// loadKeySchema starts looking up the topic's key subject for send mode
+func (m *Model) loadKeySchema(topic string) tea.Cmd {
+	m.keyTopic = topic
+	m.keySchema = nil
+	m.keySchemaLoaded = false
+	m.keyInput.Placeholder = "Message key (optional)"
+	cfg, client := m.cfg, m.client
+	return func() tea.Msg {
+		schema, err := keys.Schema(cfg, client, topic)
+		return keySchemaLoadedMsg{topic: topic, schema: schema, err: err}
+	}
+}
+
+func (m *Model) handleKeySchemaLoaded(msg keySchemaLoadedMsg) {
+	if m.keyTopic != msg.topic {
+		return // Send mode was left or retargeted since
+	}
+	if msg.err != nil {
+		// Looked up again when sending
+		m.err = msg.err
+		return
+	}
+	m.keySchema = msg.schema
+	m.keySchemaLoaded = true
+	m.keyInput.Placeholder = keyPlaceholder(msg.schema)
+}
+
+// keySchemaFor returns a topic's key schema, looking it up unless send mode
+// already has
+func (m Model) keySchemaFor(topic string) (*registry.SchemaResponse, error) {
+	if topic == m.keyTopic && m.keySchemaLoaded {
+		return m.keySchema, nil
+	}
+	return keys.Schema(m.cfg, m.client, topic)
+}
+
+// encodeKeyFor encodes a message key for a topic
+func (m Model) encodeKeyFor(topic, key string) ([]byte, error) {
+	if key == "" {
+		return nil, nil
+	}
+	schema, err := m.keySchemaFor(topic)
+	if err != nil {
+		return nil, err
+	}
+	return keys.Encode(schema, key, m.cfg.Unframed())
+}
+
+// keyPlaceholder describes how the key will be encoded
+func keyPlaceholder(schema *registry.SchemaResponse) string {
+	if schema == nil {
+		return "Message key (optional, sent as a raw string)"
+	}
+	kind := "Avro"
+	if schema.IsJSONSchema() {
+		kind = "JSON Schema"
+	}
+	return fmt.Sprintf("Message key (optional, %s v%d, %s)", schema.Subject, schema.Version, kind)
+}
@@ -20,6 +20,7 @@ type KeyMap struct {
 	Fetch        key.Binding
 	SaveEvent    key.Binding
 	LoadEvent    key.Binding
+	Resize       key.Binding
 }
 
 var Keys = KeyMap{
@@ -91,6 +92,10 @@ var Keys = KeyMap{
 		key.WithKeys("ctrl+o"),
 		key.WithHelp("ctrl+o", "load message"),
 	),
+	Resize: key.NewBinding(
+		key.WithKeys("<", ">"),
+		key.WithHelp("</>", "resize panes"),
+	),
 }
 
 func (k KeyMap) ShortHelp() []key.Binding {
@@ -100,7 +105,7 @@ func (k KeyMap) ShortHelp() []key.Binding {
 func (k KeyMap) FullHelp() [][]key.Binding {
 	return [][]key.Binding{
 		{k.Up, k.Down, k.Enter},
-		{k.Search, k.Escape, k.Tab},
+		{k.Search, k.Escape, k.Tab, k.Resize},
 		{k.Edit, k.EditExternal, k.Send},
 		{k.Consumer, k.Fetch, k.Copy},
 		{k.SaveEvent, k.LoadEvent, k.PageUp, k.PageDown},
@@ -0,0 +1,49 @@
+package ui
+
+import (
+	"errors"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/tunnel"
+)
+
+// shutdownTimeout bounds how long Shutdown waits for in-flight fetches and
+// produces to notice they have been cancelled
+const shutdownTimeout = 3 * time.Second
+
+// setConsumer replaces the consumer, closing the old one in the background.
+// The supervisor owns the new one until it is replaced or the model shuts
+// down.
+func (m *Model) setConsumer(c *kafka.Consumer) {
+	if m.consumer != nil {
+		go m.supervisor.Release(m.consumer)
+	}
+	m.consumer = c
+	if c != nil {
+		m.supervisor.Own(c)
+	}
+}
+
+// SetTunnel hands the model the SSH tunnel its connections go through, to
+// close on shutdown
+func (m *Model) SetTunnel(t *tunnel.Tunnel) {
+	m.sshTunnel = t
+}
+
+// Shutdown stops the model's background work and closes its consumers,
+// producer, subject store and SSH tunnel. Call it once the program has
+// exited, or before discarding the model.
+func (m Model) Shutdown() error {
+	err := m.supervisor.Shutdown(shutdownTimeout)
+	if m.subjectStore != nil {
+		err = errors.Join(err, m.subjectStore.Close())
+	}
+	if m.producer != nil {
+		err = errors.Join(err, m.producer.Close())
+	}
+	if m.sshTunnel != nil {
+		err = errors.Join(err, m.sshTunnel.Close())
+	}
+	return err
+}
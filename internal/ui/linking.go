@@ -0,0 +1,83 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// linksLoadedMsg carries the schema linking state of the loaded subjects
+type linksLoadedMsg struct {
+	links map[string]registry.Link
+	err   error
+}
+
+// subjectModeMsg carries the mode of a single subject, fetched when it is viewed
+type subjectModeMsg struct {
+	subject string
+	mode    string
+	err     error
+}
+
+func (m Model) loadLinks(subjects []string) tea.Cmd {
+	return func() tea.Msg {
+		links, err := m.client.Links(subjects)
+		return linksLoadedMsg{links: links, err: err}
+	}
+}
+
+func (m Model) loadSubjectMode(subject string) tea.Cmd {
+	return func() tea.Msg {
+		mode, err := m.client.GetMode(subject)
+		return subjectModeMsg{subject: subject, mode: mode, err: err}
+	}
+}
+
+// handleSubjectMode records a viewed subject's mode. Errors are ignored, as
+// registries without the mode API simply have no read-only subjects.
+func (m *Model) handleSubjectMode(msg subjectModeMsg) {
+	if msg.err != nil {
+		return
+	}
+	link := m.links[msg.subject]
+	link.Mode = msg.mode
+	if link.ReadOnly() && link.Source == "" {
+		link.Source = registry.LinkSource(msg.subject)
+	}
+	if link.ReadOnly() || len(link.Exporters) > 0 {
+		m.links[msg.subject] = link
+	} else {
+		delete(m.links, msg.subject)
+	}
+}
+
+// isReadOnly reports whether the registry will reject registrations for subject
+func (m Model) isReadOnly(subject string) bool {
+	return m.links[subject].ReadOnly()
+}
+
+// linkBanner renders the viewer notice for linked subjects
+func (m Model) linkBanner(subject string) string {
+	link, ok := m.links[subject]
+	if !ok {
+		return ""
+	}
+
+	var lines []string
+	if link.ReadOnly() {
+		lines = append(lines, ReadOnlyBannerStyle.Render(
+			fmt.Sprintf("🔒 READ-ONLY (%s mode): schemas are managed by %s", link.Mode, link.Source)))
+	}
+	for _, exporter := range link.Exporters {
+		dest := exporter.Destination()
+		if dest == "" {
+			dest = "another registry"
+		}
+		lines = append(lines, HelpStyle.Render(
+			fmt.Sprintf("⇢ Exported to %s by exporter %s", dest, exporter.Name)))
+	}
+	return strings.Join(lines, "\n")
+}
@@ -0,0 +1,164 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/mask"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// localSchemaPoll is how often an open local schema file is checked for
+// changes
+const localSchemaPoll = 500 * time.Millisecond
+
+// localSchemaFile is a schema opened from disk and watched for changes
+type localSchemaFile struct {
+	path    string
+	modTime time.Time
+	size    int64
+}
+
+// localSchemaMsg carries a local schema file read from disk
+type localSchemaMsg struct {
+	file   localSchemaFile
+	schema string
+	reload bool // Read because the file changed, rather than opened
+	err    error
+}
+
+// localSchemaTickMsg is the poll timer for a local schema file. Each open
+// starts a new watch, and ticks from earlier ones are dropped.
+type localSchemaTickMsg struct {
+	watch int
+}
+
+// enterLocalSchemaPrompt asks for a local .avsc file to open
+func (m *Model) enterLocalSchemaPrompt() {
+	m.localSchemaPrompt = NewOpenPrompt("Open Local Schema", "Schema file (.avsc), reloaded when it changes", m.localSchema.path, ".avsc")
+	m.state = stateLocalSchemaPrompt
+}
+
+func (m *Model) handleLocalSchemaPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.localSchemaPrompt.Update(msg)
+	m.localSchemaPrompt = newModel.(TextPromptModel)
+	if !m.localSchemaPrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateBrowsing
+	if m.currentSchema != "" {
+		m.state = stateViewing
+	}
+	path := m.localSchemaPrompt.Value()
+	if !m.localSchemaPrompt.Saved() || path == "" {
+		return m, nil
+	}
+	m.statusMsg = fmt.Sprintf("Opening %s...", path)
+	return m, readLocalSchema(path, false)
+}
+
+// readLocalSchema reads and checks a local schema file
+func readLocalSchema(path string, reload bool) tea.Cmd {
+	return func() tea.Msg {
+		info, err := os.Stat(path)
+		if err != nil {
+			return localSchemaMsg{file: localSchemaFile{path: path}, reload: reload, err: err}
+		}
+		file := localSchemaFile{path: path, modTime: info.ModTime(), size: info.Size()}
+		data, err := os.ReadFile(path)
+		if err != nil {
+			return localSchemaMsg{file: file, reload: reload, err: err}
+		}
+		if _, err := avro.NewCodec(string(data)); err != nil {
+			return localSchemaMsg{file: file, reload: reload, err: fmt.Errorf("%s: invalid schema: %w", filepath.Base(path), err)}
+		}
+		return localSchemaMsg{file: file, schema: string(data), reload: reload}
+	}
+}
+
+// watchLocalSchema waits one poll interval before checking the file again
+func (m Model) watchLocalSchema() tea.Cmd {
+	watch := m.localSchemaWatch
+	return tea.Tick(localSchemaPoll, func(time.Time) tea.Msg {
+		return localSchemaTickMsg{watch: watch}
+	})
+}
+
+func (m *Model) handleLocalSchemaTick(msg localSchemaTickMsg) tea.Cmd {
+	if msg.watch != m.localSchemaWatch || m.localSchema.path == "" {
+		return nil // Another schema was opened since
+	}
+	path := m.localSchema.path
+	info, err := os.Stat(path)
+	if err != nil || (info.ModTime().Equal(m.localSchema.modTime) && info.Size() == m.localSchema.size) {
+		// Editors often replace the file on save, so a missing file is
+		// checked again rather than given up on
+		return m.watchLocalSchema()
+	}
+	return readLocalSchema(path, true)
+}
+
+func (m *Model) handleLocalSchema(msg localSchemaMsg) tea.Cmd {
+	if msg.err != nil {
+		m.err = msg.err
+		if msg.reload && msg.file.path == m.localSchema.path {
+			// Keep the last good schema, and don't report this version again
+			m.localSchema = msg.file
+			return m.watchLocalSchema()
+		}
+		return nil
+	}
+	if msg.reload && msg.file.path != m.localSchema.path {
+		return nil
+	}
+
+	oldTemplate, _ := m.generateTemplate()
+
+	m.err = nil
+	m.localSchema = msg.file
+	m.rawSchema = msg.schema
+	m.schemaType = ""
+	m.schemaID = 0
+	m.schemaVersion = 0
+	m.ruleSet = nil
+	m.protocol = nil
+	m.masker = mask.New(m.cfg.Mask, msg.schema)
+	m.currentSchema = registry.PrettyPrintSchema(msg.schema)
+	m.viewer.SetContent(m.currentSchema)
+
+	if msg.reload {
+		m.copyNotify = fmt.Sprintf("Reloaded %s", filepath.Base(msg.file.path))
+		// Refresh the payload only while it is still the untouched template
+		if m.state == stateSendMode && m.editor.Value() == oldTemplate {
+			if template, err := m.generateTemplate(); err == nil {
+				m.editor.SetValue(template)
+			}
+		}
+		return m.watchLocalSchema()
+	}
+
+	m.localSchemaWatch++
+	m.selectedSubject = strings.TrimSuffix(filepath.Base(msg.file.path), filepath.Ext(msg.file.path))
+	m.fanoutTopics = nil
+	m.viewer.GotoTop()
+	m.state = stateViewing
+	m.focusedPane = viewerPane
+	m.statusMsg = fmt.Sprintf("[VIEW] %s (local file, reloaded on change)", msg.file.path)
+	return m.watchLocalSchema()
+}
+
+// localSchemaSendError stops a payload validated against a local schema
+// from being produced, as the schema has no registry ID to frame it with
+func (m Model) localSchemaSendError() error {
+	if m.localSchema.path == "" {
+		return nil
+	}
+	return fmt.Errorf("payload is valid for %s, but local schemas aren't registered, so it can't be produced", filepath.Base(m.localSchema.path))
+}
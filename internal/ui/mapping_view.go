@@ -0,0 +1,117 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// schemaMappedMsg carries the field mapping from the viewed schema to
+// another one
+type schemaMappedMsg struct {
+	target  string
+	mapping *avro.FieldMapping
+	err     error
+}
+
+// enterMappingPrompt asks which schema to map the viewed schema's fields to
+func (m *Model) enterMappingPrompt() {
+	m.mappingPrompt = NewOpenPrompt("Map Fields To", "Target schema: subject[@version] or an .avsc file", m.lastMapping, ".avsc")
+	m.state = stateMappingPrompt
+}
+
+func (m *Model) handleMappingPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.mappingPrompt.Update(msg)
+	m.mappingPrompt = newModel.(TextPromptModel)
+	if !m.mappingPrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateViewing
+	target := m.mappingPrompt.Value()
+	if !m.mappingPrompt.Saved() || target == "" {
+		return m, nil
+	}
+	m.lastMapping = target
+	m.statusMsg = fmt.Sprintf("Mapping %s to %s...", m.selectedSubject, target)
+	return m, mapSchema(m.client, m.rawSchema, target)
+}
+
+func (m *Model) handleSchemaMapped(msg schemaMappedMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = fmt.Errorf("mapping to %s: %w", msg.target, msg.err)
+		return
+	}
+	m.openReport(fmt.Sprintf("Field Mapping: %s → %s", m.selectedSubject, msg.target), renderMapping(msg.mapping))
+	m.reportCopy = msg.mapping.JQ()
+}
+
+// mapSchema loads the target schema and pairs its fields with the source's
+func mapSchema(client *registry.Client, source, target string) tea.Cmd {
+	return func() tea.Msg {
+		targetSchema, err := loadSchema(client, target)
+		if err != nil {
+			return schemaMappedMsg{target: target, err: err}
+		}
+		mapping, err := avro.MapFields(source, targetSchema)
+		return schemaMappedMsg{target: target, mapping: mapping, err: err}
+	}
+}
+
+func renderMapping(mapping *avro.FieldMapping) string {
+	var b strings.Builder
+
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("%d mapped, %d missing, %d conflicting, %d defaulted, %d dropped  ·  [y] Copy jq",
+		len(mapping.Pairs), len(mapping.Missing), len(mapping.Conflicts), len(mapping.Defaulted), len(mapping.Dropped))))
+	b.WriteString("\n\n")
+
+	if len(mapping.Missing) > 0 {
+		b.WriteString(ErrorStyle.Render("Required, no source:"))
+		b.WriteString("\n")
+		for _, path := range mapping.Missing {
+			b.WriteString("  " + path + "\n")
+		}
+		b.WriteString("\n")
+	}
+	if len(mapping.Conflicts) > 0 {
+		b.WriteString(ErrorStyle.Render("Type conflicts:"))
+		b.WriteString("\n")
+		for _, p := range mapping.Conflicts {
+			b.WriteString(fmt.Sprintf("  %s ← %s: %s\n", p.Target, p.Source, p.Note))
+		}
+		b.WriteString("\n")
+	}
+
+	b.WriteString(SuccessStyle.Render("Mapped:"))
+	b.WriteString("\n")
+	for _, p := range mapping.Pairs {
+		line := fmt.Sprintf("  %s ← %s", p.Target, p.Source)
+		if p.Match != avro.MatchName {
+			line += HelpStyle.Render(" (" + p.Match + ")")
+		}
+		if p.Note != "" {
+			line += DiffChangedStyle.Render("  " + p.Note)
+		}
+		b.WriteString(line + "\n")
+	}
+	b.WriteString("\n")
+
+	if len(mapping.Defaulted) > 0 {
+		b.WriteString(HelpStyle.Render("Unmapped, use their default: " + strings.Join(mapping.Defaulted, ", ")))
+		b.WriteString("\n")
+	}
+	if len(mapping.Dropped) > 0 {
+		b.WriteString(HelpStyle.Render("Source fields dropped: " + strings.Join(mapping.Dropped, ", ")))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	b.WriteString("Transformation (jq, over plain JSON):\n")
+	b.WriteString(mapping.JQ())
+	return b.String()
+}
@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+)
+
+// handleMark processes vim-style marks in the schema viewer: `m` followed by a
+// letter records the current line, `'` followed by a letter jumps back to it,
+// and `'` pressed twice returns to the line before the last jump. Marks are
+// kept per subject for the lifetime of the session. Returns false if the key
+// was not part of a mark command.
+func (m *Model) handleMark(key string) bool {
+	pending := m.pendingMark
+	m.pendingMark = ""
+
+	if pending == "" {
+		if key == "m" || key == "'" {
+			m.pendingMark = key
+			return true
+		}
+		return false
+	}
+
+	if len(key) != 1 {
+		// Any non-character key (esc, arrows, ...) cancels the pending mark
+		return key == "esc"
+	}
+	name := rune(key[0])
+
+	if pending == "m" {
+		if !isMarkName(name) {
+			return true
+		}
+		m.setMark(name, m.viewer.YOffset)
+		m.copyNotify = fmt.Sprintf("Mark '%c' set at line %d", name, m.viewer.YOffset+1)
+		return true
+	}
+
+	// pending == "'"
+	line, ok := m.subjectMarks()[name]
+	if !ok {
+		m.err = fmt.Errorf("mark '%c' not set", name)
+		return true
+	}
+	m.setMark('\'', m.viewer.YOffset)
+	m.viewer.SetYOffset(line)
+	return true
+}
+
+// setMark records a line for the current subject
+func (m *Model) setMark(name rune, line int) {
+	if m.marks == nil {
+		m.marks = make(map[string]map[rune]int)
+	}
+	if m.marks[m.selectedSubject] == nil {
+		m.marks[m.selectedSubject] = make(map[rune]int)
+	}
+	m.marks[m.selectedSubject][name] = line
+}
+
+// subjectMarks returns the marks recorded for the current subject
+func (m *Model) subjectMarks() map[rune]int {
+	return m.marks[m.selectedSubject]
+}
+
+func isMarkName(r rune) bool {
+	return (r >= 'a' && r <= 'z') || (r >= 'A' && r <= 'Z')
+}
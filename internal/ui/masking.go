@@ -0,0 +1,72 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/mask"
+)
+
+// maskDoc hides the masked fields of a decoded value unless the user has
+// unmasked them
+func (m Model) maskDoc(doc interface{}) interface{} {
+	if m.unmasked {
+		return doc
+	}
+	return m.masker.Apply(doc)
+}
+
+// visibleMessages returns the decoded messages as they may be shown or
+// exported, with masked fields hidden
+func (m Model) visibleMessages() []decodedMessage {
+	if m.masker == nil || m.unmasked {
+		return m.decodedMessages
+	}
+	visible := make([]decodedMessage, len(m.decodedMessages))
+	for i, d := range m.decodedMessages {
+		d.doc = m.masker.Apply(d.doc)
+		visible[i] = d
+	}
+	return visible
+}
+
+// toggleUnmask shows or hides masked fields for the rest of the consumer
+// session
+func (m *Model) toggleUnmask() {
+	if m.masker == nil {
+		m.err = fmt.Errorf("no masked fields in %s (configure mask in the config file)", m.selectedSubject)
+		return
+	}
+	m.unmasked = !m.unmasked
+	if m.unmasked {
+		m.statusMsg = "Masked fields are visible until you leave consumer mode"
+	} else {
+		m.statusMsg = "Masked fields hidden"
+	}
+}
+
+// payloadRedactor returns a function replacing a send mode payload's masked
+// fields with fakes, or nil if the schema has none. Redacted payloads must
+// still fit the schema.
+func (m Model) payloadRedactor() func(string) (string, error) {
+	if m.masker == nil {
+		return nil
+	}
+	redactor := mask.NewRedactor(m.masker)
+	schema := m.rawSchema
+	return func(payload string) (string, error) {
+		doc, err := parseJSONNumbers(payload)
+		if err != nil {
+			return "", fmt.Errorf("invalid JSON: %w", err)
+		}
+		redacted, err := json.MarshalIndent(redactor.Redact(doc), "", "  ")
+		if err != nil {
+			return "", err
+		}
+		if _, err := avro.ValidateAndEncode(schema, string(redacted)); err != nil {
+			return "", fmt.Errorf("redacted payload doesn't fit the schema (a sensitive enum field?), press ctrl+r to save it unredacted: %w", err)
+		}
+		return string(redacted), nil
+	}
+}
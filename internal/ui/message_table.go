@@ -0,0 +1,254 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// maxCellWidth truncates long values so one field can't push the rest of a
+// table row off screen
+const maxCellWidth = 30
+
+// tableColumnsFor returns the table columns for a topic: those edited this
+// session, else the config file's table_columns, else the defaults
+func (m Model) tableColumnsFor(topic string) []messageColumn {
+	if columns, ok := m.tableColumns[topic]; ok {
+		return columns
+	}
+	if spec, ok := m.cfg.TableColumns[topic]; ok {
+		if columns, err := parseColumns(spec); err == nil && len(columns) > 0 {
+			return columns
+		}
+	}
+	return defaultColumns(m.rawSchema)
+}
+
+// tableOrder returns indexes into decodedMessages in display order
+func (m Model) tableOrder(columns []messageColumn) []int {
+	order := make([]int, len(m.decodedMessages))
+	for i := range order {
+		order[i] = i
+	}
+	if m.tableSortCol < 0 || m.tableSortCol >= len(columns) {
+		return order
+	}
+
+	col := columns[m.tableSortCol]
+	sort.SliceStable(order, func(i, j int) bool {
+		a, aok := col.value(m.decodedMessages[order[i]])
+		b, bok := col.value(m.decodedMessages[order[j]])
+		// Missing values sort last in either direction
+		if !aok || a == nil || !bok || b == nil {
+			return (aok && a != nil) && !(bok && b != nil)
+		}
+		if m.tableSortDesc {
+			return lessValue(b, a)
+		}
+		return lessValue(a, b)
+	})
+	return order
+}
+
+// lessValue orders numbers numerically, times chronologically and anything
+// else by its text
+func lessValue(a, b interface{}) bool {
+	if x, ok := toFloat(a); ok {
+		if y, ok := toFloat(b); ok {
+			return x < y
+		}
+	}
+	if x, ok := a.(time.Time); ok {
+		if y, ok := b.(time.Time); ok {
+			return x.Before(y)
+		}
+	}
+	return fmt.Sprint(a) < fmt.Sprint(b)
+}
+
+func toFloat(v interface{}) (float64, bool) {
+	switch n := v.(type) {
+	case float64:
+		return n, true
+	case int64:
+		return float64(n), true
+	}
+	return 0, false
+}
+
+// handleTableKey handles consumer mode keys specific to the table view.
+// Returns false for keys the table doesn't use.
+func (m *Model) handleTableKey(key string) (bool, tea.Cmd) {
+	topic := m.topic()
+	columns := m.tableColumnsFor(topic)
+
+	switch key {
+	case "j", "down", "k", "up":
+		order := m.tableOrder(columns)
+		for pos, idx := range order {
+			if idx != m.currentMsgIdx {
+				continue
+			}
+			if (key == "j" || key == "down") && pos < len(order)-1 {
+				m.currentMsgIdx = order[pos+1]
+			} else if (key == "k" || key == "up") && pos > 0 {
+				m.currentMsgIdx = order[pos-1]
+			}
+			break
+		}
+		return true, nil
+
+	case "s":
+		// Cycle the sort column, ending back at fetch order
+		m.tableSortCol++
+		if m.tableSortCol >= len(columns) {
+			m.tableSortCol = -1
+		}
+		return true, nil
+
+	case "S":
+		m.tableSortDesc = !m.tableSortDesc
+		return true, nil
+
+	case "C":
+		headers := make([]string, len(columns))
+		for i, c := range columns {
+			headers[i] = c.header
+		}
+		m.columnsPrompt = NewTextPrompt("Table Columns: "+topic,
+			"Comma-separated: offset, key, timestamp or JSONPaths into the decoded value. Empty resets to the defaults.",
+			strings.Join(headers, ", "))
+		m.state = stateEditingColumns
+		return true, nil
+	}
+
+	return false, nil
+}
+
+func (m *Model) handleEditingColumns(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.columnsPrompt.Update(msg)
+	m.columnsPrompt = newModel.(TextPromptModel)
+
+	if !m.columnsPrompt.Quit() {
+		return m, cmd
+	}
+	m.state = stateConsumerMode
+	if !m.columnsPrompt.Saved() {
+		return m, nil
+	}
+
+	topic := m.topic()
+	if m.tableColumns == nil {
+		m.tableColumns = make(map[string][]messageColumn)
+	}
+	if m.columnsPrompt.Value() == "" {
+		delete(m.tableColumns, topic)
+	} else {
+		columns, err := parseColumns(m.columnsPrompt.Value())
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.tableColumns[topic] = columns
+	}
+	m.tableSortCol = -1
+	return m, nil
+}
+
+func (m Model) renderMessageTable(width, height int) string {
+	var b strings.Builder
+
+	b.WriteString(EditTitleStyle.Render("Messages Table"))
+	b.WriteString("\n")
+
+	columns := m.tableColumnsFor(m.topic())
+	if len(m.decodedMessages) == 0 || len(columns) == 0 {
+		b.WriteString(HelpStyle.Render("No messages fetched. Press 'f' to fetch."))
+		return b.String()
+	}
+
+	order := m.tableOrder(columns)
+	messages := m.visibleMessages()
+	cells := make([][]string, len(order))
+	widths := make([]int, len(columns))
+	for i, c := range columns {
+		widths[i] = lipgloss.Width(c.header) + 2 // Room for the sort arrow
+	}
+	for r, idx := range order {
+		cells[r] = make([]string, len(columns))
+		for i, c := range columns {
+			cell := truncateCell(strings.ReplaceAll(c.cell(messages[idx]), "\n", " "))
+			cells[r][i] = cell
+			widths[i] = max(widths[i], lipgloss.Width(cell))
+		}
+	}
+
+	header := make([]string, len(columns))
+	for i, c := range columns {
+		h := c.header
+		if i == m.tableSortCol {
+			if m.tableSortDesc {
+				h += " ↓"
+			} else {
+				h += " ↑"
+			}
+		}
+		header[i] = padCell(h, widths[i])
+	}
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(clipLine(strings.Join(header, " │ "), width-2)))
+	b.WriteString("\n")
+
+	// Keep the selected row in view
+	visible := max(height-6, 1)
+	start := 0
+	for r, idx := range order {
+		if idx == m.currentMsgIdx && r >= visible {
+			start = r - visible + 1
+		}
+	}
+
+	for r := start; r < len(order) && r < start+visible; r++ {
+		row := make([]string, len(columns))
+		for i := range columns {
+			row[i] = padCell(cells[r][i], widths[i])
+		}
+		line := clipLine(strings.Join(row, " │ "), width-2)
+		if order[r] == m.currentMsgIdx {
+			line = lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true).Render(line)
+		}
+		b.WriteString(line)
+		b.WriteString("\n")
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("[s] sort column  [S] reverse  [C] edit columns  [t] message view"))
+	return b.String()
+}
+
+func truncateCell(s string) string {
+	runes := []rune(s)
+	if len(runes) <= maxCellWidth {
+		return s
+	}
+	return string(runes[:maxCellWidth-1]) + "…"
+}
+
+func padCell(s string, width int) string {
+	if pad := width - lipgloss.Width(s); pad > 0 {
+		return s + strings.Repeat(" ", pad)
+	}
+	return s
+}
+
+// clipLine cuts a line to width runes
+func clipLine(s string, width int) string {
+	runes := []rune(s)
+	if width <= 0 || len(runes) <= width {
+		return s
+	}
+	return string(runes[:width])
+}
@@ -0,0 +1,77 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+	"time"
+
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+)
+
+// metricsGroups orders the groups in the metrics report
+var metricsGroups = []string{metrics.Registry, metrics.Kafka, metrics.UI}
+
+// renderMetrics reports the session's registry, Kafka and UI timings,
+// grouped so it's clear where the time went
+func renderMetrics(stats []metrics.Stat) string {
+	if len(stats) == 0 {
+		return HelpStyle.Render("Nothing recorded yet")
+	}
+
+	var b strings.Builder
+	totals := map[string]time.Duration{}
+	var all time.Duration
+	for _, s := range stats {
+		totals[s.Group()] += s.Total
+		all += s.Total
+	}
+	var parts []string
+	for _, g := range metricsGroups {
+		share := 0.0
+		if all > 0 {
+			share = 100 * float64(totals[g]) / float64(all)
+		}
+		parts = append(parts, fmt.Sprintf("%s %s (%.0f%%)", g, formatTiming(totals[g]), share))
+	}
+	b.WriteString(HelpStyle.Render("Time spent: " + strings.Join(parts, ", ")))
+	b.WriteString("\n")
+
+	for _, g := range metricsGroups {
+		b.WriteString("\n")
+		b.WriteString(ListTitleStyle.Render(g))
+		b.WriteString("\n")
+		b.WriteString(fmt.Sprintf("  %-34s %7s %6s %9s %9s %9s\n", "operation", "count", "errors", "mean", "p95", "max"))
+		found := false
+		for _, s := range stats {
+			if s.Group() != g {
+				continue
+			}
+			found = true
+			name := strings.TrimPrefix(s.Name, g+" ")
+			line := fmt.Sprintf("  %-34s %7d %6d %9s %9s %9s", name, s.Count, s.Errors,
+				formatTiming(s.Mean()), formatTiming(s.Quantile(0.95)), formatTiming(s.Max))
+			if s.Errors > 0 {
+				line = ErrorStyle.Render(line)
+			}
+			b.WriteString(line + "\n")
+		}
+		if !found {
+			b.WriteString(HelpStyle.Render("  none") + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("p95 is estimated from histogram buckets. Fetches include waiting for messages on quiet topics."))
+	return b.String()
+}
+
+// formatLatency rounds a duration for display
+func formatTiming(d time.Duration) string {
+	switch {
+	case d >= time.Second:
+		return d.Round(10 * time.Millisecond).String()
+	case d >= time.Millisecond:
+		return d.Round(10 * time.Microsecond).String()
+	}
+	return d.Round(time.Microsecond).String()
+}
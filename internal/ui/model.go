@@ -5,6 +5,7 @@ import (
 	"encoding/base64"
 	"encoding/json"
 	"fmt"
+	"math/rand"
 	"strings"
 	"time"
 	"unicode/utf8"
@@ -17,11 +18,26 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/charmbracelet/lipgloss"
 
+	"github.com/JimmyyyW/avrocado/internal/annotation"
 	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/bookmark"
 	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/csfle"
+	"github.com/JimmyyyW/avrocado/internal/deeplink"
+	"github.com/JimmyyyW/avrocado/internal/deprecation"
 	"github.com/JimmyyyW/avrocado/internal/editor"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/jsonschema"
 	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/mask"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
 	"github.com/JimmyyyW/avrocado/internal/registry"
+	"github.com/JimmyyyW/avrocado/internal/report"
+	"github.com/JimmyyyW/avrocado/internal/session"
+	"github.com/JimmyyyW/avrocado/internal/snippet"
+	"github.com/JimmyyyW/avrocado/internal/subjectstore"
+	"github.com/JimmyyyW/avrocado/internal/supervisor"
+	"github.com/JimmyyyW/avrocado/internal/tunnel"
 )
 
 type pane int
@@ -43,30 +59,76 @@ const (
 	stateSavingEvent
 	stateLoadingEvent
 	stateConsumerMode
+	stateReport
+	stateCopyAs
+	stateEditingDeprecation
+	stateContractPrompt
+	stateExportingCSV
+	stateEditingColumns
+	stateBackfillForm
+	statePickingPayloadFile
+	stateSavingPayloadFile
+	statePickingFanout
+	statePatchTool
+	stateProtocolBrowser
+	stateMappingPrompt
+	stateVersionPicker
+	stateEditingSchema
+	stateLocalSchemaPrompt
+	stateRenameFieldPrompt
+	stateSchemaWizard
+	stateSavingScaffold
+	stateSnippetPicker
+	stateSavingSnippet
+	stateTopicPicker
+	stateTopicConfig
+	stateDeletingSubject
+	stateReaderPicker
+	stateAnnotating
+	stateCompatibility
+	stateProfileSwitcher
+	stateUsagePrompt
+	stateQualityPrompt
 )
 
 type Model struct {
-	client   *registry.Client
-	producer *kafka.Producer
-	cfg      *config.Config
+	client     *registry.Client
+	producer   *kafka.Producer
+	cfg        *config.Config
+	supervisor *supervisor.Supervisor // Owns background work and consumers, stopped by Shutdown
+	sshTunnel  *tunnel.Tunnel         // Closed by Shutdown, nil without one
 
 	subjects         []string
+	subjectStore     *subjectstore.Store // On-disk subject list, for very large registries
 	filteredSubjects []string
+	moreSubjects     bool // The store has more matches than filteredSubjects shows
+	subjectFilter    subjectFilter
+	scopedSubjects   []string // The project's subjects, nil without a project scope
+	projectScope     bool     // List only scopedSubjects
 	selectedIndex    int
 	selectedSubject  string
 	currentSchema    string
 	rawSchema        string // Original schema JSON for validation
+	schemaType       string // Registry schema type, empty or AVRO for Avro
 	schemaID         int
+	schemaVersion    int
+	ruleSet          *registry.RuleSet // Data contract rules of the loaded schema
 
 	searchInput textinput.Model
-	keyInput    textinput.Model  // Message key input
-	viewer      viewport.Model   // Read-only schema view
-	editor      textarea.Model   // Editable send mode
+	keyInput    textinput.Model // Message key input
+	viewer      viewport.Model  // Read-only schema view
+	editor      textarea.Model  // Editable send mode
+	composer    *formComposer   // Field-by-field form over the editor's payload
+	formMode    bool            // Send mode shows the form instead of the raw JSON
 	help        help.Model
 
-	focusedPane pane
-	state       state
-	sendKeyFocused bool // Track if key field has focus in send mode
+	focusedPane     pane
+	state           state
+	sendKeyFocused  bool                     // Track if key field has focus in send mode
+	keyTopic        string                   // Topic whose key schema send mode looked up
+	keySchema       *registry.SchemaResponse // Key schema of keyTopic, nil for raw string keys
+	keySchemaLoaded bool
+	writeProbes     map[string]*kafka.WriteProbe // Topics probed for write permission this session, nil if fine
 
 	width  int
 	height int
@@ -82,15 +144,183 @@ type Model struct {
 	eventLoader EventLoaderModel
 
 	// Consumer mode
-	consumer         *kafka.Consumer
-	consumedMessages []kafka.Message
-	currentMsgIdx    int
-	isLoadingMessages bool // Track if we're fetching messages
-	spinnerFrame     int   // Spinner animation frame
+	consumer          *kafka.Consumer
+	consumedMessages  []kafka.Message
+	currentMsgIdx     int
+	consumerLag       int64 // Messages remaining after the last fetch, -1 if unknown
+	isLoadingMessages bool  // Track if we're fetching messages
+	tailing           bool  // Following the topic, fetching as messages arrive
+	tailFetching      bool  // A tail fetch is in flight
+	spinnerFrame      int   // Spinner animation frame
+	throughput        throughputStats
+	wireDecoder       *avro.WireDecoder // Decodes by wire-format schema ID, for columns
+	decodedMessages   []decodedMessage  // consumedMessages decoded for columns and the message view
+	csvExport         CSVExportModel
+
+	// Table view of consumed messages
+	tableView     bool
+	tableColumns  map[string][]messageColumn // Per topic, when edited this session
+	tableSortCol  int                        // -1 for fetch order
+	tableSortDesc bool
+	columnsPrompt TextPromptModel
+
+	// Payload files outside the events directory
+	filePicker        FilePickerModel
+	payloadFilePrompt TextPromptModel
+	payloadFileDir    string // Directory of the last payload file loaded or saved
+	payloadOverwrite  string // Existing file the user was asked about overwriting
+
+	// Extra destination topics for sending one payload to several topics
+	fanoutPicker FanoutPickerModel
+	fanoutTopics []string
+
+	patchTool PatchToolModel
+
+	// Avro protocols (.avpr): the viewed subject's, if it holds one
+	protocol        *avro.Protocol
+	protocolBrowser ProtocolBrowserModel
+	protocolReturn  state
+
+	// Consumed messages pinned for comparison
+	pinned []decodedMessage
+
+	// Historical range being consumed
+	backfill     *backfillState
+	backfillID   int
+	backfillForm BackfillFormModel
+
+	// Last offset viewed per topic, to resume consumer sessions
+	bookmarks *bookmark.Store
+
+	// Reusable field blocks for the schema editor and wizard
+	snippets      *snippet.Store
+	snippetPicker SnippetPickerModel
+	snippetReturn state // Where the picker was opened from
+	snippetPrompt TextPromptModel
+	snippetDraft  snippet.Snippet // Snippet being saved, filled in prompt by prompt
+
+	// Topic override for the selected subject
+	topicPicker       TopicPickerModel
+	topicReturn       state // Where the picker was opened from
+	topicConfig       TopicConfigModel
+	topicConfigReturn state
+
+	// Registry compatibility levels
+	compatibility       CompatibilityModel
+	compatibilityReturn state
+
+	// Schema beside the consumed message or payload, in place of the list
+	splitView  bool
+	splitField int // Highlighted field of the consumed message, by key line
+
+	// Sample size for field usage statistics
+	usagePrompt TextPromptModel
+
+	// Sample size for a data quality check
+	qualityPrompt TextPromptModel
+
+	// Runtime profile switching
+	profileSwitcher ProfileSwitcherModel
+	profileReturn   state
+
+	// Confirms deleting the viewed subject or version
+	deleteSubject DeleteSubjectModel
+
+	// Version consumed messages are resolved to, nil to show them as written
+	readerSchema *registry.SchemaResponse
+	readerPicker ReaderPickerModel
+
+	// Notes on consumed messages
+	notes      *annotation.Store
+	notePrompt TextPromptModel
+
+	// Deep link being opened, until its subject or message is shown
+	link *deeplink.Link
+
+	// Field-level encryption for schemas with ENCRYPT rules
+	encryptor *csfle.Encryptor
+
+	// Sensitive fields hidden in the consumer, and whether they are shown
+	masker   *mask.Masker
+	unmasked bool
+
+	// Layout and session restore
+	listPercent     int            // Width of the subjects pane as a percentage
+	restore         *session.State // Pending session to apply once subjects load
+	restoreConsumer bool           // Re-open consumer mode once the restored schema loads
+
+	// Vim-style motions
+	count    int  // Pending numeric prefix
+	pendingG bool // First g of gg was pressed
+
+	// Schema viewer marks, per subject
+	marks       map[string]map[rune]int
+	pendingMark string // "m" or "'" while waiting for the mark name
+
+	// Visual-line selection in the viewer and editor
+	visual          bool
+	visualAnchor    int
+	visualCursor    int
+	editorSelecting bool
+	editorAnchor    int
+
+	// Report pane (diffs, coverage and other read-only output)
+	reportView   viewport.Model
+	reportTitle  string
+	reportReturn state  // State to return to when the report is closed
+	reportCopy   string // What y copies from the report, if anything
+
+	// Copy-as menu
+	copyAsIdx    int
+	copyAsReturn state
+
+	// Subject deprecations
+	deprecations         *deprecation.Store
+	registryDeprecations map[string]deprecation.Deprecation // From schema metadata, as schemas load
+	deprecationEditor    DeprecationEditorModel
+
+	// Schema linking: read-only (imported) and exported subjects
+	links map[string]registry.Link
+
+	// Contract tests against consumer reader schemas
+	contractPrompt ContractPromptModel
+	lastContract   map[string]string // Last consumer tested, per subject
+
+	// Field mapping to another schema
+	mappingPrompt TextPromptModel
+	lastMapping   string // Last target schema mapped to
+
+	// Diff between two versions of the viewed subject
+	versionPicker VersionPickerModel
+
+	// Schema edited to register as a new version
+	schemaEditor    textarea.Model
+	confirmRegister bool // Ctrl+S pressed, waiting for y/n
+	renamePrompt    TextPromptModel
+	renameFrom      string // Field being renamed, once the first prompt is answered
+
+	// New schema wizard and where to save its result
+	schemaWizard      SchemaWizardModel
+	scaffoldPrompt    TextPromptModel
+	scaffoldOverwrite string // Path already warned about, so a second enter overwrites it
+
+	// Schema opened from a local file instead of the registry
+	localSchemaPrompt TextPromptModel
+	localSchema       localSchemaFile
+	localSchemaWatch  int // Counts opens, so only the latest watch keeps polling
+
+	// Registry rate limiting: reports from the client, and when the
+	// request waiting it out is retried
+	throttles      chan registry.Throttle
+	throttledUntil time.Time
+
+	freshIDs bool // Write a new idempotency key into each sent payload
 }
 
 type subjectsLoadedMsg struct {
 	subjects []string
+	store    *subjectstore.Store // Set instead of subjects for very large registries
+	storeErr error               // Why a very large list is held in memory instead
 	err      error
 }
 
@@ -100,8 +330,12 @@ type schemaLoadedMsg struct {
 }
 
 type messageSentMsg struct {
-	topic string
-	err   error
+	topic          string
+	key            string
+	payload        string        // As sent, with any fresh idempotency key
+	idempotencyKey string        // Fresh idempotency key written, if any
+	ack            time.Duration // Time until the brokers acknowledged the message
+	err            error
 }
 
 type externalEditorMsg struct {
@@ -111,6 +345,8 @@ type externalEditorMsg struct {
 
 type messagesLoadedMsg struct {
 	messages []kafka.Message
+	decoded  []decodedMessage
+	lag      int64
 	err      error
 }
 
@@ -130,35 +366,79 @@ func NewModel(client *registry.Client, producer *kafka.Producer, cfg *config.Con
 	ta := textarea.New()
 	ta.Placeholder = "Edit message payload..."
 	ta.ShowLineNumbers = true
+	ta.MaxHeight = 0 // Payloads can run to thousands of lines; the default cuts them at 99
 	ta.SetWidth(40)
 	ta.SetHeight(20)
 
 	h := help.New()
 	h.ShowAll = false
 
+	var startupErr error
+	deprecations, err := deprecation.LoadStore(deprecation.GetStorePath())
+	if err != nil {
+		startupErr = err
+		deprecations, _ = deprecation.LoadStore("")
+	}
+	bookmarks, err := bookmark.LoadStore(bookmark.GetStorePath())
+	if err != nil {
+		startupErr = err
+		bookmarks, _ = bookmark.LoadStore("")
+	}
+	snippets, err := snippet.LoadStore(snippet.GetStorePath())
+	if err != nil {
+		startupErr = err
+		snippets, _ = snippet.LoadStore("")
+	}
+	notes, err := annotation.LoadStore(annotation.GetStorePath())
+	if err != nil {
+		startupErr = err
+		notes, _ = annotation.LoadStore("")
+	}
+
 	return Model{
 		client:           client,
 		producer:         producer,
 		cfg:              cfg,
+		supervisor:       supervisor.New(),
 		subjects:         []string{},
 		filteredSubjects: []string{},
 		searchInput:      ti,
 		keyInput:         ki,
 		viewer:           vp,
+		reportView:       viewport.New(40, 20),
 		editor:           ta,
 		help:             h,
 		focusedPane:      listPane,
 		state:            stateLoading,
+		listPercent:      defaultListPercent,
+		err:              startupErr,
+
+		deprecations:         deprecations,
+		bookmarks:            bookmarks,
+		snippets:             snippets,
+		notes:                notes,
+		encryptor:            csfle.New(client, cfg.KMS),
+		registryDeprecations: make(map[string]deprecation.Deprecation),
+		links:                make(map[string]registry.Link),
+		writeProbes:          make(map[string]*kafka.WriteProbe),
+
+		projectScope:   cfg.Project.Scoped(),
+		payloadFileDir: firstFixtureDir(cfg),
+		throttles:      watchThrottles(client),
 	}
 }
 
-func (m Model) Init() tea.Cmd {
-	return m.loadSubjects
+// firstFixtureDir is where payload files are first browsed from: the
+// project's first fixture directory, if any
+func firstFixtureDir(cfg *config.Config) string {
+	if dirs := cfg.Project.FixtureDirs(); len(dirs) > 0 {
+		return dirs[0]
+	}
+	return ""
 }
 
-func (m Model) loadSubjects() tea.Msg {
-	subjects, err := m.client.ListSubjects()
-	return subjectsLoadedMsg{subjects: subjects, err: err}
+func (m Model) Init() tea.Cmd {
+	return tea.Batch(m.loadSubjects, m.waitForThrottle())
 }
 
 func (m Model) loadSchema(subject string) tea.Cmd {
@@ -169,27 +449,47 @@ func (m Model) loadSchema(subject string) tea.Cmd {
 }
 
 func (m Model) sendMessage() tea.Cmd {
-	return func() tea.Msg {
+	// Determine topic from subject
+	topic := m.topic()
+	probe := m.writeProbes[topic]
+
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
 		if m.producer == nil {
 			return messageSentMsg{err: fmt.Errorf("Kafka not configured")}
 		}
+		if err := m.cfg.CheckSubject(m.selectedSubject); err != nil {
+			return messageSentMsg{err: err}
+		}
+		if probe != nil && probe.Denied {
+			return messageSentMsg{err: probe}
+		}
 
-		// Validate and encode
-		binary, err := avro.ValidateAndEncode(m.rawSchema, m.editor.Value())
+		sent, headers, idKey, err := m.withFreshID(topic, m.editor.Value())
 		if err != nil {
 			return messageSentMsg{err: err}
 		}
 
-		// Determine topic from subject
-		topic := config.SubjectToTopic(m.selectedSubject)
+		// Encrypt tagged fields, validate and encode
+		binary, err := m.encodeValue(sent)
+		if err != nil {
+			return messageSentMsg{err: err}
+		}
+		key, err := m.encodeKeyFor(topic, m.keyInput.Value())
+		if err != nil {
+			return messageSentMsg{err: err}
+		}
+		if err := m.localSchemaSendError(); err != nil {
+			return messageSentMsg{err: err}
+		}
 
 		// Produce message with optional key
-		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 10*time.Second)
 		defer cancel()
 
-		err = m.producer.ProduceWithStringKey(ctx, topic, m.schemaID, m.keyInput.Value(), binary)
-		return messageSentMsg{topic: topic, err: err}
-	}
+		start := time.Now()
+		err = m.producer.ProduceRecord(ctx, topic, m.schemaID, kafka.Record{Key: key, Value: binary, Headers: headers})
+		return messageSentMsg{topic: topic, key: m.keyInput.Value(), payload: sent, idempotencyKey: idKey, ack: time.Since(start), err: err}
+	})
 }
 
 func (m Model) openExternalEditor() tea.Cmd {
@@ -200,6 +500,7 @@ func (m Model) openExternalEditor() tea.Cmd {
 }
 
 func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	defer metrics.Observe(metrics.UI+" update", time.Now(), nil)
 	var cmds []tea.Cmd
 
 	switch msg := msg.(type) {
@@ -208,6 +509,8 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		m.height = msg.Height
 		m.viewer.Width = m.width/2 - 6
 		m.viewer.Height = m.height - 10
+		m.reportView.Width = m.width/2 - 6
+		m.reportView.Height = m.height - 10
 		m.editor.SetWidth(m.width/2 - 6)
 		m.editor.SetHeight(m.height - 10)
 		return m, nil
@@ -218,10 +521,20 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			m.state = stateBrowsing
 			return m, nil
 		}
-		m.subjects = msg.subjects
-		m.filteredSubjects = msg.subjects
+		m.handleSubjectsLoaded(msg)
 		m.state = stateBrowsing
-		m.statusMsg = fmt.Sprintf("Loaded %d subjects", len(m.subjects))
+		return m, tea.Batch(m.applyRestoredSession(), m.loadLinks(m.allSubjects()))
+
+	case linksLoadedMsg:
+		// Linking state is informational; registries that refuse the mode
+		// or exporter APIs just show no linked subjects
+		if msg.err == nil {
+			m.links = msg.links
+		}
+		return m, nil
+
+	case subjectModeMsg:
+		m.handleSubjectMode(msg)
 		return m, nil
 
 	case schemaLoadedMsg:
@@ -230,14 +543,37 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 		m.rawSchema = msg.schema.Schema
+		m.schemaType = msg.schema.SchemaType
+		m.localSchema = localSchemaFile{}
+		if d, ok := deprecation.FromMetadata(msg.schema); ok {
+			m.registryDeprecations[msg.schema.Subject] = d
+		} else {
+			delete(m.registryDeprecations, msg.schema.Subject)
+		}
 		m.schemaID = msg.schema.ID
+		m.schemaVersion = msg.schema.Version
+		m.ruleSet = msg.schema.RuleSet
+		m.masker = mask.New(m.cfg.Mask, msg.schema.Schema)
+		m.fanoutTopics = nil
 		m.currentSchema = registry.PrettyPrintSchema(msg.schema.Schema)
 		m.viewer.SetContent(m.currentSchema)
 		m.viewer.GotoTop()
 		m.state = stateViewing
 		m.focusedPane = viewerPane
 		m.statusMsg = fmt.Sprintf("[VIEW] %s (v%d)", msg.schema.Subject, msg.schema.Version)
-		return m, nil
+		m.protocol = nil
+		if avro.IsProtocol(msg.schema.Schema) {
+			if protocol, err := avro.ParseProtocol(msg.schema.Schema); err == nil {
+				m.protocol = protocol
+				m.statusMsg += fmt.Sprintf("  |  Avro protocol with %d messages, P to browse", len(protocol.Messages))
+			}
+		}
+		if m.restoreConsumer {
+			m.restoreConsumer = false
+			model, cmd := m.enterConsumerMode()
+			return model, tea.Batch(cmd, m.openLinkedMessage(), m.loadSubjectMode(msg.schema.Subject))
+		}
+		return m, m.loadSubjectMode(msg.schema.Subject)
 
 	case messageSentMsg:
 		if msg.err != nil {
@@ -247,23 +583,41 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 		} else {
 			m.state = stateViewing
 			m.editor.Blur()
-			m.statusMsg = fmt.Sprintf("SUCCESS: Message produced to topic '%s'", msg.topic)
+			m.statusMsg = fmt.Sprintf("SUCCESS: Message produced to topic '%s' (ack in %s)", msg.topic, formatLatency(msg.ack))
+			if msg.idempotencyKey != "" {
+				m.statusMsg += " with idempotency key " + msg.idempotencyKey
+			}
 			m.copyNotify = fmt.Sprintf("Message produced to '%s'!", msg.topic)
+			return m, m.fireHook(hooks.MessageProduced, m.producedHookData(msg.topic, msg.key, msg.payload))
 		}
 		return m, nil
 
+	case fanoutSentMsg:
+		return m, m.handleFanoutSent(msg)
+
+	case hookFailedMsg:
+		m.handleHookFailed(msg)
+		return m, nil
+
 	case externalEditorMsg:
 		if msg.err != nil {
 			m.err = msg.err
 			m.state = stateViewing
 		} else {
 			m.editor.SetValue(msg.content)
-			topic := config.SubjectToTopic(m.selectedSubject)
+			topic := m.topic()
 			m.state = stateSendMode
 			m.statusMsg = fmt.Sprintf("[SEND MODE] Target: %s  |  Ctrl+S to send, Esc to cancel", topic)
+			return m, m.loadKeySchema(topic)
 		}
 		return m, nil
 
+	case backfillStartedMsg:
+		return m, m.handleBackfillStarted(msg)
+
+	case backfillChunkMsg:
+		return m, m.handleBackfillChunk(msg)
+
 	case messagesLoadedMsg:
 		m.isLoadingMessages = false
 		if msg.err != nil {
@@ -280,9 +634,114 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 
 		// Success - show what we fetched
 		m.consumedMessages = msg.messages
+		m.decodedMessages = msg.decoded
+		m.consumerLag = msg.lag
+		m.throughput.add(msg.decoded)
 		m.currentMsgIdx = 0
+		m.saveBookmark()
 		m.debugMsg = fmt.Sprintf("Fetched %d messages", len(msg.messages))
 		m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Showing 1/%d", len(msg.messages))
+		return m, m.redecodeIfStale(msg.decoded)
+
+	case copyAsMsg:
+		m.handleCopyAsResult(msg)
+		return m, nil
+
+	case coverageLoadedMsg:
+		m.statusMsg = fmt.Sprintf("Doc coverage for %d subjects", len(msg.results))
+		m.openReport("Doc Coverage", report.FormatCoverage(msg.results, true))
+		return m, nil
+
+	case topicConfigsLoadedMsg:
+		m.handleTopicConfigsLoaded(msg)
+		return m, nil
+
+	case topicConfigSetMsg:
+		return m, m.handleTopicConfigSet(msg)
+
+	case compatibilityLoadedMsg:
+		m.handleCompatibilityLoaded(msg)
+		return m, nil
+
+	case compatibilitySetMsg:
+		return m, m.handleCompatibilitySet(msg)
+
+	case profileConnectedMsg:
+		return m.handleProfileConnected(msg)
+
+	case fieldUsageMsg:
+		m.handleFieldUsage(msg)
+		return m, nil
+
+	case qualityCheckedMsg:
+		m.handleQualityChecked(msg)
+		return m, nil
+
+	case topicsLoadedMsg:
+		m.handleTopicsLoaded(msg)
+		return m, nil
+
+	case clusterLoadedMsg:
+		m.handleClusterLoaded(msg)
+		return m, nil
+
+	case replyReceivedMsg:
+		return m, m.handleReplyReceived(msg)
+
+	case schemaMappedMsg:
+		m.handleSchemaMapped(msg)
+		return m, nil
+
+	case versionsListedMsg:
+		m.handleVersionsListed(msg)
+		return m, nil
+
+	case versionDiffMsg:
+		m.handleVersionDiff(msg)
+		return m, nil
+
+	case subjectDeletedMsg:
+		return m, m.handleSubjectDeleted(msg)
+
+	case readerVersionsMsg:
+		m.handleReaderVersions(msg)
+		return m, nil
+
+	case readerSchemaMsg:
+		return m, m.handleReaderSchema(msg)
+
+	case redecodedMsg:
+		m.handleRedecoded(msg)
+		return m, nil
+
+	case schemaRegisteredMsg:
+		return m, m.handleSchemaRegistered(msg)
+
+	case localSchemaMsg:
+		return m, m.handleLocalSchema(msg)
+
+	case tailedMsg:
+		return m, m.handleTailed(msg)
+
+	case registryThrottledMsg:
+		return m, m.handleRegistryThrottled(msg)
+
+	case throttleTickMsg:
+		return m, m.handleThrottleTick()
+
+	case keySchemaLoadedMsg:
+		m.handleKeySchemaLoaded(msg)
+		return m, nil
+
+	case writeProbedMsg:
+		m.handleWriteProbed(msg)
+		return m, nil
+
+	case localSchemaTickMsg:
+		return m, m.handleLocalSchemaTick(msg)
+
+	case contractCheckedMsg:
+		m.handleContractChecked(msg)
 		return m, nil
 
 	case tickMsg:
@@ -312,6 +771,74 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m.handleLoadingEvent(msg)
 		case stateConsumerMode:
 			return m.handleConsumerMode(msg)
+		case stateReport:
+			return m.handleReportMode(msg)
+		case stateCopyAs:
+			return m.handleCopyAs(msg)
+		case stateEditingDeprecation:
+			return m.handleEditingDeprecation(msg)
+		case stateContractPrompt:
+			return m.handleContractPrompt(msg)
+		case stateExportingCSV:
+			return m.handleExportingCSV(msg)
+		case stateEditingColumns:
+			return m.handleEditingColumns(msg)
+		case stateBackfillForm:
+			return m.handleBackfillForm(msg)
+		case statePickingPayloadFile:
+			return m.handlePickingPayloadFile(msg)
+		case statePickingFanout:
+			return m.handleFanoutPicker(msg)
+		case statePatchTool:
+			return m.handlePatchTool(msg)
+		case stateProtocolBrowser:
+			return m.handleProtocolBrowser(msg)
+		case stateMappingPrompt:
+			return m.handleMappingPrompt(msg)
+		case stateVersionPicker:
+			return m.handleVersionPicker(msg)
+		case stateEditingSchema:
+			return m.handleSchemaEditor(msg)
+		case stateLocalSchemaPrompt:
+			return m.handleLocalSchemaPrompt(msg)
+		case stateRenameFieldPrompt:
+			return m.handleRenameFieldPrompt(msg)
+		case stateSchemaWizard:
+			return m.handleSchemaWizard(msg)
+		case stateSavingScaffold:
+			return m.handleSavingScaffold(msg)
+		case stateSnippetPicker:
+			return m.handleSnippetPicker(msg)
+		case stateSavingSnippet:
+			return m.handleSavingSnippet(msg)
+		case stateTopicPicker:
+			return m.handleTopicPicker(msg)
+		case stateTopicConfig:
+			return m.handleTopicConfig(msg)
+		case stateDeletingSubject:
+			return m.handleDeleteSubject(msg)
+		case stateReaderPicker:
+			return m.handleReaderPicker(msg)
+		case stateAnnotating:
+			return m.handleAnnotating(msg)
+		case stateCompatibility:
+			return m.handleCompatibility(msg)
+		case stateProfileSwitcher:
+			return m.handleProfileSwitcher(msg)
+		case stateUsagePrompt:
+			return m.handleUsagePrompt(msg)
+		case stateQualityPrompt:
+			return m.handleQualityPrompt(msg)
+		case stateSavingPayloadFile:
+			return m.handleSavingPayloadFile(msg)
+		}
+
+		// Visual selection and marks consume the following keys, so check
+		// them before global bindings
+		if m.focusedPane == viewerPane && m.state == stateViewing {
+			if m.handleVisual(msg.String()) || m.handleMark(msg.String()) {
+				return m, nil
+			}
 		}
 
 		// Global keybindings
@@ -343,6 +870,116 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			}
 			return m, nil
 
+		case "D":
+			// Doc coverage across the filtered subjects
+			return m, m.startCoverage()
+
+		case "P":
+			if m.state == stateBrowsing || m.state == stateViewing {
+				m.openProtocolBrowser()
+			}
+			return m, nil
+
+		case "M":
+			m.openReport("Metrics", renderMetrics(metrics.Snapshot()))
+			return m, nil
+
+		case "O":
+			if (m.state == stateBrowsing || m.state == stateViewing) && m.selectedSubject != "" {
+				return m, m.startTopicPicker()
+			}
+			return m, nil
+
+		case "I":
+			if (m.state == stateBrowsing || m.state == stateViewing) && m.selectedSubject != "" && m.needTopic() {
+				return m, m.startTopicConfig()
+			}
+			return m, nil
+
+		case "K":
+			if m.state == stateBrowsing || m.state == stateViewing {
+				return m, m.startClusterInfo()
+			}
+			return m, nil
+
+		case "ctrl+p":
+			if m.state == stateBrowsing || m.state == stateViewing {
+				m.enterProfileSwitcher()
+			}
+			return m, nil
+
+		case "C":
+			if (m.state == stateBrowsing || m.state == stateViewing) && m.localSchema.path == "" {
+				return m, m.startCompatibility()
+			}
+			return m, nil
+
+		case "T":
+			if m.state == stateViewing && m.rawSchema != "" && m.protocol == nil {
+				m.enterMappingPrompt()
+			}
+			return m, nil
+
+		case "d":
+			if m.state == stateViewing && m.rawSchema != "" && m.protocol == nil {
+				return m, m.startVersionDiff()
+			}
+			return m, nil
+
+		case "A":
+			if m.state == stateBrowsing || m.state == stateViewing {
+				m.toggleProjectScope()
+			}
+			return m, nil
+
+		case "L":
+			if m.state == stateBrowsing || m.state == stateViewing {
+				m.enterLocalSchemaPrompt()
+			}
+			return m, nil
+
+		case "N":
+			if m.state == stateBrowsing || m.state == stateViewing {
+				m.enterSchemaWizard()
+			}
+			return m, nil
+
+		case "R":
+			if m.state == stateViewing && m.rawSchema != "" && m.protocol == nil {
+				return m, m.enterSchemaEditor()
+			}
+			return m, nil
+
+		case "S":
+			if m.state == stateViewing && m.rawSchema != "" && m.protocol == nil {
+				m.enterUsagePrompt()
+			}
+			return m, nil
+
+		case "Q":
+			if m.state == stateViewing && m.rawSchema != "" && m.protocol == nil {
+				m.enterQualityPrompt()
+			}
+			return m, nil
+
+		case "X":
+			if m.state == stateViewing && m.rawSchema != "" && m.localSchema.path == "" {
+				m.enterDeleteSubject()
+			}
+			return m, nil
+
+		case "!":
+			if m.state == stateViewing && m.currentSchema != "" {
+				m.enterDeprecationEditor()
+			}
+			return m, nil
+
+		case "Y":
+			if m.state == stateViewing && m.currentSchema != "" {
+				m.enterCopyAs()
+			}
+			return m, nil
+
 		case "e", "s":
 			if m.state == stateViewing && m.currentSchema != "" {
 				return m.enterSendMode()
@@ -350,7 +987,7 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 			return m, nil
 
 		case "E":
-			if m.state == stateViewing && m.currentSchema != "" {
+			if m.state == stateViewing && m.currentSchema != "" && m.needTopic() {
 				m.state = stateSendMode
 				m.statusMsg = "Opening external editor..."
 				return m, m.openExternalEditor()
@@ -362,6 +999,18 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 				return m.enterConsumerMode()
 			}
 			return m, nil
+
+		case "<":
+			m.resizeList(-listPercentStep)
+			return m, nil
+
+		case ">":
+			m.resizeList(listPercentStep)
+			return m, nil
+		}
+
+		if m.handleMotion(msg.String()) {
+			return m, nil
 		}
 
 		if m.focusedPane == listPane {
@@ -374,23 +1023,54 @@ func (m Model) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
 	return m, tea.Batch(cmds...)
 }
 
+// generateTemplate builds a payload template for the current schema using
+// the configured template options
+func (m Model) generateTemplate() (string, error) {
+	if m.isJSONSchema() {
+		return jsonschema.Template(m.rawSchema)
+	}
+	return avro.GenerateTemplateWithOptions(m.rawSchema, m.templateOptions())
+}
+
+// templateOptions returns the profile's template settings
+func (m Model) templateOptions() avro.TemplateOptions {
+	return avro.TemplateOptions{
+		PopulateCollections: m.cfg.Template.PopulateCollections,
+		MaxDepth:            m.cfg.Template.MaxDepth,
+		PreferNull:          m.cfg.Template.PreferNull,
+		MaxRecursion:        m.cfg.Template.MaxRecursion,
+		OmitDefaults:        m.cfg.Template.OmitDefaults,
+	}
+}
+
 func (m Model) enterSendMode() (tea.Model, tea.Cmd) {
+	if m.protocol != nil {
+		m.err = fmt.Errorf("%s holds an Avro protocol, not a schema; press P to browse its messages", m.selectedSubject)
+		return m, nil
+	}
+	if !m.needTopic() {
+		return m, nil
+	}
+
 	// Generate template from schema
-	template, err := avro.GenerateTemplate(m.rawSchema)
+	template, err := m.generateTemplate()
 	if err != nil {
 		m.err = fmt.Errorf("generating template: %w", err)
 		return m, nil
 	}
 
-	topic := config.SubjectToTopic(m.selectedSubject)
+	topic := m.topic()
 	m.editor.SetValue(template)
 	m.editor.Focus()
+	m.composer, m.formMode = nil, false
 	m.keyInput.SetValue("") // Clear key field
 	m.keyInput.Blur()
 	m.sendKeyFocused = false // Focus starts on message
 	m.state = stateSendMode
-	m.statusMsg = fmt.Sprintf("[SEND MODE] Target: %s  |  Ctrl+S send, Ctrl+N save, Ctrl+O load, Tab key, Esc cancel", topic)
-	return m, textarea.Blink
+	m.statusMsg = fmt.Sprintf("[SEND MODE] Target: %s  |  Ctrl+S send, Ctrl+N save, Ctrl+O load, Alt+F form, Tab key, Esc cancel", topic)
+	cmd := tea.Batch(textarea.Blink, m.loadKeySchema(topic), m.probeWrite(topic))
+	m.showWriteProbe(topic)
+	return m, cmd
 }
 
 func (m Model) handleSendMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
@@ -430,9 +1110,18 @@ func (m Model) handleSendMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 	}
 
+	// The form takes the keys that edit it
+	if m.formMode && m.handleComposer(msg) {
+		return m, nil
+	}
+
 	// Key field is not focused - handle global keybindings and editor input
 	switch key {
 	case "esc":
+		if m.editorSelecting {
+			m.toggleEditorSelection()
+			return m, nil
+		}
 		// Cancel, return to view mode
 		m.editor.Blur()
 		m.state = stateViewing
@@ -444,26 +1133,143 @@ func (m Model) handleSendMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.lastPayload = m.editor.Value()
 		// Validate and send
 		m.state = stateSending
+		if len(m.fanoutTopics) > 0 {
+			m.statusMsg = fmt.Sprintf("[SENDING...] %d topics", len(m.fanoutTopics))
+			return m, m.sendFanout()
+		}
 		m.statusMsg = "[SENDING...] " + m.selectedSubject
 		return m, m.sendMessage()
 
+	case "alt+t":
+		// Pick several destination topics
+		m.fanoutPicker = NewFanoutPicker(m.subjectTopics(), m.topic(), m.fanoutTopics)
+		m.state = statePickingFanout
+		return m, nil
+
+	case "alt+s":
+		// Send to a request/reply service and wait for its reply
+		topic := m.topic()
+		rr, ok := m.requestReplyConfig(topic)
+		if !ok {
+			m.err = fmt.Errorf("no reply topic configured for %s (see request_reply in the config file)", topic)
+			return m, nil
+		}
+		m.lastPayload = m.editor.Value()
+		m.state = stateSending
+		m.statusMsg = fmt.Sprintf("[AWAITING REPLY] %s → %s", topic, rr.ReplyTopic)
+		return m, m.sendAndAwaitReply(rr)
+
 	case "ctrl+n":
 		// Save current message
-		topic := config.SubjectToTopic(m.selectedSubject)
-		m.eventSaver = NewEventSaver(topic, m.keyInput.Value(), m.schemaID, m.editor.Value())
+		topic := m.topic()
+		m.eventSaver = NewEventSaver(topic, m.keyInput.Value(), m.schemaID, m.editor.Value(), m.payloadRedactor())
 		m.state = stateSavingEvent
 		m.statusMsg = "[SAVE EVENT]"
 		return m, nil
 
 	case "ctrl+o":
 		// Load saved message
-		topic := config.SubjectToTopic(m.selectedSubject)
-		m.eventLoader = NewEventLoader(topic)
+		topic := m.topic()
+		m.eventLoader = NewEventLoader(topic, m.cfg.Profile)
 		m.state = stateLoadingEvent
 		m.statusMsg = "[LOAD EVENT]"
 		return m, nil
 
+	case "alt+o":
+		// Load a payload from any file
+		m.openPayloadFile()
+		return m, nil
+
+	case "alt+w":
+		// Save the payload to a file
+		m.savePayloadFile()
+		return m, nil
+
+	case "alt+p":
+		// Apply a merge patch or JSON Patch with a preview
+		m.patchTool = NewPatchTool(m.editor.Value(), m.width, m.height)
+		m.state = statePatchTool
+		return m, nil
+
+	case "alt+v":
+		m.toggleEditorSelection()
+		return m, nil
+
+	case "alt+f":
+		// Edit the payload field by field
+		m.toggleComposer()
+		return m, nil
+
+	case "alt+|":
+		// Show the schema beside the payload
+		m.toggleSplitView()
+		return m, nil
+
+	case "alt+i":
+		// Fresh idempotency key per send, for replaying saved messages
+		m.toggleFreshIDs()
+		return m, nil
+
+	case "alt+m":
+		// Replace the payload with the minimal one: only fields without defaults
+		if m.isJSONSchema() {
+			m.err = fmt.Errorf("minimal payloads are only available for Avro schemas")
+			return m, nil
+		}
+		opts := m.templateOptions()
+		opts.OmitDefaults = true
+		template, err := avro.GenerateTemplateWithOptions(m.rawSchema, opts)
+		if err != nil {
+			m.err = fmt.Errorf("generating template: %w", err)
+			return m, nil
+		}
+		m.editor.SetValue(template)
+		m.statusMsg = "[SEND MODE] Minimal payload: fields with defaults are filled in when encoding"
+		return m, nil
+
+	case "alt+r":
+		// Replace the payload with plausible random data
+		if m.isJSONSchema() {
+			m.err = fmt.Errorf("random payloads are only available for Avro schemas")
+			return m, nil
+		}
+		// The subject's generation config shapes the values, as with template --random
+		constraints, err := avro.LoadConstraints(avro.GetConstraintsPath(m.selectedSubject))
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		random, err := avro.NewRandomGenerator(m.rawSchema, constraints, rand.New(rand.NewSource(time.Now().UnixNano())))
+		if err != nil {
+			m.err = fmt.Errorf("generating random payload: %w", err)
+			return m, nil
+		}
+		payload, err := random.Generate()
+		if err != nil {
+			m.err = fmt.Errorf("generating random payload: %w", err)
+			return m, nil
+		}
+		m.editor.SetValue(payload)
+		m.statusMsg = "[SEND MODE] Random payload: Alt+R for another"
+		return m, nil
+
+	case "ctrl+g":
+		// Review the payload against a fresh template
+		m.diffAgainstTemplate()
+		return m, nil
+
+	case "ctrl+r":
+		// Check a consumer's reader schema can read the payload
+		m.contractPrompt = NewContractPrompt(m.selectedSubject, m.cfg.Contracts, m.lastContract[m.selectedSubject])
+		m.state = stateContractPrompt
+		m.statusMsg = "[CONTRACT TEST]"
+		return m, nil
+
 	case "y":
+		if m.editorSelecting {
+			m.copyEditorSelection()
+			return m, nil
+		}
 		// Copy the message content
 		if err := clipboard.WriteAll(m.editor.Value()); err != nil {
 			m.err = fmt.Errorf("failed to copy: %w", err)
@@ -472,6 +1278,11 @@ func (m Model) handleSendMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "ctrl+v":
+		// Paste the clipboard at the cursor, bypassing bracketed paste
+		m.pasteClipboard()
+		return m, nil
+
 	case "tab":
 		// Switch from message to key
 		m.editor.Blur()
@@ -490,6 +1301,9 @@ func (m Model) handleSendMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		// Pass other keys to the message editor
 		var cmd tea.Cmd
 		m.editor, cmd = m.editor.Update(msg)
+		if m.editorSelecting {
+			m.updateEditorSelectionStatus()
+		}
 		return m, cmd
 	}
 }
@@ -502,6 +1316,9 @@ func (m *Model) handleSavingEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	if m.eventSaver.quit {
 		if m.eventSaver.Saved() {
 			m.statusMsg = fmt.Sprintf("[SEND MODE] Saved: %s", m.eventSaver.FilePath())
+			if m.eventSaver.Redacted() {
+				m.statusMsg += " (redacted)"
+			}
 		}
 		m.state = stateSendMode
 	}
@@ -520,6 +1337,15 @@ func (m *Model) handleLoadingEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			m.keyInput.SetValue(event.Key)
 			m.editor.SetValue(event.Payload)
 			m.statusMsg = fmt.Sprintf("[SEND MODE] Loaded: %s", event.Name)
+			if overlay := m.eventLoader.Overlay(); overlay != "" {
+				m.statusMsg += fmt.Sprintf(" with %s overlay", m.cfg.Profile)
+			}
+			// A replayed event needs a new idempotency key to get through
+			// deduplication
+			if _, ok := m.cfg.IdempotencyFor(event.Topic); ok {
+				m.freshIDs = true
+				m.statusMsg += "  |  fresh idempotency keys on (Alt+I)"
+			}
 		}
 		m.state = stateSendMode
 	}
@@ -528,18 +1354,29 @@ func (m *Model) handleLoadingEvent(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m *Model) enterConsumerMode() (tea.Model, tea.Cmd) {
-	topic := config.SubjectToTopic(m.selectedSubject)
+	if !m.needTopic() {
+		return m, nil
+	}
+	topic := m.topic()
 
 	// Close any existing consumer first
-	if m.consumer != nil {
-		m.consumer.Close()
-		m.consumer = nil
-	}
+	m.setConsumer(nil)
 
 	// Clear old messages
 	m.consumedMessages = []kafka.Message{}
 	m.currentMsgIdx = 0
+	m.consumerLag = -1
 	m.debugMsg = ""
+	m.throughput = throughputStats{}
+	m.decodedMessages = nil
+	m.readerSchema = nil
+	m.wireDecoder = avro.NewWireDecoder(m.client.GetSchemaByID)
+	m.wireDecoder.Transform = m.encryptor.DecryptByID(m.selectedSubject)
+	m.tableSortCol = -1
+	m.pinned = nil
+	m.backfill = nil
+	m.tailing = false
+	m.tailFetching = false
 
 	// Create new consumer
 	consumer, err := kafka.NewConsumer(m.cfg, topic)
@@ -549,31 +1386,42 @@ func (m *Model) enterConsumerMode() (tea.Model, tea.Cmd) {
 		return m, nil
 	}
 
-	m.consumer = consumer
+	m.setConsumer(consumer)
 	m.state = stateConsumerMode
 	m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Topic: %s  |  f fetch, Esc cancel, j/k navigate", topic)
 	m.debugMsg = fmt.Sprintf("Consumer ready | Topic: %s | Press 'f' to fetch messages", topic)
+	if offset, ok := m.bookmarks.Get(m.cfg.Profile, topic, consumer.Partition()); ok {
+		m.debugMsg += fmt.Sprintf(", or 'r' to resume where you left off (offset %d)", offset)
+	}
 	return m, nil
 }
 
 func (m *Model) handleConsumerMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	key := msg.String()
 
+	if m.tableView {
+		if handled, cmd := m.handleTableKey(key); handled {
+			return m, cmd
+		}
+	}
+
 	switch key {
 	case "esc":
 		// Exit consumer mode and close in background
 		// Immediately transition back to viewing mode, consumer closes asynchronously
+		m.saveBookmark()
 		m.state = stateViewing
 		m.statusMsg = fmt.Sprintf("[VIEW] %s", m.selectedSubject)
 		m.consumedMessages = []kafka.Message{}
 		m.currentMsgIdx = 0
 		m.debugMsg = ""
+		m.backfill = nil
+		m.unmasked = false
+		m.tailing = false
+		m.tailFetching = false
 
-		// Close consumer in background (safe because reference is captured in goroutine)
-		if m.consumer != nil {
-			go m.consumer.Close()
-			m.consumer = nil
-		}
+		// Close consumer in background
+		m.setConsumer(nil)
 
 		return m, nil
 
@@ -584,12 +1432,12 @@ func (m *Model) handleConsumerMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 			return m, nil
 		}
 
-		if m.isLoadingMessages {
+		if m.isLoadingMessages || m.backfilling() || m.tailFetching {
 			// Already fetching, ignore
 			return m, nil
 		}
 
-		topic := config.SubjectToTopic(m.selectedSubject)
+		topic := m.topic()
 		m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Fetching from topic: %s...", topic)
 		m.isLoadingMessages = true
 		m.debugMsg = "Fetching messages..."
@@ -611,6 +1459,84 @@ func (m *Model) handleConsumerMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		}
 		return m, nil
 
+	case "p":
+		m.togglePin()
+		return m, nil
+
+	case "F":
+		// Follow new messages as they arrive
+		return m, m.toggleTail()
+
+	case "b":
+		// Backfill a historical offset or time range
+		if m.isLoadingMessages || m.backfilling() || m.tailFetching {
+			return m, nil
+		}
+		partition := 0
+		if m.consumer != nil {
+			partition = m.consumer.Partition()
+		}
+		m.backfillForm = NewBackfillForm(m.topic(), partition)
+		m.state = stateBackfillForm
+		return m, nil
+
+	case "U":
+		// Show or hide masked fields
+		m.toggleUnmask()
+		return m, nil
+
+	case "r":
+		// Resume from the last offset viewed on this topic
+		return m, m.resumeFromBookmark()
+
+	case "D":
+		// Diff the two pinned messages
+		m.diffPinned()
+		return m, nil
+
+	case "t":
+		// Toggle between message details and the column table
+		m.tableView = !m.tableView
+		return m, nil
+
+	case "v":
+		// Pick the schema version messages are read as
+		return m, m.startReaderPicker()
+
+	case "|":
+		// Show the schema beside the message
+		m.toggleSplitView()
+		return m, nil
+
+	case "[", "]":
+		// Move the split view's highlighted field
+		if key == "[" {
+			m.moveSplitField(-1)
+		} else {
+			m.moveSplitField(1)
+		}
+		return m, nil
+
+	case "n":
+		// Attach a note to the current message
+		m.enterAnnotating()
+		return m, nil
+
+	case "B":
+		// Copy the current message, its metadata and note for sharing
+		m.shareMessage()
+		return m, nil
+
+	case "e":
+		// Export the fetched messages to CSV
+		if len(m.consumedMessages) == 0 {
+			m.debugMsg = "Nothing to export. Press 'f' to fetch messages first."
+			return m, nil
+		}
+		m.csvExport = NewCSVExport(m.topic(), len(m.consumedMessages))
+		m.state = stateExportingCSV
+		return m, nil
+
 	case "y":
 		// Copy current message
 		if len(m.consumedMessages) > 0 {
@@ -645,8 +1571,7 @@ func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 		m.state = stateBrowsing
 		m.searchInput.Blur()
 		m.searchInput.SetValue("")
-		m.filteredSubjects = m.subjects
-		m.selectedIndex = 0
+		m.filterSubjects()
 		return m, nil
 	case "enter":
 		m.state = stateBrowsing
@@ -660,32 +1585,8 @@ func (m Model) handleSearchInput(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	}
 }
 
-func (m *Model) filterSubjects() {
-	query := strings.ToLower(m.searchInput.Value())
-	if query == "" {
-		m.filteredSubjects = m.subjects
-	} else {
-		filtered := []string{}
-		for _, s := range m.subjects {
-			if strings.Contains(strings.ToLower(s), query) {
-				filtered = append(filtered, s)
-			}
-		}
-		m.filteredSubjects = filtered
-	}
-	m.selectedIndex = 0
-}
-
 func (m Model) handleListNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 	switch msg.String() {
-	case "up", "k":
-		if m.selectedIndex > 0 {
-			m.selectedIndex--
-		}
-	case "down", "j":
-		if m.selectedIndex < len(m.filteredSubjects)-1 {
-			m.selectedIndex++
-		}
 	case "enter":
 		if len(m.filteredSubjects) > 0 {
 			m.selectedSubject = m.filteredSubjects[m.selectedIndex]
@@ -717,29 +1618,134 @@ func (m Model) handleViewerNavigation(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
 }
 
 func (m Model) View() string {
+	defer metrics.Observe(metrics.UI+" render", time.Now(), nil)
 	if m.width == 0 {
 		return "Loading..."
 	}
 
+	banner := m.renderBanner()
+
 	// Handle event saving/loading overlays
 	if m.state == stateSavingEvent {
-		return m.eventSaver.View()
+		return banner + m.eventSaver.View()
 	}
 	if m.state == stateLoadingEvent {
-		return m.eventLoader.View()
+		return banner + m.eventLoader.View()
+	}
+	if m.state == stateEditingDeprecation {
+		return banner + m.deprecationEditor.View()
+	}
+	if m.state == stateContractPrompt {
+		return banner + m.contractPrompt.View()
+	}
+	if m.state == stateExportingCSV {
+		return banner + m.csvExport.View()
+	}
+	if m.state == stateEditingColumns {
+		return banner + m.columnsPrompt.View()
+	}
+	if m.state == stateBackfillForm {
+		return banner + m.backfillForm.View()
+	}
+	if m.state == statePickingPayloadFile {
+		return banner + m.filePicker.View()
+	}
+	if m.state == stateSavingPayloadFile {
+		return banner + m.payloadFilePrompt.View()
+	}
+	if m.state == statePickingFanout {
+		return banner + m.fanoutPicker.View()
+	}
+	if m.state == statePatchTool {
+		return banner + m.patchTool.View()
+	}
+	if m.state == stateMappingPrompt {
+		return banner + m.mappingPrompt.View()
+	}
+	if m.state == stateVersionPicker {
+		return banner + m.versionPicker.View()
+	}
+	if m.state == stateLocalSchemaPrompt {
+		return banner + m.localSchemaPrompt.View()
+	}
+	if m.state == stateRenameFieldPrompt {
+		return banner + m.renamePrompt.View()
+	}
+	if m.state == stateSchemaWizard {
+		return banner + m.schemaWizard.View()
+	}
+	if m.state == stateSavingScaffold {
+		return banner + m.scaffoldPrompt.View()
+	}
+	if m.state == stateSnippetPicker {
+		return banner + m.snippetPicker.View()
+	}
+	if m.state == stateSavingSnippet {
+		return banner + m.snippetPrompt.View()
+	}
+	if m.state == stateTopicPicker {
+		return banner + m.topicPicker.View()
+	}
+	if m.state == stateTopicConfig {
+		return banner + m.topicConfig.View()
+	}
+	if m.state == stateDeletingSubject {
+		return banner + m.deleteSubject.View()
+	}
+	if m.state == stateReaderPicker {
+		return banner + m.readerPicker.View()
+	}
+	if m.state == stateAnnotating {
+		return banner + m.notePrompt.View()
+	}
+	if m.state == stateCompatibility {
+		return banner + m.compatibility.View()
+	}
+	if m.state == stateProfileSwitcher {
+		return banner + m.profileSwitcher.View()
+	}
+	if m.state == stateUsagePrompt {
+		return banner + m.usagePrompt.View()
+	}
+	if m.state == stateQualityPrompt {
+		return banner + m.qualityPrompt.View()
+	}
+
+	if m.state == stateProtocolBrowser {
+		return banner + m.protocolBrowser.View()
 	}
 
 	// Handle consumer mode
-	leftWidth := m.width / 3
+	leftWidth := m.width * m.listPercent / 100
 	rightWidth := m.width - leftWidth - 4
+	paneHeight := m.height - 4
+	if banner != "" {
+		paneHeight--
+	}
 
 	var left, right string
 	if m.state == stateConsumerMode {
-		left = m.renderConsumerList(leftWidth, m.height-4)
-		right = m.renderConsumerMessage(rightWidth, m.height-4)
+		left = m.renderConsumerList(leftWidth, paneHeight)
+		if m.splitView {
+			left = m.renderSplitSchema(leftWidth, paneHeight)
+		}
+		if m.tableView {
+			right = m.renderMessageTable(rightWidth, paneHeight)
+		} else {
+			right = m.renderConsumerMessage(rightWidth, paneHeight)
+		}
+	} else if m.state == stateReport {
+		left = m.renderList(leftWidth, paneHeight)
+		right = m.renderReport(rightWidth, paneHeight)
+	} else if m.state == stateCopyAs {
+		left = m.renderList(leftWidth, paneHeight)
+		right = m.renderCopyAs(rightWidth, paneHeight)
 	} else {
-		left = m.renderList(leftWidth, m.height-4)
-		right = m.renderViewer(rightWidth, m.height-4)
+		left = m.renderList(leftWidth, paneHeight)
+		if m.splitView && (m.state == stateSendMode || m.state == stateSending) {
+			left = m.renderSplitSchema(leftWidth, paneHeight)
+		}
+		right = m.renderViewer(rightWidth, paneHeight)
 	}
 
 	var leftStyle, rightStyle lipgloss.Style
@@ -748,13 +1754,19 @@ func (m Model) View() string {
 		rightStyle = PaneStyle.Width(rightWidth)
 	} else {
 		leftStyle = PaneStyle.Width(leftWidth)
-		if m.state == stateSendMode {
+		if m.state == stateSendMode || m.state == stateEditingSchema {
 			rightStyle = EditPaneStyle.Width(rightWidth)
 		} else {
 			rightStyle = FocusedPaneStyle.Width(rightWidth)
 		}
 	}
 
+	// Production profiles get a warning-colored border on every pane
+	if m.cfg.Production {
+		leftStyle = leftStyle.BorderForeground(prodColor)
+		rightStyle = rightStyle.BorderForeground(prodColor)
+	}
+
 	main := lipgloss.JoinHorizontal(
 		lipgloss.Top,
 		leftStyle.Render(left),
@@ -764,7 +1776,7 @@ func (m Model) View() string {
 	status := m.renderStatusBar()
 	helpView := m.help.View(Keys)
 
-	return lipgloss.JoinVertical(lipgloss.Left, main, status, HelpStyle.Render(helpView))
+	return banner + lipgloss.JoinVertical(lipgloss.Left, main, status, HelpStyle.Render(helpView))
 }
 
 func (m Model) renderList(width, height int) string {
@@ -772,6 +1784,9 @@ func (m Model) renderList(width, height int) string {
 
 	title := ListTitleStyle.Render("Subjects")
 	b.WriteString(title)
+	if m.projectScope && m.scopedSubjects != nil {
+		b.WriteString(HelpStyle.Render(" (project)"))
+	}
 	b.WriteString("\n\n")
 
 	if m.state == stateSearching {
@@ -783,7 +1798,7 @@ func (m Model) renderList(width, height int) string {
 		b.WriteString(fmt.Sprintf("Filter: %s\n\n", m.searchInput.Value()))
 	}
 
-	if m.err != nil && m.state == stateBrowsing && len(m.subjects) == 0 {
+	if m.err != nil && m.state == stateBrowsing && m.subjectCount() == 0 {
 		b.WriteString(ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err)))
 		return b.String()
 	}
@@ -805,8 +1820,17 @@ func (m Model) renderList(width, height int) string {
 
 	for i := start; i < end; i++ {
 		subject := m.filteredSubjects[i]
-		if len(subject) > width-4 {
-			subject = subject[:width-7] + "..."
+		_, deprecated := m.deprecationFor(subject)
+		readOnly := m.isReadOnly(subject)
+		maxLen := width - 4
+		if deprecated {
+			maxLen -= 2
+		}
+		if readOnly {
+			maxLen -= 3
+		}
+		if len(subject) > maxLen {
+			subject = subject[:maxLen-3] + "..."
 		}
 
 		if i == m.selectedIndex {
@@ -814,22 +1838,38 @@ func (m Model) renderList(width, height int) string {
 		} else {
 			b.WriteString(NormalItemStyle.Render("  " + subject))
 		}
+		if deprecated {
+			b.WriteString(" " + DeprecatedBadgeStyle.Render("⚠"))
+		}
+		if readOnly {
+			b.WriteString(" " + ReadOnlyBadgeStyle.Render("🔒"))
+		}
 		b.WriteString("\n")
 	}
 
 	if len(m.filteredSubjects) == 0 {
 		b.WriteString(HelpStyle.Render("No subjects found"))
+	} else if m.moreSubjects && end == len(m.filteredSubjects) {
+		if m.searchInput.Value() != "" {
+			b.WriteString(HelpStyle.Render(fmt.Sprintf("First %d matches, type more to narrow", len(m.filteredSubjects))))
+		} else {
+			b.WriteString(HelpStyle.Render(fmt.Sprintf("First %d of %d subjects, / to filter", len(m.filteredSubjects), m.subjectCount())))
+		}
 	}
 
 	return b.String()
 }
 
 func (m Model) renderViewer(width, height int) string {
+	if m.state == stateEditingSchema {
+		return m.renderSchemaEditor(width, height)
+	}
+
 	var b strings.Builder
 
 	switch m.state {
 	case stateSendMode:
-		topic := config.SubjectToTopic(m.selectedSubject)
+		topic := m.topic()
 		title := EditTitleStyle.Render("Send Mode")
 		b.WriteString(title)
 		b.WriteString("\n")
@@ -837,7 +1877,7 @@ func (m Model) renderViewer(width, height int) string {
 		b.WriteString(SelectedItemStyle.Render(topicLine))
 		b.WriteString("\n\n")
 	case stateSending:
-		topic := config.SubjectToTopic(m.selectedSubject)
+		topic := m.topic()
 		title := ListTitleStyle.Render("Sending...")
 		b.WriteString(title)
 		b.WriteString("\n")
@@ -850,6 +1890,15 @@ func (m Model) renderViewer(width, height int) string {
 		b.WriteString("\n\n")
 	}
 
+	if m.currentSchema != "" {
+		for _, banner := range []string{m.deprecationBanner(m.selectedSubject), m.linkBanner(m.selectedSubject)} {
+			if banner != "" {
+				b.WriteString(lipgloss.NewStyle().Width(width - 2).Render(banner))
+				b.WriteString("\n\n")
+			}
+		}
+	}
+
 	if m.currentSchema == "" {
 		b.WriteString(HelpStyle.Render("Select a subject to view its schema"))
 		return b.String()
@@ -872,59 +1921,58 @@ func (m Model) renderViewer(width, height int) string {
 		// Render message editor
 		m.editor.SetWidth(width - 2)
 		m.editor.SetHeight(contentHeight)
-		b.WriteString(m.editor.View())
+		if m.formMode {
+			b.WriteString(m.composerView(width-2, contentHeight))
+		} else {
+			b.WriteString(m.editor.View())
+		}
 	} else {
 		m.viewer.Width = width - 2
 		m.viewer.Height = contentHeight
+		if m.visual {
+			m.viewer.SetContent(m.visualContent())
+		}
 		b.WriteString(m.viewer.View())
 	}
 
 	return b.String()
 }
 
-func (m Model) renderStatusBar() string {
-	var status string
-
-	if m.copyNotify != "" {
-		status = SuccessStyle.Render(m.copyNotify)
-	} else if m.err != nil {
-		status = ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err))
-	} else if strings.HasPrefix(m.statusMsg, "SUCCESS:") {
-		status = SuccessStyle.Render(m.statusMsg)
-	} else if m.statusMsg != "" {
-		status = m.statusMsg
-	} else {
-		status = "Ready"
-	}
-
-	// Add Kafka status indicator
-	if m.producer == nil {
-		status += "  " + HelpStyle.Render("[Kafka: not configured]")
-	}
-
-	bar := StatusBarStyle.Width(m.width).Render(status)
-	return bar
-}
-
 func (m Model) renderConsumerList(width, height int) string {
 	var b strings.Builder
 
 	title := ListTitleStyle.Render("Messages")
 	b.WriteString(title)
-	b.WriteString("\n\n")
+	b.WriteString("\n")
+	if m.backfilling() {
+		b.WriteString(m.renderBackfillProgress(width))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
 
 	if len(m.consumedMessages) == 0 {
 		b.WriteString(HelpStyle.Render("Press 'f' to fetch messages"))
 		return b.String()
 	}
 
-	for i := 0; i < len(m.consumedMessages) && i < height-4; i++ {
+	// Scroll to keep the selected message in view, as backfills can load
+	// far more messages than fit
+	visible := max(height-4, 1)
+	start := max(m.currentMsgIdx-visible+1, 0)
+
+	for i := start; i < len(m.consumedMessages) && i < start+visible; i++ {
 		prefix := "  "
 		offset := m.consumedMessages[i].Offset
 		key := m.consumedMessages[i].Key
 		if key == "" {
 			key = "-"
 		}
+		if m.isPinned(offset) {
+			key += " 📌"
+		}
+		if _, ok := m.noteFor(m.consumedMessages[i]); ok {
+			key += " 📝"
+		}
 
 		if i == m.currentMsgIdx {
 			prefix = "> "
@@ -937,8 +1985,8 @@ func (m Model) renderConsumerList(width, height int) string {
 		b.WriteString("\n")
 	}
 
-	if len(m.consumedMessages) > height-4 {
-		b.WriteString(HelpStyle.Render(fmt.Sprintf("... and %d more", len(m.consumedMessages)-(height-4))))
+	if rest := len(m.consumedMessages) - (start + visible); rest > 0 {
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("... and %d more", rest)))
 	}
 
 	return b.String()
@@ -965,10 +2013,27 @@ func (m Model) decodeKey(keyBase64 string) string {
 	return fmt.Sprintf("[binary data] %s", keyBase64)
 }
 
-// decodeAvroMessage decodes a Kafka message that contains Avro data
-// Expects base64-encoded binary data as input
-// Returns formatted JSON or original string if decoding fails
-func (m Model) decodeAvroMessage(payload string) string {
+// decodedValue is a consumed message value decoded for the message view.
+// Decoding looks schemas up and decrypts fields, so it is done when
+// messages are fetched; masking is left to rendering, as it can be toggled.
+type decodedValue struct {
+	json   string                   // The decoded value; empty if it couldn't be decoded
+	note   string                   // Shown above the value
+	text   string                   // Shown instead of the value when json is empty
+	reader *registry.SchemaResponse // The reader version it was decoded for
+}
+
+// decodeValue decodes a consumed message value (base64-encoded binary
+// data): JSON as is, Avro with the schema it was written with, resolved to
+// the reader version if one is chosen
+func (m Model) decodeValue(payload string) decodedValue {
+	value := m.decodeWith(payload)
+	value.reader = m.readerSchema
+	return value
+}
+
+// decodeWith does decodeValue's decoding
+func (m Model) decodeWith(payload string) decodedValue {
 	// First, decode from base64 to get the binary data
 	binaryData, err := base64.StdEncoding.DecodeString(payload)
 	if err != nil {
@@ -976,52 +2041,78 @@ func (m Model) decodeAvroMessage(payload string) string {
 		binaryData = []byte(payload)
 	}
 
-	// Try to parse binary as JSON first (in case it's already JSON)
-	var obj interface{}
-	if json.Unmarshal(binaryData, &obj) == nil {
-		// It's already valid JSON, pretty-print it
-		pretty, err := json.MarshalIndent(obj, "", "  ")
-		if err == nil {
-			return string(pretty)
-		}
+	// Already JSON, or JSON Schema values: JSON behind the wire header
+	if json.Valid(binaryData) {
+		return decodedValue{json: string(binaryData)}
+	}
+	if _, body, ok := avro.SplitWireFormat(binaryData); ok && json.Valid(body) {
+		return decodedValue{json: string(body)}
+	}
+
+	// No schema available, return debug info
+	if m.selectedSubject == "" || m.rawSchema == "" {
+		return decodedValue{text: fmt.Sprintf("[No schema for subject: %s]\n%s", m.selectedSubject, payload)}
 	}
 
-	// If we have a selected subject, try to decode as Avro using that schema
-	if m.selectedSubject != "" && m.rawSchema != "" {
+	// Values normally carry the Schema Registry wire header (magic byte
+	// and schema ID); decode bare Avro too
+	var jsonData string
+	if _, avroPayload, ok := avro.SplitWireFormat(binaryData); ok {
+		// Decode with the schema the message was written with, resolved
+		// to the reader version if one is chosen
+		reader := ""
+		if m.readerSchema != nil {
+			reader = m.readerSchema.Schema
+		}
+		jsonData, err = avro.Decode(reader, binaryData, m.writerSchema)
+		if err != nil {
+			return decodedValue{text: fmt.Sprintf("[ERROR: Avro decode failed: %v]\n[Payload length: %d bytes]\n%s", err, len(avroPayload), payload)}
+		}
+	} else {
 		validator, err := avro.NewValidator(m.rawSchema)
 		if err != nil {
-			return fmt.Sprintf("[ERROR: Schema validation failed: %v]\n%s", err, payload)
+			return decodedValue{text: fmt.Sprintf("[ERROR: Schema validation failed: %v]\n%s", err, payload)}
 		}
-
-		// The binary data includes the Schema Registry wire format (magic byte + schema ID + data)
-		// ALWAYS strip the first 5 bytes if present
-		var avroPayload []byte
-		if len(binaryData) > 5 && binaryData[0] == 0 {
-			// Skip the magic byte and schema ID (5 bytes total)
-			avroPayload = binaryData[5:]
-		} else {
-			avroPayload = binaryData
+		if jsonData, err = validator.Decode(binaryData); err != nil {
+			return decodedValue{text: fmt.Sprintf("[ERROR: Avro decode failed: %v]\n[Payload length: %d bytes]\n%s", err, len(binaryData), payload)}
 		}
+	}
 
-		// Decode the Avro payload
-		jsonData, err := validator.Decode(avroPayload)
-		if err != nil {
-			return fmt.Sprintf("[ERROR: Avro decode failed: %v]\n[Payload length: %d bytes]\n%s", err, len(avroPayload), payload)
-		}
+	// Decrypt encrypted fields where keys are available
+	value := decodedValue{}
+	if jsonData, err = m.decryptFields(jsonData); err != nil {
+		value.note = fmt.Sprintf("[Encrypted fields left as is: %v]\n", err)
+	}
+	value.json = jsonData
+	return value
+}
 
-		// Successfully decoded, format it nicely
-		var obj interface{}
-		if err := json.Unmarshal([]byte(jsonData), &obj); err == nil {
-			pretty, err := json.MarshalIndent(obj, "", "  ")
-			if err == nil {
-				return string(pretty)
-			}
+// formatValue renders a decoded value as indented JSON, with masked fields
+// hidden unless the user has unmasked them
+func (m Model) formatValue(value decodedValue) string {
+	if value.json == "" {
+		return value.text
+	}
+	var obj interface{}
+	if err := json.Unmarshal([]byte(value.json), &obj); err == nil {
+		pretty, err := json.MarshalIndent(m.maskDoc(obj), "", "  ")
+		if err == nil {
+			return value.note + string(pretty)
 		}
-		return jsonData
 	}
+	if m.masker != nil && !m.unmasked {
+		return value.note + "[Value hidden: it could not be parsed to mask its fields]"
+	}
+	return value.note + value.json
+}
 
-	// No schema available, return debug info
-	return fmt.Sprintf("[No schema for subject: %s]\n%s", m.selectedSubject, payload)
+// messageValue returns the i-th consumed message's value as the message
+// view shows it
+func (m Model) messageValue(i int) string {
+	if i >= len(m.decodedMessages) {
+		return ""
+	}
+	return m.formatValue(m.decodedMessages[i].value)
 }
 
 func (m Model) renderConsumerMessage(width, height int) string {
@@ -1031,6 +2122,10 @@ func (m Model) renderConsumerMessage(width, height int) string {
 	title := EditTitleStyle.Render("Message Details")
 	b.WriteString(title)
 	b.WriteString("\n")
+	if stats := m.throughput.summary(); stats != "" {
+		b.WriteString(HelpStyle.Render("⚡ " + stats))
+		b.WriteString("\n")
+	}
 
 	// Display loading spinner if fetching
 	if m.isLoadingMessages {
@@ -1073,7 +2168,24 @@ func (m Model) renderConsumerMessage(width, height int) string {
 	header := fmt.Sprintf("Message %d/%d (Offset: %d, Timestamp: %s)",
 		m.currentMsgIdx+1, len(m.consumedMessages), currentMsg.Offset, currentMsg.Timestamp)
 	content.WriteString(lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("11")).Render(header))
+	if m.masker != nil {
+		if m.unmasked {
+			content.WriteString("  " + lipgloss.NewStyle().Bold(true).Foreground(lipgloss.Color("9")).Render("UNMASKED"))
+		} else {
+			content.WriteString("  " + HelpStyle.Render("masked (U to unmask)"))
+		}
+	}
+	content.WriteString("\n\n")
+	content.WriteString(HelpStyle.Render(m.messageLink(currentMsg)))
 	content.WriteString("\n\n")
+	if note, ok := m.noteFor(currentMsg); ok {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("14")).Render("📝 " + note.Text))
+		content.WriteString("\n\n")
+	}
+	if currentMsg.SerializerError != "" {
+		content.WriteString(lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("Shown as consumed: " + currentMsg.SerializerError))
+		content.WriteString("\n\n")
+	}
 
 	// Key section - decode from base64 (keys are not Avro-encoded)
 	if currentMsg.Key != "" {
@@ -1086,13 +2198,18 @@ func (m Model) renderConsumerMessage(width, height int) string {
 
 	// Value section - decode Avro if possible
 	content.WriteString(lipgloss.NewStyle().Bold(true).Render("Value:"))
+	if label := m.readerLabel(currentMsg.Value); label != "" {
+		content.WriteString("  " + HelpStyle.Render(label+"  (v to change)"))
+	}
 	content.WriteString("\n")
-	valueStr := m.decodeAvroMessage(currentMsg.Value)
+	valueStr := m.messageValue(m.currentMsgIdx)
 	if strings.Contains(valueStr, "ERROR") {
 		// Error message - wrap and color red
 		errorStyle := lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Bold(true)
 		wrappedValue := lipgloss.NewStyle().Width(width - 4).Render(errorStyle.Render(valueStr))
 		content.WriteString(wrappedValue)
+	} else if m.splitView {
+		content.WriteString(m.highlightMessageField(valueStr))
 	} else {
 		content.WriteString(valueStr)
 	}
@@ -1111,8 +2228,10 @@ func (m Model) renderConsumerMessage(width, height int) string {
 // fetchMessagesCmd returns a command that fetches messages asynchronously
 func (m *Model) fetchMessagesCmd() tea.Cmd {
 	consumer := m.consumer // Capture consumer reference
+	decoder := m.wireDecoder
+	decode := m.decodeValue
 
-	return func() tea.Msg {
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
 		if consumer == nil {
 			return messagesLoadedMsg{
 				messages: nil,
@@ -1120,15 +2239,17 @@ func (m *Model) fetchMessagesCmd() tea.Cmd {
 			}
 		}
 
-		ctx, cancel := context.WithTimeout(context.Background(), 5*time.Second)
+		ctx, cancel := context.WithTimeout(ctx, 5*time.Second)
 		defer cancel()
 
 		messages, err := consumer.FetchMessages(ctx, 10)
 		return messagesLoadedMsg{
 			messages: messages,
+			decoded:  decodeMessages(decoder, decode, messages),
+			lag:      consumer.Lag(),
 			err:      err,
 		}
-	}
+	})
 }
 
 // tickCmd returns a command that sends a tick message after 100ms
@@ -1138,4 +2259,3 @@ func (m *Model) tickCmd() tea.Cmd {
 		return tickMsg{}
 	})
 }
-
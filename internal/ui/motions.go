@@ -0,0 +1,169 @@
+package ui
+
+import (
+	"strings"
+)
+
+// maxCount caps numeric prefixes so a stuck key can't overflow
+const maxCount = 99999
+
+// handleMotion processes vim-style count prefixes and motions (j/k, gg/G, { })
+// for the focused pane. Returns false if the key is not a motion, in which case
+// any pending count is discarded.
+func (m *Model) handleMotion(key string) bool {
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.count > 0) {
+		if m.count*10+int(key[0]-'0') <= maxCount {
+			m.count = m.count*10 + int(key[0]-'0')
+		}
+		return true
+	}
+
+	explicit := m.count > 0
+	count := m.count
+	if count == 0 {
+		count = 1
+	}
+	pendingG := m.pendingG
+	m.count = 0
+	m.pendingG = false
+
+	switch key {
+	case "g":
+		if !pendingG {
+			// Wait for the second g, keeping the count for "5gg"
+			m.pendingG = true
+			if explicit {
+				m.count = count
+			}
+			return true
+		}
+		if explicit {
+			m.gotoLine(count - 1)
+		} else {
+			m.gotoLine(0)
+		}
+	case "G":
+		if explicit {
+			m.gotoLine(count - 1)
+		} else {
+			m.gotoLine(-1)
+		}
+	case "j", "down":
+		m.moveLines(count)
+	case "k", "up":
+		m.moveLines(-count)
+	case "}":
+		for i := 0; i < count; i++ {
+			m.moveParagraph(1)
+		}
+	case "{":
+		for i := 0; i < count; i++ {
+			m.moveParagraph(-1)
+		}
+	default:
+		return false
+	}
+	return true
+}
+
+// moveLines moves the list selection or scrolls the viewer by n lines
+func (m *Model) moveLines(n int) {
+	if m.focusedPane == listPane {
+		m.selectIndex(m.selectedIndex + n)
+		return
+	}
+	if n > 0 {
+		m.viewer.ScrollDown(n)
+	} else {
+		m.viewer.ScrollUp(-n)
+	}
+}
+
+// gotoLine jumps to a zero-based line, or the last line when line is negative
+func (m *Model) gotoLine(line int) {
+	if m.focusedPane == listPane {
+		if line < 0 {
+			line = len(m.filteredSubjects) - 1
+		}
+		m.selectIndex(line)
+		return
+	}
+	if line < 0 {
+		m.viewer.GotoBottom()
+		return
+	}
+	m.viewer.SetYOffset(line)
+}
+
+// selectIndex sets the list selection, clamped to the filtered subjects
+func (m *Model) selectIndex(i int) {
+	if i >= len(m.filteredSubjects) {
+		i = len(m.filteredSubjects) - 1
+	}
+	if i < 0 {
+		i = 0
+	}
+	m.selectedIndex = i
+}
+
+// moveParagraph jumps to the next (dir > 0) or previous paragraph boundary.
+// In the list a paragraph is a run of subjects sharing a prefix; in the
+// viewer it is a blank line or the opening brace of a nested object, which
+// in a pretty-printed schema lands on each field definition.
+func (m *Model) moveParagraph(dir int) {
+	if m.focusedPane == listPane {
+		i := m.selectedIndex
+		if i >= len(m.filteredSubjects) {
+			return
+		}
+		group := subjectGroup(m.filteredSubjects[i])
+		if dir > 0 {
+			for i < len(m.filteredSubjects)-1 && subjectGroup(m.filteredSubjects[i]) == group {
+				i++
+			}
+		} else {
+			// Step to the start of the current group, or of the previous one if already there
+			if i > 0 && subjectGroup(m.filteredSubjects[i-1]) != group {
+				i--
+				group = subjectGroup(m.filteredSubjects[i])
+			}
+			for i > 0 && subjectGroup(m.filteredSubjects[i-1]) == group {
+				i--
+			}
+		}
+		m.selectIndex(i)
+		return
+	}
+
+	lines := strings.Split(m.viewerContent(), "\n")
+	for i := m.viewer.YOffset + dir; i >= 0 && i < len(lines); i += dir {
+		if isParagraphBoundary(lines[i]) {
+			m.viewer.SetYOffset(i)
+			return
+		}
+	}
+	if dir > 0 {
+		m.viewer.GotoBottom()
+	} else {
+		m.viewer.GotoTop()
+	}
+}
+
+// viewerContent returns the text currently shown in the viewer pane
+func (m *Model) viewerContent() string {
+	return m.currentSchema
+}
+
+// subjectGroup returns the leading segment of a subject name used to group
+// related subjects for paragraph motions
+func subjectGroup(subject string) string {
+	if i := strings.IndexAny(subject, ".-_"); i > 0 {
+		return subject[:i]
+	}
+	return subject
+}
+
+func isParagraphBoundary(line string) bool {
+	trimmed := strings.TrimSpace(line)
+	return trimmed == "" || trimmed == "{"
+}
@@ -0,0 +1,38 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// maxPasteSize bounds what is pasted into the editor, so a stray copy of a
+// huge log doesn't freeze the textarea
+const maxPasteSize = 1 << 20
+
+// pasteClipboard inserts the system clipboard at the editor's cursor.
+// Terminals deliver bracketed pastes as a stream of key events the textarea
+// can drop or reorder on large JSON, so this reads the clipboard directly.
+func (m *Model) pasteClipboard() {
+	text, err := clipboard.ReadAll()
+	if err != nil {
+		m.err = fmt.Errorf("failed to read clipboard: %w", err)
+		return
+	}
+	if text == "" {
+		m.err = fmt.Errorf("clipboard is empty")
+		return
+	}
+	if len(text) > maxPasteSize {
+		m.err = fmt.Errorf("clipboard holds %d KB, more than the %d KB paste limit", len(text)/1024, maxPasteSize/1024)
+		return
+	}
+
+	text = strings.ReplaceAll(text, "\r\n", "\n")
+	if m.editorSelecting {
+		m.toggleEditorSelection()
+	}
+	m.editor.InsertString(text)
+	m.copyNotify = fmt.Sprintf("Pasted %d lines from clipboard", strings.Count(text, "\n")+1)
+}
@@ -0,0 +1,168 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/diff"
+	"github.com/JimmyyyW/avrocado/internal/jsonpatch"
+)
+
+// PatchToolModel applies a JSON merge patch or JSON Patch, read from the
+// clipboard or a file, to the editor content and previews the changes
+// before they are accepted
+type PatchToolModel struct {
+	current string
+	result  string
+	source  string // Where the patch came from, for the preview title
+	kind    string // "merge patch" or "JSON Patch"
+	picker  pathPicker
+	preview viewport.Model
+	err     string
+	saved   bool
+	quit    bool
+}
+
+// NewPatchTool creates the tool for the current editor content
+func NewPatchTool(current string, width, height int) PatchToolModel {
+	return PatchToolModel{
+		current: current,
+		preview: viewport.New(max(width-4, 20), max(height-12, 5)),
+	}
+}
+
+func (m PatchToolModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m PatchToolModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.picker.active() {
+		if path, ok := m.picker.update(keyMsg); ok {
+			data, err := os.ReadFile(path)
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.apply(string(data), filepath.Base(path))
+		}
+		return m, nil
+	}
+
+	switch keyMsg.String() {
+	case "esc", "q":
+		m.quit = true
+	case "c":
+		patch, err := clipboard.ReadAll()
+		if err != nil {
+			m.err = fmt.Sprintf("failed to read clipboard: %v", err)
+			return m, nil
+		}
+		m.apply(patch, "clipboard")
+	case "f":
+		m.picker.browse("Patch File", openPath, "", ".json")
+	case "enter":
+		if m.result != "" {
+			m.saved = true
+			m.quit = true
+		}
+	default:
+		var cmd tea.Cmd
+		m.preview, cmd = m.preview.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// apply patches the content and renders the preview
+func (m *PatchToolModel) apply(patch, source string) {
+	m.err = ""
+	m.result = ""
+	m.source = source
+
+	var result []byte
+	var err error
+	if jsonpatch.IsJSONPatch([]byte(patch)) {
+		m.kind = "JSON Patch"
+		result, err = jsonpatch.Apply([]byte(m.current), []byte(patch))
+	} else {
+		m.kind = "merge patch"
+		result, err = jsonpatch.MergePatch([]byte(m.current), []byte(patch))
+	}
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.result = string(result)
+
+	changes, err := diff.JSONStrings(m.current, m.result)
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+	m.preview.SetContent(renderChanges(changes, "current", "patched") + "\n\n" + m.result)
+	m.preview.GotoTop()
+}
+
+func (m PatchToolModel) View() string {
+	if m.picker.active() {
+		return m.picker.view()
+	}
+
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render("Apply Patch") + "\n\n"
+	if m.result != "" {
+		s += fmt.Sprintf("Preview of the %s from %s:\n\n", m.kind, m.source)
+		s += m.preview.View() + "\n\n"
+	} else {
+		s += "Apply a JSON merge patch (an object) or a JSON Patch (an array of operations) to the payload.\n\n"
+	}
+	if m.err != "" {
+		s += lipgloss.NewStyle().Foreground(lipgloss.Color("9")).Render("✗ "+m.err) + "\n\n"
+	}
+	if m.result != "" {
+		s += lipgloss.NewStyle().Faint(true).Render("[enter] Accept  [↑/↓] Scroll  [c] Clipboard  [f] File  [esc] Cancel") + "\n"
+	} else {
+		s += lipgloss.NewStyle().Faint(true).Render("[c] From clipboard  [f] From file  [esc] Cancel") + "\n"
+	}
+	return s
+}
+
+// Result returns the patched content
+func (m PatchToolModel) Result() string {
+	return m.result
+}
+
+// Saved returns whether the user accepted the result
+func (m PatchToolModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the tool is closed
+func (m PatchToolModel) Quit() bool {
+	return m.quit
+}
+
+func (m *Model) handlePatchTool(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.patchTool.Update(msg)
+	m.patchTool = newModel.(PatchToolModel)
+	if !m.patchTool.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateSendMode
+	if m.patchTool.Saved() {
+		m.editor.SetValue(m.patchTool.Result())
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Applied %s from %s", m.patchTool.kind, m.patchTool.source)
+	}
+	return m, nil
+}
@@ -0,0 +1,118 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/events"
+)
+
+// openPayloadFile shows a file picker for loading a payload from anywhere
+// on disk, starting where the last payload file was
+func (m *Model) openPayloadFile() {
+	m.filePicker = NewFilePicker("Load Payload From File", m.payloadFileDir, openPath, m.height-10)
+	m.state = statePickingPayloadFile
+}
+
+func (m *Model) handlePickingPayloadFile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	var cmd tea.Cmd
+	m.filePicker, cmd = m.filePicker.Update(msg)
+	if !m.filePicker.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateSendMode
+	if path := m.filePicker.Selected(); path != "" {
+		m.loadPayloadFile(path)
+	}
+	return m, nil
+}
+
+// loadPayloadFile replaces the editor buffer with a file's content. Events
+// saved by avrocado load their payload and key.
+func (m *Model) loadPayloadFile(path string) {
+	info, err := os.Stat(path)
+	if err != nil {
+		m.err = err
+		return
+	}
+	if info.Size() > maxPasteSize {
+		m.err = fmt.Errorf("%s is %d KB, more than the %d KB payload limit", filepath.Base(path), info.Size()/1024, maxPasteSize/1024)
+		return
+	}
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.payloadFileDir = filepath.Dir(path)
+
+	var event events.Event
+	if json.Unmarshal(data, &event) == nil && event.Payload != "" && event.Topic != "" {
+		overlay, err := events.ApplyOverlay(&event, path, m.cfg.Profile)
+		if err != nil {
+			m.err = err
+			return
+		}
+		m.editor.SetValue(event.Payload)
+		m.keyInput.SetValue(event.Key)
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Loaded event %s", path)
+		if overlay != "" {
+			m.statusMsg += fmt.Sprintf(" with %s overlay", m.cfg.Profile)
+		}
+		return
+	}
+
+	m.editor.SetValue(string(data))
+	if json.Valid(data) {
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Loaded %s", path)
+	} else {
+		m.statusMsg = fmt.Sprintf("[SEND MODE] Loaded %s (not valid JSON)", path)
+	}
+}
+
+// savePayloadFile asks where to write the editor buffer
+func (m *Model) savePayloadFile() {
+	dir := m.payloadFileDir
+	if dir == "" {
+		dir = "."
+	}
+	path := filepath.Join(dir, m.topic()+".json")
+	m.payloadOverwrite = ""
+	m.payloadFilePrompt = NewPathPrompt("Save Payload To File", "Writes the payload as it is in the editor", path)
+	m.state = stateSavingPayloadFile
+}
+
+func (m *Model) handleSavingPayloadFile(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.payloadFilePrompt.Update(msg)
+	m.payloadFilePrompt = newModel.(TextPromptModel)
+	if !m.payloadFilePrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateSendMode
+	path := m.payloadFilePrompt.Value()
+	if !m.payloadFilePrompt.Saved() || path == "" {
+		return m, nil
+	}
+
+	// Ask again before replacing an existing file
+	if _, err := os.Stat(path); err == nil && path != m.payloadOverwrite {
+		m.payloadOverwrite = path
+		m.payloadFilePrompt = NewPathPrompt("Save Payload To File", path+" exists: press enter again to overwrite it", path)
+		m.state = stateSavingPayloadFile
+		return m, nil
+	}
+
+	if err := os.WriteFile(path, []byte(m.editor.Value()), 0644); err != nil {
+		m.err = fmt.Errorf("saving payload: %w", err)
+		return m, nil
+	}
+	m.payloadFileDir = filepath.Dir(path)
+	m.statusMsg = fmt.Sprintf("[SEND MODE] Saved payload to %s", path)
+	return m, nil
+}
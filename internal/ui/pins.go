@@ -0,0 +1,68 @@
+package ui
+
+import (
+	"fmt"
+
+	"github.com/JimmyyyW/avrocado/internal/diff"
+)
+
+// maxPins is how many consumed messages can be pinned for comparison
+const maxPins = 2
+
+// togglePin pins or unpins the current message. Pins survive further
+// fetches; pinning a third message replaces the oldest pin.
+func (m *Model) togglePin() {
+	if m.currentMsgIdx >= len(m.decodedMessages) {
+		return
+	}
+	current := m.decodedMessages[m.currentMsgIdx]
+
+	for i, p := range m.pinned {
+		if p.msg.Offset == current.msg.Offset {
+			m.pinned = append(m.pinned[:i], m.pinned[i+1:]...)
+			m.copyNotify = fmt.Sprintf("Unpinned offset %d", current.msg.Offset)
+			return
+		}
+	}
+
+	if len(m.pinned) == maxPins {
+		m.pinned = m.pinned[1:]
+	}
+	m.pinned = append(m.pinned, current)
+	if len(m.pinned) == maxPins {
+		m.copyNotify = fmt.Sprintf("Pinned offset %d - press D to compare with offset %d", current.msg.Offset, m.pinned[0].msg.Offset)
+	} else {
+		m.copyNotify = fmt.Sprintf("Pinned offset %d - pin another message to compare", current.msg.Offset)
+	}
+}
+
+// isPinned reports whether the message at offset is pinned
+func (m Model) isPinned(offset int64) bool {
+	for _, p := range m.pinned {
+		if p.msg.Offset == offset {
+			return true
+		}
+	}
+	return false
+}
+
+// diffPinned compares the decoded values of the two pinned messages
+func (m *Model) diffPinned() {
+	if len(m.pinned) < maxPins {
+		m.err = fmt.Errorf("pin two messages with p to compare them")
+		return
+	}
+
+	older, newer := m.pinned[0], m.pinned[1]
+	if older.msg.Offset > newer.msg.Offset {
+		older, newer = newer, older
+	}
+	if older.doc == nil || newer.doc == nil {
+		m.err = fmt.Errorf("pinned messages could not both be decoded")
+		return
+	}
+
+	changes := diff.JSON(m.maskDoc(older.doc), m.maskDoc(newer.doc))
+	title := fmt.Sprintf("Offset %d vs %d", older.msg.Offset, newer.msg.Offset)
+	m.openReport(title, renderChanges(changes, fmt.Sprintf("offset %d", older.msg.Offset), fmt.Sprintf("offset %d", newer.msg.Offset)))
+}
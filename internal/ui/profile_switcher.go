@@ -0,0 +1,228 @@
+package ui
+
+import (
+	"fmt"
+	"sort"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+	"github.com/JimmyyyW/avrocado/internal/tunnel"
+)
+
+// profileConnectedMsg carries the connections to a profile being switched
+// to
+type profileConnectedMsg struct {
+	cfg         *config.Config
+	client      *registry.Client
+	producer    *kafka.Producer
+	producerErr error
+	tunnel      *tunnel.Tunnel
+	err         error
+}
+
+// ProfileSwitcherModel picks the profile to reconnect with
+type ProfileSwitcherModel struct {
+	configFile *config.ConfigFile
+	profiles   []string
+	current    string
+	cursor     int
+	chosen     bool
+	quit       bool
+}
+
+// NewProfileSwitcher lists the config file's profiles, the default first
+func NewProfileSwitcher(configFile *config.ConfigFile, current string) ProfileSwitcherModel {
+	m := ProfileSwitcherModel{configFile: configFile, current: current}
+	for name := range configFile.Configurations {
+		m.profiles = append(m.profiles, name)
+	}
+	sort.Slice(m.profiles, func(i, j int) bool {
+		if (m.profiles[i] == configFile.Default) != (m.profiles[j] == configFile.Default) {
+			return m.profiles[i] == configFile.Default
+		}
+		return m.profiles[i] < m.profiles[j]
+	})
+	for i, name := range m.profiles {
+		if name == current {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+func (m ProfileSwitcherModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ProfileSwitcherModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "esc", "q", "ctrl+p":
+		m.quit = true
+	case "j", "down":
+		if m.cursor < len(m.profiles)-1 {
+			m.cursor++
+		}
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "enter":
+		m.chosen = m.profiles[m.cursor] != m.current
+		m.quit = true
+	}
+	return m, nil
+}
+
+func (m ProfileSwitcherModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Switch Profile") + "\n\n")
+
+	for i, name := range m.profiles {
+		line := name
+		if name == m.configFile.Default {
+			line += " (Default)"
+		}
+		if name == m.current {
+			line += " ✓"
+		}
+		if i == m.cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(NormalItemStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	if profile, err := m.configFile.GetProfile(m.profiles[m.cursor]); err == nil {
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render("Schema Registry: "+profile.SchemaRegistry.URL) + "\n")
+		if profile.Kafka.BootstrapServers != "" {
+			b.WriteString(HelpStyle.Render("Kafka Bootstrap: "+profile.Kafka.BootstrapServers) + "\n")
+		}
+		if profile.SSHTunnel != nil && profile.SSHTunnel.Host != "" {
+			b.WriteString(HelpStyle.Render("SSH Tunnel: "+sshTarget(profile.SSHTunnel)) + "\n")
+		}
+		if profile.Production {
+			b.WriteString(ErrorStyle.Render("Production") + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [enter] Reconnect  [esc] Cancel") + "\n")
+	return b.String()
+}
+
+// Selected returns the chosen profile, if one other than the current one
+// was chosen
+func (m ProfileSwitcherModel) Selected() (string, bool) {
+	if !m.chosen {
+		return "", false
+	}
+	return m.profiles[m.cursor], true
+}
+
+// Quit returns whether the switcher is closed
+func (m ProfileSwitcherModel) Quit() bool {
+	return m.quit
+}
+
+// enterProfileSwitcher lists the config file's profiles
+func (m *Model) enterProfileSwitcher() {
+	configFile, err := config.LoadConfigFile(config.GetConfigPath())
+	if err != nil {
+		m.err = fmt.Errorf("loading config file: %w", err)
+		return
+	}
+	if len(configFile.Configurations) == 0 {
+		m.err = fmt.Errorf("no profiles in %s", config.GetConfigPath())
+		return
+	}
+	m.profileSwitcher = NewProfileSwitcher(configFile, m.cfg.Profile)
+	m.profileReturn = m.state
+	m.state = stateProfileSwitcher
+}
+
+func (m *Model) handleProfileSwitcher(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.profileSwitcher.Update(msg)
+	m.profileSwitcher = newModel.(ProfileSwitcherModel)
+	if !m.profileSwitcher.Quit() {
+		return m, cmd
+	}
+
+	m.state = m.profileReturn
+	name, ok := m.profileSwitcher.Selected()
+	if !ok {
+		return m, nil
+	}
+	configFile, project := m.profileSwitcher.configFile, m.cfg.Project
+	m.statusMsg = fmt.Sprintf("Connecting to %s...", name)
+	return m, func() tea.Msg {
+		return connectProfile(configFile, name, project)
+	}
+}
+
+// connectProfile opens the connections the TUI needs for a profile: its SSH
+// tunnel, registry client and, with Kafka configured, a producer
+func connectProfile(configFile *config.ConfigFile, name string, project *config.ProjectConfig) profileConnectedMsg {
+	cfg, err := configFile.Config(name)
+	if err != nil {
+		return profileConnectedMsg{err: err}
+	}
+	cfg.Project = project
+
+	t, err := tunnel.Connect(cfg)
+	if err != nil {
+		return profileConnectedMsg{err: fmt.Errorf("ssh tunnel for %s: %w", name, err)}
+	}
+	msg := profileConnectedMsg{cfg: cfg, client: registry.NewClient(cfg), tunnel: t}
+	if cfg.HasKafka() {
+		msg.producer, msg.producerErr = kafka.NewProducer(cfg)
+	}
+	return msg
+}
+
+// handleProfileConnected replaces the model with a fresh one on the new
+// profile's connections, closing the old ones. The window size carries over.
+func (m *Model) handleProfileConnected(msg profileConnectedMsg) (tea.Model, tea.Cmd) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = msg.err
+		return m, nil
+	}
+	if err := avro.SetBackend(msg.cfg.AvroBackend); err != nil {
+		if msg.producer != nil {
+			msg.producer.Close()
+		}
+		if msg.tunnel != nil {
+			msg.tunnel.Close()
+		}
+		m.err = err
+		return m, nil
+	}
+
+	shutdownErr := m.Shutdown()
+
+	next := NewModel(msg.client, msg.producer, msg.cfg)
+	next.SetTunnel(msg.tunnel)
+	switch {
+	case msg.producerErr != nil:
+		next.err = fmt.Errorf("message production is disabled: could not create Kafka producer: %w", msg.producerErr)
+	case shutdownErr != nil:
+		next.err = fmt.Errorf("closing %s: %w", m.cfg.Profile, shutdownErr)
+	case next.err == nil:
+		next.copyNotify = "Switched to " + msg.cfg.Profile
+	}
+	width, height := m.width, m.height
+	return next, tea.Batch(next.Init(), func() tea.Msg {
+		return tea.WindowSizeMsg{Width: width, Height: height}
+	})
+}
@@ -0,0 +1,110 @@
+package ui
+
+import (
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+)
+
+// TextPromptModel asks for a single line of text
+type TextPromptModel struct {
+	title  string
+	hint   string
+	value  string
+	path   pathKind
+	exts   []string // File types the browser shows
+	picker pathPicker
+	saved  bool
+	quit   bool
+}
+
+// NewTextPrompt creates a prompt prefilled with value
+func NewTextPrompt(title, hint, value string) TextPromptModel {
+	return TextPromptModel{title: title, hint: hint, value: value}
+}
+
+// NewPathPrompt creates a prompt for a file to write, which can also be
+// browsed for
+func NewPathPrompt(title, hint, value string) TextPromptModel {
+	return TextPromptModel{title: title, hint: hint, value: value, path: savePath}
+}
+
+// NewOpenPrompt creates a prompt for a file to read, which can also be
+// browsed for
+func NewOpenPrompt(title, hint, value string, extensions ...string) TextPromptModel {
+	return TextPromptModel{title: title, hint: hint, value: value, path: openPath, exts: extensions}
+}
+
+func (m TextPromptModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m TextPromptModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		if m.picker.active() {
+			if path, ok := m.picker.update(msg); ok {
+				m.value = path
+			}
+			return m, nil
+		}
+		switch msg.String() {
+		case "esc":
+			m.quit = true
+		case "ctrl+f":
+			if m.path != noPath {
+				m.picker.browse(m.title, m.path, m.value, m.exts...)
+			}
+		case "enter":
+			m.saved = true
+			m.quit = true
+		case "backspace":
+			if len(m.value) > 0 {
+				runes := []rune(m.value)
+				m.value = string(runes[:len(runes)-1])
+			}
+		case "ctrl+u":
+			m.value = ""
+		default:
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				m.value += string(msg.Runes)
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m TextPromptModel) View() string {
+	if m.picker.active() {
+		return m.picker.view()
+	}
+
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render(m.title) + "\n\n"
+	s += "> " + m.value + "\n\n"
+	if m.hint != "" {
+		s += lipgloss.NewStyle().Faint(true).Render(m.hint) + "\n"
+	}
+	if m.path != noPath {
+		s += lipgloss.NewStyle().Faint(true).Render("[enter] OK  [ctrl+f] Browse  [ctrl+u] Clear  [esc] Cancel") + "\n"
+	} else {
+		s += lipgloss.NewStyle().Faint(true).Render("[enter] OK  [ctrl+u] Clear  [esc] Cancel") + "\n"
+	}
+	return s
+}
+
+// Value returns the entered text, trimmed
+func (m TextPromptModel) Value() string {
+	return strings.TrimSpace(m.value)
+}
+
+// Saved returns whether the user confirmed the prompt
+func (m TextPromptModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the prompt is closed
+func (m TextPromptModel) Quit() bool {
+	return m.quit
+}
@@ -0,0 +1,232 @@
+package ui
+
+import (
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	"github.com/charmbracelet/bubbles/viewport"
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// ProtocolBrowserModel lists the messages of an Avro protocol, from the
+// registry or a local .avpr file, and shows a template for the selected
+// message's request
+type ProtocolBrowserModel struct {
+	protocol *avro.Protocol
+	source   string // Subject or file name, for the title
+	opts     avro.TemplateOptions
+	selected int
+	detail   viewport.Model
+	picker   pathPicker
+	template string
+	err      string
+	notice   string
+	quit     bool
+}
+
+// NewProtocolBrowser browses protocol. With a nil protocol it starts by
+// asking for a .avpr file.
+func NewProtocolBrowser(protocol *avro.Protocol, source string, opts avro.TemplateOptions, width, height int) ProtocolBrowserModel {
+	m := ProtocolBrowserModel{
+		opts:   opts,
+		detail: viewport.New(max(width-4, 20), max(height/2, 5)),
+	}
+	if protocol == nil {
+		m.picker.browse("Avro Protocol", openPath, "", ".avpr")
+		return m
+	}
+	m.show(protocol, source)
+	return m
+}
+
+func (m ProtocolBrowserModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ProtocolBrowserModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	if m.picker.active() {
+		path, ok := m.picker.update(keyMsg)
+		if ok {
+			m.load(path)
+		} else if !m.picker.active() && m.protocol == nil {
+			m.quit = true // Cancelled before anything was loaded
+		}
+		return m, nil
+	}
+
+	m.notice = ""
+	switch keyMsg.String() {
+	case "esc", "q":
+		m.quit = true
+	case "j", "down":
+		if m.selected < len(m.protocol.Messages)-1 {
+			m.selected++
+			m.render()
+		}
+	case "k", "up":
+		if m.selected > 0 {
+			m.selected--
+			m.render()
+		}
+	case "o":
+		m.picker.browse("Avro Protocol", openPath, "", ".avpr")
+	case "y":
+		if m.template != "" {
+			m.copy(m.template, "request template")
+		}
+	case "Y":
+		if len(m.protocol.Messages) > 0 {
+			schema, err := m.protocol.RequestSchema(m.protocol.Messages[m.selected].Name)
+			if err != nil {
+				m.err = err.Error()
+				return m, nil
+			}
+			m.copy(registry.PrettyPrintSchema(schema), "request schema")
+		}
+	default:
+		var cmd tea.Cmd
+		m.detail, cmd = m.detail.Update(msg)
+		return m, cmd
+	}
+	return m, nil
+}
+
+// load reads and parses a local protocol file
+func (m *ProtocolBrowserModel) load(path string) {
+	data, err := os.ReadFile(path)
+	if err != nil {
+		m.err = err.Error()
+		return
+	}
+	protocol, err := avro.ParseProtocol(string(data))
+	if err != nil {
+		m.err = fmt.Sprintf("%s: %v", filepath.Base(path), err)
+		return
+	}
+	m.show(protocol, filepath.Base(path))
+}
+
+func (m *ProtocolBrowserModel) show(protocol *avro.Protocol, source string) {
+	m.protocol = protocol
+	m.source = source
+	m.selected = 0
+	m.err = ""
+	m.render()
+}
+
+// render fills the detail pane for the selected message
+func (m *ProtocolBrowserModel) render() {
+	m.template = ""
+	if len(m.protocol.Messages) == 0 {
+		m.detail.SetContent(HelpStyle.Render("This protocol declares no messages"))
+		return
+	}
+
+	message := m.protocol.Messages[m.selected]
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render(m.protocol.Signature(message)))
+	b.WriteString("\n")
+	if message.Doc != "" {
+		b.WriteString(HelpStyle.Render(message.Doc))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	schema, err := m.protocol.RequestSchema(message.Name)
+	if err == nil {
+		m.template, err = avro.GenerateTemplateWithOptions(schema, m.opts)
+	}
+	if err != nil {
+		b.WriteString(ErrorStyle.Render("Cannot generate a request template: " + err.Error()))
+	} else {
+		b.WriteString("Request template:\n")
+		b.WriteString(m.template)
+	}
+	m.detail.SetContent(b.String())
+	m.detail.GotoTop()
+}
+
+func (m *ProtocolBrowserModel) copy(content, label string) {
+	if err := clipboard.WriteAll(content); err != nil {
+		m.err = fmt.Sprintf("failed to copy: %v", err)
+		return
+	}
+	m.notice = fmt.Sprintf("Copied %s to clipboard!", label)
+}
+
+func (m ProtocolBrowserModel) View() string {
+	if m.picker.active() {
+		s := m.picker.view()
+		if m.err != "" {
+			s += "\n" + ErrorStyle.Render("✗ "+m.err)
+		}
+		return s
+	}
+
+	var b strings.Builder
+	b.WriteString(ListTitleStyle.Render(fmt.Sprintf("Protocol %s (%s)", m.protocol.Name, m.source)))
+	b.WriteString("\n")
+	if m.protocol.Doc != "" {
+		b.WriteString(HelpStyle.Render(m.protocol.Doc))
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+
+	for i, message := range m.protocol.Messages {
+		if i == m.selected {
+			b.WriteString(SelectedItemStyle.Render("> " + message.Name))
+		} else {
+			b.WriteString(NormalItemStyle.Render("  " + message.Name))
+		}
+		b.WriteString("\n")
+	}
+	b.WriteString("\n")
+	b.WriteString(m.detail.View())
+	b.WriteString("\n\n")
+
+	if m.err != "" {
+		b.WriteString(ErrorStyle.Render("✗ "+m.err) + "\n")
+	}
+	if m.notice != "" {
+		b.WriteString(SuccessStyle.Render(m.notice) + "\n")
+	}
+	b.WriteString(HelpStyle.Render("[j/k] Message  [y] Copy template  [Y] Copy request schema  [o] Open .avpr  [pgup/pgdn] Scroll  [esc] Close"))
+	return b.String()
+}
+
+// Quit returns whether the browser is closed
+func (m ProtocolBrowserModel) Quit() bool {
+	return m.quit
+}
+
+// openProtocolBrowser browses the viewed subject if it holds a protocol,
+// and otherwise asks for a local .avpr file
+func (m *Model) openProtocolBrowser() {
+	var protocol *avro.Protocol
+	if m.state == stateViewing {
+		protocol = m.protocol
+	}
+	m.protocolBrowser = NewProtocolBrowser(protocol, m.selectedSubject, m.templateOptions(), m.width, m.height)
+	m.protocolReturn = m.state
+	m.state = stateProtocolBrowser
+}
+
+func (m *Model) handleProtocolBrowser(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.protocolBrowser.Update(msg)
+	m.protocolBrowser = newModel.(ProtocolBrowserModel)
+	if m.protocolBrowser.Quit() {
+		m.state = m.protocolReturn
+	}
+	return m, cmd
+}
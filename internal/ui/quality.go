@@ -0,0 +1,100 @@
+package ui
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strconv"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/quality"
+)
+
+// qualityCheckedMsg carries the results of a topic's data quality rules
+type qualityCheckedMsg struct {
+	topic   string
+	sampled int
+	results []quality.Result
+	err     error
+}
+
+// enterQualityPrompt asks how many recent messages to check the topic's
+// data quality rules against
+func (m *Model) enterQualityPrompt() {
+	if !m.cfg.HasKafka() {
+		m.err = fmt.Errorf("Kafka not configured")
+		return
+	}
+	if !m.needTopic() {
+		return
+	}
+	if len(m.cfg.QualityRulesFor(m.topic())) == 0 {
+		m.err = fmt.Errorf("no quality rules for %s in %s", m.topic(), config.GetConfigPath())
+		return
+	}
+	m.qualityPrompt = NewTextPrompt("Data Quality: "+m.topic(), fmt.Sprintf("Recent messages to check, split across partitions (at most %d)", maxBackfillMessages), strconv.Itoa(defaultUsageSample))
+	m.state = stateQualityPrompt
+}
+
+func (m *Model) handleQualityPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.qualityPrompt.Update(msg)
+	m.qualityPrompt = newModel.(TextPromptModel)
+	if !m.qualityPrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateViewing
+	if !m.qualityPrompt.Saved() {
+		return m, nil
+	}
+	n, err := strconv.Atoi(strings.TrimSpace(m.qualityPrompt.Value()))
+	if err != nil || n <= 0 {
+		m.err = fmt.Errorf("sample size must be a positive whole number")
+		return m, nil
+	}
+	n = min(n, maxBackfillMessages)
+
+	topic := m.topic()
+	rules, err := quality.Compile(m.cfg.QualityRulesFor(topic))
+	if err != nil {
+		m.err = fmt.Errorf("quality rules for %s: %w", topic, err)
+		return m, nil
+	}
+	cfg, redact := m.cfg, m.masker != nil
+	decoder := avro.NewWireDecoder(m.client.GetSchemaByID)
+	decoder.Transform = m.encryptor.DecryptByID(m.selectedSubject)
+	m.statusMsg = fmt.Sprintf("Checking %d messages from %s...", n, topic)
+	return m, m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		sampled, err := sampleRecent(ctx, cfg, topic, n)
+		if err != nil {
+			return qualityCheckedMsg{err: err}
+		}
+		messages := make([]quality.Message, 0, len(sampled))
+		for _, msg := range sampled {
+			data, err := base64.StdEncoding.DecodeString(msg.Value)
+			if err != nil {
+				continue
+			}
+			if _, doc, err := decoder.Decode(data); err == nil {
+				messages = append(messages, quality.Message{Partition: msg.Partition, Offset: msg.Offset, Doc: doc, Redact: redact})
+			}
+		}
+		return qualityCheckedMsg{topic: topic, sampled: len(messages), results: quality.Run(rules, messages)}
+	})
+}
+
+func (m *Model) handleQualityChecked(msg qualityCheckedMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = fmt.Errorf("data quality: %w", msg.err)
+		return
+	}
+	if m.state != stateViewing && m.state != stateBrowsing {
+		return
+	}
+	m.openReport("Data Quality", quality.Format(msg.topic, msg.sampled, msg.results))
+}
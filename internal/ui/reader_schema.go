@@ -0,0 +1,267 @@
+package ui
+
+import (
+	"context"
+	"encoding/base64"
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// readerVersionsMsg carries a subject's versions for the reader picker
+type readerVersionsMsg struct {
+	subject  string
+	versions []int
+	err      error
+}
+
+// readerSchemaMsg carries the version consumed messages are read as
+type readerSchemaMsg struct {
+	schema *registry.SchemaResponse
+	err    error
+}
+
+// redecodedMsg carries fetched message values decoded again for a new
+// reader version, by offset
+type redecodedMsg struct {
+	consumer *kafka.Consumer
+	values   map[int64]decodedValue
+}
+
+// ReaderPickerModel picks the schema version consumed messages are read
+// as, or none to show them as written
+type ReaderPickerModel struct {
+	subject  string
+	versions []int
+	current  int // 0 when messages are shown as written
+	cursor   int // 0 is "as written", then versions
+	chosen   bool
+	quit     bool
+}
+
+// NewReaderPicker lists a subject's versions, newest first, below showing
+// messages as written
+func NewReaderPicker(subject string, versions []int, current int) ReaderPickerModel {
+	m := ReaderPickerModel{subject: subject, current: current}
+	for i := len(versions) - 1; i >= 0; i-- {
+		m.versions = append(m.versions, versions[i])
+		if versions[i] == current {
+			m.cursor = len(m.versions)
+		}
+	}
+	return m
+}
+
+func (m ReaderPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m ReaderPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "esc", "q":
+		m.quit = true
+	case "j", "down":
+		if m.cursor < len(m.versions) {
+			m.cursor++
+		}
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "enter":
+		m.chosen = true
+		m.quit = true
+	}
+	return m, nil
+}
+
+func (m ReaderPickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Read Messages As: "+m.subject) + "\n\n")
+
+	lines := []string{"As written (each message's own schema)"}
+	for i, v := range m.versions {
+		line := fmt.Sprintf("v%d", v)
+		if i == 0 {
+			line += " (latest)"
+		}
+		lines = append(lines, line)
+	}
+	for i, line := range lines {
+		if (i == 0 && m.current == 0) || (i > 0 && m.versions[i-1] == m.current) {
+			line += " ✓"
+		}
+		if i == m.cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(NormalItemStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render("Messages are decoded with the schema ID they were written with, then resolved to the chosen version as a consumer using it would see them") + "\n")
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [enter] Read as  [esc] Cancel") + "\n")
+	return b.String()
+}
+
+// Selected returns the chosen version, 0 for as written
+func (m ReaderPickerModel) Selected() (int, bool) {
+	if !m.chosen || m.cursor == 0 {
+		return 0, m.chosen
+	}
+	return m.versions[m.cursor-1], true
+}
+
+// Quit returns whether the picker is closed
+func (m ReaderPickerModel) Quit() bool {
+	return m.quit
+}
+
+// startReaderPicker lists the subject's versions to read messages as
+func (m *Model) startReaderPicker() tea.Cmd {
+	subject := m.selectedSubject
+	client := m.client
+	return func() tea.Msg {
+		versions, err := client.ListVersions(subject)
+		return readerVersionsMsg{subject: subject, versions: versions, err: err}
+	}
+}
+
+func (m *Model) handleReaderVersions(msg readerVersionsMsg) {
+	if msg.err != nil {
+		m.err = fmt.Errorf("listing versions of %s: %w", msg.subject, msg.err)
+		return
+	}
+	if msg.subject != m.selectedSubject || m.state != stateConsumerMode {
+		return
+	}
+	current := 0
+	if m.readerSchema != nil {
+		current = m.readerSchema.Version
+	}
+	m.readerPicker = NewReaderPicker(msg.subject, msg.versions, current)
+	m.state = stateReaderPicker
+}
+
+func (m *Model) handleReaderPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.readerPicker.Update(msg)
+	m.readerPicker = newModel.(ReaderPickerModel)
+	if !m.readerPicker.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateConsumerMode
+	version, ok := m.readerPicker.Selected()
+	if !ok {
+		return m, nil
+	}
+	if version == 0 {
+		m.readerSchema = nil
+		m.copyNotify = "Showing messages as written"
+		return m, m.redecodeCmd()
+	}
+	subject := m.readerPicker.subject
+	client := m.client
+	return m, func() tea.Msg {
+		schema, err := client.GetSchemaVersion(subject, version)
+		return readerSchemaMsg{schema: schema, err: err}
+	}
+}
+
+func (m *Model) handleReaderSchema(msg readerSchemaMsg) tea.Cmd {
+	if msg.err != nil {
+		m.err = fmt.Errorf("fetching reader schema: %w", msg.err)
+		return nil
+	}
+	if msg.schema.Subject != m.selectedSubject {
+		return nil
+	}
+	m.readerSchema = msg.schema
+	m.copyNotify = fmt.Sprintf("Reading messages as %s v%d", msg.schema.Subject, msg.schema.Version)
+	return m.redecodeCmd()
+}
+
+// redecodeCmd decodes the fetched messages again after the reader version
+// changed
+func (m *Model) redecodeCmd() tea.Cmd {
+	consumer := m.consumer
+	messages := append([]kafka.Message(nil), m.consumedMessages...)
+	decode := m.decodeValue
+
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		values := make(map[int64]decodedValue, len(messages))
+		for _, msg := range messages {
+			if ctx.Err() != nil {
+				break
+			}
+			values[msg.Offset] = decode(msg.Value)
+		}
+		return redecodedMsg{consumer: consumer, values: values}
+	})
+}
+
+// redecodeIfStale decodes the fetched messages again if some were decoded
+// for another reader version, as those of a fetch in flight when it changed
+// are
+func (m *Model) redecodeIfStale(decoded []decodedMessage) tea.Cmd {
+	for _, d := range decoded {
+		if d.value.reader != m.readerSchema {
+			return m.redecodeCmd()
+		}
+	}
+	return nil
+}
+
+// handleRedecoded installs values decoded for a new reader version
+func (m *Model) handleRedecoded(msg redecodedMsg) {
+	if msg.consumer != m.consumer {
+		return // Consumer mode was left or restarted
+	}
+	for i, d := range m.decodedMessages {
+		if value, ok := msg.values[d.msg.Offset]; ok {
+			m.decodedMessages[i].value = value
+		}
+	}
+}
+
+// writerSchema returns the schema a wire-format message was written with,
+// looked up by its ID. Local schema files have no IDs of their own, so
+// there it is the viewed schema.
+func (m Model) writerSchema(schemaID int) (string, error) {
+	if m.cfg.RegistryFlavor == config.FlavorLocal {
+		return m.rawSchema, nil
+	}
+	if m.wireDecoder == nil {
+		return "", fmt.Errorf("no registry to look up schema %d", schemaID)
+	}
+	return m.wireDecoder.Schema(schemaID)
+}
+
+// readerLabel describes which schemas a wire-format message value is shown
+// with
+func (m Model) readerLabel(payload string) string {
+	data, err := base64.StdEncoding.DecodeString(payload)
+	if err != nil {
+		return ""
+	}
+	schemaID, _, ok := avro.SplitWireFormat(data)
+	if !ok {
+		return ""
+	}
+	label := fmt.Sprintf("Written with schema ID %d", schemaID)
+	if m.readerSchema != nil {
+		label += fmt.Sprintf(", read as v%d (ID %d)", m.readerSchema.Version, m.readerSchema.ID)
+	}
+	return label
+}
@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/diff"
+)
+
+// openReport shows read-only content in the report pane and remembers the state to return to
+func (m *Model) openReport(title, content string) {
+	m.reportTitle = title
+	m.reportView.SetContent(content)
+	m.reportView.GotoTop()
+	m.reportCopy = ""
+	m.reportReturn = m.state
+	m.state = stateReport
+}
+
+func (m *Model) handleReportMode(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	switch msg.String() {
+	case "esc", "q":
+		m.state = m.reportReturn
+		return m, nil
+	case "y":
+		if m.reportCopy != "" {
+			if err := clipboard.WriteAll(m.reportCopy); err != nil {
+				m.err = fmt.Errorf("failed to copy: %w", err)
+			} else {
+				m.copyNotify = "Copied to clipboard!"
+			}
+		}
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.reportView, cmd = m.reportView.Update(msg)
+	return m, cmd
+}
+
+// diffAgainstTemplate compares the payload being edited with a freshly
+// generated template for the current schema
+func (m *Model) diffAgainstTemplate() {
+	template, err := m.generateTemplate()
+	if err != nil {
+		m.err = fmt.Errorf("generating template: %w", err)
+		return
+	}
+
+	changes, err := diff.JSONStrings(template, m.editor.Value())
+	if err != nil {
+		m.err = err
+		return
+	}
+
+	m.openReport("Payload vs Template", renderChanges(changes, "template", "payload"))
+}
+
+// renderChanges formats a list of JSON changes with one colored line per change
+func renderChanges(changes []diff.Change, oldLabel, newLabel string) string {
+	var b strings.Builder
+
+	if len(changes) == 0 {
+		b.WriteString(SuccessStyle.Render(fmt.Sprintf("No differences between %s and %s", oldLabel, newLabel)))
+		return b.String()
+	}
+
+	counts := map[diff.Kind]int{}
+	for _, c := range changes {
+		counts[c.Kind]++
+	}
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("%s → %s: %d changed, %d removed, %d added",
+		oldLabel, newLabel, counts[diff.Changed], counts[diff.Removed], counts[diff.Added])))
+	b.WriteString("\n\n")
+
+	for _, c := range changes {
+		switch c.Kind {
+		case diff.Changed:
+			b.WriteString(DiffChangedStyle.Render(fmt.Sprintf("~ %s", c.Path)))
+			b.WriteString(fmt.Sprintf("\n    %s: %s\n    %s: %s\n",
+				oldLabel, diff.FormatValue(c.Old, 60), newLabel, diff.FormatValue(c.New, 60)))
+		case diff.Removed:
+			b.WriteString(DiffRemovedStyle.Render(fmt.Sprintf("- %s", c.Path)))
+			b.WriteString(fmt.Sprintf("\n    only in %s: %s\n", oldLabel, diff.FormatValue(c.Old, 60)))
+		case diff.Added:
+			b.WriteString(DiffAddedStyle.Render(fmt.Sprintf("+ %s", c.Path)))
+			b.WriteString(fmt.Sprintf("\n    only in %s: %s\n", newLabel, diff.FormatValue(c.New, 60)))
+		}
+	}
+
+	return b.String()
+}
+
+func (m Model) renderReport(width, height int) string {
+	var b strings.Builder
+
+	b.WriteString(ListTitleStyle.Render(m.reportTitle))
+	b.WriteString("\n\n")
+
+	m.reportView.Width = width - 2
+	m.reportView.Height = height - 6
+	b.WriteString(m.reportView.View())
+
+	return b.String()
+}
@@ -0,0 +1,235 @@
+package ui
+
+import (
+	"context"
+	"crypto/rand"
+	"encoding/base64"
+	"encoding/json"
+	"errors"
+	"fmt"
+	"sort"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// replyReceivedMsg carries the reply to a request produced with a
+// correlation ID, or the error that prevented it
+type replyReceivedMsg struct {
+	requestTopic  string
+	replyTopic    string
+	correlationID string
+	reply         *kafka.Message
+	value         string // Decoded reply value
+	key           string // Request key and payload, for hooks
+	payload       string
+	err           error
+
+	// End-to-end latency, measured from just before producing the request
+	produceAck time.Duration // Until the brokers acknowledged the request
+	observed   time.Duration // Until the reply was consumed
+	sentAt     time.Time
+}
+
+// requestReplyConfig returns the reply settings for a request topic with
+// defaults filled in
+func (m Model) requestReplyConfig(topic string) (config.RequestReplyConfig, bool) {
+	rr, ok := m.cfg.RequestReply[topic]
+	if !ok || rr.ReplyTopic == "" {
+		return rr, false
+	}
+	if rr.CorrelationHeader == "" {
+		rr.CorrelationHeader = config.DefaultCorrelationHeader
+	}
+	if rr.Timeout <= 0 {
+		rr.Timeout = config.DefaultReplyTimeout
+	}
+	return rr, true
+}
+
+// sendAndAwaitReply produces the payload with a fresh correlation ID header,
+// then tails the reply topic until a message carrying the same ID arrives
+func (m Model) sendAndAwaitReply(rr config.RequestReplyConfig) tea.Cmd {
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		topic := m.topic()
+		result := replyReceivedMsg{requestTopic: topic, replyTopic: rr.ReplyTopic}
+
+		if m.producer == nil {
+			result.err = fmt.Errorf("Kafka not configured")
+			return result
+		}
+		if err := m.cfg.CheckSubject(m.selectedSubject); err != nil {
+			result.err = err
+			return result
+		}
+
+		binary, err := m.encodeValue(m.editor.Value())
+		if err != nil {
+			result.err = err
+			return result
+		}
+		key, err := m.encodeKeyFor(topic, m.keyInput.Value())
+		if err != nil {
+			result.err = err
+			return result
+		}
+
+		ctx, cancel := context.WithTimeout(ctx, rr.Timeout)
+		defer cancel()
+
+		// Position on the reply topic before producing, so a fast reply
+		// isn't missed
+		consumer, err := kafka.NewConsumer(m.cfg, rr.ReplyTopic)
+		if err != nil {
+			result.err = fmt.Errorf("failed to create consumer: %w", err)
+			return result
+		}
+		m.supervisor.Own(consumer)
+		defer m.supervisor.Release(consumer)
+		if err := consumer.SeekToEnd(ctx); err != nil {
+			result.err = fmt.Errorf("seeking to end of %s: %w", rr.ReplyTopic, err)
+			return result
+		}
+
+		result.correlationID = newCorrelationID()
+		headers := map[string]string{rr.CorrelationHeader: result.correlationID}
+		if rr.ReplyToHeader != "" {
+			headers[rr.ReplyToHeader] = rr.ReplyTopic
+		}
+		result.sentAt = time.Now()
+		if err := m.producer.ProduceRecord(ctx, topic, m.schemaID, kafka.Record{Key: key, Value: binary, Headers: headers}); err != nil {
+			result.err = err
+			return result
+		}
+		result.produceAck = time.Since(result.sentAt)
+		result.key = m.keyInput.Value()
+		result.payload = m.editor.Value()
+
+		for {
+			messages, err := consumer.FetchMessages(ctx, 1)
+			if err != nil && !errors.Is(err, context.DeadlineExceeded) {
+				result.err = fmt.Errorf("consuming %s: %w", rr.ReplyTopic, err)
+				return result
+			}
+			if ctx.Err() != nil {
+				result.err = fmt.Errorf("no reply on %s within %s (%s: %s)", rr.ReplyTopic, rr.Timeout, rr.CorrelationHeader, result.correlationID)
+				return result
+			}
+
+			for _, msg := range messages {
+				if msg.Headers[rr.CorrelationHeader] != result.correlationID {
+					continue
+				}
+				result.observed = time.Since(result.sentAt)
+				result.reply = &msg
+				result.value = decodeReply(m, msg)
+				return result
+			}
+		}
+	})
+}
+
+// decodeReply decodes a reply value, which may use a different schema from
+// the request, falling back to the raw value when it can't be decoded
+func decodeReply(m Model, msg kafka.Message) string {
+	data, err := base64.StdEncoding.DecodeString(msg.Value)
+	if err != nil {
+		return msg.Value
+	}
+
+	_, doc, err := avro.NewWireDecoder(m.client.GetSchemaByID).Decode(data)
+	if err != nil {
+		return fmt.Sprintf("[%v]\n%s", err, msg.Value)
+	}
+	pretty, err := json.MarshalIndent(doc, "", "  ")
+	if err != nil {
+		return string(data)
+	}
+	return string(pretty)
+}
+
+func (m *Model) handleReplyReceived(msg replyReceivedMsg) tea.Cmd {
+	m.state = stateSendMode
+
+	// The request was produced even if no reply came
+	var hook tea.Cmd
+	if msg.produceAck > 0 {
+		data := m.producedHookData(msg.requestTopic, msg.key, msg.payload)
+		data["correlation_id"] = msg.correlationID
+		data["reply_topic"] = msg.replyTopic
+		hook = m.fireHook(hooks.MessageProduced, data)
+	}
+
+	if msg.err != nil {
+		m.err = msg.err
+		m.statusMsg = "[SEND MODE] No reply - press Alt+S to retry"
+		return hook
+	}
+
+	m.statusMsg = fmt.Sprintf("SUCCESS: Reply received on '%s' in %s", msg.replyTopic, formatLatency(msg.observed))
+	m.openReport("Reply: "+msg.replyTopic, renderReply(m, msg))
+	return hook
+}
+
+func renderReply(m *Model, msg replyReceivedMsg) string {
+	var b strings.Builder
+
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("Request → %s  |  correlation ID %s", msg.requestTopic, msg.correlationID)))
+	b.WriteString("\n")
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("Reply   ← %s  |  offset %d  |  %s",
+		msg.replyTopic, msg.reply.Offset, msg.reply.Timestamp.Format("2006-01-02 15:04:05.000"))))
+	b.WriteString("\n\n")
+
+	b.WriteString("Latency:\n")
+	b.WriteString(fmt.Sprintf("  produce ack      %s\n", formatLatency(msg.produceAck)))
+	b.WriteString(fmt.Sprintf("  reply observed   %s\n", formatLatency(msg.observed)))
+	if !msg.reply.Timestamp.IsZero() {
+		// The reply's broker timestamp splits the round trip into service
+		// time and consumer delay (subject to clock skew between hosts)
+		b.WriteString(fmt.Sprintf("  reply timestamp  %s after send\n", formatLatency(msg.reply.Timestamp.Sub(msg.sentAt))))
+	}
+	b.WriteString("\n")
+
+	if key := m.decodeKey(msg.reply.Key); key != "" {
+		b.WriteString("Key: " + key + "\n")
+	}
+	if len(msg.reply.Headers) > 0 {
+		names := make([]string, 0, len(msg.reply.Headers))
+		for name := range msg.reply.Headers {
+			names = append(names, name)
+		}
+		sort.Strings(names)
+		b.WriteString("Headers:\n")
+		for _, name := range names {
+			b.WriteString(fmt.Sprintf("  %s: %s\n", name, msg.reply.Headers[name]))
+		}
+	}
+	b.WriteString("\n")
+	b.WriteString(msg.value)
+
+	return b.String()
+}
+
+// formatLatency rounds a duration for display: 1ms resolution, or 0.1ms
+// below 10ms
+func formatLatency(d time.Duration) string {
+	if d < 10*time.Millisecond {
+		return d.Round(100 * time.Microsecond).String()
+	}
+	return d.Round(time.Millisecond).String()
+}
+
+// newCorrelationID returns a random UUID (version 4)
+func newCorrelationID() string {
+	var b [16]byte
+	rand.Read(b[:])
+	b[6] = (b[6] & 0x0f) | 0x40
+	b[8] = (b[8] & 0x3f) | 0x80
+	return fmt.Sprintf("%x-%x-%x-%x-%x", b[0:4], b[4:6], b[6:8], b[8:10], b[10:16])
+}
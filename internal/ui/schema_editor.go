@@ -0,0 +1,220 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/json"
+	"fmt"
+	"strings"
+
+	"github.com/charmbracelet/bubbles/textarea"
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/hooks"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// schemaRegisteredMsg carries the ID of a newly registered schema version
+type schemaRegisteredMsg struct {
+	subject string
+	schema  string
+	id      int
+	err     error
+}
+
+// enterSchemaEditor opens the viewed schema for editing, to register the
+// result as a new version
+func (m *Model) enterSchemaEditor() tea.Cmd {
+	if m.isReadOnly(m.selectedSubject) {
+		m.err = fmt.Errorf("%s is read-only: schemas are managed by %s", m.selectedSubject, m.links[m.selectedSubject].Source)
+		return nil
+	}
+	if err := m.cfg.CheckSubject(m.selectedSubject); err != nil {
+		m.err = err
+		return nil
+	}
+
+	ta := textarea.New()
+	ta.ShowLineNumbers = true
+	ta.MaxHeight = 0
+	ta.SetValue(m.currentSchema)
+	ta.Focus()
+	m.schemaEditor = ta
+	m.confirmRegister = false
+	m.state = stateEditingSchema
+	m.focusedPane = viewerPane
+	m.statusMsg = fmt.Sprintf("[EDIT SCHEMA] %s  |  Ctrl+S register as a new version, Ctrl+R rename a field, Ctrl+T snippets, Esc cancel", m.selectedSubject)
+	return textarea.Blink
+}
+
+func (m *Model) handleSchemaEditor(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if m.confirmRegister {
+		switch msg.String() {
+		case "y", "enter":
+			m.confirmRegister = false
+			m.statusMsg = fmt.Sprintf("Registering a new version of %s...", m.selectedSubject)
+			return m, registerSchema(m.client, m.selectedSubject, m.editedSchema(), m.schemaType)
+		case "n", "esc":
+			m.confirmRegister = false
+		}
+		return m, nil
+	}
+
+	switch msg.String() {
+	case "esc":
+		m.schemaEditor.Blur()
+		m.state = stateViewing
+		m.statusMsg = fmt.Sprintf("[VIEW] %s", m.selectedSubject)
+		return m, nil
+
+	case "ctrl+t":
+		m.enterSnippetPicker()
+		return m, nil
+
+	case "ctrl+s":
+		schema := m.editedSchema()
+		if err := m.checkSchema(schema); err != nil {
+			m.err = fmt.Errorf("invalid schema: %w", err)
+			return m, nil
+		}
+		if schema == m.editedBaseline() && m.localSchema.path == "" {
+			m.err = fmt.Errorf("the schema is unchanged")
+			return m, nil
+		}
+		if _, err := m.cfg.CheckSubjectName(m.selectedSubject); err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.err = nil
+		m.confirmRegister = true
+		return m, nil
+
+	case "ctrl+r":
+		m.renameFrom = ""
+		m.renamePrompt = NewTextPrompt("Rename Field", "Field path (customer.address.street), or a bare name to rename it in every record", "")
+		m.state = stateRenameFieldPrompt
+		return m, nil
+	}
+
+	var cmd tea.Cmd
+	m.schemaEditor, cmd = m.schemaEditor.Update(msg)
+	return m, cmd
+}
+
+// handleRenameFieldPrompt asks for the field to rename, then its new name,
+// and renames it in the editor with the old name kept as an alias
+func (m *Model) handleRenameFieldPrompt(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.renamePrompt.Update(msg)
+	m.renamePrompt = newModel.(TextPromptModel)
+	if !m.renamePrompt.Quit() {
+		return m, cmd
+	}
+
+	value := strings.TrimSpace(m.renamePrompt.Value())
+	if !m.renamePrompt.Saved() || value == "" {
+		m.state = stateEditingSchema
+		return m, nil
+	}
+	if m.renameFrom == "" {
+		m.renameFrom = value
+		m.renamePrompt = NewTextPrompt("Rename Field", fmt.Sprintf("New name for %s; the old name is added to its aliases", value), "")
+		return m, nil
+	}
+
+	m.state = stateEditingSchema
+	renamed, paths, err := avro.RenameField(m.schemaEditor.Value(), m.renameFrom, value)
+	if err != nil {
+		m.err = fmt.Errorf("renaming %s: %w", m.renameFrom, err)
+		return m, nil
+	}
+	m.err = nil
+	m.schemaEditor.SetValue(registry.PrettyPrintSchema(renamed))
+	m.copyNotify = fmt.Sprintf("Renamed %s → %s (old name kept as an alias)", m.renameFrom, strings.Join(paths, ", "))
+	return m, nil
+}
+
+// editedSchema returns the editor's schema, compacted when it is valid JSON
+func (m Model) editedSchema() string {
+	return compactJSON(m.schemaEditor.Value())
+}
+
+// editedBaseline returns the viewed schema in the form editedSchema compares
+// against
+func (m Model) editedBaseline() string {
+	return compactJSON(m.rawSchema)
+}
+
+func compactJSON(s string) string {
+	var b bytes.Buffer
+	if err := json.Compact(&b, []byte(s)); err != nil {
+		return s
+	}
+	return b.String()
+}
+
+// registerSchema posts a schema as a new version of subject
+func registerSchema(client *registry.Client, subject, schema, schemaType string) tea.Cmd {
+	return func() tea.Msg {
+		id, err := client.RegisterSchema(subject, schema, schemaType)
+		return schemaRegisteredMsg{subject: subject, schema: schema, id: id, err: err}
+	}
+}
+
+func (m *Model) handleSchemaRegistered(msg schemaRegisteredMsg) tea.Cmd {
+	if msg.err != nil {
+		m.statusMsg = fmt.Sprintf("[EDIT SCHEMA] %s  |  Ctrl+S register as a new version, Ctrl+R rename a field, Ctrl+T snippets, Esc cancel", msg.subject)
+		m.err = fmt.Errorf("registering %s: %w", msg.subject, msg.err)
+		return nil
+	}
+
+	m.err = nil
+	m.schemaEditor.Blur()
+	m.state = stateViewing
+	m.copyNotify = fmt.Sprintf("Registered %s: schema ID %d", msg.subject, msg.id)
+	return tea.Batch(
+		m.loadSchema(msg.subject),
+		m.fireHook(hooks.SchemaRegistered, map[string]interface{}{
+			"subject":   msg.subject,
+			"schema_id": msg.id,
+			"schema":    msg.schema,
+		}),
+	)
+}
+
+// renderSchemaEditor renders the editor and, once Ctrl+S is pressed, the
+// registration prompt
+func (m Model) renderSchemaEditor(width, height int) string {
+	var b strings.Builder
+	b.WriteString(EditTitleStyle.Render("Edit Schema"))
+	b.WriteString("\n")
+	b.WriteString(SelectedItemStyle.Render(fmt.Sprintf("→ New version of %s (latest v%d, ID %d)", m.selectedSubject, m.schemaVersion, m.schemaID)))
+	b.WriteString("\n\n")
+
+	// A naming policy in warn mode is shown with the prompt
+	warning, _ := m.cfg.CheckSubjectName(m.selectedSubject)
+
+	editorHeight := height - 6
+	if m.confirmRegister {
+		editorHeight -= 2
+		if warning != "" {
+			editorHeight--
+		}
+	}
+	m.schemaEditor.SetWidth(width - 2)
+	m.schemaEditor.SetHeight(max(editorHeight, 3))
+	b.WriteString(m.schemaEditor.View())
+
+	if m.confirmRegister {
+		b.WriteString("\n\n")
+		if warning != "" {
+			b.WriteString(DiffChangedStyle.Render("⚠ "+warning) + "\n")
+		}
+		prompt := fmt.Sprintf("Register this schema as a new version of %s? [y] Register  [n] Keep editing", m.selectedSubject)
+		if m.cfg.Production {
+			b.WriteString(ErrorStyle.Render("PRODUCTION  " + prompt))
+		} else {
+			b.WriteString(DiffChangedStyle.Render(prompt))
+		}
+	}
+	return b.String()
+}
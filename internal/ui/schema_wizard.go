@@ -0,0 +1,375 @@
+package ui
+
+import (
+	"encoding/json"
+	"fmt"
+	"os"
+	"path/filepath"
+	"strings"
+	"unicode"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/snippet"
+)
+
+// Rows of the schema wizard: the record's, then the field being added
+const (
+	wizardNamespace = iota
+	wizardRecordName
+	wizardRecordDoc
+	wizardFieldName
+	wizardFieldType
+	wizardFieldNullable
+	wizardFieldDefault
+	wizardFieldDoc
+)
+
+// SchemaWizardModel builds a new record schema from a form, one field at a
+// time
+type SchemaWizardModel struct {
+	fields     []formField
+	nullable   bool
+	added      []avro.ScaffoldField
+	focusedIdx int
+	err        error
+	schema     string
+	saved      bool
+	quit       bool
+}
+
+// NewSchemaWizard creates an empty schema wizard
+func NewSchemaWizard() SchemaWizardModel {
+	return SchemaWizardModel{
+		fields: []formField{
+			{label: "Namespace", placeholder: "e.g., com.acme.orders"},
+			{label: "Record Name", placeholder: "e.g., OrderPlaced"},
+			{label: "Record Doc", placeholder: "What one record describes"},
+			{label: "Field Name", placeholder: "e.g., order_id"},
+			{label: "Type", value: "string", placeholder: avro.ScaffoldTypes},
+			{label: "Nullable"},
+			{label: "Default", placeholder: "JSON, or text for strings (empty: none, or null if nullable)"},
+			{label: "Field Doc", placeholder: "What the field holds"},
+		},
+	}
+}
+
+func (m SchemaWizardModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m SchemaWizardModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	switch msg := msg.(type) {
+	case tea.KeyMsg:
+		m.err = nil
+		switch msg.String() {
+		case "esc":
+			m.quit = true
+			return m, nil
+		case "tab", "down":
+			m.focusedIdx = (m.focusedIdx + 1) % len(m.fields)
+		case "shift+tab", "up":
+			m.focusedIdx = (m.focusedIdx + len(m.fields) - 1) % len(m.fields)
+		case "enter":
+			if m.focusedIdx == len(m.fields)-1 {
+				m.addField()
+				return m, nil
+			}
+			m.focusedIdx++
+		case "ctrl+d":
+			// Take back the last field added, to fix it; snippet fields are
+			// just removed
+			if n := len(m.added); n > 0 {
+				if m.added[n-1].Definition != "" {
+					m.added = m.added[:n-1]
+					return m, nil
+				}
+				m.editField(m.added[n-1])
+				m.added = m.added[:n-1]
+			}
+		case "ctrl+s":
+			m.finish()
+		default:
+			if m.focusedIdx == wizardFieldNullable {
+				switch msg.String() {
+				case " ":
+					m.nullable = !m.nullable
+				case "y":
+					m.nullable = true
+				case "n":
+					m.nullable = false
+				}
+				return m, nil
+			}
+			field := &m.fields[m.focusedIdx]
+			if msg.Type == tea.KeyRunes || msg.Type == tea.KeySpace {
+				field.value += string(msg.Runes)
+			} else if msg.String() == "backspace" {
+				if len(field.value) > 0 {
+					runes := []rune(field.value)
+					field.value = string(runes[:len(runes)-1])
+				}
+			} else if msg.String() == "ctrl+u" {
+				field.value = ""
+			}
+		}
+	}
+	return m, nil
+}
+
+// pending returns the field being entered
+func (m SchemaWizardModel) pending() avro.ScaffoldField {
+	return avro.ScaffoldField{
+		Name:     strings.TrimSpace(m.fields[wizardFieldName].value),
+		Type:     strings.TrimSpace(m.fields[wizardFieldType].value),
+		Nullable: m.nullable,
+		Default:  strings.TrimSpace(m.fields[wizardFieldDefault].value),
+		Doc:      strings.TrimSpace(m.fields[wizardFieldDoc].value),
+	}
+}
+
+// addField checks the field being entered and adds it to the record,
+// clearing the rows for the next one
+func (m *SchemaWizardModel) addField() {
+	field := m.pending()
+	if field.Name == "" {
+		m.err = fmt.Errorf("enter a field name first")
+		m.focusedIdx = wizardFieldName
+		return
+	}
+	scaffold := m.scaffold()
+	scaffold.Fields = append(scaffold.Fields, field)
+	if _, err := scaffold.Schema(); err != nil {
+		m.err = err
+		return
+	}
+	m.added = append(m.added, field)
+	m.editField(avro.ScaffoldField{Type: "string"})
+	m.focusedIdx = wizardFieldName
+}
+
+// AddSnippet adds a snippet's fields to the record
+func (m *SchemaWizardModel) AddSnippet(s snippet.Snippet) {
+	fields, err := avro.ParseFields(s.Fields)
+	if err != nil {
+		m.err = fmt.Errorf("snippet %s: %w", s.Name, err)
+		return
+	}
+	scaffold := m.scaffold()
+	var added []avro.ScaffoldField
+	for _, f := range fields {
+		definition, err := json.Marshal(f)
+		if err != nil {
+			m.err = err
+			return
+		}
+		name, _ := f.(map[string]interface{})["name"].(string)
+		added = append(added, avro.ScaffoldField{Name: name, Type: "snippet " + s.Name, Definition: string(definition)})
+	}
+	scaffold.Fields = append(scaffold.Fields, added...)
+	if _, err := scaffold.Schema(); err != nil {
+		m.err = err
+		return
+	}
+	m.added = append(m.added, added...)
+}
+
+// editField fills the field rows
+func (m *SchemaWizardModel) editField(f avro.ScaffoldField) {
+	m.fields[wizardFieldName].value = f.Name
+	m.fields[wizardFieldType].value = f.Type
+	m.fields[wizardFieldDefault].value = f.Default
+	m.fields[wizardFieldDoc].value = f.Doc
+	m.nullable = f.Nullable
+}
+
+// finish builds the schema from the fields added, and the one being entered
+// if it has a name
+func (m *SchemaWizardModel) finish() {
+	scaffold := m.scaffold()
+	if field := m.pending(); field.Name != "" {
+		scaffold.Fields = append(scaffold.Fields, field)
+	}
+	schema, err := scaffold.Schema()
+	if err != nil {
+		m.err = err
+		return
+	}
+	m.schema = schema
+	m.saved = true
+	m.quit = true
+}
+
+func (m SchemaWizardModel) scaffold() avro.Scaffold {
+	return avro.Scaffold{
+		Namespace: strings.TrimSpace(m.fields[wizardNamespace].value),
+		Name:      strings.TrimSpace(m.fields[wizardRecordName].value),
+		Doc:       strings.TrimSpace(m.fields[wizardRecordDoc].value),
+		Fields:    append([]avro.ScaffoldField(nil), m.added...),
+	}
+}
+
+func (m SchemaWizardModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render("New Schema") + "\n\n"
+
+	for i, field := range m.fields {
+		if i == wizardFieldName {
+			s += "\n" + lipgloss.NewStyle().Bold(true).Render(fmt.Sprintf("Field %d", len(m.added)+1)) + "\n"
+		}
+		prefix := "  "
+		if i == m.focusedIdx {
+			prefix = "> "
+		}
+
+		label := lipgloss.NewStyle().Width(14).Render(field.label + ":")
+		value := field.value
+		if i == wizardFieldNullable {
+			value = "[ ] no"
+			if m.nullable {
+				value = "[x] yes"
+			}
+		} else if value == "" {
+			value = lipgloss.NewStyle().Faint(true).Render(field.placeholder)
+		}
+
+		if i == m.focusedIdx {
+			s += lipgloss.NewStyle().
+				Foreground(lipgloss.Color("11")).
+				Bold(true).
+				Render(prefix+label+" "+value) + "\n"
+		} else {
+			s += prefix + label + " " + value + "\n"
+		}
+	}
+
+	if len(m.added) > 0 {
+		s += "\n" + lipgloss.NewStyle().Bold(true).Render("Fields") + "\n"
+		for _, f := range m.added {
+			line := fmt.Sprintf("  %s: %s", f.Name, f.Type)
+			if f.Nullable {
+				line += " | null"
+			}
+			if f.Default != "" {
+				line += " = " + f.Default
+			}
+			s += line + "\n"
+		}
+	}
+
+	s += "\n"
+	if m.err != nil {
+		s += ErrorStyle.Render("Error: "+m.err.Error()) + "\n"
+	}
+	if m.focusedIdx == wizardFieldType {
+		s += lipgloss.NewStyle().Faint(true).Render("Types: "+avro.ScaffoldTypes) + "\n"
+	}
+	s += lipgloss.NewStyle().Faint(true).Render("[tab] Next  [space] Toggle nullable  [enter] Next / Add field  [ctrl+d] Edit last field  [ctrl+t] Snippets  [ctrl+s] Done  [esc] Cancel") + "\n"
+	return s
+}
+
+// Schema returns the finished schema
+func (m SchemaWizardModel) Schema() string {
+	return m.schema
+}
+
+// RecordName returns the record's name
+func (m SchemaWizardModel) RecordName() string {
+	return strings.TrimSpace(m.fields[wizardRecordName].value)
+}
+
+// Saved returns whether the wizard finished with a schema
+func (m SchemaWizardModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the wizard is closed
+func (m SchemaWizardModel) Quit() bool {
+	return m.quit
+}
+
+// enterSchemaWizard opens the new schema wizard
+func (m *Model) enterSchemaWizard() {
+	m.schemaWizard = NewSchemaWizard()
+	m.scaffoldOverwrite = ""
+	m.state = stateSchemaWizard
+}
+
+func (m *Model) handleSchemaWizard(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	if msg.String() == "ctrl+t" {
+		m.enterSnippetPicker()
+		return m, nil
+	}
+	newModel, cmd := m.schemaWizard.Update(msg)
+	m.schemaWizard = newModel.(SchemaWizardModel)
+	if !m.schemaWizard.Quit() {
+		return m, cmd
+	}
+
+	m.leaveSchemaWizard()
+	if !m.schemaWizard.Saved() {
+		return m, nil
+	}
+	path := subjectFileName(m.schemaWizard.RecordName()) + "-value.avsc"
+	m.scaffoldPrompt = NewPathPrompt("Save New Schema", "Named after its subject: opened as a local schema, R registers it", path)
+	m.state = stateSavingScaffold
+	return m, nil
+}
+
+func (m *Model) handleSavingScaffold(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.scaffoldPrompt.Update(msg)
+	m.scaffoldPrompt = newModel.(TextPromptModel)
+	if !m.scaffoldPrompt.Quit() {
+		return m, cmd
+	}
+
+	m.leaveSchemaWizard()
+	path := m.scaffoldPrompt.Value()
+	if !m.scaffoldPrompt.Saved() || path == "" {
+		return m, nil
+	}
+
+	// Ask again before replacing an existing file
+	if _, err := os.Stat(path); err == nil && path != m.scaffoldOverwrite {
+		m.scaffoldOverwrite = path
+		m.scaffoldPrompt = NewPathPrompt("Save New Schema", path+" exists: press enter again to overwrite it", path)
+		m.state = stateSavingScaffold
+		return m, nil
+	}
+
+	if err := os.WriteFile(path, []byte(m.schemaWizard.Schema()+"\n"), 0644); err != nil {
+		m.err = fmt.Errorf("saving schema: %w", err)
+		return m, nil
+	}
+	m.copyNotify = fmt.Sprintf("Saved %s", filepath.Base(path))
+	return m, readLocalSchema(path, false)
+}
+
+// leaveSchemaWizard returns to the state the wizard was opened from
+func (m *Model) leaveSchemaWizard() {
+	m.state = stateBrowsing
+	if m.currentSchema != "" {
+		m.state = stateViewing
+	}
+}
+
+// subjectFileName turns a record name into a subject-style file name,
+// OrderPlaced becoming order-placed
+func subjectFileName(name string) string {
+	var b strings.Builder
+	runes := []rune(name)
+	for i, r := range runes {
+		if unicode.IsUpper(r) {
+			if i > 0 && (unicode.IsLower(runes[i-1]) || (i+1 < len(runes) && unicode.IsLower(runes[i+1]))) {
+				b.WriteRune('-')
+			}
+			r = unicode.ToLower(r)
+		} else if r == '_' {
+			r = '-'
+		}
+		b.WriteRune(r)
+	}
+	return b.String()
+}
@@ -0,0 +1,174 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	"github.com/atotto/clipboard"
+)
+
+// handleVisual processes visual-line selection in the schema viewer.
+// "V" starts selecting at the top visible line, j/k (with counts) extend the
+// selection, "a" expands it to the enclosing JSON object, "y" copies the
+// selected lines and esc or "V" cancels. Returns false if the key was not
+// handled, which only happens while not selecting.
+func (m *Model) handleVisual(key string) bool {
+	if !m.visual {
+		if key != "V" {
+			return false
+		}
+		m.visual = true
+		m.visualAnchor = m.viewer.YOffset
+		m.visualCursor = m.viewer.YOffset
+		m.count = 0
+		return true
+	}
+
+	if len(key) == 1 && key[0] >= '0' && key[0] <= '9' && (key != "0" || m.count > 0) {
+		if m.count*10+int(key[0]-'0') <= maxCount {
+			m.count = m.count*10 + int(key[0]-'0')
+		}
+		return true
+	}
+	count := m.count
+	if count == 0 {
+		count = 1
+	}
+	m.count = 0
+
+	lines := strings.Split(m.currentSchema, "\n")
+
+	switch key {
+	case "esc", "V":
+		m.visual = false
+	case "j", "down":
+		m.moveVisualCursor(m.visualCursor+count, len(lines))
+	case "k", "up":
+		m.moveVisualCursor(m.visualCursor-count, len(lines))
+	case "g":
+		m.moveVisualCursor(0, len(lines))
+	case "G":
+		m.moveVisualCursor(len(lines)-1, len(lines))
+	case "a":
+		from, to := selectionBounds(m.visualAnchor, m.visualCursor)
+		if start, end, ok := enclosingBlock(lines, from, to); ok {
+			m.visualAnchor = start
+			m.moveVisualCursor(end, len(lines))
+		}
+	case "y":
+		from, to := selectionBounds(m.visualAnchor, m.visualCursor)
+		if err := clipboard.WriteAll(joinLines(lines, from, to)); err != nil {
+			m.err = fmt.Errorf("failed to copy: %w", err)
+		} else {
+			m.copyNotify = fmt.Sprintf("Copied lines %d-%d to clipboard!", from+1, to+1)
+		}
+		m.visual = false
+	}
+	return true
+}
+
+// moveVisualCursor moves the selection cursor and scrolls to keep it visible
+func (m *Model) moveVisualCursor(line, total int) {
+	if line >= total {
+		line = total - 1
+	}
+	if line < 0 {
+		line = 0
+	}
+	m.visualCursor = line
+
+	if line < m.viewer.YOffset {
+		m.viewer.SetYOffset(line)
+	} else if m.viewer.Height > 0 && line >= m.viewer.YOffset+m.viewer.Height {
+		m.viewer.SetYOffset(line - m.viewer.Height + 1)
+	}
+}
+
+// visualContent returns the schema with the selected lines highlighted
+func (m Model) visualContent() string {
+	lines := strings.Split(m.currentSchema, "\n")
+	from, to := selectionBounds(m.visualAnchor, m.visualCursor)
+	for i := from; i <= to && i < len(lines); i++ {
+		lines[i] = VisualLineStyle.Render(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// toggleEditorSelection starts or cancels a line selection in the send mode
+// editor, anchored at the editor's cursor line
+func (m *Model) toggleEditorSelection() {
+	if m.editorSelecting {
+		m.editorSelecting = false
+		m.statusMsg = "[SEND MODE] Selection cancelled"
+		return
+	}
+	m.editorSelecting = true
+	m.editorAnchor = m.editor.Line()
+	m.updateEditorSelectionStatus()
+}
+
+// copyEditorSelection copies the selected editor lines and ends the selection
+func (m *Model) copyEditorSelection() {
+	from, to := selectionBounds(m.editorAnchor, m.editor.Line())
+	lines := strings.Split(m.editor.Value(), "\n")
+	if err := clipboard.WriteAll(joinLines(lines, from, to)); err != nil {
+		m.err = fmt.Errorf("failed to copy: %w", err)
+	} else {
+		m.copyNotify = fmt.Sprintf("Copied lines %d-%d to clipboard!", from+1, to+1)
+	}
+	m.editorSelecting = false
+}
+
+func (m *Model) updateEditorSelectionStatus() {
+	from, to := selectionBounds(m.editorAnchor, m.editor.Line())
+	m.statusMsg = fmt.Sprintf("[SEND MODE] -- VISUAL LINE -- lines %d-%d  |  y copy, alt+v/Esc cancel", from+1, to+1)
+}
+
+// selectionBounds orders an anchor and cursor into an inclusive line range
+func selectionBounds(anchor, cursor int) (int, int) {
+	if anchor > cursor {
+		return cursor, anchor
+	}
+	return anchor, cursor
+}
+
+// joinLines returns lines[from..to] inclusive, clamped to the slice
+func joinLines(lines []string, from, to int) string {
+	if to >= len(lines) {
+		to = len(lines) - 1
+	}
+	if from < 0 || from > to {
+		return ""
+	}
+	return strings.Join(lines[from:to+1], "\n")
+}
+
+// enclosingBlock finds the smallest JSON object or array in pretty-printed
+// text that strictly contains the lines from..to. It relies on the consistent
+// indentation of json.MarshalIndent output: an opening line ending in { or [
+// is closed by the next line at the same indentation starting with } or ].
+func enclosingBlock(lines []string, from, to int) (int, int, bool) {
+	for start := from; start >= 0; start-- {
+		trimmed := strings.TrimSpace(lines[start])
+		if !strings.HasSuffix(trimmed, "{") && !strings.HasSuffix(trimmed, "[") {
+			continue
+		}
+
+		indent := indentOf(lines[start])
+		for end := start + 1; end < len(lines); end++ {
+			closing := strings.TrimSpace(lines[end])
+			if indentOf(lines[end]) == indent && (strings.HasPrefix(closing, "}") || strings.HasPrefix(closing, "]")) {
+				// Only accept blocks that grow the current selection
+				if end >= to && (start < from || end > to) {
+					return start, end, true
+				}
+				break
+			}
+		}
+	}
+	return 0, 0, false
+}
+
+func indentOf(line string) int {
+	return len(line) - len(strings.TrimLeft(line, " \t"))
+}
@@ -0,0 +1,91 @@
+package ui
+
+import (
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/session"
+)
+
+const (
+	defaultListPercent = 33
+	minListPercent     = 15
+	maxListPercent     = 70
+	listPercentStep    = 5
+)
+
+// RestoreSession queues a saved session to be applied once subjects are loaded
+func (m *Model) RestoreSession(state *session.State) {
+	if state == nil {
+		return
+	}
+	if state.ListPercent >= minListPercent && state.ListPercent <= maxListPercent {
+		m.listPercent = state.ListPercent
+	}
+	m.restore = state
+}
+
+// Session returns a snapshot of the current UI context for persistence
+func (m Model) Session() *session.State {
+	state := &session.State{
+		Profile:     m.cfg.Profile,
+		Subject:     m.selectedSubject,
+		Filter:      m.searchInput.Value(),
+		ListPercent: m.listPercent,
+		FocusedPane: "list",
+		View:        "schema",
+	}
+	if m.focusedPane == viewerPane {
+		state.FocusedPane = "viewer"
+	}
+	if m.state == stateConsumerMode {
+		state.View = "consumer"
+	}
+	return state
+}
+
+// applyRestoredSession re-applies the saved filter and subject after subjects load.
+// Returns a command to load the restored subject's schema, if any.
+func (m *Model) applyRestoredSession() tea.Cmd {
+	state := m.restore
+	m.restore = nil
+	if state == nil {
+		return nil
+	}
+
+	if state.Filter != "" {
+		m.searchInput.SetValue(state.Filter)
+		m.filterSubjects()
+	}
+	if state.FocusedPane == "viewer" {
+		m.focusedPane = viewerPane
+	}
+
+	for i, s := range m.filteredSubjects {
+		if s == state.Subject {
+			m.selectedIndex = i
+			m.selectedSubject = s
+			m.restoreConsumer = state.View == "consumer"
+			return m.loadSchema(s)
+		}
+	}
+
+	// A deep link opens its subject even if the list doesn't show it
+	if m.link != nil && state.Subject != "" {
+		m.selectedSubject = state.Subject
+		m.restoreConsumer = state.View == "consumer"
+		return m.loadSchema(state.Subject)
+	}
+
+	return nil
+}
+
+// resizeList grows or shrinks the subjects pane by delta percent
+func (m *Model) resizeList(delta int) {
+	m.listPercent += delta
+	if m.listPercent < minListPercent {
+		m.listPercent = minListPercent
+	}
+	if m.listPercent > maxListPercent {
+		m.listPercent = maxListPercent
+	}
+}
@@ -0,0 +1,225 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+	"github.com/JimmyyyW/avrocado/internal/snippet"
+)
+
+// SnippetPickerModel picks a field snippet to insert
+type SnippetPickerModel struct {
+	snippets []snippet.Snippet
+	cursor   int
+	canSave  bool // Fields can be saved from the schema the picker was opened on
+	action   string
+	quit     bool
+}
+
+// Snippet picker actions
+const (
+	snippetInsert = "insert"
+	snippetSave   = "save"
+	snippetDelete = "delete"
+)
+
+// NewSnippetPicker lists the snippets in a store
+func NewSnippetPicker(store *snippet.Store, canSave bool) SnippetPickerModel {
+	return SnippetPickerModel{snippets: store.List(), canSave: canSave}
+}
+
+func (m SnippetPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m SnippetPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "esc", "q":
+		m.quit = true
+	case "j", "down":
+		if m.cursor < len(m.snippets)-1 {
+			m.cursor++
+		}
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "enter":
+		if len(m.snippets) > 0 {
+			m.action = snippetInsert
+			m.quit = true
+		}
+	case "s":
+		if m.canSave {
+			m.action = snippetSave
+			m.quit = true
+		}
+	case "d":
+		if len(m.snippets) > 0 && !m.snippets[m.cursor].Builtin {
+			m.action = snippetDelete
+			m.quit = true
+		}
+	}
+	return m, nil
+}
+
+func (m SnippetPickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Field Snippets") + "\n\n")
+
+	for i, s := range m.snippets {
+		line := s.Name
+		if s.Builtin {
+			line += " (built in)"
+		}
+		if s.Description != "" {
+			line += " - " + s.Description
+		}
+		if i == m.cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(NormalItemStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	if len(m.snippets) > 0 {
+		b.WriteString("\n")
+		b.WriteString(HelpStyle.Render(m.snippets[m.cursor].Fields) + "\n")
+	}
+
+	b.WriteString("\n")
+	help := "[j/k] Move  [enter] Insert  [d] Delete  [esc] Cancel"
+	if m.canSave {
+		help = "[j/k] Move  [enter] Insert  [s] Save fields as a snippet  [d] Delete  [esc] Cancel"
+	}
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render(help) + "\n")
+	return b.String()
+}
+
+// Action returns what was chosen, empty if the picker was cancelled
+func (m SnippetPickerModel) Action() string {
+	return m.action
+}
+
+// Selected returns the snippet under the cursor
+func (m SnippetPickerModel) Selected() snippet.Snippet {
+	return m.snippets[m.cursor]
+}
+
+// Quit returns whether the picker is closed
+func (m SnippetPickerModel) Quit() bool {
+	return m.quit
+}
+
+// enterSnippetPicker opens the snippet picker over the schema editor or the
+// schema wizard
+func (m *Model) enterSnippetPicker() {
+	m.snippetReturn = m.state
+	m.snippetPicker = NewSnippetPicker(m.snippets, m.state == stateEditingSchema)
+	m.state = stateSnippetPicker
+}
+
+func (m *Model) handleSnippetPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.snippetPicker.Update(msg)
+	m.snippetPicker = newModel.(SnippetPickerModel)
+	if !m.snippetPicker.Quit() {
+		return m, cmd
+	}
+
+	m.state = m.snippetReturn
+	switch m.snippetPicker.Action() {
+	case snippetInsert:
+		m.insertSnippet(m.snippetPicker.Selected())
+	case snippetDelete:
+		name := m.snippetPicker.Selected().Name
+		if err := m.snippets.Delete(name); err != nil {
+			m.err = err
+		} else {
+			m.copyNotify = fmt.Sprintf("Deleted snippet %s", name)
+		}
+	case snippetSave:
+		m.snippetDraft = snippet.Snippet{}
+		m.snippetPrompt = NewTextPrompt("Save Snippet: Fields", "Top-level fields of the edited schema to save, comma-separated", "")
+		m.state = stateSavingSnippet
+	}
+	return m, nil
+}
+
+// insertSnippet adds a snippet's fields to the schema being edited or built
+func (m *Model) insertSnippet(s snippet.Snippet) {
+	if m.state == stateSchemaWizard {
+		m.schemaWizard.AddSnippet(s)
+		return
+	}
+
+	schema, err := avro.InsertFields(m.schemaEditor.Value(), s.Fields)
+	if err != nil {
+		m.err = fmt.Errorf("inserting snippet %s: %w", s.Name, err)
+		return
+	}
+	m.schemaEditor.SetValue(registry.PrettyPrintSchema(schema))
+	m.copyNotify = fmt.Sprintf("Inserted snippet %s", s.Name)
+}
+
+// handleSavingSnippet asks for the fields, then the name and description,
+// of a new snippet
+func (m *Model) handleSavingSnippet(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.snippetPrompt.Update(msg)
+	m.snippetPrompt = newModel.(TextPromptModel)
+	if !m.snippetPrompt.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateEditingSchema
+	value := m.snippetPrompt.Value()
+	if !m.snippetPrompt.Saved() {
+		return m, nil
+	}
+
+	switch {
+	case m.snippetDraft.Fields == "":
+		var names []string
+		for _, name := range strings.Split(value, ",") {
+			if name = strings.TrimSpace(name); name != "" {
+				names = append(names, name)
+			}
+		}
+		if len(names) == 0 {
+			return m, nil
+		}
+		fields, err := avro.ExtractFields(m.schemaEditor.Value(), names)
+		if err != nil {
+			m.err = err
+			return m, nil
+		}
+		m.snippetDraft.Fields = fields
+		m.snippetPrompt = NewTextPrompt("Save Snippet: Name", "Saving an existing name replaces that snippet", "")
+		m.state = stateSavingSnippet
+
+	case m.snippetDraft.Name == "":
+		if value == "" {
+			return m, nil
+		}
+		m.snippetDraft.Name = value
+		m.snippetPrompt = NewTextPrompt("Save Snippet: Description", "Optional, shown in the snippet list", "")
+		m.state = stateSavingSnippet
+
+	default:
+		m.snippetDraft.Description = value
+		if err := m.snippets.Save(m.snippetDraft); err != nil {
+			m.err = fmt.Errorf("saving snippet: %w", err)
+			return m, nil
+		}
+		m.copyNotify = fmt.Sprintf("Saved snippet %s", m.snippetDraft.Name)
+	}
+	return m, nil
+}
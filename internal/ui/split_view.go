@@ -0,0 +1,152 @@
+package ui
+
+import (
+	"fmt"
+	"regexp"
+	"strings"
+)
+
+// fieldKeyPattern matches a JSON line that starts with an object key
+var fieldKeyPattern = regexp.MustCompile(`^\s*"((?:[^"\\]|\\.)*)"\s*:`)
+
+// lineField returns the key a JSON line starts with, if any
+func lineField(line string) string {
+	match := fieldKeyPattern.FindStringSubmatch(line)
+	if match == nil {
+		return ""
+	}
+	return match[1]
+}
+
+// fieldLines returns the indices of the lines that start with a key
+func fieldLines(lines []string) []int {
+	var indices []int
+	for i, line := range lines {
+		if lineField(line) != "" {
+			indices = append(indices, i)
+		}
+	}
+	return indices
+}
+
+// toggleSplitView shows the schema beside the consumed message or the
+// payload being edited, in place of the list pane
+func (m *Model) toggleSplitView() {
+	if m.currentSchema == "" {
+		return
+	}
+	m.splitView = !m.splitView
+	m.splitField = 0
+	if !m.splitView {
+		m.copyNotify = "Split view off"
+	} else if m.state == stateConsumerMode {
+		m.copyNotify = "Split view: [ and ] move between the message's fields"
+	} else {
+		m.copyNotify = "Split view: the field under the cursor is highlighted in the schema"
+	}
+}
+
+// moveSplitField moves the highlighted field of the consumed message
+func (m *Model) moveSplitField(delta int) {
+	if !m.splitView || len(m.consumedMessages) == 0 {
+		return
+	}
+	lines := strings.Split(m.messageValue(m.currentMsgIdx), "\n")
+	count := len(fieldLines(lines))
+	if count == 0 {
+		return
+	}
+	m.splitField = (m.splitField + delta + count) % count
+}
+
+// messageFieldLine returns the line of the consumed value the split view
+// highlights, -1 for none
+func (m Model) messageFieldLine(lines []string) int {
+	indices := fieldLines(lines)
+	if len(indices) == 0 {
+		return -1
+	}
+	return indices[min(m.splitField, len(indices)-1)]
+}
+
+// splitFocusField returns the field the split view highlights: the key under
+// the send editor's cursor, or the one picked in the consumed message
+func (m Model) splitFocusField() string {
+	switch m.state {
+	case stateSendMode, stateSending:
+		lines := strings.Split(m.editor.Value(), "\n")
+		if row := m.editor.Line(); row < len(lines) {
+			return lineField(lines[row])
+		}
+	case stateConsumerMode:
+		if len(m.consumedMessages) == 0 {
+			return ""
+		}
+		lines := strings.Split(m.messageValue(m.currentMsgIdx), "\n")
+		if i := m.messageFieldLine(lines); i >= 0 {
+			return lineField(lines[i])
+		}
+	}
+	return ""
+}
+
+// highlightMessageField marks the highlighted field of a decoded value
+func (m Model) highlightMessageField(value string) string {
+	lines := strings.Split(value, "\n")
+	if i := m.messageFieldLine(lines); i >= 0 {
+		lines[i] = VisualLineStyle.Render(lines[i])
+	}
+	return strings.Join(lines, "\n")
+}
+
+// schemaFieldLine reports whether a schema line declares field: an Avro
+// field's name, or a JSON Schema property
+func schemaFieldLine(line, field string) bool {
+	quoted := fmt.Sprintf("%q", field)
+	trimmed := strings.TrimSpace(line)
+	return strings.HasPrefix(trimmed, `"name": `+quoted) ||
+		(lineField(line) == field && strings.HasSuffix(trimmed, "{"))
+}
+
+// renderSplitSchema renders the schema pane of the split view, scrolled to
+// the highlighted field
+func (m Model) renderSplitSchema(width, height int) string {
+	var b strings.Builder
+	b.WriteString(ListTitleStyle.Render("Schema: " + m.selectedSubject))
+	b.WriteString("\n")
+
+	field := m.splitFocusField()
+	if field == "" {
+		b.WriteString(HelpStyle.Render("No field selected"))
+	} else {
+		b.WriteString(HelpStyle.Render("Field: " + field))
+	}
+	b.WriteString("\n\n")
+
+	lines := strings.Split(m.currentSchema, "\n")
+	first := -1
+	if field != "" {
+		for i, line := range lines {
+			if schemaFieldLine(line, field) {
+				if first < 0 {
+					first = i
+				}
+				lines[i] = VisualLineStyle.Render(line)
+			}
+		}
+	}
+
+	visible := max(1, height-4)
+	start := 0
+	if first >= visible/2 {
+		start = min(first-visible/2, max(0, len(lines)-visible))
+	}
+	end := min(len(lines), start+visible)
+	for _, line := range lines[start:end] {
+		if width > 3 && len([]rune(line)) > width-2 && !strings.Contains(line, "\x1b") {
+			line = string([]rune(line)[:width-3]) + "…"
+		}
+		b.WriteString(line + "\n")
+	}
+	return b.String()
+}
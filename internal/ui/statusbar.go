@@ -0,0 +1,191 @@
+package ui
+
+import (
+	"fmt"
+	"net/url"
+	"strings"
+
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+)
+
+// modeName returns the short mode indicator shown at the left of the status bar
+func (m Model) modeName() string {
+	if m.visual || m.editorSelecting {
+		return "VISUAL"
+	}
+
+	switch m.state {
+	case stateLoading:
+		return "LOADING"
+	case stateSearching:
+		return "SEARCH"
+	case stateViewing:
+		return "VIEW"
+	case stateSendMode:
+		return "SEND"
+	case stateSending:
+		return "SENDING"
+	case stateSavingEvent:
+		return "SAVE"
+	case stateLoadingEvent:
+		return "LOAD"
+	case stateConsumerMode:
+		return "CONSUME"
+	case stateReport:
+		return "REPORT"
+	case stateCopyAs:
+		return "COPY AS"
+	case stateContractPrompt:
+		return "CONTRACT"
+	case stateExportingCSV:
+		return "EXPORT"
+	case stateEditingColumns:
+		return "COLUMNS"
+	case stateBackfillForm:
+		return "BACKFILL"
+	case statePickingPayloadFile:
+		return "OPEN FILE"
+	case stateSavingPayloadFile:
+		return "SAVE FILE"
+	case statePickingFanout:
+		return "TOPICS"
+	case statePatchTool:
+		return "PATCH"
+	case stateProtocolBrowser:
+		return "PROTOCOL"
+	case stateMappingPrompt:
+		return "MAP FIELDS"
+	case stateVersionPicker:
+		return "VERSIONS"
+	case stateEditingSchema:
+		return "EDIT SCHEMA"
+	case stateLocalSchemaPrompt:
+		return "OPEN SCHEMA"
+	case stateRenameFieldPrompt:
+		return "RENAME FIELD"
+	case stateSchemaWizard:
+		return "NEW SCHEMA"
+	case stateSavingScaffold:
+		return "SAVE SCHEMA"
+	case stateSnippetPicker:
+		return "SNIPPETS"
+	case stateSavingSnippet:
+		return "SAVE SNIPPET"
+	case stateTopicPicker:
+		return "TOPIC"
+	case stateTopicConfig:
+		return "TOPIC CONFIG"
+	case stateDeletingSubject:
+		return "DELETE"
+	case stateReaderPicker:
+		return "READ AS"
+	case stateAnnotating:
+		return "NOTE"
+	case stateCompatibility:
+		return "COMPATIBILITY"
+	case stateProfileSwitcher:
+		return "PROFILE"
+	case stateUsagePrompt:
+		return "FIELD USAGE"
+	case stateQualityPrompt:
+		return "QUALITY"
+	default:
+		return "BROWSE"
+	}
+}
+
+// statusSegments returns the context segments: profile, registry host,
+// Kafka cluster, subject@version and consumer lag when tailing
+func (m Model) statusSegments() []string {
+	var segments []string
+
+	profile := m.cfg.Profile
+	if profile == "" {
+		profile = "env"
+	}
+	segments = append(segments, "profile: "+profile)
+
+	registryHost := m.cfg.RegistryURL
+	if u, err := url.Parse(m.cfg.RegistryURL); err == nil && u.Host != "" {
+		registryHost = u.Host
+	}
+	if m.cfg.RegistryFlavor == config.FlavorLocal {
+		registryHost = "local " + m.cfg.LocalSchemaDir
+	}
+	segments = append(segments, "registry: "+registryHost)
+
+	if m.producer == nil {
+		segments = append(segments, "kafka: not configured")
+	} else {
+		segments = append(segments, "kafka: "+kafkaCluster(m.cfg.KafkaBootstrapServers))
+	}
+
+	if m.selectedSubject != "" && m.currentSchema != "" {
+		segments = append(segments, fmt.Sprintf("%s@v%d", m.selectedSubject, m.schemaVersion))
+	}
+
+	if m.state == stateConsumerMode && m.consumerLag >= 0 && len(m.consumedMessages) > 0 {
+		segments = append(segments, fmt.Sprintf("lag: %d", m.consumerLag))
+	}
+	if m.state == stateConsumerMode && m.tailing {
+		segments = append(segments, "tailing")
+	}
+
+	return segments
+}
+
+// kafkaCluster shortens a bootstrap server list to the first broker
+func kafkaCluster(servers string) string {
+	brokers := strings.Split(servers, ",")
+	first := strings.TrimSpace(brokers[0])
+	if len(brokers) > 1 {
+		return fmt.Sprintf("%s (+%d)", first, len(brokers)-1)
+	}
+	return first
+}
+
+func (m Model) renderStatusBar() string {
+	var status string
+
+	if m.copyNotify != "" {
+		status = SuccessStyle.Render(m.copyNotify)
+	} else if throttled := m.throttleStatus(); throttled != "" {
+		status = DiffChangedStyle.Render(throttled)
+	} else if m.err != nil {
+		status = ErrorStyle.Render(fmt.Sprintf("Error: %v", m.err))
+	} else if strings.HasPrefix(m.statusMsg, "SUCCESS:") {
+		status = SuccessStyle.Render(m.statusMsg)
+	} else if m.statusMsg != "" {
+		status = m.statusMsg
+	} else {
+		status = "Ready"
+	}
+
+	// Show a pending count, g or mark prefix like vim's showcmd
+	if m.count > 0 || m.pendingG || m.pendingMark != "" {
+		pending := m.pendingMark
+		if m.count > 0 {
+			pending = fmt.Sprintf("%d", m.count)
+		}
+		if m.pendingG {
+			pending += "g"
+		}
+		status += "  " + HelpStyle.Render(pending)
+	}
+
+	mode := StatusModeStyle.Render(m.modeName())
+	context := StatusSegmentStyle.Render(strings.Join(m.statusSegments(), " │ "))
+	left := mode + context
+
+	// The message gets whatever width the context segments leave over
+	remaining := m.width - lipgloss.Width(left) - 4
+	if remaining < 0 {
+		remaining = 0
+	}
+	message := lipgloss.NewStyle().MaxWidth(remaining).Render(status)
+
+	bar := StatusBarStyle.Width(m.width).Render(left + "  " + message)
+	return bar
+}
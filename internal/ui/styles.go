@@ -7,6 +7,7 @@ var (
 	highlight = lipgloss.AdaptiveColor{Light: "#874BFD", Dark: "#7D56F4"}
 	special   = lipgloss.AdaptiveColor{Light: "#43BF6D", Dark: "#73F59F"}
 	editColor = lipgloss.AdaptiveColor{Light: "#FF8C00", Dark: "#FFA500"}
+	prodColor = lipgloss.AdaptiveColor{Light: "#D70000", Dark: "#FF3B3B"}
 
 	TitleStyle = lipgloss.NewStyle().
 			Bold(true).
@@ -65,4 +66,52 @@ var (
 	SuccessStyle = lipgloss.NewStyle().
 			Foreground(special).
 			Bold(true)
+
+	VisualLineStyle = lipgloss.NewStyle().
+			Background(highlight).
+			Foreground(lipgloss.Color("#FAFAFA"))
+
+	DiffAddedStyle = lipgloss.NewStyle().
+			Foreground(special).
+			Bold(true)
+
+	DiffRemovedStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("9")).
+				Bold(true)
+
+	DiffChangedStyle = lipgloss.NewStyle().
+				Foreground(editColor).
+				Bold(true)
+
+	DeprecatedBadgeStyle = lipgloss.NewStyle().
+				Foreground(editColor).
+				Bold(true)
+
+	DeprecatedBannerStyle = lipgloss.NewStyle().
+				Foreground(editColor).
+				Bold(true)
+
+	ReadOnlyBadgeStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("12"))
+
+	ReadOnlyBannerStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.Color("12")).
+				Bold(true)
+
+	BannerStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(prodColor).
+			Align(lipgloss.Center)
+
+	StatusModeStyle = lipgloss.NewStyle().
+			Bold(true).
+			Foreground(lipgloss.Color("#FAFAFA")).
+			Background(highlight).
+			Padding(0, 1)
+
+	StatusSegmentStyle = lipgloss.NewStyle().
+				Foreground(lipgloss.AdaptiveColor{Light: "#343433", Dark: "#C1C6B2"}).
+				Background(subtle).
+				Padding(0, 1)
 )
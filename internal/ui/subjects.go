@@ -0,0 +1,231 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/subjectstore"
+)
+
+// largeSubjectList is how many subjects are held in memory; registries with
+// more are moved to an on-disk store as they are streamed in
+const largeSubjectList = 5000
+
+// subjectQueryLimit caps how many matches the list shows for a store-backed
+// subject list
+const subjectQueryLimit = 1000
+
+func (m Model) loadSubjects() tea.Msg {
+	var subjects []string
+	var store *subjectstore.Store
+	var loader *subjectstore.Loader
+	var storeErr error
+
+	err := m.client.StreamSubjects(func(subject string) error {
+		if !m.cfg.SubjectAllowed(subject) {
+			return nil
+		}
+		if loader != nil {
+			return loader.Add(subject)
+		}
+		subjects = append(subjects, subject)
+		if len(subjects) < largeSubjectList || storeErr != nil {
+			return nil
+		}
+
+		// Too many to hold: move what we have to disk and continue there. If
+		// the store can't be opened (another avrocado has it), stay in memory.
+		s, err := subjectstore.Open(subjectstore.GetStorePath(m.cfg.Profile))
+		if err != nil {
+			storeErr = err
+			return nil
+		}
+		if loader, err = s.Load(); err != nil {
+			s.Close()
+			return err
+		}
+		store = s
+		for _, s := range subjects {
+			if err := loader.Add(s); err != nil {
+				return err
+			}
+		}
+		subjects = nil
+		return nil
+	})
+	if err == nil && loader != nil {
+		err = loader.Finish()
+	}
+	if err != nil && store != nil {
+		store.Close()
+		store = nil
+	}
+	return subjectsLoadedMsg{subjects: subjects, store: store, storeErr: storeErr, err: err}
+}
+
+// reloadSubjects loads the subject list again. The open store is closed
+// first: it holds the store file's lock, which the reload would otherwise
+// time out waiting for.
+func (m *Model) reloadSubjects() tea.Cmd {
+	if m.subjectStore != nil {
+		if err := m.subjectStore.Close(); err != nil {
+			m.err = fmt.Errorf("closing subject store: %w", err)
+		}
+		m.subjectStore = nil
+	}
+	return m.loadSubjects
+}
+
+// subjectFilter keeps lower-case subjects and the last query's matches, so
+// typing another character only rescans the previous matches
+type subjectFilter struct {
+	lower   []string // Parallel to Model.subjects
+	query   string
+	matches []int // Indices into Model.subjects, nil before the first query
+}
+
+// newSubjectFilter precomputes the lower-case forms of subjects
+func newSubjectFilter(subjects []string) subjectFilter {
+	lower := make([]string, len(subjects))
+	for i, s := range subjects {
+		lower[i] = strings.ToLower(s)
+	}
+	return subjectFilter{lower: lower}
+}
+
+// match returns the indices of subjects containing query, narrowing the
+// previous matches when query extends the previous query
+func (f *subjectFilter) match(query string) []int {
+	var matches []int
+	if f.matches != nil && strings.Contains(query, f.query) {
+		for _, i := range f.matches {
+			if strings.Contains(f.lower[i], query) {
+				matches = append(matches, i)
+			}
+		}
+	} else {
+		for i, s := range f.lower {
+			if strings.Contains(s, query) {
+				matches = append(matches, i)
+			}
+		}
+	}
+	if matches == nil {
+		matches = []int{}
+	}
+	f.query, f.matches = query, matches
+	return matches
+}
+
+// handleSubjectsLoaded installs a freshly loaded subject list
+func (m *Model) handleSubjectsLoaded(msg subjectsLoadedMsg) {
+	if m.subjectStore != nil && m.subjectStore != msg.store {
+		m.subjectStore.Close()
+	}
+	m.subjects = msg.subjects
+	m.subjectStore = msg.store
+	m.subjectFilter = newSubjectFilter(m.subjects)
+	m.scopedSubjects = nil
+	if m.cfg.Project.Scoped() {
+		m.scopedSubjects = []string{}
+		for _, subject := range m.allSubjects() {
+			if m.cfg.Project.InScope(subject) {
+				m.scopedSubjects = append(m.scopedSubjects, subject)
+			}
+		}
+	}
+	m.filterSubjects()
+	if m.subjectStore != nil {
+		m.statusMsg = fmt.Sprintf("Loaded %d subjects (indexed on disk)", m.subjectStore.Count())
+	} else {
+		m.statusMsg = fmt.Sprintf("Loaded %d subjects", len(m.subjects))
+	}
+	if msg.storeErr != nil {
+		m.statusMsg += fmt.Sprintf(", held in memory (%v)", msg.storeErr)
+	}
+	if m.scopedSubjects != nil {
+		m.statusMsg += fmt.Sprintf(", %d in the project (A shows all)", len(m.scopedSubjects))
+	}
+}
+
+// toggleProjectScope switches the list between the project's subjects and
+// every subject
+func (m *Model) toggleProjectScope() {
+	if m.scopedSubjects == nil {
+		m.err = fmt.Errorf("no project scope: add subjects or topics to %s", config.ProjectFileName)
+		return
+	}
+	m.projectScope = !m.projectScope
+	m.filterSubjects()
+	if m.projectScope {
+		m.copyNotify = fmt.Sprintf("Showing the project's %d subjects", len(m.scopedSubjects))
+	} else {
+		m.copyNotify = fmt.Sprintf("Showing all %d subjects", m.subjectCount())
+	}
+}
+
+// filterSubjects narrows the list to subjects containing the search text.
+// Store-backed lists are queried through the store's index and capped at
+// subjectQueryLimit matches.
+func (m *Model) filterSubjects() {
+	query := strings.ToLower(m.searchInput.Value())
+	m.selectedIndex = 0
+	m.moreSubjects = false
+
+	// Project scopes are small enough to scan on each keystroke
+	if m.projectScope && m.scopedSubjects != nil {
+		m.filteredSubjects = []string{}
+		for _, subject := range m.scopedSubjects {
+			if strings.Contains(strings.ToLower(subject), query) {
+				m.filteredSubjects = append(m.filteredSubjects, subject)
+			}
+		}
+		return
+	}
+
+	if m.subjectStore != nil {
+		subjects, more, err := m.subjectStore.Query(query, subjectQueryLimit)
+		if err != nil {
+			m.statusMsg = fmt.Sprintf("Error: %v", err)
+			return
+		}
+		m.filteredSubjects, m.moreSubjects = subjects, more
+		return
+	}
+
+	if query == "" {
+		m.filteredSubjects = m.subjects
+		m.subjectFilter.query, m.subjectFilter.matches = "", nil
+		return
+	}
+	matches := m.subjectFilter.match(query)
+	filtered := make([]string, len(matches))
+	for i, j := range matches {
+		filtered[i] = m.subjects[j]
+	}
+	m.filteredSubjects = filtered
+}
+
+// allSubjects returns every subject, reading them back from the store for
+// large registries
+func (m Model) allSubjects() []string {
+	if m.subjectStore == nil {
+		return m.subjects
+	}
+	subjects, err := m.subjectStore.All()
+	if err != nil {
+		return m.filteredSubjects
+	}
+	return subjects
+}
+
+// subjectCount is the number of subjects in the registry
+func (m Model) subjectCount() int {
+	if m.subjectStore != nil {
+		return m.subjectStore.Count()
+	}
+	return len(m.subjects)
+}
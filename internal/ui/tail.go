@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// tailBuffer is how many messages tailing keeps; older ones are dropped
+const tailBuffer = 1000
+
+// tailWait is how long each tail fetch waits for new messages
+const tailWait = 2 * time.Second
+
+// tailedMsg carries the messages one tail fetch returned
+type tailedMsg struct {
+	consumer *kafka.Consumer // The consumer fetched from, to drop stale results
+	messages []kafka.Message
+	decoded  []decodedMessage
+	lag      int64
+	err      error
+}
+
+// toggleTail starts or stops following the topic as messages arrive
+func (m *Model) toggleTail() tea.Cmd {
+	if m.tailing {
+		m.tailing = false
+		m.debugMsg = fmt.Sprintf("Stopped tailing | %d messages", len(m.consumedMessages))
+		return nil
+	}
+	if m.consumer == nil {
+		m.debugMsg = "ERROR: Consumer not initialized. Re-enter consumer mode."
+		return nil
+	}
+	if m.isLoadingMessages || m.backfilling() {
+		return nil
+	}
+	m.tailing = true
+	m.debugMsg = fmt.Sprintf("Tailing %s | F to stop", m.topic())
+	if m.tailFetching {
+		return nil // The fetch still in flight carries on tailing
+	}
+	return m.tailCmd()
+}
+
+// tailCmd fetches whatever arrives on the topic within tailWait
+func (m *Model) tailCmd() tea.Cmd {
+	consumer := m.consumer
+	decoder := m.wireDecoder
+	decode := m.decodeValue
+	m.tailFetching = true
+
+	return m.supervisor.Cmd(func(ctx context.Context) tea.Msg {
+		ctx, cancel := context.WithTimeout(ctx, tailWait)
+		defer cancel()
+
+		messages, err := consumer.FetchMessages(ctx, 100)
+		return tailedMsg{
+			consumer: consumer,
+			messages: messages,
+			decoded:  decodeMessages(decoder, decode, messages),
+			lag:      consumer.Lag(),
+			err:      err,
+		}
+	})
+}
+
+// handleTailed appends tailed messages and fetches again. The cursor
+// follows new messages while it is on the newest one.
+func (m *Model) handleTailed(msg tailedMsg) tea.Cmd {
+	if msg.consumer != m.consumer {
+		return nil // Consumer mode was left or restarted
+	}
+	m.tailFetching = false
+	if !m.tailing || m.state != stateConsumerMode {
+		m.tailing = false
+		return nil
+	}
+	if msg.err != nil {
+		m.tailing = false
+		m.debugMsg = fmt.Sprintf("ERROR tailing: %v", msg.err)
+		return nil
+	}
+
+	if len(msg.messages) > 0 {
+		atNewest := len(m.consumedMessages) == 0 || m.currentMsgIdx == len(m.consumedMessages)-1
+		m.consumedMessages = append(m.consumedMessages, msg.messages...)
+		m.decodedMessages = append(m.decodedMessages, msg.decoded...)
+		if drop := len(m.consumedMessages) - tailBuffer; drop > 0 {
+			m.consumedMessages = m.consumedMessages[drop:]
+			m.decodedMessages = m.decodedMessages[drop:]
+			m.currentMsgIdx = max(m.currentMsgIdx-drop, 0)
+		}
+		if atNewest {
+			m.currentMsgIdx = len(m.consumedMessages) - 1
+		}
+		m.consumerLag = msg.lag
+		m.throughput.add(msg.decoded)
+		m.saveBookmark()
+		m.statusMsg = fmt.Sprintf("[CONSUMER MODE] Message %d/%d", m.currentMsgIdx+1, len(m.consumedMessages))
+	}
+	m.debugMsg = fmt.Sprintf("Tailing %s | %d messages | F to stop", m.topic(), len(m.consumedMessages))
+	return tea.Batch(m.tailCmd(), m.redecodeIfStale(msg.decoded))
+}
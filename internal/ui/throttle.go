@@ -0,0 +1,67 @@
+package ui
+
+import (
+	"fmt"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// registryThrottledMsg reports a registry request that was rate limited and
+// is waiting to be retried
+type registryThrottledMsg registry.Throttle
+
+// throttleTickMsg refreshes the throttle countdown in the status bar
+type throttleTickMsg struct{}
+
+// watchThrottles routes the client's throttle reports to a channel the model
+// listens on. Reports are dropped while one is waiting to be read, as the
+// latest says all the status bar needs.
+func watchThrottles(client *registry.Client) chan registry.Throttle {
+	throttles := make(chan registry.Throttle, 1)
+	client.OnThrottle(func(t registry.Throttle) {
+		select {
+		case throttles <- t:
+		default:
+		}
+	})
+	return throttles
+}
+
+// waitForThrottle waits for the next throttle report
+func (m Model) waitForThrottle() tea.Cmd {
+	throttles := m.throttles
+	return func() tea.Msg {
+		return registryThrottledMsg(<-throttles)
+	}
+}
+
+func (m *Model) handleRegistryThrottled(msg registryThrottledMsg) tea.Cmd {
+	m.throttledUntil = time.Now().Add(msg.Wait)
+	return tea.Batch(m.waitForThrottle(), throttleTick())
+}
+
+func (m *Model) handleThrottleTick() tea.Cmd {
+	if time.Now().Before(m.throttledUntil) {
+		return throttleTick()
+	}
+	return nil
+}
+
+func throttleTick() tea.Cmd {
+	return tea.Tick(time.Second, func(time.Time) tea.Msg {
+		return throttleTickMsg{}
+	})
+}
+
+// throttleStatus is the status bar text while a registry request waits out
+// a rate limit, or "" if none is
+func (m Model) throttleStatus() string {
+	wait := time.Until(m.throttledUntil)
+	if wait <= 0 {
+		return ""
+	}
+	return fmt.Sprintf("Registry throttled, retrying in %s", wait.Round(time.Second))
+}
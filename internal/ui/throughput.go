@@ -0,0 +1,108 @@
+package ui
+
+import (
+	"bytes"
+	"encoding/base64"
+	"encoding/json"
+	"fmt"
+	"sort"
+	"time"
+)
+
+// throughputWindow is how much message time the rolling stats cover
+const throughputWindow = time.Minute
+
+// maxThroughputSamples bounds the memory used by the stats
+const maxThroughputSamples = 1000
+
+// throughputSample is one fetched message's contribution to the stats
+type throughputSample struct {
+	timestamp   time.Time
+	wireBytes   int
+	decodedSize int // Compact decoded JSON size, -1 if it didn't decode
+}
+
+// throughputStats computes rolling topic rates from the timestamps and sizes
+// of fetched messages, so they reflect the producers' rate rather than how
+// often the user fetches
+type throughputStats struct {
+	samples []throughputSample
+	seen    map[int64]bool // Offsets already counted
+}
+
+// add records fetched messages, ignoring ones already counted
+func (t *throughputStats) add(decoded []decodedMessage) {
+	if t.seen == nil {
+		t.seen = make(map[int64]bool)
+	}
+
+	for _, d := range decoded {
+		msg := d.msg
+		if t.seen[msg.Offset] || msg.Timestamp.IsZero() {
+			continue
+		}
+		t.seen[msg.Offset] = true
+
+		raw, _ := base64.StdEncoding.DecodeString(msg.Value)
+		sample := throughputSample{timestamp: msg.Timestamp, wireBytes: len(raw), decodedSize: -1}
+		var compact bytes.Buffer
+		if json.Compact(&compact, []byte(d.value.json)) == nil {
+			sample.decodedSize = compact.Len()
+		}
+		t.samples = append(t.samples, sample)
+	}
+
+	sort.Slice(t.samples, func(i, j int) bool {
+		return t.samples[i].timestamp.Before(t.samples[j].timestamp)
+	})
+	if len(t.samples) > maxThroughputSamples {
+		t.samples = t.samples[len(t.samples)-maxThroughputSamples:]
+	}
+}
+
+// summary describes the rates over the last throughputWindow of message time,
+// or "" when there are too few messages to tell
+func (t *throughputStats) summary() string {
+	if len(t.samples) < 2 {
+		return ""
+	}
+
+	newest := t.samples[len(t.samples)-1].timestamp
+	var count, wireBytes, decodedBytes, decodedCount int
+	oldest := newest
+	for i := len(t.samples) - 1; i >= 0; i-- {
+		s := t.samples[i]
+		if newest.Sub(s.timestamp) > throughputWindow {
+			break
+		}
+		oldest = s.timestamp
+		count++
+		wireBytes += s.wireBytes
+		if s.decodedSize >= 0 {
+			decodedBytes += s.decodedSize
+			decodedCount++
+		}
+	}
+
+	span := newest.Sub(oldest).Seconds()
+	if count < 2 || span <= 0 {
+		return ""
+	}
+
+	summary := fmt.Sprintf("%.1f msg/s · %s/s", float64(count-1)/span, formatBytes(float64(wireBytes)/span))
+	if decodedCount > 0 {
+		summary += fmt.Sprintf(" · avg %s decoded", formatBytes(float64(decodedBytes)/float64(decodedCount)))
+	}
+	return summary + fmt.Sprintf(" (last %d msgs)", count)
+}
+
+func formatBytes(n float64) string {
+	switch {
+	case n >= 1<<20:
+		return fmt.Sprintf("%.1f MB", n/(1<<20))
+	case n >= 1<<10:
+		return fmt.Sprintf("%.1f KB", n/(1<<10))
+	default:
+		return fmt.Sprintf("%.0f B", n)
+	}
+}
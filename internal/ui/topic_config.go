@@ -0,0 +1,314 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strconv"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// topicConfigsLoadedMsg carries a topic's configs, and the user's quotas
+type topicConfigsLoadedMsg struct {
+	topic    string
+	configs  []kafka.TopicConfig
+	quotas   []kafka.Quota
+	quotaErr error
+	err      error
+}
+
+// topicConfigSetMsg reports changing a topic config
+type topicConfigSetMsg struct {
+	topic string
+	name  string
+	value string
+	err   error
+}
+
+// TopicConfigModel shows a topic's configs and changes the editable ones,
+// each change confirmed first
+type TopicConfigModel struct {
+	topic      string
+	production bool
+	configs    []kafka.TopicConfig
+	quotas     []kafka.Quota
+	quotaErr   error
+	cursor     int
+
+	editing    bool
+	value      string
+	confirming bool
+	confirmed  bool
+	applying   bool
+	problem    string // Why the entered value can't be set
+	quit       bool
+}
+
+// NewTopicConfig shows the configs of a topic
+func NewTopicConfig(topic string, production bool, msg topicConfigsLoadedMsg) TopicConfigModel {
+	return TopicConfigModel{
+		topic:      topic,
+		production: production,
+		configs:    msg.configs,
+		quotas:     msg.quotas,
+		quotaErr:   msg.quotaErr,
+	}
+}
+
+func (m TopicConfigModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m TopicConfigModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok || m.applying {
+		return m, nil
+	}
+
+	switch {
+	case m.confirming:
+		switch keyMsg.String() {
+		case "y", "Y":
+			m.confirming = false
+			m.confirmed = true
+			m.applying = true
+		default:
+			m.confirming = false
+		}
+
+	case m.editing:
+		switch keyMsg.String() {
+		case "esc":
+			m.editing = false
+			m.problem = ""
+		case "enter":
+			value := strings.TrimSpace(m.value)
+			if err := kafka.CheckTopicConfig(m.selected().Name, value); err != nil {
+				m.problem = err.Error()
+				return m, nil
+			}
+			if value == m.selected().Value {
+				m.editing = false
+				return m, nil
+			}
+			m.value = value
+			m.problem = ""
+			m.editing = false
+			m.confirming = true
+		case "backspace":
+			if len(m.value) > 0 {
+				runes := []rune(m.value)
+				m.value = string(runes[:len(runes)-1])
+			}
+		case "ctrl+u":
+			m.value = ""
+		default:
+			if keyMsg.Type == tea.KeyRunes {
+				m.value += string(keyMsg.Runes)
+			}
+		}
+
+	default:
+		switch keyMsg.String() {
+		case "esc", "q":
+			m.quit = true
+		case "j", "down":
+			if m.cursor < len(m.configs)-1 {
+				m.cursor++
+			}
+		case "k", "up":
+			if m.cursor > 0 {
+				m.cursor--
+			}
+		case "enter", "e":
+			if len(m.configs) > 0 && m.selected().Editable {
+				m.editing = true
+				m.value = m.selected().Value
+			}
+		}
+	}
+	return m, nil
+}
+
+func (m TopicConfigModel) selected() kafka.TopicConfig {
+	return m.configs[m.cursor]
+}
+
+func (m TopicConfigModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Topic Configs: "+m.topic) + "\n\n")
+
+	for i, c := range m.configs {
+		source := "set on topic"
+		if c.Default {
+			source = "default"
+		}
+		line := fmt.Sprintf("%-20s %-32s %s", c.Name, formatTopicConfig(c.Name, c.Value), source)
+		if !c.Editable {
+			line += ", read only"
+		}
+		if i == m.cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(NormalItemStyle.Render("  "+line) + "\n")
+		}
+	}
+	if len(m.configs) == 0 {
+		b.WriteString(HelpStyle.Render("  No configs returned") + "\n")
+	}
+
+	b.WriteString("\n" + ListTitleStyle.Render("Your quotas") + "\n")
+	switch {
+	case m.quotaErr != nil:
+		b.WriteString(HelpStyle.Render("  Unavailable: "+m.quotaErr.Error()) + "\n")
+	case len(m.quotas) == 0:
+		b.WriteString(HelpStyle.Render("  None set for your user") + "\n")
+	default:
+		for _, q := range m.quotas {
+			b.WriteString(fmt.Sprintf("  %-30s %s\n", q.Name, strconv.FormatFloat(q.Value, 'f', -1, 64)))
+		}
+	}
+
+	b.WriteString("\n")
+	switch {
+	case m.applying:
+		b.WriteString(HelpStyle.Render(fmt.Sprintf("Setting %s...", m.selected().Name)) + "\n")
+	case m.confirming:
+		c := m.selected()
+		prompt := fmt.Sprintf("Set %s on %s from %s to %s? [y/N]", c.Name, m.topic,
+			formatTopicConfig(c.Name, c.Value), formatTopicConfig(c.Name, m.value))
+		if m.production {
+			prompt = "PRODUCTION: " + prompt
+		}
+		b.WriteString(DiffChangedStyle.Render(prompt) + "\n")
+	case m.editing:
+		b.WriteString(fmt.Sprintf("New %s: %s█\n", m.selected().Name, m.value))
+		if m.problem != "" {
+			b.WriteString(ErrorStyle.Render(m.problem) + "\n")
+		}
+		b.WriteString(lipgloss.NewStyle().Faint(true).Render("[enter] Review change  [ctrl+u] Clear  [esc] Cancel") + "\n")
+	default:
+		b.WriteString(lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [enter] Change value  [esc] Close") + "\n")
+	}
+	return b.String()
+}
+
+// Confirmed returns the change confirmed since it was last applied
+func (m TopicConfigModel) Confirmed() (name, value string, ok bool) {
+	if !m.confirmed {
+		return "", "", false
+	}
+	return m.selected().Name, m.value, true
+}
+
+// Quit returns whether the view is closed
+func (m TopicConfigModel) Quit() bool {
+	return m.quit
+}
+
+// formatTopicConfig adds a readable form to durations and sizes
+func formatTopicConfig(name, value string) string {
+	n, err := strconv.ParseInt(value, 10, 64)
+	if err != nil {
+		return value
+	}
+	switch {
+	case n == -1:
+		return value + " (no limit)"
+	case strings.HasSuffix(name, ".ms"):
+		return fmt.Sprintf("%s (%s)", value, formatRetention(time.Duration(n)*time.Millisecond))
+	case strings.HasSuffix(name, ".bytes"):
+		return fmt.Sprintf("%s (%s)", value, formatBytes(float64(n)))
+	}
+	return value
+}
+
+// formatRetention shows a duration in the largest whole unit that fits
+func formatRetention(d time.Duration) string {
+	day := 24 * time.Hour
+	switch {
+	case d >= day && d%day == 0:
+		return fmt.Sprintf("%dd", d/day)
+	case d >= time.Hour && d%time.Hour == 0:
+		return fmt.Sprintf("%dh", d/time.Hour)
+	}
+	return d.String()
+}
+
+// startTopicConfig loads the selected subject's topic configs
+func (m *Model) startTopicConfig() tea.Cmd {
+	m.statusMsg = fmt.Sprintf("Loading configs of %s...", m.topic())
+	return loadTopicConfigs(m.cfg, m.topic())
+}
+
+func loadTopicConfigs(cfg *config.Config, topic string) tea.Cmd {
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		configs, err := kafka.DescribeTopicConfigs(ctx, cfg, topic)
+		if err != nil {
+			return topicConfigsLoadedMsg{topic: topic, err: err}
+		}
+		quotas, quotaErr := kafka.DescribeUserQuotas(ctx, cfg)
+		return topicConfigsLoadedMsg{topic: topic, configs: configs, quotas: quotas, quotaErr: quotaErr}
+	}
+}
+
+func (m *Model) handleTopicConfigsLoaded(msg topicConfigsLoadedMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	if m.state == stateTopicConfig && m.topicConfig.topic == msg.topic {
+		// Reloaded after a change; keep the cursor
+		cursor := m.topicConfig.cursor
+		m.topicConfig = NewTopicConfig(msg.topic, m.cfg.Production, msg)
+		m.topicConfig.cursor = min(cursor, max(0, len(msg.configs)-1))
+		return
+	}
+	if m.state != stateBrowsing && m.state != stateViewing {
+		return
+	}
+	m.topicConfig = NewTopicConfig(msg.topic, m.cfg.Production, msg)
+	m.topicConfigReturn = m.state
+	m.state = stateTopicConfig
+}
+
+func (m *Model) handleTopicConfig(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.topicConfig.Update(msg)
+	m.topicConfig = newModel.(TopicConfigModel)
+	if m.topicConfig.Quit() {
+		m.state = m.topicConfigReturn
+		return m, nil
+	}
+
+	name, value, ok := m.topicConfig.Confirmed()
+	if !ok {
+		return m, cmd
+	}
+	m.topicConfig.confirmed = false
+	cfg, topic := m.cfg, m.topicConfig.topic
+	return m, func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		err := kafka.SetTopicConfig(ctx, cfg, topic, name, value)
+		return topicConfigSetMsg{topic: topic, name: name, value: value, err: err}
+	}
+}
+
+func (m *Model) handleTopicConfigSet(msg topicConfigSetMsg) tea.Cmd {
+	m.topicConfig.applying = false
+	if msg.err != nil {
+		m.err = msg.err
+		return nil
+	}
+	m.copyNotify = fmt.Sprintf("Set %s on %s to %s", msg.name, msg.topic, msg.value)
+	return loadTopicConfigs(m.cfg, msg.topic)
+}
@@ -0,0 +1,229 @@
+package ui
+
+import (
+	"context"
+	"fmt"
+	"strings"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// topicsLoadedMsg carries the cluster's topics for the topic picker
+type topicsLoadedMsg struct {
+	topics []string
+	err    error
+}
+
+// TopicPickerModel picks the topic a subject's messages go to, from the
+// cluster's topics. Typing filters the list; a name that matches no topic
+// can still be chosen.
+type TopicPickerModel struct {
+	subject      string
+	defaultTopic string // Given by the naming strategy; picking it removes the override
+	current      string
+	topics       []string
+	filter       string
+	cursor       int
+	saved        bool
+	quit         bool
+}
+
+// NewTopicPicker lists topics for a subject, with the topic the naming
+// strategy gives it, if any, first
+func NewTopicPicker(subject, defaultTopic, current string, topics []string) TopicPickerModel {
+	m := TopicPickerModel{subject: subject, defaultTopic: defaultTopic, current: current}
+	if defaultTopic != "" {
+		m.topics = append(m.topics, defaultTopic)
+	}
+	for _, topic := range topics {
+		if topic != defaultTopic {
+			m.topics = append(m.topics, topic)
+		}
+	}
+	for i, topic := range m.topics {
+		if topic == current {
+			m.cursor = i
+		}
+	}
+	return m
+}
+
+func (m TopicPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m TopicPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+
+	visible := m.visible()
+	switch keyMsg.String() {
+	case "esc":
+		m.quit = true
+	case "enter":
+		if len(visible) > 0 || m.filter != "" {
+			m.saved = true
+			m.quit = true
+		}
+	case "down", "ctrl+n":
+		if m.cursor < len(visible)-1 {
+			m.cursor++
+		}
+	case "up", "ctrl+p":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case "backspace":
+		if len(m.filter) > 0 {
+			runes := []rune(m.filter)
+			m.filter = string(runes[:len(runes)-1])
+			m.cursor = 0
+		}
+	case "ctrl+u":
+		m.filter = ""
+		m.cursor = 0
+	default:
+		if keyMsg.Type == tea.KeyRunes {
+			m.filter += string(keyMsg.Runes)
+			m.cursor = 0
+		}
+	}
+	return m, nil
+}
+
+// visible returns the topics matching the filter
+func (m TopicPickerModel) visible() []string {
+	if m.filter == "" {
+		return m.topics
+	}
+	var topics []string
+	for _, topic := range m.topics {
+		if strings.Contains(strings.ToLower(topic), strings.ToLower(m.filter)) {
+			topics = append(topics, topic)
+		}
+	}
+	return topics
+}
+
+func (m TopicPickerModel) View() string {
+	var s string
+	s += lipgloss.NewStyle().Bold(true).Render("Topic for "+m.subject) + "\n\n"
+	s += "Filter: " + m.filter + "\n\n"
+
+	visible := m.visible()
+	start := max(0, m.cursor-15)
+	for i := start; i < len(visible) && i < start+20; i++ {
+		topic := visible[i]
+		line := topic
+		if topic != "" && topic == m.defaultTopic {
+			line += " (from the naming strategy)"
+		}
+		if topic == m.current {
+			line += " ✓"
+		}
+		if i == m.cursor {
+			s += lipgloss.NewStyle().Foreground(lipgloss.Color("11")).Bold(true).Render("> "+line) + "\n"
+		} else {
+			s += "  " + line + "\n"
+		}
+	}
+	if len(visible) == 0 {
+		s += lipgloss.NewStyle().Faint(true).Render("  No topics match; enter uses "+m.filter) + "\n"
+	}
+
+	s += "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("Sending, consuming and tailing the subject use the chosen topic; it is saved to the profile") + "\n"
+	s += lipgloss.NewStyle().Faint(true).Render("[↑/↓] Move  [enter] Use topic  [esc] Cancel") + "\n"
+	return s
+}
+
+// Selected returns the chosen topic: the one under the cursor, or the
+// filter text if no topic matches it
+func (m TopicPickerModel) Selected() string {
+	visible := m.visible()
+	if len(visible) == 0 {
+		return strings.TrimSpace(m.filter)
+	}
+	return visible[m.cursor]
+}
+
+// Saved returns whether a topic was chosen
+func (m TopicPickerModel) Saved() bool {
+	return m.saved
+}
+
+// Quit returns whether the picker is closed
+func (m TopicPickerModel) Quit() bool {
+	return m.quit
+}
+
+// topic returns the topic of the selected subject
+func (m Model) topic() string {
+	return m.cfg.TopicFor(m.selectedSubject)
+}
+
+// needTopic reports whether the selected subject has a topic, setting an
+// error that says how to pick one if not
+func (m *Model) needTopic() bool {
+	if m.topic() != "" {
+		return true
+	}
+	m.err = fmt.Errorf("no topic for %s under the %s naming strategy; press O to pick one",
+		m.selectedSubject, m.cfg.NamingStrategy())
+	return false
+}
+
+// startTopicPicker lists the cluster's topics to pick the selected
+// subject's topic from
+func (m *Model) startTopicPicker() tea.Cmd {
+	m.statusMsg = "Listing topics..."
+	cfg := m.cfg
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 30*time.Second)
+		defer cancel()
+		topics, err := kafka.ListTopics(ctx, cfg)
+		return topicsLoadedMsg{topics: topics, err: err}
+	}
+}
+
+// handleTopicsLoaded opens the picker, even if listing failed, so a topic
+// can still be typed in
+func (m *Model) handleTopicsLoaded(msg topicsLoadedMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = msg.err
+	}
+	if m.selectedSubject == "" || (m.state != stateBrowsing && m.state != stateViewing) {
+		return
+	}
+	m.topicPicker = NewTopicPicker(m.selectedSubject, m.cfg.DefaultTopic(m.selectedSubject), m.topic(), msg.topics)
+	m.topicReturn = m.state
+	m.state = stateTopicPicker
+}
+
+func (m *Model) handleTopicPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.topicPicker.Update(msg)
+	m.topicPicker = newModel.(TopicPickerModel)
+	if !m.topicPicker.Quit() {
+		return m, cmd
+	}
+
+	m.state = m.topicReturn
+	if !m.topicPicker.Saved() {
+		return m, nil
+	}
+	topic := m.topicPicker.Selected()
+	if err := m.cfg.SetTopic(config.GetConfigPath(), m.selectedSubject, topic); err != nil {
+		m.err = fmt.Errorf("saving topic for %s: %w", m.selectedSubject, err)
+		return m, nil
+	}
+	m.copyNotify = fmt.Sprintf("%s uses topic %s", m.selectedSubject, m.topic())
+	return m, nil
+}
@@ -0,0 +1,280 @@
+package ui
+
+import (
+	"fmt"
+	"strings"
+
+	tea "github.com/charmbracelet/bubbletea"
+	"github.com/charmbracelet/lipgloss"
+
+	"github.com/JimmyyyW/avrocado/internal/avro"
+	"github.com/JimmyyyW/avrocado/internal/diff"
+	"github.com/JimmyyyW/avrocado/internal/registry"
+)
+
+// diffContext is how many unchanged lines are kept around each change
+const diffContext = 3
+
+// versionsListedMsg carries a subject's versions for the diff picker
+type versionsListedMsg struct {
+	subject  string
+	versions []int
+	err      error
+}
+
+// versionDiffMsg carries the two versions to diff
+type versionDiffMsg struct {
+	older *registry.SchemaResponse
+	newer *registry.SchemaResponse
+	err   error
+}
+
+// VersionPickerModel picks the two versions of a subject to diff
+type VersionPickerModel struct {
+	subject  string
+	versions []int
+	cursor   int
+	marked   []int // At most two versions, in the order they were marked
+	chosen   bool
+	quit     bool
+}
+
+// NewVersionPicker lists versions with the viewed version and the one before
+// it marked
+func NewVersionPicker(subject string, versions []int, current int) VersionPickerModel {
+	m := VersionPickerModel{subject: subject, versions: versions}
+	for i, v := range versions {
+		if v == current {
+			m.cursor = i
+			if i > 0 {
+				m.marked = []int{versions[i-1], v}
+			}
+		}
+	}
+	if m.marked == nil && len(versions) >= 2 {
+		m.marked = versions[len(versions)-2:]
+		m.cursor = len(versions) - 1
+	}
+	return m
+}
+
+func (m VersionPickerModel) Init() tea.Cmd {
+	return nil
+}
+
+func (m VersionPickerModel) Update(msg tea.Msg) (tea.Model, tea.Cmd) {
+	keyMsg, ok := msg.(tea.KeyMsg)
+	if !ok {
+		return m, nil
+	}
+	switch keyMsg.String() {
+	case "esc", "q":
+		m.quit = true
+	case "j", "down":
+		if m.cursor < len(m.versions)-1 {
+			m.cursor++
+		}
+	case "k", "up":
+		if m.cursor > 0 {
+			m.cursor--
+		}
+	case " ", "x":
+		m.toggle(m.versions[m.cursor])
+	case "enter":
+		if len(m.marked) == 1 && m.marked[0] != m.versions[m.cursor] {
+			m.marked = append(m.marked, m.versions[m.cursor])
+		}
+		if len(m.marked) == 2 {
+			m.chosen = true
+			m.quit = true
+		}
+	}
+	return m, nil
+}
+
+// toggle marks or unmarks a version, dropping the earliest mark when a third
+// is added
+func (m *VersionPickerModel) toggle(version int) {
+	for i, v := range m.marked {
+		if v == version {
+			m.marked = append(m.marked[:i:i], m.marked[i+1:]...)
+			return
+		}
+	}
+	if len(m.marked) == 2 {
+		m.marked = m.marked[1:]
+	}
+	m.marked = append(m.marked, version)
+}
+
+func (m VersionPickerModel) View() string {
+	var b strings.Builder
+	b.WriteString(lipgloss.NewStyle().Bold(true).Render("Diff Versions: "+m.subject) + "\n\n")
+	b.WriteString("Mark two versions to compare:\n\n")
+
+	for i, v := range m.versions {
+		check := "[ ]"
+		for _, marked := range m.marked {
+			if marked == v {
+				check = "[x]"
+			}
+		}
+		line := fmt.Sprintf("%s v%d", check, v)
+		if i == m.cursor {
+			b.WriteString(SelectedItemStyle.Render("> "+line) + "\n")
+		} else {
+			b.WriteString(NormalItemStyle.Render("  "+line) + "\n")
+		}
+	}
+
+	b.WriteString("\n")
+	b.WriteString(lipgloss.NewStyle().Faint(true).Render("[j/k] Move  [space] Mark  [enter] Diff  [esc] Cancel") + "\n")
+	return b.String()
+}
+
+// Versions returns the two chosen versions, older first
+func (m VersionPickerModel) Versions() (int, int, bool) {
+	if !m.chosen {
+		return 0, 0, false
+	}
+	return min(m.marked[0], m.marked[1]), max(m.marked[0], m.marked[1]), true
+}
+
+// Quit returns whether the picker is closed
+func (m VersionPickerModel) Quit() bool {
+	return m.quit
+}
+
+// startVersionDiff lists the viewed subject's versions to pick from
+func (m *Model) startVersionDiff() tea.Cmd {
+	subject := m.selectedSubject
+	client := m.client
+	m.statusMsg = fmt.Sprintf("Loading versions of %s...", subject)
+	return func() tea.Msg {
+		versions, err := client.ListVersions(subject)
+		return versionsListedMsg{subject: subject, versions: versions, err: err}
+	}
+}
+
+func (m *Model) handleVersionsListed(msg versionsListedMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = fmt.Errorf("listing versions of %s: %w", msg.subject, msg.err)
+		return
+	}
+	if msg.subject != m.selectedSubject || m.state != stateViewing {
+		return
+	}
+	if len(msg.versions) < 2 {
+		m.err = fmt.Errorf("%s has only one version", msg.subject)
+		return
+	}
+	m.versionPicker = NewVersionPicker(msg.subject, msg.versions, m.schemaVersion)
+	m.state = stateVersionPicker
+}
+
+func (m *Model) handleVersionPicker(msg tea.KeyMsg) (tea.Model, tea.Cmd) {
+	newModel, cmd := m.versionPicker.Update(msg)
+	m.versionPicker = newModel.(VersionPickerModel)
+	if !m.versionPicker.Quit() {
+		return m, cmd
+	}
+
+	m.state = stateViewing
+	older, newer, ok := m.versionPicker.Versions()
+	if !ok {
+		return m, nil
+	}
+	subject := m.versionPicker.subject
+	client := m.client
+	m.statusMsg = fmt.Sprintf("Diffing %s v%d and v%d...", subject, older, newer)
+	return m, func() tea.Msg {
+		a, err := client.GetSchemaVersion(subject, older)
+		if err != nil {
+			return versionDiffMsg{err: fmt.Errorf("fetching v%d: %w", older, err)}
+		}
+		b, err := client.GetSchemaVersion(subject, newer)
+		if err != nil {
+			return versionDiffMsg{err: fmt.Errorf("fetching v%d: %w", newer, err)}
+		}
+		return versionDiffMsg{older: a, newer: b}
+	}
+}
+
+func (m *Model) handleVersionDiff(msg versionDiffMsg) {
+	m.statusMsg = ""
+	if msg.err != nil {
+		m.err = msg.err
+		return
+	}
+	oldText := registry.PrettyPrintSchema(msg.older.Schema)
+	newText := registry.PrettyPrintSchema(msg.newer.Schema)
+	lines := diff.Lines(oldText, newText)
+
+	m.openReport(fmt.Sprintf("%s: v%d → v%d", msg.older.Subject, msg.older.Version, msg.newer.Version), renderVersionDiff(msg.older, msg.newer, lines))
+	m.reportCopy = diff.Unified(versionLabel(msg.older), versionLabel(msg.newer), lines, diffContext)
+}
+
+// renderVersionDiff shows the field changes between two versions, then the
+// changed lines of their schemas with some context
+func renderVersionDiff(older, newer *registry.SchemaResponse, lines []diff.Line) string {
+	var b strings.Builder
+
+	added, removed := 0, 0
+	for _, l := range lines {
+		switch l.Op {
+		case diff.Insert:
+			added++
+		case diff.Delete:
+			removed++
+		}
+	}
+	b.WriteString(HelpStyle.Render(fmt.Sprintf("v%d (ID %d) → v%d (ID %d): %d lines added, %d removed  ·  [y] Copy as unified diff",
+		older.Version, older.ID, newer.Version, newer.ID, added, removed)))
+	b.WriteString("\n\n")
+
+	if added+removed == 0 {
+		b.WriteString(SuccessStyle.Render("The schemas are identical"))
+		return b.String()
+	}
+
+	if changes, err := avro.CompareSchemas(older.Schema, newer.Schema); err == nil && len(changes) > 0 {
+		for _, c := range changes {
+			switch c.Kind {
+			case diff.Added:
+				b.WriteString(DiffAddedStyle.Render(fmt.Sprintf("+ %s (%s)", c.Path, c.New.Type)) + "\n")
+			case diff.Removed:
+				b.WriteString(DiffRemovedStyle.Render(fmt.Sprintf("- %s (%s)", c.Path, c.Old.Type)) + "\n")
+			case diff.Changed:
+				b.WriteString(DiffChangedStyle.Render(fmt.Sprintf("~ %s: %s", c.Path, strings.Join(c.Details, "; "))) + "\n")
+			}
+		}
+		b.WriteString("\n")
+	}
+
+	for _, h := range diff.Hunks(lines, diffContext) {
+		if h.Skipped > 0 {
+			noun := "lines"
+			if h.Skipped == 1 {
+				noun = "line"
+			}
+			b.WriteString(HelpStyle.Render(fmt.Sprintf("  ⋯ %d unchanged %s", h.Skipped, noun)) + "\n")
+			continue
+		}
+		l := h.Line
+		switch l.Op {
+		case diff.Insert:
+			b.WriteString(DiffAddedStyle.Render(fmt.Sprintf("%4s %4d + %s", "", l.New, l.Text)) + "\n")
+		case diff.Delete:
+			b.WriteString(DiffRemovedStyle.Render(fmt.Sprintf("%4d %4s - %s", l.Old, "", l.Text)) + "\n")
+		default:
+			b.WriteString(fmt.Sprintf("%4d %4d   %s\n", l.Old, l.New, l.Text))
+		}
+	}
+	return b.String()
+}
+
+// versionLabel names a schema version in a unified diff header
+func versionLabel(schema *registry.SchemaResponse) string {
+	return fmt.Sprintf("%s v%d", schema.Subject, schema.Version)
+}
@@ -0,0 +1,58 @@
+package ui
+
+import (
+	"context"
+	"time"
+
+	tea "github.com/charmbracelet/bubbletea"
+
+	"github.com/JimmyyyW/avrocado/internal/kafka"
+)
+
+// writeProbedMsg carries what probing a topic for write permission found,
+// nil if nothing
+type writeProbedMsg struct {
+	topic string
+	probe *kafka.WriteProbe
+	err   error
+}
+
+// probeWrite checks write permission on a topic the first time send mode
+// targets it in a session
+func (m Model) probeWrite(topic string) tea.Cmd {
+	if m.producer == nil {
+		return nil
+	}
+	if _, probed := m.writeProbes[topic]; probed {
+		return nil
+	}
+	producer := m.producer
+	return func() tea.Msg {
+		ctx, cancel := context.WithTimeout(context.Background(), 10*time.Second)
+		defer cancel()
+		probe, err := producer.CheckWrite(ctx, topic)
+		return writeProbedMsg{topic: topic, probe: probe, err: err}
+	}
+}
+
+func (m *Model) handleWriteProbed(msg writeProbedMsg) {
+	if msg.err != nil {
+		return // Probed again next time; the send reports its own error
+	}
+	m.writeProbes[msg.topic] = msg.probe
+	m.showWriteProbe(msg.topic)
+}
+
+// showWriteProbe warns in send mode's status line if the topic's probe
+// found a problem
+func (m *Model) showWriteProbe(topic string) {
+	probe := m.writeProbes[topic]
+	if probe == nil || m.state != stateSendMode || m.keyTopic != topic {
+		return
+	}
+	if probe.Denied {
+		m.statusMsg = "[SEND MODE] ⚠ " + probe.Reason + "  |  Esc cancel"
+	} else {
+		m.statusMsg = "[SEND MODE] ⚠ " + probe.Reason + "  |  Ctrl+S send anyway, Esc cancel"
+	}
+}
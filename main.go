@@ -7,23 +7,83 @@ import (
 	tea "github.com/charmbracelet/bubbletea"
 	"github.com/spf13/pflag"
 
+	"github.com/JimmyyyW/avrocado/internal/avro"
 	"github.com/JimmyyyW/avrocado/internal/config"
+	"github.com/JimmyyyW/avrocado/internal/deeplink"
 	"github.com/JimmyyyW/avrocado/internal/kafka"
+	"github.com/JimmyyyW/avrocado/internal/metrics"
+	"github.com/JimmyyyW/avrocado/internal/plugin"
 	"github.com/JimmyyyW/avrocado/internal/registry"
+	"github.com/JimmyyyW/avrocado/internal/session"
+	"github.com/JimmyyyW/avrocado/internal/tunnel"
 	"github.com/JimmyyyW/avrocado/internal/ui"
 )
 
 func main() {
+	// Headless subcommands run without the TUI
+	if runCommand(os.Args[1:]) {
+		return
+	}
+
 	// Parse command line flags
 	selectConfig := pflag.BoolP("select-config", "s", false, "Show configuration selection menu")
+	metricsAddr := pflag.String("metrics-addr", "", "Serve Prometheus metrics on this address (e.g. :9464)")
+	schemaDir := pflag.String("schemas", "", "Use the .avsc files in this directory instead of a registry")
+	schemaID := pflag.Int("schema-id", 0, "Schema ID framed into produced messages, with --schemas")
+	framing := pflag.String("framing", "", "Frame produced messages in the wire format (wire) or not at all (none)")
 	pflag.Parse()
 
-	// Load configuration
-	cfg, err := loadConfiguration(*selectConfig)
+	// An avrocado:// link opens the TUI on its subject or message
+	var link *deeplink.Link
+	if pflag.NArg() > 0 {
+		var err error
+		if link, err = deeplink.Parse(pflag.Arg(0)); err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+	}
+
+	if *metricsAddr != "" {
+		server, err := metrics.Serve(*metricsAddr)
+		if err != nil {
+			fmt.Fprintf(os.Stderr, "Error: %v\n", err)
+			os.Exit(1)
+		}
+		defer server.Close()
+	}
+
+	// Load configuration, scoped by the project file of the repo we're in
+	project, err := config.FindProjectConfig(".")
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	linkProfile := ""
+	if link != nil {
+		linkProfile = link.Profile
+	}
+	cfg, restoreSession, err := loadConfiguration(*selectConfig, project, linkProfile)
 	if err != nil {
 		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
 		os.Exit(1)
 	}
+	cfg.Project = project
+	if err := applySchemaFlags(cfg, *schemaDir, *schemaID, *framing); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+	if err := avro.SetBackend(cfg.AvroBackend); err != nil {
+		fmt.Fprintf(os.Stderr, "Configuration error: %v\n", err)
+		os.Exit(1)
+	}
+
+	// Route connections through the profile's SSH bastion if configured
+	sshTunnel, err := tunnel.Connect(cfg)
+	if err != nil {
+		fmt.Fprintf(os.Stderr, "SSH tunnel error: %v\n", err)
+		os.Exit(1)
+	}
+	defer plugin.StopAll()
 
 	client := registry.NewClient(cfg)
 
@@ -35,22 +95,71 @@ func main() {
 			fmt.Fprintf(os.Stderr, "Warning: Could not create Kafka producer: %v\n", err)
 			fmt.Fprintln(os.Stderr, "Message production will be disabled.")
 			producer = nil
-		} else {
-			defer producer.Close()
 		}
 	}
 
+	// The model closes the producer and tunnel on shutdown, as switching
+	// profiles replaces them
 	model := ui.NewModel(client, producer, cfg)
+	model.SetTunnel(sshTunnel)
+
+	// Open the link, else restore the previous session if it was saved for
+	// the same profile
+	if link != nil {
+		model.OpenLink(link)
+	} else if restoreSession {
+		if state, err := session.Load(session.GetSessionPath()); err == nil && state.Profile == cfg.Profile {
+			model.RestoreSession(state)
+		}
+	}
 
 	p := tea.NewProgram(model, tea.WithAltScreen())
-	if _, err := p.Run(); err != nil {
+	finalModel, err := p.Run()
+	if err != nil {
 		fmt.Fprintf(os.Stderr, "Error running program: %v\n", err)
 		os.Exit(1)
 	}
+
+	if m, ok := finalModel.(ui.Model); ok {
+		if restoreSession {
+			if err := session.Save(session.GetSessionPath(), m.Session()); err != nil {
+				fmt.Fprintf(os.Stderr, "Warning: Could not save session: %v\n", err)
+			}
+		}
+		if err := m.Shutdown(); err != nil {
+			fmt.Fprintf(os.Stderr, "Warning: Shutdown: %v\n", err)
+		}
+	}
+}
+
+// applySchemaFlags switches to a local schema directory and sets the framing
+// of produced messages over what the profile has
+func applySchemaFlags(cfg *config.Config, dir string, schemaID int, framing string) error {
+	if dir != "" {
+		if info, err := os.Stat(dir); err != nil || !info.IsDir() {
+			return fmt.Errorf("--schemas %s is not a directory", dir)
+		}
+		cfg.RegistryFlavor = config.FlavorLocal
+		cfg.LocalSchemaDir = dir
+	}
+	if schemaID != 0 {
+		cfg.LocalSchemaID = schemaID
+	}
+	switch framing {
+	case "":
+	case config.FramingWire, config.FramingNone:
+		cfg.Framing = framing
+	default:
+		return fmt.Errorf("unknown --framing %q (want wire or none)", framing)
+	}
+	return nil
 }
 
-// loadConfiguration loads configuration from YAML file or environment variables
-func loadConfiguration(selectConfig bool) (*config.Config, error) {
+// loadConfiguration loads configuration from YAML file or environment variables.
+// It also reports whether session persistence is enabled in the config file.
+// A link's profile is used over a project's, and either unless one is
+// picked from the menu.
+func loadConfiguration(selectConfig bool, project *config.ProjectConfig, linkProfile string) (*config.Config, bool, error) {
 	configPath := config.GetConfigPath()
 	configFile, err := config.LoadConfigFile(configPath)
 
@@ -58,15 +167,18 @@ func loadConfiguration(selectConfig bool) (*config.Config, error) {
 	if err != nil {
 		if os.IsNotExist(err) {
 			if err := config.CreateDefaultConfig(configPath); err != nil {
-				return nil, fmt.Errorf("creating default config: %w", err)
+				return nil, false, fmt.Errorf("creating default config: %w", err)
 			}
 			configFile, _ = config.LoadConfigFile(configPath)
 		} else {
-			return nil, fmt.Errorf("loading config file: %w", err)
+			return nil, false, fmt.Errorf("loading config file: %w", err)
 		}
 	}
 
+	restoreSession := configFile != nil && configFile.RestoreSession
+
 	var selectedProfile *config.ProfileConfig
+	var selectedName string
 
 	// Show selection menu if flag is set
 	if selectConfig && configFile != nil && len(configFile.Configurations) > 0 {
@@ -75,22 +187,61 @@ func loadConfiguration(selectConfig bool) (*config.Config, error) {
 		model, _ := p.Run()
 		if selectorModel, ok := model.(ui.ConfigSelectorModel); ok {
 			selectedProfile = selectorModel.SelectedProfile()
+			selectedName = selectorModel.SelectedName()
 		}
 	}
 
-	// If no profile selected, use default
+	// If no profile selected, use the last session's profile or the default
 	if selectedProfile == nil && configFile != nil {
-		selectedProfile, err = configFile.GetProfile(configFile.Default)
+		selectedName = configFile.Default
+		if restoreSession {
+			if state, err := session.Load(session.GetSessionPath()); err == nil && state.Profile != "" {
+				if _, ok := configFile.Configurations[state.Profile]; ok {
+					selectedName = state.Profile
+				}
+			}
+		}
+		if project != nil && project.Profile != "" {
+			if _, ok := configFile.Configurations[project.Profile]; !ok {
+				return nil, false, fmt.Errorf("%s: profile %q not found", project.Path, project.Profile)
+			}
+			selectedName = project.Profile
+		}
+		if linkProfile != "" {
+			if _, ok := configFile.Configurations[linkProfile]; !ok {
+				return nil, false, fmt.Errorf("profile %q from the link not found", linkProfile)
+			}
+			selectedName = linkProfile
+		}
+
+		selectedProfile, err = configFile.GetProfile(selectedName)
 		if err != nil {
 			// Fall back to environment variables
-			return config.Load()
+			cfg, err := config.Load()
+			return cfg, restoreSession, err
 		}
 	}
 
 	// If still no profile, fall back to environment variables
 	if selectedProfile == nil {
-		return config.Load()
+		cfg, err := config.Load()
+		return cfg, restoreSession, err
 	}
 
-	return selectedProfile.ToConfig(), nil
+	cfg, err := configFile.Config(selectedName)
+	return cfg, restoreSession, err
+}
+
+// openTunnel connects to the profile's SSH bastion, if one is configured, and
+// routes cfg's registry and broker connections through it.
+// The returned function closes the tunnel.
+func openTunnel(cfg *config.Config) (func(), error) {
+	t, err := tunnel.Connect(cfg)
+	if err != nil {
+		return nil, err
+	}
+	if t == nil {
+		return func() {}, nil
+	}
+	return func() { t.Close() }, nil
 }